@@ -6,7 +6,6 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/yonahd/kor/pkg/kor"
-	"github.com/yonahd/kor/pkg/utils"
 )
 
 var allCmd = &cobra.Command{
@@ -15,14 +14,13 @@ var allCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(0),
 	Run: func(cmd *cobra.Command, args []string) {
 		clientset := kor.GetKubeClient(kubeConfig, kubeContext)
-		apiExtClient := kor.GetAPIExtensionsClient(kubeConfig)
-		dynamicClient := kor.GetDynamicClient(kubeConfig)
+		apiExtClient := kor.GetAPIExtensionsClient(kubeConfig, kubeContext)
+		dynamicClient := kor.GetDynamicClient(kubeConfig, kubeContext)
 
-		if response, err := kor.GetUnusedAll(filterOptions, clientset, apiExtClient, dynamicClient, outputFormat, opts); err != nil {
+		if response, err := kor.GetUnusedAll(cmd.Context(), filterOptions, clientset, apiExtClient, dynamicClient, outputFormat, opts); err != nil {
 			fmt.Println(err)
 		} else {
-			utils.PrintLogo(outputFormat)
-			fmt.Println(response)
+			printResponse(cmd.Context(), response)
 		}
 	},
 }
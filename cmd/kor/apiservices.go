@@ -0,0 +1,30 @@
+package kor
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/kor"
+)
+
+var apiServiceCmd = &cobra.Command{
+	Use:     "apiservice",
+	Aliases: []string{"apiservices"},
+	Short:   "Gets unavailable apiServices",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		clientset := kor.GetKubeClient(kubeConfig, kubeContext)
+		dynamicClient := kor.GetDynamicClient(kubeConfig, kubeContext)
+
+		if response, err := kor.GetUnusedAPIServices(cmd.Context(), filterOptions, clientset, dynamicClient, outputFormat, opts); err != nil {
+			fmt.Println(err)
+		} else {
+			printResponse(cmd.Context(), response)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(apiServiceCmd)
+}
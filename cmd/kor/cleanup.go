@@ -0,0 +1,46 @@
+package kor
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/kor"
+)
+
+var gracePeriod string
+
+var cleanupCmd = &cobra.Command{
+	Use:   "cleanup resource1,resource2,...",
+	Short: "Deletes the resources quarantined for the whole grace period that are still unused",
+	Long: `Deletes the resources quarantined with --quarantine at least --grace-period ago that
+are still unused, and releases the quarantined ones that are used again.`,
+	Example: `  kor configmap,secret --quarantine
+  kor cleanup configmap,secret --grace-period 14d`,
+	Args: cobra.ExactArgs(1),
+	// Cleanup deletes, so the delete options are checked like for --delete
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		opts.DeleteFlag = true
+		filterOptions.QuarantinedFor = gracePeriod
+		rootCmd.PersistentPreRun(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		clientset := kor.GetKubeClient(kubeConfig, kubeContext)
+		apiExtClient := kor.GetAPIExtensionsClient(kubeConfig, kubeContext)
+		dynamicClient := kor.GetDynamicClient(kubeConfig, kubeContext)
+
+		response, err := kor.GetUnusedMulti(cmd.Context(), args[0], filterOptions, clientset, apiExtClient, dynamicClient, outputFormat, opts)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		kor.ReleaseQuarantined(cmd.Context(), args[0], filterOptions, clientset, dynamicClient)
+		printResponse(cmd.Context(), response)
+	},
+}
+
+func init() {
+	cleanupCmd.Flags().StringVar(&gracePeriod, "grace-period", "", "How long resources must have been quarantined before they are deleted. Accepts d and w besides the Go duration units, e.g. 14d")
+	_ = cleanupCmd.MarkFlagRequired("grace-period")
+	rootCmd.AddCommand(cleanupCmd)
+}
@@ -0,0 +1,59 @@
+package kor
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/kor"
+)
+
+// completeNamespaces completes the comma-separated namespace flags with the
+// namespaces of the current cluster
+func completeNamespaces(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	kor.StopProgress()
+	names, err := kor.GetNamespaceNames(cmd.Context(), kubeConfig, kubeContext)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return completeListItems(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeContexts(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	names, err := kor.GetContextNames(kubeConfig)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeListItems completes the last item of a comma-separated list, keeping the items already typed
+func completeListItems(candidates []string, toComplete string) []string {
+	prefix := ""
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix = toComplete[:i+1]
+	}
+	completions := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		completions = append(completions, prefix+candidate)
+	}
+	return completions
+}
+
+// registerCompletions registers the flag value completions, the flags are
+// defined in the init of root.go which has to run first
+func registerCompletions() {
+	for _, name := range []string{"include-namespaces", "exclude-namespaces"} {
+		_ = rootCmd.RegisterFlagCompletionFunc(name, completeNamespaces)
+	}
+	for _, name := range []string{"kubecontext", "context", "contexts"} {
+		_ = rootCmd.RegisterFlagCompletionFunc(name, completeContexts)
+	}
+	_ = rootCmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{"table", "wide", "json", "yaml", "csv", "junit", "sarif", "go-template=", "jsonpath="}, cobra.ShellCompDirectiveNoFileComp))
+	_ = rootCmd.RegisterFlagCompletionFunc("group-by", cobra.FixedCompletions([]string{"namespace", "resource", "kind"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = rootCmd.RegisterFlagCompletionFunc("dry-run", cobra.FixedCompletions([]string{kor.DryRunServer, kor.DryRunClient, "none"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = rootCmd.RegisterFlagCompletionFunc("cascade", cobra.FixedCompletions(kor.CascadeModes, cobra.ShellCompDirectiveNoFileComp))
+	_ = rootCmd.RegisterFlagCompletionFunc("script-format", cobra.FixedCompletions(kor.ScriptFormats, cobra.ShellCompDirectiveNoFileComp))
+	_ = rootCmd.RegisterFlagCompletionFunc("log-format", cobra.FixedCompletions(kor.LogFormats, cobra.ShellCompDirectiveNoFileComp))
+	_ = rootCmd.RegisterFlagCompletionFunc("sort-by", cobra.FixedCompletions(kor.SortByOptions, cobra.ShellCompDirectiveNoFileComp))
+}
@@ -0,0 +1,24 @@
+package kor
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+var configFile string
+
+// applyConfigFile sets the flags listed in the config file, flags given on the
+// command line take precedence over it
+func applyConfigFile(cmd *cobra.Command) error {
+	path, required := configFile, true
+	if path == "" {
+		path, required = utils.DefaultConfigPath(), false
+	}
+
+	values, err := utils.LoadConfigFile(path, required)
+	if err != nil {
+		return err
+	}
+	return utils.ApplyConfigValues(cmd, path, values)
+}
@@ -2,11 +2,17 @@ package kor
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 
 	"github.com/yonahd/kor/pkg/kor"
-	"github.com/yonahd/kor/pkg/utils"
+)
+
+var (
+	showDuplicateConfigMaps bool
+	acrossNamespaces        bool
+	showUnusedConfigMapKeys bool
 )
 
 var configmapCmd = &cobra.Command{
@@ -15,16 +21,40 @@ var configmapCmd = &cobra.Command{
 	Short:   "Gets unused configmaps",
 	Args:    cobra.ExactArgs(0),
 	Run: func(cmd *cobra.Command, args []string) {
+		if acrossNamespaces && !showDuplicateConfigMaps {
+			fmt.Fprintln(os.Stderr, "Error while validating configmap options '--across-namespaces requires --duplicates'")
+			os.Exit(1)
+		}
 		clientset := kor.GetKubeClient(kubeConfig, kubeContext)
-		if response, err := kor.GetUnusedConfigmaps(filterOptions, clientset, outputFormat, opts); err != nil {
+		if showDuplicateConfigMaps {
+			// The reason holds the duplicate group, so it is always shown
+			opts.ShowReason = true
+			if response, err := kor.GetDuplicateConfigmaps(cmd.Context(), filterOptions, clientset, outputFormat, opts, acrossNamespaces); err != nil {
+				fmt.Println(err)
+			} else {
+				printResponse(cmd.Context(), response)
+			}
+			return
+		}
+		if showUnusedConfigMapKeys {
+			if response, err := kor.GetUnusedConfigmapKeys(cmd.Context(), filterOptions, clientset, outputFormat, opts); err != nil {
+				fmt.Println(err)
+			} else {
+				printResponse(cmd.Context(), response)
+			}
+			return
+		}
+		if response, err := kor.GetUnusedConfigmaps(cmd.Context(), filterOptions, clientset, outputFormat, opts); err != nil {
 			fmt.Println(err)
 		} else {
-			utils.PrintLogo(outputFormat)
-			fmt.Println(response)
+			printResponse(cmd.Context(), response)
 		}
 	},
 }
 
 func init() {
+	configmapCmd.Flags().BoolVar(&showDuplicateConfigMaps, "duplicates", false, "Report ConfigMaps with identical content instead of unused ConfigMaps")
+	configmapCmd.Flags().BoolVar(&acrossNamespaces, "across-namespaces", false, "Compare ConfigMap content across namespaces, requires --duplicates")
+	configmapCmd.Flags().BoolVar(&showUnusedConfigMapKeys, "unused-keys", false, "Report unreferenced keys of used ConfigMaps instead of unused ConfigMaps")
 	rootCmd.AddCommand(configmapCmd)
 }
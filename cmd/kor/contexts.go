@@ -0,0 +1,106 @@
+package kor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/yonahd/kor/pkg/kor"
+)
+
+var (
+	kubeContexts []string
+	allContexts  bool
+	clustersFile string
+)
+
+// singleClusterFlags act on the cluster of a single context, they cannot be
+// combined with --contexts, --all-contexts and --clusters
+var singleClusterFlags = []string{"context", "kubecontext", "delete", "quarantine", "generate-script", "baseline", "export-manifests", "incremental-cache", "github-issues-repo", "jira-project"}
+
+// fleetScan reports whether the scan covers several clusters.
+func fleetScan() bool {
+	return allContexts || len(kubeContexts) > 0 || clustersFile != ""
+}
+
+// scanResources returns the resources cmd scans, every resource when empty,
+// false when cmd is not kor all or the scan of some resources.
+func scanResources(cmd *cobra.Command, args []string) ([]string, bool) {
+	switch {
+	case !cmd.HasParent() && len(args) > 0:
+		return strings.Split(args[0], ","), true
+	case cmd == allCmd:
+		return nil, true
+	case kor.IsDetector(cmd.Name()):
+		return []string{cmd.Name()}, true
+	}
+	return nil, false
+}
+
+// setupFleetScan makes cmd scan the clusters of --contexts, --all-contexts
+// or --clusters instead of the current context.
+func setupFleetScan(cmd *cobra.Command, args []string) error {
+	var fleetFlags int
+	for _, set := range []bool{allContexts, len(kubeContexts) > 0, clustersFile != ""} {
+		if set {
+			fleetFlags++
+		}
+	}
+	if fleetFlags > 1 {
+		return fmt.Errorf("only one of --contexts, --all-contexts and --clusters can be used")
+	}
+	for _, name := range singleClusterFlags {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s cannot be used with --contexts, --all-contexts or --clusters", name)
+		}
+	}
+	var err error
+	cmd.LocalNonPersistentFlags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Changed {
+			err = fmt.Errorf("--%s cannot be used with --contexts, --all-contexts or --clusters", flag.Name)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	resources, ok := scanResources(cmd, args)
+	if !ok {
+		return fmt.Errorf("--contexts, --all-contexts and --clusters only apply to kor all and the scans of resources, not to kor %s", cmd.Name())
+	}
+
+	var clusters []kor.Cluster
+	switch {
+	case clustersFile != "":
+		if clusters, err = kor.LoadClusters(clustersFile); err != nil {
+			return err
+		}
+		for i := range clusters {
+			if clusters[i].Kubeconfig == "" {
+				clusters[i].Kubeconfig = kubeConfig
+			}
+		}
+	case allContexts:
+		contexts, err := kor.GetContextNames(kubeConfig)
+		if err != nil {
+			return err
+		}
+		if len(contexts) == 0 {
+			return fmt.Errorf("the kubeconfig has no contexts")
+		}
+		clusters = kor.ContextClusters(kubeConfig, contexts)
+	default:
+		clusters = kor.ContextClusters(kubeConfig, kubeContexts)
+	}
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		if response, err := kor.GetUnusedClusters(cmd.Context(), clusters, resources, filterOptions, outputFormat, opts); err != nil {
+			fmt.Println(err)
+		} else {
+			printResponse(cmd.Context(), response)
+		}
+	}
+	return nil
+}
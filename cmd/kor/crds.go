@@ -6,7 +6,6 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/yonahd/kor/pkg/kor"
-	"github.com/yonahd/kor/pkg/utils"
 )
 
 var crdCmd = &cobra.Command{
@@ -15,13 +14,12 @@ var crdCmd = &cobra.Command{
 	Short:   "Gets unused crds",
 	Args:    cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		apiExtClient := kor.GetAPIExtensionsClient(kubeConfig)
-		dynamicClient := kor.GetDynamicClient(kubeConfig)
-		if response, err := kor.GetUnusedCrds(filterOptions, apiExtClient, dynamicClient, outputFormat, opts); err != nil {
+		apiExtClient := kor.GetAPIExtensionsClient(kubeConfig, kubeContext)
+		dynamicClient := kor.GetDynamicClient(kubeConfig, kubeContext)
+		if response, err := kor.GetUnusedCrds(cmd.Context(), filterOptions, apiExtClient, dynamicClient, outputFormat, opts); err != nil {
 			fmt.Println(err)
 		} else {
-			utils.PrintLogo(outputFormat)
-			fmt.Println(response)
+			printResponse(cmd.Context(), response)
 		}
 
 	},
@@ -0,0 +1,29 @@
+package kor
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/kor"
+)
+
+var csiDriverCmd = &cobra.Command{
+	Use:     "csidriver",
+	Aliases: []string{"csidrivers"},
+	Short:   "Gets unused csiDrivers",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		clientset := kor.GetKubeClient(kubeConfig, kubeContext)
+
+		if response, err := kor.GetUnusedCSIDrivers(cmd.Context(), filterOptions, clientset, outputFormat, opts); err != nil {
+			fmt.Println(err)
+		} else {
+			printResponse(cmd.Context(), response)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(csiDriverCmd)
+}
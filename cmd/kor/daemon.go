@@ -0,0 +1,52 @@
+package kor
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/kor"
+)
+
+var (
+	daemonResources []string
+	daemonOptions   kor.DaemonOptions
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "scan on a cron schedule",
+	Long:  "Scan the cluster on the cron --schedule (in UTC) until stopped, writing the results of each scan to --results-dir and sending them to the configured notifications, issue trackers and sinks, as kor does after a single scan.",
+	Example: `  kor daemon --schedule "0 6 * * 1" --slack-webhook-url <url>
+  kor daemon --schedule "@every 6h" -r configmap,secret --run-on-start`,
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		// The daemon keeps running, a status line would only clutter its logs
+		kor.StopProgress()
+		clientset := kor.GetKubeClient(kubeConfig, kubeContext)
+		apiExtClient := kor.GetAPIExtensionsClient(kubeConfig, kubeContext)
+		dynamicClient := kor.GetDynamicClient(kubeConfig, kubeContext)
+
+		publish := func(response string) error {
+			if quarantine {
+				kor.QuarantineResources(cmd.Context(), clientset)
+			}
+			return publishResults(cmd.Context(), response)
+		}
+		if err := kor.Daemon(cmd.Context(), filterOptions, clientset, apiExtClient, dynamicClient, outputFormat, opts, daemonResources, daemonOptions, publish); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	daemonCmd.Flags().StringVar(&daemonOptions.Schedule, "schedule", "", "Cron expression of the scans in UTC, e.g. \"0 6 * * 1\", or an interval such as \"@every 6h\"")
+	daemonCmd.Flags().StringSliceVarP(&daemonResources, "resources", "r", nil, "Comma-separated list of resources to scan (e.g., deployment,service), defaults to every resource")
+	daemonCmd.Flags().StringVar(&daemonOptions.ResultsDir, "results-dir", "", "Directory to write a JSON report of every scan to, defaults to ~/.kor/results")
+	daemonCmd.Flags().IntVar(&daemonOptions.KeepResults, "keep-results", kor.DefaultDaemonKeepResults, "Number of reports kept in --results-dir, older ones are removed. 0 keeps every report")
+	daemonCmd.Flags().BoolVar(&daemonOptions.RunOnStart, "run-on-start", false, "Scan right away instead of waiting for the first scheduled time")
+	_ = daemonCmd.MarkFlagRequired("schedule")
+	rootCmd.AddCommand(daemonCmd)
+}
@@ -6,7 +6,6 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/yonahd/kor/pkg/kor"
-	"github.com/yonahd/kor/pkg/utils"
 )
 
 var dsCmd = &cobra.Command{
@@ -17,11 +16,10 @@ var dsCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		clientset := kor.GetKubeClient(kubeConfig, kubeContext)
 
-		if response, err := kor.GetUnusedDaemonSets(filterOptions, clientset, outputFormat, opts); err != nil {
+		if response, err := kor.GetUnusedDaemonSets(cmd.Context(), filterOptions, clientset, outputFormat, opts); err != nil {
 			fmt.Println(err)
 		} else {
-			utils.PrintLogo(outputFormat)
-			fmt.Println(response)
+			printResponse(cmd.Context(), response)
 		}
 	},
 }
@@ -0,0 +1,45 @@
+package kor
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/yonahd/kor/pkg/common"
+)
+
+// deleteValue backs --delete, which is a plain switch or takes the kinds to delete
+type deleteValue struct {
+	opts *common.Opts
+}
+
+func (d *deleteValue) String() string {
+	if d.opts == nil || !d.opts.DeleteFlag {
+		return "false"
+	}
+	if len(d.opts.DeleteKinds) == 0 {
+		return "true"
+	}
+	return strings.Join(d.opts.DeleteKinds, ",")
+}
+
+func (d *deleteValue) Set(value string) error {
+	if enabled, err := strconv.ParseBool(value); err == nil {
+		d.opts.DeleteFlag = enabled
+		d.opts.DeleteKinds = nil
+		return nil
+	}
+
+	var kinds []string
+	for _, kind := range strings.Split(value, ",") {
+		if kind = strings.ToLower(strings.TrimSpace(kind)); kind != "" {
+			kinds = append(kinds, kind)
+		}
+	}
+	d.opts.DeleteFlag = len(kinds) > 0
+	d.opts.DeleteKinds = kinds
+	return nil
+}
+
+func (d *deleteValue) Type() string {
+	return "kinds"
+}
@@ -0,0 +1,29 @@
+package kor
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/kor"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff OLD_REPORT NEW_REPORT",
+	Short: "Compare two json or yaml reports",
+	Long: `Compare two reports written with --output json or yaml and show the resources
+which became unused, stayed unused and were resolved between the runs.
+Both reports must have been written with the same --group-by.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if response, err := kor.GetReportDiff(args[0], args[1], outputFormat, opts); err != nil {
+			fmt.Println(err)
+		} else {
+			printResponse(cmd.Context(), response)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+}
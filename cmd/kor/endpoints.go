@@ -0,0 +1,29 @@
+package kor
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/kor"
+)
+
+var endpointsCmd = &cobra.Command{
+	Use:     "endpoints",
+	Aliases: []string{"ep"},
+	Short:   "Gets endpoints without a parent service",
+	Args:    cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		clientset := kor.GetKubeClient(kubeConfig, kubeContext)
+
+		if response, err := kor.GetUnusedEndpoints(cmd.Context(), filterOptions, clientset, outputFormat, opts); err != nil {
+			fmt.Println(err)
+		} else {
+			printResponse(cmd.Context(), response)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(endpointsCmd)
+}
@@ -1,28 +1,44 @@
 package kor
 
 import (
+	"fmt"
+	"os"
+	"time"
+
 	"github.com/spf13/cobra"
 
 	"github.com/yonahd/kor/pkg/kor"
 )
 
-var resourceList []string
+var (
+	resourceList     []string
+	listenAddress    string
+	exporterInterval time.Duration
+)
 
 var exporterCmd = &cobra.Command{
 	Use:   "exporter",
 	Short: "start prometheus exporter",
+	Long:  "Serve Prometheus metrics of the unused resources, such as kor_unused_resources{kind,namespace}, rescanning the cluster on an interval.",
 	Args:  cobra.ExactArgs(0),
 	Run: func(cmd *cobra.Command, args []string) {
+		// The exporter keeps running, a status line would only clutter its logs
+		kor.StopProgress()
 		clientset := kor.GetKubeClient(kubeConfig, kubeContext)
-		apiExtClient := kor.GetAPIExtensionsClient(kubeConfig)
-		dynamicClient := kor.GetDynamicClient(kubeConfig)
+		apiExtClient := kor.GetAPIExtensionsClient(kubeConfig, kubeContext)
+		dynamicClient := kor.GetDynamicClient(kubeConfig, kubeContext)
 
-		kor.Exporter(filterOptions, clientset, apiExtClient, dynamicClient, "json", opts, resourceList)
+		if err := kor.Exporter(cmd.Context(), filterOptions, clientset, apiExtClient, dynamicClient, "json", opts, resourceList, listenAddress, exporterInterval); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
 
 	},
 }
 
 func init() {
 	exporterCmd.Flags().StringSliceVarP(&resourceList, "resources", "r", nil, "Comma-separated list of resources to monitor (e.g., deployment,service)")
+	exporterCmd.Flags().StringVar(&listenAddress, "listen-address", kor.DefaultExporterAddress, "Address to serve /metrics and /healthz on")
+	exporterCmd.Flags().DurationVar(&exporterInterval, "interval", 0, "Time between two scans, e.g. 5m. Defaults to $EXPORTER_INTERVAL minutes, or 10m")
 	rootCmd.AddCommand(exporterCmd)
 }
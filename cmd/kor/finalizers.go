@@ -15,9 +15,9 @@ var finalizerCmd = &cobra.Command{
 	Args:    cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		clientset := kor.GetKubeClient(kubeConfig, kubeContext)
-		dynamicClient := kor.GetDynamicClient(kubeConfig)
+		dynamicClient := kor.GetDynamicClient(kubeConfig, kubeContext)
 
-		if response, err := kor.GetUnusedfinalizers(filterOptions, clientset, dynamicClient, outputFormat, opts); err != nil {
+		if response, err := kor.GetUnusedfinalizers(cmd.Context(), filterOptions, clientset, dynamicClient, outputFormat, opts); err != nil {
 			fmt.Println(err)
 		} else {
 			fmt.Println(response)
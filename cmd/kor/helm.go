@@ -0,0 +1,33 @@
+package kor
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/kor"
+)
+
+var helmHistoryMax int
+
+var helmCmd = &cobra.Command{
+	Use:     "helm",
+	Aliases: []string{"helmrelease", "helmreleases"},
+	Short:   "Gets leftover helm release secrets",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		clientset := kor.GetKubeClient(kubeConfig, kubeContext)
+		dynamicClient := kor.GetDynamicClient(kubeConfig, kubeContext)
+
+		if response, err := kor.GetUnusedHelmReleases(cmd.Context(), filterOptions, clientset, dynamicClient, outputFormat, opts, helmHistoryMax); err != nil {
+			fmt.Println(err)
+		} else {
+			printResponse(cmd.Context(), response)
+		}
+	},
+}
+
+func init() {
+	helmCmd.Flags().IntVar(&helmHistoryMax, "history-max", kor.DefaultHelmHistoryMax, "Report superseded release revisions beyond this many revisions per release (0 disables the check)")
+	rootCmd.AddCommand(helmCmd)
+}
@@ -0,0 +1,56 @@
+package kor
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/kor"
+)
+
+var historySince string
+
+var historyCmd = &cobra.Command{
+	Use:   "history [resource]",
+	Short: "Shows the unused resources recorded in the state store over time",
+	Long: `Shows the number of unused resources per day of the scans recorded in the
+--state-store, or with a resource, kind/name or namespace/kind/name like the --quiet
+lines, since when it has been continuously unused and its earlier unused periods.`,
+	Example: `  kor history --state-store ~/.kor/state.db --since 90d
+  kor history default/configmap/my-config --state-store ~/.kor/state.db
+  kor history pv/pvc-0b1c -o json --state-store ~/.kor/state.db`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		kor.StopProgress()
+		if stateStore == "" {
+			fmt.Fprintln(os.Stderr, "Error while validating history options '--state-store is required'")
+			os.Exit(1)
+		}
+
+		var response string
+		var err error
+		if len(args) == 1 {
+			response, err = kor.GetResourceHistory(stateStore, args[0], outputFormat, time.Now())
+		} else {
+			var since time.Duration
+			if since, err = filters.ParseDuration(historySince); err != nil {
+				fmt.Fprintf(os.Stderr, "Error while validating history options 'invalid --since: %s'\n", err)
+				os.Exit(1)
+			}
+			response, err = kor.GetHistoryTrend(stateStore, time.Now().Add(-since), outputFormat)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Print(response)
+	},
+}
+
+func init() {
+	historyCmd.Flags().StringVar(&historySince, "since", "30d", "Show the scans of this last period, e.g. 90d or 12h")
+	rootCmd.AddCommand(historyCmd)
+}
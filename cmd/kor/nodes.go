@@ -0,0 +1,37 @@
+package kor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/kor"
+)
+
+var (
+	nodeCordonedFor          time.Duration
+	nodeUtilisationThreshold float64
+)
+
+var nodeCmd = &cobra.Command{
+	Use:     "node",
+	Aliases: []string{"nodes", "no"},
+	Short:   "Gets idle nodes",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		clientset := kor.GetKubeClient(kubeConfig, kubeContext)
+
+		if response, err := kor.GetUnusedNodes(cmd.Context(), filterOptions, clientset, outputFormat, opts, nodeCordonedFor, nodeUtilisationThreshold); err != nil {
+			fmt.Println(err)
+		} else {
+			printResponse(cmd.Context(), response)
+		}
+	},
+}
+
+func init() {
+	nodeCmd.Flags().DurationVar(&nodeCordonedFor, "cordoned-for", kor.DefaultNodeCordonedFor, "Report cordoned nodes without workload pods once they have been cordoned this long")
+	nodeCmd.Flags().Float64Var(&nodeUtilisationThreshold, "utilisation-threshold", kor.DefaultNodeUtilisationThreshold, "Report nodes whose cpu and memory requests are below this percentage of allocatable (0 disables the check)")
+	rootCmd.AddCommand(nodeCmd)
+}
@@ -0,0 +1,36 @@
+package kor
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/kor"
+)
+
+var installCRDs bool
+
+var operatorCmd = &cobra.Command{
+	Use:   "operator",
+	Short: "run the ScanPolicy controller",
+	Long:  "Watch ScanPolicy resources and scan the cluster on their schedule, writing the unused resources found to a ScanReport named after each policy. Notifications are configured with the flags of the operator.",
+	Args:  cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		// The operator keeps running, a status line would only clutter its logs
+		kor.StopProgress()
+		clientset := kor.GetKubeClient(kubeConfig, kubeContext)
+		apiExtClient := kor.GetAPIExtensionsClient(kubeConfig, kubeContext)
+		dynamicClient := kor.GetDynamicClient(kubeConfig, kubeContext)
+
+		if err := kor.Operator(cmd.Context(), clientset, apiExtClient, dynamicClient, opts, installCRDs); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	operatorCmd.Flags().BoolVar(&installCRDs, "install-crds", true, "Create the ScanPolicy and ScanReport CustomResourceDefinitions when missing")
+	rootCmd.AddCommand(operatorCmd)
+}
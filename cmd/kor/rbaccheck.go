@@ -0,0 +1,55 @@
+package kor
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/kor"
+)
+
+var rbacCheckCmd = &cobra.Command{
+	Use:   "rbac-check [resources]",
+	Short: "Prints the minimal ClusterRole kor needs to scan",
+	Long: `Prints the minimal ClusterRole allowing kor to scan the given comma-separated
+resources, every resource when omitted, and checks it against the permissions of the
+current user. The permissions the user lacks are listed on stderr and the scans needing
+them are reported as skipped.`,
+	Example: `  kor rbac-check
+  kor rbac-check configmap,secret > kor-role.yaml`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		kor.StopProgress()
+		var resources []string
+		if len(args) == 1 {
+			resources = strings.Split(args[0], ",")
+		}
+		role, notes, err := kor.ScanRole(resources)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		manifest, err := kor.FormatScanRole(role, notes)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Print(manifest)
+
+		missing, err := kor.MissingScanPermissions(cmd.Context(), kor.GetKubeClient(kubeConfig, kubeContext), role)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to check the permissions of the current user: %v\n", err)
+			os.Exit(1)
+		}
+		if len(missing) > 0 {
+			fmt.Fprintf(os.Stderr, "The current user is not allowed to:\n- %s\n", strings.Join(missing, "\n- "))
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rbacCheckCmd)
+}
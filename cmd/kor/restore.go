@@ -0,0 +1,35 @@
+package kor
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/kor"
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore archive",
+	Short: "Creates again the resources backed up before a deletion",
+	Long: `Creates again the resources backed up before a deletion, from the backup directory,
+one of its files or the s3://bucket/prefix they were exported to. Resources that exist
+already are skipped.`,
+	Example: `  kor restore ~/.kor/backups/20261014-101500
+  kor restore s3://my-bucket/kor-backups/my-cluster`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		kor.StopProgress()
+		clientset := kor.GetKubeClient(kubeConfig, kubeContext)
+		dynamicClient := kor.GetDynamicClient(kubeConfig, kubeContext)
+
+		if err := kor.RestoreManifests(cmd.Context(), args[0], dynamicClient, kor.NewRESTMapper(clientset)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+}
@@ -1,12 +1,20 @@
 package kor
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/kubernetes"
 
 	"github.com/yonahd/kor/pkg/common"
 	"github.com/yonahd/kor/pkg/filters"
@@ -14,9 +22,14 @@ import (
 	"github.com/yonahd/kor/pkg/utils"
 )
 
+// isKubectlPlugin reports whether kor was started by kubectl as the kor plugin, e.g. installed with krew
+func isKubectlPlugin() bool {
+	return strings.HasPrefix(filepath.Base(os.Args[0]), "kubectl-")
+}
+
 func execName() string {
 	n := "kor"
-	if strings.HasPrefix(filepath.Base(os.Args[0]), "kubectl-") {
+	if isKubectlPlugin() {
 		return "kubectl-" + n
 	}
 
@@ -29,62 +42,525 @@ var rootCmd = &cobra.Command{
 	Long: `kor is a CLI to to discover unused Kubernetes resources
 	kor can currently discover unused configmaps and secrets`,
 	Args: cobra.MinimumNArgs(1),
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if err := applyConfigFile(cmd); err != nil {
+			fmt.Fprintf(os.Stderr, "Error while loading config file '%s'\n", err)
+			os.Exit(1)
+		}
+		kor.SetRequestTimeout(requestTimeout)
+		kor.SetWorkers(workers)
+		kor.SetChunkSize(chunkSize)
+		kor.SetClusterWideLists(clusterWideLists)
+		kor.SetInformerCache(informerCache)
+		kor.SetIncrementalCache(incrementalCache)
+		kor.SetBuiltinExceptions(!noBuiltinExceptions)
+		if err := kor.SetClientRateLimits(qps, burst); err != nil {
+			fmt.Fprintf(os.Stderr, "Error while validating client options '%s'\n", err)
+			os.Exit(1)
+		}
+		if err := kor.ConfigureLogging(logFormat, opts.Verbose); err != nil {
+			fmt.Fprintf(os.Stderr, "Error while configuring logging '%s'\n", err)
+			os.Exit(1)
+		}
+		if otlpEndpoint == "" {
+			otlpEndpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+		}
+		if otlpEndpoint != "" {
+			if err := kor.EnableTelemetry(otlpEndpoint); err != nil {
+				fmt.Fprintf(os.Stderr, "Error while validating telemetry options '%s'\n", err)
+				os.Exit(1)
+			}
+		}
+		if err := filterOptions.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error while validating filter options '%s'\n", err)
+			os.Exit(1)
+		}
+		filterOptions.Modify()
+		kor.SetPprof(pprofEndpoints)
+		if err := kor.StartProfile(profile, profileOutput); err != nil {
+			fmt.Fprintf(os.Stderr, "Error while starting the profile '%s'\n", err)
+			os.Exit(1)
+		}
+		// Quiet output is built from the table path, regardless of --output
+		if opts.Quiet {
+			outputFormat = "table"
+		}
+		// Wide output is a table with the owner columns added
+		if outputFormat == "wide" {
+			outputFormat = "table"
+			opts.Wide = true
+		}
+		// fatih/color already disables colors when stdout is not a terminal
+		if noColor || opts.Quiet || outputFile != "" || opts.WebhookURL != "" || opts.Channel != "" || opts.TeamsWebhookURL != "" || opts.DiscordWebhookURL != "" {
+			color.NoColor = true
+		}
+		if (opts.Channel == "") != (opts.Token == "") {
+			fmt.Fprintln(os.Stderr, "Error while validating slack options '--slack-channel and --slack-auth-token must be set together'")
+			os.Exit(1)
+		}
+		if _, err := utils.ParseHeaders(opts.ResultWebhookHeaders); err != nil {
+			fmt.Fprintf(os.Stderr, "Error while validating webhook options '%s'\n", err)
+			os.Exit(1)
+		}
+		if len(opts.ResultWebhookHeaders) > 0 && opts.ResultWebhookURL == "" {
+			fmt.Fprintln(os.Stderr, "Error while validating webhook options '--webhook-header requires --webhook-url'")
+			os.Exit(1)
+		}
+		if err := kor.ValidateUploadFormats(uploadFormats); err != nil {
+			fmt.Fprintf(os.Stderr, "Error while validating upload options '%s'\n", err)
+			os.Exit(1)
+		}
+		if err := kor.ValidateIssueOptions(opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error while validating issue options '%s'\n", err)
+			os.Exit(1)
+		}
+		if err := kor.ValidateEmailOptions(opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error while validating email options '%s'\n", err)
+			os.Exit(1)
+		}
+		if dryRun != "" && !opts.DeleteFlag {
+			fmt.Fprintln(os.Stderr, "Error while validating delete options '--dry-run requires --delete'")
+			os.Exit(1)
+		}
+		if err := kor.SetDeleteDryRun(dryRun); err != nil {
+			fmt.Fprintf(os.Stderr, "Error while validating delete options '%s'\n", err)
+			os.Exit(1)
+		}
+		if generateScript != "" && opts.DeleteFlag {
+			fmt.Fprintln(os.Stderr, "Error while validating delete options '--generate-script cannot be used with --delete'")
+			os.Exit(1)
+		}
+		if quarantine && opts.DeleteFlag {
+			fmt.Fprintln(os.Stderr, "Error while validating delete options '--quarantine cannot be used with --delete'")
+			os.Exit(1)
+		}
+		if err := kor.ValidateScriptFormat(scriptFormat); err != nil {
+			fmt.Fprintf(os.Stderr, "Error while validating delete options '%s'\n", err)
+			os.Exit(1)
+		}
+		if cascade != "" && !opts.DeleteFlag {
+			fmt.Fprintln(os.Stderr, "Error while validating delete options '--cascade requires --delete'")
+			os.Exit(1)
+		}
+		if err := kor.SetDeleteCascade(cascade); err != nil {
+			fmt.Fprintf(os.Stderr, "Error while validating delete options '%s'\n", err)
+			os.Exit(1)
+		}
+		if err := kor.LoadReferenceRules(referenceRules); err != nil {
+			fmt.Fprintf(os.Stderr, "Error while loading the reference rules '%s'\n", err)
+			os.Exit(1)
+		}
+		if err := kor.LoadPricing(pricingFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error while loading the pricing file '%s'\n", err)
+			os.Exit(1)
+		}
+		if err := kor.SetUsageMarkers(usageMarkers); err != nil {
+			fmt.Fprintf(os.Stderr, "Error while validating the usage markers '%s'\n", err)
+			os.Exit(1)
+		}
+		if err := kor.SetProtectedResources(protect, force); err != nil {
+			fmt.Fprintf(os.Stderr, "Error while validating delete options '%s'\n", err)
+			os.Exit(1)
+		}
+		if err := kor.SetDeleteRateLimits(deleteQPS, deleteBatchSize, deleteBatchPause); err != nil {
+			fmt.Fprintf(os.Stderr, "Error while validating delete options '%s'\n", err)
+			os.Exit(1)
+		}
+		// Deletions are backed up so they can be undone with kor restore
+		if opts.DeleteFlag && opts.ExportManifests == "" && !noBackup && (dryRun == "" || dryRun == "none") {
+			opts.ExportManifests = kor.DefaultBackupDir(time.Now())
+			slog.Info("Backing up the manifests of unused resources before deleting them", "path", opts.ExportManifests)
+		}
+		// Deletion prompts would be overwritten by the status line
+		if opts.DeleteFlag && !opts.NoInteractive {
+			kor.StopProgress()
+		}
+		if opts.GroupBy == "kind" {
+			opts.GroupBy = "resource"
+		}
+		if err := kor.ValidateSortBy(opts.SortBy); err != nil {
+			fmt.Fprintf(os.Stderr, "Error while validating output options '%s'\n", err)
+			os.Exit(1)
+		}
+		kor.SetStateStore(stateStore)
+		if stateStore != "" {
+			historyResources, recordHistory = scanResources(cmd, args)
+			// These report other findings than the unused resources
+			for _, name := range []string{"duplicates", "unused-keys"} {
+				if cmd.Flags().Changed(name) {
+					recordHistory = false
+				}
+			}
+		}
+		if unusedFor != "" {
+			gracePeriod, err := filters.ParseDuration(unusedFor)
+			if err == nil {
+				err = kor.SetUnusedFor(gracePeriod)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error while validating history options '%s'\n", err)
+				os.Exit(1)
+			}
+			if cmd.Flags().Changed("duplicates") || cmd.Flags().Changed("unused-keys") {
+				fmt.Fprintln(os.Stderr, "Error while validating history options '--unused-for cannot be used with --duplicates or --unused-keys'")
+				os.Exit(1)
+			}
+		}
+		if fleetScan() {
+			if err := setupFleetScan(cmd, args); err != nil {
+				fmt.Fprintf(os.Stderr, "Error while validating cluster options '%s'\n", err)
+				os.Exit(1)
+			}
+		}
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		resourceNames := args[0]
 		clientset := kor.GetKubeClient(kubeConfig, kubeContext)
-		apiExtClient := kor.GetAPIExtensionsClient(kubeConfig)
-		dynamicClient := kor.GetDynamicClient(kubeConfig)
+		apiExtClient := kor.GetAPIExtensionsClient(kubeConfig, kubeContext)
+		dynamicClient := kor.GetDynamicClient(kubeConfig, kubeContext)
 
-		if response, err := kor.GetUnusedMulti(resourceNames, filterOptions, clientset, apiExtClient, dynamicClient, outputFormat, opts); err != nil {
+		if response, err := kor.GetUnusedMulti(cmd.Context(), resourceNames, filterOptions, clientset, apiExtClient, dynamicClient, outputFormat, opts); err != nil {
 			fmt.Println(err)
 		} else {
-			utils.PrintLogo(outputFormat)
-			fmt.Println(response)
+			printResponse(cmd.Context(), response)
 		}
 	},
 }
 
 var (
-	outputFormat  string
-	kubeConfig    string
-	kubeContext   string
-	opts          common.Opts
-	filterOptions = &filters.Options{}
+	outputFormat        string
+	outputFile          string
+	appendOutput        bool
+	exitCode            bool
+	exitThreshold       int
+	baseline            string
+	noColor             bool
+	logFormat           string
+	dryRun              string
+	generateScript      string
+	scriptFormat        string
+	quarantine          bool
+	noBackup            bool
+	cascade             string
+	protect             []string
+	usageMarkers        []string
+	referenceRules      string
+	pricingFile         string
+	force               bool
+	deleteQPS           float32
+	deleteBatchSize     int
+	deleteBatchPause    time.Duration
+	pushGatewayURL      string
+	pushGatewayJob      string
+	pushGatewayGrouping []string
+	elasticsearchURL    string
+	elasticsearchIndex  string
+	lokiURL             string
+	lokiTenant          string
+	findingsLabels      []string
+	otlpEndpoint        string
+	uploadReport        string
+	uploadFormats       []string
+	requestTimeout      time.Duration
+	workers             int
+	chunkSize           int64
+	clusterWideLists    bool
+	informerCache       bool
+	incrementalCache    string
+	stateStore          string
+	// historyResources are the resources of the scan recorded in the
+	// --state-store, recordHistory is false for the other commands
+	historyResources    []string
+	recordHistory       bool
+	unusedFor           string
+	noBuiltinExceptions bool
+	profile             string
+	profileOutput       string
+	pprofEndpoints      bool
+	qps                 float32
+	burst               int
+	kubeConfig          string
+	kubeContext         string
+	opts                common.Opts
+	filterOptions       = &filters.Options{}
 )
 
+// unusedResourcesExitCode is returned with --exit-code when the number of
+// unused resources exceeds --exit-code-threshold.
+const unusedResourcesExitCode = 3
+
+// interruptedExitCode is returned when kor is stopped with SIGINT or SIGTERM.
+const interruptedExitCode = 130
+
+// printResponse prints the report to stdout, or writes it to --output-file when set.
+func printResponse(ctx context.Context, response string) {
+	kor.StopProgress()
+	if baseline != "" {
+		var err error
+		if response, err = kor.GetBaselineDiff(baseline, outputFormat, opts); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	if quarantine {
+		kor.QuarantineResources(ctx, kor.GetKubeClient(kubeConfig, kubeContext))
+	}
+	if generateScript != "" {
+		if err := kor.GenerateScript(generateScript, scriptFormat, kubeContext); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	// The failed namespaces and detectors follow the table, the other formats
+	// keep them out of the report
+	warnings := kor.FormatScanWarnings()
+	if warnings != "" && outputFormat == "table" {
+		response += "\n" + warnings
+		warnings = ""
+	}
+
+	switch {
+	case outputFile != "":
+		if err := utils.WriteOutput(outputFile, response, appendOutput); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	case opts.Quiet:
+		fmt.Print(response)
+	default:
+		utils.PrintLogo(outputFormat)
+		fmt.Println(response)
+	}
+	if warnings != "" {
+		fmt.Fprint(os.Stderr, warnings)
+	}
+
+	if err := publishResults(ctx, response); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if recordHistory {
+		var clientset kubernetes.Interface
+		if !fleetScan() {
+			clientset = kor.GetKubeClient(kubeConfig, kubeContext)
+		}
+		if err := kor.RecordHistory(ctx, historyResources, filterOptions, clientset); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	// Telemetry is best effort, the scan itself succeeded
+	if err := kor.FlushTelemetry(); err != nil {
+		slog.Error("Failed to export telemetry", "error", err)
+	}
+
+	if exitCode && kor.UnusedResourceCount() > exitThreshold {
+		stopProfile()
+		os.Exit(unusedResourcesExitCode)
+	}
+}
+
+// publishResults hands the report of a scan to the configured notifications,
+// issue trackers and sinks, stopping at the first failure.
+func publishResults(ctx context.Context, response string) error {
+	if err := kor.Notify(opts, response); err != nil {
+		return err
+	}
+	if opts.GitHubRepo != "" || opts.JiraProject != "" {
+		if err := kor.OpenIssues(ctx, opts, kor.GetKubeClient(kubeConfig, kubeContext)); err != nil {
+			return err
+		}
+	}
+	if uploadReport != "" {
+		if err := kor.UploadReport(uploadReport, uploadFormats); err != nil {
+			return err
+		}
+	}
+	if elasticsearchURL != "" {
+		if err := kor.IndexFindings(elasticsearchURL, elasticsearchIndex, findingsLabels); err != nil {
+			return err
+		}
+	}
+	if lokiURL != "" {
+		if err := kor.PushFindingsToLoki(lokiURL, lokiTenant, findingsLabels); err != nil {
+			return err
+		}
+	}
+	if pushGatewayURL != "" {
+		if err := kor.PushMetrics(pushGatewayURL, pushGatewayJob, pushGatewayGrouping); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func init() {
-	rootCmd.PersistentFlags().StringVarP(&kubeConfig, "kubeconfig", "k", "", "Path to kubeConfig file (optional)")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Path to a YAML config file of flag defaults keyed by flag name, defaults to ~/"+utils.DefaultConfigFile+" when present")
+	rootCmd.PersistentFlags().StringVarP(&kubeConfig, "kubeconfig", "k", "", "Path to kubeConfig file (optional), defaults to $KUBECONFIG or ~/.kube/config")
 	rootCmd.PersistentFlags().StringVarP(&kubeContext, "kubecontext", "c", "", "kubectl context to be used (optional)")
-	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table", "Output format (table, json or yaml)")
-	rootCmd.PersistentFlags().StringVar(&opts.WebhookURL, "slack-webhook-url", "", "Slack webhook URL to send notifications to")
-	rootCmd.PersistentFlags().StringVar(&opts.Channel, "slack-channel", "", "Slack channel to send notifications to. --slack-channel requires --slack-auth-token to be set.")
-	rootCmd.PersistentFlags().StringVar(&opts.Token, "slack-auth-token", "", "Slack auth token to send notifications to. --slack-auth-token requires --slack-channel to be set.")
-	rootCmd.PersistentFlags().BoolVar(&opts.DeleteFlag, "delete", false, "Delete unused resources")
-	rootCmd.PersistentFlags().BoolVar(&opts.NoInteractive, "no-interactive", false, "Do not prompt for confirmation when deleting resources. Be careful using this flag!")
-	rootCmd.PersistentFlags().BoolVarP(&opts.Verbose, "verbose", "v", false, "Verbose output (print empty namespaces)")
-	rootCmd.PersistentFlags().StringVar(&opts.GroupBy, "group-by", "namespace", "Group output by (namespace, resource)")
+	rootCmd.PersistentFlags().StringVar(&kubeContext, "context", "", "kubeconfig context to scan instead of the current context (alias of --kubecontext)")
+	rootCmd.PersistentFlags().StringSliceVar(&kubeContexts, "contexts", nil, "kubeconfig contexts to scan at the same time, split by commas, into a single report with a cluster column. Example: --contexts staging,production")
+	rootCmd.PersistentFlags().BoolVar(&allContexts, "all-contexts", false, "Scan every context of the kubeconfig at the same time, like --contexts")
+	rootCmd.PersistentFlags().StringVar(&clustersFile, "clusters", "", "YAML file of the clusters to scan at the same time like --contexts, each with its kubeconfig, context and exclusions")
+	rootCmd.PersistentFlags().DurationVar(&requestTimeout, "timeout", 0, "Timeout of each Kubernetes API request, e.g. 30s. Zero means no timeout")
+	rootCmd.PersistentFlags().IntVar(&workers, "workers", 1, "Number of namespaces to scan in parallel")
+	rootCmd.PersistentFlags().Float32Var(&qps, "qps", 0, "Maximum number of Kubernetes API requests per second, 0 keeps the client-go default of 5")
+	rootCmd.PersistentFlags().IntVar(&burst, "burst", 0, "Maximum burst of Kubernetes API requests above --qps, 0 keeps the client-go default of 10")
+	rootCmd.PersistentFlags().Int64Var(&chunkSize, "chunk-size", 500, "Number of objects requested per page of the List calls to the API server, 0 lists everything in a single request")
+	rootCmd.PersistentFlags().BoolVar(&clusterWideLists, "cluster-wide-lists", false, "List each namespaced resource once across the cluster instead of once per namespace, fewer API requests at the cost of memory")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", fmt.Sprintf("Profile the run and write the profile to --profile-output, to report performance issues (%s)", strings.Join(kor.ProfileKinds, ", ")))
+	rootCmd.PersistentFlags().StringVar(&profileOutput, "profile-output", "", "File to write the --profile to, defaults to kor.<profile>.pprof")
+	rootCmd.PersistentFlags().BoolVar(&pprofEndpoints, "pprof", false, "With exporter and serve, serve the pprof endpoints under /debug/pprof/ on the listen address")
+	rootCmd.PersistentFlags().StringVar(&incrementalCache, "incremental-cache", "", "File keeping the resources listed across the cluster with their resourceVersion, later scans only watch the changes since the last one")
+	rootCmd.PersistentFlags().StringVar(&stateStore, "state-store", "", "File of the local state store recording the unused resources of every scan with its time, for kor history")
+	rootCmd.PersistentFlags().StringVar(&unusedFor, "unused-for", "", "Only report and delete the resources the --state-store recorded as continuously unused for at least this long, e.g. 7d, leaving out the ones used now and then such as the volumes of CronJobs")
+	rootCmd.PersistentFlags().BoolVar(&informerCache, "informer-cache", false, "With exporter, daemon, operator and serve, scan from informer caches kept up to date by watch events instead of listing the cluster at every scan")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table", "Output format (table, wide, json, yaml, csv, junit, sarif, go-template=... or jsonpath=...)")
+	rootCmd.PersistentFlags().StringVar(&outputFile, "output-file", "", "Write the report to the given file instead of stdout, creating parent directories as needed")
+	rootCmd.PersistentFlags().BoolVar(&appendOutput, "append", false, "Append to --output-file instead of overwriting it")
+	rootCmd.PersistentFlags().StringVar(&opts.WebhookURL, "slack-webhook-url", "", "Slack webhook URL to post a summary of unused resources per namespace to")
+	rootCmd.PersistentFlags().StringVar(&opts.Channel, "slack-channel", "", "Slack channel ID to upload the report to, with the summary as comment. --slack-channel requires --slack-auth-token to be set.")
+	rootCmd.PersistentFlags().StringVar(&opts.Token, "slack-auth-token", "", "Slack bot token with the files:write scope to upload the report with. --slack-auth-token requires --slack-channel to be set.")
+	rootCmd.PersistentFlags().StringVar(&opts.TeamsWebhookURL, "teams-webhook-url", "", "Microsoft Teams incoming or Workflows webhook URL to post a summary of unused resources per namespace to")
+	rootCmd.PersistentFlags().StringVar(&opts.DiscordWebhookURL, "discord-webhook-url", "", "Discord webhook URL to post a summary of unused resources per namespace to")
+	rootCmd.PersistentFlags().StringVar(&opts.ResultWebhookURL, "webhook-url", "", "URL to POST the report to as a JSON document of every unused resource by namespace and kind")
+	rootCmd.PersistentFlags().StringArrayVar(&opts.ResultWebhookHeaders, "webhook-header", nil, "Header of the --webhook-url request as 'Name: value', $VARIABLES in the value are expanded. Can be repeated. Example: --webhook-header 'Authorization: Bearer $KOR_WEBHOOK_TOKEN'")
+	rootCmd.PersistentFlags().StringVar(&opts.GitHubRepo, "github-issues-repo", "", "GitHub repository, as owner/name, to open an issue in per namespace or team with unused resources. The open issue is updated on later runs")
+	rootCmd.PersistentFlags().StringVar(&opts.GitHubToken, "github-token", "", "GitHub token allowed to write the issues of --github-issues-repo, defaults to $GITHUB_TOKEN")
+	rootCmd.PersistentFlags().StringVar(&opts.JiraURL, "jira-url", "", "Base URL of the Jira instance of --jira-project, e.g. https://example.atlassian.net")
+	rootCmd.PersistentFlags().StringVar(&opts.JiraProject, "jira-project", "", "Jira project key to open an issue in per namespace or team with unused resources. The unresolved issue is updated on later runs")
+	rootCmd.PersistentFlags().StringVar(&opts.JiraUser, "jira-user", "", "Jira Cloud user email to authenticate with an API token, without it the token is a Data Center personal access token")
+	rootCmd.PersistentFlags().StringVar(&opts.JiraToken, "jira-token", "", "Jira API token or personal access token, defaults to $JIRA_API_TOKEN")
+	rootCmd.PersistentFlags().StringVar(&opts.JiraIssueType, "jira-issue-type", "Task", "Type of the Jira issues")
+	rootCmd.PersistentFlags().StringVar(&opts.IssueGroupBy, "issue-group-by", kor.IssueGroupByNamespace, "Open an issue per namespace, or per team given by the --issue-team-label of the namespaces (namespace, team)")
+	rootCmd.PersistentFlags().StringVar(&opts.IssueTeamLabel, "issue-team-label", "team", "Namespace label holding the team, with --issue-group-by team")
+	rootCmd.PersistentFlags().StringVar(&opts.IssueOwnersFile, "issue-owners", "", "YAML file of the GitHub logins and Jira user to assign the issue of each namespace or team to, \"*\" assigns the others")
+	rootCmd.PersistentFlags().StringVar(&elasticsearchURL, "elasticsearch-url", "", "Elasticsearch URL to index every unused resource in as a document, authenticated with $ELASTICSEARCH_API_KEY or the credentials of the URL")
+	rootCmd.PersistentFlags().StringVar(&elasticsearchIndex, "elasticsearch-index", kor.DefaultElasticsearchIndex, "Index or data stream of the --elasticsearch-url documents")
+	rootCmd.PersistentFlags().StringVar(&lokiURL, "loki-url", "", "Loki URL to push every unused resource to as a JSON log line, in a stream per namespace and kind labeled job=\"kor\"")
+	rootCmd.PersistentFlags().StringVar(&lokiTenant, "loki-tenant", "", "Tenant of multi-tenant Loki, sent as X-Scope-OrgID")
+	rootCmd.PersistentFlags().StringSliceVar(&findingsLabels, "findings-labels", nil, "Labels as name=value added to the findings shipped to --elasticsearch-url and --loki-url. Example: --findings-labels cluster=production")
+	rootCmd.PersistentFlags().StringVar(&uploadReport, "upload-report", "", "s3://, gs:// or azblob://bucket/prefix to upload the report to, under <prefix>/<yyyy>/<mm>/<dd>/kor-<time>.<format>")
+	rootCmd.PersistentFlags().StringSliceVar(&uploadFormats, "upload-formats", kor.UploadFormats, "Formats of the report uploaded with --upload-report (json, html)")
+	rootCmd.PersistentFlags().StringVar(&otlpEndpoint, "otlp-endpoint", "", "OTLP/HTTP endpoint to export traces of the scan, with a span per namespace and detector, and its metrics to, e.g. http://otel-collector:4318. Defaults to $OTEL_EXPORTER_OTLP_ENDPOINT")
+	rootCmd.PersistentFlags().StringVar(&pushGatewayURL, "push-gateway-url", "", "Prometheus Pushgateway URL to push the unused resource counts and the scan duration to, for runs as a CronJob")
+	rootCmd.PersistentFlags().StringVar(&pushGatewayJob, "push-gateway-job", kor.DefaultPushGatewayJob, "Job label of the metrics pushed to --push-gateway-url")
+	rootCmd.PersistentFlags().StringSliceVar(&pushGatewayGrouping, "push-gateway-grouping", nil, "Grouping labels of the pushed metrics as name=value, runs with other labels don't replace each other's metrics. Example: --push-gateway-grouping cluster=production")
+	rootCmd.PersistentFlags().StringSliceVar(&opts.EmailTo, "email-to", nil, "Email addresses to send the summary to, with the report attached. Requires --smtp-host and --email-from")
+	rootCmd.PersistentFlags().StringVar(&opts.EmailFrom, "email-from", "", "Sender address of the --email-to emails")
+	rootCmd.PersistentFlags().StringSliceVar(&opts.EmailAttach, "email-attach", kor.EmailAttachments, "Formats the report is attached in to the --email-to emails (html, csv)")
+	rootCmd.PersistentFlags().StringVar(&opts.SMTPHost, "smtp-host", "", "SMTP server sending the --email-to emails")
+	rootCmd.PersistentFlags().IntVar(&opts.SMTPPort, "smtp-port", 587, "Port of the SMTP server, 465 connects with TLS and other ports use STARTTLS when offered")
+	rootCmd.PersistentFlags().StringVar(&opts.SMTPUsername, "smtp-username", "", "Username to authenticate to the SMTP server with")
+	rootCmd.PersistentFlags().StringVar(&opts.SMTPPassword, "smtp-password", "", "Password to authenticate to the SMTP server with, defaults to $KOR_SMTP_PASSWORD")
+	rootCmd.PersistentFlags().Var(&deleteValue{opts: &opts}, "delete", "Delete unused resources, optionally only the given kinds, e.g. --delete=configmap,secret")
+	rootCmd.PersistentFlags().Lookup("delete").NoOptDefVal = "true"
+	rootCmd.PersistentFlags().StringVar(&generateScript, "generate-script", "", "Write the unused resources to this file as a deletion script to review instead of deleting them, see --script-format")
+	rootCmd.PersistentFlags().StringVar(&scriptFormat, "script-format", kor.ScriptFormatShell, "Format of --generate-script: shell for ordered kubectl delete commands, kustomize for $patch: delete patches that kustomize and Argo CD prune")
+	rootCmd.PersistentFlags().BoolVar(&quarantine, "quarantine", false, "Label unused resources with "+filters.QuarantineLabel+"=true and annotate them with "+filters.UnusedSinceKey+" instead of deleting them, see --quarantined-for")
+	rootCmd.PersistentFlags().StringVar(&dryRun, "dry-run", "", "With --delete, only preview the deletions: server sends dry-run requests that admission webhooks still review, client just prints them")
+	rootCmd.PersistentFlags().StringVar(&opts.ExportManifests, "export-manifests", "", "Directory or s3://bucket/prefix to write the YAML manifests of unused resources to, one file per namespace, before any deletion. Defaults to ~/.kor/backups/<time> with --delete")
+	rootCmd.PersistentFlags().StringVar(&cascade, "cascade", "", "With --delete, how dependents of deleted resources such as the Pods of a Deployment are handled: background or foreground deletes them, orphan keeps them. Defaults to the policy of each resource")
+	rootCmd.PersistentFlags().StringVar(&referenceRules, "reference-rules", "", "YAML file of rules giving the JSONPaths at which custom resources reference the ConfigMaps and Secrets they use")
+	rootCmd.PersistentFlags().StringSliceVar(&usageMarkers, "usage-markers", nil, "Extra kind:type:key[=value] markers of the ConfigMaps and Secrets used outside of the pod specs: a label or annotation of theirs, or a reference annotation of the workloads listing their names. Example: --usage-markers 'ConfigMap:label:dashboards=true,Secret:reference:example.com/secrets'")
+	rootCmd.PersistentFlags().StringSliceVar(&protect, "protect", nil, "Extra kind/namespace/name regular expressions of resources --delete, --quarantine and kor ui must not touch, an empty part matches anything. Example: --protect 'Secret/prod/.*,ConfigMap//ca-bundle'")
+	rootCmd.PersistentFlags().BoolVar(&force, "force", false, "Let --delete, --quarantine and kor ui touch protected resources, such as the kube-root-ca.crt ConfigMaps, default ServiceAccounts and anything in the system namespaces")
+	rootCmd.PersistentFlags().Float32Var(&deleteQPS, "delete-qps", 0, "Maximum number of deletion requests per second, 0 means no limit")
+	rootCmd.PersistentFlags().IntVar(&deleteBatchSize, "delete-batch-size", 0, "Pause for --delete-batch-pause after this many deletions, 0 deletes without pausing")
+	rootCmd.PersistentFlags().DurationVar(&deleteBatchPause, "delete-batch-pause", kor.DefaultDeleteBatchPause, "Pause between two batches of --delete-batch-size deletions")
+	rootCmd.PersistentFlags().BoolVar(&noBackup, "no-backup", false, "Do not back up the manifests of unused resources before deleting them")
+	rootCmd.PersistentFlags().BoolVar(&opts.NoInteractive, "no-interactive", false, "Do not prompt for confirmation when deleting resources (alias --yes). Be careful using this flag!")
+	rootCmd.PersistentFlags().BoolVar(&noBuiltinExceptions, "no-builtin-exceptions", false, "Also report the well-known objects kor never reports by default (kube-root-ca.crt, istio-ca-root-cert, default tokens, leader election locks...)")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored table output")
+	rootCmd.PersistentFlags().BoolVarP(&opts.Verbose, "verbose", "v", false, "Verbose output (print empty namespaces and log every API request)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "Format of the logs written to stderr (text or json)")
+	rootCmd.PersistentFlags().StringVar(&baseline, "baseline", "", "Path to an earlier json or yaml report, print the newly unused, still unused and resolved resources compared to it")
+	rootCmd.PersistentFlags().BoolVar(&exitCode, "exit-code", false, fmt.Sprintf("Exit with code %d when the number of unused resources exceeds --exit-code-threshold", unusedResourcesExitCode))
+	rootCmd.PersistentFlags().IntVar(&exitThreshold, "exit-code-threshold", 0, "Number of unused resources tolerated before --exit-code fails the run")
+	rootCmd.PersistentFlags().StringVar(&opts.GroupBy, "group-by", "namespace", "Group output by (namespace, resource or kind)")
+	rootCmd.PersistentFlags().StringVar(&opts.SortBy, "sort-by", "", "Sort table rows by (age, name, size, namespace)")
 	rootCmd.PersistentFlags().BoolVar(&opts.ShowReason, "show-reason", false, "Print reason resource is considered unused")
+	rootCmd.PersistentFlags().BoolVar(&opts.ShowAge, "show-age", false, "Print the age of unused resources")
+	rootCmd.PersistentFlags().BoolVar(&opts.ShowSize, "show-size", false, "Print the data size of unused ConfigMaps and Secrets and the capacity of unused volumes")
+	rootCmd.PersistentFlags().BoolVar(&opts.ShowCost, "show-cost", false, "Print the estimated monthly cost of unused volumes, LoadBalancer Services and nodes, with a summary of the total, from the prices of --pricing-file")
+	rootCmd.PersistentFlags().StringVar(&pricingFile, "pricing-file", "", "YAML file of the monthly prices of storage per storage class, LoadBalancers and nodes used by --show-cost, defaults to rough cloud list prices")
+	rootCmd.PersistentFlags().BoolVar(&opts.ShowSummary, "show-summary", false, "Print a summary of unused resources per resource type and namespace with the overall total")
+	rootCmd.PersistentFlags().BoolVar(&opts.ShowHelmSummary, "show-helm-summary", false, "Print a summary of unused resources per Helm release, the resources of no release are counted under <none>")
+	rootCmd.PersistentFlags().BoolVarP(&opts.Quiet, "quiet", "q", false, "Only print namespace/kind/name of unused resources, one per line (overrides --output)")
 	addFilterOptionsFlag(rootCmd, filterOptions)
+	rootCmd.SetGlobalNormalizationFunc(normalizeFlagAliases)
+	if isKubectlPlugin() {
+		// Usage and examples then read like the kubectl command users type
+		rootCmd.Annotations = map[string]string{cobra.CommandDisplayNameAnnotation: "kubectl kor"}
+	}
+	registerCompletions()
 }
 
-func Execute() {
-	_ = rootCmd.ParseFlags(os.Args)
-	if err := filterOptions.Validate(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error while validating filter options '%s'", err)
-		os.Exit(1)
+// flagAliases maps alternative long flag names to the flag they stand for,
+// so values passed through either name end up in the same flag
+var flagAliases = map[string]string{
+	"namespace": "include-namespaces",
+	"selector":  "include-labels",
+	// kubectl's name of --timeout, so kubectl habits keep working for the plugin
+	"request-timeout": "timeout",
+	"yes":             "no-interactive",
+}
+
+func normalizeFlagAliases(f *pflag.FlagSet, name string) pflag.NormalizedName {
+	if alias, ok := flagAliases[name]; ok {
+		name = alias
 	}
-	filterOptions.Modify()
-	if err := rootCmd.Execute(); err != nil {
+	return pflag.NormalizedName(name)
+}
+
+func Execute() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		// Abort pending requests and leave right away instead of waiting for them or for prompts
+		cancel()
+		kor.StopProgress()
+		stopProfile()
+		fmt.Fprintln(os.Stderr, "Interrupted")
+		os.Exit(interruptedExitCode)
+	}()
+
+	err := rootCmd.ExecuteContext(ctx)
+	kor.StopProgress()
+	stopProfile()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error while executing your CLI '%s'", err)
 		os.Exit(1)
 	}
 }
 
+// stopProfile writes the --profile of the run, it must be called before kor exits.
+func stopProfile() {
+	if err := kor.StopProfile(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error while writing the profile '%s'\n", err)
+	}
+}
+
 func addFilterOptionsFlag(cmd *cobra.Command, opts *filters.Options) {
 	cmd.PersistentFlags().StringSliceVarP(&opts.ExcludeLabels, "exclude-labels", "l", opts.ExcludeLabels, "Selector to filter out, Example: --exclude-labels key1=value1,key2=value2. If --include-labels is set, --exclude-labels will be ignored.")
-	cmd.PersistentFlags().StringVar(&opts.NewerThan, "newer-than", opts.NewerThan, "The maximum age of the resources to be considered unused. This flag cannot be used together with older-than flag. Example: --newer-than=1h2m")
-	cmd.PersistentFlags().StringVar(&opts.OlderThan, "older-than", opts.OlderThan, "The minimum age of the resources to be considered unused. This flag cannot be used together with newer-than flag. Example: --older-than=1h2m")
-	cmd.PersistentFlags().StringVar(&opts.IncludeLabels, "include-labels", opts.IncludeLabels, "Selector to filter in, Example: --include-labels key1=value1.(currently supports one label)")
+	cmd.PersistentFlags().StringVar(&opts.NewerThan, "newer-than", opts.NewerThan, "The maximum age of the resources to be considered unused. Accepts d and w besides the Go duration units. This flag cannot be used together with older-than flag. Example: --newer-than=1d12h")
+	cmd.PersistentFlags().StringVar(&opts.OlderThan, "older-than", opts.OlderThan, "The minimum age of the resources to be considered unused. Accepts d and w besides the Go duration units. This flag cannot be used together with newer-than flag. Example: --older-than=7d")
+	cmd.PersistentFlags().StringVar(&opts.QuarantinedFor, "quarantined-for", opts.QuarantinedFor, "Only consider resources quarantined with --quarantine at least this long ago, e.g. --quarantined-for=14d --delete")
+	cmd.PersistentFlags().StringVar(&opts.IncludeLabels, "include-labels", opts.IncludeLabels, "Label selector passed to the API server to only evaluate matching resources (alias --selector), Example: --include-labels team=payments,tier!=frontend")
 	cmd.PersistentFlags().StringSliceVarP(&opts.ExcludeNamespaces, "exclude-namespaces", "e", opts.ExcludeNamespaces, "Namespaces to be excluded, split by commas. Example: --exclude-namespaces ns1,ns2,ns3. If --include-namespaces is set, --exclude-namespaces will be ignored.")
-	cmd.PersistentFlags().StringSliceVarP(&opts.IncludeNamespaces, "include-namespaces", "n", opts.IncludeNamespaces, "Namespaces to run on, split by commas. Example: --include-namespaces ns1,ns2,ns3. If set, non-namespaced resources will be ignored.")
+	cmd.PersistentFlags().StringSliceVar(&opts.IncludeNames, "include-names", opts.IncludeNames, "Regular expressions matching the whole resource name, only matching resources are considered. Example: --include-names 'payments-.*'")
+	cmd.PersistentFlags().StringSliceVar(&opts.ExcludeNames, "exclude-names", opts.ExcludeNames, "Regular expressions matching the whole resource name, matching resources are skipped. Example: --exclude-names '.*-canary,istio-.*'")
+	cmd.PersistentFlags().StringVar(&opts.ExceptionsFile, "exceptions", opts.ExceptionsFile, "Path to a YAML file of approved exceptions (kind, namespace and name patterns with an optional expires date) that are never reported")
+	cmd.PersistentFlags().BoolVar(&opts.IgnoreHelmManaged, "ignore-helm-managed", opts.IgnoreHelmManaged, "Skip the resources of Helm releases (meta.helm.sh/release-name annotation or app.kubernetes.io/managed-by=Helm label)")
+	cmd.PersistentFlags().BoolVar(&opts.IgnoreOwned, "ignore-owned", opts.IgnoreOwned, "Skip the resources owned by another object (ownerReferences), such as the ConfigMaps generated by an operator or the Jobs of a CronJob")
+	cmd.PersistentFlags().BoolVar(&opts.IncludeSystemNamespaces, "include-system-namespaces", opts.IncludeSystemNamespaces, fmt.Sprintf("Also scan the system namespaces (%s), which are excluded by default", strings.Join(filters.SystemNamespaces, ", ")))
+	cmd.PersistentFlags().StringSliceVarP(&opts.IncludeNamespaces, "include-namespaces", "n", opts.IncludeNamespaces, "Namespaces to run on, repeatable or split by commas (alias --namespace). Example: -n ns1,ns2 -n ns3. If set, non-namespaced resources will be ignored.")
 }
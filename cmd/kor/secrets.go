@@ -6,9 +6,10 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/yonahd/kor/pkg/kor"
-	"github.com/yonahd/kor/pkg/utils"
 )
 
+var showUnusedSecretKeys bool
+
 var secretCmd = &cobra.Command{
 	Use:     "secret",
 	Aliases: []string{"secrets"},
@@ -17,15 +18,24 @@ var secretCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		clientset := kor.GetKubeClient(kubeConfig, kubeContext)
 
-		if response, err := kor.GetUnusedSecrets(filterOptions, clientset, outputFormat, opts); err != nil {
+		if showUnusedSecretKeys {
+			if response, err := kor.GetUnusedSecretKeys(cmd.Context(), filterOptions, clientset, outputFormat, opts); err != nil {
+				fmt.Println(err)
+			} else {
+				printResponse(cmd.Context(), response)
+			}
+			return
+		}
+
+		if response, err := kor.GetUnusedSecrets(cmd.Context(), filterOptions, clientset, outputFormat, opts); err != nil {
 			fmt.Println(err)
 		} else {
-			utils.PrintLogo(outputFormat)
-			fmt.Println(response)
+			printResponse(cmd.Context(), response)
 		}
 	},
 }
 
 func init() {
+	secretCmd.Flags().BoolVar(&showUnusedSecretKeys, "unused-keys", false, "Report unreferenced keys of used Secrets instead of unused Secrets")
 	rootCmd.AddCommand(secretCmd)
 }
@@ -0,0 +1,45 @@
+package kor
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/kor"
+)
+
+var (
+	serveResources     []string
+	serveListenAddress string
+	serveGRPCAddress   string
+	serveCacheTTL      time.Duration
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "serve a web dashboard and read-only REST API of unused resources",
+	Long:  "Serve the unused resources as JSON on /api/v1/unused, filtered with ?kind= and ?namespace=, and their counts per namespace and kind on /api/v1/summary. / serves a dashboard of the counts, history and resources, with exports from /api/v1/export. --grpc-listen-address serves the kor.v1.Kor gRPC service as well. Scans are reused for --cache-ttl, ?refresh=true scans again.",
+	Args:  cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		// The server keeps running, a status line would only clutter its logs
+		kor.StopProgress()
+		clientset := kor.GetKubeClient(kubeConfig, kubeContext)
+		apiExtClient := kor.GetAPIExtensionsClient(kubeConfig, kubeContext)
+		dynamicClient := kor.GetDynamicClient(kubeConfig, kubeContext)
+
+		if err := kor.Serve(cmd.Context(), filterOptions, clientset, apiExtClient, dynamicClient, opts, serveResources, serveListenAddress, serveGRPCAddress, serveCacheTTL); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	serveCmd.Flags().StringSliceVarP(&serveResources, "resources", "r", nil, "Comma-separated list of resources to scan (e.g., deployment,service), defaults to every resource")
+	serveCmd.Flags().StringVar(&serveListenAddress, "listen-address", kor.DefaultServerAddress, "Address to serve the dashboard, the API and /healthz on")
+	serveCmd.Flags().StringVar(&serveGRPCAddress, "grpc-listen-address", "", "Address to serve the gRPC API streaming the findings of scans on, e.g. :9090. Disabled when empty")
+	serveCmd.Flags().DurationVar(&serveCacheTTL, "cache-ttl", kor.DefaultServerCacheTTL, "How long the results of a scan answer requests before the cluster is scanned again, 0 scans on every request")
+	rootCmd.AddCommand(serveCmd)
+}
@@ -0,0 +1,29 @@
+package kor
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/kor"
+)
+
+var saTokenCmd = &cobra.Command{
+	Use:     "serviceaccounttoken",
+	Aliases: []string{"satoken", "satokens", "serviceaccounttokens"},
+	Short:   "Gets unused legacy serviceAccount token secrets",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		clientset := kor.GetKubeClient(kubeConfig, kubeContext)
+
+		if response, err := kor.GetUnusedServiceAccountTokens(cmd.Context(), filterOptions, clientset, outputFormat, opts); err != nil {
+			fmt.Println(err)
+		} else {
+			printResponse(cmd.Context(), response)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(saTokenCmd)
+}
@@ -0,0 +1,31 @@
+package kor
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/kor"
+)
+
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: "Browse unused resources interactively",
+	Long: `Scan the cluster like kor all, then browse the findings by namespace and kind,
+inspect their YAML and delete a batch of marked resources.`,
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		clientset := kor.GetKubeClient(kubeConfig, kubeContext)
+		apiExtClient := kor.GetAPIExtensionsClient(kubeConfig, kubeContext)
+		dynamicClient := kor.GetDynamicClient(kubeConfig, kubeContext)
+
+		if err := kor.RunUI(cmd.Context(), filterOptions, clientset, apiExtClient, dynamicClient, os.Stdin, os.Stdout); err != nil {
+			fmt.Println(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(uiCmd)
+}
@@ -1,20 +1,50 @@
 package kor
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
 
 	"github.com/yonahd/kor/pkg/utils"
 )
 
+var checkUpdate bool
+
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print kor version information",
 	Args:  cobra.ExactArgs(0),
 	Run: func(cmd *cobra.Command, args []string) {
-		utils.PrintVersion()
+		info := utils.GetBuildInfo()
+		switch outputFormat {
+		case "json":
+			output, _ := json.MarshalIndent(info, "", "  ")
+			fmt.Println(string(output))
+		case "yaml":
+			output, _ := yaml.Marshal(info)
+			fmt.Print(string(output))
+		default:
+			fmt.Println(info)
+		}
+
+		if checkUpdate {
+			latest, newer, err := utils.CheckLatestVersion()
+			switch {
+			case err != nil:
+				fmt.Fprintln(os.Stderr, err)
+			case newer:
+				fmt.Fprintf(os.Stderr, "A newer version of kor is available: v%s\n", latest)
+			default:
+				fmt.Fprintln(os.Stderr, "kor is up to date")
+			}
+		}
 	},
 }
 
 func init() {
+	versionCmd.Flags().BoolVar(&checkUpdate, "check-update", false, "Check GitHub for a newer kor release")
 	rootCmd.AddCommand(versionCmd)
 }
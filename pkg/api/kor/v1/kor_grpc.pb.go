@@ -0,0 +1,134 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: kor/v1/kor.proto
+
+package korv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Kor_Scan_FullMethodName = "/kor.v1.Kor/Scan"
+)
+
+// KorClient is the client API for Kor service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Kor scans the cluster for unused resources. It is served by
+// kor serve --grpc-listen-address.
+type KorClient interface {
+	// Scan streams the unused resources while the cluster is scanned. Scans
+	// run one at a time, and never delete anything.
+	Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Finding], error)
+}
+
+type korClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewKorClient(cc grpc.ClientConnInterface) KorClient {
+	return &korClient{cc}
+}
+
+func (c *korClient) Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Finding], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Kor_ServiceDesc.Streams[0], Kor_Scan_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ScanRequest, Finding]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Kor_ScanClient = grpc.ServerStreamingClient[Finding]
+
+// KorServer is the server API for Kor service.
+// All implementations must embed UnimplementedKorServer
+// for forward compatibility.
+//
+// Kor scans the cluster for unused resources. It is served by
+// kor serve --grpc-listen-address.
+type KorServer interface {
+	// Scan streams the unused resources while the cluster is scanned. Scans
+	// run one at a time, and never delete anything.
+	Scan(*ScanRequest, grpc.ServerStreamingServer[Finding]) error
+	mustEmbedUnimplementedKorServer()
+}
+
+// UnimplementedKorServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedKorServer struct{}
+
+func (UnimplementedKorServer) Scan(*ScanRequest, grpc.ServerStreamingServer[Finding]) error {
+	return status.Errorf(codes.Unimplemented, "method Scan not implemented")
+}
+func (UnimplementedKorServer) mustEmbedUnimplementedKorServer() {}
+func (UnimplementedKorServer) testEmbeddedByValue()             {}
+
+// UnsafeKorServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to KorServer will
+// result in compilation errors.
+type UnsafeKorServer interface {
+	mustEmbedUnimplementedKorServer()
+}
+
+func RegisterKorServer(s grpc.ServiceRegistrar, srv KorServer) {
+	// If the following call pancis, it indicates UnimplementedKorServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Kor_ServiceDesc, srv)
+}
+
+func _Kor_Scan_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ScanRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KorServer).Scan(m, &grpc.GenericServerStream[ScanRequest, Finding]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Kor_ScanServer = grpc.ServerStreamingServer[Finding]
+
+// Kor_ServiceDesc is the grpc.ServiceDesc for Kor service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Kor_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "kor.v1.Kor",
+	HandlerType: (*KorServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Scan",
+			Handler:       _Kor_Scan_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "kor/v1/kor.proto",
+}
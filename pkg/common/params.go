@@ -7,6 +7,45 @@ type Opts struct {
 	WebhookURL    string
 	Channel       string
 	Token         string
-	GroupBy       string
-	ShowReason    bool
+	// TeamsWebhookURL and DiscordWebhookURL receive a summary of the report
+	TeamsWebhookURL   string
+	DiscordWebhookURL string
+	// ResultWebhookURL receives the whole report as JSON, with the ResultWebhookHeaders
+	ResultWebhookURL     string
+	ResultWebhookHeaders []string
+	// EmailTo receives the summary with the report attached in the EmailAttach formats
+	EmailTo      []string
+	EmailFrom    string
+	EmailAttach  []string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	// GitHubRepo and JiraProject get an issue per IssueGroupBy namespace or
+	// team, assigned to the owners listed in IssueOwnersFile
+	GitHubRepo      string
+	GitHubToken     string
+	JiraURL         string
+	JiraProject     string
+	JiraUser        string
+	JiraToken       string
+	JiraIssueType   string
+	IssueGroupBy    string
+	IssueTeamLabel  string
+	IssueOwnersFile string
+	GroupBy         string
+	ShowReason      bool
+	Quiet           bool
+	ShowSummary     bool
+	// ShowHelmSummary counts the unused resources per Helm release
+	ShowHelmSummary bool
+	ShowAge         bool
+	ShowSize        bool
+	// ShowCost estimates the monthly cost of the unused resources
+	ShowCost        bool
+	Wide            bool
+	SortBy          string
+	ExportManifests string
+	// DeleteKinds limits --delete to these lowercase kinds, empty deletes every kind
+	DeleteKinds []string
 }
@@ -0,0 +1,135 @@
+package filters
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+// ExceptionsFile is the layout of the file passed with --exceptions
+type ExceptionsFile struct {
+	Exceptions []Exception `json:"exceptions"`
+}
+
+// Exception is an approved exclusion, resources matching it are never reported.
+// Kind, Namespace and Name are regular expressions matching the whole value, an empty one matches anything.
+// Kind is matched case-insensitively. Once Expires (2006-01-02 or RFC3339) has passed the exception no longer applies.
+type Exception struct {
+	Kind      string `json:"kind,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Expires   string `json:"expires,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+type exceptionRule struct {
+	kind      *regexp.Regexp
+	namespace *regexp.Regexp
+	name      *regexp.Regexp
+	expiresAt time.Time
+}
+
+// ExceptionFilter is a filter that filters out resources matching an exception of the ExceptionsFile which has not expired
+func ExceptionFilter(object runtime.Object, opts *Options) bool {
+	meta, ok := object.(metav1.Object)
+	if !ok {
+		return false
+	}
+	rules, err := opts.exceptionRules()
+	if err != nil || len(rules) == 0 {
+		return false
+	}
+
+	kind := objectKind(object)
+	now := time.Now()
+	for _, rule := range rules {
+		if !rule.expiresAt.IsZero() && !now.Before(rule.expiresAt) {
+			continue
+		}
+		if rule.kind.MatchString(kind) && rule.namespace.MatchString(meta.GetNamespace()) && rule.name.MatchString(meta.GetName()) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadExceptions reads an exceptions file
+func LoadExceptions(path string) ([]Exception, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exceptions file: %w", err)
+	}
+	var file ExceptionsFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse exceptions file %s: %w", path, err)
+	}
+	return file.Exceptions, nil
+}
+
+// exceptionRules loads and compiles the ExceptionsFile, only called once
+func (o *Options) exceptionRules() ([]exceptionRule, error) {
+	o.exceptionsOnce.Do(func() {
+		if o.ExceptionsFile == "" {
+			return
+		}
+		exceptions, err := LoadExceptions(o.ExceptionsFile)
+		if err != nil {
+			o.exceptionsErr = err
+			return
+		}
+		for i, exception := range exceptions {
+			rule, err := compileException(exception)
+			if err != nil {
+				o.exceptionsErr = fmt.Errorf("invalid exception %d in %s: %w", i+1, o.ExceptionsFile, err)
+				return
+			}
+			o.exceptions = append(o.exceptions, rule)
+		}
+	})
+	return o.exceptions, o.exceptionsErr
+}
+
+func compileException(exception Exception) (exceptionRule, error) {
+	var rule exceptionRule
+	patterns, err := compileNamePatterns([]string{
+		"(?i:" + orAny(exception.Kind) + ")",
+		orAny(exception.Namespace),
+		orAny(exception.Name),
+	})
+	if err != nil {
+		return rule, err
+	}
+	rule.kind, rule.namespace, rule.name = patterns[0], patterns[1], patterns[2]
+
+	if exception.Expires != "" {
+		if rule.expiresAt, err = parseExpires(exception.Expires); err != nil {
+			return rule, fmt.Errorf("invalid expires %q, use 2006-01-02 or RFC3339", exception.Expires)
+		}
+	}
+	return rule, nil
+}
+
+func orAny(pattern string) string {
+	if pattern == "" {
+		return ".*"
+	}
+	return pattern
+}
+
+// objectKind returns the kind of the object, typed objects listed through the clientset have an empty TypeMeta
+// so their kind is looked up in the client-go scheme
+func objectKind(object runtime.Object) string {
+	if kind := object.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+		return kind
+	}
+	if gvks, _, err := scheme.Scheme.ObjectKinds(object); err == nil && len(gvks) > 0 {
+		return gvks[0].Kind
+	}
+	return ""
+}
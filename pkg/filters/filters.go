@@ -10,21 +10,61 @@ import (
 )
 
 const (
-	LabelFilterName    = "label"
-	AgeFilterName      = "age"
-	KorLabelFilterName = "korlabel"
+	LabelFilterName      = "label"
+	AgeFilterName        = "age"
+	KorLabelFilterName   = "korlabel"
+	NameFilterName       = "name"
+	ExceptionFilterName  = "exception"
+	QuarantineFilterName = "quarantine"
+	OwnerFilterName      = "owner"
+	HelmFilterName       = "helm"
 )
 
-// KorLabelFilter is a filter that filters out resources that are ["kor/used"] != "true"
+const (
+	// KorUsedKey is the label or annotation marking a resource as used, kor then never reports it
+	KorUsedKey = "kor/used"
+	// KorExpiresKey is the annotation ending a KorUsedKey opt-out at the given date (2006-01-02 or RFC3339)
+	KorExpiresKey = "kor/expires"
+	// HelmReleaseNameKey is the annotation Helm sets on the objects of a release
+	HelmReleaseNameKey = "meta.helm.sh/release-name"
+	// ManagedByKey is the label naming the tool managing an object, Helm sets it to "Helm"
+	ManagedByKey = "app.kubernetes.io/managed-by"
+)
+
+// KorLabelFilter is a filter that filters out resources labeled or annotated with kor/used=true
+// whose kor/expires annotation, if any, has not passed yet
 func KorLabelFilter(object runtime.Object, opts *Options) bool {
 	if meta, ok := object.(metav1.Object); ok {
-		if meta.GetLabels()["kor/used"] == "true" {
-			return true
-		}
+		return IsMarkedUsed(meta, time.Now())
 	}
 	return false
 }
 
+// IsMarkedUsed checks if the resource opted out of kor reports at the given time
+func IsMarkedUsed(meta metav1.Object, now time.Time) bool {
+	if meta.GetLabels()[KorUsedKey] != "true" && meta.GetAnnotations()[KorUsedKey] != "true" {
+		return false
+	}
+
+	expires, ok := meta.GetAnnotations()[KorExpiresKey]
+	if !ok {
+		return true
+	}
+	// An unreadable expiry keeps the opt-out, so a typo never gets a resource deleted
+	expiresAt, err := parseExpires(expires)
+	if err != nil {
+		return true
+	}
+	return now.Before(expiresAt)
+}
+
+func parseExpires(value string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
 // LabelFilter is a filter that filters out resources by label
 func LabelFilter(object runtime.Object, opts *Options) bool {
 	if meta, ok := object.(metav1.Object); ok {
@@ -35,6 +75,40 @@ func LabelFilter(object runtime.Object, opts *Options) bool {
 	return false
 }
 
+// NameFilter is a filter that filters out resources whose name matches one of the ExcludeNames patterns,
+// or matches none of the IncludeNames patterns when any are set
+func NameFilter(object runtime.Object, opts *Options) bool {
+	if meta, ok := object.(metav1.Object); ok {
+		if included, err := HasIncludedName(meta.GetName(), opts); err == nil {
+			return !included
+		}
+	}
+	return false
+}
+
+// OwnerFilter is a filter that filters out resources owned by another object when IgnoreOwned is set,
+// their lifecycle is managed by their owner
+func OwnerFilter(object runtime.Object, opts *Options) bool {
+	if meta, ok := object.(metav1.Object); ok {
+		return opts.IgnoreOwned && len(meta.GetOwnerReferences()) > 0
+	}
+	return false
+}
+
+// HelmFilter is a filter that filters out resources managed by a Helm release when IgnoreHelmManaged is set,
+// they are removed by uninstalling or upgrading the release
+func HelmFilter(object runtime.Object, opts *Options) bool {
+	if meta, ok := object.(metav1.Object); ok {
+		return opts.IgnoreHelmManaged && IsHelmManaged(meta)
+	}
+	return false
+}
+
+// IsHelmManaged checks if the resource belongs to a Helm release, from its release annotation or managed-by label
+func IsHelmManaged(meta metav1.Object) bool {
+	return meta.GetAnnotations()[HelmReleaseNameKey] != "" || meta.GetLabels()[ManagedByKey] == "Helm"
+}
+
 // AgeFilter is a filter that filters out resources by age
 func AgeFilter(object runtime.Object, opts *Options) bool {
 	if meta, ok := object.(metav1.Object); ok {
@@ -89,7 +163,7 @@ func HasIncludedAge(creationTime metav1.Time, filterOpts *Options) (bool, error)
 
 	// Parse the older-than flag value into a time.Duration value
 	if filterOpts.OlderThan != "" {
-		olderThan, err := time.ParseDuration(filterOpts.OlderThan)
+		olderThan, err := ParseDuration(filterOpts.OlderThan)
 		if err != nil {
 			return false, err
 		}
@@ -98,7 +172,7 @@ func HasIncludedAge(creationTime metav1.Time, filterOpts *Options) (bool, error)
 
 	// Parse the newer-than flag value into a time.Duration value
 	if filterOpts.NewerThan != "" {
-		newerThan, err := time.ParseDuration(filterOpts.NewerThan)
+		newerThan, err := ParseDuration(filterOpts.NewerThan)
 		if err != nil {
 			return false, err
 		}
@@ -107,3 +181,28 @@ func HasIncludedAge(creationTime metav1.Time, filterOpts *Options) (bool, error)
 
 	return true, nil
 }
+
+// HasIncludedName checks if a resource name passes the IncludeNames and ExcludeNames patterns of the filter options.
+// Patterns are regular expressions that have to match the whole name, ExcludeNames takes precedence over IncludeNames.
+func HasIncludedName(name string, filterOpts *Options) (bool, error) {
+	includes, excludes, err := filterOpts.nameRegexps()
+	if err != nil {
+		return false, err
+	}
+
+	for _, exclude := range excludes {
+		if exclude.MatchString(name) {
+			return false, nil
+		}
+	}
+
+	if len(includes) == 0 {
+		return true, nil
+	}
+	for _, include := range includes {
+		if include.MatchString(name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
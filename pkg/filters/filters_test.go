@@ -1,12 +1,23 @@
 package filters
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
 	"testing"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestLabelFilter(t *testing.T) {
@@ -177,3 +188,391 @@ func TestKorLabelFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestNamespacesInclude(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns2"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns3"}},
+	)
+	opts := &Options{IncludeNamespaces: []string{"ns1", "ns3", "ns1", "missing"}}
+
+	got := opts.Namespaces(context.Background(), clientset)
+	sort.Strings(got)
+	if want := []string{"ns1", "ns3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Namespaces() = %v, want %v", got, want)
+	}
+}
+
+func TestNamespacesIncludeForbidden(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1"}})
+	clientset.PrependReactor("get", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, action.(k8stesting.GetAction).GetName(), errors.New("denied"))
+	})
+	opts := &Options{IncludeNamespaces: []string{"ns1", "ns2"}}
+
+	got := opts.Namespaces(context.Background(), clientset)
+	sort.Strings(got)
+	if want := []string{"ns1", "ns2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Namespaces() = %v, want %v", got, want)
+	}
+}
+
+func TestCloneNamespaces(t *testing.T) {
+	opts := &Options{ExcludeNamespaces: []string{"legacy"}, IgnoreOwned: true}
+	if got := opts.Namespaces(context.Background(), fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1"}})); !reflect.DeepEqual(got, []string{"ns1"}) {
+		t.Fatalf("Namespaces() = %v, want [ns1]", got)
+	}
+
+	clone := opts.Clone()
+	if !reflect.DeepEqual(clone.ExcludeNamespaces, opts.ExcludeNamespaces) || !clone.IgnoreOwned {
+		t.Errorf("Clone() = %+v, want the filters of %+v", clone, opts)
+	}
+	got := clone.Namespaces(context.Background(), fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns2"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "legacy"}},
+	))
+	if want := []string{"ns2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Namespaces() of the clone = %v, want %v", got, want)
+	}
+}
+
+func TestNamespacesExcludeSystem(t *testing.T) {
+	newClientset := func() *fake.Clientset {
+		return fake.NewSimpleClientset(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-public"}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-node-lease"}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payments"}},
+		)
+	}
+	tests := []struct {
+		name string
+		opts *Options
+		want []string
+	}{
+		{
+			name: "system namespaces excluded by default",
+			opts: &Options{ExcludeNamespaces: []string{"payments"}},
+			want: []string{"default"},
+		},
+		{
+			name: "include system namespaces",
+			opts: &Options{IncludeSystemNamespaces: true},
+			want: []string{"default", "kube-node-lease", "kube-public", "kube-system", "payments"},
+		},
+		{
+			name: "explicitly included system namespace",
+			opts: &Options{IncludeNamespaces: []string{"kube-system"}},
+			want: []string{"kube-system"},
+		},
+	}
+	for _, tt := range tests {
+		got := tt.opts.Namespaces(context.Background(), newClientset())
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s Namespaces() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestListOptions(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          *Options
+		labelSelector string
+		fieldSelector string
+	}{
+		{name: "no filters", opts: &Options{}},
+		{name: "include labels", opts: &Options{IncludeLabels: "team=payments", ExcludeLabels: []string{"tier=frontend"}}, labelSelector: "team=payments"},
+		{
+			name:          "negated exclude labels",
+			opts:          &Options{ExcludeLabels: []string{"tier=frontend", "env!=prod", "legacy", "!keep", "zone in (a,b)", "a=1,b=2", "size>3"}},
+			labelSelector: "tier!=frontend,env=prod,!legacy,keep,zone notin (a,b)",
+		},
+		{name: "plain names", opts: &Options{IncludeNames: []string{"app-config"}, ExcludeNames: []string{"old", "tmp-.*"}}, fieldSelector: "metadata.name=app-config,metadata.name!=old"},
+		{name: "name patterns", opts: &Options{IncludeNames: []string{"app-.*"}, ExcludeNames: []string{"kube-root-ca.crt"}}},
+		{name: "several included names", opts: &Options{IncludeNames: []string{"a", "b"}}},
+	}
+	for _, tt := range tests {
+		options := tt.opts.ListOptions()
+		if options.LabelSelector != tt.labelSelector || options.FieldSelector != tt.fieldSelector {
+			t.Errorf("%s ListOptions() = %q %q, want %q %q", tt.name, options.LabelSelector, options.FieldSelector, tt.labelSelector, tt.fieldSelector)
+		}
+	}
+}
+
+func TestValidateIncludeLabels(t *testing.T) {
+	if err := (&Options{IncludeLabels: "team=payments,tier!=frontend"}).Validate(); err != nil {
+		t.Errorf("Validate() unexpected error for a valid selector: %v", err)
+	}
+	if err := (&Options{IncludeLabels: "team=payments,=broken"}).Validate(); err == nil {
+		t.Errorf("Validate() expected an error for an invalid selector")
+	}
+}
+
+func TestNameFilter(t *testing.T) {
+	object := func(name string) runtime.Object {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+	tests := []struct {
+		name   string
+		object runtime.Object
+		opts   *Options
+		want   bool
+	}{
+		{
+			name:   "no patterns",
+			object: object("app-config"),
+			opts:   &Options{},
+			want:   false,
+		},
+		{
+			name:   "excluded name",
+			object: object("payments-canary"),
+			opts:   &Options{ExcludeNames: []string{".*-canary", "istio-.*"}},
+			want:   true,
+		},
+		{
+			name:   "partial match is not excluded",
+			object: object("payments-canary-config"),
+			opts:   &Options{ExcludeNames: []string{".*-canary"}},
+			want:   false,
+		},
+		{
+			name:   "included name",
+			object: object("payments-config"),
+			opts:   &Options{IncludeNames: []string{"payments-.*"}},
+			want:   false,
+		},
+		{
+			name:   "not included name",
+			object: object("checkout-config"),
+			opts:   &Options{IncludeNames: []string{"payments-.*"}},
+			want:   true,
+		},
+		{
+			name:   "exclude takes precedence",
+			object: object("payments-canary"),
+			opts:   &Options{IncludeNames: []string{"payments-.*"}, ExcludeNames: []string{".*-canary"}},
+			want:   true,
+		},
+	}
+	for _, tt := range tests {
+		if got := NameFilter(tt.object, tt.opts); got != tt.want {
+			t.Errorf("%s NameFilter() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if err := (&Options{ExcludeNames: []string{"("}}).Validate(); err == nil {
+		t.Errorf("Validate() expected an error for an invalid name pattern")
+	}
+}
+
+func TestIsMarkedUsed(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		meta metav1.ObjectMeta
+		want bool
+	}{
+		{
+			name: "kor/used annotation",
+			meta: metav1.ObjectMeta{Annotations: map[string]string{KorUsedKey: "true"}},
+			want: true,
+		},
+		{
+			name: "opt-out not expired yet",
+			meta: metav1.ObjectMeta{Annotations: map[string]string{KorUsedKey: "true", KorExpiresKey: "2024-07-01"}},
+			want: true,
+		},
+		{
+			name: "expired opt-out",
+			meta: metav1.ObjectMeta{Labels: map[string]string{KorUsedKey: "true"}, Annotations: map[string]string{KorExpiresKey: "2024-06-01T00:00:00Z"}},
+			want: false,
+		},
+		{
+			name: "unreadable expiry keeps the opt-out",
+			meta: metav1.ObjectMeta{Annotations: map[string]string{KorUsedKey: "true", KorExpiresKey: "next week"}},
+			want: true,
+		},
+		{
+			name: "expiry without opt-out",
+			meta: metav1.ObjectMeta{Annotations: map[string]string{KorExpiresKey: "2024-07-01"}},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		if got := IsMarkedUsed(&corev1.ConfigMap{ObjectMeta: tt.meta}, now); got != tt.want {
+			t.Errorf("%s IsMarkedUsed() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExceptionFilter(t *testing.T) {
+	exceptionsFile := filepath.Join(t.TempDir(), "exceptions.yaml")
+	content := `exceptions:
+  - kind: configmap
+    namespace: payments
+    name: legacy-.*
+  - name: expired
+    expires: 2000-01-01
+  - kind: Deployment
+    name: canary
+    expires: 2999-01-01T00:00:00Z
+`
+	if err := os.WriteFile(exceptionsFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	opts := &Options{ExceptionsFile: exceptionsFile}
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		object runtime.Object
+		want   bool
+	}{
+		{
+			name:   "matching configmap",
+			object: &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "legacy-config", Namespace: "payments"}},
+			want:   true,
+		},
+		{
+			name:   "other namespace",
+			object: &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "legacy-config", Namespace: "checkout"}},
+			want:   false,
+		},
+		{
+			name:   "other kind",
+			object: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "legacy-config", Namespace: "payments"}},
+			want:   false,
+		},
+		{
+			name:   "expired exception",
+			object: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "expired", Namespace: "payments"}},
+			want:   false,
+		},
+		{
+			name: "unstructured object",
+			object: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]interface{}{"name": "canary", "namespace": "payments"},
+			}},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		if got := ExceptionFilter(tt.object, opts); got != tt.want {
+			t.Errorf("%s ExceptionFilter() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if err := os.WriteFile(exceptionsFile, []byte("exceptions:\n  - name: x\n    expires: soon\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&Options{ExceptionsFile: exceptionsFile}).Validate(); err == nil {
+		t.Errorf("Validate() expected an error for an invalid expires date")
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "1h2m", want: time.Hour + 2*time.Minute},
+		{value: "7d", want: 7 * 24 * time.Hour},
+		{value: "1w2d12h", want: 9*24*time.Hour + 12*time.Hour},
+		{value: "1.5d", wantErr: true},
+		{value: "7days", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDuration(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDuration(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	if err := (&Options{OlderThan: "7d", NewerThan: "1h"}).Validate(); err == nil {
+		t.Errorf("Validate() expected an error when both older-than and newer-than are set")
+	}
+}
+
+func TestQuarantineFilter(t *testing.T) {
+	quarantined := func(since time.Duration) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{UnusedSinceKey: time.Now().Add(-since).UTC().Format(time.RFC3339)},
+		}}
+	}
+	tests := []struct {
+		name           string
+		object         runtime.Object
+		quarantinedFor string
+		want           bool
+	}{
+		{"no grace period", &corev1.ConfigMap{}, "", false},
+		{"not quarantined", &corev1.ConfigMap{}, "14d", true},
+		{"quarantined for the grace period", quarantined(15 * 24 * time.Hour), "14d", false},
+		{"quarantined recently", quarantined(time.Hour), "14d", true},
+		{"unreadable annotation", &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{UnusedSinceKey: "yesterday"}}}, "1d", true},
+	}
+	for _, tt := range tests {
+		if got := QuarantineFilter(tt.object, &Options{QuarantinedFor: tt.quarantinedFor}); got != tt.want {
+			t.Errorf("%s QuarantineFilter() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if err := (&Options{QuarantinedFor: "two weeks"}).Validate(); err == nil {
+		t.Error("Expected an error for an invalid quarantined-for duration")
+	}
+}
+
+func TestOwnerFilter(t *testing.T) {
+	owned := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		OwnerReferences: []metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "CronJob", Name: "backup"}},
+	}}
+	tests := []struct {
+		name        string
+		object      runtime.Object
+		ignoreOwned bool
+		want        bool
+	}{
+		{"owned", owned, true, true},
+		{"not owned", &corev1.ConfigMap{}, true, false},
+		{"owned kept without ignore-owned", owned, false, false},
+	}
+	for _, tt := range tests {
+		if got := OwnerFilter(tt.object, &Options{IgnoreOwned: tt.ignoreOwned}); got != tt.want {
+			t.Errorf("%s OwnerFilter() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHelmFilter(t *testing.T) {
+	tests := []struct {
+		name              string
+		object            runtime.Object
+		ignoreHelmManaged bool
+		want              bool
+	}{
+		{"release annotation", &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{HelmReleaseNameKey: "payments"}}}, true, true},
+		{"managed-by label", &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{ManagedByKey: "Helm"}}}, true, true},
+		{"managed by another tool", &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{ManagedByKey: "kustomize"}}}, true, false},
+		{"kept without ignore-helm-managed", &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{ManagedByKey: "Helm"}}}, false, false},
+	}
+	for _, tt := range tests {
+		if got := HelmFilter(tt.object, &Options{IgnoreHelmManaged: tt.ignoreHelmManaged}); got != tt.want {
+			t.Errorf("%s HelmFilter() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -8,7 +8,7 @@ import (
 // If the resource is legal, return true
 // example:
 // deployment.Spec.Replicas > 0; return true
-// meta.GetLabels()["kor/used"] == "true"; return true
+// meta.GetLabels()[KorUsedKey] == "true"; return true
 type FilterFunc func(object runtime.Object, opts *Options) bool
 
 // Framework is a filter framework
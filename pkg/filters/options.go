@@ -4,14 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
+	"log/slog"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/client-go/kubernetes"
+
+	"github.com/yonahd/kor/pkg/utils"
 )
 
 // Options represents the flags and options for filtering unused Kubernetes resources, such as pods, services, or configmaps.
@@ -28,21 +35,52 @@ type Options struct {
 	OlderThan string
 	// NewerThan is the maximum age of the resources to be considered unused
 	NewerThan string
-	// ExcludeLabels is a label selector to exclude resources with matching labels
+	// ExcludeLabels is a label selector to exclude resources with matching labels, the ones of a single requirement
+	// are passed negated to the List calls
 	// IncludeLabels conflicts with it, and when setting IncludeLabels, ExcludeLabels is ignored and set to empty
 	ExcludeLabels []string
-	// IncludeLabels is a label selector to include resources with matching labels
+	// IncludeLabels is a label selector to include resources with matching labels, it is passed to the List calls
+	// so only matching resources are fetched from the API server
 	IncludeLabels string
 	// ExcludeNamespaces is a namespace selector to exclude resources in matching namespaces
 	// IncludeNamespaces conflicts with it, and when setting IncludeNamespaces, ExcludeNamespaces is ignored and set to empty
 	ExcludeNamespaces []string
 	// IncludeNamespaces is a namespace selector to include resources in matching namespaces
 	IncludeNamespaces []string
+	// IncludeSystemNamespaces scans the SystemNamespaces as well, which are skipped by default
+	// unless they are listed in IncludeNamespaces
+	IncludeSystemNamespaces bool
+	// IncludeNames are regular expressions, when set only resources with a matching name are considered
+	IncludeNames []string
+	// ExcludeNames are regular expressions, resources with a matching name are never reported
+	ExcludeNames []string
+	// ExceptionsFile is the path of a YAML file listing approved exceptions, see Exception
+	ExceptionsFile string
+	// QuarantinedFor is the minimum time resources must have been quarantined for to be considered unused,
+	// resources that were not quarantined are skipped when it is set
+	QuarantinedFor string
+	// IgnoreOwned skips the resources with an OwnerReference, such as the ConfigMaps generated by an operator
+	// or the Jobs of a CronJob, which are cleaned up through their owner
+	IgnoreOwned bool
+	// IgnoreHelmManaged skips the resources of Helm releases, which are cleaned up with helm instead
+	IgnoreHelmManaged bool
 
 	namespace []string
 	once      sync.Once
+
+	includeNames []*regexp.Regexp
+	excludeNames []*regexp.Regexp
+	namesErr     error
+	namesOnce    sync.Once
+
+	exceptions     []exceptionRule
+	exceptionsErr  error
+	exceptionsOnce sync.Once
 }
 
+// SystemNamespaces are the namespaces managed by Kubernetes itself, they are excluded from scans by default
+var SystemNamespaces = []string{"kube-system", "kube-public", "kube-node-lease"}
+
 // NewFilterOptions returns a new FilterOptions instance with default values
 func NewFilterOptions() *Options {
 	return &Options{
@@ -51,6 +89,26 @@ func NewFilterOptions() *Options {
 	}
 }
 
+// Clone returns a copy of the options, without the namespaces looked up so far, for the scan of another cluster
+// or a later scan of the same one
+func (o *Options) Clone() *Options {
+	return &Options{
+		OlderThan:               o.OlderThan,
+		NewerThan:               o.NewerThan,
+		ExcludeLabels:           o.ExcludeLabels,
+		IncludeLabels:           o.IncludeLabels,
+		ExcludeNamespaces:       o.ExcludeNamespaces,
+		IncludeNamespaces:       o.IncludeNamespaces,
+		IncludeSystemNamespaces: o.IncludeSystemNamespaces,
+		IncludeNames:            o.IncludeNames,
+		ExcludeNames:            o.ExcludeNames,
+		ExceptionsFile:          o.ExceptionsFile,
+		QuarantinedFor:          o.QuarantinedFor,
+		IgnoreOwned:             o.IgnoreOwned,
+		IgnoreHelmManaged:       o.IgnoreHelmManaged,
+	}
+}
+
 func parseLabels(labelsStr string) (labels.Set, error) {
 	labelMap := map[string]string{}
 
@@ -67,6 +125,37 @@ func parseLabels(labelsStr string) (labels.Set, error) {
 	return labels.Set(labelMap), nil
 }
 
+// durationUnits are the units ParseDuration accepts on top of the ones of time.ParseDuration
+var durationUnits = map[string]time.Duration{
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+var durationComponent = regexp.MustCompile(`(\d+)([dw])`)
+
+// ParseDuration parses a duration like time.ParseDuration, additionally accepting days (d) and weeks (w),
+// so ages can be given as 7d or 1w2d12h
+func ParseDuration(value string) (time.Duration, error) {
+	var total time.Duration
+	rest := durationComponent.ReplaceAllStringFunc(value, func(component string) string {
+		match := durationComponent.FindStringSubmatch(component)
+		count, _ := strconv.Atoi(match[1])
+		total += time.Duration(count) * durationUnits[match[2]]
+		return ""
+	})
+	if rest == "" {
+		if value == "" {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		return total, nil
+	}
+	remaining, err := time.ParseDuration(rest)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	return total + remaining, nil
+}
+
 // Validate makes sure provided values for FilterOptions are valid
 func (o *Options) Validate() error {
 
@@ -77,9 +166,28 @@ func (o *Options) Validate() error {
 		}
 	}
 
+	// The include selector is sent as is to the API server, reject it early if it can't be parsed
+	if o.IncludeLabels != "" {
+		if _, err := labels.Parse(o.IncludeLabels); err != nil {
+			return err
+		}
+	}
+
+	if _, _, err := o.nameRegexps(); err != nil {
+		return err
+	}
+
+	if _, err := o.exceptionRules(); err != nil {
+		return err
+	}
+
+	if o.OlderThan != "" && o.NewerThan != "" {
+		return errors.New("OlderThan and NewerThan cannot be used together")
+	}
+
 	// Parse the older-than flag value into a time.Duration value
 	if o.OlderThan != "" {
-		olderThan, err := time.ParseDuration(o.OlderThan)
+		olderThan, err := ParseDuration(o.OlderThan)
 		if err != nil {
 			return err
 		}
@@ -90,7 +198,7 @@ func (o *Options) Validate() error {
 
 	// Parse the newer-than flag value into a time.Duration value
 	if o.NewerThan != "" {
-		newerThan, err := time.ParseDuration(o.NewerThan)
+		newerThan, err := ParseDuration(o.NewerThan)
 		if err != nil {
 			return err
 		}
@@ -99,6 +207,16 @@ func (o *Options) Validate() error {
 		}
 	}
 
+	if o.QuarantinedFor != "" {
+		quarantinedFor, err := ParseDuration(o.QuarantinedFor)
+		if err != nil {
+			return err
+		}
+		if quarantinedFor < 0 {
+			return errors.New("QuarantinedFor must be a non-negative duration")
+		}
+	}
+
 	return nil
 }
 
@@ -107,13 +225,13 @@ func (o *Options) Modify() {
 	o.modifyLabels()
 }
 
-// Namespaces returns the namespaces, only called once
-func (o *Options) Namespaces(clientset kubernetes.Interface) []string {
+// Namespaces returns the namespaces, they are only looked up with ctx once
+func (o *Options) Namespaces(ctx context.Context, clientset kubernetes.Interface) []string {
 	o.once.Do(func() {
 		namespaces := make([]string, 0)
 		namespacesMap := make(map[string]bool)
 		if len(o.IncludeNamespaces) > 0 && len(o.ExcludeNamespaces) > 0 {
-			fmt.Fprintf(os.Stderr, "Exclude namespaces can't be used together with include namespaces. Ignoring --exclude-namespaces (-e) flag\n")
+			slog.Warn("Exclude namespaces can't be used together with include namespaces. Ignoring --exclude-namespaces (-e) flag")
 			o.ExcludeNamespaces = nil
 		}
 		includeNamespaces := o.IncludeNamespaces
@@ -123,17 +241,19 @@ func (o *Options) Namespaces(clientset kubernetes.Interface) []string {
 
 			for _, ns := range includeNamespaces {
 
-				_, err := clientset.CoreV1().Namespaces().Get(context.TODO(), ns, metav1.GetOptions{})
-				if err == nil {
+				_, err := clientset.CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{})
+				// A Role of the namespace does not allow getting it, the scans
+				// tell which of its resources are forbidden
+				if err == nil || apierrors.IsForbidden(err) {
 					namespacesMap[ns] = true
 				} else {
-					fmt.Fprintf(os.Stderr, "namespace [%s] not found\n", ns)
+					slog.Warn("Namespace not found", "namespace", ns)
 				}
 			}
 		} else {
-			namespaceList, err := clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+			namespaceList, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.CoreV1().Namespaces().List)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to retrieve namespaces: %v\n", err)
+				slog.Error("Failed to retrieve namespaces, use --include-namespaces to scan the namespaces kor has access to", "error", err)
 				return
 			}
 
@@ -144,6 +264,9 @@ func (o *Options) Namespaces(clientset kubernetes.Interface) []string {
 			for _, ns := range namespaceList.Items {
 				namespacesMap[ns.Name] = true
 			}
+			if !o.IncludeSystemNamespaces {
+				excludeNamespaces = append(excludeNamespaces, SystemNamespaces...)
+			}
 			for _, ns := range excludeNamespaces {
 				if _, exists := namespacesMap[ns]; exists {
 					namespacesMap[ns] = false
@@ -160,11 +283,106 @@ func (o *Options) Namespaces(clientset kubernetes.Interface) []string {
 	return o.namespace
 }
 
+// LabelSelector returns the label selector the API server can filter the resources to report with: IncludeLabels,
+// or else the negation of each ExcludeLabels selector of a single requirement. The other exclusions are only applied
+// by the LabelFilter.
+func (o *Options) LabelSelector() string {
+	if o.IncludeLabels != "" {
+		return o.IncludeLabels
+	}
+	var requirements []string
+	for _, exclude := range o.ExcludeLabels {
+		selector, err := labels.Parse(exclude)
+		if err != nil {
+			continue
+		}
+		if excluded, _ := selector.Requirements(); len(excluded) == 1 {
+			if negated, ok := negateRequirement(excluded[0]); ok {
+				requirements = append(requirements, negated.String())
+			}
+		}
+	}
+	return strings.Join(requirements, ",")
+}
+
+// negatedOperators pair the operators with the ones matching every other label set. Greater and lower than have
+// none, they do not match the label sets without the key either.
+var negatedOperators = map[selection.Operator]selection.Operator{
+	selection.Equals:       selection.NotEquals,
+	selection.DoubleEquals: selection.NotEquals,
+	selection.NotEquals:    selection.Equals,
+	selection.In:           selection.NotIn,
+	selection.NotIn:        selection.In,
+	selection.Exists:       selection.DoesNotExist,
+	selection.DoesNotExist: selection.Exists,
+}
+
+func negateRequirement(requirement labels.Requirement) (*labels.Requirement, bool) {
+	operator, ok := negatedOperators[requirement.Operator()]
+	if !ok {
+		return nil, false
+	}
+	negated, err := labels.NewRequirement(requirement.Key(), operator, requirement.Values().List())
+	return negated, err == nil
+}
+
+// FieldSelector returns the metadata.name field selector of the IncludeNames and ExcludeNames patterns which are plain
+// names, the API server cannot match the other patterns, like the name prefixes, which only the NameFilter applies.
+func (o *Options) FieldSelector() string {
+	var selectors []fields.Selector
+	if len(o.IncludeNames) == 1 && isPlainName(o.IncludeNames[0]) {
+		selectors = append(selectors, fields.OneTermEqualSelector("metadata.name", o.IncludeNames[0]))
+	}
+	for _, name := range o.ExcludeNames {
+		if isPlainName(name) {
+			selectors = append(selectors, fields.OneTermNotEqualSelector("metadata.name", name))
+		}
+	}
+	if len(selectors) == 0 {
+		return ""
+	}
+	return fields.AndSelectors(selectors...).String()
+}
+
+func isPlainName(pattern string) bool {
+	return pattern != "" && regexp.QuoteMeta(pattern) == pattern
+}
+
+// ListOptions returns the options of the List calls of the resources to report, with the LabelSelector and the
+// FieldSelector of the filters so the API server only sends the resources passing them.
+func (o *Options) ListOptions() metav1.ListOptions {
+	return metav1.ListOptions{LabelSelector: o.LabelSelector(), FieldSelector: o.FieldSelector()}
+}
+
 func (o *Options) modifyLabels() {
 	if o.IncludeLabels != "" {
 		if len(o.ExcludeLabels) > 0 {
-			fmt.Fprintf(os.Stderr, "Exclude labels can't be used together with include labels. Ignoring --exclude-labels (-l) flag\n")
+			slog.Warn("Exclude labels can't be used together with include labels. Ignoring --exclude-labels (-l) flag")
 		}
 		o.ExcludeLabels = nil
 	}
 }
+
+// nameRegexps compiles the IncludeNames and ExcludeNames patterns, only called once
+func (o *Options) nameRegexps() ([]*regexp.Regexp, []*regexp.Regexp, error) {
+	o.namesOnce.Do(func() {
+		o.includeNames, o.namesErr = compileNamePatterns(o.IncludeNames)
+		if o.namesErr != nil {
+			return
+		}
+		o.excludeNames, o.namesErr = compileNamePatterns(o.ExcludeNames)
+	})
+	return o.includeNames, o.excludeNames, o.namesErr
+}
+
+func compileNamePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	regexps := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid name pattern %q: %w", pattern, err)
+		}
+		regexps = append(regexps, re)
+	}
+	return regexps, nil
+}
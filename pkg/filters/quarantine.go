@@ -0,0 +1,47 @@
+package filters
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// QuarantineLabel is set to "true" on the resources quarantined by kor, so they can be selected
+	QuarantineLabel = "kor.io/quarantined"
+	// UnusedSinceKey is the annotation holding the RFC3339 time kor first quarantined the resource
+	UnusedSinceKey = "kor.io/unused-since"
+)
+
+// QuarantineFilter is a filter that filters out resources that have not been quarantined for at least
+// QuarantinedFor, it keeps every resource when QuarantinedFor is not set
+func QuarantineFilter(object runtime.Object, opts *Options) bool {
+	if opts.QuarantinedFor == "" {
+		return false
+	}
+	meta, ok := object.(metav1.Object)
+	if !ok {
+		return false
+	}
+	gracePeriod, err := ParseDuration(opts.QuarantinedFor)
+	if err != nil {
+		return false
+	}
+	since, ok := QuarantinedSince(meta)
+	return !ok || time.Since(since) < gracePeriod
+}
+
+// QuarantinedSince returns when the resource was quarantined, false if it is not quarantined
+// or its UnusedSinceKey annotation cannot be read
+func QuarantinedSince(meta metav1.Object) (time.Time, bool) {
+	value, ok := meta.GetAnnotations()[UnusedSinceKey]
+	if !ok {
+		return time.Time{}, false
+	}
+	since, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return since, true
+}
@@ -4,9 +4,14 @@ import "fmt"
 
 func NewDefaultRegistry() Registry {
 	return Registry{
-		LabelFilterName:    LabelFilter,
-		AgeFilterName:      AgeFilter,
-		KorLabelFilterName: KorLabelFilter,
+		LabelFilterName:      LabelFilter,
+		AgeFilterName:        AgeFilter,
+		KorLabelFilterName:   KorLabelFilter,
+		NameFilterName:       NameFilter,
+		ExceptionFilterName:  ExceptionFilter,
+		QuarantineFilterName: QuarantineFilter,
+		OwnerFilterName:      OwnerFilter,
+		HelmFilterName:       HelmFilter,
 	}
 }
 
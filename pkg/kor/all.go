@@ -1,10 +1,7 @@
 package kor
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
+	"context"
 
 	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	"k8s.io/client-go/dynamic"
@@ -24,403 +21,407 @@ type ResourceDiff struct {
 	diff         []ResourceInfo
 }
 
-func getUnusedCMs(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
-	cmDiff, err := processNamespaceCM(clientset, namespace, filterOpts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get %s namespace %s: %v\n", "configmaps", namespace, err)
-	}
+func getUnusedCMs(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("ConfigMap", namespace)()
+	cmDiff, err := processNamespaceCM(ctx, clientset, namespace, filterOpts)
 	namespaceCMDiff := ResourceDiff{
 		"ConfigMap",
 		cmDiff,
 	}
-	return namespaceCMDiff
+	return namespaceCMDiff, err
 }
 
-func getUnusedSVCs(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
-	svcDiff, err := processNamespaceServices(clientset, namespace, filterOpts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get %s namespace %s: %v\n", "services", namespace, err)
-	}
+func getUnusedSVCs(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("Service", namespace)()
+	svcDiff, err := processNamespaceServices(ctx, clientset, namespace, filterOpts)
 	namespaceSVCDiff := ResourceDiff{
 		"Service",
 		svcDiff,
 	}
-	return namespaceSVCDiff
+	return namespaceSVCDiff, err
 }
 
-func getUnusedSecrets(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
-	secretDiff, err := processNamespaceSecret(clientset, namespace, filterOpts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get %s namespace %s: %v\n", "secrets", namespace, err)
-	}
+func getUnusedSecrets(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("Secret", namespace)()
+	secretDiff, err := processNamespaceSecret(ctx, clientset, namespace, filterOpts)
 	namespaceSecretDiff := ResourceDiff{
 		"Secret",
 		secretDiff,
 	}
-	return namespaceSecretDiff
+	return namespaceSecretDiff, err
 }
 
-func getUnusedServiceAccounts(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
-	saDiff, err := processNamespaceSA(clientset, namespace, filterOpts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get %s namespace %s: %v\n", "serviceaccounts", namespace, err)
-	}
+func getUnusedServiceAccounts(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("ServiceAccount", namespace)()
+	saDiff, err := processNamespaceSA(ctx, clientset, namespace, filterOpts)
 	namespaceSADiff := ResourceDiff{
 		"ServiceAccount",
 		saDiff,
 	}
-	return namespaceSADiff
+	return namespaceSADiff, err
 }
 
-func getUnusedDeployments(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
-	deployDiff, err := processNamespaceDeployments(clientset, namespace, filterOpts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get %s namespace %s: %v\n", "deployments", namespace, err)
-	}
+func getUnusedDeployments(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("Deployment", namespace)()
+	deployDiff, err := processNamespaceDeployments(ctx, clientset, namespace, filterOpts)
 	namespaceSADiff := ResourceDiff{
 		"Deployment",
 		deployDiff,
 	}
-	return namespaceSADiff
+	return namespaceSADiff, err
 }
 
-func getUnusedStatefulSets(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
-	stsDiff, err := processNamespaceStatefulSets(clientset, namespace, filterOpts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get %s namespace %s: %v\n", "statefulSets", namespace, err)
-	}
+func getUnusedStatefulSets(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("StatefulSet", namespace)()
+	stsDiff, err := processNamespaceStatefulSets(ctx, clientset, namespace, filterOpts)
 	namespaceSADiff := ResourceDiff{
 		"StatefulSet",
 		stsDiff,
 	}
-	return namespaceSADiff
+	return namespaceSADiff, err
 }
 
-func getUnusedRoles(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
-	roleDiff, err := processNamespaceRoles(clientset, namespace, filterOpts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get %s namespace %s: %v\n", "roles", namespace, err)
-	}
+func getUnusedRoles(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("Role", namespace)()
+	roleDiff, err := processNamespaceRoles(ctx, clientset, namespace, filterOpts)
 	namespaceSADiff := ResourceDiff{
 		"Role",
 		roleDiff,
 	}
-	return namespaceSADiff
+	return namespaceSADiff, err
 }
 
-func getUnusedClusterRoles(clientset kubernetes.Interface, filterOpts *filters.Options) ResourceDiff {
-	clusterRoleDiff, err := processClusterRoles(clientset, filterOpts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get %s: %v\n", "clusterRoles", err)
-	}
+func getUnusedClusterRoles(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("ClusterRole", "")()
+	clusterRoleDiff, err := processClusterRoles(ctx, clientset, filterOpts)
 	aDiff := ResourceDiff{
 		"ClusterRole",
 		clusterRoleDiff,
 	}
-	return aDiff
+	return aDiff, err
 }
 
-func getUnusedHpas(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
-	hpaDiff, err := processNamespaceHpas(clientset, namespace, filterOpts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get %s namespace %s: %v\n", "hpas", namespace, err)
-	}
+func getUnusedHpas(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("Hpa", namespace)()
+	hpaDiff, err := processNamespaceHpas(ctx, clientset, namespace, filterOpts)
 	namespaceHpaDiff := ResourceDiff{
 		"Hpa",
 		hpaDiff,
 	}
-	return namespaceHpaDiff
+	return namespaceHpaDiff, err
 }
 
-func getUnusedPvcs(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
-	pvcDiff, err := processNamespacePvcs(clientset, namespace, filterOpts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get %s namespace %s: %v\n", "pvcs", namespace, err)
-	}
+func getUnusedPvcs(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("Pvc", namespace)()
+	pvcDiff, err := processNamespacePvcs(ctx, clientset, namespace, filterOpts)
 	namespacePvcDiff := ResourceDiff{
 		"Pvc",
 		pvcDiff,
 	}
-	return namespacePvcDiff
+	return namespacePvcDiff, err
 }
 
-func getUnusedIngresses(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
-	ingressDiff, err := processNamespaceIngresses(clientset, namespace, filterOpts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get %s namespace %s: %v\n", "ingresses", namespace, err)
-	}
+func getUnusedIngresses(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("Ingress", namespace)()
+	ingressDiff, err := processNamespaceIngresses(ctx, clientset, namespace, filterOpts)
 	namespaceIngressDiff := ResourceDiff{
 		"Ingress",
 		ingressDiff,
 	}
-	return namespaceIngressDiff
+	return namespaceIngressDiff, err
 }
 
-func getUnusedPdbs(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
-	pdbDiff, err := processNamespacePdbs(clientset, namespace, filterOpts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get %s namespace %s: %v\n", "pdbs", namespace, err)
-	}
+func getUnusedPdbs(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("Pdb", namespace)()
+	pdbDiff, err := processNamespacePdbs(ctx, clientset, namespace, filterOpts)
 	namespacePdbDiff := ResourceDiff{
 		"Pdb",
 		pdbDiff,
 	}
-	return namespacePdbDiff
+	return namespacePdbDiff, err
 }
 
-func getUnusedCrds(apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, filterOpts *filters.Options) ResourceDiff {
-	crdDiff, err := processCrds(apiExtClient, dynamicClient, filterOpts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get %s: %v\n", "Crds", err)
-	}
+func getUnusedCrds(ctx context.Context, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("Crd", "")()
+	crdDiff, err := processCrds(ctx, apiExtClient, dynamicClient, filterOpts)
 	allCrdDiff := ResourceDiff{
 		"Crd",
 		crdDiff,
 	}
-	return allCrdDiff
+	return allCrdDiff, err
 }
 
-func getUnusedPvs(clientset kubernetes.Interface, filterOpts *filters.Options) ResourceDiff {
-	pvDiff, err := processPvs(clientset, filterOpts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get %s: %v\n", "Pvs", err)
-	}
+func getUnusedPvs(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("Pv", "")()
+	pvDiff, err := processPvs(ctx, clientset, filterOpts)
 	allPvDiff := ResourceDiff{
 		"Pv",
 		pvDiff,
 	}
-	return allPvDiff
+	return allPvDiff, err
 }
 
-func getUnusedPods(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
-	podDiff, err := processNamespacePods(clientset, namespace, filterOpts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get %s namespace %s: %v\n", "pods", namespace, err)
-	}
+func getUnusedPods(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("Pod", namespace)()
+	podDiff, err := processNamespacePods(ctx, clientset, namespace, filterOpts)
 	namespacePodDiff := ResourceDiff{
 		"Pod",
 		podDiff,
 	}
-	return namespacePodDiff
+	return namespacePodDiff, err
 }
 
-func getUnusedJobs(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
-	jobDiff, err := processNamespaceJobs(clientset, namespace, filterOpts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get %s namespace %s: %v\n", "jobs", namespace, err)
-	}
+func getUnusedJobs(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("Job", namespace)()
+	jobDiff, err := processNamespaceJobs(ctx, clientset, namespace, filterOpts)
 	namespaceJobDiff := ResourceDiff{
 		"Job",
 		jobDiff,
 	}
-	return namespaceJobDiff
+	return namespaceJobDiff, err
 }
 
-func getUnusedReplicaSets(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
-	replicaSetDiff, err := processNamespaceReplicaSets(clientset, namespace, filterOpts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get %s namespace %s: %v\n", "ReplicaSets", namespace, err)
-	}
+func getUnusedReplicaSets(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("ReplicaSet", namespace)()
+	replicaSetDiff, err := processNamespaceReplicaSets(ctx, clientset, namespace, filterOpts)
 	namespaceRSDiff := ResourceDiff{
 		"ReplicaSet",
 		replicaSetDiff,
 	}
-	return namespaceRSDiff
+	return namespaceRSDiff, err
 }
 
-func getUnusedDaemonSets(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
-	dsDiff, err := processNamespaceDaemonSets(clientset, namespace, filterOpts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get %s namespace %s: %v\n", "DaemonSets", namespace, err)
-	}
+func getUnusedDaemonSets(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("DaemonSet", namespace)()
+	dsDiff, err := processNamespaceDaemonSets(ctx, clientset, namespace, filterOpts)
 	namespaceSADiff := ResourceDiff{
 		"DaemonSet",
 		dsDiff,
 	}
-	return namespaceSADiff
+	return namespaceSADiff, err
 }
 
-func getUnusedStorageClasses(clientset kubernetes.Interface, filterOpts *filters.Options) ResourceDiff {
-	scDiff, err := processStorageClasses(clientset, filterOpts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get %s: %v\n", "StorageClasses", err)
-	}
+func getUnusedStorageClasses(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("StorageClass", "")()
+	scDiff, err := processStorageClasses(ctx, clientset, filterOpts)
 	allScDiff := ResourceDiff{
 		"StorageClass",
 		scDiff,
 	}
-	return allScDiff
+	return allScDiff, err
 }
 
-func getUnusedNetworkPolicies(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
-	netpolDiff, err := processNamespaceNetworkPolicies(clientset, namespace, filterOpts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get %s namespace %s: %v\n", "NetworkPolicies", namespace, err)
+func getUnusedCSIDrivers(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("CSIDriver", "")()
+	csiDriverDiff, err := processCSIDrivers(ctx, clientset, filterOpts)
+	allCSIDriverDiff := ResourceDiff{
+		"CSIDriver",
+		csiDriverDiff,
 	}
+	return allCSIDriverDiff, err
+}
+
+func getUnusedVolumeAttachments(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("VolumeAttachment", "")()
+	vaDiff, err := processVolumeAttachments(ctx, clientset, filterOpts)
+	allVaDiff := ResourceDiff{
+		"VolumeAttachment",
+		vaDiff,
+	}
+	return allVaDiff, err
+}
+
+func getUnusedAPIServices(ctx context.Context, clientset kubernetes.Interface, dynamicClient dynamic.Interface, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("APIService", "")()
+	apiServiceDiff, err := processAPIServices(ctx, clientset, dynamicClient, filterOpts)
+	allAPIServiceDiff := ResourceDiff{
+		"APIService",
+		apiServiceDiff,
+	}
+	return allAPIServiceDiff, err
+}
+
+func getUnusedServiceAccountTokens(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("ServiceAccountToken", namespace)()
+	tokenDiff, err := processNamespaceSATokens(ctx, clientset, namespace, filterOpts)
+	namespaceTokenDiff := ResourceDiff{
+		"ServiceAccountToken",
+		tokenDiff,
+	}
+	return namespaceTokenDiff, err
+}
+
+func getUnusedNetworkPolicies(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("NetworkPolicy", namespace)()
+	netpolDiff, err := processNamespaceNetworkPolicies(ctx, clientset, namespace, filterOpts)
 	namespaceNetpolDiff := ResourceDiff{
 		"NetworkPolicy",
 		netpolDiff,
 	}
-	return namespaceNetpolDiff
+	return namespaceNetpolDiff, err
 }
 
-func getUnusedRoleBindings(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ResourceDiff {
-	roleBindingDiff, err := processNamespaceRoleBindings(clientset, namespace, filterOpts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get %s namespace %s: %v\n", "RoleBindings", namespace, err)
-	}
+func getUnusedRoleBindings(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("RoleBinding", namespace)()
+	roleBindingDiff, err := processNamespaceRoleBindings(ctx, clientset, namespace, filterOpts)
 
 	namespaceRoleBindingDiff := ResourceDiff{
 		"RoleBinding",
 		roleBindingDiff,
 	}
-	return namespaceRoleBindingDiff
+	return namespaceRoleBindingDiff, err
 }
 
-func GetUnusedAllNamespaced(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		switch opts.GroupBy {
-		case "namespace":
-			resources[namespace] = make(map[string][]ResourceInfo)
-			resources[namespace]["ConfigMap"] = getUnusedCMs(clientset, namespace, filterOpts).diff
-			resources[namespace]["Service"] = getUnusedSVCs(clientset, namespace, filterOpts).diff
-			resources[namespace]["Secret"] = getUnusedSecrets(clientset, namespace, filterOpts).diff
-			resources[namespace]["ServiceAccount"] = getUnusedServiceAccounts(clientset, namespace, filterOpts).diff
-			resources[namespace]["Deployment"] = getUnusedDeployments(clientset, namespace, filterOpts).diff
-			resources[namespace]["StatefulSet"] = getUnusedStatefulSets(clientset, namespace, filterOpts).diff
-			resources[namespace]["Role"] = getUnusedRoles(clientset, namespace, filterOpts).diff
-			resources[namespace]["Hpa"] = getUnusedHpas(clientset, namespace, filterOpts).diff
-			resources[namespace]["Pvc"] = getUnusedPvcs(clientset, namespace, filterOpts).diff
-			resources[namespace]["Pod"] = getUnusedPods(clientset, namespace, filterOpts).diff
-			resources[namespace]["Ingress"] = getUnusedIngresses(clientset, namespace, filterOpts).diff
-			resources[namespace]["Pdb"] = getUnusedPdbs(clientset, namespace, filterOpts).diff
-			resources[namespace]["Job"] = getUnusedJobs(clientset, namespace, filterOpts).diff
-			resources[namespace]["ReplicaSet"] = getUnusedReplicaSets(clientset, namespace, filterOpts).diff
-			resources[namespace]["DaemonSet"] = getUnusedDaemonSets(clientset, namespace, filterOpts).diff
-			resources[namespace]["NetworkPolicy"] = getUnusedNetworkPolicies(clientset, namespace, filterOpts).diff
-			resources[namespace]["RoleBinding"] = getUnusedRoleBindings(clientset, namespace, filterOpts).diff
-		case "resource":
-			appendResources(resources, "ConfigMap", namespace, getUnusedCMs(clientset, namespace, filterOpts).diff)
-			appendResources(resources, "Service", namespace, getUnusedSVCs(clientset, namespace, filterOpts).diff)
-			appendResources(resources, "Secret", namespace, getUnusedSecrets(clientset, namespace, filterOpts).diff)
-			appendResources(resources, "ServiceAccount", namespace, getUnusedServiceAccounts(clientset, namespace, filterOpts).diff)
-			appendResources(resources, "Deployment", namespace, getUnusedDeployments(clientset, namespace, filterOpts).diff)
-			appendResources(resources, "StatefulSet", namespace, getUnusedStatefulSets(clientset, namespace, filterOpts).diff)
-			appendResources(resources, "Role", namespace, getUnusedRoles(clientset, namespace, filterOpts).diff)
-			appendResources(resources, "Hpa", namespace, getUnusedHpas(clientset, namespace, filterOpts).diff)
-			appendResources(resources, "Pvc", namespace, getUnusedPvcs(clientset, namespace, filterOpts).diff)
-			appendResources(resources, "Pod", namespace, getUnusedPods(clientset, namespace, filterOpts).diff)
-			appendResources(resources, "Ingress", namespace, getUnusedIngresses(clientset, namespace, filterOpts).diff)
-			appendResources(resources, "Pdb", namespace, getUnusedPdbs(clientset, namespace, filterOpts).diff)
-			appendResources(resources, "Job", namespace, getUnusedJobs(clientset, namespace, filterOpts).diff)
-			appendResources(resources, "ReplicaSet", namespace, getUnusedReplicaSets(clientset, namespace, filterOpts).diff)
-			appendResources(resources, "DaemonSet", namespace, getUnusedDaemonSets(clientset, namespace, filterOpts).diff)
-			appendResources(resources, "NetworkPolicy", namespace, getUnusedNetworkPolicies(clientset, namespace, filterOpts).diff)
-			appendResources(resources, "RoleBinding", namespace, getUnusedRoleBindings(clientset, namespace, filterOpts).diff)
-		}
-	}
+func getUnusedEndpoints(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("Endpoints", namespace)()
+	endpointsDiff, err := processNamespaceEndpoints(ctx, clientset, namespace, filterOpts)
 
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
-			return "", err
-		}
+	namespaceEndpointsDiff := ResourceDiff{
+		"Endpoints",
+		endpointsDiff,
 	}
+	return namespaceEndpointsDiff, err
+}
 
-	unusedAllNamespaced, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-	}
+func getUnusedEndpointSlices(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("EndpointSlice", namespace)()
+	endpointSliceDiff, err := processNamespaceEndpointSlices(ctx, clientset, namespace, filterOpts)
 
-	return unusedAllNamespaced, nil
+	namespaceEndpointSliceDiff := ResourceDiff{
+		"EndpointSlice",
+		endpointSliceDiff,
+	}
+	return namespaceEndpointSliceDiff, err
 }
 
-func GetUnusedAllNonNamespaced(filterOpts *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resources := make(map[string]map[string][]ResourceInfo)
-	switch opts.GroupBy {
-	case "namespace":
-		resources[""] = make(map[string][]ResourceInfo)
-		resources[""]["Crd"] = getUnusedCrds(apiExtClient, dynamicClient, filterOpts).diff
-		resources[""]["Pv"] = getUnusedPvs(clientset, filterOpts).diff
-		resources[""]["ClusterRole"] = getUnusedClusterRoles(clientset, filterOpts).diff
-		resources[""]["StorageClass"] = getUnusedStorageClasses(clientset, filterOpts).diff
-	case "resource":
-		appendResources(resources, "Crd", "", getUnusedCrds(apiExtClient, dynamicClient, filterOpts).diff)
-		appendResources(resources, "Pv", "", getUnusedPvs(clientset, filterOpts).diff)
-		appendResources(resources, "ClusterRole", "", getUnusedClusterRoles(clientset, filterOpts).diff)
-		appendResources(resources, "StorageClass", "", getUnusedStorageClasses(clientset, filterOpts).diff)
-	}
-
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
-			return "", err
-		}
+func getUnusedNodes(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("Node", "")()
+	nodeDiff, err := processNodes(ctx, clientset, filterOpts, DefaultNodeCordonedFor, DefaultNodeUtilisationThreshold)
+	allNodeDiff := ResourceDiff{
+		"Node",
+		nodeDiff,
 	}
+	return allNodeDiff, err
+}
 
-	unusedAllNonNamespaced, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
+func getUnusedHelmReleases(ctx context.Context, clientset kubernetes.Interface, dynamicClient dynamic.Interface, namespace string, filterOpts *filters.Options) (ResourceDiff, error) {
+	defer traceDetector("HelmReleaseSecret", namespace)()
+	helmDiff, err := processNamespaceHelmReleases(ctx, clientset, dynamicClient, namespace, filterOpts, DefaultHelmHistoryMax)
+	namespaceHelmDiff := ResourceDiff{
+		"HelmReleaseSecret",
+		helmDiff,
 	}
-
-	return unusedAllNonNamespaced, nil
+	return namespaceHelmDiff, err
 }
 
-func GetUnusedAll(filterOpts *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
-	unusedAllNamespaced, err := GetUnusedAllNamespaced(filterOpts, clientset, outputFormat, opts)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
+func getUnusedManagedSecrets(ctx context.Context, clientset kubernetes.Interface, dynamicClient dynamic.Interface, namespace string, filterOpts *filters.Options) ([]ResourceDiff, error) {
+	defer traceDetector("ManagedSecret", namespace)()
+	managedDiffs, err := processNamespaceManagedSecrets(ctx, clientset, dynamicClient, namespace, filterOpts)
+	var namespaceManagedDiffs []ResourceDiff
+	for _, kind := range []string{"SealedSecret", "ExternalSecret", "GeneratedSecret"} {
+		namespaceManagedDiffs = append(namespaceManagedDiffs, ResourceDiff{kind, managedDiffs[kind]})
 	}
+	return namespaceManagedDiffs, err
+}
 
-	// Skip getting non-namespaced resources if --include-namespaces flag is used
-	if len(filterOpts.IncludeNamespaces) > 0 {
-		return unusedAllNamespaced, nil
+func getUnusedGitOpsResources(ctx context.Context, clientset kubernetes.Interface, dynamicClient dynamic.Interface, namespace string, filterOpts *filters.Options) ([]ResourceDiff, error) {
+	defer traceDetector("GitOps", namespace)()
+	gitOpsDiffs, err := processNamespaceGitOps(ctx, clientset, dynamicClient, namespace, filterOpts)
+	var namespaceGitOpsDiffs []ResourceDiff
+	for _, kind := range gitOpsKinds {
+		namespaceGitOpsDiffs = append(namespaceGitOpsDiffs, ResourceDiff{kind, gitOpsDiffs[kind]})
 	}
+	return namespaceGitOpsDiffs, err
+}
 
-	unusedAllNonNamespaced, err := GetUnusedAllNonNamespaced(filterOpts, clientset, apiExtClient, dynamicClient, outputFormat, opts)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
+// retrieveAllNamespacedDiffs runs every namespaced detector against a namespace.
+func retrieveAllNamespacedDiffs(ctx context.Context, clientset kubernetes.Interface, dynamicClient dynamic.Interface, namespace string, filterOpts *filters.Options) []ResourceDiff {
+	var diffs []ResourceDiff
+	clients := Clients{Kubernetes: clientset, Dynamic: dynamicClient}
+	for _, detector := range Detectors() {
+		if !isClusterScoped(detector) {
+			diffs = append(diffs, detectorDiffs(ctx, detector, clients, namespace, filterOpts)...)
+		}
 	}
+	return diffs
+}
 
-	unusedAll := make(map[string]interface{})
-
-	if outputFormat != "json" {
-		unusedAll := unusedAllNamespaced + unusedAllNonNamespaced
-
-		return unusedAll, nil
-	} else {
-		var namespacedResourceMap, nonNamespacedResourceMap map[string]interface{}
-
-		if err := json.Unmarshal([]byte(unusedAllNamespaced), &namespacedResourceMap); err != nil {
-			return "", err
-		}
-		if err := json.Unmarshal([]byte(unusedAllNonNamespaced), &nonNamespacedResourceMap); err != nil {
-			return "", err
+// retrieveAllNonNamespacedDiffs runs every cluster-scoped detector.
+func retrieveAllNonNamespacedDiffs(ctx context.Context, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, filterOpts *filters.Options) []ResourceDiff {
+	var diffs []ResourceDiff
+	clients := Clients{Kubernetes: clientset, APIExtensions: apiExtClient, Dynamic: dynamicClient}
+	for _, detector := range Detectors() {
+		if isClusterScoped(detector) {
+			diffs = append(diffs, detectorDiffs(ctx, detector, clients, "", filterOpts)...)
 		}
+	}
+	return diffs
+}
 
-		for k, v := range namespacedResourceMap {
-			unusedAll[k] = v
+// groupResourceDiffs adds the diffs found in namespace to resources,
+// following the requested grouping.
+func groupResourceDiffs(resources map[string]map[string][]ResourceInfo, namespace string, diffs []ResourceDiff, groupBy string) {
+	for _, diff := range diffs {
+		progress.addFindings(len(diff.diff))
+	}
+	switch groupBy {
+	case "namespace":
+		if resources[namespace] == nil {
+			resources[namespace] = make(map[string][]ResourceInfo)
 		}
-		for k, v := range nonNamespacedResourceMap {
-			unusedAll[k] = v
+		for _, diff := range diffs {
+			resources[namespace][diff.resourceType] = diff.diff
 		}
-
-		jsonResponse, err := json.MarshalIndent(unusedAll, "", "  ")
-		if err != nil {
-			return "", err
+	case "resource":
+		for _, diff := range diffs {
+			appendResources(resources, diff.resourceType, namespace, diff.diff)
 		}
+	}
+}
+
+func GetUnusedAllNamespaced(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
+	clientset = scanClientset(clientset)
+	resources := make(map[string]map[string][]ResourceInfo)
+	for _, scan := range scanNamespaces(filterOpts.Namespaces(ctx, clientset), func(namespace string) ([]ResourceDiff, error) {
+		return retrieveAllNamespacedDiffs(ctx, clientset, dynamicClient, namespace, filterOpts), nil
+	}) {
+		groupResourceDiffs(resources, scan.namespace, scan.result, opts.GroupBy)
+	}
+	exportResourceManifests(ctx, clientset, resources, opts)
+	enrichResources(ctx, clientset, resources, opts)
+	return formatUnusedResources(resources, outputFormat, opts)
+}
 
-		return string(jsonResponse), nil
+func GetUnusedAllNonNamespaced(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
+	resources := make(map[string]map[string][]ResourceInfo)
+	groupResourceDiffs(resources, "", retrieveAllNonNamespacedDiffs(ctx, clientset, apiExtClient, dynamicClient, filterOpts), opts.GroupBy)
+	exportResourceManifests(ctx, clientset, resources, opts)
+	enrichResources(ctx, clientset, resources, opts)
+	return formatUnusedResources(resources, outputFormat, opts)
+}
+
+// GetUnusedAll runs every detector and renders a single report, grouped by
+// namespace or by resource kind.
+func GetUnusedAll(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
+	resources := collectAllResources(ctx, filterOpts, clientset, apiExtClient, dynamicClient, opts.GroupBy)
+	exportResourceManifests(ctx, clientset, resources, opts)
+	enrichResources(ctx, clientset, resources, opts)
+	return formatUnusedResources(resources, outputFormat, opts)
+}
+
+// collectAllResources runs every detector and groups the findings.
+func collectAllResources(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, groupBy string) map[string]map[string][]ResourceInfo {
+	clientset = scanClientset(clientset)
+	resources := make(map[string]map[string][]ResourceInfo)
+	for _, scan := range scanNamespaces(filterOpts.Namespaces(ctx, clientset), func(namespace string) ([]ResourceDiff, error) {
+		return retrieveAllNamespacedDiffs(ctx, clientset, dynamicClient, namespace, filterOpts), nil
+	}) {
+		groupResourceDiffs(resources, scan.namespace, scan.result, groupBy)
+	}
+
+	// Skip getting non-namespaced resources if --include-namespaces flag is used
+	if len(filterOpts.IncludeNamespaces) == 0 {
+		groupResourceDiffs(resources, "", retrieveAllNonNamespacedDiffs(ctx, clientset, apiExtClient, dynamicClient, filterOpts), groupBy)
 	}
+	return resources
 }
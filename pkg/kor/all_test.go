@@ -0,0 +1,79 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func TestGetUnusedAllCombinesNamespacedAndClusterResources(t *testing.T) {
+	clientset := createTestMultiResources(t)
+
+	pv1 := CreateTestPv("test-pv1", "Available", AppLabels, "")
+	_, err := clientset.CoreV1().PersistentVolumes().Create(context.TODO(), pv1, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "PV", err)
+	}
+
+	_, err = clientset.CoreV1().Nodes().Create(context.TODO(), &corev1.Node{
+		ObjectMeta: v1.ObjectMeta{Name: "test-node1"},
+		Spec: corev1.NodeSpec{
+			Unschedulable: true,
+			Taints: []corev1.Taint{{
+				Key:       corev1.TaintNodeUnschedulable,
+				Effect:    corev1.TaintEffectNoSchedule,
+				TimeAdded: &v1.Time{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+			}},
+		},
+	}, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "Node", err)
+	}
+
+	apiExtClient := apiextensionsfake.NewSimpleClientset()
+	dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{apiServiceGVR: "APIServiceList"},
+	)
+
+	opts := common.Opts{
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+
+	output, err := GetUnusedAll(context.Background(), &filters.Options{}, clientset, apiExtClient, dynamicClient, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedAll: %v", err)
+	}
+
+	expectedOutput := map[string]map[string][]string{
+		"": {
+			"Node": {"test-node1"},
+			"Pv":   {"test-pv1"},
+		},
+		testNamespace: {
+			"ConfigMap":  {"configmap-1"},
+			"Deployment": {"test-deployment1"},
+		},
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+
+	if !reflect.DeepEqual(expectedOutput, actualOutput) {
+		t.Errorf("Expected output does not match \n actualOutput:\n %v \n expectedOutput:\n %v", actualOutput, expectedOutput)
+	}
+}
@@ -0,0 +1,128 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+var apiServiceGVR = schema.GroupVersionResource{
+	Group:    "apiregistration.k8s.io",
+	Version:  "v1",
+	Resource: "apiservices",
+}
+
+// apiServiceUnavailableReason returns the message of a non-True Available
+// condition, or an empty string when the APIService reports itself available.
+func apiServiceUnavailableReason(apiService unstructured.Unstructured) string {
+	conditions, _, _ := unstructured.NestedSlice(apiService.Object, "status", "conditions")
+	for _, condition := range conditions {
+		c, ok := condition.(map[string]interface{})
+		if !ok || c["type"] != "Available" {
+			continue
+		}
+		if c["status"] == "True" {
+			return ""
+		}
+		if message, ok := c["message"].(string); ok && message != "" {
+			return message
+		}
+		if reason, ok := c["reason"].(string); ok && reason != "" {
+			return reason
+		}
+		return "Available condition is not True"
+	}
+	return ""
+}
+
+func processAPIServices(ctx context.Context, clientset kubernetes.Interface, dynamicClient dynamic.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	apiServices, err := utils.ListAll(ctx, metav1.ListOptions{LabelSelector: filterOpts.LabelSelector()}, dynamicClient.Resource(apiServiceGVR).List)
+	if err != nil {
+		return nil, err
+	}
+
+	var unusedAPIServices []ResourceInfo
+
+	for _, apiService := range apiServices.Items {
+		if pass, _ := filter.SetObject(&apiService).Run(filterOpts, filters.LabelFilterName, filters.AgeFilterName); pass {
+			continue
+		}
+
+		if apiService.GetLabels()["kor/used"] == "false" {
+			unusedAPIServices = append(unusedAPIServices, ResourceInfo{Name: apiService.GetName(), Reason: "Marked with unused label"})
+			continue
+		}
+
+		// APIServices without a service are served locally by kube-apiserver
+		serviceName, found, _ := unstructured.NestedString(apiService.Object, "spec", "service", "name")
+		if !found || serviceName == "" {
+			continue
+		}
+		serviceNamespace, _, _ := unstructured.NestedString(apiService.Object, "spec", "service", "namespace")
+
+		_, err := clientset.CoreV1().Services(serviceNamespace).Get(ctx, serviceName, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			reason := fmt.Sprintf("APIService backing Service %s/%s does not exist", serviceNamespace, serviceName)
+			unusedAPIServices = append(unusedAPIServices, ResourceInfo{Name: apiService.GetName(), Reason: reason})
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		if reason := apiServiceUnavailableReason(apiService); reason != "" {
+			unusedAPIServices = append(unusedAPIServices, ResourceInfo{Name: apiService.GetName(), Reason: "APIService is not available: " + reason})
+		}
+	}
+
+	return unusedAPIServices, nil
+}
+
+func GetUnusedAPIServices(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
+	resources := make(map[string]map[string][]ResourceInfo)
+	diff, err := processAPIServices(ctx, clientset, dynamicClient, filterOpts)
+	if err != nil {
+		recordScanError("APIService", "", err)
+	}
+	switch opts.GroupBy {
+	case "namespace":
+		resources[""] = make(map[string][]ResourceInfo)
+		resources[""]["APIService"] = diff
+	case "resource":
+		appendResources(resources, "APIService", "", diff)
+	}
+
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+	default:
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	unusedAPIServices, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return unusedAPIServices, nil
+}
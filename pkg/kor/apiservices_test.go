@@ -0,0 +1,70 @@
+package kor
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestAPIService(name, serviceNamespace, serviceName, availableStatus string, labels map[string]string) *unstructured.Unstructured {
+	apiService := CreateTestUnstructered("APIService", apiServiceGVR.GroupVersion().String(), "", name)
+	apiService.SetLabels(labels)
+	if serviceName != "" {
+		apiService.Object["spec"] = map[string]interface{}{
+			"service": map[string]interface{}{
+				"namespace": serviceNamespace,
+				"name":      serviceName,
+			},
+		}
+	}
+	apiService.Object["status"] = map[string]interface{}{
+		"conditions": []interface{}{
+			map[string]interface{}{
+				"type":    "Available",
+				"status":  availableStatus,
+				"message": "failing or missing response",
+			},
+		},
+	}
+	return apiService
+}
+
+func TestProcessAPIServices(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	_, err := clientset.CoreV1().Services(testNamespace).Create(context.TODO(), CreateTestService(testNamespace, "metrics-server"), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake service: %v", err)
+	}
+
+	dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{apiServiceGVR: "APIServiceList"},
+		createTestAPIService("v1.apps", "", "", "True", AppLabels),
+		createTestAPIService("v1beta1.metrics.k8s.io", testNamespace, "metrics-server", "True", AppLabels),
+		createTestAPIService("v1beta1.custom.metrics.k8s.io", testNamespace, "prometheus-adapter", "False", AppLabels),
+		createTestAPIService("v1.broken.kor.com", testNamespace, "metrics-server", "False", AppLabels),
+		createTestAPIService("v1.marked.kor.com", testNamespace, "metrics-server", "True", UnusedLabels),
+	)
+
+	unusedAPIServices, err := processAPIServices(context.Background(), clientset, dynamicClient, &filters.Options{})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	expected := []ResourceInfo{
+		{Name: "v1.broken.kor.com", Reason: "APIService is not available: failing or missing response"},
+		{Name: "v1.marked.kor.com", Reason: "Marked with unused label"},
+		{Name: "v1beta1.custom.metrics.k8s.io", Reason: "APIService backing Service test-namespace/prometheus-adapter does not exist"},
+	}
+	if !equalResourceInfoSlices(unusedAPIServices, expected) {
+		t.Errorf("Expected %v, got %v", expected, unusedAPIServices)
+	}
+}
@@ -0,0 +1,32 @@
+package kor
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// leaderElectionAnnotation holds the leader record of the ConfigMaps and
+// Endpoints used as leader election locks, by the controllers which do not
+// use Leases yet.
+const leaderElectionAnnotation = "control-plane.alpha.kubernetes.io/leader"
+
+// builtinExceptions makes the scans skip the well-known objects of
+// Kubernetes, cloud providers and common add-ons, see SetBuiltinExceptions.
+var builtinExceptions = true
+
+// SetBuiltinExceptions enables the built-in exceptions, the objects kor never
+// reports although nothing references them: the lists of the exceptions
+// directory (kube-root-ca.crt, istio-ca-root-cert, default tokens...) and the
+// leader election locks. The --exceptions file adds to them.
+func SetBuiltinExceptions(enabled bool) {
+	builtinExceptions = enabled
+}
+
+// isLeaderElectionLock reports whether object is a leader election lock
+// skipped by the built-in exceptions.
+func isLeaderElectionLock(object metav1.Object) bool {
+	if !builtinExceptions {
+		return false
+	}
+	_, ok := object.GetAnnotations()[leaderElectionAnnotation]
+	return ok
+}
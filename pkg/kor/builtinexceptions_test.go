@@ -0,0 +1,72 @@
+package kor
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func TestBuiltinExceptions(t *testing.T) {
+	leaderLock := CreateTestConfigmap(testNamespace, "controller-leader", AppLabels)
+	leaderLock.Annotations = map[string]string{leaderElectionAnnotation: `{"holderIdentity":"controller-0"}`}
+	clientset := fake.NewSimpleClientset(
+		leaderLock,
+		CreateTestConfigmap(testNamespace, "istio-ca-root-cert", AppLabels),
+		CreateTestConfigmap(testNamespace, "kube-root-ca.crt", AppLabels),
+		CreateTestConfigmap(testNamespace, "configmap-1", AppLabels),
+		CreateTestServiceAccount(testNamespace, "default", AppLabels),
+		CreateTestServiceAccountToken(testNamespace, "default-token-abcde", "default", AppLabels),
+	)
+
+	defer SetBuiltinExceptions(true)
+	for _, test := range []struct {
+		enabled    bool
+		configmaps []string
+		tokens     []string
+	}{
+		{enabled: true, configmaps: []string{"configmap-1"}},
+		{enabled: false, configmaps: []string{"configmap-1", "controller-leader", "istio-ca-root-cert", "kube-root-ca.crt"}, tokens: []string{"default-token-abcde"}},
+	} {
+		SetBuiltinExceptions(test.enabled)
+
+		configmaps, err := processNamespaceCM(context.TODO(), clientset, testNamespace, &filters.Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := resourceInfoNames(configmaps)
+		slices.Sort(names)
+		if !slices.Equal(names, test.configmaps) {
+			t.Errorf("Expected unused configmaps %v with the built-in exceptions enabled=%t, got %v", test.configmaps, test.enabled, names)
+		}
+
+		tokens, err := processNamespaceSATokens(context.TODO(), clientset, testNamespace, &filters.Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if names := resourceInfoNames(tokens); !slices.Equal(names, test.tokens) {
+			t.Errorf("Expected unused tokens %v with the built-in exceptions enabled=%t, got %v", test.tokens, test.enabled, names)
+		}
+	}
+}
+
+func TestBuiltinExceptionsLeaderElectionEndpoints(t *testing.T) {
+	leaderLock := CreateTestEndpoint(testNamespace, "kube-scheduler", 0, AppLabels)
+	leaderLock.Annotations = map[string]string{leaderElectionAnnotation: `{"holderIdentity":"scheduler-0"}`}
+	clientset := fake.NewSimpleClientset(leaderLock, &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "orphaned", Namespace: testNamespace},
+	})
+
+	endpoints, err := processNamespaceEndpoints(context.TODO(), clientset, testNamespace, &filters.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := resourceInfoNames(endpoints); !slices.Equal(names, []string{"orphaned"}) {
+		t.Errorf("Expected the leader election lock to be skipped, got %v", names)
+	}
+}
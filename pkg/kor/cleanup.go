@@ -0,0 +1,127 @@
+package kor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+// shortKindNames maps the short resource names accepted on the command line to scriptKinds keys.
+var shortKindNames = map[string]string{
+	"cm":      "configmap",
+	"svc":     "service",
+	"sa":      "serviceaccount",
+	"deploy":  "deployment",
+	"sts":     "statefulset",
+	"ing":     "ingress",
+	"po":      "pod",
+	"rs":      "replicaset",
+	"ds":      "daemonset",
+	"netpol":  "networkpolicy",
+	"ep":      "endpoints",
+	"sc":      "storageclass",
+	"no":      "node",
+	"satoken": "serviceaccounttoken",
+}
+
+// resolveScriptKinds returns the kinds of a comma separated list of resource
+// names, as accepted by GetUnusedMulti. Kinds stored as the same resource are
+// only returned once.
+func resolveScriptKinds(resourceNames string) []scriptKind {
+	var kinds []scriptKind
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(resourceNames, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if short, ok := shortKindNames[name]; ok {
+			name = short
+		}
+		for key, kind := range scriptKinds {
+			lowerKind := strings.ToLower(kind.kind)
+			if name != key && name != key+"s" && name != kind.resource && name != lowerKind && name != lowerKind+"s" {
+				continue
+			}
+			if !seen[kind.resource] {
+				seen[kind.resource] = true
+				kinds = append(kinds, kind)
+			}
+			break
+		}
+	}
+	return kinds
+}
+
+// ReleaseQuarantined removes the quarantine marker from the resources of the
+// scanned kinds that were quarantined for the whole filterOpts.QuarantinedFor
+// grace period but were not reported as unused during this run: they are used
+// again, and a new quarantine restarts the grace period. A resource whose
+// deletion failed is released as well.
+func ReleaseQuarantined(ctx context.Context, resourceNames string, filterOpts *filters.Options, clientset kubernetes.Interface, dynamicClient dynamic.Interface) {
+	gracePeriod, err := filters.ParseDuration(filterOpts.QuarantinedFor)
+	if err != nil {
+		slog.Error("Failed to release quarantined resources", "error", err)
+		return
+	}
+
+	reported := make(map[string]bool)
+	for _, finding := range reportedResources {
+		name := strings.TrimSuffix(strings.TrimSuffix(finding.Name, deletedNameSuffix), dryRunSuffix)
+		reported[quarantineKey(lookupScriptKind(finding.Kind).resource, finding.Namespace, name)] = true
+	}
+	namespaces := make(map[string]bool)
+	for _, namespace := range filterOpts.Namespaces(ctx, clientset) {
+		namespaces[namespace] = true
+	}
+
+	for _, kind := range resolveScriptKinds(resourceNames) {
+		gv, err := schema.ParseGroupVersion(kind.apiVersion)
+		if err != nil {
+			continue
+		}
+		gvr := gv.WithResource(kind.resource)
+		list, err := utils.ListAll(ctx, metav1.ListOptions{LabelSelector: filters.QuarantineLabel + "=true"}, dynamicClient.Resource(gvr).List)
+		if err != nil {
+			slog.Error("Failed to list quarantined resources", "resource", kind.resource, "error", err)
+			continue
+		}
+
+		for _, item := range list.Items {
+			namespace := item.GetNamespace()
+			// Cluster-scoped resources are not scanned when namespaces are given
+			if namespace != "" && !namespaces[namespace] || namespace == "" && len(filterOpts.IncludeNamespaces) > 0 {
+				continue
+			}
+			since, ok := filters.QuarantinedSince(&item)
+			if !ok || time.Since(since) < gracePeriod || reported[quarantineKey(kind.resource, namespace, item.GetName())] {
+				continue
+			}
+
+			fmt.Printf("Releasing %s %s in namespace %s, it is used again%s\n", kind.kind, item.GetName(), namespace, dryRunNote())
+			if deleteDryRun == DryRunClient {
+				continue
+			}
+			labels := item.GetLabels()
+			delete(labels, filters.QuarantineLabel)
+			item.SetLabels(labels)
+			annotations := item.GetAnnotations()
+			delete(annotations, filters.UnusedSinceKey)
+			item.SetAnnotations(annotations)
+			if _, err := dynamicClient.Resource(gvr).Namespace(namespace).Update(ctx, &item, metav1.UpdateOptions{DryRun: serverDryRun()}); err != nil {
+				slog.Error("Failed to release quarantined resource", "resource", kind.kind, "name", item.GetName(), "namespace", namespace, "error", err)
+			}
+		}
+	}
+}
+
+func quarantineKey(resource, namespace, name string) string {
+	return resource + "/" + namespace + "/" + name
+}
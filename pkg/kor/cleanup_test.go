@@ -0,0 +1,68 @@
+package kor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	fake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func TestResolveScriptKinds(t *testing.T) {
+	kinds := resolveScriptKinds("cm,secrets,HelmReleaseSecret,deploy,persistentvolumeclaims,unknown")
+	var resources []string
+	for _, kind := range kinds {
+		resources = append(resources, kind.resource)
+	}
+	expected := []string{"configmaps", "secrets", "deployments", "persistentvolumeclaims"}
+	if len(resources) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, resources)
+	}
+	for i := range expected {
+		if resources[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, resources)
+		}
+	}
+}
+
+func TestReleaseQuarantined(t *testing.T) {
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	quarantined := func(name string, since time.Duration) runtime.Object {
+		configmap := CreateTestUnstructered("ConfigMap", "v1", testNamespace, name)
+		configmap.SetLabels(map[string]string{filters.QuarantineLabel: "true", "app": "test"})
+		configmap.SetAnnotations(map[string]string{filters.UnusedSinceKey: time.Now().Add(-since).UTC().Format(time.RFC3339)})
+		return configmap
+	}
+	dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "ConfigMapList"},
+		quarantined("used-again", 20*24*time.Hour),
+		quarantined("still-unused", 20*24*time.Hour),
+		quarantined("recent", time.Hour),
+	)
+
+	defer func() { reportedResources = nil }()
+	reportedResources = []unusedResource{{Namespace: testNamespace, Kind: "ConfigMap", Name: "still-unused" + deletedNameSuffix}}
+	filterOpts := &filters.Options{QuarantinedFor: "14d", IncludeNamespaces: []string{testNamespace}}
+	ReleaseQuarantined(context.Background(), "configmaps", filterOpts, fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: testNamespace}}), dynamicClient)
+
+	for name, wantReleased := range map[string]bool{"used-again": true, "still-unused": false, "recent": false} {
+		configmap, err := dynamicClient.Resource(gvr).Namespace(testNamespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Error getting configmap %s: %v", name, err)
+		}
+		_, quarantined := configmap.GetLabels()[filters.QuarantineLabel]
+		if quarantined == wantReleased {
+			t.Errorf("Expected %s released: %v, got labels %v", name, wantReleased, configmap.GetLabels())
+		}
+		if configmap.GetLabels()["app"] != "test" {
+			t.Errorf("Expected %s to keep its other labels, got %v", name, configmap.GetLabels())
+		}
+	}
+}
@@ -0,0 +1,571 @@
+package kor
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	typedappsv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+	typedautoscalingv2 "k8s.io/client-go/kubernetes/typed/autoscaling/v2"
+	typedbatchv1 "k8s.io/client-go/kubernetes/typed/batch/v1"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	typeddiscoveryv1 "k8s.io/client-go/kubernetes/typed/discovery/v1"
+	typednetworkingv1 "k8s.io/client-go/kubernetes/typed/networking/v1"
+	typedpolicyv1 "k8s.io/client-go/kubernetes/typed/policy/v1"
+	typedrbacv1 "k8s.io/client-go/kubernetes/typed/rbac/v1"
+
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+// clusterWideLists makes the scans list each namespaced resource once across
+// the cluster, see SetClusterWideLists.
+var clusterWideLists bool
+
+// SetClusterWideLists makes the scans list the Pods, ConfigMaps and the other
+// namespaced resources of every namespace in a single List call per kind
+// instead of one per namespace, and hand each namespace its share. It cuts
+// the API requests of scans of many namespaces at the cost of holding the
+// resources of the whole cluster in memory during the scan.
+func SetClusterWideLists(enabled bool) {
+	clusterWideLists = enabled
+}
+
+// listSource serves the objects of a namespaced resource in a namespace
+// without listing the namespace. served is false when the source cannot, the
+// namespace is then listed from the API server.
+type listSource interface {
+	objects(ctx context.Context, gvr schema.GroupVersionResource, namespace string, options metav1.ListOptions, cluster clusterResource) (objects []runtime.Object, served bool, err error)
+}
+
+// clusterResource lists and watches a namespaced resource across the cluster.
+type clusterResource interface {
+	list(ctx context.Context, options metav1.ListOptions) (runtime.Object, error)
+	watch(ctx context.Context, options metav1.ListOptions) (watch.Interface, error)
+	// resourceVersion returns the current resourceVersion of the resource,
+	// listing a single object.
+	resourceVersion(ctx context.Context, options metav1.ListOptions) (string, error)
+	// newList returns an empty list of the resource.
+	newList() runtime.Object
+}
+
+// clusterInterface is the typed client of a resource across the cluster.
+type clusterInterface[L runtime.Object] interface {
+	List(ctx context.Context, options metav1.ListOptions) (L, error)
+	Watch(ctx context.Context, options metav1.ListOptions) (watch.Interface, error)
+}
+
+type typedClusterResource[L runtime.Object] struct {
+	cluster clusterInterface[L]
+}
+
+func (r typedClusterResource[L]) list(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+	return utils.ListAll(ctx, options, r.cluster.List)
+}
+
+func (r typedClusterResource[L]) watch(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+	return r.cluster.Watch(ctx, options)
+}
+
+func (r typedClusterResource[L]) resourceVersion(ctx context.Context, options metav1.ListOptions) (string, error) {
+	options.Limit = 1
+	list, err := r.cluster.List(ctx, options)
+	if err != nil {
+		return "", err
+	}
+	listMeta, err := meta.ListAccessor(list)
+	if err != nil {
+		return "", err
+	}
+	return listMeta.GetResourceVersion(), nil
+}
+
+func (r typedClusterResource[L]) newList() runtime.Object {
+	return reflect.New(reflect.TypeFor[L]().Elem()).Interface().(runtime.Object)
+}
+
+// listSourceClientset serves the List calls of the namespaced resources the
+// detectors look at from source.
+type listSourceClientset struct {
+	kubernetes.Interface
+	source listSource
+}
+
+// scanClientset returns the clientset a scan lists resources with, one
+// listing each resource once across the cluster with SetClusterWideLists,
+// or bringing the resources of the last scan up to date with
+// SetIncrementalCache.
+func scanClientset(clientset kubernetes.Interface) kubernetes.Interface {
+	if clientset == nil {
+		return clientset
+	}
+	resetCrossNamespaceIndex(clientset)
+	if _, ok := clientset.(*listSourceClientset); ok {
+		return clientset
+	}
+	if incrementalCache != "" {
+		return &listSourceClientset{Interface: clientset, source: newIncrementalLists(incrementalCache)}
+	}
+	if !clusterWideLists {
+		return clientset
+	}
+	return &listSourceClientset{Interface: clientset, source: &clusterLists{lists: make(map[clusterListKey]*clusterList)}}
+}
+
+// clusterLists lists each resource once across the cluster per selectors,
+// for a single scan: the lists are not refreshed.
+type clusterLists struct {
+	mu    sync.Mutex
+	lists map[clusterListKey]*clusterList
+}
+
+type clusterListKey struct {
+	gvr           schema.GroupVersionResource
+	labelSelector string
+	fieldSelector string
+}
+
+// clusterList holds the objects of a resource across the cluster by
+// namespace, or the error listing them.
+type clusterList struct {
+	once       sync.Once
+	namespaces map[string][]runtime.Object
+	err        error
+}
+
+func (c *clusterLists) objects(ctx context.Context, gvr schema.GroupVersionResource, namespace string, options metav1.ListOptions, cluster clusterResource) ([]runtime.Object, bool, error) {
+	key := clusterListKey{gvr: gvr, labelSelector: options.LabelSelector, fieldSelector: options.FieldSelector}
+	c.mu.Lock()
+	list, ok := c.lists[key]
+	if !ok {
+		list = &clusterList{}
+		c.lists[key] = list
+	}
+	c.mu.Unlock()
+
+	list.once.Do(func() {
+		clusterOptions := metav1.ListOptions{LabelSelector: options.LabelSelector, FieldSelector: options.FieldSelector}
+		var all runtime.Object
+		if all, list.err = cluster.list(ctx, clusterOptions); list.err != nil {
+			return
+		}
+		var items []runtime.Object
+		if items, list.err = meta.ExtractList(all); list.err != nil {
+			return
+		}
+		list.namespaces, list.err = byNamespace(items)
+	})
+	// kor may only be allowed to list some namespaces
+	if apierrors.IsForbidden(list.err) {
+		return nil, false, nil
+	}
+	return list.namespaces[namespace], list.err == nil, list.err
+}
+
+// byNamespace buckets objects by namespace.
+func byNamespace(objects []runtime.Object) (map[string][]runtime.Object, error) {
+	namespaces := make(map[string][]runtime.Object)
+	for _, item := range objects {
+		object, err := meta.Accessor(item)
+		if err != nil {
+			return nil, err
+		}
+		namespaces[object.GetNamespace()] = append(namespaces[object.GetNamespace()], item)
+	}
+	return namespaces, nil
+}
+
+// listFromSource returns the objects of gvr in namespace out of source, or
+// lists them with listNamespace when source cannot serve them. cluster is the
+// client of gvr across the cluster.
+func listFromSource[L runtime.Object](ctx context.Context, source listSource, gvr schema.GroupVersionResource, namespace string, options metav1.ListOptions, listNamespace func(context.Context, metav1.ListOptions) (L, error), cluster clusterInterface[L]) (L, error) {
+	var zero L
+	if namespace == "" || options.Continue != "" {
+		return listNamespace(ctx, options)
+	}
+	resource := typedClusterResource[L]{cluster: cluster}
+	objects, served, err := source.objects(ctx, gvr, namespace, options, resource)
+	if err != nil {
+		return zero, err
+	}
+	if !served {
+		return listNamespace(ctx, options)
+	}
+
+	result := resource.newList().(L)
+	if err := meta.SetList(result, objects); err != nil {
+		return zero, err
+	}
+	return result, nil
+}
+
+func (c *listSourceClientset) CoreV1() typedcorev1.CoreV1Interface {
+	return clusterCoreV1{CoreV1Interface: c.Interface.CoreV1(), source: c.source}
+}
+
+type clusterCoreV1 struct {
+	typedcorev1.CoreV1Interface
+	source listSource
+}
+
+func (c clusterCoreV1) ConfigMaps(namespace string) typedcorev1.ConfigMapInterface {
+	return clusterConfigMaps{ConfigMapInterface: c.CoreV1Interface.ConfigMaps(namespace), cluster: c.CoreV1Interface.ConfigMaps(""), source: c.source, namespace: namespace}
+}
+
+type clusterConfigMaps struct {
+	typedcorev1.ConfigMapInterface
+	cluster   typedcorev1.ConfigMapInterface
+	source    listSource
+	namespace string
+}
+
+func (l clusterConfigMaps) List(ctx context.Context, options metav1.ListOptions) (*corev1.ConfigMapList, error) {
+	return listFromSource(ctx, l.source, corev1.SchemeGroupVersion.WithResource("configmaps"), l.namespace, options, l.ConfigMapInterface.List, l.cluster)
+}
+
+func (c clusterCoreV1) Endpoints(namespace string) typedcorev1.EndpointsInterface {
+	return clusterEndpoints{EndpointsInterface: c.CoreV1Interface.Endpoints(namespace), cluster: c.CoreV1Interface.Endpoints(""), source: c.source, namespace: namespace}
+}
+
+type clusterEndpoints struct {
+	typedcorev1.EndpointsInterface
+	cluster   typedcorev1.EndpointsInterface
+	source    listSource
+	namespace string
+}
+
+func (l clusterEndpoints) List(ctx context.Context, options metav1.ListOptions) (*corev1.EndpointsList, error) {
+	return listFromSource(ctx, l.source, corev1.SchemeGroupVersion.WithResource("endpoints"), l.namespace, options, l.EndpointsInterface.List, l.cluster)
+}
+
+func (c clusterCoreV1) PersistentVolumeClaims(namespace string) typedcorev1.PersistentVolumeClaimInterface {
+	return clusterPersistentVolumeClaims{PersistentVolumeClaimInterface: c.CoreV1Interface.PersistentVolumeClaims(namespace), cluster: c.CoreV1Interface.PersistentVolumeClaims(""), source: c.source, namespace: namespace}
+}
+
+type clusterPersistentVolumeClaims struct {
+	typedcorev1.PersistentVolumeClaimInterface
+	cluster   typedcorev1.PersistentVolumeClaimInterface
+	source    listSource
+	namespace string
+}
+
+func (l clusterPersistentVolumeClaims) List(ctx context.Context, options metav1.ListOptions) (*corev1.PersistentVolumeClaimList, error) {
+	return listFromSource(ctx, l.source, corev1.SchemeGroupVersion.WithResource("persistentvolumeclaims"), l.namespace, options, l.PersistentVolumeClaimInterface.List, l.cluster)
+}
+
+func (c clusterCoreV1) Pods(namespace string) typedcorev1.PodInterface {
+	return clusterPods{PodInterface: c.CoreV1Interface.Pods(namespace), cluster: c.CoreV1Interface.Pods(""), source: c.source, namespace: namespace}
+}
+
+type clusterPods struct {
+	typedcorev1.PodInterface
+	cluster   typedcorev1.PodInterface
+	source    listSource
+	namespace string
+}
+
+func (l clusterPods) List(ctx context.Context, options metav1.ListOptions) (*corev1.PodList, error) {
+	return listFromSource(ctx, l.source, corev1.SchemeGroupVersion.WithResource("pods"), l.namespace, options, l.PodInterface.List, l.cluster)
+}
+
+func (c clusterCoreV1) Secrets(namespace string) typedcorev1.SecretInterface {
+	return clusterSecrets{SecretInterface: c.CoreV1Interface.Secrets(namespace), cluster: c.CoreV1Interface.Secrets(""), source: c.source, namespace: namespace}
+}
+
+type clusterSecrets struct {
+	typedcorev1.SecretInterface
+	cluster   typedcorev1.SecretInterface
+	source    listSource
+	namespace string
+}
+
+func (l clusterSecrets) List(ctx context.Context, options metav1.ListOptions) (*corev1.SecretList, error) {
+	return listFromSource(ctx, l.source, corev1.SchemeGroupVersion.WithResource("secrets"), l.namespace, options, l.SecretInterface.List, l.cluster)
+}
+
+func (c clusterCoreV1) ServiceAccounts(namespace string) typedcorev1.ServiceAccountInterface {
+	return clusterServiceAccounts{ServiceAccountInterface: c.CoreV1Interface.ServiceAccounts(namespace), cluster: c.CoreV1Interface.ServiceAccounts(""), source: c.source, namespace: namespace}
+}
+
+type clusterServiceAccounts struct {
+	typedcorev1.ServiceAccountInterface
+	cluster   typedcorev1.ServiceAccountInterface
+	source    listSource
+	namespace string
+}
+
+func (l clusterServiceAccounts) List(ctx context.Context, options metav1.ListOptions) (*corev1.ServiceAccountList, error) {
+	return listFromSource(ctx, l.source, corev1.SchemeGroupVersion.WithResource("serviceaccounts"), l.namespace, options, l.ServiceAccountInterface.List, l.cluster)
+}
+
+func (c clusterCoreV1) Services(namespace string) typedcorev1.ServiceInterface {
+	return clusterServices{ServiceInterface: c.CoreV1Interface.Services(namespace), cluster: c.CoreV1Interface.Services(""), source: c.source, namespace: namespace}
+}
+
+type clusterServices struct {
+	typedcorev1.ServiceInterface
+	cluster   typedcorev1.ServiceInterface
+	source    listSource
+	namespace string
+}
+
+func (l clusterServices) List(ctx context.Context, options metav1.ListOptions) (*corev1.ServiceList, error) {
+	return listFromSource(ctx, l.source, corev1.SchemeGroupVersion.WithResource("services"), l.namespace, options, l.ServiceInterface.List, l.cluster)
+}
+
+func (c *listSourceClientset) AppsV1() typedappsv1.AppsV1Interface {
+	return clusterAppsV1{AppsV1Interface: c.Interface.AppsV1(), source: c.source}
+}
+
+type clusterAppsV1 struct {
+	typedappsv1.AppsV1Interface
+	source listSource
+}
+
+func (c clusterAppsV1) DaemonSets(namespace string) typedappsv1.DaemonSetInterface {
+	return clusterDaemonSets{DaemonSetInterface: c.AppsV1Interface.DaemonSets(namespace), cluster: c.AppsV1Interface.DaemonSets(""), source: c.source, namespace: namespace}
+}
+
+type clusterDaemonSets struct {
+	typedappsv1.DaemonSetInterface
+	cluster   typedappsv1.DaemonSetInterface
+	source    listSource
+	namespace string
+}
+
+func (l clusterDaemonSets) List(ctx context.Context, options metav1.ListOptions) (*appsv1.DaemonSetList, error) {
+	return listFromSource(ctx, l.source, appsv1.SchemeGroupVersion.WithResource("daemonsets"), l.namespace, options, l.DaemonSetInterface.List, l.cluster)
+}
+
+func (c clusterAppsV1) Deployments(namespace string) typedappsv1.DeploymentInterface {
+	return clusterDeployments{DeploymentInterface: c.AppsV1Interface.Deployments(namespace), cluster: c.AppsV1Interface.Deployments(""), source: c.source, namespace: namespace}
+}
+
+type clusterDeployments struct {
+	typedappsv1.DeploymentInterface
+	cluster   typedappsv1.DeploymentInterface
+	source    listSource
+	namespace string
+}
+
+func (l clusterDeployments) List(ctx context.Context, options metav1.ListOptions) (*appsv1.DeploymentList, error) {
+	return listFromSource(ctx, l.source, appsv1.SchemeGroupVersion.WithResource("deployments"), l.namespace, options, l.DeploymentInterface.List, l.cluster)
+}
+
+func (c clusterAppsV1) ReplicaSets(namespace string) typedappsv1.ReplicaSetInterface {
+	return clusterReplicaSets{ReplicaSetInterface: c.AppsV1Interface.ReplicaSets(namespace), cluster: c.AppsV1Interface.ReplicaSets(""), source: c.source, namespace: namespace}
+}
+
+type clusterReplicaSets struct {
+	typedappsv1.ReplicaSetInterface
+	cluster   typedappsv1.ReplicaSetInterface
+	source    listSource
+	namespace string
+}
+
+func (l clusterReplicaSets) List(ctx context.Context, options metav1.ListOptions) (*appsv1.ReplicaSetList, error) {
+	return listFromSource(ctx, l.source, appsv1.SchemeGroupVersion.WithResource("replicasets"), l.namespace, options, l.ReplicaSetInterface.List, l.cluster)
+}
+
+func (c clusterAppsV1) StatefulSets(namespace string) typedappsv1.StatefulSetInterface {
+	return clusterStatefulSets{StatefulSetInterface: c.AppsV1Interface.StatefulSets(namespace), cluster: c.AppsV1Interface.StatefulSets(""), source: c.source, namespace: namespace}
+}
+
+type clusterStatefulSets struct {
+	typedappsv1.StatefulSetInterface
+	cluster   typedappsv1.StatefulSetInterface
+	source    listSource
+	namespace string
+}
+
+func (l clusterStatefulSets) List(ctx context.Context, options metav1.ListOptions) (*appsv1.StatefulSetList, error) {
+	return listFromSource(ctx, l.source, appsv1.SchemeGroupVersion.WithResource("statefulsets"), l.namespace, options, l.StatefulSetInterface.List, l.cluster)
+}
+
+func (c *listSourceClientset) AutoscalingV2() typedautoscalingv2.AutoscalingV2Interface {
+	return clusterAutoscalingV2{AutoscalingV2Interface: c.Interface.AutoscalingV2(), source: c.source}
+}
+
+type clusterAutoscalingV2 struct {
+	typedautoscalingv2.AutoscalingV2Interface
+	source listSource
+}
+
+func (c clusterAutoscalingV2) HorizontalPodAutoscalers(namespace string) typedautoscalingv2.HorizontalPodAutoscalerInterface {
+	return clusterHorizontalPodAutoscalers{HorizontalPodAutoscalerInterface: c.AutoscalingV2Interface.HorizontalPodAutoscalers(namespace), cluster: c.AutoscalingV2Interface.HorizontalPodAutoscalers(""), source: c.source, namespace: namespace}
+}
+
+type clusterHorizontalPodAutoscalers struct {
+	typedautoscalingv2.HorizontalPodAutoscalerInterface
+	cluster   typedautoscalingv2.HorizontalPodAutoscalerInterface
+	source    listSource
+	namespace string
+}
+
+func (l clusterHorizontalPodAutoscalers) List(ctx context.Context, options metav1.ListOptions) (*autoscalingv2.HorizontalPodAutoscalerList, error) {
+	return listFromSource(ctx, l.source, autoscalingv2.SchemeGroupVersion.WithResource("horizontalpodautoscalers"), l.namespace, options, l.HorizontalPodAutoscalerInterface.List, l.cluster)
+}
+
+func (c *listSourceClientset) BatchV1() typedbatchv1.BatchV1Interface {
+	return clusterBatchV1{BatchV1Interface: c.Interface.BatchV1(), source: c.source}
+}
+
+type clusterBatchV1 struct {
+	typedbatchv1.BatchV1Interface
+	source listSource
+}
+
+func (c clusterBatchV1) Jobs(namespace string) typedbatchv1.JobInterface {
+	return clusterJobs{JobInterface: c.BatchV1Interface.Jobs(namespace), cluster: c.BatchV1Interface.Jobs(""), source: c.source, namespace: namespace}
+}
+
+type clusterJobs struct {
+	typedbatchv1.JobInterface
+	cluster   typedbatchv1.JobInterface
+	source    listSource
+	namespace string
+}
+
+func (l clusterJobs) List(ctx context.Context, options metav1.ListOptions) (*batchv1.JobList, error) {
+	return listFromSource(ctx, l.source, batchv1.SchemeGroupVersion.WithResource("jobs"), l.namespace, options, l.JobInterface.List, l.cluster)
+}
+
+func (c *listSourceClientset) DiscoveryV1() typeddiscoveryv1.DiscoveryV1Interface {
+	return clusterDiscoveryV1{DiscoveryV1Interface: c.Interface.DiscoveryV1(), source: c.source}
+}
+
+type clusterDiscoveryV1 struct {
+	typeddiscoveryv1.DiscoveryV1Interface
+	source listSource
+}
+
+func (c clusterDiscoveryV1) EndpointSlices(namespace string) typeddiscoveryv1.EndpointSliceInterface {
+	return clusterEndpointSlices{EndpointSliceInterface: c.DiscoveryV1Interface.EndpointSlices(namespace), cluster: c.DiscoveryV1Interface.EndpointSlices(""), source: c.source, namespace: namespace}
+}
+
+type clusterEndpointSlices struct {
+	typeddiscoveryv1.EndpointSliceInterface
+	cluster   typeddiscoveryv1.EndpointSliceInterface
+	source    listSource
+	namespace string
+}
+
+func (l clusterEndpointSlices) List(ctx context.Context, options metav1.ListOptions) (*discoveryv1.EndpointSliceList, error) {
+	return listFromSource(ctx, l.source, discoveryv1.SchemeGroupVersion.WithResource("endpointslices"), l.namespace, options, l.EndpointSliceInterface.List, l.cluster)
+}
+
+func (c *listSourceClientset) NetworkingV1() typednetworkingv1.NetworkingV1Interface {
+	return clusterNetworkingV1{NetworkingV1Interface: c.Interface.NetworkingV1(), source: c.source}
+}
+
+type clusterNetworkingV1 struct {
+	typednetworkingv1.NetworkingV1Interface
+	source listSource
+}
+
+func (c clusterNetworkingV1) Ingresses(namespace string) typednetworkingv1.IngressInterface {
+	return clusterIngresses{IngressInterface: c.NetworkingV1Interface.Ingresses(namespace), cluster: c.NetworkingV1Interface.Ingresses(""), source: c.source, namespace: namespace}
+}
+
+type clusterIngresses struct {
+	typednetworkingv1.IngressInterface
+	cluster   typednetworkingv1.IngressInterface
+	source    listSource
+	namespace string
+}
+
+func (l clusterIngresses) List(ctx context.Context, options metav1.ListOptions) (*networkingv1.IngressList, error) {
+	return listFromSource(ctx, l.source, networkingv1.SchemeGroupVersion.WithResource("ingresses"), l.namespace, options, l.IngressInterface.List, l.cluster)
+}
+
+func (c clusterNetworkingV1) NetworkPolicies(namespace string) typednetworkingv1.NetworkPolicyInterface {
+	return clusterNetworkPolicies{NetworkPolicyInterface: c.NetworkingV1Interface.NetworkPolicies(namespace), cluster: c.NetworkingV1Interface.NetworkPolicies(""), source: c.source, namespace: namespace}
+}
+
+type clusterNetworkPolicies struct {
+	typednetworkingv1.NetworkPolicyInterface
+	cluster   typednetworkingv1.NetworkPolicyInterface
+	source    listSource
+	namespace string
+}
+
+func (l clusterNetworkPolicies) List(ctx context.Context, options metav1.ListOptions) (*networkingv1.NetworkPolicyList, error) {
+	return listFromSource(ctx, l.source, networkingv1.SchemeGroupVersion.WithResource("networkpolicies"), l.namespace, options, l.NetworkPolicyInterface.List, l.cluster)
+}
+
+func (c *listSourceClientset) PolicyV1() typedpolicyv1.PolicyV1Interface {
+	return clusterPolicyV1{PolicyV1Interface: c.Interface.PolicyV1(), source: c.source}
+}
+
+type clusterPolicyV1 struct {
+	typedpolicyv1.PolicyV1Interface
+	source listSource
+}
+
+func (c clusterPolicyV1) PodDisruptionBudgets(namespace string) typedpolicyv1.PodDisruptionBudgetInterface {
+	return clusterPodDisruptionBudgets{PodDisruptionBudgetInterface: c.PolicyV1Interface.PodDisruptionBudgets(namespace), cluster: c.PolicyV1Interface.PodDisruptionBudgets(""), source: c.source, namespace: namespace}
+}
+
+type clusterPodDisruptionBudgets struct {
+	typedpolicyv1.PodDisruptionBudgetInterface
+	cluster   typedpolicyv1.PodDisruptionBudgetInterface
+	source    listSource
+	namespace string
+}
+
+func (l clusterPodDisruptionBudgets) List(ctx context.Context, options metav1.ListOptions) (*policyv1.PodDisruptionBudgetList, error) {
+	return listFromSource(ctx, l.source, policyv1.SchemeGroupVersion.WithResource("poddisruptionbudgets"), l.namespace, options, l.PodDisruptionBudgetInterface.List, l.cluster)
+}
+
+func (c *listSourceClientset) RbacV1() typedrbacv1.RbacV1Interface {
+	return clusterRbacV1{RbacV1Interface: c.Interface.RbacV1(), source: c.source}
+}
+
+type clusterRbacV1 struct {
+	typedrbacv1.RbacV1Interface
+	source listSource
+}
+
+func (c clusterRbacV1) RoleBindings(namespace string) typedrbacv1.RoleBindingInterface {
+	return clusterRoleBindings{RoleBindingInterface: c.RbacV1Interface.RoleBindings(namespace), cluster: c.RbacV1Interface.RoleBindings(""), source: c.source, namespace: namespace}
+}
+
+type clusterRoleBindings struct {
+	typedrbacv1.RoleBindingInterface
+	cluster   typedrbacv1.RoleBindingInterface
+	source    listSource
+	namespace string
+}
+
+func (l clusterRoleBindings) List(ctx context.Context, options metav1.ListOptions) (*rbacv1.RoleBindingList, error) {
+	return listFromSource(ctx, l.source, rbacv1.SchemeGroupVersion.WithResource("rolebindings"), l.namespace, options, l.RoleBindingInterface.List, l.cluster)
+}
+
+func (c clusterRbacV1) Roles(namespace string) typedrbacv1.RoleInterface {
+	return clusterRoles{RoleInterface: c.RbacV1Interface.Roles(namespace), cluster: c.RbacV1Interface.Roles(""), source: c.source, namespace: namespace}
+}
+
+type clusterRoles struct {
+	typedrbacv1.RoleInterface
+	cluster   typedrbacv1.RoleInterface
+	source    listSource
+	namespace string
+}
+
+func (l clusterRoles) List(ctx context.Context, options metav1.ListOptions) (*rbacv1.RoleList, error) {
+	return listFromSource(ctx, l.source, rbacv1.SchemeGroupVersion.WithResource("roles"), l.namespace, options, l.RoleInterface.List, l.cluster)
+}
@@ -0,0 +1,92 @@
+package kor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createClusterListsClientset(t *testing.T) *fake.Clientset {
+	t.Helper()
+	clientset := fake.NewSimpleClientset()
+	for i := range 3 {
+		namespace := fmt.Sprintf("ns-%d", i)
+		if _, err := clientset.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := clientset.CoreV1().ConfigMaps(namespace).Create(context.TODO(), CreateTestConfigmap(namespace, "configmap-"+namespace, AppLabels), metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return clientset
+}
+
+// listActions counts the List calls of resource by namespace.
+func listActions(clientset *fake.Clientset, resource string) map[string]int {
+	lists := make(map[string]int)
+	for _, action := range clientset.Actions() {
+		if action.GetVerb() == "list" && action.GetResource().Resource == resource {
+			lists[action.GetNamespace()]++
+		}
+	}
+	return lists
+}
+
+func TestClusterWideLists(t *testing.T) {
+	defer resetReportedResources()
+	defer SetClusterWideLists(false)
+	SetClusterWideLists(true)
+	clientset := createClusterListsClientset(t)
+
+	output, err := GetUnusedConfigmaps(context.Background(), &filters.Options{}, clientset, "table", common.Opts{GroupBy: "namespace"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 3 {
+		if name := fmt.Sprintf("configmap-ns-%d", i); !strings.Contains(output, name) {
+			t.Errorf("Expected %s to be reported, got %s", name, output)
+		}
+	}
+	for _, resource := range []string{"configmaps", "pods"} {
+		if lists := listActions(clientset, resource); len(lists) != 1 || lists[""] != 1 {
+			t.Errorf("Expected a single cluster-wide list of %s, got %v", resource, lists)
+		}
+	}
+}
+
+func TestClusterWideListsForbidden(t *testing.T) {
+	defer resetReportedResources()
+	defer SetClusterWideLists(false)
+	SetClusterWideLists(true)
+	clientset := createClusterListsClientset(t)
+	clientset.PrependReactor("list", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() != "" {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "", errors.New("denied"))
+	})
+
+	output, err := GetUnusedConfigmaps(context.Background(), &filters.Options{}, clientset, "table", common.Opts{GroupBy: "namespace"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output, "configmap-ns-2") {
+		t.Errorf("Expected the namespaces to be listed one by one, got %s", output)
+	}
+	if lists := listActions(clientset, "configmaps"); lists[""] != 1 || lists["ns-0"] != 1 || lists["ns-2"] != 1 {
+		t.Errorf("Expected a fallback to a list per namespace, got %v", lists)
+	}
+}
@@ -6,7 +6,7 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
-	"os"
+	"log/slog"
 	"strconv"
 
 	v1 "k8s.io/api/rbac/v1"
@@ -17,24 +17,24 @@ import (
 
 	"github.com/yonahd/kor/pkg/common"
 	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
 )
 
 //go:embed exceptions/clusterroles/clusterroles.json
 var clusterRolesConfig []byte
 
-func retrieveUsedClusterRoles(clientset kubernetes.Interface, filterOpts *filters.Options) ([]string, error) {
+func retrieveUsedClusterRoles(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]string, error) {
 
 	//Get a list of all namespaces
-	namespaceList, err := clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	namespaceList, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.CoreV1().Namespaces().List)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to retrieve namespaces: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
 	}
 	roleBindingsAllNameSpaces := make([]v1.RoleBinding, 0)
 
 	for _, ns := range namespaceList.Items {
 		// Get a list of all role bindings in the specified namespace
-		roleBindings, err := clientset.RbacV1().RoleBindings(ns.Name).List(context.TODO(), metav1.ListOptions{})
+		roleBindings, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.RbacV1().RoleBindings(ns.Name).List)
 		if err != nil {
 			return nil, fmt.Errorf("failed to list role bindings in namespace %s: %v", ns.Name, err)
 		}
@@ -52,7 +52,7 @@ func retrieveUsedClusterRoles(clientset kubernetes.Interface, filterOpts *filter
 	}
 
 	// Get a list of all cluster role bindings in the specified namespace
-	clusterRoleBindings, err := clientset.RbacV1().ClusterRoleBindings().List(context.TODO(), metav1.ListOptions{})
+	clusterRoleBindings, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.RbacV1().ClusterRoleBindings().List)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to list cluster role bindings %v", err)
@@ -63,7 +63,7 @@ func retrieveUsedClusterRoles(clientset kubernetes.Interface, filterOpts *filter
 	}
 
 	// Get a list of all ClusterRoles
-	clusterRoles, err := clientset.RbacV1().ClusterRoles().List(context.TODO(), metav1.ListOptions{})
+	clusterRoles, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.RbacV1().ClusterRoles().List)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list cluster roles %v", err)
 	}
@@ -113,8 +113,8 @@ func retrieveUsedClusterRoles(clientset kubernetes.Interface, filterOpts *filter
 	return usedClusterRoleNames, nil
 }
 
-func retrieveClusterRoleNames(clientset kubernetes.Interface, filterOpts *filters.Options) ([]string, []string, error) {
-	clusterRoles, err := clientset.RbacV1().ClusterRoles().List(context.TODO(), metav1.ListOptions{})
+func retrieveClusterRoleNames(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]string, []string, error) {
+	clusterRoles, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.RbacV1().ClusterRoles().List)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -151,22 +151,20 @@ func retrieveClusterRoleNames(clientset kubernetes.Interface, filterOpts *filter
 	return names, unusedClusterRoles, nil
 }
 
-func processClusterRoles(clientset kubernetes.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
-	usedClusterRoles, err := retrieveUsedClusterRoles(clientset, filterOpts)
+func processClusterRoles(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	usedClusterRoles, err := retrieveUsedClusterRoles(ctx, clientset, filterOpts)
 	if err != nil {
 		return nil, err
 	}
 
-	usedClusterRoles = RemoveDuplicatesAndSort(usedClusterRoles)
-
-	clusterRoleNames, unusedClusterRoles, err := retrieveClusterRoleNames(clientset, filterOpts)
+	clusterRoleNames, unusedClusterRoles, err := retrieveClusterRoleNames(ctx, clientset, filterOpts)
 	if err != nil {
 		return nil, err
 	}
 
 	var diff []ResourceInfo
 
-	for _, name := range CalculateResourceDifference(usedClusterRoles, clusterRoleNames) {
+	for _, name := range newNameSet(usedClusterRoles).unused(clusterRoleNames) {
 		reason := "ClusterRole is not used by any RoleBinding or ClusterRoleBinding"
 		diff = append(diff, ResourceInfo{Name: name, Reason: reason})
 	}
@@ -180,15 +178,16 @@ func processClusterRoles(clientset kubernetes.Interface, filterOpts *filters.Opt
 
 }
 
-func GetUnusedClusterRoles(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+func GetUnusedClusterRoles(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
 	resources := make(map[string]map[string][]ResourceInfo)
-	diff, err := processClusterRoles(clientset, filterOpts)
+	diff, err := processClusterRoles(ctx, clientset, filterOpts)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to process cluster role : %v\n", err)
+		recordScanError("ClusterRole", "", err)
 	}
-	if opts.DeleteFlag {
-		if diff, err = DeleteResource(diff, clientset, "", "ClusterRole", opts.NoInteractive); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete clusterRole %s : %v\n", diff, err)
+	exportManifests(ctx, clientset, "", "ClusterRole", diff, opts)
+	if deleteEnabled(opts, "ClusterRole") {
+		if diff, err = DeleteResource(ctx, diff, clientset, "", "ClusterRole", opts.NoInteractive); err != nil {
+			slog.Error("Failed to delete clusterRole", "name", diff, "error", err)
 		}
 	}
 	switch opts.GroupBy {
@@ -199,12 +198,15 @@ func GetUnusedClusterRoles(filterOpts *filters.Options, clientset kubernetes.Int
 		appendResources(resources, "ClusterRole", "", diff)
 	}
 
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
 	var outputBuffer bytes.Buffer
 	var jsonResponse []byte
 	switch outputFormat {
 	case "table":
 		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
+	default:
 		var err error
 		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
 			return "", err
@@ -88,7 +88,7 @@ func createTestClusterRoles(t *testing.T) *fake.Clientset {
 func TestRetrieveUsedClusterRoles(t *testing.T) {
 	clientset := createTestClusterRoles(t)
 
-	usedClusterRoles, err := retrieveUsedClusterRoles(clientset, &filters.Options{})
+	usedClusterRoles, err := retrieveUsedClusterRoles(context.Background(), clientset, &filters.Options{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -110,7 +110,7 @@ func TestRetrieveUsedClusterRoles(t *testing.T) {
 
 func TestRetrieveClusterRoleNames(t *testing.T) {
 	clientset := createTestClusterRoles(t)
-	allRoles, _, err := retrieveClusterRoleNames(clientset, &filters.Options{})
+	allRoles, _, err := retrieveClusterRoleNames(context.Background(), clientset, &filters.Options{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -122,7 +122,7 @@ func TestRetrieveClusterRoleNames(t *testing.T) {
 func TestProcessClusterRoles(t *testing.T) {
 	clientset := createTestClusterRoles(t)
 
-	unusedClusterRoles, err := processClusterRoles(clientset, &filters.Options{})
+	unusedClusterRoles, err := processClusterRoles(context.Background(), clientset, &filters.Options{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -148,7 +148,7 @@ func TestGetUnusedClusterRolesStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedClusterRoles(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedClusterRoles(context.Background(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedRolesStructured: %v", err)
 	}
@@ -3,36 +3,41 @@ package kor
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	_ "embed"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"os"
+	"log/slog"
+	"sort"
+	"strings"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
 
 	"github.com/yonahd/kor/pkg/common"
 	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
 )
 
 //go:embed exceptions/configmaps/configmaps.json
 var configMapsConfig []byte
 
-func retrieveUsedCM(clientset kubernetes.Interface, namespace string) ([]string, []string, []string, []string, []string, error) {
+func retrieveUsedCM(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]string, []string, []string, []string, []string, error) {
 	var volumesCM []string
 	var envCM []string
 	var envFromCM []string
 	var envFromContainerCM []string
 	var envFromInitContainerCM []string
 
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	specs, err := podSpecs(ctx, clientset, namespace)
 	if err != nil {
 		return nil, nil, nil, nil, nil, err
 	}
 
-	for _, pod := range pods.Items {
-		for _, volume := range pod.Spec.Volumes {
+	for _, spec := range specs {
+		for _, volume := range spec.Volumes {
 			if volume.ConfigMap != nil {
 				volumesCM = append(volumesCM, volume.ConfigMap.Name)
 			}
@@ -44,7 +49,7 @@ func retrieveUsedCM(clientset kubernetes.Interface, namespace string) ([]string,
 				}
 			}
 		}
-		for _, container := range pod.Spec.Containers {
+		for _, container := range runContainers(spec) {
 			for _, env := range container.Env {
 				if env.ValueFrom != nil && env.ValueFrom.ConfigMapKeyRef != nil {
 					envCM = append(envCM, env.ValueFrom.ConfigMapKeyRef.Name)
@@ -61,7 +66,7 @@ func retrieveUsedCM(clientset kubernetes.Interface, namespace string) ([]string,
 				}
 			}
 		}
-		for _, initContainer := range pod.Spec.InitContainers {
+		for _, initContainer := range spec.InitContainers {
 			for _, volume := range initContainer.VolumeMounts {
 				if volume.Name != "" && volume.MountPath != "" {
 					volumesCM = append(volumesCM, volume.Name)
@@ -72,38 +77,47 @@ func retrieveUsedCM(clientset kubernetes.Interface, namespace string) ([]string,
 					envFromInitContainerCM = append(envFromInitContainerCM, env.ValueFrom.ConfigMapKeyRef.Name)
 				}
 			}
+			for _, envFrom := range initContainer.EnvFrom {
+				if envFrom.ConfigMapRef != nil {
+					envFromInitContainerCM = append(envFromInitContainerCM, envFrom.ConfigMapRef.Name)
+				}
+			}
 		}
 	}
 
 	return volumesCM, envCM, envFromCM, envFromContainerCM, envFromInitContainerCM, nil
 }
 
-func retrieveConfigMapNames(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]string, []string, error) {
-	configmaps, err := clientset.CoreV1().ConfigMaps(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func retrieveConfigMapNames(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]string, []string, error) {
+	configmaps, err := listObjectMetadata(ctx, clientset, corev1.SchemeGroupVersion.WithKind("ConfigMap"), namespace, filterOpts.ListOptions(), clientset.CoreV1().ConfigMaps(namespace).List)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	var unusedConfigmapNames []string
-	names := make([]string, 0, len(configmaps.Items))
+	names := make([]string, 0, len(configmaps))
 
-	for _, configmap := range configmaps.Items {
-		if pass, _ := filter.SetObject(&configmap).Run(filterOpts); pass {
+	for _, configmap := range configmaps {
+		if pass, _ := filter.SetObject(configmap).Run(filterOpts); pass {
 			continue
 		}
 
-		if configmap.Labels["kor/used"] == "false" {
-			unusedConfigmapNames = append(unusedConfigmapNames, configmap.Name)
+		if configmap.GetLabels()["kor/used"] == "false" {
+			unusedConfigmapNames = append(unusedConfigmapNames, configmap.GetName())
 			continue
 		}
 
-		names = append(names, configmap.Name)
+		if isLeaderElectionLock(configmap) || hasUsageMarker("ConfigMap", configmap) {
+			continue
+		}
+
+		names = append(names, configmap.GetName())
 	}
 	return names, unusedConfigmapNames, nil
 }
 
-func processNamespaceCM(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
-	volumesCM, envCM, envFromCM, envFromContainerCM, envFromInitContainerCM, err := retrieveUsedCM(clientset, namespace)
+func processNamespaceCM(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	volumesCM, envCM, envFromCM, envFromContainerCM, envFromInitContainerCM, err := retrieveUsedCM(ctx, clientset, namespace)
 	if err != nil {
 		return nil, err
 	}
@@ -112,33 +126,25 @@ func processNamespaceCM(clientset kubernetes.Interface, namespace string, filter
 		return nil, err
 	}
 
-	volumesCM = RemoveDuplicatesAndSort(volumesCM)
-	envCM = RemoveDuplicatesAndSort(envCM)
-	envFromCM = RemoveDuplicatesAndSort(envFromCM)
-	envFromContainerCM = RemoveDuplicatesAndSort(envFromContainerCM)
-	envFromInitContainerCM = RemoveDuplicatesAndSort(envFromInitContainerCM)
-
-	configMapNames, unusedConfigmapNames, err := retrieveConfigMapNames(clientset, namespace, filterOpts)
+	configMapNames, unusedConfigmapNames, err := retrieveConfigMapNames(ctx, clientset, namespace, filterOpts)
 	if err != nil {
 		return nil, err
 	}
 
-	var usedConfigMaps []string
-	slicesToAppend := [][]string{
-		volumesCM,
-		envCM,
-		envFromCM,
-		envFromContainerCM,
-		envFromInitContainerCM,
+	markedCM, err := retrieveMarkedReferences(ctx, clientset, namespace, "ConfigMap")
+	if err != nil {
+		return nil, err
 	}
-
-	for _, slice := range slicesToAppend {
-		usedConfigMaps = append(usedConfigMaps, slice...)
+	ruleCM, err := retrieveRuleReferences(ctx, clientset, namespace, "ConfigMap")
+	if err != nil {
+		return nil, err
 	}
 
+	usedConfigMaps := newNameSet(volumesCM, envCM, envFromCM, envFromContainerCM, envFromInitContainerCM, markedCM, ruleCM)
+
 	var diff []ResourceInfo
 
-	for _, name := range CalculateResourceDifference(usedConfigMaps, configMapNames) {
+	for _, name := range usedConfigMaps.unused(configMapNames) {
 		exceptionFound, err := isResourceException(name, namespace, config.ExceptionConfigMaps)
 		if err != nil {
 			return nil, err
@@ -159,17 +165,21 @@ func processNamespaceCM(clientset kubernetes.Interface, namespace string, filter
 	return diff, nil
 }
 
-func GetUnusedConfigmaps(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+func GetUnusedConfigmaps(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	clientset = scanClientset(clientset)
 	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceCM(clientset, namespace, filterOpts)
+	for _, scan := range scanNamespaces(filterOpts.Namespaces(ctx, clientset), func(namespace string) ([]ResourceInfo, error) {
+		return processNamespaceCM(ctx, clientset, namespace, filterOpts)
+	}) {
+		namespace, diff, err := scan.namespace, scan.result, scan.err
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
+			recordScanError("ConfigMap", namespace, err)
 			continue
 		}
-		if opts.DeleteFlag {
-			if diff, err = DeleteResource(diff, clientset, namespace, "ConfigMap", opts.NoInteractive); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to delete ConfigMap %s in namespace %s: %v\n", diff, namespace, err)
+		exportManifests(ctx, clientset, namespace, "ConfigMap", diff, opts)
+		if deleteEnabled(opts, "ConfigMap") {
+			if diff, err = DeleteResource(ctx, diff, clientset, namespace, "ConfigMap", opts.NoInteractive); err != nil {
+				slog.Error("Failed to delete ConfigMap", "name", diff, "namespace", namespace, "error", err)
 			}
 		}
 		switch opts.GroupBy {
@@ -181,12 +191,15 @@ func GetUnusedConfigmaps(filterOpts *filters.Options, clientset kubernetes.Inter
 		}
 	}
 
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
 	var outputBuffer bytes.Buffer
 	var jsonResponse []byte
 	switch outputFormat {
 	case "table":
 		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
+	default:
 		var err error
 		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
 			return "", err
@@ -200,3 +213,312 @@ func GetUnusedConfigmaps(filterOpts *filters.Options, clientset kubernetes.Inter
 
 	return unusedCMs, nil
 }
+
+// hashConfigMapData returns a stable digest of the data and binaryData of a ConfigMap
+func hashConfigMapData(configmap *corev1.ConfigMap) string {
+	keys := make([]string, 0, len(configmap.Data)+len(configmap.BinaryData))
+	for key := range configmap.Data {
+		keys = append(keys, key)
+	}
+	for key := range configmap.BinaryData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		hash.Write([]byte(key))
+		hash.Write([]byte{0})
+		if value, ok := configmap.Data[key]; ok {
+			hash.Write([]byte(value))
+		} else {
+			hash.Write(configmap.BinaryData[key])
+		}
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// retrieveConfigMapHashes maps the content digest of every non-empty ConfigMap in the namespace to the ConfigMaps sharing it
+func retrieveConfigMapHashes(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) (map[string][]string, error) {
+	configmaps, err := utils.ListAll(ctx, filterOpts.ListOptions(), clientset.CoreV1().ConfigMaps(namespace).List)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := unmarshalConfig(configMapsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make(map[string][]string)
+	for _, configmap := range configmaps.Items {
+		if pass, _ := filter.SetObject(&configmap).Run(filterOpts); pass {
+			continue
+		}
+
+		if len(configmap.Data) == 0 && len(configmap.BinaryData) == 0 {
+			continue
+		}
+
+		exceptionFound, err := isResourceException(configmap.Name, configmap.Namespace, config.ExceptionConfigMaps)
+		if err != nil {
+			return nil, err
+		}
+
+		if exceptionFound {
+			continue
+		}
+
+		digest := hashConfigMapData(&configmap)
+		hashes[digest] = append(hashes[digest], configmap.Name)
+	}
+	return hashes, nil
+}
+
+// processDuplicateConfigMaps groups ConfigMaps with identical content, either per namespace or across all given namespaces
+func processDuplicateConfigMaps(ctx context.Context, clientset kubernetes.Interface, namespaces []string, filterOpts *filters.Options, acrossNamespaces bool) (map[string][]ResourceInfo, error) {
+	// map[digest][]namespace/name
+	groups := make(map[string][]string)
+	for _, namespace := range namespaces {
+		hashes, err := retrieveConfigMapHashes(ctx, clientset, namespace, filterOpts)
+		if err != nil {
+			return nil, err
+		}
+		for digest, names := range hashes {
+			key := digest
+			if !acrossNamespaces {
+				key = namespace + "/" + digest
+			}
+			for _, name := range names {
+				groups[key] = append(groups[key], namespace+"/"+name)
+			}
+		}
+	}
+
+	duplicates := make(map[string][]ResourceInfo)
+	for _, members := range groups {
+		if len(members) < 2 {
+			continue
+		}
+		sort.Strings(members)
+		for _, member := range members {
+			namespace, name, _ := strings.Cut(member, "/")
+			var others []string
+			for _, other := range members {
+				if other == member {
+					continue
+				}
+				if acrossNamespaces {
+					others = append(others, other)
+				} else {
+					others = append(others, strings.TrimPrefix(other, namespace+"/"))
+				}
+			}
+			reason := fmt.Sprintf("ConfigMap has identical content to %s", strings.Join(others, ", "))
+			duplicates[namespace] = append(duplicates[namespace], ResourceInfo{Name: name, Reason: reason})
+		}
+	}
+
+	for namespace := range duplicates {
+		sort.Slice(duplicates[namespace], func(i, j int) bool {
+			return duplicates[namespace][i].Name < duplicates[namespace][j].Name
+		})
+	}
+
+	return duplicates, nil
+}
+
+func GetDuplicateConfigmaps(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts, acrossNamespaces bool) (string, error) {
+	resources := make(map[string]map[string][]ResourceInfo)
+	duplicates, err := processDuplicateConfigMaps(ctx, clientset, filterOpts.Namespaces(ctx, clientset), filterOpts, acrossNamespaces)
+	if err != nil {
+		return "", err
+	}
+
+	for namespace, diff := range duplicates {
+		switch opts.GroupBy {
+		case "namespace":
+			resources[namespace] = make(map[string][]ResourceInfo)
+			resources[namespace]["ConfigMap"] = diff
+		case "resource":
+			appendResources(resources, "ConfigMap", namespace, diff)
+		}
+	}
+
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+	default:
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	duplicateCMs, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return duplicateCMs, nil
+}
+
+// retrieveUsedConfigMapKeys records the keys of each ConfigMap referenced by pods and pod templates in the namespace
+func retrieveUsedConfigMapKeys(ctx context.Context, clientset kubernetes.Interface, namespace string) (map[string]*keyUsage, error) {
+	specs, err := podSpecs(ctx, clientset, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make(map[string]*keyUsage)
+	for _, spec := range specs {
+		for _, container := range podContainers(spec) {
+			for _, env := range container.Env {
+				if env.ValueFrom != nil && env.ValueFrom.ConfigMapKeyRef != nil {
+					recordUsedKeys(usage, env.ValueFrom.ConfigMapKeyRef.Name, env.ValueFrom.ConfigMapKeyRef.Key)
+				}
+			}
+			for _, envFrom := range container.EnvFrom {
+				if envFrom.ConfigMapRef != nil {
+					recordAllKeysUsed(usage, envFrom.ConfigMapRef.Name)
+				}
+			}
+		}
+
+		subPaths, wholeMounts := volumeSubPaths(spec)
+		for _, volume := range spec.Volumes {
+			if volume.ConfigMap != nil {
+				switch {
+				case len(volume.ConfigMap.Items) > 0:
+					for _, item := range volume.ConfigMap.Items {
+						recordUsedKeys(usage, volume.ConfigMap.Name, item.Key)
+					}
+				case wholeMounts[volume.Name] || len(subPaths[volume.Name]) == 0:
+					recordAllKeysUsed(usage, volume.ConfigMap.Name)
+				default:
+					recordUsedKeys(usage, volume.ConfigMap.Name, subPaths[volume.Name]...)
+				}
+			}
+			if volume.Projected != nil {
+				for _, source := range volume.Projected.Sources {
+					if source.ConfigMap == nil {
+						continue
+					}
+					if len(source.ConfigMap.Items) == 0 {
+						recordAllKeysUsed(usage, source.ConfigMap.Name)
+						continue
+					}
+					for _, item := range source.ConfigMap.Items {
+						recordUsedKeys(usage, source.ConfigMap.Name, item.Key)
+					}
+				}
+			}
+		}
+	}
+
+	return usage, nil
+}
+
+// processNamespaceCMKeys reports the keys of used ConfigMaps which are never referenced
+func processNamespaceCMKeys(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	usage, err := retrieveUsedConfigMapKeys(ctx, clientset, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := unmarshalConfig(configMapsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	configmaps, err := utils.ListAll(ctx, filterOpts.ListOptions(), clientset.CoreV1().ConfigMaps(namespace).List)
+	if err != nil {
+		return nil, err
+	}
+
+	var diff []ResourceInfo
+	for _, configmap := range configmaps.Items {
+		if pass, _ := filter.SetObject(&configmap).Run(filterOpts); pass {
+			continue
+		}
+
+		// ConfigMaps which are not used at all are reported by processNamespaceCM
+		configMapUsage, ok := usage[configmap.Name]
+		if !ok {
+			continue
+		}
+
+		exceptionFound, err := isResourceException(configmap.Name, configmap.Namespace, config.ExceptionConfigMaps)
+		if err != nil {
+			return nil, err
+		}
+
+		if exceptionFound {
+			continue
+		}
+
+		keys := make([]string, 0, len(configmap.Data)+len(configmap.BinaryData))
+		for key := range configmap.Data {
+			keys = append(keys, key)
+		}
+		for key := range configmap.BinaryData {
+			keys = append(keys, key)
+		}
+
+		for _, key := range configMapUsage.unusedKeys(keys) {
+			reason := "ConfigMap key is not referenced by any pod or container"
+			diff = append(diff, ResourceInfo{Name: configmap.Name + "/" + key, Reason: reason})
+		}
+	}
+
+	return diff, nil
+}
+
+func GetUnusedConfigmapKeys(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	clientset = scanClientset(clientset)
+	resources := make(map[string]map[string][]ResourceInfo)
+	for _, scan := range scanNamespaces(filterOpts.Namespaces(ctx, clientset), func(namespace string) ([]ResourceInfo, error) {
+		return processNamespaceCMKeys(ctx, clientset, namespace, filterOpts)
+	}) {
+		namespace, diff, err := scan.namespace, scan.result, scan.err
+		if err != nil {
+			recordScanError("ConfigMap", namespace, err)
+			continue
+		}
+		switch opts.GroupBy {
+		case "namespace":
+			resources[namespace] = make(map[string][]ResourceInfo)
+			resources[namespace]["ConfigMapKey"] = diff
+		case "resource":
+			appendResources(resources, "ConfigMapKey", namespace, diff)
+		}
+	}
+
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+	default:
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	unusedCMKeys, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return unusedCMKeys, nil
+}
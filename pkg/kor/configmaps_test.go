@@ -3,15 +3,18 @@ package kor
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"reflect"
 	"testing"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
 
 	"github.com/yonahd/kor/pkg/common"
 	"github.com/yonahd/kor/pkg/filters"
@@ -124,7 +127,7 @@ func createTestConfigmaps(t *testing.T) *fake.Clientset {
 func TestRetrieveConfigMapNames(t *testing.T) {
 	clientset := createTestConfigmaps(t)
 
-	configMapNames, _, err := retrieveConfigMapNames(clientset, testNamespace, &filters.Options{})
+	configMapNames, _, err := retrieveConfigMapNames(context.Background(), clientset, testNamespace, &filters.Options{})
 
 	if err != nil {
 		t.Fatalf("Error retrieving configmap names: %v", err)
@@ -143,7 +146,7 @@ func TestRetrieveConfigMapNames(t *testing.T) {
 func TestProcessNamespaceCM(t *testing.T) {
 	clientset := createTestConfigmaps(t)
 
-	diff, err := processNamespaceCM(clientset, testNamespace, &filters.Options{})
+	diff, err := processNamespaceCM(context.Background(), clientset, testNamespace, &filters.Options{})
 	if err != nil {
 		t.Fatalf("Error processing namespace CM: %v", err)
 	}
@@ -160,7 +163,7 @@ func TestProcessNamespaceCM(t *testing.T) {
 func TestRetrieveUsedCM(t *testing.T) {
 	clientset := createTestConfigmaps(t)
 
-	volumesCM, envCM, envFromCM, envFromContainerCM, envFromInitContainerCM, err := retrieveUsedCM(clientset, testNamespace)
+	volumesCM, envCM, envFromCM, envFromContainerCM, envFromInitContainerCM, err := retrieveUsedCM(context.Background(), clientset, testNamespace)
 
 	if err != nil {
 		t.Fatalf("Error retrieving used ConfigMaps: %v", err)
@@ -195,6 +198,67 @@ func TestRetrieveUsedCM(t *testing.T) {
 
 }
 
+func TestProcessNamespaceCMPodTemplates(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	for _, name := range []string{"configmap-deployment", "configmap-cronjob", "configmap-unused"} {
+		if _, err := clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), CreateTestConfigmap(testNamespace, name, AppLabels), metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Scaled to zero, the deployment has no pods
+	deployment := CreateTestDeployment(testNamespace, "deployment", 0, AppLabels)
+	deployment.Spec.Template.Spec.Containers = []corev1.Container{{
+		Name:    "app",
+		EnvFrom: []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "configmap-deployment"}}}},
+	}}
+	if _, err := clientset.AppsV1().Deployments(testNamespace).Create(context.TODO(), deployment, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	cronJob := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "cronjob", Namespace: testNamespace}}
+	cronJob.Spec.JobTemplate.Spec.Template.Spec.Volumes = []corev1.Volume{{
+		Name:         "config",
+		VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "configmap-cronjob"}}},
+	}}
+	if _, err := clientset.BatchV1().CronJobs(testNamespace).Create(context.TODO(), cronJob, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	unused, err := processNamespaceCM(context.TODO(), clientset, testNamespace, &filters.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unused) != 1 || unused[0].Name != "configmap-unused" {
+		t.Errorf("Expected the configmaps of the pod templates to be used, got %v", unused)
+	}
+}
+
+func TestRetrieveUsedCMInitAndEphemeralContainers(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	pod := CreateTestPod(testNamespace, "pod", "", nil, AppLabels)
+	pod.Spec.InitContainers = []corev1.Container{{
+		Name:    "migrate",
+		EnvFrom: []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "configmap-init"}}}},
+	}}
+	pod.Spec.EphemeralContainers = []corev1.EphemeralContainer{{EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+		Name: "debug",
+		Env:  []corev1.EnvVar{{Name: "LEVEL", ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "configmap-debug"}}}}},
+	}}}
+	if _, err := clientset.CoreV1().Pods(testNamespace).Create(context.TODO(), pod, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, envCM, _, _, envFromInitContainerCM, err := retrieveUsedCM(context.TODO(), clientset, testNamespace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalSlices(envCM, []string{"configmap-debug"}) {
+		t.Errorf("Expected the configmap of the ephemeral container, got %v", envCM)
+	}
+	if !equalSlices(envFromInitContainerCM, []string{"configmap-init"}) {
+		t.Errorf("Expected the configmap of the init container, got %v", envFromInitContainerCM)
+	}
+}
+
 func TestGetUnusedConfigmapsStructured(t *testing.T) {
 	clientset := createTestConfigmaps(t)
 
@@ -207,7 +271,7 @@ func TestGetUnusedConfigmapsStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedConfigmaps(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedConfigmaps(context.Background(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedConfigmapsStructured: %v", err)
 	}
@@ -231,6 +295,199 @@ func TestGetUnusedConfigmapsStructured(t *testing.T) {
 	}
 }
 
+func TestProcessDuplicateConfigMaps(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	for _, namespace := range []string{testNamespace, "other-namespace"} {
+		_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: namespace},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating namespace %s: %v", namespace, err)
+		}
+	}
+
+	configmaps := []*corev1.ConfigMap{
+		CreateTestConfigmap(testNamespace, "configmap-1", AppLabels),
+		CreateTestConfigmap(testNamespace, "configmap-2", AppLabels),
+		CreateTestConfigmap(testNamespace, "configmap-3", AppLabels),
+		CreateTestConfigmap(testNamespace, "configmap-4", AppLabels),
+		CreateTestConfigmap("other-namespace", "configmap-5", AppLabels),
+	}
+	configmaps[0].Data = map[string]string{"key": "value"}
+	configmaps[1].Data = map[string]string{"key": "value"}
+	configmaps[2].Data = map[string]string{"key": "other-value"}
+	configmaps[4].Data = map[string]string{"key": "other-value"}
+
+	for _, configmap := range configmaps {
+		_, err := clientset.CoreV1().ConfigMaps(configmap.Namespace).Create(context.TODO(), configmap, metav1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake configmap: %v", err)
+		}
+	}
+
+	namespaces := []string{testNamespace, "other-namespace"}
+
+	duplicates, err := processDuplicateConfigMaps(context.Background(), clientset, namespaces, &filters.Options{}, false)
+	if err != nil {
+		t.Fatalf("Error processing duplicate configmaps: %v", err)
+	}
+
+	expected := map[string][]ResourceInfo{
+		testNamespace: {
+			{Name: "configmap-1", Reason: "ConfigMap has identical content to configmap-2"},
+			{Name: "configmap-2", Reason: "ConfigMap has identical content to configmap-1"},
+		},
+	}
+	if !reflect.DeepEqual(duplicates, expected) {
+		t.Errorf("Expected duplicates %v, got %v", expected, duplicates)
+	}
+
+	duplicates, err = processDuplicateConfigMaps(context.Background(), clientset, namespaces, &filters.Options{}, true)
+	if err != nil {
+		t.Fatalf("Error processing duplicate configmaps: %v", err)
+	}
+
+	expected = map[string][]ResourceInfo{
+		testNamespace: {
+			{Name: "configmap-1", Reason: "ConfigMap has identical content to test-namespace/configmap-2"},
+			{Name: "configmap-2", Reason: "ConfigMap has identical content to test-namespace/configmap-1"},
+			{Name: "configmap-3", Reason: "ConfigMap has identical content to other-namespace/configmap-5"},
+		},
+		"other-namespace": {
+			{Name: "configmap-5", Reason: "ConfigMap has identical content to test-namespace/configmap-3"},
+		},
+	}
+	if !reflect.DeepEqual(duplicates, expected) {
+		t.Errorf("Expected duplicates %v, got %v", expected, duplicates)
+	}
+}
+
+func TestProcessNamespaceCMKeys(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: testNamespace},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+
+	keys := map[string]string{"key-1": "value", "key-2": "value", "key-3": "value"}
+	configmaps := []*corev1.ConfigMap{
+		CreateTestConfigmap(testNamespace, "env-configmap", AppLabels),
+		CreateTestConfigmap(testNamespace, "items-configmap", AppLabels),
+		CreateTestConfigmap(testNamespace, "subpath-configmap", AppLabels),
+		CreateTestConfigmap(testNamespace, "envfrom-configmap", AppLabels),
+		CreateTestConfigmap(testNamespace, "unused-configmap", AppLabels),
+	}
+	for _, configmap := range configmaps {
+		configmap.Data = keys
+		_, err = clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), configmap, metav1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake configmap: %v", err)
+		}
+	}
+
+	pod := CreateTestPod(testNamespace, "pod-1", "", []corev1.Volume{
+		{
+			Name: "items",
+			VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "items-configmap"},
+				Items:                []corev1.KeyToPath{{Key: "key-1", Path: "file"}},
+			}},
+		},
+		{
+			Name:         "subpath",
+			VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "subpath-configmap"}}},
+		},
+	}, AppLabels)
+	pod.Spec.Containers = []corev1.Container{
+		{
+			Env: []corev1.EnvVar{
+				{
+					Name:      "ENV_VAR_1",
+					ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "env-configmap"}, Key: "key-1"}},
+				},
+			},
+			VolumeMounts: []corev1.VolumeMount{
+				{Name: "items", MountPath: "/items"},
+				{Name: "subpath", MountPath: "/etc/key-2", SubPath: "key-2"},
+			},
+		},
+	}
+	pod.Spec.InitContainers = []corev1.Container{
+		{
+			EnvFrom: []corev1.EnvFromSource{
+				{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "envfrom-configmap"}}},
+			},
+		},
+	}
+	_, err = clientset.CoreV1().Pods(testNamespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake pod: %v", err)
+	}
+
+	diff, err := processNamespaceCMKeys(context.Background(), clientset, testNamespace, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Error processing namespace CM keys: %v", err)
+	}
+
+	reason := "ConfigMap key is not referenced by any pod or container"
+	expected := []ResourceInfo{
+		{Name: "env-configmap/key-2", Reason: reason},
+		{Name: "env-configmap/key-3", Reason: reason},
+		{Name: "items-configmap/key-2", Reason: reason},
+		{Name: "items-configmap/key-3", Reason: reason},
+		{Name: "subpath-configmap/key-1", Reason: reason},
+		{Name: "subpath-configmap/key-3", Reason: reason},
+	}
+	if !equalResourceInfoSlices(diff, expected) {
+		t.Errorf("Expected diff %v, got %v", expected, diff)
+	}
+}
+
+func BenchmarkProcessNamespaceCM(b *testing.B) {
+	clientset := fake.NewSimpleClientset()
+	for i := range 1000 {
+		name := fmt.Sprintf("configmap-%d", i)
+		if _, err := clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), CreateTestConfigmap(testNamespace, name, AppLabels), metav1.CreateOptions{}); err != nil {
+			b.Fatal(err)
+		}
+		if i%2 == 0 {
+			continue
+		}
+		volume := corev1.Volume{Name: name, VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: name}}}}
+		if _, err := clientset.CoreV1().Pods(testNamespace).Create(context.TODO(), CreateTestPod(testNamespace, fmt.Sprintf("pod-%d", i), "", []corev1.Volume{volume}, AppLabels), metav1.CreateOptions{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ResetTimer()
+	for range b.N {
+		if _, err := processNamespaceCM(context.TODO(), clientset, testNamespace, &filters.Options{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestConfigMapsListSelectors(t *testing.T) {
+	clientset := createTestConfigmaps(t)
+	filterOpts := &filters.Options{ExcludeLabels: []string{"team=payments"}, IncludeNames: []string{"configmap-1"}}
+	if _, err := processNamespaceCM(context.TODO(), clientset, testNamespace, filterOpts); err != nil {
+		t.Fatal(err)
+	}
+
+	var restrictions []k8stesting.ListRestrictions
+	for _, action := range clientset.Actions() {
+		if list, ok := action.(k8stesting.ListAction); ok && action.GetResource().Resource == "configmaps" {
+			restrictions = append(restrictions, list.GetListRestrictions())
+		}
+	}
+	if len(restrictions) != 1 || restrictions[0].Labels.String() != "team!=payments" || restrictions[0].Fields.String() != "metadata.name=configmap-1" {
+		t.Errorf("Expected the filters to be passed to the API server, got %+v", restrictions)
+	}
+}
+
 func init() {
 	scheme.Scheme = runtime.NewScheme()
 	_ = appsv1.AddToScheme(scheme.Scheme)
@@ -0,0 +1,39 @@
+package kor
+
+import (
+	"context"
+	"time"
+
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+// requestTimeout bounds each API request, zero means no timeout.
+var requestTimeout time.Duration
+
+// SetRequestTimeout sets the timeout of each API request made by the clients
+// created afterwards.
+func SetRequestTimeout(timeout time.Duration) {
+	requestTimeout = timeout
+}
+
+// SetChunkSize sets the number of objects requested per page of the List
+// calls, zero lists everything in a single request.
+func SetChunkSize(size int64) {
+	utils.ListChunkSize = size
+}
+
+type scanClusterKey struct{}
+
+// withScanCluster returns a copy of ctx scanning cluster, the kubeconfig
+// context of a scan across several contexts, so the failures of its
+// detectors are told apart.
+func withScanCluster(ctx context.Context, cluster string) context.Context {
+	return context.WithValue(ctx, scanClusterKey{}, cluster)
+}
+
+// scanCluster returns the cluster ctx scans, empty outside of the scans
+// across several contexts.
+func scanCluster(ctx context.Context) string {
+	cluster, _ := ctx.Value(scanClusterKey{}).(string)
+	return cluster
+}
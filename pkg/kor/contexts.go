@@ -0,0 +1,87 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// GetUnusedContexts scans the clusters of the kubeconfig contexts at the same
+// time, like GetUnusedClusters.
+func GetUnusedContexts(ctx context.Context, kubeconfig string, contexts []string, resourceList []string, filterOpts *filters.Options, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedClusters(ctx, ContextClusters(kubeconfig, contexts), resourceList, filterOpts, outputFormat, opts)
+}
+
+// GetUnusedClusters scans the clusters at the same time, for the resources
+// of resourceList or every resource when empty, with the exclusions of each
+// cluster on top of filterOpts. It renders a single report in which each
+// resource carries the name of its cluster. A cluster kor fails to connect
+// to is reported as a failed scan, the others are scanned regardless.
+func GetUnusedClusters(ctx context.Context, clusters []Cluster, resourceList []string, filterOpts *filters.Options, outputFormat string, opts common.Opts) (string, error) {
+	for _, resource := range resourceList {
+		if lookupDetector(resource) == nil {
+			return "", fmt.Errorf("resource type %q is not supported", resource)
+		}
+	}
+
+	scans := make([][]Resource, len(clusters))
+	var wg sync.WaitGroup
+	for i, cluster := range clusters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			scans[i] = scanClusterResources(ctx, cluster, resourceList, cluster.filterOptions(filterOpts), opts)
+		}()
+	}
+	wg.Wait()
+
+	var resources []Resource
+	for i, scan := range scans {
+		report := groupResources(scan, opts.GroupBy)
+		recordClusterUnusedResources(clusters[i].Name, report, opts.GroupBy)
+		// The report without the resources --unused-for held back
+		scan = reportResources(report, opts.GroupBy)
+		for j := range scan {
+			scan[j].Cluster = clusters[i].Name
+		}
+		resources = append(resources, scan...)
+	}
+	var output bytes.Buffer
+	if err := FormatResources(&output, outputFormat, resources, opts); err != nil {
+		return "", err
+	}
+	return output.String(), nil
+}
+
+// scanClusterResources returns the unused resources of cluster.
+func scanClusterResources(ctx context.Context, cluster Cluster, resourceList []string, filterOpts *filters.Options, opts common.Opts) []Resource {
+	clients, err := NewClients(cluster.Kubeconfig, cluster.Context)
+	if err == nil {
+		// The clients connect lazily, an unreachable cluster would only
+		// report nothing
+		_, err = clients.Kubernetes.Discovery().ServerVersion()
+	}
+	if err != nil {
+		recordClusterScanError(cluster.Name, "", "", err)
+		return nil
+	}
+	ctx = withScanCluster(ctx, cluster.Name)
+
+	var report map[string]map[string][]ResourceInfo
+	if len(resourceList) == 0 {
+		report = collectAllResources(ctx, filterOpts, clients.Kubernetes, clients.APIExtensions, clients.Dynamic, opts.GroupBy)
+	} else {
+		report = collectResources(ctx, resourceList, filterOpts, clients.Kubernetes, clients.APIExtensions, clients.Dynamic, opts.GroupBy)
+	}
+	enrichResources(ctx, clients.Kubernetes, report, opts)
+	recordScannedNamespaces(ctx, cluster.Name, filterOpts, clients.Kubernetes)
+	resources := reportResources(report, opts.GroupBy)
+	for i := range resources {
+		resources[i].Cluster = cluster.Name
+	}
+	return resources
+}
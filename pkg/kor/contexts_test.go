@@ -0,0 +1,133 @@
+package kor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// newFakeAPIServer serves a cluster with the default namespace holding the
+// ConfigMap configMap, every other list is empty.
+func newFakeAPIServer(t *testing.T, configMap string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/version":
+			_, _ = w.Write([]byte(`{"major":"1","minor":"31","gitVersion":"v1.31.0"}`))
+		case "/api/v1/namespaces":
+			_, _ = w.Write([]byte(`{"kind":"NamespaceList","apiVersion":"v1","items":[{"metadata":{"name":"default"}}]}`))
+		case "/api/v1/namespaces/default/configmaps":
+			fmt.Fprintf(w, `{"kind":"ConfigMapList","apiVersion":"v1","items":[{"metadata":{"name":%q,"namespace":"default"}}]}`, configMap)
+		default:
+			_, _ = w.Write([]byte(`{"metadata":{},"items":[]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// writeTestKubeconfig writes a kubeconfig with a context per server, named
+// like its cluster.
+func writeTestKubeconfig(t *testing.T, servers map[string]string) string {
+	var kubeconfig strings.Builder
+	kubeconfig.WriteString("apiVersion: v1\nkind: Config\nclusters:\n")
+	for name, server := range servers {
+		fmt.Fprintf(&kubeconfig, "- name: %s\n  cluster:\n    server: %s\n", name, server)
+	}
+	kubeconfig.WriteString("contexts:\n")
+	for name := range servers {
+		fmt.Fprintf(&kubeconfig, "- name: %s\n  context:\n    cluster: %s\n", name, name)
+	}
+	configFile := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(configFile, []byte(kubeconfig.String()), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return configFile
+}
+
+func TestGetUnusedContexts(t *testing.T) {
+	staging := newFakeAPIServer(t, "configmap-staging")
+	production := newFakeAPIServer(t, "configmap-production")
+	offline := httptest.NewServer(http.NotFoundHandler())
+	offline.Close()
+
+	configFile := writeTestKubeconfig(t, map[string]string{"staging": staging.URL, "production": production.URL, "offline": offline.URL})
+
+	resetReportedResources()
+	defer resetReportedResources()
+	output, err := GetUnusedContexts(context.Background(), configFile, []string{"staging", "production", "offline"}, []string{"configmap"}, &filters.Options{}, "json", common.Opts{GroupBy: "namespace"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{
+  "production": {
+    "default": {
+      "ConfigMap": [
+        "configmap-production"
+      ]
+    }
+  },
+  "staging": {
+    "default": {
+      "ConfigMap": [
+        "configmap-staging"
+      ]
+    }
+  }
+}`
+	if output != expected {
+		t.Errorf("Expected the report of both clusters, got %s", output)
+	}
+	if count := UnusedResourceCount(); count != 2 {
+		t.Errorf("Expected 2 recorded resources, got %d", count)
+	}
+
+	var scanErr *ScanError
+	if err := ScanErrors(); !errors.As(err, &scanErr) || scanErr.Cluster != "offline" || !strings.HasPrefix(scanErr.Error(), "failed to scan cluster offline: ") {
+		t.Errorf("Expected the offline cluster to fail, got %v", err)
+	}
+
+	if _, err := GetUnusedContexts(context.Background(), configFile, []string{"staging"}, []string{"unknown"}, &filters.Options{}, "json", common.Opts{GroupBy: "namespace"}); err == nil {
+		t.Error("Expected an error for an unknown resource")
+	}
+}
+
+func TestGetUnusedClusters(t *testing.T) {
+	configFile := writeTestKubeconfig(t, map[string]string{
+		"staging":    newFakeAPIServer(t, "configmap-staging").URL,
+		"production": newFakeAPIServer(t, "configmap-production").URL,
+	})
+	clusters := []Cluster{
+		{Name: "eu-staging", Kubeconfig: configFile, Context: "staging", ExcludeNames: []string{"configmap-.*"}},
+		{Name: "eu-production", Kubeconfig: configFile, Context: "production"},
+	}
+
+	resetReportedResources()
+	defer resetReportedResources()
+	filterOpts := &filters.Options{ExcludeNames: []string{"other"}}
+	output, err := GetUnusedClusters(context.Background(), clusters, []string{"configmap"}, filterOpts, "csv", common.Opts{GroupBy: "namespace"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `Cluster,Namespace,Kind,Name,Reason,Created,Size,Owners,Managed By,Helm Release
+eu-production,default,ConfigMap,configmap-production,ConfigMap is not used in any pod or container,,,,,
+`
+	if output != expected {
+		t.Errorf("Expected the exclusions of the staging cluster to apply to it only, got\n%s", output)
+	}
+	if len(filterOpts.ExcludeNames) != 1 {
+		t.Errorf("Expected the filters of the scan to be left untouched, got %v", filterOpts.ExcludeNames)
+	}
+	if scope := newHistoryScope(nil, scannedNamespaces.clusters); !scope.covers("eu-production", "default", "ConfigMap") || scope.covers("", "default", "ConfigMap") {
+		t.Errorf("Expected the history to cover the namespaces scanned in each cluster, got %v", scannedNamespaces.clusters)
+	}
+}
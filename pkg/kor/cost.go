@@ -0,0 +1,208 @@
+package kor
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Pricing is the layout of the file passed with --pricing-file, the monthly
+// prices used to estimate the cost of unused resources with --show-cost.
+type Pricing struct {
+	Currency string `json:"currency,omitempty"`
+	// Storage is the price of a GiB of volume per month
+	Storage StoragePricing `json:"storage,omitempty"`
+	// LoadBalancer is the price of the load balancer of a Service per month
+	LoadBalancer float64     `json:"loadBalancer,omitempty"`
+	Node         NodePricing `json:"node,omitempty"`
+}
+
+// StoragePricing prices the volumes of the storage classes of Classes, and
+// the others at Default.
+type StoragePricing struct {
+	Default float64            `json:"default,omitempty"`
+	Classes map[string]float64 `json:"classes,omitempty"`
+}
+
+// NodePricing prices the nodes of the instance types of InstanceTypes per
+// month, and the others from the price of a CPU and a GiB of memory of their
+// capacity per month.
+type NodePricing struct {
+	CPU           float64            `json:"cpu,omitempty"`
+	Memory        float64            `json:"memory,omitempty"`
+	InstanceTypes map[string]float64 `json:"instanceTypes,omitempty"`
+}
+
+// defaultPricing roughly matches the on-demand list prices of the big cloud
+// providers, a ballpark until a pricing file gives the prices of the cluster.
+var defaultPricing = Pricing{
+	Currency:     "USD",
+	Storage:      StoragePricing{Default: 0.10},
+	LoadBalancer: 18.25,
+	Node:         NodePricing{CPU: 23, Memory: 3},
+}
+
+// pricing holds the prices of --pricing-file over defaultPricing.
+var pricing = defaultPricing
+
+const gibibyte = 1 << 30
+
+// LoadPricing reads the prices of the pricing file at path over the default
+// prices, an empty path restores the defaults.
+func LoadPricing(path string) error {
+	pricing = defaultPricing
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read pricing file: %w", err)
+	}
+	loaded := defaultPricing
+	if err := yaml.UnmarshalStrict(data, &loaded); err != nil {
+		return fmt.Errorf("failed to parse pricing file %s: %w", path, err)
+	}
+	prices := map[string]float64{
+		"storage.default": loaded.Storage.Default,
+		"loadBalancer":    loaded.LoadBalancer,
+		"node.cpu":        loaded.Node.CPU,
+		"node.memory":     loaded.Node.Memory,
+	}
+	for class, price := range loaded.Storage.Classes {
+		prices["storage.classes."+class] = price
+	}
+	for instanceType, price := range loaded.Node.InstanceTypes {
+		prices["node.instanceTypes."+instanceType] = price
+	}
+	for key, price := range prices {
+		if price < 0 {
+			return fmt.Errorf("invalid price %s in %s, prices cannot be negative", key, path)
+		}
+	}
+	pricing = loaded
+	return nil
+}
+
+// resourceCost estimates the monthly cost of the volumes, LoadBalancer
+// Services and nodes. It is nil for the other resources, which cost nothing
+// by themselves.
+func resourceCost(obj interface{}) *Cost {
+	var cost Cost
+	switch o := obj.(type) {
+	case *corev1.PersistentVolumeClaim:
+		size := resourceSize(o)
+		if size == nil {
+			return nil
+		}
+		var class string
+		if o.Spec.StorageClassName != nil {
+			class = *o.Spec.StorageClassName
+		}
+		cost = Cost(storagePrice(class) * float64(size.Value()) / gibibyte)
+	case *corev1.PersistentVolume:
+		size := resourceSize(o)
+		if size == nil {
+			return nil
+		}
+		cost = Cost(storagePrice(o.Spec.StorageClassName) * float64(size.Value()) / gibibyte)
+	case *corev1.Service:
+		if o.Spec.Type != corev1.ServiceTypeLoadBalancer {
+			return nil
+		}
+		cost = Cost(pricing.LoadBalancer)
+	case *corev1.Node:
+		instanceType := o.Labels[corev1.LabelInstanceTypeStable]
+		if instanceType == "" {
+			instanceType = o.Labels[corev1.LabelInstanceType]
+		}
+		if price, ok := pricing.Node.InstanceTypes[instanceType]; ok && instanceType != "" {
+			cost = Cost(price)
+		} else {
+			cost = Cost(pricing.Node.CPU*float64(o.Status.Capacity.Cpu().MilliValue())/1000 +
+				pricing.Node.Memory*float64(o.Status.Capacity.Memory().Value())/gibibyte)
+		}
+	default:
+		return nil
+	}
+	return &cost
+}
+
+func storagePrice(class string) float64 {
+	if price, ok := pricing.Storage.Classes[class]; ok {
+		return price
+	}
+	return pricing.Storage.Default
+}
+
+// Cost is an estimated monthly cost, in the currency of the prices.
+type Cost float64
+
+// String renders the cost with two decimals.
+func (c Cost) String() string {
+	return fmt.Sprintf("%.2f", float64(c))
+}
+
+// costHeader names the cost columns, with the currency of the prices.
+func costHeader(name string) string {
+	if pricing.Currency == "" {
+		return name
+	}
+	return fmt.Sprintf("%s (%s)", name, pricing.Currency)
+}
+
+// formatCostSummary renders the estimated monthly cost of the unused
+// resources per resource type and namespace, followed by the overall total.
+// The resources that cost nothing by themselves are left out.
+func formatCostSummary(resources map[string]map[string][]ResourceInfo, groupBy string) string {
+	type costKey struct {
+		kind      string
+		namespace string
+	}
+	counts := make(map[costKey]int)
+	costs := make(map[costKey]Cost)
+	var total Cost
+	for outerKey, inner := range resources {
+		for innerKey, infos := range inner {
+			namespace, kind := outerKey, innerKey
+			if groupBy == "resource" {
+				namespace, kind = innerKey, outerKey
+			}
+			for _, info := range infos {
+				if info.MonthlyCost == nil {
+					continue
+				}
+				key := costKey{kind: kind, namespace: namespace}
+				counts[key]++
+				costs[key] += *info.MonthlyCost
+				total += *info.MonthlyCost
+			}
+		}
+	}
+	keys := make([]costKey, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].kind != keys[j].kind {
+			return keys[i].kind < keys[j].kind
+		}
+		return keys[i].namespace < keys[j].namespace
+	})
+
+	var buf strings.Builder
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"#", "RESOURCE TYPE", "NAMESPACE", "COUNT", costHeader("MONTHLY COST")})
+	var count int
+	for index, key := range keys {
+		table.Append(getTableRow(index, key.kind, key.namespace, fmt.Sprintf("%d", counts[key]), costs[key].String()))
+		count += counts[key]
+	}
+	table.SetFooter([]string{"", "", "Total", fmt.Sprintf("%d", count), total.String()})
+	table.Render()
+	return fmt.Sprintf("Estimated monthly cost:\n%s\n", buf.String())
+}
@@ -0,0 +1,157 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+)
+
+func writeTestPricing(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "pricing.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPricing(t *testing.T) {
+	defer func() { _ = LoadPricing("") }()
+
+	path := writeTestPricing(t, `currency: EUR
+storage:
+  classes:
+    premium: 0.2
+node:
+  instanceTypes:
+    m5.large: 60
+`)
+	if err := LoadPricing(path); err != nil {
+		t.Fatal(err)
+	}
+	if pricing.Currency != "EUR" || storagePrice("premium") != 0.2 || storagePrice("standard") != defaultPricing.Storage.Default ||
+		pricing.LoadBalancer != defaultPricing.LoadBalancer || pricing.Node.InstanceTypes["m5.large"] != 60 {
+		t.Errorf("Expected the prices of the file over the defaults, got %+v", pricing)
+	}
+
+	for _, content := range []string{"loadBalancer: -1", "storage:\n  classes:\n    premium: -0.1", "disks: 0.1"} {
+		if err := LoadPricing(writeTestPricing(t, content)); err == nil {
+			t.Errorf("Expected an error for %q", content)
+		}
+	}
+	if err := LoadPricing(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected an error for a missing pricing file")
+	}
+
+	if err := LoadPricing(""); err != nil || pricing.Currency != "USD" {
+		t.Errorf("Expected an empty path to restore the defaults, got %+v, %v", pricing, err)
+	}
+}
+
+func TestResourceCost(t *testing.T) {
+	defer func() { _ = LoadPricing("") }()
+	path := writeTestPricing(t, `storage:
+  default: 0.1
+  classes:
+    premium: 0.3
+loadBalancer: 20
+node:
+  cpu: 10
+  memory: 2
+  instanceTypes:
+    m5.large: 70
+`)
+	if err := LoadPricing(path); err != nil {
+		t.Fatal(err)
+	}
+
+	pvc := CreateTestPvc(testNamespace, "pvc-1", AppLabels, "premium")
+	pvc.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}
+	pv := &corev1.PersistentVolume{Spec: corev1.PersistentVolumeSpec{Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("100Gi")}}}
+	loadBalancer := CreateTestService(testNamespace, "lb")
+	loadBalancer.Spec.Type = corev1.ServiceTypeLoadBalancer
+	instanceNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{corev1.LabelInstanceTypeStable: "m5.large"}}}
+	capacityNode := &corev1.Node{Status: corev1.NodeStatus{Capacity: corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("4"),
+		corev1.ResourceMemory: resource.MustParse("16Gi"),
+	}}}
+
+	tests := []struct {
+		name     string
+		obj      interface{}
+		expected string
+	}{
+		{"pvc of a priced storage class", pvc, "3.00"},
+		{"pv of the default price", pv, "10.00"},
+		{"load balancer", loadBalancer, "20.00"},
+		{"node of a priced instance type", instanceNode, "70.00"},
+		{"node priced from its capacity", capacityNode, "72.00"},
+		{"cluster ip service", CreateTestService(testNamespace, "svc"), ""},
+		{"configmap", CreateTestConfigmap(testNamespace, "cm", AppLabels), ""},
+	}
+	for _, test := range tests {
+		var cost string
+		if c := resourceCost(test.obj); c != nil {
+			cost = c.String()
+		}
+		if cost != test.expected {
+			t.Errorf("%s: expected cost %q, got %q", test.name, test.expected, cost)
+		}
+	}
+}
+
+func TestShowCost(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	pvc := CreateTestPvc(testNamespace, "pvc-1", AppLabels, "standard")
+	pvc.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("50Gi")}
+	if _, err := clientset.CoreV1().PersistentVolumeClaims(testNamespace).Create(context.TODO(), pvc, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating fake pvc: %v", err)
+	}
+	service := CreateTestService(testNamespace, "lb")
+	service.Spec.Type = corev1.ServiceTypeLoadBalancer
+	if _, err := clientset.CoreV1().Services(testNamespace).Create(context.TODO(), service, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating fake service: %v", err)
+	}
+	configmap := CreateTestConfigmap(testNamespace, "cm-1", AppLabels)
+	if _, err := clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), configmap, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating fake configmap: %v", err)
+	}
+
+	resources := map[string]map[string][]ResourceInfo{
+		testNamespace: {
+			"Pvc":       {{Name: "pvc-1"}},
+			"Service":   {{Name: "lb"}},
+			"ConfigMap": {{Name: "cm-1"}},
+		},
+	}
+	opts := common.Opts{GroupBy: "namespace", ShowCost: true}
+	enrichResources(context.Background(), clientset, resources, opts)
+	if cost := resources[testNamespace]["ConfigMap"][0].MonthlyCost; cost != nil {
+		t.Errorf("Expected configmaps to cost nothing, got %v", cost)
+	}
+
+	output := FormatOutput(resources, opts)
+	for _, expected := range []string{"MONTHLY COST (USD)", "5.00", "18.25", "<none>", "Estimated monthly cost:", "23.25"} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("Expected table output to contain %q, got:\n%s", expected, output.String())
+		}
+	}
+
+	var csv bytes.Buffer
+	if err := FormatResources(&csv, "csv", reportResources(resources, opts.GroupBy), opts); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(csv.String(), "\n")
+	if !strings.HasSuffix(lines[0], ",Monthly Cost") || !strings.Contains(csv.String(), ",5.00\n") {
+		t.Errorf("Expected a Monthly Cost column, got\n%s", csv.String())
+	}
+}
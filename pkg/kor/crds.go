@@ -6,7 +6,6 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
-	"os"
 
 	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -16,16 +15,17 @@ import (
 
 	"github.com/yonahd/kor/pkg/common"
 	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
 )
 
 //go:embed exceptions/crds/crds.json
 var crdsConfig []byte
 
-func processCrds(apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
+func processCrds(ctx context.Context, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
 
 	var unusedCRDs []ResourceInfo
 
-	crds, err := apiExtClient.ApiextensionsV1().CustomResourceDefinitions().List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	crds, err := utils.ListAll(ctx, metav1.ListOptions{LabelSelector: filterOpts.LabelSelector()}, apiExtClient.ApiextensionsV1().CustomResourceDefinitions().List)
 	if err != nil {
 		return nil, err
 	}
@@ -36,7 +36,7 @@ func processCrds(apiExtClient apiextensionsclientset.Interface, dynamicClient dy
 	}
 
 	for _, crd := range crds.Items {
-		if pass := filters.KorLabelFilter(&crd, &filters.Options{}); pass {
+		if pass, _ := filter.SetObject(&crd).Run(filterOpts, filters.LabelFilterName, filters.AgeFilterName); pass {
 			continue
 		}
 
@@ -54,7 +54,7 @@ func processCrds(apiExtClient apiextensionsclientset.Interface, dynamicClient dy
 			Version:  crd.Spec.Versions[0].Name, // We're checking the first version.
 			Resource: crd.Spec.Names.Plural,
 		}
-		instances, err := dynamicClient.Resource(gvr).Namespace("").List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+		instances, err := utils.ListAll(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels}, dynamicClient.Resource(gvr).Namespace("").List)
 		if err != nil {
 			return nil, err
 		}
@@ -66,11 +66,11 @@ func processCrds(apiExtClient apiextensionsclientset.Interface, dynamicClient dy
 	return unusedCRDs, nil
 }
 
-func GetUnusedCrds(_ *filters.Options, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
+func GetUnusedCrds(ctx context.Context, filterOpts *filters.Options, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
 	resources := make(map[string]map[string][]ResourceInfo)
-	diff, err := processCrds(apiExtClient, dynamicClient, &filters.Options{})
+	diff, err := processCrds(ctx, apiExtClient, dynamicClient, filterOpts)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to process crds: %v\n", err)
+		recordScanError("Crd", "", err)
 	}
 	switch opts.GroupBy {
 	case "namespace":
@@ -80,12 +80,14 @@ func GetUnusedCrds(_ *filters.Options, apiExtClient apiextensionsclientset.Inter
 		appendResources(resources, "Crd", "", diff)
 	}
 
+	recordUnusedResources(resources, opts.GroupBy)
+
 	var outputBuffer bytes.Buffer
 	var jsonResponse []byte
 	switch outputFormat {
 	case "table":
 		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
+	default:
 		var err error
 		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
 			return "", err
@@ -5,6 +5,7 @@ import (
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -176,6 +177,24 @@ func CreateTestEndpoint(namespace, name string, endpointSubsetCount int, labels
 		Subsets: make([]corev1.EndpointSubset, endpointSubsetCount),
 	}
 }
+func CreateTestEndpointSlice(namespace, name, serviceName string, labels map[string]string) *discoveryv1.EndpointSlice {
+	sliceLabels := make(map[string]string)
+	for key, value := range labels {
+		sliceLabels[key] = value
+	}
+	if serviceName != "" {
+		sliceLabels[discoveryv1.LabelServiceName] = serviceName
+	}
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: v1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Labels:    sliceLabels,
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+	}
+}
+
 func CreateTestHpa(namespace, name, deploymentName string, minReplicas, maxReplicas int32, labels map[string]string) *autoscalingv2.HorizontalPodAutoscaler {
 	return &autoscalingv2.HorizontalPodAutoscaler{
 		ObjectMeta: v1.ObjectMeta{
@@ -266,6 +285,31 @@ func CreateTestStorageClass(name, provisioner string) *storagev1.StorageClass {
 	}
 }
 
+func CreateTestCSIDriver(name string, labels map[string]string) *storagev1.CSIDriver {
+	return &storagev1.CSIDriver{
+		ObjectMeta: v1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+	}
+}
+
+func CreateTestVolumeAttachment(name, attacher, nodeName, pvName string, labels map[string]string) *storagev1.VolumeAttachment {
+	return &storagev1.VolumeAttachment{
+		ObjectMeta: v1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+		Spec: storagev1.VolumeAttachmentSpec{
+			Attacher: attacher,
+			NodeName: nodeName,
+			Source: storagev1.VolumeAttachmentSource{
+				PersistentVolumeName: &pvName,
+			},
+		},
+	}
+}
+
 func CreateTestPdb(namespace, name string, matchLabels, pdbLabels map[string]string) *policyv1.PodDisruptionBudget {
 	return &policyv1.PodDisruptionBudget{
 		ObjectMeta: v1.ObjectMeta{
@@ -292,6 +336,13 @@ func CreateTestSecret(namespace, name string, labels map[string]string) *corev1.
 	}
 }
 
+func CreateTestServiceAccountToken(namespace, name, serviceAccountName string, labels map[string]string) *corev1.Secret {
+	secret := CreateTestSecret(namespace, name, labels)
+	secret.Type = corev1.SecretTypeServiceAccountToken
+	secret.Annotations = map[string]string{corev1.ServiceAccountNameKey: serviceAccountName}
+	return secret
+}
+
 func CreateTestConfigmap(namespace, name string, labels map[string]string) *corev1.ConfigMap {
 	return &corev1.ConfigMap{
 		ObjectMeta: v1.ObjectMeta{
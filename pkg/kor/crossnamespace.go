@@ -0,0 +1,192 @@
+package kor
+
+import (
+	"context"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+var (
+	apiServicesResource = schema.GroupVersionResource{Group: "apiregistration.k8s.io", Version: "v1", Resource: "apiservices"}
+	gatewaysResource    = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "gateways"}
+	// routeResources are the Gateway API routes forwarding to backendRefs
+	routeResources = []schema.GroupVersionResource{
+		{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"},
+		{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "grpcroutes"},
+		{Group: "gateway.networking.k8s.io", Version: "v1alpha2", Resource: "tlsroutes"},
+		{Group: "gateway.networking.k8s.io", Version: "v1alpha2", Resource: "tcproutes"},
+		{Group: "gateway.networking.k8s.io", Version: "v1alpha2", Resource: "udproutes"},
+	}
+)
+
+// crossNamespaceIndex holds the Services and Secrets the objects of the
+// whole cluster reference, by kind and namespace: the ones of the admission
+// webhooks, APIServices, Gateways and routes, which may be in any namespace.
+type crossNamespaceIndex struct {
+	once       sync.Once
+	references map[string]map[string][]string
+	err        error
+}
+
+// crossNamespaceIndexes are the indexes of the running scans, by clientset.
+var crossNamespaceIndexes sync.Map
+
+func baseClientset(clientset kubernetes.Interface) kubernetes.Interface {
+	if wrapped, ok := clientset.(*listSourceClientset); ok {
+		return wrapped.Interface
+	}
+	return clientset
+}
+
+// resetCrossNamespaceIndex makes the next scan with clientset build the
+// index again.
+func resetCrossNamespaceIndex(clientset kubernetes.Interface) {
+	crossNamespaceIndexes.Delete(baseClientset(clientset))
+}
+
+// retrieveCrossNamespaceReferences returns the Services or Secrets of kind in
+// namespace referenced across the cluster. The index is built once per scan.
+func retrieveCrossNamespaceReferences(ctx context.Context, clientset kubernetes.Interface, namespace, kind string) ([]string, error) {
+	value, _ := crossNamespaceIndexes.LoadOrStore(baseClientset(clientset), &crossNamespaceIndex{})
+	index := value.(*crossNamespaceIndex)
+	index.once.Do(func() {
+		index.references, index.err = buildCrossNamespaceIndex(ctx, clientset)
+	})
+	if index.err != nil {
+		return nil, index.err
+	}
+	return index.references[kind][namespace], nil
+}
+
+func buildCrossNamespaceIndex(ctx context.Context, clientset kubernetes.Interface) (map[string]map[string][]string, error) {
+	references := map[string]map[string][]string{"Service": {}, "Secret": {}}
+	add := func(kind, namespace, name string) {
+		if namespace != "" && name != "" {
+			references[kind][namespace] = append(references[kind][namespace], name)
+		}
+	}
+
+	validating, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().List)
+	if err != nil && !skippableListError(err) {
+		return nil, err
+	}
+	if err == nil {
+		for _, configuration := range validating.Items {
+			for _, webhook := range configuration.Webhooks {
+				if service := webhook.ClientConfig.Service; service != nil {
+					add("Service", service.Namespace, service.Name)
+				}
+			}
+		}
+	}
+	mutating, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.AdmissionregistrationV1().MutatingWebhookConfigurations().List)
+	if err != nil && !skippableListError(err) {
+		return nil, err
+	}
+	if err == nil {
+		for _, configuration := range mutating.Items {
+			for _, webhook := range configuration.Webhooks {
+				if service := webhook.ClientConfig.Service; service != nil {
+					add("Service", service.Namespace, service.Name)
+				}
+			}
+		}
+	}
+
+	client, ok := dynamicClientFor(clientset)
+	if !ok {
+		return references, nil
+	}
+
+	apiServices, err := listClusterObjects(ctx, client, apiServicesResource)
+	if err != nil {
+		return nil, err
+	}
+	for _, apiService := range apiServices {
+		namespace, _, _ := unstructured.NestedString(apiService.Object, "spec", "service", "namespace")
+		name, _, _ := unstructured.NestedString(apiService.Object, "spec", "service", "name")
+		add("Service", namespace, name)
+	}
+
+	gateways, err := listClusterObjects(ctx, client, gatewaysResource)
+	if err != nil {
+		return nil, err
+	}
+	for _, gateway := range gateways {
+		listeners, _, _ := unstructured.NestedSlice(gateway.Object, "spec", "listeners")
+		for _, listener := range listeners {
+			listener, _ := listener.(map[string]interface{})
+			certificateRefs, _, _ := unstructured.NestedSlice(listener, "tls", "certificateRefs")
+			for _, ref := range certificateRefs {
+				if namespace, name, ok := gatewayReference(ref, gateway.GetNamespace(), "Secret"); ok {
+					add("Secret", namespace, name)
+				}
+			}
+		}
+	}
+
+	for _, resource := range routeResources {
+		routes, err := listClusterObjects(ctx, client, resource)
+		if err != nil {
+			return nil, err
+		}
+		for _, route := range routes {
+			rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+			for _, rule := range rules {
+				rule, _ := rule.(map[string]interface{})
+				backendRefs, _, _ := unstructured.NestedSlice(rule, "backendRefs")
+				for _, ref := range backendRefs {
+					if namespace, name, ok := gatewayReference(ref, route.GetNamespace(), "Service"); ok {
+						add("Service", namespace, name)
+					}
+				}
+			}
+		}
+	}
+	return references, nil
+}
+
+// listClusterObjects lists the objects of resource in every namespace, or
+// none when the API server does not serve it or kor may not list it.
+func listClusterObjects(ctx context.Context, client dynamic.Interface, resource schema.GroupVersionResource) ([]unstructured.Unstructured, error) {
+	list, err := utils.ListAll(ctx, metav1.ListOptions{}, client.Resource(resource).List)
+	if skippableListError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func skippableListError(err error) bool {
+	return apierrors.IsNotFound(err) || apierrors.IsForbidden(err)
+}
+
+// gatewayReference returns the namespace and name of a Gateway API object
+// reference to a core object of kind, its namespace defaults to the one of
+// the referencing object.
+func gatewayReference(ref interface{}, namespace, kind string) (string, string, bool) {
+	object, ok := ref.(map[string]interface{})
+	if !ok {
+		return "", "", false
+	}
+	group, _, _ := unstructured.NestedString(object, "group")
+	refKind, found, _ := unstructured.NestedString(object, "kind")
+	if group != "" || (found && refKind != kind) {
+		return "", "", false
+	}
+	name, _, _ := unstructured.NestedString(object, "name")
+	if refNamespace, found, _ := unstructured.NestedString(object, "namespace"); found && refNamespace != "" {
+		namespace = refNamespace
+	}
+	return namespace, name, name != ""
+}
@@ -0,0 +1,119 @@
+package kor
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// crossNamespaceListKinds are the list kinds the fake dynamic clients need
+// to build the cross-namespace index.
+func crossNamespaceListKinds(kinds map[schema.GroupVersionResource]string) map[schema.GroupVersionResource]string {
+	listKinds := map[schema.GroupVersionResource]string{
+		apiServicesResource: "APIServiceList",
+		gatewaysResource:    "GatewayList",
+	}
+	for _, resource := range routeResources {
+		listKinds[resource] = strings.TrimSuffix(strings.ToUpper(resource.Resource[:1])+resource.Resource[1:], "s") + "List"
+	}
+	maps.Copy(listKinds, kinds)
+	return listKinds
+}
+
+func TestCrossNamespaceReferences(t *testing.T) {
+	webhookPath := "/validate"
+	webhooks := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "webhooks"},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{{
+			Name:         "validate.example.com",
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{Service: &admissionregistrationv1.ServiceReference{Namespace: testNamespace, Name: "webhook", Path: &webhookPath}},
+		}},
+	}
+	gateway := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "Gateway",
+		"metadata":   map[string]interface{}{"name": "gateway", "namespace": "gateways"},
+		"spec": map[string]interface{}{"listeners": []interface{}{map[string]interface{}{
+			"name": "https",
+			"tls": map[string]interface{}{"certificateRefs": []interface{}{
+				map[string]interface{}{"name": "certificate", "namespace": testNamespace},
+				map[string]interface{}{"name": "gateway-certificate"},
+			}},
+		}}},
+	}}
+	route := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "HTTPRoute",
+		"metadata":   map[string]interface{}{"name": "route", "namespace": "gateways"},
+		"spec": map[string]interface{}{"rules": []interface{}{map[string]interface{}{
+			"backendRefs": []interface{}{
+				map[string]interface{}{"name": "backend", "namespace": testNamespace, "port": int64(80)},
+				map[string]interface{}{"name": "other", "namespace": testNamespace, "kind": "ServiceImport", "group": "multicluster.x-k8s.io"},
+			},
+		}}},
+	}}
+	dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), crossNamespaceListKinds(nil), route)
+	// The fake client would guess gatewaies as the resource of the Gateways
+	if _, err := dynamicClient.Resource(gatewaysResource).Namespace("gateways").Create(context.TODO(), gateway, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	clientset := fake.NewSimpleClientset(
+		webhooks,
+		CreateTestEndpoint(testNamespace, "webhook", 0, AppLabels),
+		CreateTestEndpoint(testNamespace, "backend", 0, AppLabels),
+		CreateTestEndpoint(testNamespace, "other", 0, AppLabels),
+		CreateTestSecret(testNamespace, "certificate", AppLabels),
+		CreateTestSecret(testNamespace, "secret-1", AppLabels),
+	)
+	dynamicClients.Store(clientset, dynamicClient)
+	defer dynamicClients.Delete(clientset)
+	defer resetCrossNamespaceIndex(clientset)
+
+	services, err := processNamespaceServices(context.TODO(), clientset, testNamespace, &filters.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := resourceInfoNames(services); !slices.Equal(names, []string{"other"}) {
+		t.Errorf("Expected the services of the webhook and the route of another namespace to be used, got %v", names)
+	}
+
+	secrets, err := processNamespaceSecret(context.TODO(), clientset, testNamespace, &filters.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := resourceInfoNames(secrets); !slices.Equal(names, []string{"secret-1"}) {
+		t.Errorf("Expected the certificate of the gateway of another namespace to be used, got %v", names)
+	}
+
+	if references, err := retrieveCrossNamespaceReferences(context.TODO(), clientset, "gateways", "Secret"); err != nil || !slices.Equal(references, []string{"gateway-certificate"}) {
+		t.Errorf("Expected the certificate of the namespace of the gateway, got %v, %v", references, err)
+	}
+
+	// The index is built again by the next scan
+	if _, err := clientset.CoreV1().Secrets(testNamespace).Create(context.TODO(), &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret-2", Namespace: testNamespace}}, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := dynamicClient.Resource(gatewaysResource).Namespace("gateways").Delete(context.TODO(), "gateway", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	secrets, err = processNamespaceSecret(context.TODO(), scanClientset(clientset), testNamespace, &filters.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := resourceInfoNames(secrets); !slices.Equal(names, []string{"certificate", "secret-1", "secret-2"}) {
+		t.Errorf("Expected the index to be built again for the new scan, got %v", names)
+	}
+}
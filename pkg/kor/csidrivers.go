@@ -0,0 +1,136 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+func retrieveUsedCSIDrivers(ctx context.Context, clientset kubernetes.Interface) ([]string, error) {
+	pvs, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.CoreV1().PersistentVolumes().List)
+	if err != nil {
+		return nil, err
+	}
+
+	scs, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.StorageV1().StorageClasses().List)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.CoreV1().Pods("").List)
+	if err != nil {
+		return nil, err
+	}
+
+	var usedCSIDrivers []string
+
+	// Iterate through each PV and check for CSI driver usage
+	for _, pv := range pvs.Items {
+		if pv.Spec.CSI != nil {
+			usedCSIDrivers = append(usedCSIDrivers, pv.Spec.CSI.Driver)
+		}
+	}
+
+	// StorageClasses reference CSI drivers through their provisioner
+	for _, sc := range scs.Items {
+		usedCSIDrivers = append(usedCSIDrivers, sc.Provisioner)
+	}
+
+	// Iterate through each Pod and check for inline CSI volumes
+	for _, pod := range pods.Items {
+		for _, volume := range pod.Spec.Volumes {
+			if volume.CSI != nil {
+				usedCSIDrivers = append(usedCSIDrivers, volume.CSI.Driver)
+			}
+		}
+	}
+
+	return usedCSIDrivers, nil
+}
+
+func processCSIDrivers(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	csiDrivers, err := utils.ListAll(ctx, metav1.ListOptions{LabelSelector: filterOpts.LabelSelector()}, clientset.StorageV1().CSIDrivers().List)
+	if err != nil {
+		return nil, err
+	}
+
+	var unusedCSIDrivers []ResourceInfo
+	csiDriverNames := make([]string, 0, len(csiDrivers.Items))
+
+	for _, csiDriver := range csiDrivers.Items {
+		if pass, _ := filter.SetObject(&csiDriver).Run(filterOpts, filters.LabelFilterName, filters.AgeFilterName); pass {
+			continue
+		}
+
+		if csiDriver.Labels["kor/used"] == "false" {
+			unusedCSIDrivers = append(unusedCSIDrivers, ResourceInfo{Name: csiDriver.Name, Reason: "Marked with unused label"})
+			continue
+		}
+
+		csiDriverNames = append(csiDriverNames, csiDriver.Name)
+	}
+
+	usedCSIDrivers, err := retrieveUsedCSIDrivers(ctx, clientset)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := CalculateResourceDifference(usedCSIDrivers, csiDriverNames)
+	for _, name := range diff {
+		unusedCSIDrivers = append(unusedCSIDrivers, ResourceInfo{Name: name, Reason: "CSIDriver is not used by any PV, StorageClass or Pod"})
+	}
+	return unusedCSIDrivers, nil
+}
+
+func GetUnusedCSIDrivers(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	resources := make(map[string]map[string][]ResourceInfo)
+	diff, err := processCSIDrivers(ctx, clientset, filterOpts)
+	if err != nil {
+		recordScanError("CSIDriver", "", err)
+	}
+	exportManifests(ctx, clientset, "", "CSIDriver", diff, opts)
+	if deleteEnabled(opts, "CSIDriver") {
+		if diff, err = DeleteResource(ctx, diff, clientset, "", "CSIDriver", opts.NoInteractive); err != nil {
+			slog.Error("Failed to delete CSIDriver", "name", diff, "error", err)
+		}
+	}
+	switch opts.GroupBy {
+	case "namespace":
+		resources[""] = make(map[string][]ResourceInfo)
+		resources[""]["CSIDriver"] = diff
+	case "resource":
+		appendResources(resources, "CSIDriver", "", diff)
+	}
+
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+	default:
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	unusedCSIDrivers, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return unusedCSIDrivers, nil
+}
@@ -0,0 +1,111 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestCSIDrivers(t *testing.T) *fake.Clientset {
+	clientset := fake.NewSimpleClientset()
+
+	for _, name := range []string{"pv.csi.kor.com", "sc.csi.kor.com", "inline.csi.kor.com", "unused.csi.kor.com"} {
+		_, err := clientset.StorageV1().CSIDrivers().Create(context.TODO(), CreateTestCSIDriver(name, AppLabels), v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake %s: %v", "CSIDriver", err)
+		}
+	}
+
+	_, err := clientset.StorageV1().CSIDrivers().Create(context.TODO(), CreateTestCSIDriver("marked.csi.kor.com", UnusedLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "CSIDriver", err)
+	}
+
+	pv1 := CreateTestPv("test-pv1", "Bound", AppLabels, "")
+	pv1.Spec.CSI = &corev1.CSIPersistentVolumeSource{Driver: "pv.csi.kor.com", VolumeHandle: "vol-1"}
+	_, err = clientset.CoreV1().PersistentVolumes().Create(context.TODO(), pv1, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "PV", err)
+	}
+
+	sc1 := CreateTestStorageClass("test-sc1", "sc.csi.kor.com")
+	_, err = clientset.StorageV1().StorageClasses().Create(context.TODO(), sc1, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "StorageClass", err)
+	}
+
+	inlineVolume := corev1.Volume{
+		Name: "inline",
+		VolumeSource: corev1.VolumeSource{
+			CSI: &corev1.CSIVolumeSource{Driver: "inline.csi.kor.com"},
+		},
+	}
+	pod1 := CreateTestPod(testNamespace, "test-pod1", "", []corev1.Volume{inlineVolume}, AppLabels)
+	_, err = clientset.CoreV1().Pods(testNamespace).Create(context.TODO(), pod1, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "Pod", err)
+	}
+
+	return clientset
+}
+
+func TestProcessCSIDrivers(t *testing.T) {
+	clientset := createTestCSIDrivers(t)
+
+	unusedCSIDrivers, err := processCSIDrivers(context.Background(), clientset, &filters.Options{})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	expected := []ResourceInfo{
+		{Name: "marked.csi.kor.com", Reason: "Marked with unused label"},
+		{Name: "unused.csi.kor.com", Reason: "CSIDriver is not used by any PV, StorageClass or Pod"},
+	}
+	if !equalResourceInfoSlices(unusedCSIDrivers, expected) {
+		t.Errorf("Expected %v, got %v", expected, unusedCSIDrivers)
+	}
+}
+
+func TestGetUnusedCSIDriversStructured(t *testing.T) {
+	clientset := createTestCSIDrivers(t)
+
+	opts := common.Opts{
+		WebhookURL:    "",
+		Channel:       "",
+		Token:         "",
+		DeleteFlag:    false,
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+
+	output, err := GetUnusedCSIDrivers(context.Background(), &filters.Options{}, clientset, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedCSIDriversStructured: %v", err)
+	}
+
+	expectedOutput := map[string]map[string][]string{
+		"": {
+			"CSIDriver": {
+				"marked.csi.kor.com",
+				"unused.csi.kor.com",
+			},
+		},
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+
+	if !reflect.DeepEqual(expectedOutput, actualOutput) {
+		t.Errorf("Expected output does not match actual output")
+	}
+}
@@ -0,0 +1,204 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/homedir"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// DefaultDaemonKeepResults is the number of results kor daemon keeps by default.
+const DefaultDaemonKeepResults = 30
+
+// DaemonOptions configures the scans of kor daemon.
+type DaemonOptions struct {
+	// Schedule is a cron expression or @every interval, in UTC
+	Schedule string
+	// ResultsDir receives a JSON report per scan, ~/.kor/results when empty
+	ResultsDir string
+	// KeepResults is the number of reports kept in ResultsDir, 0 keeps every report
+	KeepResults int
+	// RunOnStart scans right away instead of waiting for the first scheduled time
+	RunOnStart bool
+}
+
+// DefaultResultsDir returns the directory kor daemon writes its results to
+// without --results-dir.
+func DefaultResultsDir() string {
+	return filepath.Join(homedir.HomeDir(), ".kor", "results")
+}
+
+// daemon runs the scheduled scans of kor daemon.
+type daemon struct {
+	schedule scanSchedule
+	options  DaemonOptions
+	// scan returns the report of the resources it records
+	scan func() (string, error)
+	// publish delivers the report to the configured notifications
+	publish func(report string) error
+	// record keeps the findings of the scan in the state store
+	record func() error
+	now    func() time.Time
+}
+
+// Daemon scans the cluster on the schedule of daemonOptions until kor stops.
+// Each scan writes its results to the results directory and hands the report,
+// rendered as outputFormat, to publish. Failures are logged, the next
+// scheduled scan runs regardless.
+func Daemon(ctx context.Context, filterOptions *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts, resourceList []string, daemonOptions DaemonOptions, publish func(report string) error) error {
+	clientset = cachedClientset(ctx, clientset)
+	schedule, err := parseSchedule(daemonOptions.Schedule)
+	if err != nil {
+		return err
+	}
+	if daemonOptions.KeepResults < 0 {
+		return fmt.Errorf("invalid number of results to keep %d, must not be negative", daemonOptions.KeepResults)
+	}
+	if daemonOptions.ResultsDir == "" {
+		daemonOptions.ResultsDir = DefaultResultsDir()
+	}
+	// Each scan looks the namespaces up again, new ones are scanned as well
+	scanOptions := filterOptions
+	d := &daemon{
+		schedule: schedule,
+		options:  daemonOptions,
+		scan: func() (string, error) {
+			if tracer != nil {
+				tracer.startTrace()
+			}
+			// Only the current scan counts, notifications would otherwise repeat earlier findings
+			resetReportedResources()
+			scanOptions = filterOptions.Clone()
+			return getUnusedResources(ctx, scanOptions, clientset, apiExtClient, dynamicClient, outputFormat, opts, resourceList)
+		},
+		publish: publish,
+		record: func() error {
+			return RecordHistory(ctx, resourceList, scanOptions, clientset)
+		},
+		now: time.Now,
+	}
+	return d.loop(ctx)
+}
+
+func (d *daemon) loop(ctx context.Context) error {
+	slog.Info("Daemon started", "schedule", d.options.Schedule, "results", d.options.ResultsDir)
+	if d.options.RunOnStart {
+		d.runLogged()
+	}
+	for {
+		next := d.schedule.next(d.now())
+		if next.IsZero() {
+			return fmt.Errorf("schedule %q never fires", d.options.Schedule)
+		}
+		slog.Info("Next scan scheduled", "time", next)
+		timer := time.NewTimer(next.Sub(d.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+		d.runLogged()
+	}
+}
+
+func (d *daemon) runLogged() {
+	if err := d.run(); err != nil {
+		slog.Error("Scheduled scan failed", "error", err)
+	}
+}
+
+// run scans once, persists the results and publishes them. The results are
+// written even when publishing fails.
+func (d *daemon) run() error {
+	start := d.now()
+	slog.Info("Collecting unused resources")
+	report, err := d.scan()
+	if err != nil {
+		return err
+	}
+	path, err := writeDaemonResult(d.options.ResultsDir, start)
+	if err != nil {
+		return err
+	}
+	slog.Info("Scan finished", "unusedResources", len(reportedResources), "duration", d.now().Sub(start), "results", path)
+
+	var errs []error
+	if err := pruneDaemonResults(d.options.ResultsDir, d.options.KeepResults); err != nil {
+		errs = append(errs, err)
+	}
+	if d.record != nil {
+		if err := d.record(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if d.publish != nil {
+		if err := d.publish(report); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := FlushTelemetry(); err != nil {
+		slog.Error("Failed to export telemetry", "error", err)
+	}
+	return errors.Join(errs...)
+}
+
+// writeDaemonResult writes the resources reported by the scan started at now
+// to dir, as the JSON document of --webhook-url.
+func writeDaemonResult(dir string, now time.Time) (string, error) {
+	now = now.UTC()
+	data, err := json.MarshalIndent(resultDocument{GeneratedAt: now, Total: len(reportedResources), Resources: reportedInfo}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create the results directory: %w", err)
+	}
+	path := filepath.Join(dir, "kor-"+now.Format("20060102T150405Z")+".json")
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write the results: %w", err)
+	}
+	return path, nil
+}
+
+// pruneDaemonResults removes the oldest results of dir beyond keep, zero
+// keeps every result.
+func pruneDaemonResults(dir string, keep int) error {
+	if keep == 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read the results directory: %w", err)
+	}
+	var results []string
+	for _, entry := range entries {
+		if name := entry.Name(); !entry.IsDir() && strings.HasPrefix(name, "kor-") && strings.HasSuffix(name, ".json") {
+			results = append(results, name)
+		}
+	}
+	// The names sort by the time of the scan
+	sort.Strings(results)
+	var errs []error
+	for len(results) > keep {
+		if err := os.Remove(filepath.Join(dir, results[0])); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove old results: %w", err))
+		}
+		results = results[1:]
+	}
+	return errors.Join(errs...)
+}
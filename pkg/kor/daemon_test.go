@@ -0,0 +1,184 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func testDaemon(t *testing.T, keep int) (*daemon, *[]string) {
+	t.Helper()
+	now := time.Date(2026, 10, 19, 6, 0, 0, 0, time.UTC)
+	var published []string
+	d := &daemon{
+		options: DaemonOptions{ResultsDir: t.TempDir(), KeepResults: keep},
+		scan: func() (string, error) {
+			resetReportedResources()
+			recordUnusedResources(emailTestReport(), "namespace")
+			now = now.Add(time.Minute)
+			return "report", nil
+		},
+		publish: func(report string) error {
+			published = append(published, report)
+			return nil
+		},
+		now: func() time.Time { return now },
+	}
+	return d, &published
+}
+
+func daemonResults(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func TestDaemonRun(t *testing.T) {
+	defer resetReportedResources()
+	d, published := testDaemon(t, 0)
+
+	if err := d.run(); err != nil {
+		t.Fatalf("Expected the scan to succeed, got %v", err)
+	}
+	if !slices.Equal(*published, []string{"report"}) {
+		t.Errorf("Expected the report to be published, got %v", *published)
+	}
+	names := daemonResults(t, d.options.ResultsDir)
+	if !slices.Equal(names, []string{"kor-20261019T060000Z.json"}) {
+		t.Fatalf("Expected the results named after the scan, got %v", names)
+	}
+	data, err := os.ReadFile(filepath.Join(d.options.ResultsDir, names[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var document resultDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		t.Fatalf("Invalid results %s: %v", data, err)
+	}
+	if document.Total != 3 || len(document.Resources["default"]["Secret"]) != 1 || !document.GeneratedAt.Equal(time.Date(2026, 10, 19, 6, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected results %+v", document)
+	}
+}
+
+func TestDaemonRunErrors(t *testing.T) {
+	defer resetReportedResources()
+	d, _ := testDaemon(t, 0)
+	d.publish = func(string) error { return errors.New("slack is down") }
+	if err := d.run(); err == nil || !strings.Contains(err.Error(), "slack is down") {
+		t.Errorf("Expected the publish error, got %v", err)
+	}
+	if names := daemonResults(t, d.options.ResultsDir); len(names) != 1 {
+		t.Errorf("Expected the results to be written although publishing failed, got %v", names)
+	}
+
+	d.scan = func() (string, error) { return "", errors.New("forbidden") }
+	d.publish = func(string) error {
+		t.Error("Expected a failed scan not to be published")
+		return nil
+	}
+	if err := d.run(); err == nil || err.Error() != "forbidden" {
+		t.Errorf("Expected the scan error, got %v", err)
+	}
+}
+
+func TestDaemonKeepResults(t *testing.T) {
+	defer resetReportedResources()
+	d, _ := testDaemon(t, 2)
+	if err := os.WriteFile(filepath.Join(d.options.ResultsDir, "notes.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		if err := d.run(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := []string{"kor-20261019T060200Z.json", "kor-20261019T060300Z.json", "notes.txt"}
+	if names := daemonResults(t, d.options.ResultsDir); !slices.Equal(names, expected) {
+		t.Errorf("Expected the last 2 results to be kept, got %v", names)
+	}
+}
+
+func TestDaemonLoop(t *testing.T) {
+	defer resetReportedResources()
+	d, published := testDaemon(t, 0)
+	d.options.RunOnStart = true
+	d.schedule, _ = parseSchedule("0 6 * * 1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := d.loop(ctx); err != nil {
+		t.Fatalf("Expected the daemon to stop with the context, got %v", err)
+	}
+	if len(*published) != 1 {
+		t.Errorf("Expected a scan on start, got %d", len(*published))
+	}
+
+	d.schedule, _ = parseSchedule("0 0 30 2 *")
+	d.options.RunOnStart = false
+	if err := d.loop(context.Background()); err == nil {
+		t.Error("Expected a schedule that never fires to fail")
+	}
+}
+
+func TestDaemonOptions(t *testing.T) {
+	tests := map[string]DaemonOptions{
+		"invalid schedule": {Schedule: "every monday"},
+		"negative keep":    {Schedule: "@daily", KeepResults: -1},
+	}
+	for name, options := range tests {
+		if err := Daemon(context.Background(), &filters.Options{}, nil, nil, nil, "json", common.Opts{}, nil, options, nil); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestDaemonScansNewNamespaces(t *testing.T) {
+	defer resetReportedResources()
+	clientset := createClusterListsClientset(t)
+	filterOpts := &filters.Options{}
+	var published []string
+	publish := func(report string) error {
+		published = append(published, report)
+		return nil
+	}
+	// The scan on start runs before the daemon stops with the context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	daemonOptions := DaemonOptions{Schedule: "@daily", RunOnStart: true, ResultsDir: t.TempDir()}
+
+	if err := Daemon(ctx, filterOpts, clientset, nil, nil, "json", common.Opts{GroupBy: "namespace"}, []string{"cm"}, daemonOptions, publish); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := clientset.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-new"}}, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := clientset.CoreV1().ConfigMaps("ns-new").Create(context.TODO(), CreateTestConfigmap("ns-new", "configmap-ns-new", AppLabels), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Daemon(ctx, filterOpts, clientset, nil, nil, "json", common.Opts{GroupBy: "namespace"}, []string{"cm"}, daemonOptions, publish); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(published) != 2 || strings.Contains(published[0], "configmap-ns-new") || !strings.Contains(published[1], "configmap-ns-new") {
+		t.Errorf("Expected the namespaces to be looked up at every scan, got %v", published)
+	}
+}
@@ -6,20 +6,20 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
-	"os"
+	"log/slog"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/yonahd/kor/pkg/common"
 	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
 )
 
 //go:embed exceptions/daemonsets/daemonsets.json
 var daemonsetsConfig []byte
 
-func processNamespaceDaemonSets(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
-	daemonSetsList, err := clientset.AppsV1().DaemonSets(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func processNamespaceDaemonSets(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	daemonSetsList, err := utils.ListAll(ctx, filterOpts.ListOptions(), clientset.AppsV1().DaemonSets(namespace).List)
 	if err != nil {
 		return nil, err
 	}
@@ -60,17 +60,21 @@ func processNamespaceDaemonSets(clientset kubernetes.Interface, namespace string
 	return daemonSetsWithoutReplicas, nil
 }
 
-func GetUnusedDaemonSets(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+func GetUnusedDaemonSets(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	clientset = scanClientset(clientset)
 	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceDaemonSets(clientset, namespace, filterOpts)
+	for _, scan := range scanNamespaces(filterOpts.Namespaces(ctx, clientset), func(namespace string) ([]ResourceInfo, error) {
+		return processNamespaceDaemonSets(ctx, clientset, namespace, filterOpts)
+	}) {
+		namespace, diff, err := scan.namespace, scan.result, scan.err
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
+			recordScanError("DaemonSet", namespace, err)
 			continue
 		}
-		if opts.DeleteFlag {
-			if diff, err = DeleteResource(diff, clientset, namespace, "DaemonSet", opts.NoInteractive); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to delete DaemonSet %s in namespace %s: %v\n", diff, namespace, err)
+		exportManifests(ctx, clientset, namespace, "DaemonSet", diff, opts)
+		if deleteEnabled(opts, "DaemonSet") {
+			if diff, err = DeleteResource(ctx, diff, clientset, namespace, "DaemonSet", opts.NoInteractive); err != nil {
+				slog.Error("Failed to delete DaemonSet", "name", diff, "namespace", namespace, "error", err)
 			}
 		}
 		switch opts.GroupBy {
@@ -82,12 +86,15 @@ func GetUnusedDaemonSets(filterOpts *filters.Options, clientset kubernetes.Inter
 		}
 	}
 
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
 	var outputBuffer bytes.Buffer
 	var jsonResponse []byte
 	switch outputFormat {
 	case "table":
 		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
+	default:
 		var err error
 		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
 			return "", err
@@ -66,7 +66,7 @@ func createTestDaemonSets(t *testing.T) *fake.Clientset {
 func TestProcessNamespaceDaemonSets(t *testing.T) {
 	clientset := createTestDaemonSets(t)
 
-	daemonSetsWithoutReplicas, err := processNamespaceDaemonSets(clientset, testNamespace, &filters.Options{})
+	daemonSetsWithoutReplicas, err := processNamespaceDaemonSets(context.Background(), clientset, testNamespace, &filters.Options{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -92,7 +92,7 @@ func TestGetUnusedDaemonSetsStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedDaemonSets(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedDaemonSets(context.Background(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedDaemonSetsStructured: %v", err)
 	}
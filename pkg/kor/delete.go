@@ -3,97 +3,125 @@ package kor
 import (
 	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"reflect"
 	"strings"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+
+	"github.com/yonahd/kor/pkg/common"
 )
 
-func DeleteResourceCmd() map[string]func(clientset kubernetes.Interface, namespace, name string) error {
-	var deleteResourceApiMap = map[string]func(clientset kubernetes.Interface, namespace, name string) error{
-		"ConfigMap": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.CoreV1().ConfigMaps(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+func DeleteResourceCmd() map[string]func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+	var deleteResourceApiMap = map[string]func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error{
+		"ConfigMap": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.CoreV1().ConfigMaps(namespace).Delete(ctx, name, deleteOptions())
+		},
+		"Secret": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.CoreV1().Secrets(namespace).Delete(ctx, name, deleteOptions())
+		},
+		"ServiceAccountToken": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.CoreV1().Secrets(namespace).Delete(ctx, name, deleteOptions())
+		},
+		"GeneratedSecret": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.CoreV1().Secrets(namespace).Delete(ctx, name, deleteOptions())
+		},
+		"HelmReleaseSecret": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.CoreV1().Secrets(namespace).Delete(ctx, name, deleteOptions())
+		},
+		"Service": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.CoreV1().Services(namespace).Delete(ctx, name, deleteOptions())
+		},
+		"Deployment": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.AppsV1().Deployments(namespace).Delete(ctx, name, deleteOptions())
 		},
-		"Secret": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.CoreV1().Secrets(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		"HPA": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Delete(ctx, name, deleteOptions())
 		},
-		"Service": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.CoreV1().Services(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		"Ingress": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.NetworkingV1().Ingresses(namespace).Delete(ctx, name, deleteOptions())
 		},
-		"Deployment": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.AppsV1().Deployments(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		"PDB": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.PolicyV1beta1().PodDisruptionBudgets(namespace).Delete(ctx, name, deleteOptions())
 		},
-		"HPA": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		"Role": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.RbacV1().Roles(namespace).Delete(ctx, name, deleteOptions())
 		},
-		"Ingress": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.NetworkingV1().Ingresses(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		"ClusterRole": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.RbacV1().ClusterRoles().Delete(ctx, name, deleteOptions())
 		},
-		"PDB": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.PolicyV1beta1().PodDisruptionBudgets(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		"PVC": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.CoreV1().PersistentVolumeClaims(namespace).Delete(ctx, name, deleteOptions())
 		},
-		"Role": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.RbacV1().Roles(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		"StatefulSet": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.AppsV1().StatefulSets(namespace).Delete(ctx, name, deleteOptions())
 		},
-		"ClusterRole": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.RbacV1().ClusterRoles().Delete(context.TODO(), name, metav1.DeleteOptions{})
+		"ServiceAccount": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.CoreV1().ServiceAccounts(namespace).Delete(ctx, name, deleteOptions())
 		},
-		"PVC": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.CoreV1().PersistentVolumeClaims(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		"PV": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.CoreV1().PersistentVolumes().Delete(ctx, name, deleteOptions())
 		},
-		"StatefulSet": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.AppsV1().StatefulSets(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		"Pod": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.CoreV1().Pods(namespace).Delete(ctx, name, deleteOptions())
 		},
-		"ServiceAccount": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.CoreV1().ServiceAccounts(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		"Job": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.BatchV1().Jobs(namespace).Delete(ctx, name, deleteOptions())
 		},
-		"PV": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.CoreV1().PersistentVolumes().Delete(context.TODO(), name, metav1.DeleteOptions{})
+		"ReplicaSet": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.AppsV1().ReplicaSets(namespace).Delete(ctx, name, deleteOptions())
 		},
-		"Pod": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.CoreV1().Pods(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		"DaemonSet": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.AppsV1().DaemonSets(namespace).Delete(ctx, name, deleteOptions())
 		},
-		"Job": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.BatchV1().Jobs(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		"StorageClass": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.StorageV1().StorageClasses().Delete(ctx, name, deleteOptions())
 		},
-		"ReplicaSet": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.AppsV1().ReplicaSets(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		"CSIDriver": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.StorageV1().CSIDrivers().Delete(ctx, name, deleteOptions())
 		},
-		"DaemonSet": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.AppsV1().DaemonSets(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		"VolumeAttachment": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.StorageV1().VolumeAttachments().Delete(ctx, name, deleteOptions())
 		},
-		"StorageClass": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.StorageV1().StorageClasses().Delete(context.TODO(), name, metav1.DeleteOptions{})
+		"NetworkPolicy": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.NetworkingV1().NetworkPolicies(namespace).Delete(ctx, name, deleteOptions())
 		},
-		"NetworkPolicy": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.NetworkingV1().NetworkPolicies(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		"RoleBinding": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.RbacV1().RoleBindings(namespace).Delete(ctx, name, deleteOptions())
 		},
-		"RoleBinding": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.RbacV1().RoleBindings(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		"Endpoints": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.CoreV1().Endpoints(namespace).Delete(ctx, name, deleteOptions())
+		},
+		"EndpointSlice": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+			return clientset.DiscoveryV1().EndpointSlices(namespace).Delete(ctx, name, deleteOptions())
 		},
 	}
 
 	return deleteResourceApiMap
 }
 
-func FlagDynamicResource(dynamicClient dynamic.Interface, namespace string, gvr schema.GroupVersionResource, resourceName string) error {
+func FlagDynamicResource(ctx context.Context, dynamicClient dynamic.Interface, namespace string, gvr schema.GroupVersionResource, resourceName string) error {
 	resource, err := dynamicClient.
 		Resource(gvr).
 		Namespace(namespace).
-		Get(context.TODO(), resourceName, metav1.GetOptions{})
+		Get(ctx, resourceName, metav1.GetOptions{})
 	if err != nil {
 		return err
 	}
@@ -107,12 +135,12 @@ func FlagDynamicResource(dynamicClient dynamic.Interface, namespace string, gvr
 	_, err = dynamicClient.
 		Resource(gvr).
 		Namespace(namespace).
-		Update(context.TODO(), resource, metav1.UpdateOptions{})
+		Update(ctx, resource, metav1.UpdateOptions{})
 	return err
 }
 
-func FlagResource(clientset kubernetes.Interface, namespace, resourceType, resourceName string) error {
-	resource, err := getResource(clientset, namespace, resourceType, resourceName)
+func FlagResource(ctx context.Context, clientset kubernetes.Interface, namespace, resourceType, resourceName string) error {
+	resource, err := getResource(ctx, clientset, namespace, resourceType, resourceName)
 	if err != nil {
 		return err
 	}
@@ -129,111 +157,157 @@ func FlagResource(clientset kubernetes.Interface, namespace, resourceType, resou
 		return fmt.Errorf("unable to set labels for resource type: %s", resourceType)
 	}
 
-	_, err = updateResource(clientset, namespace, resourceType, resource)
+	_, err = updateResource(ctx, clientset, namespace, resourceType, resource)
 	return err
 }
 
-func updateResource(clientset kubernetes.Interface, namespace, resourceType string, resource interface{}) (interface{}, error) {
+func updateResource(ctx context.Context, clientset kubernetes.Interface, namespace, resourceType string, resource interface{}) (interface{}, error) {
 	switch resourceType {
 	case "ConfigMap":
-		return clientset.CoreV1().ConfigMaps(namespace).Update(context.TODO(), resource.(*corev1.ConfigMap), metav1.UpdateOptions{})
-	case "Secret":
-		return clientset.CoreV1().Secrets(namespace).Update(context.TODO(), resource.(*corev1.Secret), metav1.UpdateOptions{})
+		return clientset.CoreV1().ConfigMaps(namespace).Update(ctx, resource.(*corev1.ConfigMap), metav1.UpdateOptions{})
+	case "Secret", "ServiceAccountToken", "GeneratedSecret", "HelmReleaseSecret":
+		return clientset.CoreV1().Secrets(namespace).Update(ctx, resource.(*corev1.Secret), metav1.UpdateOptions{})
 	case "Service":
-		return clientset.CoreV1().Services(namespace).Update(context.TODO(), resource.(*corev1.Service), metav1.UpdateOptions{})
+		return clientset.CoreV1().Services(namespace).Update(ctx, resource.(*corev1.Service), metav1.UpdateOptions{})
 	case "Deployment":
-		return clientset.AppsV1().Deployments(namespace).Update(context.TODO(), resource.(*appsv1.Deployment), metav1.UpdateOptions{})
+		return clientset.AppsV1().Deployments(namespace).Update(ctx, resource.(*appsv1.Deployment), metav1.UpdateOptions{})
 	case "HPA":
-		return clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Update(context.TODO(), resource.(*autoscalingv1.HorizontalPodAutoscaler), metav1.UpdateOptions{})
+		return clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Update(ctx, resource.(*autoscalingv1.HorizontalPodAutoscaler), metav1.UpdateOptions{})
 	case "Ingress":
-		return clientset.NetworkingV1().Ingresses(namespace).Update(context.TODO(), resource.(*networkingv1.Ingress), metav1.UpdateOptions{})
+		return clientset.NetworkingV1().Ingresses(namespace).Update(ctx, resource.(*networkingv1.Ingress), metav1.UpdateOptions{})
 	case "PDB":
-		return clientset.PolicyV1beta1().PodDisruptionBudgets(namespace).Update(context.TODO(), resource.(*policyv1beta1.PodDisruptionBudget), metav1.UpdateOptions{})
+		return clientset.PolicyV1beta1().PodDisruptionBudgets(namespace).Update(ctx, resource.(*policyv1beta1.PodDisruptionBudget), metav1.UpdateOptions{})
 	case "Role":
-		return clientset.RbacV1().Roles(namespace).Update(context.TODO(), resource.(*rbacv1.Role), metav1.UpdateOptions{})
+		return clientset.RbacV1().Roles(namespace).Update(ctx, resource.(*rbacv1.Role), metav1.UpdateOptions{})
 	case "ClusterRole":
-		return clientset.RbacV1().ClusterRoles().Update(context.TODO(), resource.(*rbacv1.ClusterRole), metav1.UpdateOptions{})
+		return clientset.RbacV1().ClusterRoles().Update(ctx, resource.(*rbacv1.ClusterRole), metav1.UpdateOptions{})
 	case "PVC":
-		return clientset.CoreV1().PersistentVolumeClaims(namespace).Update(context.TODO(), resource.(*corev1.PersistentVolumeClaim), metav1.UpdateOptions{})
+		return clientset.CoreV1().PersistentVolumeClaims(namespace).Update(ctx, resource.(*corev1.PersistentVolumeClaim), metav1.UpdateOptions{})
 	case "StatefulSet":
-		return clientset.AppsV1().StatefulSets(namespace).Update(context.TODO(), resource.(*appsv1.StatefulSet), metav1.UpdateOptions{})
+		return clientset.AppsV1().StatefulSets(namespace).Update(ctx, resource.(*appsv1.StatefulSet), metav1.UpdateOptions{})
 	case "ServiceAccount":
-		return clientset.CoreV1().ServiceAccounts(namespace).Update(context.TODO(), resource.(*corev1.ServiceAccount), metav1.UpdateOptions{})
+		return clientset.CoreV1().ServiceAccounts(namespace).Update(ctx, resource.(*corev1.ServiceAccount), metav1.UpdateOptions{})
 	case "PV":
-		return clientset.CoreV1().PersistentVolumes().Update(context.TODO(), resource.(*corev1.PersistentVolume), metav1.UpdateOptions{})
+		return clientset.CoreV1().PersistentVolumes().Update(ctx, resource.(*corev1.PersistentVolume), metav1.UpdateOptions{})
 	case "Pod":
-		return clientset.CoreV1().Pods(namespace).Update(context.TODO(), resource.(*corev1.Pod), metav1.UpdateOptions{})
+		return clientset.CoreV1().Pods(namespace).Update(ctx, resource.(*corev1.Pod), metav1.UpdateOptions{})
 	case "Job":
-		return clientset.BatchV1().Jobs(namespace).Update(context.TODO(), resource.(*batchv1.Job), metav1.UpdateOptions{})
+		return clientset.BatchV1().Jobs(namespace).Update(ctx, resource.(*batchv1.Job), metav1.UpdateOptions{})
 	case "ReplicaSet":
-		return clientset.AppsV1().ReplicaSets(namespace).Update(context.TODO(), resource.(*appsv1.ReplicaSet), metav1.UpdateOptions{})
+		return clientset.AppsV1().ReplicaSets(namespace).Update(ctx, resource.(*appsv1.ReplicaSet), metav1.UpdateOptions{})
 	case "DaemonSet":
-		return clientset.AppsV1().DaemonSets(namespace).Update(context.TODO(), resource.(*appsv1.DaemonSet), metav1.UpdateOptions{})
+		return clientset.AppsV1().DaemonSets(namespace).Update(ctx, resource.(*appsv1.DaemonSet), metav1.UpdateOptions{})
 	case "StorageClass":
-		return clientset.StorageV1().StorageClasses().Update(context.TODO(), resource.(*storagev1.StorageClass), metav1.UpdateOptions{})
+		return clientset.StorageV1().StorageClasses().Update(ctx, resource.(*storagev1.StorageClass), metav1.UpdateOptions{})
+	case "CSIDriver":
+		return clientset.StorageV1().CSIDrivers().Update(ctx, resource.(*storagev1.CSIDriver), metav1.UpdateOptions{})
+	case "VolumeAttachment":
+		return clientset.StorageV1().VolumeAttachments().Update(ctx, resource.(*storagev1.VolumeAttachment), metav1.UpdateOptions{})
 	case "NetworkPolicy":
-		return clientset.NetworkingV1().NetworkPolicies(namespace).Update(context.TODO(), resource.(*networkingv1.NetworkPolicy), metav1.UpdateOptions{})
+		return clientset.NetworkingV1().NetworkPolicies(namespace).Update(ctx, resource.(*networkingv1.NetworkPolicy), metav1.UpdateOptions{})
 	case "RoleBinding":
-		return clientset.RbacV1().RoleBindings(namespace).Update(context.TODO(), resource.(*rbacv1.RoleBinding), metav1.UpdateOptions{})
+		return clientset.RbacV1().RoleBindings(namespace).Update(ctx, resource.(*rbacv1.RoleBinding), metav1.UpdateOptions{})
+	case "Endpoints":
+		return clientset.CoreV1().Endpoints(namespace).Update(ctx, resource.(*corev1.Endpoints), metav1.UpdateOptions{})
+	case "EndpointSlice":
+		return clientset.DiscoveryV1().EndpointSlices(namespace).Update(ctx, resource.(*discoveryv1.EndpointSlice), metav1.UpdateOptions{})
 	}
 	return nil, fmt.Errorf("resource type '%s' is not supported", resourceType)
 }
 
-func getResource(clientset kubernetes.Interface, namespace, resourceType, resourceName string) (interface{}, error) {
+// resourceOwners returns the owners of the resource which are to be deleted
+// instead of it. The Pods reported are replaced by their owners already.
+func resourceOwners(ctx context.Context, clientset kubernetes.Interface, namespace, resourceType, resourceName string) string {
+	if forceDelete || resourceType == "Pod" {
+		return ""
+	}
+	obj, err := getResource(ctx, clientset, namespace, resourceType, resourceName)
+	if err != nil {
+		return ""
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return ""
+	}
+	return ownerNames(accessor)
+}
+
+func getResource(ctx context.Context, clientset kubernetes.Interface, namespace, resourceType, resourceName string) (interface{}, error) {
 	switch resourceType {
 	case "ConfigMap":
-		return clientset.CoreV1().ConfigMaps(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
-	case "Secret":
-		return clientset.CoreV1().Secrets(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
+		return clientset.CoreV1().ConfigMaps(namespace).Get(ctx, resourceName, metav1.GetOptions{})
+	case "Secret", "ServiceAccountToken", "GeneratedSecret", "HelmReleaseSecret":
+		return clientset.CoreV1().Secrets(namespace).Get(ctx, resourceName, metav1.GetOptions{})
 	case "Service":
-		return clientset.CoreV1().Services(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
+		return clientset.CoreV1().Services(namespace).Get(ctx, resourceName, metav1.GetOptions{})
 	case "Deployment":
-		return clientset.AppsV1().Deployments(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
+		return clientset.AppsV1().Deployments(namespace).Get(ctx, resourceName, metav1.GetOptions{})
 	case "HPA":
-		return clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
+		return clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Get(ctx, resourceName, metav1.GetOptions{})
 	case "Ingress":
-		return clientset.NetworkingV1().Ingresses(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
+		return clientset.NetworkingV1().Ingresses(namespace).Get(ctx, resourceName, metav1.GetOptions{})
 	case "PDB":
-		return clientset.PolicyV1beta1().PodDisruptionBudgets(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
+		return clientset.PolicyV1beta1().PodDisruptionBudgets(namespace).Get(ctx, resourceName, metav1.GetOptions{})
 	case "Role":
-		return clientset.RbacV1().Roles(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
+		return clientset.RbacV1().Roles(namespace).Get(ctx, resourceName, metav1.GetOptions{})
 	case "ClusterRole":
-		return clientset.RbacV1().ClusterRoles().Get(context.TODO(), resourceName, metav1.GetOptions{})
+		return clientset.RbacV1().ClusterRoles().Get(ctx, resourceName, metav1.GetOptions{})
 	case "PVC":
-		return clientset.CoreV1().PersistentVolumeClaims(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
+		return clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, resourceName, metav1.GetOptions{})
 	case "StatefulSet":
-		return clientset.AppsV1().StatefulSets(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
+		return clientset.AppsV1().StatefulSets(namespace).Get(ctx, resourceName, metav1.GetOptions{})
 	case "ServiceAccount":
-		return clientset.CoreV1().ServiceAccounts(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
+		return clientset.CoreV1().ServiceAccounts(namespace).Get(ctx, resourceName, metav1.GetOptions{})
 	case "PV":
-		return clientset.CoreV1().PersistentVolumes().Get(context.TODO(), resourceName, metav1.GetOptions{})
+		return clientset.CoreV1().PersistentVolumes().Get(ctx, resourceName, metav1.GetOptions{})
 	case "Pod":
-		return clientset.CoreV1().Pods(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
+		return clientset.CoreV1().Pods(namespace).Get(ctx, resourceName, metav1.GetOptions{})
 	case "Job":
-		return clientset.BatchV1().Jobs(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
+		return clientset.BatchV1().Jobs(namespace).Get(ctx, resourceName, metav1.GetOptions{})
 	case "ReplicaSet":
-		return clientset.AppsV1().ReplicaSets(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
+		return clientset.AppsV1().ReplicaSets(namespace).Get(ctx, resourceName, metav1.GetOptions{})
 	case "DaemonSet":
-		return clientset.AppsV1().DaemonSets(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
+		return clientset.AppsV1().DaemonSets(namespace).Get(ctx, resourceName, metav1.GetOptions{})
 	case "StorageClass":
-		return clientset.StorageV1().StorageClasses().Get(context.TODO(), resourceName, metav1.GetOptions{})
+		return clientset.StorageV1().StorageClasses().Get(ctx, resourceName, metav1.GetOptions{})
+	case "CSIDriver":
+		return clientset.StorageV1().CSIDrivers().Get(ctx, resourceName, metav1.GetOptions{})
+	case "VolumeAttachment":
+		return clientset.StorageV1().VolumeAttachments().Get(ctx, resourceName, metav1.GetOptions{})
 	case "NetworkPolicy":
-		return clientset.NetworkingV1().NetworkPolicies(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
+		return clientset.NetworkingV1().NetworkPolicies(namespace).Get(ctx, resourceName, metav1.GetOptions{})
 	case "RoleBinding":
-		return clientset.RbacV1().RoleBindings(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
+		return clientset.RbacV1().RoleBindings(namespace).Get(ctx, resourceName, metav1.GetOptions{})
+	case "Endpoints":
+		return clientset.CoreV1().Endpoints(namespace).Get(ctx, resourceName, metav1.GetOptions{})
+	case "EndpointSlice":
+		return clientset.DiscoveryV1().EndpointSlices(namespace).Get(ctx, resourceName, metav1.GetOptions{})
+	case "Node":
+		return clientset.CoreV1().Nodes().Get(ctx, resourceName, metav1.GetOptions{})
 	}
 	return nil, fmt.Errorf("resource type '%s' is not supported", resourceType)
 }
 
-func DeleteResourceWithFinalizer(resources []ResourceInfo, dynamicClient dynamic.Interface, namespace string, gvr schema.GroupVersionResource, noInteractive bool) ([]ResourceInfo, error) {
+func DeleteResourceWithFinalizer(ctx context.Context, resources []ResourceInfo, dynamicClient dynamic.Interface, namespace string, gvr schema.GroupVersionResource, noInteractive bool) ([]ResourceInfo, error) {
 	var remainingResources []ResourceInfo
 	for _, resource := range resources {
-		if !noInteractive {
+		if reason, protected := protectedReason(gvr.Resource, namespace, resource.Name); protected {
+			fmt.Printf("Skipping %s %s in namespace %s, it is protected: %s. Use --force to delete it\n", gvr.Resource, resource.Name, namespace, reason)
+			resource.Reason = "not deleted - protected"
+			remainingResources = append(remainingResources, resource)
+			continue
+		}
+		if !unusedLongEnough("", namespace, gvr.Resource, resource.Name, time.Now()) {
+			fmt.Printf("Skipping %s %s in namespace %s, it has not been unused for --unused-for yet\n", gvr.Resource, resource.Name, namespace)
+			remainingResources = append(remainingResources, resource)
+			continue
+		}
+		if !noInteractive && deleteDryRun == "" {
 			fmt.Printf("Do you want to delete %s %s in namespace %s? (Y/N): ", gvr.Resource, resource.Name, namespace)
 			var confirmation string
 			_, err := fmt.Scanf("%s", &confirmation)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to read input: %v\n", err)
+				slog.Error("Failed to read input", "error", err)
 				continue
 			}
 
@@ -245,13 +319,13 @@ func DeleteResourceWithFinalizer(resources []ResourceInfo, dynamicClient dynamic
 				var inUse string
 				_, err = fmt.Scanf("%s", &inUse)
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Failed to read input: %v\n", err)
+					slog.Error("Failed to read input", "error", err)
 					continue
 				}
 
 				if strings.ToLower(inUse) == "y" || strings.ToLower(inUse) == "yes" {
-					if err := FlagDynamicResource(dynamicClient, namespace, gvr, resource.Name); err != nil {
-						fmt.Fprintf(os.Stderr, "Failed to flag resource %s %s in namespace %s as In Use: %v\n", gvr.Resource, resource.Name, namespace, err)
+					if err := FlagDynamicResource(ctx, dynamicClient, namespace, gvr, resource.Name); err != nil {
+						slog.Error("Failed to flag resource as In Use", "resource", gvr.Resource, "name", resource.Name, "namespace", namespace, "error", err)
 					} else {
 						resource.Reason = "flagged as in use"
 					}
@@ -260,72 +334,248 @@ func DeleteResourceWithFinalizer(resources []ResourceInfo, dynamicClient dynamic
 			}
 		}
 
-		fmt.Printf("Deleting %s %s in namespace %s\n", gvr.Resource, resource.Name, namespace)
+		fmt.Printf("Deleting %s %s in namespace %s%s\n", gvr.Resource, resource.Name, namespace, dryRunNote())
+		if deleteDryRun == DryRunClient {
+			resource.Name += dryRunSuffix
+			remainingResources = append(remainingResources, resource)
+			continue
+		}
+		if err := waitForDelete(ctx); err != nil {
+			return remainingResources, err
+		}
 		if _, err := dynamicClient.
 			Resource(gvr).
 			Namespace(namespace).
-			Patch(context.TODO(), resource.Name, types.MergePatchType,
+			Patch(ctx, resource.Name, types.MergePatchType,
 				[]byte(`{"metadata":{"finalizers":null}}`),
-				metav1.PatchOptions{}); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete %s %s in namespace %s: %v\n", gvr.Resource, resource.Name, namespace, err)
+				metav1.PatchOptions{DryRun: serverDryRun()}); err != nil {
+			slog.Error("Failed to delete resource", "resource", gvr.Resource, "name", resource.Name, "namespace", namespace, "error", err)
 			continue
 		}
-		resource.Name = resource.Name + "-DELETED"
+		resource.Name = resource.Name + deletedSuffix()
 		remainingResources = append(remainingResources, resource)
 	}
 
 	return remainingResources, nil
 }
 
-func DeleteResource(diff []ResourceInfo, clientset kubernetes.Interface, namespace, resourceType string, noInteractive bool) ([]ResourceInfo, error) {
-	deletedDiff := []ResourceInfo{}
+const (
+	// DryRunServer sends the deletions to the API server as dry runs, so admission webhooks still review them
+	DryRunServer = "server"
+	// DryRunClient only prints the deletions
+	DryRunClient = "client"
 
-	for _, resource := range diff {
-		deleteFunc, exists := DeleteResourceCmd()[resourceType]
-		if !exists {
-			fmt.Printf("Resource type '%s' is not supported\n", resource.Name)
-			continue
+	dryRunSuffix      = "-DRY-RUN"
+	deletedNameSuffix = "-DELETED"
+)
+
+// exportFailureReported is set once the deletions skipped after a failed backup were reported.
+var exportFailureReported bool
+
+// deleteDryRun is the --dry-run mode of --delete, empty deletes for real.
+var deleteDryRun string
+
+// SetDeleteDryRun sets the --dry-run mode of the deletions, "server", "client" or "none".
+func SetDeleteDryRun(mode string) error {
+	switch mode {
+	case "", "none":
+		deleteDryRun = ""
+	case DryRunServer, DryRunClient:
+		deleteDryRun = mode
+	default:
+		return fmt.Errorf("invalid dry-run mode %q, must be one of server, client or none", mode)
+	}
+	return nil
+}
+
+func serverDryRun() []string {
+	if deleteDryRun == DryRunServer {
+		return []string{metav1.DryRunAll}
+	}
+	return nil
+}
+
+// CascadeModes lists the values accepted by --cascade.
+var CascadeModes = []string{
+	string(metav1.DeletePropagationBackground),
+	string(metav1.DeletePropagationForeground),
+	string(metav1.DeletePropagationOrphan),
+}
+
+// deletePropagation is the --cascade propagation policy of the deletions,
+// nil leaves it to the default of each resource.
+var deletePropagation *metav1.DeletionPropagation
+
+// SetDeleteCascade sets how the dependents of deleted resources are handled,
+// "background", "foreground" or "orphan" (case-insensitive). Empty keeps the
+// default of each resource.
+func SetDeleteCascade(mode string) error {
+	if mode == "" {
+		deletePropagation = nil
+		return nil
+	}
+	for _, cascade := range CascadeModes {
+		if strings.EqualFold(mode, cascade) {
+			policy := metav1.DeletionPropagation(cascade)
+			deletePropagation = &policy
+			return nil
 		}
+	}
+	return fmt.Errorf("invalid cascade mode %q, must be one of background, foreground or orphan", mode)
+}
 
-		if !noInteractive {
-			fmt.Printf("Do you want to delete %s %s in namespace %s? (Y/N): ", resourceType, resource.Name, namespace)
-			var confirmation string
-			_, err := fmt.Scanf("%s\n", &confirmation)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to read input: %v\n", err)
-				continue
-			}
+func deleteOptions() metav1.DeleteOptions {
+	return metav1.DeleteOptions{DryRun: serverDryRun(), PropagationPolicy: deletePropagation}
+}
 
-			if strings.ToLower(confirmation) != "y" && strings.ToLower(confirmation) != "yes" {
-				deletedDiff = append(deletedDiff, resource)
+func dryRunNote() string {
+	if deleteDryRun == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (%s dry run)", deleteDryRun)
+}
 
-				fmt.Printf("Do you want flag the resource %s %s in namespace %s as In Use? (Y/N): ", resourceType, resource.Name, namespace)
-				var inUse string
-				_, err := fmt.Scanf("%s\n", &inUse)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Failed to read input: %v\n", err)
-					continue
-				}
+func deletedSuffix() string {
+	if deleteDryRun != "" {
+		return dryRunSuffix
+	}
+	return deletedNameSuffix
+}
 
-				if strings.ToLower(inUse) == "y" || strings.ToLower(inUse) == "yes" {
-					if err := FlagResource(clientset, namespace, resourceType, resource.Name); err != nil {
-						fmt.Fprintf(os.Stderr, "Failed to flag resource %s %s in namespace %s as In Use: %v\n", resourceType, resource.Name, namespace, err)
-					}
-					continue
-				}
-				continue
+// deleteEnabled reports whether --delete applies to the resource type. Kinds
+// given to --delete match the report kind, the kind kubectl acts on for it
+// (e.g. secret for a HelmReleaseSecret) or their plural, case-insensitively.
+// Nothing is deleted anymore once a backup with --export-manifests failed.
+func deleteEnabled(opts common.Opts, resourceType string) bool {
+	if !opts.DeleteFlag {
+		return false
+	}
+	if exportFailed && deleteDryRun == "" {
+		if !exportFailureReported {
+			slog.Error("Skipping deletions, the manifests could not be backed up", "path", opts.ExportManifests)
+			exportFailureReported = true
+		}
+		return false
+	}
+	if len(opts.DeleteKinds) == 0 {
+		return true
+	}
+
+	candidates := []string{strings.ToLower(resourceType)}
+	if kind, ok := quietKinds[resourceType]; ok {
+		candidates = append(candidates, kind)
+	}
+	for _, kind := range opts.DeleteKinds {
+		kind = strings.ToLower(kind)
+		for _, candidate := range candidates {
+			if kind == candidate || kind == candidate+"s" || kind+"s" == candidate {
+				return true
 			}
 		}
+	}
+	return false
+}
 
-		fmt.Printf("Deleting %s %s in namespace %s\n", resourceType, resource.Name, namespace)
-		if err := deleteFunc(clientset, namespace, resource.Name); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete %s %s in namespace %s: %v\n", resourceType, resource.Name, namespace, err)
-			continue
+func DeleteResource(ctx context.Context, diff []ResourceInfo, clientset kubernetes.Interface, namespace, resourceType string, noInteractive bool) ([]ResourceInfo, error) {
+	deletedDiff := []ResourceInfo{}
+
+	for _, resource := range diff {
+		var confirm func() bool
+		if !noInteractive && deleteDryRun == "" {
+			confirm = func() bool {
+				return confirmDelete(ctx, clientset, namespace, resourceType, resource.Name)
+			}
+		}
+		result, outcome, err := deleteGuarded(ctx, os.Stdout, clientset, namespace, resourceType, resource, confirm)
+		if err != nil {
+			return deletedDiff, err
+		}
+		if outcome != deleteFailed {
+			deletedDiff = append(deletedDiff, result)
 		}
-		deletedResource := resource
-		deletedResource.Name += "-DELETED"
-		deletedDiff = append(deletedDiff, deletedResource)
 	}
 
 	return deletedDiff, nil
 }
+
+// deleteOutcome tells what deleteGuarded did with a resource.
+type deleteOutcome int
+
+const (
+	// deleteFailed means the resource type cannot be deleted or the deletion failed
+	deleteFailed deleteOutcome = iota
+	// deleteSkipped means a guard or the user kept the resource
+	deleteSkipped
+	// deleteDone means the resource was deleted, or previewed with --dry-run
+	deleteDone
+)
+
+// deleteGuarded deletes a single resource with the checks every delete mode
+// applies: protected resources, the ones --unused-for holds back and the
+// ones owned by another object are skipped. confirm, when set, is asked last
+// and keeps the resource when it returns false. The deletion honours
+// --dry-run and the --delete-qps and --delete-batch-size limits, and its
+// messages are written to w. The resource is returned as reported after it.
+func deleteGuarded(ctx context.Context, w io.Writer, clientset kubernetes.Interface, namespace, resourceType string, resource ResourceInfo, confirm func() bool) (ResourceInfo, deleteOutcome, error) {
+	deleteFunc, exists := DeleteResourceCmd()[resourceType]
+	if !exists {
+		fmt.Fprintf(w, "Resource type '%s' is not supported\n", resourceType)
+		return resource, deleteFailed, nil
+	}
+	if reason, protected := protectedReason(resourceType, namespace, resource.Name); protected {
+		fmt.Fprintf(w, "Skipping %s %s in namespace %s, it is protected: %s. Use --force to delete it\n", resourceType, resource.Name, namespace, reason)
+		return resource, deleteSkipped, nil
+	}
+	if !unusedLongEnough("", namespace, resourceType, resource.Name, time.Now()) {
+		fmt.Fprintf(w, "Skipping %s %s in namespace %s, it has not been unused for --unused-for yet\n", resourceType, resource.Name, namespace)
+		return resource, deleteSkipped, nil
+	}
+	if owners := resourceOwners(ctx, clientset, namespace, resourceType, resource.Name); owners != "" {
+		fmt.Fprintf(w, "Skipping %s %s in namespace %s, it is owned by %s which should be deleted instead. Use --force to delete it\n", resourceType, resource.Name, namespace, owners)
+		resource.Owners = owners
+		return resource, deleteSkipped, nil
+	}
+	if confirm != nil && !confirm() {
+		return resource, deleteSkipped, nil
+	}
+
+	fmt.Fprintf(w, "Deleting %s %s in namespace %s%s\n", resourceType, resource.Name, namespace, dryRunNote())
+	if deleteDryRun != DryRunClient {
+		if err := waitForDelete(ctx); err != nil {
+			return resource, deleteFailed, err
+		}
+		if err := deleteFunc(ctx, clientset, namespace, resource.Name); err != nil {
+			slog.Error("Failed to delete resource", "resource", resourceType, "name", resource.Name, "namespace", namespace, "error", err)
+			return resource, deleteFailed, nil
+		}
+	}
+	resource.Name += deletedSuffix()
+	return resource, deleteDone, nil
+}
+
+// confirmDelete asks whether to delete the resource, and when the user
+// declines, whether to flag it as in use.
+func confirmDelete(ctx context.Context, clientset kubernetes.Interface, namespace, resourceType, name string) bool {
+	fmt.Printf("Do you want to delete %s %s in namespace %s? (Y/N): ", resourceType, name, namespace)
+	var confirmation string
+	if _, err := fmt.Scanf("%s\n", &confirmation); err != nil {
+		slog.Error("Failed to read input", "error", err)
+		return false
+	}
+	if strings.ToLower(confirmation) == "y" || strings.ToLower(confirmation) == "yes" {
+		return true
+	}
+
+	fmt.Printf("Do you want flag the resource %s %s in namespace %s as In Use? (Y/N): ", resourceType, name, namespace)
+	var inUse string
+	if _, err := fmt.Scanf("%s\n", &inUse); err != nil {
+		slog.Error("Failed to read input", "error", err)
+		return false
+	}
+	if strings.ToLower(inUse) == "y" || strings.ToLower(inUse) == "yes" {
+		if err := FlagResource(ctx, clientset, namespace, resourceType, name); err != nil {
+			slog.Error("Failed to flag resource as In Use", "resource", resourceType, "name", name, "namespace", namespace, "error", err)
+		}
+	}
+	return false
+}
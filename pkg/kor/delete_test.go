@@ -10,6 +10,9 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	fakedynamic "k8s.io/client-go/dynamic/fake"
 	fake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/yonahd/kor/pkg/common"
 )
 
 func TestDeleteResource(t *testing.T) {
@@ -50,7 +53,7 @@ func TestDeleteResource(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			deletedDiff, _ := DeleteResource(test.diff, clientset, testNamespace, test.resourceType, true)
+			deletedDiff, _ := DeleteResource(context.Background(), test.diff, clientset, testNamespace, test.resourceType, true)
 			for i, deleted := range deletedDiff {
 				if deleted != test.expectedDiff[i] {
 					t.Errorf("Expected: %s, Got: %s", test.expectedDiff[i], deleted)
@@ -102,7 +105,7 @@ func TestDeleteDeleteResourceWithFinalizer(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			deletedDiff, _ := DeleteResourceWithFinalizer(test.diff, dynamicClient, testNamespace, gvr, true)
+			deletedDiff, _ := DeleteResourceWithFinalizer(context.Background(), test.diff, dynamicClient, testNamespace, gvr, true)
 
 			for i, deleted := range deletedDiff {
 				if deleted.Name != test.expectedDiff[i] {
@@ -169,7 +172,7 @@ func TestFlagDynamicResource(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			err := FlagDynamicResource(dynamicClient, testNamespace, gvr, test.resourceName)
+			err := FlagDynamicResource(context.Background(), dynamicClient, testNamespace, gvr, test.resourceName)
 
 			if (err != nil) != test.expectedError {
 				t.Errorf("Expected error: %v, Got: %v", test.expectedError, err)
@@ -189,3 +192,112 @@ func TestFlagDynamicResource(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteEnabled(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         common.Opts
+		resourceType string
+		want         bool
+	}{
+		{name: "delete disabled", opts: common.Opts{}, resourceType: "ConfigMap", want: false},
+		{name: "every kind", opts: common.Opts{DeleteFlag: true}, resourceType: "PVC", want: true},
+		{name: "listed kind", opts: common.Opts{DeleteFlag: true, DeleteKinds: []string{"configmap", "secret"}}, resourceType: "ConfigMap", want: true},
+		{name: "plural kind", opts: common.Opts{DeleteFlag: true, DeleteKinds: []string{"configmaps"}}, resourceType: "ConfigMap", want: true},
+		{name: "secret-backed kind", opts: common.Opts{DeleteFlag: true, DeleteKinds: []string{"secret"}}, resourceType: "HelmReleaseSecret", want: true},
+		{name: "finalizer resource", opts: common.Opts{DeleteFlag: true, DeleteKinds: []string{"configmap"}}, resourceType: "configmaps", want: true},
+		{name: "unlisted kind", opts: common.Opts{DeleteFlag: true, DeleteKinds: []string{"configmap"}}, resourceType: "Secret", want: false},
+	}
+	for _, tt := range tests {
+		if got := deleteEnabled(tt.opts, tt.resourceType); got != tt.want {
+			t.Errorf("%s: deleteEnabled() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDeleteResourceDryRun(t *testing.T) {
+	defer SetDeleteDryRun("")
+
+	for _, mode := range []string{DryRunServer, DryRunClient} {
+		t.Run(mode, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(CreateTestConfigmap(testNamespace, "configmap-1", AppLabels))
+			var deleteOptions []metav1.DeleteOptions
+			clientset.PrependReactor("delete", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				deleteOptions = append(deleteOptions, action.(k8stesting.DeleteAction).GetDeleteOptions())
+				return true, nil, nil
+			})
+
+			if err := SetDeleteDryRun(mode); err != nil {
+				t.Fatal(err)
+			}
+			diff := []ResourceInfo{{Name: "configmap-1", Reason: "ConfigMap is not used in any pod or container"}}
+			deletedDiff, err := DeleteResource(context.Background(), diff, clientset, testNamespace, "ConfigMap", true)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if deletedDiff[0].Name != "configmap-1-DRY-RUN" {
+				t.Errorf("Expected configmap-1-DRY-RUN, got %s", deletedDiff[0].Name)
+			}
+
+			switch mode {
+			case DryRunServer:
+				if len(deleteOptions) != 1 || len(deleteOptions[0].DryRun) != 1 || deleteOptions[0].DryRun[0] != metav1.DryRunAll {
+					t.Errorf("Expected one dry-run delete request, got %v", deleteOptions)
+				}
+			case DryRunClient:
+				if len(deleteOptions) != 0 {
+					t.Errorf("Expected no delete request, got %v", deleteOptions)
+				}
+			}
+		})
+	}
+
+	if err := SetDeleteDryRun("bogus"); err == nil {
+		t.Error("Expected an error for an unknown dry-run mode")
+	}
+}
+
+func TestDeleteResourceCascade(t *testing.T) {
+	defer SetDeleteCascade("")
+
+	clientset := fake.NewSimpleClientset()
+	var deleteOptions []metav1.DeleteOptions
+	clientset.PrependReactor("delete", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		deleteOptions = append(deleteOptions, action.(k8stesting.DeleteAction).GetDeleteOptions())
+		return true, nil, nil
+	})
+
+	if err := SetDeleteCascade("Orphan"); err != nil {
+		t.Fatal(err)
+	}
+	diff := []ResourceInfo{{Name: "deployment-1", Reason: "Deployment has no replicas"}}
+	if _, err := DeleteResource(context.Background(), diff, clientset, testNamespace, "Deployment", true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(deleteOptions) != 1 || deleteOptions[0].PropagationPolicy == nil || *deleteOptions[0].PropagationPolicy != metav1.DeletePropagationOrphan {
+		t.Errorf("Expected one delete request orphaning the dependents, got %v", deleteOptions)
+	}
+
+	if err := SetDeleteCascade("cascade"); err == nil {
+		t.Error("Expected an error for an unknown cascade mode")
+	}
+}
+
+func TestDeleteResourceOwned(t *testing.T) {
+	owned := CreateTestConfigmap(testNamespace, "configmap-owned", AppLabels)
+	owned.OwnerReferences = []metav1.OwnerReference{{APIVersion: "example.com/v1", Kind: "Database", Name: "db-1"}}
+	clientset := fake.NewSimpleClientset(owned, CreateTestConfigmap(testNamespace, "configmap-1", AppLabels))
+
+	diff := []ResourceInfo{{Name: "configmap-owned"}, {Name: "configmap-1"}}
+	deletedDiff, err := DeleteResource(context.Background(), diff, clientset, testNamespace, "ConfigMap", true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []ResourceInfo{{Name: "configmap-owned", Owners: "Database/db-1"}, {Name: "configmap-1-DELETED"}}
+	if len(deletedDiff) != len(expected) || deletedDiff[0] != expected[0] || deletedDiff[1] != expected[1] {
+		t.Errorf("Expected the owned configmap to be left to its owner, got %v", deletedDiff)
+	}
+	if _, err := clientset.CoreV1().ConfigMaps(testNamespace).Get(context.TODO(), "configmap-owned", metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected the owned configmap to be kept, got %v", err)
+	}
+}
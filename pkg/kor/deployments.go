@@ -5,17 +5,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
+	"log/slog"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/yonahd/kor/pkg/common"
 	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
 )
 
-func processNamespaceDeployments(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
-	deploymentsList, err := clientset.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func processNamespaceDeployments(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	deploymentsList, err := utils.ListAll(ctx, filterOpts.ListOptions(), clientset.AppsV1().Deployments(namespace).List)
 	if err != nil {
 		return nil, err
 	}
@@ -42,17 +42,21 @@ func processNamespaceDeployments(clientset kubernetes.Interface, namespace strin
 	return deploymentsWithoutReplicas, nil
 }
 
-func GetUnusedDeployments(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+func GetUnusedDeployments(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	clientset = scanClientset(clientset)
 	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceDeployments(clientset, namespace, filterOpts)
+	for _, scan := range scanNamespaces(filterOpts.Namespaces(ctx, clientset), func(namespace string) ([]ResourceInfo, error) {
+		return processNamespaceDeployments(ctx, clientset, namespace, filterOpts)
+	}) {
+		namespace, diff, err := scan.namespace, scan.result, scan.err
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
+			recordScanError("Deployment", namespace, err)
 			continue
 		}
-		if opts.DeleteFlag {
-			if diff, err = DeleteResource(diff, clientset, namespace, "Deployment", opts.NoInteractive); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to delete Deployment %s in namespace %s: %v\n", diff, namespace, err)
+		exportManifests(ctx, clientset, namespace, "Deployment", diff, opts)
+		if deleteEnabled(opts, "Deployment") {
+			if diff, err = DeleteResource(ctx, diff, clientset, namespace, "Deployment", opts.NoInteractive); err != nil {
+				slog.Error("Failed to delete Deployment", "name", diff, "namespace", namespace, "error", err)
 			}
 		}
 		switch opts.GroupBy {
@@ -64,12 +68,15 @@ func GetUnusedDeployments(filterOpts *filters.Options, clientset kubernetes.Inte
 		}
 	}
 
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
 	var outputBuffer bytes.Buffer
 	var jsonResponse []byte
 	switch outputFormat {
 	case "table":
 		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
+	default:
 		var err error
 		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
 			return "", err
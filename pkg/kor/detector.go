@@ -0,0 +1,237 @@
+package kor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// Clients are the clients of the cluster a Detector scans with.
+type Clients struct {
+	Kubernetes    kubernetes.Interface
+	APIExtensions apiextensionsclientset.Interface
+	Dynamic       dynamic.Interface
+}
+
+// Finding is an unused resource reported by a Detector.
+type Finding struct {
+	Kind string
+	ResourceInfo
+}
+
+// Detector finds the unused resources of one or more kinds. Registered
+// detectors are selected by name like the built-in ones, e.g. kor <name>,
+// --resources of the exporter and kor serve, and run by kor all.
+type Detector interface {
+	// Name selects the detector, case-insensitively
+	Name() string
+	// SupportedKinds are the kinds of the findings, in the order they are reported
+	SupportedKinds() []string
+	// Detect returns the unused resources of namespace, which is empty for
+	// cluster-scoped detectors. filterOpts are the filters of the scan.
+	Detect(ctx context.Context, clients Clients, namespace string, filterOpts *filters.Options) ([]Finding, error)
+}
+
+// ClusterScoped is implemented by the detectors of cluster-scoped resources,
+// which run once per scan instead of once per namespace.
+type ClusterScoped interface {
+	ClusterScoped() bool
+}
+
+var detectorRegistry = struct {
+	sync.RWMutex
+	names     map[string]Detector
+	detectors []Detector
+}{names: make(map[string]Detector)}
+
+// RegisterDetector adds detector to the detectors of kor, selected by its
+// name and aliases. It fails when one of them is taken.
+func RegisterDetector(detector Detector, aliases ...string) error {
+	names := append([]string{detector.Name()}, aliases...)
+	detectorRegistry.Lock()
+	defer detectorRegistry.Unlock()
+	for _, name := range names {
+		if name == "" || name == "all" {
+			return fmt.Errorf("invalid detector name %q", name)
+		}
+		if _, ok := detectorRegistry.names[strings.ToLower(name)]; ok {
+			return fmt.Errorf("detector %q is already registered", name)
+		}
+	}
+	for _, name := range names {
+		detectorRegistry.names[strings.ToLower(name)] = detector
+	}
+	detectorRegistry.detectors = append(detectorRegistry.detectors, detector)
+	return nil
+}
+
+// Detectors returns the registered detectors, the built-in ones first.
+func Detectors() []Detector {
+	detectorRegistry.RLock()
+	defer detectorRegistry.RUnlock()
+	return append([]Detector(nil), detectorRegistry.detectors...)
+}
+
+// IsDetector reports whether name selects a registered detector, by its name
+// or one of its aliases.
+func IsDetector(name string) bool {
+	return lookupDetector(name) != nil
+}
+
+func lookupDetector(name string) Detector {
+	detectorRegistry.RLock()
+	defer detectorRegistry.RUnlock()
+	return detectorRegistry.names[strings.ToLower(name)]
+}
+
+func isClusterScoped(detector Detector) bool {
+	scoped, ok := detector.(ClusterScoped)
+	return ok && scoped.ClusterScoped()
+}
+
+// detectorDiffs runs detector against namespace, recording its failure. The
+// findings returned along with an error are kept.
+func detectorDiffs(ctx context.Context, detector Detector, clients Clients, namespace string, filterOpts *filters.Options) []ResourceDiff {
+	diffs, err := runDetector(ctx, detector, clients, namespace, filterOpts)
+	if err != nil {
+		recordClusterScanError(scanCluster(ctx), detector.Name(), namespace, err)
+	}
+	return diffs
+}
+
+// runDetector runs detector against namespace, with a diff per supported kind.
+func runDetector(ctx context.Context, detector Detector, clients Clients, namespace string, filterOpts *filters.Options) ([]ResourceDiff, error) {
+	if builtin, ok := detector.(*builtinDetector); ok {
+		return builtin.detect(ctx, clients, namespace, filterOpts)
+	}
+	defer traceDetector(detector.Name(), namespace)()
+	findings, err := detector.Detect(ctx, clients, namespace, filterOpts)
+	diffs := make([]ResourceDiff, 0, len(detector.SupportedKinds()))
+	kinds := make(map[string]int)
+	for _, kind := range detector.SupportedKinds() {
+		kinds[kind] = len(diffs)
+		diffs = append(diffs, ResourceDiff{kind, nil})
+	}
+	for _, finding := range findings {
+		i, ok := kinds[finding.Kind]
+		if !ok {
+			i = len(diffs)
+			kinds[finding.Kind] = i
+			diffs = append(diffs, ResourceDiff{finding.Kind, nil})
+		}
+		diffs[i].diff = append(diffs[i].diff, finding.ResourceInfo)
+	}
+	return diffs, err
+}
+
+// builtinDetector wraps the detectors of kor.
+type builtinDetector struct {
+	name    string
+	kinds   []string
+	cluster bool
+	detect  func(ctx context.Context, clients Clients, namespace string, filterOpts *filters.Options) ([]ResourceDiff, error)
+}
+
+func (d *builtinDetector) Name() string             { return d.name }
+func (d *builtinDetector) SupportedKinds() []string { return d.kinds }
+func (d *builtinDetector) ClusterScoped() bool      { return d.cluster }
+
+func (d *builtinDetector) Detect(ctx context.Context, clients Clients, namespace string, filterOpts *filters.Options) ([]Finding, error) {
+	diffs, err := d.detect(ctx, clients, namespace, filterOpts)
+	var findings []Finding
+	for _, diff := range diffs {
+		for _, info := range diff.diff {
+			findings = append(findings, Finding{Kind: diff.resourceType, ResourceInfo: info})
+		}
+	}
+	return findings, err
+}
+
+func namespacedBuiltin(name, kind string, get func(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) (ResourceDiff, error)) *builtinDetector {
+	return &builtinDetector{name: name, kinds: []string{kind}, detect: func(ctx context.Context, clients Clients, namespace string, filterOpts *filters.Options) ([]ResourceDiff, error) {
+		diff, err := get(ctx, clients.Kubernetes, namespace, filterOpts)
+		return []ResourceDiff{diff}, err
+	}}
+}
+
+func clusterBuiltin(name, kind string, get func(ctx context.Context, clients Clients, filterOpts *filters.Options) (ResourceDiff, error)) *builtinDetector {
+	return &builtinDetector{name: name, kinds: []string{kind}, cluster: true, detect: func(ctx context.Context, clients Clients, _ string, filterOpts *filters.Options) ([]ResourceDiff, error) {
+		diff, err := get(ctx, clients, filterOpts)
+		return []ResourceDiff{diff}, err
+	}}
+}
+
+func init() {
+	builtins := []struct {
+		detector *builtinDetector
+		aliases  []string
+	}{
+		{namespacedBuiltin("configmap", "ConfigMap", getUnusedCMs), []string{"cm", "configmaps"}},
+		{namespacedBuiltin("service", "Service", getUnusedSVCs), []string{"svc", "services"}},
+		{namespacedBuiltin("secret", "Secret", getUnusedSecrets), []string{"secrets"}},
+		{namespacedBuiltin("serviceaccount", "ServiceAccount", getUnusedServiceAccounts), []string{"sa", "serviceaccounts"}},
+		{namespacedBuiltin("deployment", "Deployment", getUnusedDeployments), []string{"deploy", "deployments"}},
+		{namespacedBuiltin("statefulset", "StatefulSet", getUnusedStatefulSets), []string{"sts", "statefulsets"}},
+		{namespacedBuiltin("role", "Role", getUnusedRoles), []string{"roles"}},
+		{namespacedBuiltin("horizontalpodautoscaler", "Hpa", getUnusedHpas), []string{"hpa", "horizontalpodautoscalers"}},
+		{namespacedBuiltin("persistentvolumeclaim", "Pvc", getUnusedPvcs), []string{"pvc", "persistentvolumeclaims"}},
+		{namespacedBuiltin("pod", "Pod", getUnusedPods), []string{"po", "pods"}},
+		{namespacedBuiltin("ingress", "Ingress", getUnusedIngresses), []string{"ing", "ingresses"}},
+		{namespacedBuiltin("poddisruptionbudget", "Pdb", getUnusedPdbs), []string{"pdb", "poddisruptionbudgets"}},
+		{namespacedBuiltin("job", "Job", getUnusedJobs), []string{"jobs"}},
+		{namespacedBuiltin("replicaset", "ReplicaSet", getUnusedReplicaSets), []string{"rs", "replicasets"}},
+		{namespacedBuiltin("daemonset", "DaemonSet", getUnusedDaemonSets), []string{"ds", "daemonsets"}},
+		{namespacedBuiltin("networkpolicy", "NetworkPolicy", getUnusedNetworkPolicies), []string{"netpol", "networkpolicies"}},
+		{namespacedBuiltin("rolebinding", "RoleBinding", getUnusedRoleBindings), []string{"rolebindings"}},
+		{namespacedBuiltin("endpoints", "Endpoints", getUnusedEndpoints), []string{"ep"}},
+		{namespacedBuiltin("endpointslice", "EndpointSlice", getUnusedEndpointSlices), []string{"endpointslices"}},
+		{namespacedBuiltin("serviceaccounttoken", "ServiceAccountToken", getUnusedServiceAccountTokens), []string{"satoken", "satokens", "serviceaccounttokens"}},
+		{&builtinDetector{name: "helmrelease", kinds: []string{"HelmReleaseSecret"}, detect: func(ctx context.Context, clients Clients, namespace string, filterOpts *filters.Options) ([]ResourceDiff, error) {
+			diff, err := getUnusedHelmReleases(ctx, clients.Kubernetes, clients.Dynamic, namespace, filterOpts)
+			return []ResourceDiff{diff}, err
+		}}, []string{"helm", "helmreleases"}},
+		{&builtinDetector{name: "managedsecret", kinds: []string{"SealedSecret", "ExternalSecret", "GeneratedSecret"}, detect: func(ctx context.Context, clients Clients, namespace string, filterOpts *filters.Options) ([]ResourceDiff, error) {
+			return getUnusedManagedSecrets(ctx, clients.Kubernetes, clients.Dynamic, namespace, filterOpts)
+		}}, []string{"managedsecrets"}},
+		{&builtinDetector{name: "gitops", kinds: gitOpsKinds, detect: func(ctx context.Context, clients Clients, namespace string, filterOpts *filters.Options) ([]ResourceDiff, error) {
+			return getUnusedGitOpsResources(ctx, clients.Kubernetes, clients.Dynamic, namespace, filterOpts)
+		}}, nil},
+
+		{clusterBuiltin("customresourcedefinition", "Crd", func(ctx context.Context, clients Clients, filterOpts *filters.Options) (ResourceDiff, error) {
+			return getUnusedCrds(ctx, clients.APIExtensions, clients.Dynamic, filterOpts)
+		}), []string{"crd", "crds", "customresourcedefinitions"}},
+		{clusterBuiltin("persistentvolume", "Pv", func(ctx context.Context, clients Clients, filterOpts *filters.Options) (ResourceDiff, error) {
+			return getUnusedPvs(ctx, clients.Kubernetes, filterOpts)
+		}), []string{"pv", "persistentvolumes"}},
+		{clusterBuiltin("clusterrole", "ClusterRole", func(ctx context.Context, clients Clients, filterOpts *filters.Options) (ResourceDiff, error) {
+			return getUnusedClusterRoles(ctx, clients.Kubernetes, filterOpts)
+		}), []string{"clusterroles"}},
+		{clusterBuiltin("storageclass", "StorageClass", func(ctx context.Context, clients Clients, filterOpts *filters.Options) (ResourceDiff, error) {
+			return getUnusedStorageClasses(ctx, clients.Kubernetes, filterOpts)
+		}), []string{"sc", "storageclasses"}},
+		{clusterBuiltin("csidriver", "CSIDriver", func(ctx context.Context, clients Clients, filterOpts *filters.Options) (ResourceDiff, error) {
+			return getUnusedCSIDrivers(ctx, clients.Kubernetes, filterOpts)
+		}), []string{"csidrivers"}},
+		{clusterBuiltin("volumeattachment", "VolumeAttachment", func(ctx context.Context, clients Clients, filterOpts *filters.Options) (ResourceDiff, error) {
+			return getUnusedVolumeAttachments(ctx, clients.Kubernetes, filterOpts)
+		}), []string{"volumeattachments"}},
+		{clusterBuiltin("apiservice", "APIService", func(ctx context.Context, clients Clients, filterOpts *filters.Options) (ResourceDiff, error) {
+			return getUnusedAPIServices(ctx, clients.Kubernetes, clients.Dynamic, filterOpts)
+		}), []string{"apiservices"}},
+		{clusterBuiltin("node", "Node", func(ctx context.Context, clients Clients, filterOpts *filters.Options) (ResourceDiff, error) {
+			return getUnusedNodes(ctx, clients.Kubernetes, filterOpts)
+		}), []string{"no", "nodes"}},
+	}
+	for _, builtin := range builtins {
+		if err := RegisterDetector(builtin.detector, builtin.aliases...); err != nil {
+			panic(err)
+		}
+	}
+}
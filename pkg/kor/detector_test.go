@@ -0,0 +1,106 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+type testDetector struct {
+	name       string
+	cluster    bool
+	err        error
+	namespaces []string
+}
+
+func (d *testDetector) Name() string             { return d.name }
+func (d *testDetector) SupportedKinds() []string { return []string{"Widget", "Gadget"} }
+func (d *testDetector) ClusterScoped() bool      { return d.cluster }
+
+func (d *testDetector) Detect(_ context.Context, clients Clients, namespace string, _ *filters.Options) ([]Finding, error) {
+	if clients.Kubernetes == nil {
+		return nil, errors.New("missing clientset")
+	}
+	d.namespaces = append(d.namespaces, namespace)
+	return []Finding{{Kind: "Widget", ResourceInfo: ResourceInfo{Name: "widget-" + namespace, Reason: "Widget is not used"}}}, d.err
+}
+
+// registerTestDetector registers detector until the end of the test.
+func registerTestDetector(t *testing.T, detector Detector, aliases ...string) {
+	t.Helper()
+	detectorRegistry.Lock()
+	names := maps.Clone(detectorRegistry.names)
+	detectors := slices.Clone(detectorRegistry.detectors)
+	detectorRegistry.Unlock()
+	t.Cleanup(func() {
+		detectorRegistry.Lock()
+		defer detectorRegistry.Unlock()
+		detectorRegistry.names, detectorRegistry.detectors = names, detectors
+	})
+	if err := RegisterDetector(detector, aliases...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRegisterDetector(t *testing.T) {
+	registerTestDetector(t, &testDetector{name: "widget"}, "widgets")
+
+	if lookupDetector("Widgets") == nil {
+		t.Error("Expected the aliases to select the detector case-insensitively")
+	}
+	for _, name := range []string{"widget", "cm", "all", ""} {
+		if err := RegisterDetector(&testDetector{name: name}); err == nil {
+			t.Errorf("Expected detector %q not to be registered", name)
+		}
+	}
+	if lookupDetector("configmaps") == nil || !isClusterScoped(lookupDetector("pv")) || isClusterScoped(lookupDetector("cm")) {
+		t.Error("Expected the built-in detectors to be registered")
+	}
+	if detectors := Detectors(); detectors[len(detectors)-1].Name() != "widget" {
+		t.Error("Expected the detector to run after the built-in ones")
+	}
+}
+
+func TestGetUnusedMultiDetector(t *testing.T) {
+	defer resetReportedResources()
+	namespaced := &testDetector{name: "widget", err: errors.New("forbidden")}
+	cluster := &testDetector{name: "clusterwidget", cluster: true}
+	registerTestDetector(t, namespaced)
+	registerTestDetector(t, cluster)
+	clientset := createTestConfigmaps(t)
+
+	output, err := GetUnusedMulti(context.Background(), "widget,clusterwidget", &filters.Options{}, clientset, nil, nil, "json", common.Opts{GroupBy: "namespace"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resources map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &resources); err != nil {
+		t.Fatal(err)
+	}
+	if widgets := resources[testNamespace]["Widget"]; !slices.Equal(widgets, []string{"widget-" + testNamespace}) {
+		t.Errorf("Expected the findings of the namespaced detector in spite of its error, got %v", resources)
+	}
+	if widgets := resources[""]["Widget"]; !slices.Equal(widgets, []string{"widget-"}) {
+		t.Errorf("Expected the findings of the cluster-scoped detector, got %v", resources)
+	}
+	if !slices.Equal(cluster.namespaces, []string{""}) {
+		t.Errorf("Expected the cluster-scoped detector to run once, got %v", cluster.namespaces)
+	}
+
+	diffs := retrieveAllNamespacedDiffs(context.Background(), fake.NewSimpleClientset(), nil, testNamespace, &filters.Options{})
+	var kinds []string
+	for _, diff := range diffs[len(diffs)-2:] {
+		kinds = append(kinds, diff.resourceType)
+	}
+	if !slices.Equal(kinds, []string{"Widget", "Gadget"}) {
+		t.Errorf("Expected kor all to run the detector with a diff per supported kind, got %v", kinds)
+	}
+}
@@ -0,0 +1,160 @@
+package kor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"sigs.k8s.io/yaml"
+
+	"github.com/yonahd/kor/pkg/common"
+)
+
+// diffEntry is a resource in the comparison of two reports.
+type diffEntry struct {
+	Namespace string `json:"namespace,omitempty"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// ReportDiff holds the resources that became unused, stayed unused and were
+// resolved between two reports.
+type ReportDiff struct {
+	NewlyUnused []diffEntry `json:"newlyUnused"`
+	StillUnused []diffEntry `json:"stillUnused"`
+	Resolved    []diffEntry `json:"resolved"`
+}
+
+// loadReport reads a json or yaml report written by kor with the given --group-by.
+// Reports written without --show-reason hold plain names instead of objects.
+func loadReport(path, groupBy string) ([]unusedResource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]map[string][]json.RawMessage
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse report %s: %w", path, err)
+	}
+
+	resources := make(map[string]map[string][]ResourceInfo)
+	for outerKey, inner := range raw {
+		resources[outerKey] = make(map[string][]ResourceInfo)
+		for innerKey, items := range inner {
+			for _, item := range items {
+				var info ResourceInfo
+				if err := json.Unmarshal(item, &info.Name); err != nil {
+					if err := json.Unmarshal(item, &info); err != nil {
+						return nil, fmt.Errorf("failed to parse report %s: %w", path, err)
+					}
+				}
+				resources[outerKey][innerKey] = append(resources[outerKey][innerKey], info)
+			}
+		}
+	}
+	return flattenResources(resources, groupBy), nil
+}
+
+func diffFindings(oldFindings, newFindings []unusedResource) ReportDiff {
+	key := func(finding unusedResource) string {
+		return finding.Kind + "/" + finding.Namespace + "/" + finding.Name
+	}
+	toEntry := func(finding unusedResource) diffEntry {
+		return diffEntry{Namespace: finding.Namespace, Kind: finding.Kind, Name: finding.Name, Reason: finding.Reason}
+	}
+
+	previous := make(map[string]bool, len(oldFindings))
+	for _, finding := range oldFindings {
+		previous[key(finding)] = true
+	}
+	current := make(map[string]bool, len(newFindings))
+
+	result := ReportDiff{NewlyUnused: []diffEntry{}, StillUnused: []diffEntry{}, Resolved: []diffEntry{}}
+	for _, finding := range newFindings {
+		current[key(finding)] = true
+		if previous[key(finding)] {
+			result.StillUnused = append(result.StillUnused, toEntry(finding))
+		} else {
+			result.NewlyUnused = append(result.NewlyUnused, toEntry(finding))
+		}
+	}
+	for _, finding := range oldFindings {
+		if !current[key(finding)] {
+			result.Resolved = append(result.Resolved, toEntry(finding))
+		}
+	}
+	return result
+}
+
+// GetReportDiff compares two reports written with --output json or yaml.
+func GetReportDiff(oldPath, newPath, outputFormat string, opts common.Opts) (string, error) {
+	oldFindings, err := loadReport(oldPath, opts.GroupBy)
+	if err != nil {
+		return "", err
+	}
+	newFindings, err := loadReport(newPath, opts.GroupBy)
+	if err != nil {
+		return "", err
+	}
+	return formatReportDiff(diffFindings(oldFindings, newFindings), outputFormat, opts)
+}
+
+// GetBaselineDiff compares the resources reported during this run with a
+// baseline report.
+func GetBaselineDiff(baselinePath, outputFormat string, opts common.Opts) (string, error) {
+	baseline, err := loadReport(baselinePath, opts.GroupBy)
+	if err != nil {
+		return "", err
+	}
+	return formatReportDiff(diffFindings(baseline, reportedResources), outputFormat, opts)
+}
+
+func formatReportDiff(result ReportDiff, outputFormat string, opts common.Opts) (string, error) {
+	switch outputFormat {
+	case "table":
+		var buf strings.Builder
+		table := tablewriter.NewWriter(&buf)
+		table.SetColWidth(60)
+		header := []string{"#", "STATUS", "RESOURCE TYPE", "NAMESPACE", "RESOURCE NAME"}
+		if opts.ShowReason {
+			header = append(header, "REASON")
+		}
+		table.SetHeader(header)
+		var index int
+		for _, group := range []struct {
+			status  string
+			entries []diffEntry
+		}{
+			{"Newly unused", result.NewlyUnused},
+			{"Still unused", result.StillUnused},
+			{"Resolved", result.Resolved},
+		} {
+			for _, entry := range group.entries {
+				row := getTableRow(index, group.status, entry.Kind, entry.Namespace, entry.Name)
+				if opts.ShowReason {
+					row = append(row, entry.Reason)
+				}
+				table.Append(row)
+				index++
+			}
+		}
+		table.Render()
+		return fmt.Sprintf("%d newly unused, %d still unused, %d resolved\n%s", len(result.NewlyUnused), len(result.StillUnused), len(result.Resolved), buf.String()), nil
+	case "json", "yaml":
+		response, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		if outputFormat == "yaml" {
+			if response, err = yaml.JSONToYAML(response); err != nil {
+				return "", err
+			}
+		}
+		return string(response), nil
+	default:
+		return "", fmt.Errorf("unsupported output format for diff: %s", outputFormat)
+	}
+}
@@ -0,0 +1,55 @@
+package kor
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/yonahd/kor/pkg/common"
+)
+
+func TestGetReportDiff(t *testing.T) {
+	dir := t.TempDir()
+	oldReport := filepath.Join(dir, "old.json")
+	newReport := filepath.Join(dir, "new.yaml")
+
+	// Written without --show-reason
+	if err := os.WriteFile(oldReport, []byte(`{"test-namespace": {"ConfigMap": ["cm-1", "cm-2"]}}`), 0o644); err != nil {
+		t.Fatalf("Error writing report: %v", err)
+	}
+	newContent := `test-namespace:
+  ConfigMap:
+  - name: cm-2
+    reason: ConfigMap is not used in any pod or container
+  Secret:
+  - name: secret-1
+`
+	if err := os.WriteFile(newReport, []byte(newContent), 0o644); err != nil {
+		t.Fatalf("Error writing report: %v", err)
+	}
+
+	output, err := GetReportDiff(oldReport, newReport, "json", common.Opts{GroupBy: "namespace"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var result ReportDiff
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Error unmarshaling diff: %v", err)
+	}
+
+	expected := ReportDiff{
+		NewlyUnused: []diffEntry{{Namespace: testNamespace, Kind: "Secret", Name: "secret-1"}},
+		StillUnused: []diffEntry{{Namespace: testNamespace, Kind: "ConfigMap", Name: "cm-2", Reason: "ConfigMap is not used in any pod or container"}},
+		Resolved:    []diffEntry{{Namespace: testNamespace, Kind: "ConfigMap", Name: "cm-1"}},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+
+	if _, err := GetReportDiff(oldReport, filepath.Join(dir, "missing.json"), "table", common.Opts{GroupBy: "namespace"}); err == nil {
+		t.Error("Expected an error for a missing report")
+	}
+}
@@ -0,0 +1,238 @@
+package kor
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+const (
+	// EmailAttachHTML attaches the report as an HTML table
+	EmailAttachHTML = "html"
+	// EmailAttachCSV attaches the report as CSV, for spreadsheets
+	EmailAttachCSV = "csv"
+)
+
+// EmailAttachments lists the values accepted by --email-attach.
+var EmailAttachments = []string{EmailAttachHTML, EmailAttachCSV}
+
+// reportRow is a resource of the detailed report, in the email attachments.
+// Cluster is only set by the scans across several kubeconfig contexts.
+type reportRow struct {
+	Cluster   string
+	Namespace string
+	Kind      string
+	ResourceInfo
+}
+
+// reportRows returns the detailed report sorted by namespace, kind and name.
+func reportRows(report map[string]map[string][]ResourceInfo) []reportRow {
+	var rows []reportRow
+	for namespace, kinds := range report {
+		for kind, infos := range kinds {
+			for _, info := range infos {
+				rows = append(rows, reportRow{Namespace: namespace, Kind: kind, ResourceInfo: info})
+			}
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	return rows
+}
+
+func (r reportRow) columns() []string {
+	created, size := "", ""
+	if r.CreationTimestamp != nil {
+		created = r.CreationTimestamp.UTC().Format(time.RFC3339)
+	}
+	if r.Size != nil {
+		size = r.Size.String()
+	}
+	return []string{r.Namespace, r.Kind, r.Name, r.Reason, created, size, r.Owners, r.ManagedBy, r.HelmRelease}
+}
+
+var reportColumns = []string{"Namespace", "Kind", "Name", "Reason", "Created", "Size", "Owners", "Managed By", "Helm Release"}
+
+// costed reports whether the rows carry the costs of --show-cost, which
+// add a Monthly Cost column.
+func costed(rows []reportRow) bool {
+	return slices.ContainsFunc(rows, func(row reportRow) bool { return row.MonthlyCost != nil })
+}
+
+// costColumn is the Monthly Cost column of row.
+func (r reportRow) costColumn() string {
+	if r.MonthlyCost == nil {
+		return ""
+	}
+	return r.MonthlyCost.String()
+}
+
+func renderCSVReport(rows []reportRow) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	clustered := slices.ContainsFunc(rows, func(row reportRow) bool { return row.Cluster != "" })
+	withCost := costed(rows)
+	columns := reportColumns
+	if clustered {
+		columns = append([]string{"Cluster"}, reportColumns...)
+	}
+	if withCost {
+		columns = append(slices.Clip(columns), "Monthly Cost")
+	}
+	if err := writer.Write(columns); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		columns := row.columns()
+		if clustered {
+			columns = append([]string{row.Cluster}, columns...)
+		}
+		if withCost {
+			columns = append(columns, row.costColumn())
+		}
+		if err := writer.Write(columns); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+th { background: #f2f2f2; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>Generated on {{.GeneratedAt}}</p>
+{{- if .Rows}}
+<table>
+<tr>{{range .Columns}}<th>{{.}}</th>{{end}}</tr>
+{{- range .Rows}}
+<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+func renderHTMLReport(title string, rows []reportRow, now time.Time) ([]byte, error) {
+	withCost := costed(rows)
+	columns := reportColumns
+	if withCost {
+		columns = append(slices.Clip(columns), "Monthly Cost")
+	}
+	cells := make([][]string, len(rows))
+	for i, row := range rows {
+		cells[i] = row.columns()
+		if withCost {
+			cells[i] = append(cells[i], row.costColumn())
+		}
+	}
+	var buf bytes.Buffer
+	err := htmlReportTemplate.Execute(&buf, map[string]interface{}{
+		"Title":       title,
+		"GeneratedAt": now.UTC().Format(time.RFC3339),
+		"Columns":     columns,
+		"Rows":        cells,
+	})
+	return buf.Bytes(), err
+}
+
+// ValidateEmailOptions checks the --email-* and --smtp-* values.
+func ValidateEmailOptions(opts common.Opts) error {
+	if len(opts.EmailTo) == 0 {
+		return nil
+	}
+	if opts.SMTPHost == "" || opts.EmailFrom == "" {
+		return fmt.Errorf("--email-to requires --smtp-host and --email-from")
+	}
+	for _, attach := range opts.EmailAttach {
+		if attach != EmailAttachHTML && attach != EmailAttachCSV {
+			return fmt.Errorf("invalid email attachment %q, must be one of %v", attach, EmailAttachments)
+		}
+	}
+	return nil
+}
+
+// emailReport builds the email of the resources reported during this run:
+// the summary in the body and the detailed report attached.
+func emailReport(opts common.Opts, summary notificationSummary, now time.Time) (utils.EmailMessage, error) {
+	message := utils.EmailMessage{
+		From:    opts.EmailFrom,
+		To:      opts.EmailTo,
+		Subject: summary.title(),
+		Body:    plainSummary(summary),
+	}
+
+	rows := reportRows(reportedInfo)
+	for _, attach := range opts.EmailAttach {
+		var attachment utils.EmailAttachment
+		var err error
+		switch attach {
+		case EmailAttachHTML:
+			attachment = utils.EmailAttachment{Name: "kor-report.html", ContentType: "text/html; charset=utf-8"}
+			attachment.Data, err = renderHTMLReport(summary.title(), rows, now)
+		case EmailAttachCSV:
+			attachment = utils.EmailAttachment{Name: "kor-report.csv", ContentType: "text/csv; charset=utf-8"}
+			attachment.Data, err = renderCSVReport(rows)
+		}
+		if err != nil {
+			return message, err
+		}
+		message.Attachments = append(message.Attachments, attachment)
+	}
+	return message, nil
+}
+
+// plainSummary renders the summary as plain text.
+func plainSummary(summary notificationSummary) string {
+	var sb strings.Builder
+	sb.WriteString(summary.title() + "\n")
+	for _, ns := range summary.Namespaces {
+		sb.WriteString("\n" + ns.heading(plainQuote) + "\n")
+		for _, kind := range ns.Kinds {
+			sb.WriteString("- " + kind.line(plainQuote) + "\n")
+		}
+	}
+	return sb.String()
+}
+
+func sendEmailReport(opts common.Opts, summary notificationSummary) error {
+	message, err := emailReport(opts, summary, time.Now())
+	if err != nil {
+		return err
+	}
+	password := opts.SMTPPassword
+	if password == "" {
+		password = os.Getenv("KOR_SMTP_PASSWORD")
+	}
+	cfg := utils.SMTPConfig{Host: opts.SMTPHost, Port: opts.SMTPPort, Username: opts.SMTPUsername, Password: password}
+	return utils.SendEmail(cfg, message)
+}
@@ -0,0 +1,99 @@
+package kor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/yonahd/kor/pkg/common"
+)
+
+func emailTestReport() map[string]map[string][]ResourceInfo {
+	created := metav1.NewTime(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	return map[string]map[string][]ResourceInfo{
+		"default": {
+			"Secret":    {{Name: "token", Reason: "Secret is not used, \"anywhere\""}},
+			"ConfigMap": {{Name: "<script>", CreationTimestamp: &created}},
+		},
+		"": {"Pv": {{Name: "pv-1"}}},
+	}
+}
+
+func TestRenderCSVReport(t *testing.T) {
+	data, err := renderCSVReport(reportRows(emailTestReport()))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := `Namespace,Kind,Name,Reason,Created,Size,Owners,Managed By,Helm Release
+,Pv,pv-1,,,,,,
+default,ConfigMap,<script>,,2026-01-02T03:04:05Z,,,,
+default,Secret,token,"Secret is not used, ""anywhere""",,,,,
+`
+	if string(data) != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, data)
+	}
+}
+
+func TestRenderHTMLReport(t *testing.T) {
+	data, err := renderHTMLReport("kor found 3 unused resources in 2 namespaces", reportRows(emailTestReport()), time.Date(2026, 10, 14, 1, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	html := string(data)
+	for _, want := range []string{
+		"<h1>kor found 3 unused resources in 2 namespaces</h1>",
+		"<p>Generated on 2026-10-14T01:00:00Z</p>",
+		"<th>Helm Release</th>",
+		"<td>default</td><td>ConfigMap</td><td>&lt;script&gt;</td>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Expected report to contain %q, got:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "<script>") {
+		t.Error("Expected resource names to be escaped")
+	}
+}
+
+func TestEmailReport(t *testing.T) {
+	resetReportedResources()
+	defer resetReportedResources()
+	recordUnusedResources(emailTestReport(), "namespace")
+
+	opts := common.Opts{EmailFrom: "kor@example.com", EmailTo: []string{"ops@example.com"}, EmailAttach: []string{EmailAttachCSV}}
+	message, err := emailReport(opts, summarizeFindings(reportedResources), time.Now())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if message.Subject != "kor found 3 unused resources in 2 namespaces" {
+		t.Errorf("Unexpected subject %q", message.Subject)
+	}
+	if !strings.Contains(message.Body, "Namespace default\n- ConfigMap (1): <script>\n") {
+		t.Errorf("Expected the summary in the body, got:\n%s", message.Body)
+	}
+	if len(message.Attachments) != 1 || message.Attachments[0].Name != "kor-report.csv" {
+		t.Errorf("Expected the CSV report attached, got %v", message.Attachments)
+	}
+}
+
+func TestValidateEmailOptions(t *testing.T) {
+	valid := common.Opts{EmailTo: []string{"ops@example.com"}, EmailFrom: "kor@example.com", SMTPHost: "smtp.example.com", EmailAttach: EmailAttachments}
+	if err := ValidateEmailOptions(valid); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if err := ValidateEmailOptions(common.Opts{}); err != nil {
+		t.Errorf("Expected no error without email, got %v", err)
+	}
+
+	missingHost := valid
+	missingHost.SMTPHost = ""
+	invalidAttach := valid
+	invalidAttach.EmailAttach = []string{"pdf"}
+	for _, opts := range []common.Opts{missingHost, invalidAttach} {
+		if err := ValidateEmailOptions(opts); err == nil {
+			t.Errorf("Expected an error for %+v", opts)
+		}
+	}
+}
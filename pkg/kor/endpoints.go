@@ -0,0 +1,130 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+//go:embed exceptions/endpoints/endpoints.json
+var endpointsConfig []byte
+
+func retrieveServiceNames(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]string, error) {
+	services, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.CoreV1().Services(namespace).List)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(services.Items))
+	for _, service := range services.Items {
+		names = append(names, service.Name)
+	}
+	return names, nil
+}
+
+func processNamespaceEndpoints(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	endpointsList, err := utils.ListAll(ctx, filterOpts.ListOptions(), clientset.CoreV1().Endpoints(namespace).List)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := unmarshalConfig(endpointsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceNames, err := retrieveServiceNames(ctx, clientset, namespace)
+	if err != nil {
+		return nil, err
+	}
+	services := newNameSet(serviceNames)
+
+	var unusedEndpoints []ResourceInfo
+
+	for _, endpoints := range endpointsList.Items {
+		if pass, _ := filter.SetObject(&endpoints).Run(filterOpts); pass {
+			continue
+		}
+
+		if endpoints.Labels["kor/used"] == "false" {
+			reason := "Marked with unused label"
+			unusedEndpoints = append(unusedEndpoints, ResourceInfo{Name: endpoints.Name, Reason: reason})
+			continue
+		}
+
+		exceptionFound, err := isResourceException(endpoints.Name, endpoints.Namespace, config.ExceptionEndpoints)
+		if err != nil {
+			return nil, err
+		}
+
+		if exceptionFound || isLeaderElectionLock(&endpoints) {
+			continue
+		}
+
+		if !services.has(endpoints.Name) {
+			reason := "Endpoints has no parent Service"
+			unusedEndpoints = append(unusedEndpoints, ResourceInfo{Name: endpoints.Name, Reason: reason})
+		}
+	}
+
+	return unusedEndpoints, nil
+}
+
+func GetUnusedEndpoints(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	clientset = scanClientset(clientset)
+	resources := make(map[string]map[string][]ResourceInfo)
+	for _, scan := range scanNamespaces(filterOpts.Namespaces(ctx, clientset), func(namespace string) ([]ResourceInfo, error) {
+		return processNamespaceEndpoints(ctx, clientset, namespace, filterOpts)
+	}) {
+		namespace, diff, err := scan.namespace, scan.result, scan.err
+		if err != nil {
+			recordScanError("Endpoints", namespace, err)
+			continue
+		}
+		exportManifests(ctx, clientset, namespace, "Endpoints", diff, opts)
+		if deleteEnabled(opts, "Endpoints") {
+			if diff, err = DeleteResource(ctx, diff, clientset, namespace, "Endpoints", opts.NoInteractive); err != nil {
+				slog.Error("Failed to delete Endpoints", "name", diff, "namespace", namespace, "error", err)
+			}
+		}
+		switch opts.GroupBy {
+		case "namespace":
+			resources[namespace] = make(map[string][]ResourceInfo)
+			resources[namespace]["Endpoints"] = diff
+		case "resource":
+			appendResources(resources, "Endpoints", namespace, diff)
+		}
+	}
+
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+	default:
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	unusedEndpoints, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return unusedEndpoints, nil
+}
@@ -0,0 +1,120 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestEndpoints(t *testing.T) *fake.Clientset {
+	clientset := fake.NewSimpleClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{
+		ObjectMeta: v1.ObjectMeta{Name: testNamespace},
+	}, v1.CreateOptions{})
+
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+
+	service1 := CreateTestService(testNamespace, "test-service1")
+	_, err = clientset.CoreV1().Services(testNamespace).Create(context.TODO(), service1, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake service: %v", err)
+	}
+
+	endpoint1 := CreateTestEndpoint(testNamespace, "test-service1", 1, AppLabels)
+	_, err = clientset.CoreV1().Endpoints(testNamespace).Create(context.TODO(), endpoint1, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake endpoint: %v", err)
+	}
+
+	endpoint2 := CreateTestEndpoint(testNamespace, "test-endpoint2", 1, AppLabels)
+	_, err = clientset.CoreV1().Endpoints(testNamespace).Create(context.TODO(), endpoint2, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake endpoint: %v", err)
+	}
+
+	endpoint3 := CreateTestEndpoint(testNamespace, "test-endpoint3", 1, UsedLabels)
+	_, err = clientset.CoreV1().Endpoints(testNamespace).Create(context.TODO(), endpoint3, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake endpoint: %v", err)
+	}
+
+	endpoint4 := CreateTestEndpoint(testNamespace, "test-service1-copy", 1, UnusedLabels)
+	_, err = clientset.CoreV1().Endpoints(testNamespace).Create(context.TODO(), endpoint4, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake endpoint: %v", err)
+	}
+
+	return clientset
+}
+
+func TestProcessNamespaceEndpoints(t *testing.T) {
+	clientset := createTestEndpoints(t)
+
+	unusedEndpoints, err := processNamespaceEndpoints(context.Background(), clientset, testNamespace, &filters.Options{})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	expected := []ResourceInfo{
+		{Name: "test-endpoint2", Reason: "Endpoints has no parent Service"},
+		{Name: "test-service1-copy", Reason: "Marked with unused label"},
+	}
+	if !equalResourceInfoSlices(unusedEndpoints, expected) {
+		t.Errorf("Expected %v, got %v", expected, unusedEndpoints)
+	}
+}
+
+func TestGetUnusedEndpointsStructured(t *testing.T) {
+	clientset := createTestEndpoints(t)
+
+	opts := common.Opts{
+		WebhookURL:    "",
+		Channel:       "",
+		Token:         "",
+		DeleteFlag:    false,
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+
+	output, err := GetUnusedEndpoints(context.Background(), &filters.Options{}, clientset, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedEndpointsStructured: %v", err)
+	}
+
+	expectedOutput := map[string]map[string][]string{
+		testNamespace: {
+			"Endpoints": {
+				"test-endpoint2",
+				"test-service1-copy",
+			},
+		},
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+
+	if !reflect.DeepEqual(expectedOutput, actualOutput) {
+		t.Errorf("Expected output does not match actual output")
+	}
+}
+
+func init() {
+	scheme.Scheme = runtime.NewScheme()
+	_ = appsv1.AddToScheme(scheme.Scheme)
+}
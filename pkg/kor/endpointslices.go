@@ -0,0 +1,106 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+func processNamespaceEndpointSlices(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	endpointSlices, err := utils.ListAll(ctx, filterOpts.ListOptions(), clientset.DiscoveryV1().EndpointSlices(namespace).List)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceNames, err := retrieveServiceNames(ctx, clientset, namespace)
+	if err != nil {
+		return nil, err
+	}
+	services := newNameSet(serviceNames)
+
+	var unusedEndpointSlices []ResourceInfo
+
+	for _, endpointSlice := range endpointSlices.Items {
+		if pass, _ := filter.SetObject(&endpointSlice).Run(filterOpts); pass {
+			continue
+		}
+
+		if endpointSlice.Labels["kor/used"] == "false" {
+			reason := "Marked with unused label"
+			unusedEndpointSlices = append(unusedEndpointSlices, ResourceInfo{Name: endpointSlice.Name, Reason: reason})
+			continue
+		}
+
+		serviceName, ok := endpointSlice.Labels[discoveryv1.LabelServiceName]
+		if !ok || serviceName == "" {
+			reason := "EndpointSlice is not associated with any Service"
+			unusedEndpointSlices = append(unusedEndpointSlices, ResourceInfo{Name: endpointSlice.Name, Reason: reason})
+			continue
+		}
+
+		if !services.has(serviceName) {
+			reason := fmt.Sprintf("EndpointSlice's parent Service %s does not exist", serviceName)
+			unusedEndpointSlices = append(unusedEndpointSlices, ResourceInfo{Name: endpointSlice.Name, Reason: reason})
+		}
+	}
+
+	return unusedEndpointSlices, nil
+}
+
+func GetUnusedEndpointSlices(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	clientset = scanClientset(clientset)
+	resources := make(map[string]map[string][]ResourceInfo)
+	for _, scan := range scanNamespaces(filterOpts.Namespaces(ctx, clientset), func(namespace string) ([]ResourceInfo, error) {
+		return processNamespaceEndpointSlices(ctx, clientset, namespace, filterOpts)
+	}) {
+		namespace, diff, err := scan.namespace, scan.result, scan.err
+		if err != nil {
+			recordScanError("EndpointSlice", namespace, err)
+			continue
+		}
+		exportManifests(ctx, clientset, namespace, "EndpointSlice", diff, opts)
+		if deleteEnabled(opts, "EndpointSlice") {
+			if diff, err = DeleteResource(ctx, diff, clientset, namespace, "EndpointSlice", opts.NoInteractive); err != nil {
+				slog.Error("Failed to delete EndpointSlice", "name", diff, "namespace", namespace, "error", err)
+			}
+		}
+		switch opts.GroupBy {
+		case "namespace":
+			resources[namespace] = make(map[string][]ResourceInfo)
+			resources[namespace]["EndpointSlice"] = diff
+		case "resource":
+			appendResources(resources, "EndpointSlice", namespace, diff)
+		}
+	}
+
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+	default:
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	unusedEndpointSlices, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return unusedEndpointSlices, nil
+}
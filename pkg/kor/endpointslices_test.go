@@ -0,0 +1,116 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestEndpointSlices(t *testing.T) *fake.Clientset {
+	clientset := fake.NewSimpleClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{
+		ObjectMeta: v1.ObjectMeta{Name: testNamespace},
+	}, v1.CreateOptions{})
+
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+
+	service1 := CreateTestService(testNamespace, "test-service1")
+	_, err = clientset.CoreV1().Services(testNamespace).Create(context.TODO(), service1, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake service: %v", err)
+	}
+
+	endpointSlices := []struct {
+		name, service string
+		labels        map[string]string
+	}{
+		{"test-service1-abcde", "test-service1", AppLabels},
+		{"test-service2-abcde", "test-service2", AppLabels},
+		{"manual-slice", "", AppLabels},
+		{"used-slice", "", UsedLabels},
+		{"test-service1-fghij", "test-service1", UnusedLabels},
+	}
+	for _, endpointSlice := range endpointSlices {
+		slice := CreateTestEndpointSlice(testNamespace, endpointSlice.name, endpointSlice.service, endpointSlice.labels)
+		_, err = clientset.DiscoveryV1().EndpointSlices(testNamespace).Create(context.TODO(), slice, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake endpointslice: %v", err)
+		}
+	}
+
+	return clientset
+}
+
+func TestProcessNamespaceEndpointSlices(t *testing.T) {
+	clientset := createTestEndpointSlices(t)
+
+	unusedEndpointSlices, err := processNamespaceEndpointSlices(context.Background(), clientset, testNamespace, &filters.Options{})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	expected := []ResourceInfo{
+		{Name: "manual-slice", Reason: "EndpointSlice is not associated with any Service"},
+		{Name: "test-service1-fghij", Reason: "Marked with unused label"},
+		{Name: "test-service2-abcde", Reason: "EndpointSlice's parent Service test-service2 does not exist"},
+	}
+	if !equalResourceInfoSlices(unusedEndpointSlices, expected) {
+		t.Errorf("Expected %v, got %v", expected, unusedEndpointSlices)
+	}
+}
+
+func TestGetUnusedEndpointSlicesStructured(t *testing.T) {
+	clientset := createTestEndpointSlices(t)
+
+	opts := common.Opts{
+		WebhookURL:    "",
+		Channel:       "",
+		Token:         "",
+		DeleteFlag:    false,
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+
+	output, err := GetUnusedEndpointSlices(context.Background(), &filters.Options{}, clientset, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedEndpointSlicesStructured: %v", err)
+	}
+
+	expectedOutput := map[string]map[string][]string{
+		testNamespace: {
+			"EndpointSlice": {
+				"manual-slice",
+				"test-service1-fghij",
+				"test-service2-abcde",
+			},
+		},
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+
+	if !reflect.DeepEqual(expectedOutput, actualOutput) {
+		t.Errorf("Expected output does not match actual output")
+	}
+}
+
+func init() {
+	scheme.Scheme = runtime.NewScheme()
+	_ = appsv1.AddToScheme(scheme.Scheme)
+}
@@ -0,0 +1,188 @@
+package kor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/homedir"
+	"sigs.k8s.io/yaml"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+// clusterScopedManifestFile holds the exported manifests of cluster-scoped resources.
+const clusterScopedManifestFile = "cluster-scoped.yaml"
+
+var (
+	exportScheme = runtime.NewScheme()
+	// exportedFiles tracks the files written during this run, so earlier runs
+	// are overwritten while the detectors of this run append to them.
+	exportedFiles = make(map[string]bool)
+	// exportedObjects holds the content of the S3 objects written during this
+	// run, S3 objects cannot be appended to so they are uploaded whole.
+	exportedObjects = make(map[string][]byte)
+	exportS3Client  *utils.S3Client
+	// exportFailed is set once a manifest could not be exported, the deletions
+	// are then skipped so nothing is deleted without a backup.
+	exportFailed bool
+)
+
+// serverFields are set by the API server, they are stripped from exported
+// manifests so the resources can be created again.
+var serverFields = [][]string{
+	{"metadata", "uid"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "generation"},
+	{"metadata", "creationTimestamp"},
+	{"metadata", "deletionTimestamp"},
+	{"metadata", "deletionGracePeriodSeconds"},
+	{"metadata", "selfLink"},
+	{"metadata", "managedFields"},
+	// The owners are deleted too or the restored resource is garbage collected
+	{"metadata", "ownerReferences"},
+	{"status"},
+}
+
+// DefaultBackupDir returns the directory the manifests are backed up to when
+// deleting without --export-manifests.
+func DefaultBackupDir(now time.Time) string {
+	return filepath.Join(homedir.HomeDir(), ".kor", "backups", now.Format("20060102-150405"))
+}
+
+func init() {
+	_ = clientgoscheme.AddToScheme(exportScheme)
+}
+
+// exportManifests writes the full YAML of every unused resource to a file per
+// namespace under opts.ExportManifests, so a cleanup can be reverted. Failures
+// are reported and do not stop the scan.
+func exportManifests(ctx context.Context, clientset kubernetes.Interface, namespace, resourceType string, diff []ResourceInfo, opts common.Opts) {
+	if opts.ExportManifests == "" || len(diff) == 0 {
+		return
+	}
+	if mapped, ok := reportResourceTypes[resourceType]; ok {
+		resourceType = mapped
+	}
+
+	var manifests []byte
+	for _, info := range diff {
+		obj, err := getResource(ctx, clientset, namespace, resourceType, info.Name)
+		if err != nil {
+			slog.Error("Failed to export resource", "resource", resourceType, "name", info.Name, "error", err)
+			// Anything but a resource deleted in the meantime fails for the remaining ones too
+			if !apierrors.IsNotFound(err) {
+				exportFailed = true
+				break
+			}
+			continue
+		}
+		manifest, err := marshalManifest(obj)
+		if err != nil {
+			slog.Error("Failed to export resource", "resource", resourceType, "name", info.Name, "error", err)
+			exportFailed = true
+			continue
+		}
+		manifests = append(manifests, "---\n"...)
+		manifests = append(manifests, manifest...)
+	}
+
+	if err := writeManifests(opts.ExportManifests, namespace, manifests); err != nil {
+		slog.Error("Failed to export manifests", "resource", resourceType, "error", err)
+		exportFailed = true
+	}
+}
+
+// exportResourceManifests exports every resource of a grouped report.
+func exportResourceManifests(ctx context.Context, clientset kubernetes.Interface, resources map[string]map[string][]ResourceInfo, opts common.Opts) {
+	if opts.ExportManifests == "" {
+		return
+	}
+	for outerKey, inner := range resources {
+		for innerKey, diff := range inner {
+			namespace, kind := outerKey, innerKey
+			if opts.GroupBy == "resource" {
+				namespace, kind = innerKey, outerKey
+			}
+			exportManifests(ctx, clientset, namespace, kind, diff, opts)
+		}
+	}
+}
+
+func marshalManifest(obj interface{}) ([]byte, error) {
+	runtimeObj, ok := obj.(runtime.Object)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type %T", obj)
+	}
+	runtimeObj = runtimeObj.DeepCopyObject()
+
+	// Typed clients drop the apiVersion and kind, restore them so the manifest can be applied again
+	if gvks, _, err := exportScheme.ObjectKinds(runtimeObj); err == nil && len(gvks) > 0 {
+		runtimeObj.GetObjectKind().SetGroupVersionKind(gvks[0])
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(runtimeObj)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range serverFields {
+		unstructured.RemoveNestedField(content, field...)
+	}
+	return yaml.Marshal(content)
+}
+
+func writeManifests(dir, namespace string, manifests []byte) error {
+	if len(manifests) == 0 {
+		return nil
+	}
+	name := namespace + ".yaml"
+	if namespace == "" {
+		name = clusterScopedManifestFile
+	}
+	if utils.IsS3URL(dir) {
+		return uploadManifests(dir, name, manifests)
+	}
+
+	// The manifests may be of Secrets, only the user can read them
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, name)
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if !exportedFiles[path] {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+		exportedFiles[path] = true
+	}
+	file, err := os.OpenFile(path, flags, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(manifests)
+	return err
+}
+
+func uploadManifests(url, name string, manifests []byte) error {
+	location, err := utils.ParseS3URL(url)
+	if err != nil {
+		return err
+	}
+	if exportS3Client == nil {
+		if exportS3Client, err = utils.NewS3ClientFromEnv(); err != nil {
+			return err
+		}
+	}
+	key := location.Key(name)
+	exportedObjects[key] = append(exportedObjects[key], manifests...)
+	return exportS3Client.PutObject(location.Bucket, key, exportedObjects[key])
+}
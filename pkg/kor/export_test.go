@@ -0,0 +1,64 @@
+package kor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+)
+
+func TestExportManifests(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	configmap := CreateTestConfigmap(testNamespace, "cm-1", AppLabels)
+	configmap.Data = map[string]string{"key": "value"}
+	if _, err := clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), configmap, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating fake configmap: %v", err)
+	}
+	if _, err := clientset.StorageV1().StorageClasses().Create(context.TODO(), CreateTestStorageClass("sc-1", "kubernetes.io/no-provisioner"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating fake storageclass: %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "backup")
+	opts := common.Opts{ExportManifests: dir}
+
+	exportManifests(context.Background(), clientset, testNamespace, "ConfigMap", []ResourceInfo{{Name: "cm-1"}, {Name: "missing-cm"}}, opts)
+	exportManifests(context.Background(), clientset, "", "StorageClass", []ResourceInfo{{Name: "sc-1"}}, opts)
+
+	content, err := os.ReadFile(filepath.Join(dir, testNamespace+".yaml"))
+	if err != nil {
+		t.Fatalf("Error reading exported manifests: %v", err)
+	}
+	for _, expected := range []string{"apiVersion: v1", "kind: ConfigMap", "name: cm-1", "key: value"} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Expected exported manifest to contain %q, got:\n%s", expected, content)
+		}
+	}
+	if strings.Contains(string(content), "missing-cm") {
+		t.Errorf("Expected missing configmap to be skipped, got:\n%s", content)
+	}
+
+	content, err = os.ReadFile(filepath.Join(dir, clusterScopedManifestFile))
+	if err != nil {
+		t.Fatalf("Error reading exported cluster-scoped manifests: %v", err)
+	}
+	if !strings.Contains(string(content), "kind: StorageClass") {
+		t.Errorf("Expected exported StorageClass manifest, got:\n%s", content)
+	}
+
+	for path, mode := range map[string]os.FileMode{dir: 0o700, filepath.Join(dir, testNamespace+".yaml"): 0o600} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Error reading the mode of %s: %v", path, err)
+		}
+		if info.Mode().Perm() != mode {
+			t.Errorf("Expected %s to be private with mode %v, got %v", path, mode, info.Mode().Perm())
+		}
+	}
+}
@@ -1,8 +1,9 @@
 package kor
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"strconv"
@@ -19,6 +20,13 @@ import (
 	"github.com/yonahd/kor/pkg/filters"
 )
 
+const (
+	// DefaultExporterAddress is the address the exporter listens on
+	DefaultExporterAddress = ":8080"
+	// defaultExporterInterval is used when neither --interval nor EXPORTER_INTERVAL is set
+	defaultExporterInterval = 10 * time.Minute
+)
+
 var (
 	orphanedResourcesCounter = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -27,63 +35,124 @@ var (
 		},
 		[]string{"kind", "namespace", "resourceName"},
 	)
+	unusedResourcesGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kor_unused_resources",
+			Help: "Number of unused resources found by the last scan",
+		},
+		[]string{"kind", "namespace"},
+	)
+	scanDurationGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "kor_scan_duration_seconds",
+			Help: "Duration of the last scan",
+		},
+	)
+	lastScanGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "kor_last_scan_timestamp_seconds",
+			Help: "Unix time of the last successful scan",
+		},
+	)
+	scanErrorsCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "kor_scan_errors_total",
+			Help: "Number of failed scans",
+		},
+	)
 )
 
 func init() {
-	prometheus.MustRegister(orphanedResourcesCounter)
+	prometheus.MustRegister(orphanedResourcesCounter, unusedResourcesGauge, scanDurationGauge, lastScanGauge, scanErrorsCounter)
 }
 
-// TODO: add option to change port / url !?
-func Exporter(filterOptions *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts, resourceList []string) {
-	http.Handle("/metrics", promhttp.Handler())
-	fmt.Println("Server listening on :8080")
-	go exportMetrics(filterOptions, clientset, apiExtClient, dynamicClient, outputFormat, opts, resourceList) // Start exporting metrics in the background
-	if err := http.ListenAndServe(":8080", nil); err != nil {
-		fmt.Println(err)
+// Exporter serves /metrics on listenAddress and rescans the cluster every
+// interval. A zero interval is read as minutes from EXPORTER_INTERVAL.
+func Exporter(ctx context.Context, filterOptions *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts, resourceList []string, listenAddress string, interval time.Duration) error {
+	clientset = cachedClientset(ctx, clientset)
+	interval, err := exporterInterval(interval)
+	if err != nil {
+		return err
 	}
+	if listenAddress == "" {
+		listenAddress = DefaultExporterAddress
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	handlePprof(mux)
+	slog.Info("Server listening", "address", listenAddress, "interval", interval)
+	go exportMetrics(ctx, filterOptions, clientset, apiExtClient, dynamicClient, outputFormat, opts, resourceList, interval) // Start exporting metrics in the background
+	return http.ListenAndServe(listenAddress, mux)
 }
 
-func exportMetrics(filterOptions *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts, resourceList []string) {
+func exporterInterval(interval time.Duration) (time.Duration, error) {
+	if interval < 0 {
+		return 0, fmt.Errorf("invalid exporter interval %s, must be positive", interval)
+	}
+	if interval > 0 {
+		return interval, nil
+	}
 	exporterInterval := os.Getenv("EXPORTER_INTERVAL")
 	if exporterInterval == "" {
-		exporterInterval = "10"
+		return defaultExporterInterval, nil
 	}
-	exporterIntervalValue, err := strconv.Atoi(exporterInterval)
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	minutes, err := strconv.Atoi(exporterInterval)
+	if err != nil || minutes <= 0 {
+		return 0, fmt.Errorf("invalid EXPORTER_INTERVAL %q, must be a number of minutes", exporterInterval)
 	}
+	return time.Duration(minutes) * time.Minute, nil
+}
 
+func exportMetrics(ctx context.Context, filterOptions *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts, resourceList []string, interval time.Duration) {
 	for {
-		fmt.Println("collecting unused resources")
-		if korOutput, err := getUnusedResources(filterOptions, clientset, apiExtClient, dynamicClient, outputFormat, opts, resourceList); err != nil {
-			fmt.Println(err)
-			os.Exit(1)
-		} else {
-			var data map[string]map[string][]string
-			if err := json.Unmarshal([]byte(korOutput), &data); err != nil {
-				fmt.Println("Error parsing JSON:", err)
-				return
-			}
+		// A failed scan keeps the metrics of the previous one, the next scan may succeed
+		if err := scanMetrics(ctx, filterOptions, clientset, apiExtClient, dynamicClient, outputFormat, opts, resourceList); err != nil {
+			scanErrorsCounter.Inc()
+			slog.Error("Failed to collect unused resources", "error", err)
+		}
+		time.Sleep(interval)
+	}
+}
 
-			orphanedResourcesCounter.Reset()
+func scanMetrics(ctx context.Context, filterOptions *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts, resourceList []string) error {
+	slog.Info("Collecting unused resources")
+	start := time.Now()
+	// Each scan is a trace of its own, the wait since the previous one is not part of it
+	if tracer != nil {
+		tracer.startTrace()
+	}
+	// Only the current scan counts, the exporter would otherwise keep every earlier finding
+	resetReportedResources()
+	if _, err := getUnusedResources(ctx, filterOptions, clientset, apiExtClient, dynamicClient, outputFormat, opts, resourceList); err != nil {
+		return err
+	}
+	updateMetrics(reportedResources)
+	scanDurationGauge.Set(time.Since(start).Seconds())
+	lastScanGauge.Set(float64(time.Now().Unix()))
+	if err := FlushTelemetry(); err != nil {
+		slog.Error("Failed to export telemetry", "error", err)
+	}
+	return nil
+}
 
-			for namespace, resources := range data {
-				for kind, resourceList := range resources {
-					for _, resourceName := range resourceList {
-						orphanedResourcesCounter.WithLabelValues(kind, namespace, resourceName).Set(1)
-					}
-				}
-			}
-			time.Sleep(time.Duration(exporterIntervalValue) * time.Minute)
-		}
+// updateMetrics replaces the metrics of the previous scan with the findings.
+func updateMetrics(findings []unusedResource) {
+	orphanedResourcesCounter.Reset()
+	unusedResourcesGauge.Reset()
+	for _, finding := range findings {
+		orphanedResourcesCounter.WithLabelValues(finding.Kind, finding.Namespace, finding.Name).Set(1)
+		unusedResourcesGauge.WithLabelValues(finding.Kind, finding.Namespace).Inc()
 	}
 }
 
-func getUnusedResources(filterOptions *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts, resourceList []string) (string, error) {
+func getUnusedResources(ctx context.Context, filterOptions *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts, resourceList []string) (string, error) {
 	if len(resourceList) == 0 || (len(resourceList) == 1 && resourceList[0] == "all") {
-		return GetUnusedAll(filterOptions, clientset, apiExtClient, dynamicClient, outputFormat, opts)
+		return GetUnusedAll(ctx, filterOptions, clientset, apiExtClient, dynamicClient, outputFormat, opts)
 	}
-	return GetUnusedMulti(strings.Join(resourceList, ","), filterOptions, clientset, apiExtClient, dynamicClient, outputFormat, opts)
+	return GetUnusedMulti(ctx, strings.Join(resourceList, ","), filterOptions, clientset, apiExtClient, dynamicClient, outputFormat, opts)
 
 }
@@ -0,0 +1,92 @@
+package kor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func TestUpdateMetrics(t *testing.T) {
+	updateMetrics([]unusedResource{
+		{Namespace: "default", Kind: "ConfigMap", Name: "cm-1"},
+		{Namespace: "default", Kind: "ConfigMap", Name: "cm-2"},
+		{Namespace: "", Kind: "Pv", Name: "pv-1"},
+	})
+	expected := `
+# HELP kor_unused_resources Number of unused resources found by the last scan
+# TYPE kor_unused_resources gauge
+kor_unused_resources{kind="ConfigMap",namespace="default"} 2
+kor_unused_resources{kind="Pv",namespace=""} 1
+`
+	if err := testutil.CollectAndCompare(unusedResourcesGauge, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+	if got := testutil.CollectAndCount(orphanedResourcesCounter); got != 3 {
+		t.Errorf("Expected 3 orphaned resources, got %d", got)
+	}
+
+	// Resources that are no longer unused are dropped
+	updateMetrics([]unusedResource{{Namespace: "default", Kind: "ConfigMap", Name: "cm-1"}})
+	if got := testutil.ToFloat64(unusedResourcesGauge.WithLabelValues("ConfigMap", "default")); got != 1 {
+		t.Errorf("Expected 1 unused ConfigMap, got %v", got)
+	}
+	if got := testutil.CollectAndCount(unusedResourcesGauge); got != 1 {
+		t.Errorf("Expected a single series, got %d", got)
+	}
+}
+
+func TestScanMetrics(t *testing.T) {
+	defer resetReportedResources()
+	clientset := fake.NewClientset()
+	if _, err := clientset.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: testNamespace}}, v1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), CreateTestConfigmap(testNamespace, "configmap-1", AppLabels), v1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reasons change the JSON report, the metrics must not depend on it
+	opts := common.Opts{GroupBy: "namespace", ShowReason: true}
+	for i := 0; i < 2; i++ {
+		if err := scanMetrics(context.Background(), &filters.Options{}, clientset, nil, nil, "json", opts, []string{"cm"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if got := testutil.ToFloat64(unusedResourcesGauge.WithLabelValues("ConfigMap", testNamespace)); got != 1 {
+		t.Errorf("Expected 1 unused ConfigMap after two scans, got %v", got)
+	}
+	if testutil.ToFloat64(lastScanGauge) == 0 {
+		t.Error("Expected the time of the last scan")
+	}
+}
+
+func TestExporterInterval(t *testing.T) {
+	t.Setenv("EXPORTER_INTERVAL", "")
+	for _, tc := range []struct {
+		flag     time.Duration
+		env      string
+		expected time.Duration
+		err      bool
+	}{
+		{flag: time.Minute, env: "5", expected: time.Minute},
+		{env: "5", expected: 5 * time.Minute},
+		{expected: defaultExporterInterval},
+		{env: "5m", err: true},
+		{flag: -time.Minute, err: true},
+	} {
+		t.Setenv("EXPORTER_INTERVAL", tc.env)
+		got, err := exporterInterval(tc.flag)
+		if (err != nil) != tc.err || got != tc.expected {
+			t.Errorf("exporterInterval(%s) with EXPORTER_INTERVAL=%q: expected %s (error %v), got %s, %v", tc.flag, tc.env, tc.expected, tc.err, got, err)
+		}
+	}
+}
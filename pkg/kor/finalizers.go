@@ -5,7 +5,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
+	"log/slog"
+	"sort"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -25,7 +26,7 @@ func CheckFinalizers(finalizers []string, deletionTimestamp *metav1.Time) bool {
 	return false
 }
 
-func retrievePendingDeletionResources(resourceTypes []*metav1.APIResourceList, dynamicClient dynamic.Interface, filterOpts *filters.Options) (map[string]map[schema.GroupVersionResource][]ResourceInfo, error) {
+func retrievePendingDeletionResources(ctx context.Context, resourceTypes []*metav1.APIResourceList, dynamicClient dynamic.Interface, filterOpts *filters.Options) (map[string]map[schema.GroupVersionResource][]ResourceInfo, error) {
 	pendingDeletionResources := make(map[string]map[schema.GroupVersionResource][]ResourceInfo) //map[namespace]map[gvr][]resourceNames
 
 	for _, apiResourceList := range resourceTypes {
@@ -42,7 +43,7 @@ func retrievePendingDeletionResources(resourceTypes []*metav1.APIResourceList, d
 				resourceList, err := dynamicClient.
 					Resource(gvr).
 					Namespace(metav1.NamespaceAll).
-					List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+					List(ctx, filterOpts.ListOptions())
 				if err != nil {
 					fmt.Printf("Error listing resources for GVR %s: %v\n", apiResourceList.GroupVersion, err)
 					continue
@@ -68,44 +69,53 @@ func retrievePendingDeletionResources(resourceTypes []*metav1.APIResourceList, d
 	return pendingDeletionResources, nil
 }
 
-func getResourcesWithFinalizersPendingDeletion(clientset kubernetes.Interface, dynamicClient dynamic.Interface, filterOpts *filters.Options) (map[string]map[schema.GroupVersionResource][]ResourceInfo, error) {
+func getResourcesWithFinalizersPendingDeletion(ctx context.Context, clientset kubernetes.Interface, dynamicClient dynamic.Interface, filterOpts *filters.Options) (map[string]map[schema.GroupVersionResource][]ResourceInfo, error) {
 	// Use the discovery client to fetch API resources
 	resourceTypes, err := clientset.Discovery().ServerPreferredNamespacedResources()
 	if err != nil {
-		fmt.Printf("Error fetching server resources: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to discover the server resources: %w", err)
 	}
 
-	return retrievePendingDeletionResources(resourceTypes, dynamicClient, filterOpts)
+	return retrievePendingDeletionResources(ctx, resourceTypes, dynamicClient, filterOpts)
 }
 
-func GetUnusedfinalizers(filterOpts *filters.Options, clientset kubernetes.Interface, dynamicClient *dynamic.DynamicClient, outputFormat string, opts common.Opts) (string, error) {
+func GetUnusedfinalizers(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
 	var outputBuffer bytes.Buffer
-	namespaces := filterOpts.Namespaces(clientset)
+	namespaces := filterOpts.Namespaces(ctx, clientset)
 	response := make(map[string]map[string][]ResourceInfo)
-	pendingDeletionDiffs, err := getResourcesWithFinalizersPendingDeletion(clientset, dynamicClient, filterOpts)
+	pendingDeletionDiffs, err := getResourcesWithFinalizersPendingDeletion(ctx, clientset, dynamicClient, filterOpts)
 
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to process resources waiting for finalizers: %v\n", err)
+		recordScanError("Finalizer", "", err)
 	}
 
-	allDiffs := make(map[string][]ResourceInfo)
-
 	for namespace, resourceType := range pendingDeletionDiffs {
 		if slices.Contains(namespaces, namespace) {
+			allDiffs := make(map[string][]ResourceInfo)
 			for gvr, resourceDiff := range resourceType {
-				if opts.DeleteFlag {
-					if resourceDiff, err = DeleteResourceWithFinalizer(resourceDiff, dynamicClient, namespace, gvr, opts.NoInteractive); err != nil {
-						fmt.Fprintf(os.Stderr, "Failed to delete objects waiting for Finalizers %s in namespace %s: %v\n", resourceDiff, namespace, err)
+				if deleteEnabled(opts, gvr.Resource) {
+					if resourceDiff, err = DeleteResourceWithFinalizer(ctx, resourceDiff, dynamicClient, namespace, gvr, opts.NoInteractive); err != nil {
+						slog.Error("Failed to delete objects waiting for finalizers", "name", resourceDiff, "namespace", namespace, "error", err)
 					}
 				}
 				allDiffs[gvr.Resource] = resourceDiff
 			}
+			response[namespace] = allDiffs
+		}
+	}
 
-			output := formatOutputForNamespace(namespace, allDiffs, opts)
-			outputBuffer.WriteString(output)
+	recordUnusedResources(response, "namespace")
 
-			response[namespace] = allDiffs
+	if opts.Quiet {
+		outputBuffer = formatQuiet(response, "namespace")
+	} else {
+		sorted := make([]string, 0, len(response))
+		for namespace := range response {
+			sorted = append(sorted, namespace)
+		}
+		sort.Strings(sorted)
+		for _, namespace := range sorted {
+			outputBuffer.WriteString(formatOutputForNamespace(namespace, response[namespace], opts))
 		}
 	}
 
@@ -1,15 +1,20 @@
 package kor
 
 import (
+	"context"
+	"strings"
 	"testing"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/utils/strings/slices"
 
+	"github.com/yonahd/kor/pkg/common"
 	"github.com/yonahd/kor/pkg/filters"
 )
 
@@ -87,7 +92,7 @@ func TestRetrievePendingDeletionResources(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			result, err := retrievePendingDeletionResources(test.apiResourceLists, dynamicClient, &filters.Options{})
+			result, err := retrievePendingDeletionResources(context.Background(), test.apiResourceLists, dynamicClient, &filters.Options{})
 			if (err != nil) != test.expectedError {
 				t.Errorf("Expected error: %v, Got: %v", test.expectedError, err)
 			}
@@ -101,6 +106,20 @@ func TestRetrievePendingDeletionResources(t *testing.T) {
 	}
 }
 
+func TestGetUnusedfinalizersWithFakeClients(t *testing.T) {
+	defer resetReportedResources()
+	clientset := fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: testNamespace}})
+	dynamicClient := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())
+
+	output, err := GetUnusedfinalizers(context.Background(), &filters.Options{}, clientset, dynamicClient, "json", common.Opts{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if strings.TrimSpace(output) != "{}" {
+		t.Errorf("Expected no resources pending deletion, got %s", output)
+	}
+}
+
 func extractNames(resources []ResourceInfo) []string {
 	names := make([]string, len(resources))
 	for i, resource := range resources {
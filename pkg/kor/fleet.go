@@ -0,0 +1,103 @@
+package kor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// ClustersFile is the layout of the file passed with --clusters.
+type ClustersFile struct {
+	Clusters []Cluster `json:"clusters"`
+}
+
+// Cluster is a cluster of a scan across several clusters, the Context of
+// Kubeconfig. The default kubeconfig and its current context are used when
+// they are empty. The exclusions are added to the filters of the scan for
+// this cluster only, and ExceptionsFile replaces --exceptions.
+type Cluster struct {
+	// Name is the cluster of the resources in the report
+	Name              string   `json:"name"`
+	Kubeconfig        string   `json:"kubeconfig,omitempty"`
+	Context           string   `json:"context,omitempty"`
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
+	ExcludeLabels     []string `json:"excludeLabels,omitempty"`
+	ExcludeNames      []string `json:"excludeNames,omitempty"`
+	ExceptionsFile    string   `json:"exceptionsFile,omitempty"`
+}
+
+// LoadClusters reads the clusters of the clusters file at path. The relative
+// kubeconfig and exceptions paths are relative to the directory of the file.
+func LoadClusters(path string) ([]Cluster, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read clusters file: %w", err)
+	}
+	var file ClustersFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse clusters file %s: %w", path, err)
+	}
+	if len(file.Clusters) == 0 {
+		return nil, fmt.Errorf("clusters file %s has no clusters", path)
+	}
+
+	names := make(map[string]bool, len(file.Clusters))
+	for i := range file.Clusters {
+		cluster := &file.Clusters[i]
+		if cluster.Name == "" {
+			return nil, fmt.Errorf("invalid cluster %d in %s, the name is required", i+1, path)
+		}
+		if names[cluster.Name] {
+			return nil, fmt.Errorf("invalid cluster %d in %s, %s is already listed", i+1, path, cluster.Name)
+		}
+		names[cluster.Name] = true
+		cluster.Kubeconfig = clustersFilePath(path, cluster.Kubeconfig)
+		cluster.ExceptionsFile = clustersFilePath(path, cluster.ExceptionsFile)
+		if err := cluster.filterOptions(&filters.Options{}).Validate(); err != nil {
+			return nil, fmt.Errorf("invalid cluster %s in %s: %w", cluster.Name, path, err)
+		}
+	}
+	return file.Clusters, nil
+}
+
+// ContextClusters returns the clusters of the contexts of kubeconfig, named
+// after their context.
+func ContextClusters(kubeconfig string, contexts []string) []Cluster {
+	clusters := make([]Cluster, 0, len(contexts))
+	for _, context := range contexts {
+		clusters = append(clusters, Cluster{Name: context, Kubeconfig: kubeconfig, Context: context})
+	}
+	return clusters
+}
+
+// clustersFilePath resolves path, given in the clusters file at file.
+func clustersFilePath(file, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return filepath.Join(filepath.Dir(file), path)
+}
+
+// filterOptions returns a copy of filterOpts with the exclusions of the
+// cluster, looking up the namespaces of the cluster.
+func (c Cluster) filterOptions(filterOpts *filters.Options) *filters.Options {
+	clusterOpts := filterOpts.Clone()
+	clusterOpts.ExcludeNamespaces = append(append([]string{}, clusterOpts.ExcludeNamespaces...), c.ExcludeNamespaces...)
+	clusterOpts.ExcludeLabels = append(append([]string{}, clusterOpts.ExcludeLabels...), c.ExcludeLabels...)
+	clusterOpts.ExcludeNames = append(append([]string{}, clusterOpts.ExcludeNames...), c.ExcludeNames...)
+	if c.ExceptionsFile != "" {
+		clusterOpts.ExceptionsFile = c.ExceptionsFile
+	}
+	clusterOpts.Modify()
+	return clusterOpts
+}
@@ -0,0 +1,68 @@
+package kor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func TestLoadClusters(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "clusters.yaml")
+	if err := os.WriteFile(path, []byte(`clusters:
+- name: staging
+  kubeconfig: configs/staging.yaml
+  excludeNamespaces: [kube-system]
+  excludeNames: ["tmp-.*"]
+  exceptionsFile: exceptions.yaml
+- name: production
+  kubeconfig: /etc/kor/production.yaml
+  context: prod
+`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "exceptions.yaml"), []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	clusters, err := LoadClusters(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Cluster{
+		{Name: "staging", Kubeconfig: filepath.Join(dir, "configs", "staging.yaml"), ExcludeNamespaces: []string{"kube-system"}, ExcludeNames: []string{"tmp-.*"}, ExceptionsFile: filepath.Join(dir, "exceptions.yaml")},
+		{Name: "production", Kubeconfig: "/etc/kor/production.yaml", Context: "prod"},
+	}
+	if !reflect.DeepEqual(clusters, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, clusters)
+	}
+
+	filterOpts := clusters[0].filterOptions(&filters.Options{ExcludeNamespaces: []string{"default"}})
+	if !reflect.DeepEqual(filterOpts.ExcludeNamespaces, []string{"default", "kube-system"}) || filterOpts.ExceptionsFile != expected[0].ExceptionsFile {
+		t.Errorf("Expected the exclusions of the cluster on top of the scan, got %v and %q", filterOpts.ExcludeNamespaces, filterOpts.ExceptionsFile)
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"empty", "clusters: []", "has no clusters"},
+		{"unknown field", "clusters:\n- name: staging\n  namespace: default", "failed to parse clusters file"},
+		{"missing name", "clusters:\n- context: staging", "invalid cluster 1"},
+		{"duplicate name", "clusters:\n- name: staging\n- name: staging", "staging is already listed"},
+		{"invalid names", "clusters:\n- name: staging\n  excludeNames: [\"tmp-(\"]", "invalid cluster staging"},
+	}
+	for _, test := range tests {
+		if err := os.WriteFile(path, []byte(test.content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadClusters(path); err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%s: expected an error containing %q, got %v", test.name, test.expected, err)
+		}
+	}
+}
@@ -4,18 +4,184 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/fatih/color"
 	"github.com/olekukonko/tablewriter"
-	"sigs.k8s.io/yaml"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/yonahd/kor/pkg/common"
-	"github.com/yonahd/kor/pkg/utils"
 )
 
 type ResourceInfo struct {
-	Name   string `json:"name"`
-	Reason string `json:"reason,omitempty"`
+	Name              string             `json:"name"`
+	Reason            string             `json:"reason,omitempty"`
+	CreationTimestamp *metav1.Time       `json:"creationTimestamp,omitempty"`
+	Size              *resource.Quantity `json:"size,omitempty"`
+	Owners            string             `json:"owners,omitempty"`
+	ManagedBy         string             `json:"managedBy,omitempty"`
+	HelmRelease       string             `json:"helmRelease,omitempty"`
+	// MonthlyCost is the estimated cost of the resource per month, with --show-cost
+	MonthlyCost *Cost `json:"monthlyCost,omitempty"`
+}
+
+var (
+	// reportedResources holds every unused resource reported during this run.
+	reportedResources []unusedResource
+	// reportedInfo holds the same resources with their details, by namespace and kind.
+	reportedInfo = make(map[string]map[string][]ResourceInfo)
+	// onRecord is called with the resources of each report, by namespace and
+	// kind, as soon as they are recorded.
+	onRecord func(map[string]map[string][]ResourceInfo)
+)
+
+// UnusedResourceCount returns the number of unused resources reported so far.
+func UnusedResourceCount() int {
+	return len(reportedResources)
+}
+
+func recordUnusedResources(resources map[string]map[string][]ResourceInfo, groupBy string) {
+	recordClusterUnusedResources("", resources, groupBy)
+}
+
+// recordClusterUnusedResources is recordUnusedResources for the resources of
+// cluster, in a scan across several clusters.
+func recordClusterUnusedResources(cluster string, resources map[string]map[string][]ResourceInfo, groupBy string) {
+	historyFindings = append(historyFindings, clusterFindings(cluster, resources, groupBy)...)
+	if unusedFor > 0 {
+		holdBackRecentlyUnused(cluster, resources, groupBy, time.Now())
+	}
+	findings := clusterFindings(cluster, resources, groupBy)
+	reportedResources = append(reportedResources, findings...)
+	recorded := make(map[string]map[string][]ResourceInfo)
+	for outerKey, inner := range resources {
+		for innerKey, infos := range inner {
+			namespace, kind := outerKey, innerKey
+			if groupBy == "resource" {
+				namespace, kind = innerKey, outerKey
+			}
+			if len(infos) == 0 {
+				continue
+			}
+			if reportedInfo[namespace] == nil {
+				reportedInfo[namespace] = make(map[string][]ResourceInfo)
+			}
+			reportedInfo[namespace][kind] = append(reportedInfo[namespace][kind], infos...)
+			if recorded[namespace] == nil {
+				recorded[namespace] = make(map[string][]ResourceInfo)
+			}
+			recorded[namespace][kind] = append(recorded[namespace][kind], infos...)
+		}
+	}
+	if onRecord != nil && len(recorded) > 0 {
+		onRecord(recorded)
+	}
+	progress.setFindings(len(reportedResources))
+}
+
+func clusterFindings(cluster string, resources map[string]map[string][]ResourceInfo, groupBy string) []unusedResource {
+	findings := flattenResources(resources, groupBy)
+	for i := range findings {
+		findings[i].Cluster = cluster
+	}
+	return findings
+}
+
+// resetReportedResources forgets the resources reported so far.
+func resetReportedResources() {
+	reportedResources = nil
+	historyFindings = nil
+	resetUnusedSince()
+	reportedInfo = make(map[string]map[string][]ResourceInfo)
+	resetScanErrors()
+	resetScannedNamespaces()
+}
+
+// unusedResource is a single finding, independent of how the report is grouped.
+// Cluster is only set by the scans across several clusters.
+type unusedResource struct {
+	Cluster   string
+	Namespace string
+	Kind      string
+	Name      string
+	Reason    string
+}
+
+// flattenResources turns a report grouped by namespace or by resource kind
+// into a list of findings sorted by kind, namespace and name.
+func flattenResources(resources map[string]map[string][]ResourceInfo, groupBy string) []unusedResource {
+	var findings []unusedResource
+	for outerKey, inner := range resources {
+		for innerKey, infos := range inner {
+			namespace, kind := outerKey, innerKey
+			if groupBy == "resource" {
+				namespace, kind = innerKey, outerKey
+			}
+			for _, info := range infos {
+				findings = append(findings, unusedResource{Namespace: namespace, Kind: kind, Name: info.Name, Reason: info.Reason})
+			}
+		}
+	}
+
+	sort.Slice(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return findings
+}
+
+// quietKinds maps report kinds that are not Kubernetes kinds to the kind kubectl
+// should act on.
+var quietKinds = map[string]string{
+	"ServiceAccountToken": "secret",
+	"GeneratedSecret":     "secret",
+	"HelmReleaseSecret":   "secret",
+}
+
+// formatQuiet prints one namespace/kind/name line per finding, dropping the
+// namespace for cluster-scoped resources, so the output can be piped into kubectl.
+func formatQuiet(resources map[string]map[string][]ResourceInfo, groupBy string) bytes.Buffer {
+	var output bytes.Buffer
+	for _, finding := range flattenResources(resources, groupBy) {
+		kind, ok := quietKinds[finding.Kind]
+		if !ok {
+			kind = strings.ToLower(finding.Kind)
+		}
+		if finding.Namespace != "" {
+			fmt.Fprintf(&output, "%s/%s/%s\n", finding.Namespace, kind, finding.Name)
+		} else {
+			fmt.Fprintf(&output, "%s/%s\n", kind, finding.Name)
+		}
+	}
+	return output
+}
+
+// oldResourceAge is the age from which unused resources are highlighted in red
+// rather than yellow.
+const oldResourceAge = 30 * 24 * time.Hour
+
+var (
+	oldResourceColor    = color.New(color.FgRed)
+	unusedResourceColor = color.New(color.FgYellow)
+)
+
+// colorizeName highlights the name of an unused resource, in red when it is
+// known to be older than oldResourceAge. Colors are disabled by --no-color and
+// when stdout is not a terminal.
+func colorizeName(info ResourceInfo) string {
+	if info.CreationTimestamp != nil && !info.CreationTimestamp.IsZero() && time.Since(info.CreationTimestamp.Time) > oldResourceAge {
+		return oldResourceColor.Sprint(info.Name)
+	}
+	return unusedResourceColor.Sprint(info.Name)
 }
 
 func getTableRow(index int, columns ...string) []string {
@@ -26,96 +192,268 @@ func getTableRow(index int, columns ...string) []string {
 }
 
 func unusedResourceFormatter(outputFormat string, outputBuffer bytes.Buffer, opts common.Opts, jsonResponse []byte) (string, error) {
+	if kind, expression, ok := parseCustomOutput(outputFormat); ok {
+		return formatCustomOutput(kind, expression, jsonResponse)
+	}
+
+	// The callers render the table as they scan
+	if outputFormat == "table" {
+		return outputBuffer.String(), nil
+	}
+	formatter := lookupFormatter(outputFormat)
+	if formatter == nil {
+		return "", fmt.Errorf("unsupported output format: %s", outputFormat)
+	}
+	var resources map[string]map[string][]ResourceInfo
+	if err := json.Unmarshal(jsonResponse, &resources); err != nil {
+		return "", err
+	}
+	var output bytes.Buffer
+	if err := formatter.Format(&output, reportResources(resources, opts.GroupBy), opts); err != nil {
+		return "", err
+	}
+	return output.String(), nil
+}
+
+// formatUnusedResources renders a report in the requested output format.
+func formatUnusedResources(resources map[string]map[string][]ResourceInfo, outputFormat string, opts common.Opts) (string, error) {
+	recordUnusedResources(resources, opts.GroupBy)
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
 	switch outputFormat {
 	case "table":
-		if opts.WebhookURL == "" || opts.Channel == "" || opts.Token != "" {
-			return outputBuffer.String(), nil
+		outputBuffer = FormatOutput(resources, opts)
+	default:
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
 		}
-		if err := utils.SendToSlack(utils.SlackMessage{}, opts, outputBuffer.String()); err != nil {
-			return "", fmt.Errorf("failed to send message to slack: %w", err)
+	}
+
+	unusedResources, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return unusedResources, nil
+}
+
+func FormatOutput(resources map[string]map[string][]ResourceInfo, opts common.Opts) bytes.Buffer {
+	if opts.Quiet {
+		return formatQuiet(resources, opts.GroupBy)
+	}
+	var output bytes.Buffer
+	switch opts.GroupBy {
+	case "namespace":
+		for _, namespace := range sortedKeys(resources) {
+			output.WriteString(formatOutputForNamespace(namespace, resources[namespace], opts))
 		}
-	case "json", "yaml":
-		var resources map[string]map[string][]ResourceInfo
-		if err := json.Unmarshal(jsonResponse, &resources); err != nil {
-			return "", err
+	case "resource":
+		for _, resource := range sortedKeys(resources) {
+			output.WriteString(formatOutputForResource(resource, resources[resource], opts))
 		}
+	}
+	if opts.ShowSummary {
+		output.WriteString(formatSummary(resources, opts.GroupBy))
+	}
+	if opts.ShowHelmSummary {
+		output.WriteString(formatHelmSummary(resources, opts.GroupBy))
+	}
+	if opts.ShowCost {
+		output.WriteString(formatCostSummary(resources, opts.GroupBy))
+	}
+	return output
+}
 
-		if !opts.ShowReason {
-			// Create a map of namespaces with their corresponding maps of resource types and lists of resource names
-			namespaces := make(map[string]map[string][]string)
-			for namespace, resourceMap := range resources {
-				for resourceType, infoSlice := range resourceMap {
-					for _, info := range infoSlice {
-						if _, ok := namespaces[namespace]; !ok {
-							namespaces[namespace] = make(map[string][]string)
-						}
-						namespaces[namespace][resourceType] = append(namespaces[namespace][resourceType], info.Name)
-					}
+// formatClusterOutput renders the resources found across several clusters
+// like FormatOutput, with the cluster of each resource in a CLUSTER column.
+// Quiet output prefixes each line with the cluster.
+func formatClusterOutput(clusters map[string][]Resource, opts common.Opts) bytes.Buffer {
+	grouped := make(map[string]map[string]map[string][]ResourceInfo, len(clusters))
+	keys := make(map[string]bool)
+	var resources []Resource
+	for cluster, clusterResources := range clusters {
+		grouped[cluster] = groupResources(clusterResources, opts.GroupBy)
+		for key := range grouped[cluster] {
+			keys[key] = true
+		}
+		resources = append(resources, clusterResources...)
+	}
+
+	var output bytes.Buffer
+	if opts.Quiet {
+		for _, cluster := range sortedKeys(grouped) {
+			quiet := formatQuiet(grouped[cluster], opts.GroupBy)
+			for _, line := range strings.SplitAfter(quiet.String(), "\n") {
+				if line != "" {
+					output.WriteString(cluster + " " + line)
 				}
 			}
-			// Marshal the map to JSON format
-			modifiedJSONResponse, err := json.MarshalIndent(namespaces, "", "  ")
-			if err != nil {
-				return "", err
-			}
-			if outputFormat == "yaml" {
-				modifiedJSONResponse, err = yaml.JSONToYAML(modifiedJSONResponse)
-				if err != nil {
-					return "", err
-				}
+		}
+		return output
+	}
+	header := getTableHeader(opts)
+	for _, key := range sortedKeys(keys) {
+		var buf strings.Builder
+		table := tablewriter.NewWriter(&buf)
+		table.SetColWidth(60)
+		table.SetHeader(append([]string{header[0], "CLUSTER"}, header[1:]...))
+		var index int
+		for _, cluster := range sortedKeys(grouped) {
+			for _, entry := range getTableEntries(grouped[cluster][key], opts.SortBy) {
+				row := getTableRowResourceInfo(index, entry.column, entry.info, opts)
+				table.Append(append([]string{row[0], cluster}, row[1:]...))
+				index++
 			}
-			return string(modifiedJSONResponse), nil
 		}
+		if index == 0 {
+			continue
+		}
+		table.Render()
+		if opts.GroupBy == "resource" {
+			fmt.Fprintf(&output, "Unused %ss:\n%s\n", key, buf.String())
+		} else {
+			fmt.Fprintf(&output, "Unused resources in namespace: %q\n%s\n", key, buf.String())
+		}
+	}
+	if opts.ShowSummary {
+		output.WriteString(formatClusterSummary(grouped, opts.GroupBy))
+	}
+	if opts.ShowHelmSummary {
+		output.WriteString(formatHelmSummary(groupResources(resources, opts.GroupBy), opts.GroupBy))
+	}
+	if opts.ShowCost {
+		output.WriteString(formatCostSummary(groupResources(resources, opts.GroupBy), opts.GroupBy))
+	}
+	return output
+}
 
-		modifiedJSONResponse, err := json.MarshalIndent(resources, "", "  ")
-		if err != nil {
-			return "", err
+// formatClusterSummary renders the number of unused resources per cluster,
+// kind and namespace, followed by the overall total.
+func formatClusterSummary(clusters map[string]map[string]map[string][]ResourceInfo, groupBy string) string {
+	var buf strings.Builder
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"#", "CLUSTER", "RESOURCE TYPE", "NAMESPACE", "COUNT"})
+	var index, total int
+	for _, cluster := range sortedKeys(clusters) {
+		type summaryKey struct {
+			kind      string
+			namespace string
 		}
-		if outputFormat == "yaml" {
-			modifiedJSONResponse, err = yaml.JSONToYAML(modifiedJSONResponse)
-			if err != nil {
-				return "", err
+		counts := make(map[summaryKey]int)
+		var keys []summaryKey
+		findings := flattenResources(clusters[cluster], groupBy)
+		for _, finding := range findings {
+			key := summaryKey{kind: finding.Kind, namespace: finding.Namespace}
+			if _, ok := counts[key]; !ok {
+				keys = append(keys, key)
 			}
+			counts[key]++
 		}
-		return string(modifiedJSONResponse), nil
-	default:
-		return "", fmt.Errorf("unsupported output format: %s", outputFormat)
+		for _, key := range keys {
+			table.Append(getTableRow(index, cluster, key.kind, key.namespace, fmt.Sprintf("%d", counts[key])))
+			index++
+		}
+		total += len(findings)
 	}
-	return "", fmt.Errorf("unsupported output format: %s", outputFormat)
+	table.SetFooter([]string{"", "", "", "Total", fmt.Sprintf("%d", total)})
+	table.Render()
+	return fmt.Sprintf("Summary:\n%s\n", buf.String())
 }
 
-func FormatOutput(resources map[string]map[string][]ResourceInfo, opts common.Opts) bytes.Buffer {
-	var output bytes.Buffer
-	switch opts.GroupBy {
-	case "namespace":
-		for namespace, diffs := range resources {
-			output.WriteString(formatOutputForNamespace(namespace, diffs, opts))
+// formatSummary renders the number of unused resources per kind and namespace,
+// followed by the overall total.
+func formatSummary(resources map[string]map[string][]ResourceInfo, groupBy string) string {
+	type summaryKey struct {
+		kind      string
+		namespace string
+	}
+	counts := make(map[summaryKey]int)
+	var keys []summaryKey
+	findings := flattenResources(resources, groupBy)
+	for _, finding := range findings {
+		key := summaryKey{kind: finding.Kind, namespace: finding.Namespace}
+		if _, ok := counts[key]; !ok {
+			keys = append(keys, key)
 		}
-	case "resource":
-		for resource, diffs := range resources {
-			output.WriteString(formatOutputForResource(resource, diffs, opts))
+		counts[key]++
+	}
+
+	var buf strings.Builder
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"#", "RESOURCE TYPE", "NAMESPACE", "COUNT"})
+	for index, key := range keys {
+		table.Append(getTableRow(index, key.kind, key.namespace, fmt.Sprintf("%d", counts[key])))
+	}
+	table.SetFooter([]string{"", "", "Total", fmt.Sprintf("%d", len(findings))})
+	table.Render()
+	return fmt.Sprintf("Summary:\n%s\n", buf.String())
+}
+
+// formatHelmSummary renders the number of unused resources per Helm release
+// and namespace, the resources of no release are counted under <none>.
+func formatHelmSummary(resources map[string]map[string][]ResourceInfo, groupBy string) string {
+	type releaseKey struct {
+		release   string
+		namespace string
+	}
+	counts := make(map[releaseKey]int)
+	var total int
+	for outerKey, inner := range resources {
+		for innerKey, infos := range inner {
+			namespace := outerKey
+			if groupBy == "resource" {
+				namespace = innerKey
+			}
+			for _, info := range infos {
+				counts[releaseKey{release: info.HelmRelease, namespace: namespace}]++
+				total++
+			}
 		}
 	}
-	return output
+	keys := make([]releaseKey, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		// The resources of no release come last
+		if (keys[i].release == "") != (keys[j].release == "") {
+			return keys[j].release == ""
+		}
+		if keys[i].release != keys[j].release {
+			return keys[i].release < keys[j].release
+		}
+		return keys[i].namespace < keys[j].namespace
+	})
+
+	var buf strings.Builder
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"#", "HELM RELEASE", "NAMESPACE", "COUNT"})
+	for index, key := range keys {
+		table.Append(getTableRow(index, valueOrNone(key.release), key.namespace, fmt.Sprintf("%d", counts[key])))
+	}
+	table.SetFooter([]string{"", "", "Total", fmt.Sprintf("%d", total)})
+	table.Render()
+	return fmt.Sprintf("Helm release summary:\n%s\n", buf.String())
 }
 
 func formatOutputForNamespace(namespace string, resources map[string][]ResourceInfo, opts common.Opts) string {
 	var buf strings.Builder
 	table := tablewriter.NewWriter(&buf)
 	table.SetColWidth(60)
-	table.SetHeader(getTableHeader(opts.GroupBy, opts.ShowReason))
+	table.SetHeader(getTableHeader(opts))
 	allEmpty := true
 	var index int
-	for resourceType, diff := range resources {
-		for _, info := range diff {
-			row := getTableRow(index, resourceType, info.Name)
-			if opts.ShowReason && info.Reason != "" {
-				row = append(row, info.Reason)
-			}
-			table.Append(row)
-			allEmpty = false
-			index++
+	for _, entry := range getTableEntries(resources, opts.SortBy) {
+		row := getTableRow(index, entry.column, colorizeName(entry.info))
+		row = append(row, getResourceInfoColumns(entry.info, opts)...)
+		if opts.ShowReason && entry.info.Reason != "" {
+			row = append(row, entry.info.Reason)
 		}
+		table.Append(row)
+		allEmpty = false
+		index++
 	}
 	if allEmpty {
 		if opts.Verbose {
@@ -138,17 +476,16 @@ func formatOutputForResource(resource string, resources map[string][]ResourceInf
 	var buf bytes.Buffer
 	table := tablewriter.NewWriter(&buf)
 	table.SetColWidth(60)
-	table.SetHeader(getTableHeader(opts.GroupBy, opts.ShowReason))
+	table.SetHeader(getTableHeader(opts))
 	var index int
-	for ns, infos := range resources {
-		for _, info := range infos {
-			row := getTableRow(index, ns, info.Name)
-			if opts.ShowReason && info.Reason != "" {
-				row = append(row, info.Reason)
-			}
-			table.Append(row)
-			index++
+	for _, entry := range getTableEntries(resources, opts.SortBy) {
+		row := getTableRow(index, entry.column, colorizeName(entry.info))
+		row = append(row, getResourceInfoColumns(entry.info, opts)...)
+		if opts.ShowReason && entry.info.Reason != "" {
+			row = append(row, entry.info.Reason)
 		}
+		table.Append(row)
+		index++
 	}
 	table.Render()
 	return fmt.Sprintf("Unused %ss:\n%s\n", resource, buf.String())
@@ -163,32 +500,17 @@ func appendResources(resources map[string]map[string][]ResourceInfo, resourceTyp
 	}
 }
 
-func getTableHeader(groupBy string, showReason bool) []string {
-	switch groupBy {
+func getTableHeader(opts common.Opts) []string {
+	var header []string
+	switch opts.GroupBy {
 	case "namespace":
-		if showReason {
-			return []string{
-				"#",
-				"RESOURCE TYPE",
-				"RESOURCE NAME",
-				"REASON",
-			}
-		}
-		return []string{
+		header = []string{
 			"#",
 			"RESOURCE TYPE",
 			"RESOURCE NAME",
 		}
 	case "resource":
-		if showReason {
-			return []string{
-				"#",
-				"NAMESPACE",
-				"RESOURCE NAME",
-				"REASON",
-			}
-		}
-		return []string{
+		header = []string{
 			"#",
 			"NAMESPACE",
 			"RESOURCE NAME",
@@ -196,16 +518,29 @@ func getTableHeader(groupBy string, showReason bool) []string {
 	default:
 		return nil
 	}
+	if opts.ShowAge {
+		header = append(header, "AGE")
+	}
+	if opts.ShowSize {
+		header = append(header, "SIZE")
+	}
+	if opts.ShowCost {
+		header = append(header, costHeader("MONTHLY COST"))
+	}
+	if opts.Wide {
+		header = append(header, "OWNERS", "MANAGED BY", "HELM RELEASE")
+	}
+	if opts.ShowReason {
+		header = append(header, "REASON")
+	}
+	return header
 }
 
-func getTableRowResourceInfo(index int, resourceType string, resource ResourceInfo, ShowReason bool) []string {
-	row := []string{
-		fmt.Sprintf("%d", index+1),
-		resourceType,
-		resource.Name,
-	}
-	if ShowReason && resource.Reason != "" {
-		row = append(row, resource.Reason)
+func getTableRowResourceInfo(index int, resourceType string, info ResourceInfo, opts common.Opts) []string {
+	row := getTableRow(index, resourceType, colorizeName(info))
+	row = append(row, getResourceInfoColumns(info, opts)...)
+	if opts.ShowReason && info.Reason != "" {
+		row = append(row, info.Reason)
 	}
 	return row
 }
@@ -214,12 +549,12 @@ func FormatOutputAll(namespace string, allDiffs []ResourceDiff, opts common.Opts
 	var buf strings.Builder
 	table := tablewriter.NewWriter(&buf)
 	table.SetColWidth(60)
-	table.SetHeader(getTableHeader(opts.GroupBy, opts.ShowReason))
+	table.SetHeader(getTableHeader(opts))
 	allEmpty := true
 	var index int
 	for _, data := range allDiffs {
 		for _, info := range data.diff {
-			row := getTableRowResourceInfo(index, data.resourceType, info, opts.ShowReason)
+			row := getTableRowResourceInfo(index, data.resourceType, info, opts)
 			table.Append(row)
 			allEmpty = false
 			index++
@@ -0,0 +1,128 @@
+package kor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/yonahd/kor/pkg/common"
+)
+
+func TestFormatOutputQuiet(t *testing.T) {
+	resources := map[string]map[string][]ResourceInfo{
+		"": {
+			"StorageClass": {{Name: "sc-1"}},
+		},
+		testNamespace: {
+			"ConfigMap":           {{Name: "cm-1", Reason: "ConfigMap is not used in any pod or container"}},
+			"ServiceAccountToken": {{Name: "token-1"}},
+		},
+	}
+
+	output := FormatOutput(resources, common.Opts{GroupBy: "namespace", Quiet: true, ShowReason: true})
+
+	expected := "test-namespace/configmap/cm-1\n" +
+		"test-namespace/secret/token-1\n" +
+		"storageclass/sc-1\n"
+	if output.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, output.String())
+	}
+}
+
+func TestFormatOutputSummary(t *testing.T) {
+	resources := map[string]map[string][]ResourceInfo{
+		"ConfigMap": {
+			"ns-a": {{Name: "cm-1"}, {Name: "cm-2"}},
+			"ns-b": {{Name: "cm-3"}},
+		},
+		"Secret": {
+			"ns-a": {{Name: "secret-1"}},
+		},
+	}
+
+	summary := formatSummary(resources, "resource")
+
+	for _, expected := range []string{
+		"| 1 | ConfigMap     | ns-a      |     2 |",
+		"| 2 | ConfigMap     | ns-b      |     1 |",
+		"| 3 | Secret        | ns-a      |     1 |",
+		"TOTAL   |   4   |",
+	} {
+		if !strings.Contains(summary, expected) {
+			t.Errorf("Expected summary to contain %q, got:\n%s", expected, summary)
+		}
+	}
+}
+
+func TestFormatHelmSummary(t *testing.T) {
+	resources := map[string]map[string][]ResourceInfo{
+		"ns-a": {
+			"ConfigMap": {{Name: "cm-1", HelmRelease: "payments"}, {Name: "cm-2"}},
+			"Secret":    {{Name: "secret-1", HelmRelease: "payments"}},
+		},
+		"ns-b": {
+			"ConfigMap": {{Name: "cm-3", HelmRelease: "billing"}},
+		},
+	}
+
+	summary := formatHelmSummary(resources, "namespace")
+
+	for _, expected := range []string{
+		"| 1 | billing      | ns-b      |     1 |",
+		"| 2 | payments     | ns-a      |     2 |",
+		"| 3 | <none>       | ns-a      |     1 |",
+		"TOTAL   |   4   |",
+	} {
+		if !strings.Contains(summary, expected) {
+			t.Errorf("Expected summary to contain %q, got:\n%s", expected, summary)
+		}
+	}
+}
+
+func TestUnusedResourceCount(t *testing.T) {
+	reportedResources = nil
+	defer func() { reportedResources = nil }()
+
+	resources := map[string]map[string][]ResourceInfo{
+		testNamespace: {
+			"ConfigMap": {{Name: "cm-1"}, {Name: "cm-2"}},
+			"Secret":    {{Name: "secret-1"}},
+		},
+	}
+	if _, err := formatUnusedResources(resources, "json", common.Opts{GroupBy: "namespace"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := formatUnusedResources(map[string]map[string][]ResourceInfo{}, "json", common.Opts{GroupBy: "namespace"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if count := UnusedResourceCount(); count != 3 {
+		t.Errorf("Expected 3 unused resources, got %d", count)
+	}
+}
+
+func TestColorizeName(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	old := metav1.NewTime(time.Now().Add(-2 * oldResourceAge))
+	recent := metav1.NewTime(time.Now().Add(-time.Hour))
+
+	if name := colorizeName(ResourceInfo{Name: "cm-1", CreationTimestamp: &old}); name != oldResourceColor.Sprint("cm-1") {
+		t.Errorf("Expected old resource to be red, got %q", name)
+	}
+	for _, info := range []ResourceInfo{{Name: "cm-2", CreationTimestamp: &recent}, {Name: "cm-3"}} {
+		if name := colorizeName(info); name != unusedResourceColor.Sprint(info.Name) {
+			t.Errorf("Expected %s to be yellow, got %q", info.Name, name)
+		}
+	}
+
+	color.NoColor = true
+	if name := colorizeName(ResourceInfo{Name: "cm-1", CreationTimestamp: &old}); name != "cm-1" {
+		t.Errorf("Expected plain name without colors, got %q", name)
+	}
+}
@@ -0,0 +1,200 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+const fluxSourceGroup = "source.toolkit.fluxcd.io"
+
+var fluxSourceResources = map[string]string{
+	"GitRepository":  "gitrepositories",
+	"OCIRepository":  "ocirepositories",
+	"Bucket":         "buckets",
+	"HelmRepository": "helmrepositories",
+	"HelmChart":      "helmcharts",
+}
+
+// argoInClusterServers are the destinations ArgoCD uses for the cluster it runs in.
+var argoInClusterServers = []string{"https://kubernetes.default.svc", ""}
+
+var gitOpsKinds = []string{"Kustomization", "HelmRelease", "Application"}
+
+// fluxSourceExists reports whether the source referenced by sourceRef exists.
+// When the reference omits a namespace the referencing object's namespace is used.
+func fluxSourceExists(ctx context.Context, clientset kubernetes.Interface, dynamicClient dynamic.Interface, namespace string, sourceRef map[string]interface{}) (bool, error) {
+	kind, _ := sourceRef["kind"].(string)
+	name, _ := sourceRef["name"].(string)
+	if sourceNamespace, _ := sourceRef["namespace"].(string); sourceNamespace != "" {
+		namespace = sourceNamespace
+	}
+
+	resource, ok := fluxSourceResources[kind]
+	if !ok {
+		return false, nil
+	}
+
+	gvr, found, err := discoverResource(clientset, fluxSourceGroup, resource)
+	if err != nil || !found {
+		return false, err
+	}
+
+	_, err = dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func fluxMissingSourceReason(ctx context.Context, clientset kubernetes.Interface, dynamicClient dynamic.Interface, obj unstructured.Unstructured, fields ...[]string) (string, error) {
+	for _, path := range fields {
+		sourceRef, found, _ := unstructured.NestedMap(obj.Object, path...)
+		if !found {
+			continue
+		}
+
+		exists, err := fluxSourceExists(ctx, clientset, dynamicClient, obj.GetNamespace(), sourceRef)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return fmt.Sprintf("Referenced source %s %s does not exist", sourceRef["kind"], sourceRef["name"]), nil
+		}
+	}
+	return "", nil
+}
+
+func processNamespaceGitOps(ctx context.Context, clientset kubernetes.Interface, dynamicClient dynamic.Interface, namespace string, filterOpts *filters.Options) (map[string][]ResourceInfo, error) {
+	diffs := make(map[string][]ResourceInfo)
+
+	checks := []struct {
+		kind, group, resource string
+		check                 func(obj unstructured.Unstructured) (string, error)
+	}{
+		{"Kustomization", "kustomize.toolkit.fluxcd.io", "kustomizations", func(obj unstructured.Unstructured) (string, error) {
+			return fluxMissingSourceReason(ctx, clientset, dynamicClient, obj, []string{"spec", "sourceRef"})
+		}},
+		{"HelmRelease", "helm.toolkit.fluxcd.io", "helmreleases", func(obj unstructured.Unstructured) (string, error) {
+			return fluxMissingSourceReason(ctx, clientset, dynamicClient, obj, []string{"spec", "chart", "spec", "sourceRef"}, []string{"spec", "chartRef"})
+		}},
+		{"Application", "argoproj.io", "applications", func(obj unstructured.Unstructured) (string, error) {
+			return argoApplicationReason(ctx, clientset, obj)
+		}},
+	}
+
+	for _, c := range checks {
+		gvr, found, err := discoverResource(clientset, c.group, c.resource)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+
+		objects, err := utils.ListAll(ctx, metav1.ListOptions{LabelSelector: filterOpts.LabelSelector()}, dynamicClient.Resource(gvr).Namespace(namespace).List)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range objects.Items {
+			if pass, _ := filter.SetObject(&obj).Run(filterOpts, filters.LabelFilterName, filters.AgeFilterName); pass {
+				continue
+			}
+
+			if obj.GetLabels()["kor/used"] == "false" {
+				diffs[c.kind] = append(diffs[c.kind], ResourceInfo{Name: obj.GetName(), Reason: "Marked with unused label"})
+				continue
+			}
+
+			reason, err := c.check(obj)
+			if err != nil {
+				return nil, err
+			}
+			if reason != "" {
+				diffs[c.kind] = append(diffs[c.kind], ResourceInfo{Name: obj.GetName(), Reason: reason})
+			}
+		}
+	}
+
+	return diffs, nil
+}
+
+func argoApplicationReason(ctx context.Context, clientset kubernetes.Interface, obj unstructured.Unstructured) (string, error) {
+	if health, _, _ := unstructured.NestedString(obj.Object, "status", "health", "status"); health == "Missing" {
+		return "Application health is Missing", nil
+	}
+
+	server, _, _ := unstructured.NestedString(obj.Object, "spec", "destination", "server")
+	name, _, _ := unstructured.NestedString(obj.Object, "spec", "destination", "name")
+	destinationNamespace, _, _ := unstructured.NestedString(obj.Object, "spec", "destination", "namespace")
+	// Destinations on remote clusters cannot be verified from here
+	if destinationNamespace == "" || !contains(argoInClusterServers, server) || (name != "" && name != "in-cluster") {
+		return "", nil
+	}
+
+	_, err := clientset.CoreV1().Namespaces().Get(ctx, destinationNamespace, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return fmt.Sprintf("Application destination namespace %s does not exist", destinationNamespace), nil
+	}
+	return "", err
+}
+
+func GetUnusedGitOpsResources(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
+	clientset = scanClientset(clientset)
+	resources := make(map[string]map[string][]ResourceInfo)
+	for _, scan := range scanNamespaces(filterOpts.Namespaces(ctx, clientset), func(namespace string) (map[string][]ResourceInfo, error) {
+		return processNamespaceGitOps(ctx, clientset, dynamicClient, namespace, filterOpts)
+	}) {
+		namespace, diffs, err := scan.namespace, scan.result, scan.err
+		if err != nil {
+			recordScanError("GitOps", namespace, err)
+			continue
+		}
+		switch opts.GroupBy {
+		case "namespace":
+			resources[namespace] = make(map[string][]ResourceInfo)
+			for _, kind := range gitOpsKinds {
+				resources[namespace][kind] = diffs[kind]
+			}
+		case "resource":
+			for _, kind := range gitOpsKinds {
+				appendResources(resources, kind, namespace, diffs[kind])
+			}
+		}
+	}
+
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+	default:
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	unusedGitOpsResources, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return unusedGitOpsResources, nil
+}
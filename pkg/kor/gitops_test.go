@@ -0,0 +1,121 @@
+package kor
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+var (
+	kustomizationGVR  = schema.GroupVersionResource{Group: "kustomize.toolkit.fluxcd.io", Version: "v1", Resource: "kustomizations"}
+	helmReleaseGVR    = schema.GroupVersionResource{Group: "helm.toolkit.fluxcd.io", Version: "v2", Resource: "helmreleases"}
+	gitRepositoryGVR  = schema.GroupVersionResource{Group: fluxSourceGroup, Version: "v1", Resource: "gitrepositories"}
+	helmRepositoryGVR = schema.GroupVersionResource{Group: fluxSourceGroup, Version: "v1", Resource: "helmrepositories"}
+	applicationGVR    = schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "applications"}
+)
+
+func createTestGitOpsObject(gvr schema.GroupVersionResource, kind, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := CreateTestUnstructered(kind, gvr.GroupVersion().String(), testNamespace, name)
+	obj.Object["spec"] = spec
+	return obj
+}
+
+func createTestGitOps(t *testing.T) (*fake.Clientset, *fakedynamic.FakeDynamicClient) {
+	clientset := fake.NewSimpleClientset()
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*v1.APIResourceList{
+		{GroupVersion: kustomizationGVR.GroupVersion().String(), APIResources: []v1.APIResource{{Name: kustomizationGVR.Resource, Namespaced: true}}},
+		{GroupVersion: helmReleaseGVR.GroupVersion().String(), APIResources: []v1.APIResource{{Name: helmReleaseGVR.Resource, Namespaced: true}}},
+		{GroupVersion: gitRepositoryGVR.GroupVersion().String(), APIResources: []v1.APIResource{
+			{Name: gitRepositoryGVR.Resource, Namespaced: true},
+			{Name: helmRepositoryGVR.Resource, Namespaced: true},
+		}},
+		{GroupVersion: applicationGVR.GroupVersion().String(), APIResources: []v1.APIResource{{Name: applicationGVR.Resource, Namespaced: true}}},
+	}
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{
+		ObjectMeta: v1.ObjectMeta{Name: testNamespace},
+	}, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+
+	missingApplication := createTestGitOpsObject(applicationGVR, "Application", "test-app-missing", map[string]interface{}{
+		"destination": map[string]interface{}{"server": "https://kubernetes.default.svc", "namespace": testNamespace},
+	})
+	missingApplication.Object["status"] = map[string]interface{}{
+		"health": map[string]interface{}{"status": "Missing"},
+	}
+
+	dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			kustomizationGVR:  "KustomizationList",
+			helmReleaseGVR:    "HelmReleaseList",
+			gitRepositoryGVR:  "GitRepositoryList",
+			helmRepositoryGVR: "HelmRepositoryList",
+			applicationGVR:    "ApplicationList",
+		},
+		CreateTestUnstructered("GitRepository", gitRepositoryGVR.GroupVersion().String(), testNamespace, "test-repo"),
+		createTestGitOpsObject(kustomizationGVR, "Kustomization", "test-ks-valid", map[string]interface{}{
+			"sourceRef": map[string]interface{}{"kind": "GitRepository", "name": "test-repo"},
+		}),
+		createTestGitOpsObject(kustomizationGVR, "Kustomization", "test-ks-missing", map[string]interface{}{
+			"sourceRef": map[string]interface{}{"kind": "GitRepository", "name": "deleted-repo"},
+		}),
+		createTestGitOpsObject(helmReleaseGVR, "HelmRelease", "test-hr-missing", map[string]interface{}{
+			"chart": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"chart":     "podinfo",
+					"sourceRef": map[string]interface{}{"kind": "HelmRepository", "name": "podinfo"},
+				},
+			},
+		}),
+		createTestGitOpsObject(applicationGVR, "Application", "test-app-valid", map[string]interface{}{
+			"destination": map[string]interface{}{"server": "https://kubernetes.default.svc", "namespace": testNamespace},
+		}),
+		createTestGitOpsObject(applicationGVR, "Application", "test-app-no-namespace", map[string]interface{}{
+			"destination": map[string]interface{}{"name": "in-cluster", "namespace": "deleted-namespace"},
+		}),
+		createTestGitOpsObject(applicationGVR, "Application", "test-app-remote", map[string]interface{}{
+			"destination": map[string]interface{}{"server": "https://remote.kor.com", "namespace": "deleted-namespace"},
+		}),
+		missingApplication,
+	)
+
+	return clientset, dynamicClient
+}
+
+func TestProcessNamespaceGitOps(t *testing.T) {
+	clientset, dynamicClient := createTestGitOps(t)
+
+	diffs, err := processNamespaceGitOps(context.Background(), clientset, dynamicClient, testNamespace, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string][]ResourceInfo{
+		"Kustomization": {
+			{Name: "test-ks-missing", Reason: "Referenced source GitRepository deleted-repo does not exist"},
+		},
+		"HelmRelease": {
+			{Name: "test-hr-missing", Reason: "Referenced source HelmRepository podinfo does not exist"},
+		},
+		"Application": {
+			{Name: "test-app-missing", Reason: "Application health is Missing"},
+			{Name: "test-app-no-namespace", Reason: "Application destination namespace deleted-namespace does not exist"},
+		},
+	}
+	if !reflect.DeepEqual(diffs, expected) {
+		t.Errorf("Expected %v, got %v", expected, diffs)
+	}
+}
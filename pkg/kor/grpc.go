@@ -0,0 +1,87 @@
+package kor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	korv1 "github.com/yonahd/kor/pkg/api/kor/v1"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// scanLock serializes the scans of kor serve, they share the reported resources.
+var scanLock sync.Mutex
+
+// grpcServer streams the findings of the scans requested over gRPC.
+type grpcServer struct {
+	korv1.UnimplementedKorServer
+	// scan scans the resources, or the default ones when empty
+	scan func(ctx context.Context, filterOpts *filters.Options, resources []string) error
+}
+
+// Scan streams the unused resources as the detectors report them. The filters
+// of the request replace the ones kor serve was started with.
+func (s *grpcServer) Scan(request *korv1.ScanRequest, stream korv1.Kor_ScanServer) error {
+	ctx := stream.Context()
+	filterOpts := &filters.Options{
+		IncludeNamespaces: request.GetIncludeNamespaces(),
+		ExcludeNamespaces: request.GetExcludeNamespaces(),
+		IncludeLabels:     request.GetIncludeLabels(),
+		ExcludeLabels:     request.GetExcludeLabels(),
+		OlderThan:         request.GetOlderThan(),
+		NewerThan:         request.GetNewerThan(),
+	}
+	if err := filterOpts.Validate(); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	filterOpts.Modify()
+
+	scanLock.Lock()
+	defer scanLock.Unlock()
+	var sendErr error
+	onRecord = func(resources map[string]map[string][]ResourceInfo) {
+		for _, document := range sortedDocuments(resources, time.Time{}, nil) {
+			if sendErr != nil {
+				return
+			}
+			sendErr = stream.Send(grpcFinding(document))
+		}
+	}
+	defer func() { onRecord = nil }()
+
+	resetReportedResources()
+	// Closing the stream cancels ctx, which aborts the scan
+	err := s.scan(ctx, filterOpts, request.GetResources())
+	switch {
+	case ctx.Err() != nil:
+		return status.FromContextError(ctx.Err()).Err()
+	case sendErr != nil:
+		return sendErr
+	case err != nil:
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+func grpcFinding(document findingDocument) *korv1.Finding {
+	finding := &korv1.Finding{
+		Namespace:   document.Namespace,
+		Kind:        document.Kind,
+		Name:        document.Name,
+		Reason:      document.Reason,
+		Owners:      document.Owners,
+		ManagedBy:   document.ManagedBy,
+		HelmRelease: document.HelmRelease,
+	}
+	if document.CreationTimestamp != nil {
+		finding.CreationTimestamp = timestamppb.New(document.CreationTimestamp.Time)
+	}
+	if document.Size != nil {
+		finding.Size = document.Size.String()
+	}
+	return finding
+}
@@ -0,0 +1,132 @@
+package kor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	korv1 "github.com/yonahd/kor/pkg/api/kor/v1"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func testGRPCClient(t *testing.T, server *grpcServer) korv1.KorClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	rpcServer := grpc.NewServer()
+	korv1.RegisterKorServer(rpcServer, server)
+	go func() { _ = rpcServer.Serve(listener) }()
+	t.Cleanup(rpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return korv1.NewKorClient(conn)
+}
+
+func receiveFindings(stream korv1.Kor_ScanClient) ([]*korv1.Finding, error) {
+	var findings []*korv1.Finding
+	for {
+		finding, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return findings, nil
+		}
+		if err != nil {
+			return findings, err
+		}
+		findings = append(findings, finding)
+	}
+}
+
+func TestGRPCScan(t *testing.T) {
+	defer resetReportedResources()
+	var scanned []string
+	var filterOpts *filters.Options
+	var scanCtx context.Context
+	size := resource.MustParse("1Ki")
+	client := testGRPCClient(t, &grpcServer{scan: func(ctx context.Context, opts *filters.Options, resources []string) error {
+		scanned, filterOpts, scanCtx = resources, opts, ctx
+		recordUnusedResources(emailTestReport(), "namespace")
+		recordUnusedResources(map[string]map[string][]ResourceInfo{"apps": {"ConfigMap": {{Name: "old", Size: &size}}}}, "namespace")
+		return nil
+	}})
+
+	stream, err := client.Scan(context.Background(), &korv1.ScanRequest{Resources: []string{"cm", "secret"}, IncludeNamespaces: []string{"default", "apps"}, OlderThan: "1h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings, err := receiveFindings(stream)
+	if err != nil {
+		t.Fatalf("Expected the scan to succeed, got %v", err)
+	}
+	if !slices.Equal(scanned, []string{"cm", "secret"}) || !slices.Equal(filterOpts.IncludeNamespaces, []string{"default", "apps"}) || filterOpts.OlderThan != "1h" {
+		t.Errorf("Expected the request to select the scan, got %v %+v", scanned, filterOpts)
+	}
+
+	var names []string
+	for _, finding := range findings {
+		names = append(names, finding.GetNamespace()+"/"+finding.GetKind()+"/"+finding.GetName())
+	}
+	expected := []string{"/Pv/pv-1", "default/ConfigMap/<script>", "default/Secret/token", "apps/ConfigMap/old"}
+	if !slices.Equal(names, expected) {
+		t.Fatalf("Expected the findings in the order they were reported %v, got %v", expected, names)
+	}
+	if created := findings[1].GetCreationTimestamp().AsTime(); created.Year() != 2026 {
+		t.Errorf("Expected the creation timestamp, got %v", created)
+	}
+	if findings[2].GetReason() != `Secret is not used, "anywhere"` || findings[3].GetSize() != "1Ki" {
+		t.Errorf("Expected the details of the findings, got %v and %v", findings[2], findings[3])
+	}
+	if onRecord != nil {
+		t.Error("Expected the stream to stop receiving findings after the scan")
+	}
+	select {
+	case <-scanCtx.Done():
+	case <-time.After(time.Second):
+		t.Error("Expected the scan to run with the context of the stream")
+	}
+}
+
+func TestGRPCScanErrors(t *testing.T) {
+	defer resetReportedResources()
+	scans := 0
+	client := testGRPCClient(t, &grpcServer{scan: func(context.Context, *filters.Options, []string) error {
+		scans++
+		return errors.New("forbidden")
+	}})
+
+	tests := map[string]struct {
+		request *korv1.ScanRequest
+		code    codes.Code
+	}{
+		"invalid filters": {&korv1.ScanRequest{OlderThan: "1h", NewerThan: "2h"}, codes.InvalidArgument},
+		"invalid labels":  {&korv1.ScanRequest{IncludeLabels: "team in"}, codes.InvalidArgument},
+		"failed scan":     {&korv1.ScanRequest{}, codes.Internal},
+	}
+	for name, test := range tests {
+		stream, err := client.Scan(context.Background(), test.request)
+		if err == nil {
+			_, err = receiveFindings(stream)
+		}
+		if status.Code(err) != test.code {
+			t.Errorf("%s: expected %s, got %v", name, test.code, err)
+		}
+	}
+	if scans != 1 {
+		t.Errorf("Expected invalid requests not to scan, got %d scans", scans)
+	}
+}
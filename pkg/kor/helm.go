@@ -0,0 +1,275 @@
+package kor
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
+	"k8s.io/client-go/restmapper"
+	"sigs.k8s.io/yaml"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+const (
+	helmReleaseSecretType = "helm.sh/release.v1"
+
+	DefaultHelmHistoryMax = 10
+)
+
+var gzipMagic = []byte{0x1f, 0x8b, 0x08}
+
+// helmRelease holds the fields kor needs from a stored Helm release.
+type helmRelease struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Manifest  string `json:"manifest"`
+	Version   int    `json:"version"`
+}
+
+// decodeHelmRelease decodes the release payload Helm stores in the "release"
+// key of its Secrets: base64 encoded, usually gzipped, JSON.
+func decodeHelmRelease(data []byte) (*helmRelease, error) {
+	decoded, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(decoded, gzipMagic) {
+		reader, err := gzip.NewReader(bytes.NewReader(decoded))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		if decoded, err = io.ReadAll(reader); err != nil {
+			return nil, err
+		}
+	}
+
+	var release helmRelease
+	if err := json.Unmarshal(decoded, &release); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+// manifestObjects splits a rendered Helm manifest into its objects.
+func manifestObjects(manifest string) []unstructured.Unstructured {
+	var objects []unstructured.Unstructured
+	for _, document := range strings.Split(manifest, "\n---") {
+		var obj map[string]interface{}
+		if err := yaml.Unmarshal([]byte(document), &obj); err != nil || obj == nil {
+			continue
+		}
+		u := unstructured.Unstructured{Object: obj}
+		if u.GetKind() == "" || u.GetName() == "" {
+			continue
+		}
+		objects = append(objects, u)
+	}
+	return objects
+}
+
+// releaseHasLiveObjects reports whether any object of the release manifest
+// still exists in the cluster, or whether the manifest has no objects to check.
+func releaseHasLiveObjects(ctx context.Context, mapper meta.RESTMapper, dynamicClient dynamic.Interface, release *helmRelease) (bool, error) {
+	objects := manifestObjects(release.Manifest)
+	if len(objects) == 0 {
+		return true, nil
+	}
+
+	for _, obj := range objects {
+		gvk := obj.GroupVersionKind()
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			// The API serving this kind is gone, so the object is too
+			continue
+		}
+
+		resource := dynamicClient.Resource(mapping.Resource)
+		var getErr error
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			namespace := obj.GetNamespace()
+			if namespace == "" {
+				namespace = release.Namespace
+			}
+			_, getErr = resource.Namespace(namespace).Get(ctx, obj.GetName(), metav1.GetOptions{})
+		} else {
+			_, getErr = resource.Get(ctx, obj.GetName(), metav1.GetOptions{})
+		}
+
+		if getErr == nil {
+			return true, nil
+		}
+		if !errors.IsNotFound(getErr) {
+			return false, getErr
+		}
+	}
+	return false, nil
+}
+
+func processNamespaceHelmReleases(ctx context.Context, clientset kubernetes.Interface, dynamicClient dynamic.Interface, namespace string, filterOpts *filters.Options, historyMax int) ([]ResourceInfo, error) {
+	secrets, err := utils.ListAll(ctx, metav1.ListOptions{
+		LabelSelector: "owner=helm",
+		FieldSelector: "type=" + helmReleaseSecretType,
+	}, clientset.CoreV1().Secrets(namespace).List)
+	if err != nil {
+		return nil, err
+	}
+
+	groupResources, err := restmapper.GetAPIGroupResources(clientset.Discovery())
+	if err != nil {
+		return nil, err
+	}
+	mapper := restmapper.NewDiscoveryRESTMapper(groupResources)
+
+	type revision struct {
+		secretName string
+		version    int
+		status     string
+	}
+	revisions := make(map[string][]revision)
+
+	var unusedReleases []ResourceInfo
+
+	for _, secret := range secrets.Items {
+		if string(secret.Type) != helmReleaseSecretType {
+			continue
+		}
+
+		if pass, _ := filter.SetObject(&secret).Run(filterOpts); pass {
+			continue
+		}
+
+		if secret.Labels["kor/used"] == "false" {
+			unusedReleases = append(unusedReleases, ResourceInfo{Name: secret.Name, Reason: "Marked with unused label"})
+			continue
+		}
+
+		version, err := strconv.Atoi(secret.Labels["version"])
+		if err != nil {
+			continue
+		}
+		releaseName := secret.Labels["name"]
+		revisions[releaseName] = append(revisions[releaseName], revision{secret.Name, version, secret.Labels["status"]})
+	}
+
+	releaseNames := make([]string, 0, len(revisions))
+	for releaseName := range revisions {
+		releaseNames = append(releaseNames, releaseName)
+	}
+	sort.Strings(releaseNames)
+
+	for _, releaseName := range releaseNames {
+		releaseRevisions := revisions[releaseName]
+		sort.Slice(releaseRevisions, func(i, j int) bool {
+			return releaseRevisions[i].version > releaseRevisions[j].version
+		})
+
+		latest := releaseRevisions[0]
+		secret, err := clientset.CoreV1().Secrets(namespace).Get(ctx, latest.secretName, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		release, err := decodeHelmRelease(secret.Data["release"])
+		if err != nil {
+			slog.Error("Failed to decode Helm release", "secret", latest.secretName, "error", err)
+			continue
+		}
+		if release.Namespace == "" {
+			release.Namespace = namespace
+		}
+
+		live, err := releaseHasLiveObjects(ctx, mapper, dynamicClient, release)
+		if err != nil {
+			return nil, err
+		}
+		if !live {
+			// Every revision of a release without live objects is a leftover
+			for _, r := range releaseRevisions {
+				reason := fmt.Sprintf("Helm release %s no longer manages any live objects", releaseName)
+				unusedReleases = append(unusedReleases, ResourceInfo{Name: r.secretName, Reason: reason})
+			}
+			continue
+		}
+
+		if historyMax <= 0 {
+			continue
+		}
+		for i, r := range releaseRevisions {
+			if i >= historyMax && r.status == "superseded" {
+				reason := fmt.Sprintf("Superseded revision %d of release %s exceeds history limit of %d", r.version, releaseName, historyMax)
+				unusedReleases = append(unusedReleases, ResourceInfo{Name: r.secretName, Reason: reason})
+			}
+		}
+	}
+
+	return unusedReleases, nil
+}
+
+func GetUnusedHelmReleases(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts, historyMax int) (string, error) {
+	clientset = scanClientset(clientset)
+	resources := make(map[string]map[string][]ResourceInfo)
+	for _, scan := range scanNamespaces(filterOpts.Namespaces(ctx, clientset), func(namespace string) ([]ResourceInfo, error) {
+		return processNamespaceHelmReleases(ctx, clientset, dynamicClient, namespace, filterOpts, historyMax)
+	}) {
+		namespace, diff, err := scan.namespace, scan.result, scan.err
+		if err != nil {
+			recordScanError("HelmReleaseSecret", namespace, err)
+			continue
+		}
+		exportManifests(ctx, clientset, namespace, "HelmReleaseSecret", diff, opts)
+		if deleteEnabled(opts, "HelmReleaseSecret") {
+			if diff, err = DeleteResource(ctx, diff, clientset, namespace, "HelmReleaseSecret", opts.NoInteractive); err != nil {
+				slog.Error("Failed to delete HelmReleaseSecret", "name", diff, "namespace", namespace, "error", err)
+			}
+		}
+		switch opts.GroupBy {
+		case "namespace":
+			resources[namespace] = make(map[string][]ResourceInfo)
+			resources[namespace]["HelmReleaseSecret"] = diff
+		case "resource":
+			appendResources(resources, "HelmReleaseSecret", namespace, diff)
+		}
+	}
+
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+	default:
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	unusedHelmReleases, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return unusedHelmReleases, nil
+}
@@ -0,0 +1,84 @@
+package kor
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestHelmReleaseSecret(t *testing.T, release, status, manifest string, version int) *corev1.Secret {
+	payload, err := json.Marshal(helmRelease{Name: release, Namespace: testNamespace, Manifest: manifest, Version: version})
+	if err != nil {
+		t.Fatalf("Error encoding release: %v", err)
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(payload); err != nil {
+		t.Fatalf("Error compressing release: %v", err)
+	}
+	writer.Close()
+
+	secret := CreateTestSecret(testNamespace, fmt.Sprintf("sh.helm.release.v1.%s.v%d", release, version), map[string]string{
+		"name":    release,
+		"owner":   "helm",
+		"status":  status,
+		"version": fmt.Sprint(version),
+	})
+	secret.Type = helmReleaseSecretType
+	secret.Data = map[string][]byte{"release": []byte(base64.StdEncoding.EncodeToString(buf.Bytes()))}
+	return secret
+}
+
+func TestProcessNamespaceHelmReleases(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*v1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []v1.APIResource{{Name: "configmaps", Kind: "ConfigMap", Namespaced: true}}},
+	}
+
+	liveManifest := "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: live-config\n"
+	goneManifest := "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: gone-config\n"
+
+	secrets := []*corev1.Secret{
+		createTestHelmReleaseSecret(t, "live", "superseded", liveManifest, 1),
+		createTestHelmReleaseSecret(t, "live", "superseded", liveManifest, 2),
+		createTestHelmReleaseSecret(t, "live", "deployed", liveManifest, 3),
+		createTestHelmReleaseSecret(t, "gone", "deployed", goneManifest, 1),
+	}
+	for _, secret := range secrets {
+		_, err := clientset.CoreV1().Secrets(testNamespace).Create(context.TODO(), secret, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake %s: %v", "Secret", err)
+		}
+	}
+
+	dynamicClient := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(),
+		CreateTestUnstructered("ConfigMap", "v1", testNamespace, "live-config"),
+	)
+
+	unusedReleases, err := processNamespaceHelmReleases(context.Background(), clientset, dynamicClient, testNamespace, &filters.Options{}, 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []ResourceInfo{
+		{Name: "sh.helm.release.v1.gone.v1", Reason: "Helm release gone no longer manages any live objects"},
+		{Name: "sh.helm.release.v1.live.v1", Reason: "Superseded revision 1 of release live exceeds history limit of 2"},
+	}
+	if !equalResourceInfoSlices(unusedReleases, expected) {
+		t.Errorf("Expected %v, got %v", expected, unusedReleases)
+	}
+}
@@ -0,0 +1,667 @@
+package kor
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	bolt "go.etcd.io/bbolt"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// stateStore is the file of --state-store, the history of the scans is not
+// recorded when empty.
+var stateStore string
+
+// stateStoreTimeout is how long a run waits for another one to release the
+// state store.
+const stateStoreTimeout = 30 * time.Second
+
+var (
+	historyScansBucket     = []byte("scans")
+	historyResourcesBucket = []byte("resources")
+)
+
+// scannedNamespaces holds the namespaces scanned in each cluster of the
+// scans across several clusters, "" standing for the cluster-scoped
+// resources.
+var scannedNamespaces struct {
+	sync.Mutex
+	clusters map[string][]string
+}
+
+// SetStateStore makes the scans record their findings in the state store at
+// path, for kor history. An empty path records nothing.
+func SetStateStore(path string) {
+	stateStore = path
+}
+
+// HistoryScan is a scan recorded in the state store, with the number of
+// unused resources it reported, how many of them were not unused in the
+// previous scans and how many of the previously unused ones it resolved.
+type HistoryScan struct {
+	Time        time.Time `json:"time"`
+	Unused      int       `json:"unused"`
+	NewlyUnused int       `json:"newlyUnused"`
+	Resolved    int       `json:"resolved"`
+}
+
+// ResourceHistory is the record of a resource in the state store, the
+// periods it was reported unused, oldest first.
+type ResourceHistory struct {
+	Cluster   string         `json:"cluster,omitempty"`
+	Namespace string         `json:"namespace,omitempty"`
+	Kind      string         `json:"kind"`
+	Name      string         `json:"name"`
+	Reason    string         `json:"reason,omitempty"`
+	Periods   []UnusedPeriod `json:"periods"`
+}
+
+// UnusedPeriod is a period during which every scan covering a resource
+// reported it unused. Resolved is the time of the first scan that did not,
+// nil while the resource is still unused.
+type UnusedPeriod struct {
+	Since    time.Time  `json:"since"`
+	LastSeen time.Time  `json:"lastSeen"`
+	Scans    int        `json:"scans"`
+	Resolved *time.Time `json:"resolved,omitempty"`
+}
+
+// HistoryDay sums up the scans of a day, Unused is the count of its last scan.
+type HistoryDay struct {
+	Date        string `json:"date"`
+	Scans       int    `json:"scans"`
+	Unused      int    `json:"unused"`
+	NewlyUnused int    `json:"newlyUnused"`
+	Resolved    int    `json:"resolved"`
+}
+
+// current returns the period the resource is still unused in, nil when it
+// was resolved.
+func (h *ResourceHistory) current() *UnusedPeriod {
+	if len(h.Periods) == 0 || h.Periods[len(h.Periods)-1].Resolved != nil {
+		return nil
+	}
+	return &h.Periods[len(h.Periods)-1]
+}
+
+// UnusedSince returns since when the resource has been continuously unused,
+// false when the last scans resolved it.
+func (h ResourceHistory) UnusedSince() (time.Time, bool) {
+	if period := h.current(); period != nil {
+		return period.Since, true
+	}
+	return time.Time{}, false
+}
+
+// title names the resource like the --quiet lines, with its cluster.
+func (h ResourceHistory) title() string {
+	title := h.Kind + " " + h.Name
+	if h.Namespace != "" {
+		title = h.Kind + " " + h.Namespace + "/" + h.Name
+	}
+	if h.Cluster != "" {
+		title += " of cluster " + h.Cluster
+	}
+	return title
+}
+
+func historyKey(cluster, namespace, kind, name string) []byte {
+	return []byte(strings.Join([]string{cluster, namespace, kind, name}, "\x00"))
+}
+
+func scanKey(t time.Time) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	return key
+}
+
+func openStateStore(path string, readOnly bool) (*bolt.DB, error) {
+	if readOnly {
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("failed to open state store: %w", err)
+		}
+	} else if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create the directory of the state store: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: stateStoreTimeout, ReadOnly: readOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state store %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// recordScannedNamespaces keeps the namespaces scanned in cluster by a scan
+// across several clusters, for the history of the scan.
+func recordScannedNamespaces(ctx context.Context, cluster string, filterOpts *filters.Options, clientset kubernetes.Interface) {
+	namespaces := historyNamespaces(ctx, filterOpts, clientset)
+	scannedNamespaces.Lock()
+	defer scannedNamespaces.Unlock()
+	if scannedNamespaces.clusters == nil {
+		scannedNamespaces.clusters = make(map[string][]string)
+	}
+	scannedNamespaces.clusters[cluster] = namespaces
+}
+
+// historyNamespaces returns the namespaces scanned with filterOpts, and ""
+// for the cluster-scoped resources unless the scan is limited to some
+// namespaces.
+func historyNamespaces(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface) []string {
+	namespaces := append([]string(nil), filterOpts.Namespaces(ctx, clientset)...)
+	if len(filterOpts.IncludeNamespaces) == 0 {
+		namespaces = append(namespaces, "")
+	}
+	return namespaces
+}
+
+func resetScannedNamespaces() {
+	scannedNamespaces.Lock()
+	defer scannedNamespaces.Unlock()
+	scannedNamespaces.clusters = nil
+}
+
+// unusedFor is the --unused-for grace period, resources are only reported
+// and deleted once the state store recorded them as continuously unused for
+// that long.
+var unusedFor time.Duration
+
+// historyFindings are the findings of the run, including the ones unusedFor
+// held back from the report, which the state store records as well.
+var historyFindings []unusedResource
+
+// unusedSince holds since when the resources of the state store have been
+// continuously unused, read once per run.
+var unusedSince struct {
+	sync.Mutex
+	loaded    bool
+	resources map[string]time.Time
+}
+
+// SetUnusedFor only reports and deletes the resources the state store
+// recorded as continuously unused for at least d, so the resources used
+// intermittently are left out. 0 reports every unused resource.
+func SetUnusedFor(d time.Duration) error {
+	if d < 0 {
+		return errors.New("--unused-for must be a non-negative duration")
+	}
+	if d > 0 && stateStore == "" {
+		return errors.New("--unused-for requires --state-store")
+	}
+	unusedFor = d
+	return nil
+}
+
+func resetUnusedSince() {
+	unusedSince.Lock()
+	defer unusedSince.Unlock()
+	unusedSince.loaded, unusedSince.resources = false, nil
+}
+
+// findingName strips the suffix --delete adds to the names of the resources
+// it deleted.
+func findingName(name string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(name, deletedNameSuffix), dryRunSuffix)
+}
+
+// unusedSinceKey matches the report kinds case-insensitively, the deletions
+// name PVC the Pvc of the reports.
+func unusedSinceKey(cluster, namespace, kind, name string) string {
+	return string(historyKey(cluster, namespace, strings.ToLower(kind), name))
+}
+
+// loadUnusedSince reads since when the resources the state store at path
+// records as unused have been, none when the store does not exist yet.
+func loadUnusedSince(path string) (map[string]time.Time, error) {
+	resources := make(map[string]time.Time)
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return resources, nil
+	}
+	db, err := openStateStore(path, true)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(historyResourcesBucket)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(_, data []byte) error {
+			var history ResourceHistory
+			if err := json.Unmarshal(data, &history); err != nil {
+				return err
+			}
+			if since, ok := history.UnusedSince(); ok {
+				resources[unusedSinceKey(history.Cluster, history.Namespace, history.Kind, history.Name)] = since
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state store %s: %w", path, err)
+	}
+	return resources, nil
+}
+
+// unusedLongEnough reports whether the state store recorded the resource as
+// continuously unused for --unused-for. A resource it has no record of is
+// first seen unused by this scan, and none is when the store cannot be read,
+// so an unreadable store never gets a resource deleted.
+func unusedLongEnough(cluster, namespace, kind, name string, now time.Time) bool {
+	if unusedFor == 0 {
+		return true
+	}
+	unusedSince.Lock()
+	defer unusedSince.Unlock()
+	if !unusedSince.loaded {
+		resources, err := loadUnusedSince(stateStore)
+		if err != nil {
+			slog.Error("Failed to read the state store, no resource has been unused for --unused-for", "error", err)
+		}
+		unusedSince.loaded, unusedSince.resources = true, resources
+	}
+	since, ok := unusedSince.resources[unusedSinceKey(cluster, namespace, kind, findingName(name))]
+	return ok && now.Sub(since) >= unusedFor
+}
+
+// holdBackRecentlyUnused removes the resources that have not been unused for
+// --unused-for yet from the report of cluster.
+func holdBackRecentlyUnused(cluster string, resources map[string]map[string][]ResourceInfo, groupBy string, now time.Time) {
+	for outerKey, inner := range resources {
+		for innerKey, infos := range inner {
+			if len(infos) == 0 {
+				continue
+			}
+			namespace, kind := outerKey, innerKey
+			if groupBy == "resource" {
+				namespace, kind = innerKey, outerKey
+			}
+			kept := make([]ResourceInfo, 0, len(infos))
+			for _, info := range infos {
+				if unusedLongEnough(cluster, namespace, kind, info.Name, now) {
+					kept = append(kept, info)
+				}
+			}
+			inner[innerKey] = kept
+		}
+	}
+}
+
+// historyScope tells which resources a scan would have reported if they
+// were still unused.
+type historyScope struct {
+	// kinds are the kinds the scan looked for, every kind when nil
+	kinds map[string]bool
+	// namespaces are the namespaces scanned per cluster
+	namespaces map[string]map[string]bool
+	// failed are the clusters and namespaces some scans failed for, by
+	// cluster, "" standing for the whole cluster
+	failed map[string]map[string]bool
+}
+
+func (s historyScope) covers(cluster, namespace, kind string) bool {
+	if s.kinds != nil && !s.kinds[kind] {
+		return false
+	}
+	if failed := s.failed[cluster]; failed[""] || failed[namespace] {
+		return false
+	}
+	return s.namespaces[cluster][namespace]
+}
+
+// newHistoryScope returns the scope of a scan of resourceList, every
+// resource when empty, over namespaces by cluster.
+func newHistoryScope(resourceList []string, namespaces map[string][]string) historyScope {
+	scope := historyScope{namespaces: make(map[string]map[string]bool), failed: make(map[string]map[string]bool)}
+	if len(resourceList) > 0 {
+		scope.kinds = make(map[string]bool)
+		for _, resource := range resourceList {
+			if detector := lookupDetector(resource); detector != nil {
+				for _, kind := range detector.SupportedKinds() {
+					scope.kinds[kind] = true
+				}
+			}
+		}
+	}
+	for cluster, clusterNamespaces := range namespaces {
+		scope.namespaces[cluster] = make(map[string]bool, len(clusterNamespaces))
+		for _, namespace := range clusterNamespaces {
+			scope.namespaces[cluster][namespace] = true
+		}
+	}
+
+	scanErrors.Lock()
+	defer scanErrors.Unlock()
+	for _, err := range scanErrors.errs {
+		var scanErr *ScanError
+		if !errors.As(err, &scanErr) {
+			continue
+		}
+		if scope.failed[scanErr.Cluster] == nil {
+			scope.failed[scanErr.Cluster] = make(map[string]bool)
+		}
+		scope.failed[scanErr.Cluster][scanErr.Namespace] = true
+	}
+	return scope
+}
+
+// RecordHistory records the unused resources found during this run in the
+// state store of SetStateStore, with the ones --unused-for held back, as a
+// scan of resourceList, every resource when empty. The unused resources of
+// the state store the scan covered, of its kinds and in the namespaces
+// scanned with filterOpts and clientset or in the clusters of a scan across
+// several clusters, are resolved when the scan did not find them. Nothing is
+// resolved in the namespaces the scan failed for.
+func RecordHistory(ctx context.Context, resourceList []string, filterOpts *filters.Options, clientset kubernetes.Interface) error {
+	if stateStore == "" {
+		return nil
+	}
+	scannedNamespaces.Lock()
+	namespaces := scannedNamespaces.clusters
+	scannedNamespaces.Unlock()
+	if len(namespaces) == 0 && clientset != nil {
+		namespaces = map[string][]string{"": historyNamespaces(ctx, filterOpts, clientset)}
+	}
+	return recordHistory(stateStore, historyFindings, newHistoryScope(resourceList, namespaces), time.Now())
+}
+
+func recordHistory(path string, findings []unusedResource, scope historyScope, now time.Time) error {
+	db, err := openStateStore(path, false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		scans, err := tx.CreateBucketIfNotExists(historyScansBucket)
+		if err != nil {
+			return err
+		}
+		resources, err := tx.CreateBucketIfNotExists(historyResourcesBucket)
+		if err != nil {
+			return err
+		}
+
+		scan := HistoryScan{Time: now}
+		updates := make(map[string]ResourceHistory)
+		for _, finding := range findings {
+			name := findingName(finding.Name)
+			key := historyKey(finding.Cluster, finding.Namespace, finding.Kind, name)
+			if _, ok := updates[string(key)]; ok {
+				continue
+			}
+			history := ResourceHistory{Cluster: finding.Cluster, Namespace: finding.Namespace, Kind: finding.Kind, Name: name}
+			if data := resources.Get(key); data != nil {
+				if err := json.Unmarshal(data, &history); err != nil {
+					return fmt.Errorf("failed to decode the history of %s: %w", history.title(), err)
+				}
+			}
+			history.Reason = finding.Reason
+			if period := history.current(); period != nil {
+				period.LastSeen = now
+				period.Scans++
+			} else {
+				history.Periods = append(history.Periods, UnusedPeriod{Since: now, LastSeen: now, Scans: 1})
+				scan.NewlyUnused++
+			}
+			updates[string(key)] = history
+		}
+		scan.Unused = len(updates)
+
+		err = resources.ForEach(func(key, data []byte) error {
+			if _, ok := updates[string(key)]; ok {
+				return nil
+			}
+			var history ResourceHistory
+			if err := json.Unmarshal(data, &history); err != nil {
+				return fmt.Errorf("failed to decode the history of %q: %w", key, err)
+			}
+			period := history.current()
+			if period == nil || !scope.covers(history.Cluster, history.Namespace, history.Kind) {
+				return nil
+			}
+			period.Resolved = &now
+			scan.Resolved++
+			updates[string(key)] = history
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for key, history := range updates {
+			data, err := json.Marshal(history)
+			if err != nil {
+				return err
+			}
+			if err := resources.Put([]byte(key), data); err != nil {
+				return err
+			}
+		}
+		data, err := json.Marshal(scan)
+		if err != nil {
+			return err
+		}
+		return scans.Put(scanKey(now), data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record the scan in state store %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadResourceHistories returns the records of the state store at path
+// matching resource, namespace/kind/name or kind/name like the --quiet
+// lines. The kind is a kind or a resource name accepted by kor, kind/name
+// matches the resources of every namespace and cluster.
+func loadResourceHistories(path, resource string) ([]ResourceHistory, error) {
+	parts := strings.Split(resource, "/")
+	var namespace, kind, name string
+	switch len(parts) {
+	case 2:
+		kind, name = parts[0], parts[1]
+	case 3:
+		namespace, kind, name = parts[0], parts[1], parts[2]
+	}
+	if kind == "" || name == "" {
+		return nil, fmt.Errorf("invalid resource %q, expected kind/name or namespace/kind/name", resource)
+	}
+
+	db, err := openStateStore(path, true)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var histories []ResourceHistory
+	err = db.View(func(tx *bolt.Tx) error {
+		resources := tx.Bucket(historyResourcesBucket)
+		if resources == nil {
+			return nil
+		}
+		return resources.ForEach(func(_, data []byte) error {
+			var history ResourceHistory
+			if err := json.Unmarshal(data, &history); err != nil {
+				return err
+			}
+			if history.Name == name && (len(parts) == 2 || history.Namespace == namespace) && historyKindMatches(history.Kind, kind) {
+				histories = append(histories, history)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state store %s: %w", path, err)
+	}
+	sort.Slice(histories, func(i, j int) bool {
+		a, b := histories[i], histories[j]
+		if a.Cluster != b.Cluster {
+			return a.Cluster < b.Cluster
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Kind < b.Kind
+	})
+	return histories, nil
+}
+
+// historyKindMatches reports whether the kind of a record is kind, the kind
+// of a --quiet line or of the detector named kind.
+func historyKindMatches(recorded, kind string) bool {
+	if strings.EqualFold(recorded, kind) {
+		return true
+	}
+	if quiet, ok := quietKinds[recorded]; ok && quiet == strings.ToLower(kind) {
+		return true
+	}
+	if detector := lookupDetector(kind); detector != nil {
+		for _, supported := range detector.SupportedKinds() {
+			if supported == recorded {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// loadHistoryTrend sums up the scans of the state store at path recorded
+// since then, per UTC day.
+func loadHistoryTrend(path string, since time.Time) ([]HistoryDay, error) {
+	db, err := openStateStore(path, true)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var days []HistoryDay
+	err = db.View(func(tx *bolt.Tx) error {
+		scans := tx.Bucket(historyScansBucket)
+		if scans == nil {
+			return nil
+		}
+		cursor := scans.Cursor()
+		for key, data := cursor.Seek(scanKey(since)); key != nil; key, data = cursor.Next() {
+			var scan HistoryScan
+			if err := json.Unmarshal(data, &scan); err != nil {
+				return err
+			}
+			date := scan.Time.UTC().Format(time.DateOnly)
+			if len(days) == 0 || days[len(days)-1].Date != date {
+				days = append(days, HistoryDay{Date: date})
+			}
+			day := &days[len(days)-1]
+			day.Scans++
+			day.Unused = scan.Unused
+			day.NewlyUnused += scan.NewlyUnused
+			day.Resolved += scan.Resolved
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state store %s: %w", path, err)
+	}
+	return days, nil
+}
+
+// GetResourceHistory renders the periods resource was unused in, recorded
+// in the state store at path, for kor history. resource is kind/name or
+// namespace/kind/name like the --quiet lines.
+func GetResourceHistory(path, resource, outputFormat string, now time.Time) (string, error) {
+	histories, err := loadResourceHistories(path, resource)
+	if err != nil {
+		return "", err
+	}
+	if len(histories) == 0 {
+		return "", fmt.Errorf("%s was never reported unused in state store %s", resource, path)
+	}
+	if outputFormat != "table" {
+		return marshalHistory(histories, outputFormat)
+	}
+
+	var output strings.Builder
+	for _, history := range histories {
+		if since, ok := history.UnusedSince(); ok {
+			fmt.Fprintf(&output, "%s has been unused for %s, since %s\n", history.title(), duration.HumanDuration(now.Sub(since)), since.UTC().Format(time.RFC3339))
+		} else {
+			last := history.Periods[len(history.Periods)-1]
+			fmt.Fprintf(&output, "%s is not unused anymore, since %s\n", history.title(), last.Resolved.UTC().Format(time.RFC3339))
+		}
+		table := tablewriter.NewWriter(&output)
+		table.SetHeader([]string{"#", "UNUSED SINCE", "LAST SEEN", "RESOLVED", "SCANS", "DURATION"})
+		for index, period := range history.Periods {
+			resolved, end := "<none>", now
+			if period.Resolved != nil {
+				resolved, end = period.Resolved.UTC().Format(time.RFC3339), *period.Resolved
+			}
+			table.Append(getTableRow(index, period.Since.UTC().Format(time.RFC3339), period.LastSeen.UTC().Format(time.RFC3339), resolved, fmt.Sprintf("%d", period.Scans), duration.HumanDuration(end.Sub(period.Since))))
+		}
+		table.Render()
+		if history.Reason != "" {
+			fmt.Fprintf(&output, "Reason: %s\n", history.Reason)
+		}
+		output.WriteString("\n")
+	}
+	return output.String(), nil
+}
+
+// GetHistoryTrend renders the number of unused resources per day of the
+// scans recorded in the state store at path since then, for kor history.
+func GetHistoryTrend(path string, since time.Time, outputFormat string) (string, error) {
+	days, err := loadHistoryTrend(path, since)
+	if err != nil {
+		return "", err
+	}
+	if outputFormat != "table" {
+		return marshalHistory(days, outputFormat)
+	}
+	if len(days) == 0 {
+		return fmt.Sprintf("No scans recorded since %s\n", since.UTC().Format(time.RFC3339)), nil
+	}
+
+	var buf strings.Builder
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"#", "DATE", "SCANS", "UNUSED", "NEWLY UNUSED", "RESOLVED"})
+	var scans, newlyUnused, resolved int
+	for index, day := range days {
+		table.Append(getTableRow(index, day.Date, fmt.Sprintf("%d", day.Scans), fmt.Sprintf("%d", day.Unused), fmt.Sprintf("%d", day.NewlyUnused), fmt.Sprintf("%d", day.Resolved)))
+		scans += day.Scans
+		newlyUnused += day.NewlyUnused
+		resolved += day.Resolved
+	}
+	table.SetFooter([]string{"", "Total", fmt.Sprintf("%d", scans), fmt.Sprintf("%d", days[len(days)-1].Unused), fmt.Sprintf("%d", newlyUnused), fmt.Sprintf("%d", resolved)})
+	table.Render()
+	return fmt.Sprintf("Unused resources per day since %s:\n%s", since.UTC().Format(time.DateOnly), buf.String()), nil
+}
+
+func marshalHistory(history interface{}, outputFormat string) (string, error) {
+	response, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	switch outputFormat {
+	case "json":
+	case "yaml":
+		if response, err = yaml.JSONToYAML(response); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unsupported output format for the history: %s", outputFormat)
+	}
+	return string(response), nil
+}
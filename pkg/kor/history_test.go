@@ -0,0 +1,225 @@
+package kor
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func TestRecordHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "kor.db")
+	start := time.Date(2026, 7, 1, 10, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	everywhere := map[string][]string{"": {"default", "other", ""}}
+
+	cm := unusedResource{Namespace: "default", Kind: "ConfigMap", Name: "cm-1", Reason: "ConfigMap is not used"}
+	secret := unusedResource{Namespace: "default", Kind: "Secret", Name: "secret-1"}
+	pv := unusedResource{Kind: "Pv", Name: "pv-1"}
+	scans := []struct {
+		findings []unusedResource
+		scope    historyScope
+	}{
+		{[]unusedResource{cm, secret, pv}, newHistoryScope(nil, everywhere)},
+		// The secret is resolved, the scan of ConfigMaps does not cover the PV
+		{[]unusedResource{cm}, newHistoryScope([]string{"configmap", "secret"}, everywhere)},
+		// A scan of other namespaces covers none of them
+		{nil, newHistoryScope(nil, map[string][]string{"": {"other"}})},
+		{[]unusedResource{cm, secret}, newHistoryScope(nil, everywhere)},
+	}
+	for i, scan := range scans {
+		if err := recordHistory(path, scan.findings, scan.scope, start.Add(time.Duration(i)*day)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	histories, err := loadResourceHistories(path, "default/configmap/cm-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(histories) != 1 || len(histories[0].Periods) != 1 || histories[0].Periods[0].Scans != 3 || histories[0].Reason != cm.Reason {
+		t.Fatalf("Expected a single period of 3 scans for the ConfigMap, got %+v", histories)
+	}
+	if since, ok := histories[0].UnusedSince(); !ok || !since.Equal(start) {
+		t.Errorf("Expected the ConfigMap unused since the first scan, got %v %v", since, ok)
+	}
+
+	histories, err = loadResourceHistories(path, "secret/secret-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(histories) != 1 || len(histories[0].Periods) != 2 || histories[0].Periods[0].Resolved == nil || !histories[0].Periods[0].Resolved.Equal(start.Add(day)) {
+		t.Fatalf("Expected the secret to be resolved by the second scan and unused again, got %+v", histories)
+	}
+	if since, _ := histories[0].UnusedSince(); !since.Equal(start.Add(3 * day)) {
+		t.Errorf("Expected the secret unused since the last scan, got %v", since)
+	}
+
+	histories, err = loadResourceHistories(path, "pv/pv-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(histories) != 1 || len(histories[0].Periods) != 1 || histories[0].Periods[0].Resolved == nil || !histories[0].Periods[0].Resolved.Equal(start.Add(3*day)) {
+		t.Errorf("Expected the PV to be resolved by the last scan of every kind, got %+v", histories)
+	}
+
+	days, err := loadHistoryTrend(path, start.Add(day))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []HistoryDay{
+		{Date: "2026-07-02", Scans: 1, Unused: 1, Resolved: 1},
+		{Date: "2026-07-03", Scans: 1},
+		{Date: "2026-07-04", Scans: 1, Unused: 2, NewlyUnused: 1, Resolved: 1},
+	}
+	if len(days) != len(expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, days)
+	}
+	for i := range expected {
+		if days[i] != expected[i] {
+			t.Errorf("Expected %+v, got %+v", expected[i], days[i])
+		}
+	}
+
+	output, err := GetResourceHistory(path, "default/cm/cm-1", "table", start.Add(90*day))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(output, "ConfigMap default/cm-1 has been unused for 90d, since 2026-07-01T10:00:00Z\n") || !strings.Contains(output, "Reason: ConfigMap is not used") {
+		t.Errorf("Unexpected history of the ConfigMap:\n%s", output)
+	}
+	if _, err := GetResourceHistory(path, "configmap/missing", "table", start); err == nil {
+		t.Error("Expected an error for a resource that was never unused")
+	}
+	if _, err := GetResourceHistory(path, "missing", "table", start); err == nil {
+		t.Error("Expected an error for an invalid resource")
+	}
+	output, err = GetHistoryTrend(path, start, "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output, `"date": "2026-07-01"`) {
+		t.Errorf("Expected the trend as JSON, got\n%s", output)
+	}
+	if _, err := GetHistoryTrend(filepath.Join(t.TempDir(), "missing.db"), start, "table"); err == nil {
+		t.Error("Expected an error for a missing state store")
+	}
+}
+
+func TestRecordHistoryScanErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kor.db")
+	defer resetReportedResources()
+	everywhere := map[string][]string{"": {"default", "other", ""}}
+	cm := unusedResource{Namespace: "default", Kind: "ConfigMap", Name: "cm-1"}
+	now := time.Now()
+	if err := recordHistory(path, []unusedResource{cm}, newHistoryScope(nil, everywhere), now); err != nil {
+		t.Fatal(err)
+	}
+
+	resetReportedResources()
+	recordScanError("ConfigMap", "default", errors.New("timeout"))
+	if err := recordHistory(path, nil, newHistoryScope(nil, everywhere), now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	histories, err := loadResourceHistories(path, "configmap/cm-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := histories[0].UnusedSince(); !ok {
+		t.Errorf("Expected a failed scan of the namespace not to resolve the ConfigMap, got %+v", histories[0])
+	}
+}
+
+func TestRecordHistoryOfRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kor.db")
+	SetStateStore(path)
+	defer SetStateStore("")
+	resetReportedResources()
+	defer resetReportedResources()
+
+	clientset := fake.NewSimpleClientset()
+	if _, err := clientset.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: testNamespace}}, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	recordUnusedResources(map[string]map[string][]ResourceInfo{testNamespace: {"ConfigMap": {{Name: "cm-1"}}}}, "namespace")
+	if err := RecordHistory(context.Background(), []string{"configmap"}, &filters.Options{}, clientset); err != nil {
+		t.Fatal(err)
+	}
+	histories, err := loadResourceHistories(path, testNamespace+"/configmap/cm-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(histories) != 1 {
+		t.Errorf("Expected the ConfigMap reported by the run to be recorded, got %+v", histories)
+	}
+}
+
+func TestUnusedFor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kor.db")
+	now := time.Now()
+	day := 24 * time.Hour
+	everywhere := map[string][]string{"": {testNamespace, ""}}
+	old := unusedResource{Namespace: testNamespace, Kind: "ConfigMap", Name: "cm-old"}
+	recent := unusedResource{Namespace: testNamespace, Kind: "ConfigMap", Name: "cm-recent"}
+	if err := recordHistory(path, []unusedResource{old}, newHistoryScope(nil, everywhere), now.Add(-8*day)); err != nil {
+		t.Fatal(err)
+	}
+	if err := recordHistory(path, []unusedResource{old, recent}, newHistoryScope(nil, everywhere), now.Add(-day)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetUnusedFor(7 * day); err == nil {
+		t.Error("Expected --unused-for to require a state store")
+	}
+	SetStateStore(path)
+	defer SetStateStore("")
+	if err := SetUnusedFor(7 * day); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = SetUnusedFor(0) }()
+	resetReportedResources()
+	defer resetReportedResources()
+
+	clientset := fake.NewSimpleClientset()
+	for _, name := range []string{"cm-old", "cm-recent"} {
+		if _, err := clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), CreateTestConfigmap(testNamespace, name, AppLabels), metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating fake configmap: %v", err)
+		}
+	}
+	diff, err := DeleteResource(context.Background(), []ResourceInfo{{Name: "cm-old"}, {Name: "cm-recent"}}, clientset, testNamespace, "ConfigMap", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff) != 2 || diff[0].Name != "cm-old"+deletedNameSuffix || diff[1].Name != "cm-recent" {
+		t.Errorf("Expected only the ConfigMap unused for 7d to be deleted, got %+v", diff)
+	}
+	if _, err := clientset.CoreV1().ConfigMaps(testNamespace).Get(context.TODO(), "cm-recent", metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected the recently unused ConfigMap to be kept, got %v", err)
+	}
+
+	resources := map[string]map[string][]ResourceInfo{testNamespace: {"ConfigMap": append(diff, ResourceInfo{Name: "cm-new"})}}
+	recordUnusedResources(resources, "namespace")
+	if reported := resources[testNamespace]["ConfigMap"]; len(reported) != 1 || reported[0].Name != "cm-old"+deletedNameSuffix {
+		t.Errorf("Expected only the ConfigMap unused for 7d to be reported, got %+v", reported)
+	}
+	if len(reportedResources) != 1 || len(historyFindings) != 3 {
+		t.Errorf("Expected 1 reported resource and 3 recorded ones, got %+v and %+v", reportedResources, historyFindings)
+	}
+
+	// Nothing has been unused for long enough before the first scan
+	resetReportedResources()
+	SetStateStore(filepath.Join(t.TempDir(), "missing.db"))
+	resources = map[string]map[string][]ResourceInfo{testNamespace: {"ConfigMap": {{Name: "cm-old"}}}}
+	recordUnusedResources(resources, "namespace")
+	if len(resources[testNamespace]["ConfigMap"]) != 0 {
+		t.Errorf("Expected no resource reported without a state store yet, got %+v", resources)
+	}
+}
@@ -5,19 +5,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
+	"log/slog"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
-	"k8s.io/utils/strings/slices"
 
 	"github.com/yonahd/kor/pkg/common"
 	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
 )
 
-func getDeploymentNames(clientset kubernetes.Interface, namespace string) ([]string, error) {
-	deployments, err := clientset.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{})
+func getDeploymentNames(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]string, error) {
+	deployments, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.AppsV1().Deployments(namespace).List)
 	if err != nil {
 		return nil, err
 	}
@@ -28,8 +28,8 @@ func getDeploymentNames(clientset kubernetes.Interface, namespace string) ([]str
 	return names, nil
 }
 
-func getStatefulSetNames(clientset kubernetes.Interface, namespace string) ([]string, error) {
-	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(context.TODO(), metav1.ListOptions{})
+func getStatefulSetNames(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]string, error) {
+	statefulSets, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.AppsV1().StatefulSets(namespace).List)
 	if err != nil {
 		return nil, err
 	}
@@ -40,22 +40,24 @@ func getStatefulSetNames(clientset kubernetes.Interface, namespace string) ([]st
 	return names, nil
 }
 
-func processNamespaceHpas(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
-	deploymentNames, err := getDeploymentNames(clientset, namespace)
+func processNamespaceHpas(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	deploymentNames, err := getDeploymentNames(ctx, clientset, namespace)
 	if err != nil {
 		return nil, err
 	}
 
-	statefulsetNames, err := getStatefulSetNames(clientset, namespace)
+	statefulsetNames, err := getStatefulSetNames(ctx, clientset, namespace)
 	if err != nil {
 		return nil, err
 	}
 
-	hpas, err := clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	hpas, err := utils.ListAll(ctx, filterOpts.ListOptions(), clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).List)
 	if err != nil {
 		return nil, err
 	}
 
+	deployments, statefulSets := newNameSet(deploymentNames), newNameSet(statefulsetNames)
+
 	var unusedHpas []ResourceInfo
 	for _, hpa := range hpas.Items {
 		if pass, _ := filter.SetObject(&hpa).Run(filterOpts); pass {
@@ -69,11 +71,11 @@ func processNamespaceHpas(clientset kubernetes.Interface, namespace string, filt
 
 		switch hpa.Spec.ScaleTargetRef.Kind {
 		case "Deployment":
-			if !slices.Contains(deploymentNames, hpa.Spec.ScaleTargetRef.Name) {
+			if !deployments.has(hpa.Spec.ScaleTargetRef.Name) {
 				unusedHpas = append(unusedHpas, ResourceInfo{Name: hpa.Name, Reason: "Scale target Deployment does not exist"})
 			}
 		case "StatefulSet":
-			if !slices.Contains(statefulsetNames, hpa.Spec.ScaleTargetRef.Name) {
+			if !statefulSets.has(hpa.Spec.ScaleTargetRef.Name) {
 				unusedHpas = append(unusedHpas, ResourceInfo{Name: hpa.Name, Reason: "Scale target StatefulSet does not exist"})
 			}
 		}
@@ -81,17 +83,21 @@ func processNamespaceHpas(clientset kubernetes.Interface, namespace string, filt
 	return unusedHpas, nil
 }
 
-func GetUnusedHpas(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+func GetUnusedHpas(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	clientset = scanClientset(clientset)
 	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceHpas(clientset, namespace, filterOpts)
+	for _, scan := range scanNamespaces(filterOpts.Namespaces(ctx, clientset), func(namespace string) ([]ResourceInfo, error) {
+		return processNamespaceHpas(ctx, clientset, namespace, filterOpts)
+	}) {
+		namespace, diff, err := scan.namespace, scan.result, scan.err
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
+			recordScanError("Hpa", namespace, err)
 			continue
 		}
-		if opts.DeleteFlag {
-			if diff, err = DeleteResource(diff, clientset, namespace, "HPA", opts.NoInteractive); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to delete HPA %s in namespace %s: %v\n", diff, namespace, err)
+		exportManifests(ctx, clientset, namespace, "HPA", diff, opts)
+		if deleteEnabled(opts, "HPA") {
+			if diff, err = DeleteResource(ctx, diff, clientset, namespace, "HPA", opts.NoInteractive); err != nil {
+				slog.Error("Failed to delete HPA", "name", diff, "namespace", namespace, "error", err)
 			}
 		}
 		switch opts.GroupBy {
@@ -103,12 +109,15 @@ func GetUnusedHpas(filterOpts *filters.Options, clientset kubernetes.Interface,
 		}
 	}
 
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
 	var outputBuffer bytes.Buffer
 	var jsonResponse []byte
 	switch outputFormat {
 	case "table":
 		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
+	default:
 		var err error
 		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
 			return "", err
@@ -67,7 +67,7 @@ func createTestHpas(t *testing.T) *fake.Clientset {
 func TestExtractUnusedHpas(t *testing.T) {
 	clientset := createTestHpas(t)
 
-	unusedHpas, err := processNamespaceHpas(clientset, testNamespace, &filters.Options{})
+	unusedHpas, err := processNamespaceHpas(context.Background(), clientset, testNamespace, &filters.Options{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -93,7 +93,7 @@ func TestGetUnusedHpasStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedHpas(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedHpas(context.Background(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedHpasStructured: %v", err)
 	}
@@ -0,0 +1,329 @@
+package kor
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// incrementalCache is the file the scans keep the resources they listed in,
+// see SetIncrementalCache.
+var incrementalCache string
+
+// incrementalWatchTimeout is how long the watch of the changes since the last
+// scan may take to reach the current resourceVersion of the resource, it is
+// listed again otherwise.
+var incrementalWatchTimeout = 5 * time.Second
+
+// SetIncrementalCache makes the scans keep the namespaced resources they list
+// across the cluster, with their resourceVersion, in the file at path. The
+// next scans watch the changes made since then instead of listing them
+// again, which makes frequent scheduled scans cheap. Secrets are never
+// written to the file, they are listed at every scan. An empty path disables
+// the cache.
+func SetIncrementalCache(path string) {
+	incrementalCache = path
+}
+
+// incrementalCacheFile is the content of the cache file.
+type incrementalCacheFile struct {
+	Resources []cachedResource `json:"resources"`
+}
+
+// cachedResource is a resource across the cluster as of resourceVersion.
+type cachedResource struct {
+	Group           string          `json:"group,omitempty"`
+	Version         string          `json:"version"`
+	Resource        string          `json:"resource"`
+	LabelSelector   string          `json:"labelSelector,omitempty"`
+	FieldSelector   string          `json:"fieldSelector,omitempty"`
+	ResourceVersion string          `json:"resourceVersion"`
+	List            json.RawMessage `json:"list"`
+}
+
+func (r cachedResource) key() clusterListKey {
+	return clusterListKey{
+		gvr:           schema.GroupVersionResource{Group: r.Group, Version: r.Version, Resource: r.Resource},
+		labelSelector: r.LabelSelector,
+		fieldSelector: r.FieldSelector,
+	}
+}
+
+// incrementalLists lists each resource across the cluster once per scan like
+// clusterLists, bringing the resources of the cache file up to date from the
+// watch events since the last scan.
+type incrementalLists struct {
+	path string
+
+	mu     sync.Mutex
+	cached map[clusterListKey]cachedResource
+	lists  map[clusterListKey]*clusterList
+}
+
+func newIncrementalLists(path string) *incrementalLists {
+	return &incrementalLists{path: path, lists: make(map[clusterListKey]*clusterList)}
+}
+
+func (c *incrementalLists) objects(ctx context.Context, gvr schema.GroupVersionResource, namespace string, options metav1.ListOptions, cluster clusterResource) ([]runtime.Object, bool, error) {
+	// The data of the Secrets is not written to disk
+	if gvr.Resource == "secrets" {
+		return nil, false, nil
+	}
+	key := clusterListKey{gvr: gvr, labelSelector: options.LabelSelector, fieldSelector: options.FieldSelector}
+	c.mu.Lock()
+	c.load()
+	list, ok := c.lists[key]
+	if !ok {
+		list = &clusterList{}
+		c.lists[key] = list
+	}
+	c.mu.Unlock()
+
+	list.once.Do(func() {
+		var items []runtime.Object
+		if items, list.err = c.refresh(ctx, key, cluster); list.err != nil {
+			return
+		}
+		list.namespaces, list.err = byNamespace(items)
+	})
+	// kor may only be allowed to list some namespaces
+	if apierrors.IsForbidden(list.err) {
+		return nil, false, nil
+	}
+	return list.namespaces[namespace], list.err == nil, list.err
+}
+
+// load reads the cache file the first time, a missing or unreadable file
+// makes the resources be listed again.
+func (c *incrementalLists) load() {
+	if c.cached != nil {
+		return
+	}
+	c.cached = make(map[clusterListKey]cachedResource)
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("Failed to read the incremental cache, listing the resources again", "path", c.path, "error", err)
+		}
+		return
+	}
+	var file incrementalCacheFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		slog.Warn("Failed to read the incremental cache, listing the resources again", "path", c.path, "error", err)
+		return
+	}
+	for _, resource := range file.Resources {
+		c.cached[resource.key()] = resource
+	}
+}
+
+// refresh returns the resource of key across the cluster, from the cache
+// file and the changes since, or listed again when the file has none or the
+// API server no longer has the changes since its resourceVersion.
+func (c *incrementalLists) refresh(ctx context.Context, key clusterListKey, cluster clusterResource) ([]runtime.Object, error) {
+	options := metav1.ListOptions{LabelSelector: key.labelSelector, FieldSelector: key.fieldSelector}
+	c.mu.Lock()
+	cached, ok := c.cached[key]
+	c.mu.Unlock()
+
+	var items []runtime.Object
+	var resourceVersion string
+	if ok {
+		items, resourceVersion, ok = watchChanges(ctx, cluster, options, cached)
+	}
+	if !ok {
+		list, err := cluster.list(ctx, options)
+		if err != nil {
+			return nil, err
+		}
+		if items, err = meta.ExtractList(list); err != nil {
+			return nil, err
+		}
+		listMeta, err := meta.ListAccessor(list)
+		if err != nil {
+			return nil, err
+		}
+		resourceVersion = listMeta.GetResourceVersion()
+	}
+
+	if err := c.store(key, cluster, items, resourceVersion); err != nil {
+		slog.Warn("Failed to write the incremental cache", "path", c.path, "error", err)
+	}
+	return items, nil
+}
+
+// watchChanges applies the changes made to the resource since the cached
+// resourceVersion to its cached objects. ok is false when the changes cannot
+// be watched.
+func watchChanges(ctx context.Context, cluster clusterResource, options metav1.ListOptions, cached cachedResource) (items []runtime.Object, resourceVersion string, ok bool) {
+	if cached.ResourceVersion == "" {
+		return nil, "", false
+	}
+	list := cluster.newList()
+	if err := json.Unmarshal(cached.List, list); err != nil {
+		return nil, "", false
+	}
+	cachedItems, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, "", false
+	}
+	objects := make(map[types.NamespacedName]runtime.Object, len(cachedItems))
+	for _, item := range cachedItems {
+		name, err := objectName(item)
+		if err != nil {
+			return nil, "", false
+		}
+		objects[name] = item
+	}
+
+	// The cache is up to date once the watch reaches the resourceVersion of
+	// the resource now, the changes before it may still be on their way
+	current, err := cluster.resourceVersion(ctx, options)
+	if err != nil {
+		return nil, "", false
+	}
+	resourceVersion = cached.ResourceVersion
+	reached, err := resourceVersionReached(resourceVersion, current)
+	if err != nil {
+		return nil, "", false
+	}
+
+	if !reached {
+		options.ResourceVersion = cached.ResourceVersion
+		// The bookmarks tell that the watch reached current without changes to the resource
+		options.AllowWatchBookmarks = true
+		watcher, err := cluster.watch(ctx, options)
+		if err != nil {
+			return nil, "", false
+		}
+		defer watcher.Stop()
+
+		timeout := time.NewTimer(incrementalWatchTimeout)
+		defer timeout.Stop()
+		for !reached {
+			select {
+			case <-ctx.Done():
+				return nil, "", false
+			case <-timeout.C:
+				return nil, "", false
+			case event, open := <-watcher.ResultChan():
+				if !open {
+					return nil, "", false
+				}
+				// Typically the resourceVersion is too old and the changes are gone
+				if event.Type == watch.Error {
+					return nil, "", false
+				}
+				accessor, err := meta.Accessor(event.Object)
+				if err != nil {
+					return nil, "", false
+				}
+				resourceVersion = accessor.GetResourceVersion()
+				name := types.NamespacedName{Namespace: accessor.GetNamespace(), Name: accessor.GetName()}
+				switch event.Type {
+				case watch.Added, watch.Modified:
+					objects[name] = event.Object
+				case watch.Deleted:
+					delete(objects, name)
+				}
+				if reached, err = resourceVersionReached(resourceVersion, current); err != nil {
+					return nil, "", false
+				}
+			}
+		}
+	}
+
+	// Listed in the order of the API server
+	names := make([]types.NamespacedName, 0, len(objects))
+	for name := range objects {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, func(a, b types.NamespacedName) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+	})
+	items = make([]runtime.Object, 0, len(names))
+	for _, name := range names {
+		items = append(items, objects[name])
+	}
+	return items, resourceVersion, true
+}
+
+// resourceVersionReached tells whether resourceVersion is target or a later
+// one. The API server of etcd orders them as integers, other ones fail.
+func resourceVersionReached(resourceVersion, target string) (bool, error) {
+	version, err := strconv.ParseUint(resourceVersion, 10, 64)
+	if err != nil {
+		return false, err
+	}
+	targetVersion, err := strconv.ParseUint(target, 10, 64)
+	if err != nil {
+		return false, err
+	}
+	return version >= targetVersion, nil
+}
+
+func objectName(object runtime.Object) (types.NamespacedName, error) {
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		return types.NamespacedName{}, err
+	}
+	return types.NamespacedName{Namespace: accessor.GetNamespace(), Name: accessor.GetName()}, nil
+}
+
+// store writes the resource of key as of resourceVersion to the cache file,
+// with the other resources of the file.
+func (c *incrementalLists) store(key clusterListKey, cluster clusterResource, items []runtime.Object, resourceVersion string) error {
+	list := cluster.newList()
+	if err := meta.SetList(list, items); err != nil {
+		return err
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cached[key] = cachedResource{
+		Group:           key.gvr.Group,
+		Version:         key.gvr.Version,
+		Resource:        key.gvr.Resource,
+		LabelSelector:   key.labelSelector,
+		FieldSelector:   key.fieldSelector,
+		ResourceVersion: resourceVersion,
+		List:            data,
+	}
+	file := incrementalCacheFile{Resources: make([]cachedResource, 0, len(c.cached))}
+	for _, resource := range c.cached {
+		file.Resources = append(file.Resources, resource)
+	}
+	slices.SortFunc(file.Resources, func(a, b cachedResource) int {
+		return cmp.Or(cmp.Compare(a.Group, b.Group), cmp.Compare(a.Resource, b.Resource), cmp.Compare(a.LabelSelector, b.LabelSelector), cmp.Compare(a.FieldSelector, b.FieldSelector))
+	})
+	content, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+	// Written aside and renamed so a scan never reads a partial file
+	temporary := c.path + ".tmp"
+	if err := os.WriteFile(temporary, content, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(temporary, c.path)
+}
@@ -0,0 +1,114 @@
+package kor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestIncrementalCache(t *testing.T) {
+	defer SetIncrementalCache("")
+	defer func(timeout time.Duration) { incrementalWatchTimeout = timeout }(incrementalWatchTimeout)
+	incrementalWatchTimeout = time.Second
+	path := filepath.Join(t.TempDir(), "resources.json")
+	SetIncrementalCache(path)
+
+	clientset := createClusterListsClientset(t)
+	// The fake clientset lists without a resourceVersion
+	current := "5"
+	fullLists := 0
+	clientset.PrependReactor("list", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		list, err := clientset.Tracker().List(action.GetResource(), corev1.SchemeGroupVersion.WithKind("ConfigMap"), action.GetNamespace())
+		if err != nil {
+			return true, nil, err
+		}
+		if action.(k8stesting.ListActionImpl).GetListOptions().Limit != 1 {
+			fullLists++
+		}
+		list.(*corev1.ConfigMapList).ResourceVersion = current
+		return true, list, nil
+	})
+	var watches []string
+	var changes *watch.FakeWatcher
+	clientset.PrependWatchReactor("configmaps", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		watches = append(watches, action.(k8stesting.WatchAction).GetWatchRestrictions().ResourceVersion)
+		return true, changes, nil
+	})
+
+	if names := unusedConfigMapNames(t, context.TODO(), scanClientset(clientset), "ns-1"); !slices.Equal(names, []string{"configmap-ns-1"}) {
+		t.Fatalf("Expected the configmaps listed across the cluster, got %v", names)
+	}
+	if content, err := os.ReadFile(path); err != nil || !strings.Contains(string(content), `"resourceVersion":"5"`) {
+		t.Fatalf("Expected the configmaps to be written to the cache, got %v, %s", err, content)
+	}
+
+	current = "8"
+	added := CreateTestConfigmap("ns-1", "configmap-new", AppLabels)
+	added.ResourceVersion = "7"
+	deleted := CreateTestConfigmap("ns-1", "configmap-ns-1", AppLabels)
+	deleted.ResourceVersion = "8"
+	changes = watch.NewFakeWithChanSize(2, false)
+	changes.Add(added)
+	changes.Delete(deleted)
+	if names := unusedConfigMapNames(t, context.TODO(), scanClientset(clientset), "ns-1"); !slices.Equal(names, []string{"configmap-new"}) {
+		t.Fatalf("Expected the changes to be applied to the cache, got %v", names)
+	}
+	if fullLists != 1 || !slices.Equal(watches, []string{"5"}) {
+		t.Errorf("Expected the changes since the cached resourceVersion to be watched instead of a list, got %d lists and %v", fullLists, watches)
+	}
+
+	// The changes come after a pause, the watch waits for the resourceVersion of the list
+	current = "10"
+	late := CreateTestConfigmap("ns-1", "configmap-late", AppLabels)
+	late.ResourceVersion = "9"
+	changes = watch.NewFake()
+	go func(changes *watch.FakeWatcher) {
+		time.Sleep(300 * time.Millisecond)
+		changes.Add(late)
+		time.Sleep(300 * time.Millisecond)
+		changes.Action(watch.Bookmark, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "10"}})
+	}(changes)
+	if names := unusedConfigMapNames(t, context.TODO(), scanClientset(clientset), "ns-1"); !slices.Equal(names, []string{"configmap-late", "configmap-new"}) {
+		t.Fatalf("Expected the delayed changes to be applied to the cache, got %v", names)
+	}
+	if content, err := os.ReadFile(path); err != nil || !strings.Contains(string(content), `"resourceVersion":"10"`) {
+		t.Fatalf("Expected the cache to be written as of the bookmark, got %v, %s", err, content)
+	}
+
+	if names := unusedConfigMapNames(t, context.TODO(), scanClientset(clientset), "ns-1"); !slices.Equal(names, []string{"configmap-late", "configmap-new"}) {
+		t.Fatalf("Expected the cache to be used, got %v", names)
+	}
+	if fullLists != 1 || !slices.Equal(watches, []string{"5", "8"}) {
+		t.Errorf("Expected an up to date cache not to be watched, got %d lists and %v", fullLists, watches)
+	}
+
+	current = "12"
+	incrementalWatchTimeout = 20 * time.Millisecond
+	changes = watch.NewFake()
+	if names := unusedConfigMapNames(t, context.TODO(), scanClientset(clientset), "ns-0"); !slices.Equal(names, []string{"configmap-ns-0"}) {
+		t.Fatalf("Expected the configmaps listed again, got %v", names)
+	}
+	if fullLists != 2 || !slices.Equal(watches, []string{"5", "8", "10"}) {
+		t.Errorf("Expected a watch not reaching the resourceVersion in time to be listed again, got %d lists and %v", fullLists, watches)
+	}
+
+	current = "13"
+	changes = watch.NewFakeWithChanSize(1, false)
+	changes.Error(&metav1.Status{Status: metav1.StatusFailure, Reason: metav1.StatusReasonExpired})
+	if names := unusedConfigMapNames(t, context.TODO(), scanClientset(clientset), "ns-0"); !slices.Equal(names, []string{"configmap-ns-0"}) {
+		t.Fatalf("Expected the configmaps listed again, got %v", names)
+	}
+	if fullLists != 3 || !slices.Equal(watches, []string{"5", "8", "10", "12"}) {
+		t.Errorf("Expected an expired resourceVersion to be listed again, got %d lists and %v", fullLists, watches)
+	}
+}
@@ -0,0 +1,185 @@
+package kor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// informerCache makes the exporter, the daemon, the operator and the API
+// server scan from informer caches, see SetInformerCache.
+var informerCache bool
+
+// SetInformerCache makes the long-running modes of kor watch the namespaced
+// resources the detectors look at and scan from shared informer caches kept
+// up to date by the watch events, instead of listing the whole cluster again
+// at every scan. The caches hold the resources of the whole cluster in
+// memory for as long as kor runs.
+func SetInformerCache(enabled bool) {
+	informerCache = enabled
+}
+
+// cachedClientset returns the clientset the long-running modes scan with,
+// serving the List calls from informer caches with SetInformerCache. The
+// informers run until ctx is done.
+func cachedClientset(ctx context.Context, clientset kubernetes.Interface) kubernetes.Interface {
+	if !informerCache || clientset == nil {
+		return clientset
+	}
+	return &listSourceClientset{Interface: clientset, source: &informerSource{
+		ctx:       ctx,
+		clientset: clientset,
+		informers: make(map[schema.GroupVersionResource]*resourceInformer),
+	}}
+}
+
+// informerSource serves the objects of the namespaced resources from an
+// informer per resource, started on the first scan listing the resource.
+type informerSource struct {
+	ctx       context.Context
+	clientset kubernetes.Interface
+
+	mu        sync.Mutex
+	informers map[schema.GroupVersionResource]*resourceInformer
+}
+
+// resourceInformer is the informer of a resource, or the reason it cannot
+// serve the scans.
+type resourceInformer struct {
+	synced   sync.Once
+	informer cache.SharedIndexInformer
+	err      error
+}
+
+func (s *informerSource) objects(ctx context.Context, gvr schema.GroupVersionResource, namespace string, options metav1.ListOptions, _ clusterResource) ([]runtime.Object, bool, error) {
+	// The caches are only matched against the metadata fields
+	fieldSelector, err := fields.ParseSelector(options.FieldSelector)
+	if err != nil || !metadataFieldSelector(fieldSelector) {
+		return nil, false, nil
+	}
+	selector, err := labels.Parse(options.LabelSelector)
+	if err != nil {
+		return nil, false, nil
+	}
+	informer := s.informer(ctx, gvr)
+	if informer.err != nil {
+		return nil, false, nil
+	}
+
+	cached, err := informer.informer.GetIndexer().ByIndex(cache.NamespaceIndex, namespace)
+	if err != nil {
+		return nil, false, err
+	}
+	objects := make([]runtime.Object, 0, len(cached))
+	for _, item := range cached {
+		object, ok := item.(runtime.Object)
+		if !ok {
+			continue
+		}
+		accessor, err := meta.Accessor(object)
+		if err != nil {
+			return nil, false, err
+		}
+		metadataFields := fields.Set{"metadata.name": accessor.GetName(), "metadata.namespace": accessor.GetNamespace()}
+		if selector.Matches(labels.Set(accessor.GetLabels())) && fieldSelector.Matches(metadataFields) {
+			objects = append(objects, object)
+		}
+	}
+	return objects, true, nil
+}
+
+// metadataFieldSelector reports whether selector only selects by the name
+// and namespace of the objects.
+func metadataFieldSelector(selector fields.Selector) bool {
+	for _, requirement := range selector.Requirements() {
+		if requirement.Field != "metadata.name" && requirement.Field != "metadata.namespace" {
+			return false
+		}
+	}
+	return true
+}
+
+// informer returns the informer of gvr, starting it and waiting for its
+// cache to be filled the first time. A resource kor may not watch across the
+// cluster is listed from the API server from then on.
+func (s *informerSource) informer(ctx context.Context, gvr schema.GroupVersionResource) *resourceInformer {
+	s.mu.Lock()
+	informer, ok := s.informers[gvr]
+	if !ok {
+		informer = &resourceInformer{}
+		s.informers[gvr] = informer
+	}
+	s.mu.Unlock()
+
+	informer.synced.Do(func() {
+		factory := informers.NewSharedInformerFactory(s.clientset, 0)
+		generic, err := factory.ForResource(gvr)
+		if err != nil {
+			informer.err = err
+			return
+		}
+		informer.informer = generic.Informer()
+		forbidden := make(chan struct{})
+		var forbid sync.Once
+		if err := informer.informer.SetWatchErrorHandler(func(reflector *cache.Reflector, err error) {
+			if apierrors.IsForbidden(err) {
+				forbid.Do(func() { close(forbidden) })
+				return
+			}
+			cache.DefaultWatchErrorHandler(reflector, err)
+		}); err != nil {
+			informer.err = err
+			return
+		}
+
+		// The informer stops with kor, or right away when it may not watch the resource
+		stop := make(chan struct{})
+		go func() {
+			defer close(stop)
+			select {
+			case <-s.ctx.Done():
+			case <-forbidden:
+			}
+		}()
+		factory.Start(stop)
+
+		waited := make(chan struct{})
+		synced := make(chan struct{})
+		go func() {
+			defer close(waited)
+			select {
+			case <-ctx.Done():
+			case <-stop:
+			case <-synced:
+			}
+		}()
+		ok := cache.WaitForCacheSync(waited, informer.informer.HasSynced)
+		close(synced)
+		if ok {
+			return
+		}
+		select {
+		case <-forbidden:
+			slog.Warn("Cannot watch the resource across the cluster, listing it at every scan", "resource", gvr.Resource)
+			informer.err = fmt.Errorf("cannot watch %s across the cluster", gvr.Resource)
+		default:
+			informer.err = context.Cause(ctx)
+			if informer.err == nil {
+				informer.err = context.Cause(s.ctx)
+			}
+		}
+	})
+	return informer
+}
@@ -0,0 +1,103 @@
+package kor
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func unusedConfigMapNames(t *testing.T, ctx context.Context, clientset kubernetes.Interface, namespace string) []string {
+	t.Helper()
+	diff, err := processNamespaceCM(ctx, clientset, namespace, &filters.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, info := range diff {
+		names = append(names, info.Name)
+	}
+	return names
+}
+
+func TestInformerCache(t *testing.T) {
+	defer SetInformerCache(false)
+	SetInformerCache(true)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fakeClientset := createClusterListsClientset(t)
+	clientset := cachedClientset(ctx, fakeClientset)
+
+	if names := unusedConfigMapNames(t, ctx, clientset, "ns-1"); !slices.Equal(names, []string{"configmap-ns-1"}) {
+		t.Fatalf("Expected the unused configmap of the namespace, got %v", names)
+	}
+
+	if _, err := fakeClientset.CoreV1().ConfigMaps("ns-1").Create(context.TODO(), CreateTestConfigmap("ns-1", "configmap-new", AppLabels), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for names := unusedConfigMapNames(t, ctx, clientset, "ns-1"); !slices.Contains(names, "configmap-new"); names = unusedConfigMapNames(t, ctx, clientset, "ns-1") {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the watch events to update the cache, got %v", names)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if lists := listActions(fakeClientset, "configmaps"); len(lists) != 1 || lists[""] != 1 {
+		t.Errorf("Expected the configmaps to be listed once across the cluster, got %v", lists)
+	}
+}
+
+func TestInformerCacheForbidden(t *testing.T) {
+	defer SetInformerCache(false)
+	SetInformerCache(true)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fakeClientset := createClusterListsClientset(t)
+	fakeClientset.PrependReactor("list", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() != "" {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "", errors.New("denied"))
+	})
+	clientset := cachedClientset(ctx, fakeClientset)
+
+	for range 2 {
+		if names := unusedConfigMapNames(t, ctx, clientset, "ns-2"); !slices.Equal(names, []string{"configmap-ns-2"}) {
+			t.Fatalf("Expected the namespace to be listed from the API server, got %v", names)
+		}
+	}
+	if lists := listActions(fakeClientset, "configmaps"); lists["ns-2"] != 2 {
+		t.Errorf("Expected a list of the namespace per scan, got %v", lists)
+	}
+}
+
+func TestInformerCacheFieldSelector(t *testing.T) {
+	defer SetInformerCache(false)
+	SetInformerCache(true)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fakeClientset := createClusterListsClientset(t)
+	clientset := cachedClientset(ctx, fakeClientset)
+
+	diff, err := processNamespaceCM(ctx, clientset, "ns-1", &filters.Options{ExcludeNames: []string{"configmap-ns-1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff) != 0 {
+		t.Errorf("Expected the excluded configmap to be left out, got %v", diff)
+	}
+	if lists := listActions(fakeClientset, "configmaps"); len(lists) != 1 || lists[""] != 1 {
+		t.Errorf("Expected the name selector to be served from the cache, got %v", lists)
+	}
+}
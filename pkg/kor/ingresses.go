@@ -5,7 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
+	"log/slog"
 
 	v1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -14,13 +14,14 @@ import (
 
 	"github.com/yonahd/kor/pkg/common"
 	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
 )
 
-func validateServiceBackend(clientset kubernetes.Interface, namespace string, backend *v1.IngressBackend) bool {
+func validateServiceBackend(ctx context.Context, clientset kubernetes.Interface, namespace string, backend *v1.IngressBackend) bool {
 	if backend.Service != nil {
 		serviceName := backend.Service.Name
 
-		_, err := clientset.CoreV1().Services(namespace).Get(context.TODO(), serviceName, metav1.GetOptions{})
+		_, err := clientset.CoreV1().Services(namespace).Get(ctx, serviceName, metav1.GetOptions{})
 		if err != nil {
 			return false
 		}
@@ -28,8 +29,8 @@ func validateServiceBackend(clientset kubernetes.Interface, namespace string, ba
 	return true
 }
 
-func retrieveUsedIngress(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]string, error) {
-	ingresses, err := clientset.NetworkingV1().Ingresses(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func retrieveUsedIngress(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]string, error) {
+	ingresses, err := utils.ListAll(ctx, filterOpts.ListOptions(), clientset.NetworkingV1().Ingresses(namespace).List)
 	if err != nil {
 		return nil, err
 	}
@@ -44,7 +45,7 @@ func retrieveUsedIngress(clientset kubernetes.Interface, namespace string, filte
 		used := true
 
 		if ingress.Spec.DefaultBackend != nil {
-			used = validateServiceBackend(clientset, namespace, ingress.Spec.DefaultBackend)
+			used = validateServiceBackend(ctx, clientset, namespace, ingress.Spec.DefaultBackend)
 		}
 		for _, rule := range ingress.Spec.Rules {
 			if rule.HTTP == nil {
@@ -52,7 +53,7 @@ func retrieveUsedIngress(clientset kubernetes.Interface, namespace string, filte
 				break
 			}
 			for _, path := range rule.HTTP.Paths {
-				used = validateServiceBackend(clientset, namespace, &path.Backend)
+				used = validateServiceBackend(ctx, clientset, namespace, &path.Backend)
 				if used {
 					break
 				}
@@ -68,8 +69,8 @@ func retrieveUsedIngress(clientset kubernetes.Interface, namespace string, filte
 	return usedIngresses, nil
 }
 
-func retrieveIngressNames(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]string, []string, error) {
-	ingresses, err := clientset.NetworkingV1().Ingresses(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func retrieveIngressNames(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]string, []string, error) {
+	ingresses, err := utils.ListAll(ctx, filterOpts.ListOptions(), clientset.NetworkingV1().Ingresses(namespace).List)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -91,12 +92,12 @@ func retrieveIngressNames(clientset kubernetes.Interface, namespace string, filt
 	return names, unusedIngressNames, nil
 }
 
-func processNamespaceIngresses(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
-	usedIngresses, err := retrieveUsedIngress(clientset, namespace, filterOpts)
+func processNamespaceIngresses(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	usedIngresses, err := retrieveUsedIngress(ctx, clientset, namespace, filterOpts)
 	if err != nil {
 		return nil, err
 	}
-	ingressNames, unusedIngressNames, err := retrieveIngressNames(clientset, namespace, filterOpts)
+	ingressNames, unusedIngressNames, err := retrieveIngressNames(ctx, clientset, namespace, filterOpts)
 	if err != nil {
 		return nil, err
 	}
@@ -117,17 +118,21 @@ func processNamespaceIngresses(clientset kubernetes.Interface, namespace string,
 
 }
 
-func GetUnusedIngresses(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+func GetUnusedIngresses(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	clientset = scanClientset(clientset)
 	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceIngresses(clientset, namespace, filterOpts)
+	for _, scan := range scanNamespaces(filterOpts.Namespaces(ctx, clientset), func(namespace string) ([]ResourceInfo, error) {
+		return processNamespaceIngresses(ctx, clientset, namespace, filterOpts)
+	}) {
+		namespace, diff, err := scan.namespace, scan.result, scan.err
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
+			recordScanError("Ingress", namespace, err)
 			continue
 		}
-		if opts.DeleteFlag {
-			if diff, err = DeleteResource(diff, clientset, namespace, "Ingress", opts.NoInteractive); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to delete Ingress %s in namespace %s: %v\n", diff, namespace, err)
+		exportManifests(ctx, clientset, namespace, "Ingress", diff, opts)
+		if deleteEnabled(opts, "Ingress") {
+			if diff, err = DeleteResource(ctx, diff, clientset, namespace, "Ingress", opts.NoInteractive); err != nil {
+				slog.Error("Failed to delete Ingress", "name", diff, "namespace", namespace, "error", err)
 			}
 		}
 		switch opts.GroupBy {
@@ -139,12 +144,15 @@ func GetUnusedIngresses(filterOpts *filters.Options, clientset kubernetes.Interf
 		}
 	}
 
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
 	var outputBuffer bytes.Buffer
 	var jsonResponse []byte
 	switch outputFormat {
 	case "table":
 		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
+	default:
 		var err error
 		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
 			return "", err
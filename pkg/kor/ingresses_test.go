@@ -65,7 +65,7 @@ func createTestIngresses(t *testing.T) *fake.Clientset {
 func TestRetrieveUsedIngress(t *testing.T) {
 	clientset := createTestIngresses(t)
 
-	usedIngresses, err := retrieveUsedIngress(clientset, testNamespace, &filters.Options{})
+	usedIngresses, err := retrieveUsedIngress(context.Background(), clientset, testNamespace, &filters.Options{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -91,7 +91,7 @@ func TestGetUnusedIngressesStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedIngresses(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedIngresses(context.Background(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedIngressesStructured: %v", err)
 	}
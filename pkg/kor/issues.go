@@ -0,0 +1,278 @@
+package kor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+const (
+	// IssueGroupByNamespace opens an issue per namespace
+	IssueGroupByNamespace = "namespace"
+	// IssueGroupByTeam opens an issue per value of the team label of the namespaces
+	IssueGroupByTeam = "team"
+)
+
+// IssueGroupings lists the values accepted by --issue-group-by.
+var IssueGroupings = []string{IssueGroupByNamespace, IssueGroupByTeam}
+
+const (
+	// issueLabel marks the issues opened by kor, so they are updated instead of duplicated
+	issueLabel = "kor"
+	// issueMaxRows keeps the issues under the size limits of GitHub and Jira
+	issueMaxRows = 200
+	// defaultIssueOwner assigns the groups missing from the owners file
+	defaultIssueOwner = "*"
+)
+
+// IssueOwner assigns the issue of a namespace or team, as listed in the
+// --issue-owners file by namespace or team name.
+type IssueOwner struct {
+	// GitHub logins
+	GitHub []string `json:"github,omitempty"`
+	// Jira account ID on Jira Cloud, user name on Jira Data Center
+	Jira string `json:"jira,omitempty"`
+}
+
+// LoadIssueOwners reads the owners of each namespace or team from a YAML file:
+//
+//	payments:
+//	  github: [alice, bob]
+//	  jira: 5b10ac8d82e05b22cc7d4ef5
+//	"*":
+//	  github: [platform-bot]
+func LoadIssueOwners(path string) (map[string]IssueOwner, error) {
+	owners := make(map[string]IssueOwner)
+	if path == "" {
+		return owners, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read issue owners: %w", err)
+	}
+	if err := yaml.UnmarshalStrict(data, &owners); err != nil {
+		return nil, fmt.Errorf("failed to parse issue owners %s: %w", path, err)
+	}
+	return owners, nil
+}
+
+// ValidateIssueOptions checks the --github-*, --jira-* and --issue-* values.
+func ValidateIssueOptions(opts common.Opts) error {
+	if opts.GitHubRepo == "" && opts.JiraProject == "" {
+		return nil
+	}
+	if opts.IssueGroupBy != IssueGroupByNamespace && opts.IssueGroupBy != IssueGroupByTeam {
+		return fmt.Errorf("invalid issue grouping %q, must be one of %v", opts.IssueGroupBy, IssueGroupings)
+	}
+	if opts.IssueGroupBy == IssueGroupByTeam && opts.IssueTeamLabel == "" {
+		return fmt.Errorf("--issue-group-by team requires --issue-team-label")
+	}
+	if owner, name, ok := strings.Cut(opts.GitHubRepo, "/"); opts.GitHubRepo != "" && (!ok || owner == "" || name == "" || strings.Contains(name, "/")) {
+		return fmt.Errorf("invalid GitHub repository %q, must be owner/name", opts.GitHubRepo)
+	}
+	if opts.JiraProject != "" && opts.JiraURL == "" {
+		return fmt.Errorf("--jira-project requires --jira-url")
+	}
+	_, err := LoadIssueOwners(opts.IssueOwnersFile)
+	return err
+}
+
+// issueGroup holds the findings of a namespace or team.
+type issueGroup struct {
+	// name is empty for the cluster-scoped resources, or the namespaces without a team
+	name string
+	rows []reportRow
+}
+
+func groupIssueRows(rows []reportRow, groupBy string, teams map[string]string) []issueGroup {
+	byName := make(map[string][]reportRow)
+	for _, row := range rows {
+		name := row.Namespace
+		if groupBy == IssueGroupByTeam {
+			name = teams[row.Namespace]
+		}
+		byName[name] = append(byName[name], row)
+	}
+	groups := make([]issueGroup, 0, len(byName))
+	for name, rows := range byName {
+		groups = append(groups, issueGroup{name: name, rows: rows})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].name < groups[j].name })
+	return groups
+}
+
+func (g issueGroup) title(groupBy string) string {
+	switch {
+	case groupBy == IssueGroupByTeam && g.name == "":
+		return "Unused Kubernetes resources without a team"
+	case groupBy == IssueGroupByTeam:
+		return fmt.Sprintf("Unused Kubernetes resources of team %s", g.name)
+	case g.name == "":
+		return "Unused cluster-scoped Kubernetes resources"
+	}
+	return fmt.Sprintf("Unused Kubernetes resources in namespace %s", g.name)
+}
+
+// label identifies the Jira issue of the group, Jira labels cannot hold spaces.
+func (g issueGroup) label(groupBy string) string {
+	name := g.name
+	if name == "" {
+		name = "none"
+	}
+	return issueLabel + "-" + groupBy + "-" + strings.Join(strings.Fields(name), "_")
+}
+
+func (g issueGroup) owner(owners map[string]IssueOwner) IssueOwner {
+	if owner, ok := owners[g.name]; ok {
+		return owner
+	}
+	return owners[defaultIssueOwner]
+}
+
+// githubIssueBody renders the findings of the group as a Markdown table.
+func githubIssueBody(g issueGroup, now time.Time) string {
+	escape := func(s string) string { return strings.ReplaceAll(s, "|", "\\|") }
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "kor found %d unused resources on %s.\n\n", len(g.rows), now.UTC().Format(time.RFC3339))
+	sb.WriteString("| Namespace | Kind | Name | Reason |\n| --- | --- | --- | --- |\n")
+	for i, row := range g.rows {
+		if i == issueMaxRows {
+			fmt.Fprintf(&sb, "\n_and %d more._\n", len(g.rows)-issueMaxRows)
+			break
+		}
+		fmt.Fprintf(&sb, "| %s | %s | `%s` | %s |\n", escape(row.Namespace), escape(row.Kind), escape(row.Name), escape(row.Reason))
+	}
+	sb.WriteString("\nThis issue is updated by every kor run, close it once the resources are deleted.\n")
+	return sb.String()
+}
+
+// jiraIssueBody renders the findings of the group as a table of Jira wiki markup.
+func jiraIssueBody(g issueGroup, now time.Time) string {
+	cell := func(s string) string {
+		if s == "" {
+			return " "
+		}
+		return strings.ReplaceAll(s, "|", "\\|")
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "kor found %d unused resources on %s.\n\n", len(g.rows), now.UTC().Format(time.RFC3339))
+	sb.WriteString("||Namespace||Kind||Name||Reason||\n")
+	for i, row := range g.rows {
+		if i == issueMaxRows {
+			fmt.Fprintf(&sb, "\n_and %d more._\n", len(g.rows)-issueMaxRows)
+			break
+		}
+		fmt.Fprintf(&sb, "|%s|%s|{{%s}}|%s|\n", cell(row.Namespace), cell(row.Kind), row.Name, cell(row.Reason))
+	}
+	sb.WriteString("\nThis issue is updated by every kor run, resolve it once the resources are deleted.\n")
+	return sb.String()
+}
+
+// namespaceTeams returns the value of the team label of every namespace.
+func namespaceTeams(ctx context.Context, clientset kubernetes.Interface, teamLabel string) (map[string]string, error) {
+	namespaces, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.CoreV1().Namespaces().List)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	teams := make(map[string]string, len(namespaces.Items))
+	for _, namespace := range namespaces.Items {
+		teams[namespace.Name] = namespace.Labels[teamLabel]
+	}
+	return teams, nil
+}
+
+// OpenIssues opens a GitHub issue and a Jira issue per namespace or team
+// summarizing the resources reported during this run, assigned to the owners
+// of the --issue-owners file. The open issue of a group is updated rather than
+// duplicated, groups without unused resources are left alone.
+func OpenIssues(ctx context.Context, opts common.Opts, clientset kubernetes.Interface) error {
+	if opts.GitHubRepo == "" && opts.JiraProject == "" {
+		return nil
+	}
+	owners, err := LoadIssueOwners(opts.IssueOwnersFile)
+	if err != nil {
+		return err
+	}
+	var teams map[string]string
+	if opts.IssueGroupBy == IssueGroupByTeam {
+		if teams, err = namespaceTeams(ctx, clientset, opts.IssueTeamLabel); err != nil {
+			return err
+		}
+	}
+
+	var github *utils.GitHubClient
+	if opts.GitHubRepo != "" {
+		github = &utils.GitHubClient{Token: opts.GitHubToken}
+		if github.Token == "" {
+			github.Token = os.Getenv("GITHUB_TOKEN")
+		}
+	}
+	var jira *utils.JiraClient
+	if opts.JiraProject != "" {
+		jira = &utils.JiraClient{BaseURL: opts.JiraURL, User: opts.JiraUser, Token: opts.JiraToken, Project: opts.JiraProject, IssueType: opts.JiraIssueType}
+		if jira.Token == "" {
+			jira.Token = os.Getenv("JIRA_API_TOKEN")
+		}
+	}
+
+	now := time.Now()
+	var errs []error
+	for _, group := range groupIssueRows(reportRows(reportedInfo), opts.IssueGroupBy, teams) {
+		owner := group.owner(owners)
+		title := group.title(opts.IssueGroupBy)
+		if github != nil {
+			issue := utils.Issue{Title: title, Body: githubIssueBody(group, now), Label: issueLabel, Assignees: owner.GitHub}
+			if err := syncGitHubIssue(github, opts.GitHubRepo, issue); err != nil {
+				errs = append(errs, fmt.Errorf("failed to open GitHub issue %q: %w", title, err))
+			}
+		}
+		if jira != nil {
+			issue := utils.Issue{Title: title, Body: jiraIssueBody(group, now), Label: group.label(opts.IssueGroupBy)}
+			if owner.Jira != "" {
+				issue.Assignees = []string{owner.Jira}
+			}
+			if err := syncJiraIssue(jira, issue); err != nil {
+				errs = append(errs, fmt.Errorf("failed to open Jira issue %q: %w", title, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func syncGitHubIssue(client *utils.GitHubClient, repo string, issue utils.Issue) error {
+	number, err := client.FindIssue(repo, issue.Label, issue.Title)
+	if err != nil {
+		return err
+	}
+	if number != 0 {
+		slog.Info("Updating GitHub issue", "repository", repo, "number", number, "title", issue.Title)
+		return client.UpdateIssue(repo, number, issue)
+	}
+	slog.Info("Opening GitHub issue", "repository", repo, "title", issue.Title)
+	return client.CreateIssue(repo, issue)
+}
+
+func syncJiraIssue(client *utils.JiraClient, issue utils.Issue) error {
+	key, err := client.FindIssue(issue.Label)
+	if err != nil {
+		return err
+	}
+	if key != "" {
+		slog.Info("Updating Jira issue", "key", key, "title", issue.Title)
+		return client.UpdateIssue(key, issue)
+	}
+	slog.Info("Opening Jira issue", "project", client.Project, "title", issue.Title)
+	return client.CreateIssue(issue)
+}
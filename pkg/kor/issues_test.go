@@ -0,0 +1,97 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+)
+
+func TestGroupIssueRows(t *testing.T) {
+	rows := reportRows(emailTestReport())
+	groups := groupIssueRows(rows, IssueGroupByNamespace, nil)
+	if len(groups) != 2 || groups[0].name != "" || groups[1].name != "default" || len(groups[1].rows) != 2 {
+		t.Fatalf("Expected the cluster-scoped and default groups, got %+v", groups)
+	}
+	if groups[0].title(IssueGroupByNamespace) != "Unused cluster-scoped Kubernetes resources" || groups[1].label(IssueGroupByNamespace) != "kor-namespace-default" {
+		t.Errorf("Unexpected title or label %q %q", groups[0].title(IssueGroupByNamespace), groups[1].label(IssueGroupByNamespace))
+	}
+
+	groups = groupIssueRows(rows, IssueGroupByTeam, map[string]string{"default": "payments"})
+	if len(groups) != 2 || groups[1].name != "payments" || groups[1].title(IssueGroupByTeam) != "Unused Kubernetes resources of team payments" {
+		t.Errorf("Expected the payments team and the resources without a team, got %+v", groups)
+	}
+
+	body := githubIssueBody(groups[1], time.Now())
+	if !strings.Contains(body, "| default | Secret | `token` | Secret is not used, \"anywhere\" |") {
+		t.Errorf("Expected a row per resource, got:\n%s", body)
+	}
+}
+
+func TestOpenIssues(t *testing.T) {
+	resetReportedResources()
+	defer resetReportedResources()
+	recordUnusedResources(emailTestReport(), "namespace")
+
+	var created, updated []map[string]interface{}
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		switch r.Method {
+		case http.MethodGet:
+			fmt.Fprint(w, `[{"number": 4, "title": "Unused Kubernetes resources of team payments"}]`)
+		case http.MethodPost:
+			created = append(created, payload)
+		case http.MethodPatch:
+			updated = append(updated, payload)
+		}
+	}))
+	defer github.Close()
+	t.Setenv("GITHUB_API_URL", github.URL)
+	t.Setenv("GITHUB_TOKEN", "token")
+
+	owners := filepath.Join(t.TempDir(), "owners.yaml")
+	if err := os.WriteFile(owners, []byte("payments:\n  github: [alice]\n\"*\":\n  github: [platform-bot]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	clientset := fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default", Labels: map[string]string{"team": "payments"}}})
+	opts := common.Opts{GitHubRepo: "acme/platform", IssueGroupBy: IssueGroupByTeam, IssueTeamLabel: "team", IssueOwnersFile: owners}
+	if err := ValidateIssueOptions(opts); err != nil {
+		t.Fatalf("Expected valid options, got %v", err)
+	}
+	if err := OpenIssues(context.Background(), opts, clientset); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(updated) != 1 || fmt.Sprint(updated[0]["assignees"]) != "[alice]" {
+		t.Errorf("Expected the open issue of the payments team to be updated, got %v", updated)
+	}
+	if len(created) != 1 || created[0]["title"] != "Unused Kubernetes resources without a team" || fmt.Sprint(created[0]["assignees"]) != "[platform-bot]" {
+		t.Errorf("Expected an issue for the resources without a team, got %v", created)
+	}
+}
+
+func TestValidateIssueOptions(t *testing.T) {
+	for _, opts := range []common.Opts{
+		{GitHubRepo: "platform", IssueGroupBy: IssueGroupByNamespace},
+		{GitHubRepo: "acme/platform", IssueGroupBy: "cluster"},
+		{JiraProject: "OPS", IssueGroupBy: IssueGroupByNamespace},
+		{JiraProject: "OPS", JiraURL: "https://example.atlassian.net", IssueGroupBy: IssueGroupByNamespace, IssueOwnersFile: "missing.yaml"},
+	} {
+		if err := ValidateIssueOptions(opts); err == nil {
+			t.Errorf("Expected %+v to be rejected", opts)
+		}
+	}
+}
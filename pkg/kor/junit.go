@@ -0,0 +1,79 @@
+package kor
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// formatJUnit renders findings as JUnit XML with one test suite per resource
+// kind and one failed test case per unused resource.
+func formatJUnit(findings []unusedResource) (string, error) {
+	report := junitTestSuites{Name: "kor"}
+
+	suiteIndex := make(map[string]int)
+	for _, finding := range findings {
+		index, ok := suiteIndex[finding.Kind]
+		if !ok {
+			index = len(report.Suites)
+			suiteIndex[finding.Kind] = index
+			report.Suites = append(report.Suites, junitTestSuite{Name: finding.Kind})
+		}
+
+		name := finding.Name
+		if finding.Namespace != "" {
+			name = finding.Namespace + "/" + finding.Name
+		}
+		message := finding.Reason
+		if message == "" {
+			message = fmt.Sprintf("%s is unused", finding.Kind)
+		}
+
+		suite := &report.Suites[index]
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			Name:      name,
+			ClassName: "kor." + finding.Kind,
+			Failure: &junitFailure{
+				Message: message,
+				Type:    "Unused" + finding.Kind,
+				Text:    fmt.Sprintf("%s %s is unused: %s", finding.Kind, name, message),
+			},
+		})
+		suite.Tests++
+		suite.Failures++
+		report.Tests++
+		report.Failures++
+	}
+
+	output, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(output), nil
+}
@@ -0,0 +1,50 @@
+package kor
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/yonahd/kor/pkg/common"
+)
+
+func TestUnusedResourceFormatterJUnit(t *testing.T) {
+	resources := map[string]map[string][]ResourceInfo{
+		testNamespace: {
+			"ConfigMap": {{Name: "cm-1", Reason: "ConfigMap is not used in any pod or container"}},
+			"Secret":    {{Name: "secret-1"}, {Name: "secret-2"}},
+		},
+		"": {
+			"Pv": {{Name: "pv-1", Reason: "Persistent Volume is not in use"}},
+		},
+	}
+
+	output, err := formatUnusedResources(resources, "junit", common.Opts{GroupBy: "namespace"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf("Error unmarshaling JUnit output: %v", err)
+	}
+
+	if report.Tests != 4 || report.Failures != 4 {
+		t.Errorf("Expected 4 failed tests, got %d tests and %d failures", report.Tests, report.Failures)
+	}
+
+	if len(report.Suites) != 3 {
+		t.Fatalf("Expected 3 test suites, got %d", len(report.Suites))
+	}
+
+	configMapCase := report.Suites[0].TestCases[0]
+	if report.Suites[0].Name != "ConfigMap" || configMapCase.Name != testNamespace+"/cm-1" {
+		t.Errorf("Expected ConfigMap suite with %s/cm-1, got %s with %s", testNamespace, report.Suites[0].Name, configMapCase.Name)
+	}
+	if configMapCase.Failure == nil || configMapCase.Failure.Message != "ConfigMap is not used in any pod or container" {
+		t.Errorf("Expected failure with the unused reason, got %+v", configMapCase.Failure)
+	}
+
+	if pvCase := report.Suites[1].TestCases[0]; pvCase.Name != "pv-1" {
+		t.Errorf("Expected cluster-scoped test case pv-1, got %s", pvCase.Name)
+	}
+}
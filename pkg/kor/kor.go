@@ -1,19 +1,28 @@
 package kor
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strings"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/homedir"
+
+	"github.com/yonahd/kor/pkg/utils"
 )
 
 type ExceptionResource struct {
@@ -39,6 +48,7 @@ type Config struct {
 	ExceptionJobs            []ExceptionResource `json:"exceptionJobs"`
 	ExceptionPdbs            []ExceptionResource `json:"exceptionPdbs"`
 	ExceptionRoleBindings    []ExceptionResource `json:"exceptionRoleBindings"`
+	ExceptionEndpoints       []ExceptionResource `json:"exceptionEndpoints"`
 	// Add other configurations if needed
 }
 
@@ -60,90 +70,254 @@ func GetKubeConfigPath() string {
 	return filepath.Join(home, ".kube", "config")
 }
 
-func GetConfig(kubeconfig string) (*rest.Config, error) {
-	if _, err := os.Stat("/var/run/secrets/kubernetes.io/serviceaccount/token"); err == nil {
-		return rest.InClusterConfig()
+// kubeConfigLoader resolves the kubeconfig like kubectl does: the --kubeconfig
+// flag, then the KUBECONFIG environment variable (a list of paths which are
+// merged), then ~/.kube/config.
+func kubeConfigLoader(kubeconfig, kubeContext string) clientcmd.ClientConfig {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		loadingRules.ExplicitPath = kubeconfig
 	}
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: kubeContext}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
+}
 
-	if kubeconfig == "" {
-		if configEnv := os.Getenv("KUBECONFIG"); configEnv != "" {
-			kubeconfig = configEnv
-		} else {
-			kubeconfig = GetKubeConfigPath()
-		}
-	}
+// inClusterTokenPath is the service account token mounted into pods.
+var inClusterTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
 
-	return clientcmd.BuildConfigFromFlags("", kubeconfig)
+// runningInCluster reports whether kor runs inside a pod with a service account token.
+func runningInCluster() bool {
+	if os.Getenv("KUBERNETES_SERVICE_HOST") == "" || os.Getenv("KUBERNETES_SERVICE_PORT") == "" {
+		return false
+	}
+	_, err := os.Stat(inClusterTokenPath)
+	return err == nil
 }
 
-func GetKubeClient(kubeconfig string, kubeContext string) *kubernetes.Clientset {
-	config, err := clientcmd.LoadFromFile(kubeconfig)
+// GetConfig returns the in-cluster configuration when kor runs inside a pod and
+// no kubeconfig was given, the kubeconfig configuration otherwise.
+func GetConfig(kubeconfig, kubeContext string) (*rest.Config, error) {
+	var config *rest.Config
+	var err error
+	if kubeconfig == "" && os.Getenv("KUBECONFIG") == "" && runningInCluster() {
+		config, err = rest.InClusterConfig()
+	} else {
+		config, err = kubeConfigLoader(kubeconfig, kubeContext).ClientConfig()
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to load kubeconfig: %v\n", err)
-		os.Exit(1)
+		return nil, err
 	}
 
-	if kubeContext != "" {
-		config.CurrentContext = kubeContext
+	if requestTimeout > 0 {
+		config.Timeout = requestTimeout
 	}
+	applyClientRateLimits(config)
+	return config, nil
+}
+
+// withProtobuf returns a copy of config requesting the protobuf encoding of
+// the built-in resources, much smaller and faster to decode than JSON on big
+// clusters. Servers answering in JSON are still understood.
+func withProtobuf(config *rest.Config) *rest.Config {
+	config = rest.CopyConfig(config)
+	config.AcceptContentTypes = runtime.ContentTypeProtobuf + "," + runtime.ContentTypeJSON
+	config.ContentType = runtime.ContentTypeProtobuf
+	return config
+}
 
-	restConfig, err := clientcmd.NewDefaultClientConfig(*config, &clientcmd.ConfigOverrides{}).ClientConfig()
+func GetKubeClient(kubeconfig string, kubeContext string) *kubernetes.Clientset {
+	restConfig, err := GetConfig(kubeconfig, kubeContext)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to create REST config: %v\n", err)
+		slog.Error("failed to create REST config", "error", err)
 		os.Exit(1)
 	}
 
-	clientset, err := kubernetes.NewForConfig(restConfig)
+	restConfig.Wrap(wrapProgress)
+	clientset, err := kubernetes.NewForConfig(withProtobuf(restConfig))
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to create Kubernetes clientset: %v\n", err)
+		slog.Error("failed to create Kubernetes clientset", "error", err)
+		os.Exit(1)
+	}
+	if err := registerMetadataClient(clientset, restConfig); err != nil {
+		slog.Error("failed to create metadata client", "error", err)
+		os.Exit(1)
+	}
+	if err := registerDynamicClient(clientset, restConfig); err != nil {
+		slog.Error("failed to create dynamic client", "error", err)
+		os.Exit(1)
 	}
 
 	return clientset
 }
 
-func GetAPIExtensionsClient(kubeconfig string) *apiextensionsclientset.Clientset {
-	config, err := GetConfig(kubeconfig)
+func GetAPIExtensionsClient(kubeconfig, kubeContext string) *apiextensionsclientset.Clientset {
+	config, err := GetConfig(kubeconfig, kubeContext)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to load kubeconfig: %v\n", err)
+		slog.Error("Failed to load kubeconfig", "error", err)
 		os.Exit(1)
 	}
 
+	config.Wrap(wrapProgress)
 	clientset, err := apiextensionsclientset.NewForConfig(config)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to create Kubernetes client: %v\n", err)
+		slog.Error("Failed to create Kubernetes client", "error", err)
 		os.Exit(1)
 	}
 	return clientset
 }
 
-func GetDynamicClient(kubeconfig string) *dynamic.DynamicClient {
-	config, err := GetConfig(kubeconfig)
+func GetDynamicClient(kubeconfig, kubeContext string) *dynamic.DynamicClient {
+	config, err := GetConfig(kubeconfig, kubeContext)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to load kubeconfig: %v\n", err)
+		slog.Error("Failed to load kubeconfig", "error", err)
 		os.Exit(1)
 	}
 
+	config.Wrap(wrapProgress)
 	clientset, err := dynamic.NewForConfig(config)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to create Kubernetes client: %v\n", err)
+		slog.Error("Failed to create Kubernetes client", "error", err)
 		os.Exit(1)
 	}
 	return clientset
 }
 
+// NewClients creates the clients of the kubeconfig and context, returning
+// the errors GetKubeClient and the others exit on.
+func NewClients(kubeconfig, kubeContext string) (Clients, error) {
+	config, err := GetConfig(kubeconfig, kubeContext)
+	if err != nil {
+		return Clients{}, err
+	}
+	config.Wrap(wrapProgress)
+	var clients Clients
+	if clients.Kubernetes, err = kubernetes.NewForConfig(withProtobuf(config)); err != nil {
+		return Clients{}, err
+	}
+	if err := registerMetadataClient(clients.Kubernetes, config); err != nil {
+		return Clients{}, err
+	}
+	if err := registerDynamicClient(clients.Kubernetes, config); err != nil {
+		return Clients{}, err
+	}
+	if clients.APIExtensions, err = apiextensionsclientset.NewForConfig(config); err != nil {
+		return Clients{}, err
+	}
+	if clients.Dynamic, err = dynamic.NewForConfig(config); err != nil {
+		return Clients{}, err
+	}
+	return clients, nil
+}
+
+// GetContextNames returns the sorted context names of the kubeconfig.
+func GetContextNames(kubeconfig string) ([]string, error) {
+	config, err := kubeConfigLoader(kubeconfig, "").RawConfig()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(config.Contexts))
+	for name := range config.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// GetNamespaceNames returns the namespace names of the cluster, without
+// exiting on connection errors like GetKubeClient does.
+func GetNamespaceNames(ctx context.Context, kubeconfig, kubeContext string) ([]string, error) {
+	config, err := GetConfig(kubeconfig, kubeContext)
+	if err != nil {
+		return nil, err
+	}
+	config.Timeout = 5 * time.Second
+	clientset, err := kubernetes.NewForConfig(withProtobuf(config))
+	if err != nil {
+		return nil, err
+	}
+	namespaces, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.CoreV1().Namespaces().List)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(namespaces.Items))
+	for _, namespace := range namespaces.Items {
+		names = append(names, namespace.Name)
+	}
+	return names, nil
+}
+
 // TODO create formatter by resource "#", "Resource Name", "Namespace"
 // TODO Functions that use this object are accompanied by repeated data acquisition operations and can be optimized.
-func CalculateResourceDifference(usedResourceNames []string, allResourceNames []string) []string {
-	var difference []string
-	for _, name := range allResourceNames {
-		found := false
-		for _, usedName := range usedResourceNames {
-			if name == usedName {
-				found = true
-				break
+// discoverResource resolves the served version of a resource in the given API
+// group, preferring the group's preferred version. found is false when the
+// group or resource is not installed in the cluster.
+func discoverResource(clientset kubernetes.Interface, group, resource string) (gvr schema.GroupVersionResource, found bool, err error) {
+	groups, err := clientset.Discovery().ServerGroups()
+	if err != nil {
+		return gvr, false, err
+	}
+
+	for _, apiGroup := range groups.Groups {
+		if apiGroup.Name != group {
+			continue
+		}
+
+		versions := append([]metav1.GroupVersionForDiscovery{apiGroup.PreferredVersion}, apiGroup.Versions...)
+		for _, version := range versions {
+			resources, err := clientset.Discovery().ServerResourcesForGroupVersion(version.GroupVersion)
+			if err != nil {
+				continue
+			}
+			for _, apiResource := range resources.APIResources {
+				if apiResource.Name == resource {
+					return schema.GroupVersionResource{Group: group, Version: version.Version, Resource: resource}, true, nil
+				}
 			}
 		}
-		if !found {
+	}
+
+	return gvr, false, nil
+}
+
+// CalculateResourceDifference returns the names of allResourceNames missing
+// from usedResourceNames, in their order.
+func CalculateResourceDifference(usedResourceNames []string, allResourceNames []string) []string {
+	return newNameSet(usedResourceNames).unused(allResourceNames)
+}
+
+// nameSet is a set of resource names, such as the names of the resources
+// referenced by the pods of a namespace.
+type nameSet map[string]struct{}
+
+// newNameSet returns the set of the names of every slice.
+func newNameSet(slices ...[]string) nameSet {
+	size := 0
+	for _, names := range slices {
+		size += len(names)
+	}
+	set := make(nameSet, size)
+	for _, names := range slices {
+		set.add(names...)
+	}
+	return set
+}
+
+func (s nameSet) add(names ...string) {
+	for _, name := range names {
+		s[name] = struct{}{}
+	}
+}
+
+func (s nameSet) has(name string) bool {
+	_, ok := s[name]
+	return ok
+}
+
+// unused returns the names missing from the set, in their order.
+func (s nameSet) unused(names []string) []string {
+	var difference []string
+	for _, name := range names {
+		if !s.has(name) {
 			difference = append(difference, name)
 		}
 	}
@@ -178,6 +352,9 @@ func isResourceException(resourceName, namespace string, exceptions []ExceptionR
 
 func unmarshalConfig(data []byte) (*Config, error) {
 	var config Config
+	if !builtinExceptions {
+		return &config, nil
+	}
 	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, err
 	}
@@ -202,6 +379,144 @@ func resourceInfoContains(slice []ResourceInfo, item string) bool {
 	return false
 }
 
+// keyUsage records which keys of a ConfigMap or Secret are referenced by consumers
+type keyUsage struct {
+	allKeys bool
+	keys    map[string]bool
+}
+
+func recordUsedKeys(usage map[string]*keyUsage, name string, keys ...string) {
+	if _, ok := usage[name]; !ok {
+		usage[name] = &keyUsage{keys: make(map[string]bool)}
+	}
+	for _, key := range keys {
+		usage[name].keys[key] = true
+	}
+}
+
+func recordAllKeysUsed(usage map[string]*keyUsage, name string) {
+	recordUsedKeys(usage, name)
+	usage[name].allKeys = true
+}
+
+// unusedKeys returns the sorted keys which are not referenced according to the usage record
+func (u *keyUsage) unusedKeys(keys []string) []string {
+	if u.allKeys {
+		return nil
+	}
+	var unused []string
+	for _, key := range keys {
+		if !u.keys[key] {
+			unused = append(unused, key)
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
+
+// podSpecs returns the specs of the pods of the namespace and the pod
+// templates of its workloads, so what workloads scaled to zero or not
+// scheduled yet reference is taken as used.
+func podSpecs(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]corev1.PodSpec, error) {
+	pods, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.CoreV1().Pods(namespace).List)
+	if err != nil {
+		return nil, err
+	}
+	specs := make([]corev1.PodSpec, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		specs = append(specs, pod.Spec)
+	}
+
+	deployments, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.AppsV1().Deployments(namespace).List)
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range deployments.Items {
+		specs = append(specs, deployment.Spec.Template.Spec)
+	}
+
+	statefulSets, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.AppsV1().StatefulSets(namespace).List)
+	if err != nil {
+		return nil, err
+	}
+	for _, statefulSet := range statefulSets.Items {
+		specs = append(specs, statefulSet.Spec.Template.Spec)
+	}
+
+	daemonSets, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.AppsV1().DaemonSets(namespace).List)
+	if err != nil {
+		return nil, err
+	}
+	for _, daemonSet := range daemonSets.Items {
+		specs = append(specs, daemonSet.Spec.Template.Spec)
+	}
+
+	replicaSets, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.AppsV1().ReplicaSets(namespace).List)
+	if err != nil {
+		return nil, err
+	}
+	for _, replicaSet := range replicaSets.Items {
+		specs = append(specs, replicaSet.Spec.Template.Spec)
+	}
+
+	jobs, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.BatchV1().Jobs(namespace).List)
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range jobs.Items {
+		specs = append(specs, job.Spec.Template.Spec)
+	}
+
+	cronJobs, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.BatchV1().CronJobs(namespace).List)
+	if err != nil {
+		return nil, err
+	}
+	for _, cronJob := range cronJobs.Items {
+		specs = append(specs, cronJob.Spec.JobTemplate.Spec.Template.Spec)
+	}
+	return specs, nil
+}
+
+// podContainers returns the init, regular and ephemeral containers of a pod
+func podContainers(spec corev1.PodSpec) []corev1.Container {
+	containers := make([]corev1.Container, 0, len(spec.InitContainers)+len(spec.Containers)+len(spec.EphemeralContainers))
+	containers = append(containers, spec.InitContainers...)
+	return append(containers, runContainers(spec)...)
+}
+
+// runContainers returns the regular and ephemeral containers of a pod, without its init containers
+func runContainers(spec corev1.PodSpec) []corev1.Container {
+	containers := make([]corev1.Container, 0, len(spec.Containers)+len(spec.EphemeralContainers))
+	containers = append(containers, spec.Containers...)
+	for _, ephemeralContainer := range spec.EphemeralContainers {
+		containers = append(containers, corev1.Container(ephemeralContainer.EphemeralContainerCommon))
+	}
+	return containers
+}
+
+// volumeSubPaths returns, for each volume of the pod, the keys mounted through subPath.
+// Volumes mounted at least once without a subPath are reported in wholeMounts.
+func volumeSubPaths(spec corev1.PodSpec) (map[string][]string, map[string]bool) {
+	subPaths := make(map[string][]string)
+	wholeMounts := make(map[string]bool)
+	for _, container := range podContainers(spec) {
+		for _, mount := range container.VolumeMounts {
+			if mount.SubPath == "" && mount.SubPathExpr == "" {
+				wholeMounts[mount.Name] = true
+				continue
+			}
+			if mount.SubPathExpr != "" {
+				// The key can't be resolved statically
+				wholeMounts[mount.Name] = true
+				continue
+			}
+			key, _, _ := strings.Cut(mount.SubPath, "/")
+			subPaths[mount.Name] = append(subPaths[mount.Name], key)
+		}
+	}
+	return subPaths, wholeMounts
+}
+
 // Convert a slice of names into a map for fast lookup
 func convertNamesToPresenseMap(names []string, _ []string, err error) (map[string]bool, error) {
 	if err != nil {
@@ -1,9 +1,18 @@
 package kor
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"sort"
+	"strings"
 	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func stringSlicesEqual(a, b []string) bool {
@@ -62,6 +71,33 @@ func TestCalculateResourceDifference(t *testing.T) {
 	}
 }
 
+func TestNameSetUnused(t *testing.T) {
+	used := newNameSet([]string{"resource2"}, nil, []string{"resource4", "resource2"})
+	if len(used) != 2 || !used.has("resource4") || used.has("resource1") {
+		t.Errorf("Expected the set of the used names, got %v", used)
+	}
+
+	difference := used.unused([]string{"resource3", "resource1", "resource2", "resource3"})
+	if !stringSlicesEqual(difference, []string{"resource3", "resource1", "resource3"}) {
+		t.Errorf("Expected the unused names in their order, got %v", difference)
+	}
+	if difference := newNameSet().unused(nil); difference != nil {
+		t.Errorf("Expected no difference, got %v", difference)
+	}
+}
+
+func BenchmarkCalculateResourceDifference(b *testing.B) {
+	all := make([]string, 10000)
+	for i := range all {
+		all[i] = fmt.Sprintf("configmap-%d", i)
+	}
+	used := all[:len(all)/2]
+	b.ResetTimer()
+	for range b.N {
+		CalculateResourceDifference(used, all)
+	}
+}
+
 func getFakeConfigContent() string {
 	fakeContent := `
 apiVersion: v1
@@ -105,6 +141,100 @@ func TestGetKubeClientFromEnvVar(t *testing.T) {
 	}
 }
 
+func TestGetKubeClientFromMultiPathEnvVar(t *testing.T) {
+	configFile, err := os.CreateTemp("", "kubeconfig-")
+	if err != nil {
+		t.Error(err)
+	}
+	defer os.Remove(configFile.Name())
+	if err := os.WriteFile(configFile.Name(), []byte(getFakeConfigContent()), 0666); err != nil {
+		t.Error(err)
+	}
+
+	originalKCEnv := os.Getenv("KUBECONFIG")
+	defer os.Setenv("KUBECONFIG", originalKCEnv)
+	missingFile := filepath.Join(t.TempDir(), "missing")
+	os.Setenv("KUBECONFIG", missingFile+string(os.PathListSeparator)+configFile.Name())
+
+	config, err := kubeConfigLoader("", "").ClientConfig()
+	if err != nil {
+		t.Fatalf("Expected config from KUBECONFIG list, got %v", err)
+	}
+	if config.Host == "" {
+		t.Errorf("Expected the cluster server to be set")
+	}
+}
+
+func TestKubeConfigLoaderContext(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "kubeconfig")
+	content := strings.Replace(getFakeConfigContent(), "contexts:\n", `contexts:
+- context:
+    cluster: other-cluster
+  name: other-context
+`, 1)
+	content = strings.Replace(content, "clusters:\n", `clusters:
+- cluster:
+    server: https://other:6443
+  name: other-cluster
+`, 1)
+	if err := os.WriteFile(configFile, []byte(content), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := kubeConfigLoader(configFile, "").ClientConfig()
+	if err != nil {
+		t.Fatalf("Expected config for the current context, got %v", err)
+	}
+	if config.Host != "https://localhost:8080" {
+		t.Errorf("Expected current context server, got %s", config.Host)
+	}
+
+	config, err = kubeConfigLoader(configFile, "other-context").ClientConfig()
+	if err != nil {
+		t.Fatalf("Expected config for other-context, got %v", err)
+	}
+	if config.Host != "https://other:6443" {
+		t.Errorf("Expected other-context server, got %s", config.Host)
+	}
+}
+
+func TestGetContextNames(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "kubeconfig")
+	content := strings.Replace(getFakeConfigContent(), "contexts:\n", `contexts:
+- context:
+    cluster: foo-cluster
+  name: another-context
+`, 1)
+	if err := os.WriteFile(configFile, []byte(content), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := GetContextNames(configFile)
+	if err != nil {
+		t.Fatalf("Expected context names, got %v", err)
+	}
+	if !stringSlicesEqual(names, []string{"another-context", "foo-context"}) {
+		t.Errorf("Expected another-context and foo-context, got %v", names)
+	}
+}
+
+func TestGetConfigRequestTimeout(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(configFile, []byte(getFakeConfigContent()), 0666); err != nil {
+		t.Fatal(err)
+	}
+	defer SetRequestTimeout(0)
+
+	SetRequestTimeout(30 * time.Second)
+	config, err := GetConfig(configFile, "")
+	if err != nil {
+		t.Fatalf("Expected config, got %v", err)
+	}
+	if config.Timeout != 30*time.Second {
+		t.Errorf("Expected a 30s request timeout, got %v", config.Timeout)
+	}
+}
+
 func TestGetKubeClientFromInput(t *testing.T) {
 	configFile, err := os.CreateTemp("", "kubeconfig")
 	if err != nil {
@@ -131,6 +261,36 @@ func TestGetKubeClientFromInput(t *testing.T) {
 	}
 }
 
+func TestNewClientsProtobuf(t *testing.T) {
+	var accept string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"NamespaceList","apiVersion":"v1","items":[{"metadata":{"name":"default"}}]}`))
+	}))
+	defer server.Close()
+
+	configFile := filepath.Join(t.TempDir(), "kubeconfig")
+	kubeconfig := strings.Replace(getFakeConfigContent(), "https://localhost:8080", server.URL, 1)
+	if err := os.WriteFile(configFile, []byte(kubeconfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	clients, err := NewClients(configFile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	namespaces, err := clients.Kubernetes.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(accept, "application/vnd.kubernetes.protobuf,") {
+		t.Errorf("Expected protobuf to be requested, got %q", accept)
+	}
+	if len(namespaces.Items) != 1 || namespaces.Items[0].Name != "default" {
+		t.Errorf("Expected the JSON answer to be decoded, got %+v", namespaces)
+	}
+}
+
 func getFakeExceptions() []ExceptionResource {
 	return []ExceptionResource{
 		{
@@ -198,3 +358,30 @@ func TestResourceExceptionWithRegexPrefixInNamespace(t *testing.T) {
 		t.Error("Expected to find exception")
 	}
 }
+
+func TestRunningInCluster(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("token"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	originalTokenPath := inClusterTokenPath
+	defer func() { inClusterTokenPath = originalTokenPath }()
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "443")
+
+	inClusterTokenPath = tokenFile
+	if !runningInCluster() {
+		t.Error("Expected to detect the in-cluster environment")
+	}
+
+	inClusterTokenPath = filepath.Join(t.TempDir(), "missing")
+	if runningInCluster() {
+		t.Error("Expected no in-cluster environment without a service account token")
+	}
+
+	inClusterTokenPath = tokenFile
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	if runningInCluster() {
+		t.Error("Expected no in-cluster environment without KUBERNETES_SERVICE_HOST")
+	}
+}
@@ -0,0 +1,233 @@
+package kor
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// Resource is an unused resource, as returned by the library functions and
+// the REST API.
+type Resource struct {
+	// Cluster is the kubeconfig context the resource was found in, only set
+	// by the scans across several contexts
+	Cluster string `json:"cluster,omitempty"`
+	// Namespace is empty for cluster-scoped resources
+	Namespace string `json:"namespace,omitempty"`
+	Kind      string `json:"kind"`
+	ResourceInfo
+}
+
+// namespacedDetector finds the unused resources of a namespace.
+type namespacedDetector func(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error)
+
+// clusterDetector finds the unused cluster-scoped resources.
+type clusterDetector func(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]ResourceInfo, error)
+
+// The Unused functions below are the library API of kor: they scan with the
+// clientset and filterOpts, a nil filterOpts scans every namespace but the
+// system ones, and return the unused resources with their creation time, size
+// and owners sorted by namespace and name. They never print, delete or
+// exit, and leave the reports of the kor commands untouched. Cancelling ctx
+// aborts the scan. When namespaces fail to scan, the
+// resources of the others are returned along with the errors.
+//
+// Calls may run concurrently, each detector makes its API requests with the
+// ctx it is given.
+
+// UnusedConfigMaps returns the ConfigMaps not used by any pod or container.
+func UnusedConfigMaps(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]Resource, error) {
+	return unusedNamespaced(ctx, clientset, filterOpts, "ConfigMap", "ConfigMap", processNamespaceCM)
+}
+
+// UnusedSecrets returns the Secrets not used by any pod, container or ingress.
+func UnusedSecrets(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]Resource, error) {
+	return unusedNamespaced(ctx, clientset, filterOpts, "Secret", "Secret", processNamespaceSecret)
+}
+
+// UnusedServices returns the Services without endpoints.
+func UnusedServices(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]Resource, error) {
+	return unusedNamespaced(ctx, clientset, filterOpts, "Service", "Service", processNamespaceServices)
+}
+
+// UnusedDeployments returns the Deployments scaled to zero replicas.
+func UnusedDeployments(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]Resource, error) {
+	return unusedNamespaced(ctx, clientset, filterOpts, "Deployment", "Deployment", processNamespaceDeployments)
+}
+
+// UnusedStatefulSets returns the StatefulSets scaled to zero replicas.
+func UnusedStatefulSets(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]Resource, error) {
+	return unusedNamespaced(ctx, clientset, filterOpts, "StatefulSet", "StatefulSet", processNamespaceStatefulSets)
+}
+
+// UnusedDaemonSets returns the DaemonSets without any scheduled pod.
+func UnusedDaemonSets(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]Resource, error) {
+	return unusedNamespaced(ctx, clientset, filterOpts, "DaemonSet", "DaemonSet", processNamespaceDaemonSets)
+}
+
+// UnusedReplicaSets returns the ReplicaSets scaled to zero replicas, such as
+// the ones of older Deployment revisions.
+func UnusedReplicaSets(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]Resource, error) {
+	return unusedNamespaced(ctx, clientset, filterOpts, "ReplicaSet", "ReplicaSet", processNamespaceReplicaSets)
+}
+
+// UnusedJobs returns the completed or failed Jobs.
+func UnusedJobs(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]Resource, error) {
+	return unusedNamespaced(ctx, clientset, filterOpts, "Job", "Job", processNamespaceJobs)
+}
+
+// UnusedPods returns the evicted Pods.
+func UnusedPods(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]Resource, error) {
+	return unusedNamespaced(ctx, clientset, filterOpts, "Pod", "Pod", processNamespacePods)
+}
+
+// UnusedServiceAccounts returns the ServiceAccounts not used by any pod or
+// binding.
+func UnusedServiceAccounts(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]Resource, error) {
+	return unusedNamespaced(ctx, clientset, filterOpts, "ServiceAccount", "ServiceAccount", processNamespaceSA)
+}
+
+// UnusedServiceAccountTokens returns the legacy ServiceAccount token Secrets
+// not mounted by any pod.
+func UnusedServiceAccountTokens(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]Resource, error) {
+	return unusedNamespaced(ctx, clientset, filterOpts, "Secret", "ServiceAccountToken", processNamespaceSATokens)
+}
+
+// UnusedRoles returns the Roles not referenced by any role binding.
+func UnusedRoles(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]Resource, error) {
+	return unusedNamespaced(ctx, clientset, filterOpts, "Role", "Role", processNamespaceRoles)
+}
+
+// UnusedRoleBindings returns the RoleBindings referencing a missing role or
+// ServiceAccount.
+func UnusedRoleBindings(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]Resource, error) {
+	return unusedNamespaced(ctx, clientset, filterOpts, "RoleBinding", "RoleBinding", processNamespaceRoleBindings)
+}
+
+// UnusedHPAs returns the HorizontalPodAutoscalers of missing workloads.
+func UnusedHPAs(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]Resource, error) {
+	return unusedNamespaced(ctx, clientset, filterOpts, "HorizontalPodAutoscaler", "HPA", processNamespaceHpas)
+}
+
+// UnusedPVCs returns the PersistentVolumeClaims not mounted by any pod.
+func UnusedPVCs(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]Resource, error) {
+	return unusedNamespaced(ctx, clientset, filterOpts, "PersistentVolumeClaim", "PVC", processNamespacePvcs)
+}
+
+// UnusedIngresses returns the Ingresses routing to missing services.
+func UnusedIngresses(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]Resource, error) {
+	return unusedNamespaced(ctx, clientset, filterOpts, "Ingress", "Ingress", processNamespaceIngresses)
+}
+
+// UnusedPDBs returns the PodDisruptionBudgets not matching any workload or pod.
+func UnusedPDBs(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]Resource, error) {
+	return unusedNamespaced(ctx, clientset, filterOpts, "PodDisruptionBudget", "PDB", processNamespacePdbs)
+}
+
+// UnusedNetworkPolicies returns the NetworkPolicies applying to no pod.
+func UnusedNetworkPolicies(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]Resource, error) {
+	return unusedNamespaced(ctx, clientset, filterOpts, "NetworkPolicy", "NetworkPolicy", processNamespaceNetworkPolicies)
+}
+
+// UnusedEndpoints returns the Endpoints without a Service.
+func UnusedEndpoints(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]Resource, error) {
+	return unusedNamespaced(ctx, clientset, filterOpts, "Endpoints", "Endpoints", processNamespaceEndpoints)
+}
+
+// UnusedEndpointSlices returns the EndpointSlices without a Service.
+func UnusedEndpointSlices(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]Resource, error) {
+	return unusedNamespaced(ctx, clientset, filterOpts, "EndpointSlice", "EndpointSlice", processNamespaceEndpointSlices)
+}
+
+// UnusedClusterRoles returns the ClusterRoles not referenced by any binding.
+func UnusedClusterRoles(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]Resource, error) {
+	return unusedCluster(ctx, clientset, filterOpts, "ClusterRole", "ClusterRole", processClusterRoles)
+}
+
+// UnusedPVs returns the PersistentVolumes not bound to any claim.
+func UnusedPVs(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]Resource, error) {
+	return unusedCluster(ctx, clientset, filterOpts, "PersistentVolume", "PV", processPvs)
+}
+
+// UnusedStorageClasses returns the StorageClasses not used by any volume or
+// claim.
+func UnusedStorageClasses(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]Resource, error) {
+	return unusedCluster(ctx, clientset, filterOpts, "StorageClass", "StorageClass", processStorageClasses)
+}
+
+// UnusedCSIDrivers returns the CSIDrivers not used by any volume, storage
+// class or inline pod volume.
+func UnusedCSIDrivers(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]Resource, error) {
+	return unusedCluster(ctx, clientset, filterOpts, "CSIDriver", "CSIDriver", processCSIDrivers)
+}
+
+// UnusedVolumeAttachments returns the VolumeAttachments of missing nodes or
+// volumes.
+func UnusedVolumeAttachments(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]Resource, error) {
+	return unusedCluster(ctx, clientset, filterOpts, "VolumeAttachment", "VolumeAttachment", processVolumeAttachments)
+}
+
+func unusedNamespaced(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options, kind, reportKind string, detect namespacedDetector) ([]Resource, error) {
+	return libraryScan(ctx, filterOpts, func(filterOpts *filters.Options) ([]Resource, error) {
+		var resources []Resource
+		var errs []error
+		for _, namespace := range filterOpts.Namespaces(ctx, clientset) {
+			if err := ctx.Err(); err != nil {
+				return resources, err
+			}
+			infos, err := detect(ctx, clientset, namespace, filterOpts)
+			if err != nil {
+				errs = append(errs, &ScanError{Resource: kind, Namespace: namespace, Err: err})
+				continue
+			}
+			resources = append(resources, libraryResources(ctx, clientset, namespace, kind, reportKind, infos)...)
+		}
+		return resources, errors.Join(errs...)
+	})
+}
+
+func unusedCluster(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options, kind, reportKind string, detect clusterDetector) ([]Resource, error) {
+	return libraryScan(ctx, filterOpts, func(filterOpts *filters.Options) ([]Resource, error) {
+		infos, err := detect(ctx, clientset, filterOpts)
+		if err != nil {
+			return nil, &ScanError{Resource: kind, Err: err}
+		}
+		return libraryResources(ctx, clientset, "", kind, reportKind, infos), nil
+	})
+}
+
+// libraryScan validates the filters and runs scan.
+func libraryScan(ctx context.Context, filterOpts *filters.Options, scan func(filterOpts *filters.Options) ([]Resource, error)) ([]Resource, error) {
+	if filterOpts == nil {
+		filterOpts = filters.NewFilterOptions()
+	}
+	if err := filterOpts.Validate(); err != nil {
+		return nil, err
+	}
+	filterOpts.Modify()
+
+	resources, err := scan(filterOpts)
+	sort.SliceStable(resources, func(i, j int) bool {
+		if resources[i].Namespace != resources[j].Namespace {
+			return resources[i].Namespace < resources[j].Namespace
+		}
+		return resources[i].Name < resources[j].Name
+	})
+	return resources, err
+}
+
+// libraryResources looks up the details of the resources found by a detector.
+func libraryResources(ctx context.Context, clientset kubernetes.Interface, namespace, kind, reportKind string, infos []ResourceInfo) []Resource {
+	resources := make([]Resource, 0, len(infos))
+	for _, info := range infos {
+		if obj, err := getResource(ctx, clientset, namespace, reportKind, info.Name); err == nil {
+			setResourceMetadata(&info, obj)
+		}
+		resources = append(resources, Resource{Namespace: namespace, Kind: kind, ResourceInfo: info})
+	}
+	return resources
+}
@@ -0,0 +1,74 @@
+package kor
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func TestUnusedConfigMaps(t *testing.T) {
+	clientset := createTestConfigmaps(t)
+	reported := UnusedResourceCount()
+
+	resources, err := UnusedConfigMaps(context.Background(), clientset, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []Resource{
+		{Namespace: testNamespace, Kind: "ConfigMap", ResourceInfo: ResourceInfo{Name: "configmap-3", Reason: "ConfigMap is not used in any pod or container"}},
+		{Namespace: testNamespace, Kind: "ConfigMap", ResourceInfo: ResourceInfo{Name: "configmap-5", Reason: "Marked with unused label"}},
+	}
+	if len(resources) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, resources)
+	}
+	for i, resource := range resources {
+		if resource.Namespace != expected[i].Namespace || resource.Kind != expected[i].Kind || resource.Name != expected[i].Name || resource.Reason != expected[i].Reason {
+			t.Errorf("Expected %+v, got %+v", expected[i], resource)
+		}
+	}
+	if UnusedResourceCount() != reported {
+		t.Errorf("Expected the library not to record reports, got %d more resources", UnusedResourceCount()-reported)
+	}
+}
+
+func TestUnusedPVs(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	created := metav1.Now()
+	for name, phase := range map[string]string{"pv-1": "Available", "pv-2": "Bound"} {
+		pv := CreateTestPv(name, phase, AppLabels, "standard")
+		pv.CreationTimestamp = created
+		if _, err := clientset.CoreV1().PersistentVolumes().Create(context.TODO(), pv, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating fake pv: %v", err)
+		}
+	}
+
+	resources, err := UnusedPVs(context.Background(), clientset, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(resources) != 1 || resources[0].Name != "pv-1" || resources[0].Kind != "PersistentVolume" || resources[0].Namespace != "" {
+		t.Fatalf("Expected the available pv, got %+v", resources)
+	}
+	if resources[0].CreationTimestamp == nil {
+		t.Error("Expected the details of the resource to be looked up")
+	}
+}
+
+func TestUnusedLibraryErrors(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: testNamespace}})
+
+	if _, err := UnusedSecrets(context.Background(), clientset, &filters.Options{OlderThan: "1h", NewerThan: "2h"}); err == nil {
+		t.Error("Expected invalid filters to be rejected")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := UnusedDeployments(ctx, clientset, nil); err == nil {
+		t.Error("Expected a cancelled context to abort the scan")
+	}
+}
@@ -0,0 +1,45 @@
+package kor
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// LogFormats lists the values accepted by --log-format.
+var LogFormats = []string{"text", "json"}
+
+// ConfigureLogging sets the default slog logger used for warnings, errors and
+// the debug logs of API requests. Records are written to stderr through the
+// status line so the two never interleave.
+func ConfigureLogging(format string, verbose bool) error {
+	handler, err := newLogHandler(progress, format, verbose)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+func newLogHandler(out io.Writer, format string, verbose bool) (slog.Handler, error) {
+	level := slog.LevelInfo
+	if verbose {
+		level = slog.LevelDebug
+	}
+
+	switch format {
+	case "json":
+		return slog.NewJSONHandler(out, &slog.HandlerOptions{Level: level}), nil
+	case "text", "":
+		// Text logs are read next to the report, the time only adds noise there
+		return slog.NewTextHandler(out, &slog.HandlerOptions{Level: level, ReplaceAttr: dropTime}), nil
+	}
+	return nil, fmt.Errorf("invalid log format %q, must be one of %v", format, LogFormats)
+}
+
+func dropTime(groups []string, attr slog.Attr) slog.Attr {
+	if len(groups) == 0 && attr.Key == slog.TimeKey {
+		return slog.Attr{}
+	}
+	return attr
+}
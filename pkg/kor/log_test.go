@@ -0,0 +1,58 @@
+package kor
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNewLogHandler(t *testing.T) {
+	var out bytes.Buffer
+	handler, err := newLogHandler(&out, "json", false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	logger := slog.New(handler)
+	logger.Debug("API request", "method", "GET")
+	logger.Error("Failed to process namespace", "namespace", testNamespace)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected the debug record to be dropped without verbose, got %q", out.String())
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("Expected a JSON record, got %v", err)
+	}
+	if record["level"] != "ERROR" || record["namespace"] != testNamespace {
+		t.Errorf("Unexpected record %v", record)
+	}
+
+	out.Reset()
+	handler, err = newLogHandler(&out, "text", true)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	slog.New(handler).Debug("API request", "method", "GET")
+	if got := strings.TrimSpace(out.String()); got != `level=DEBUG msg="API request" method=GET` {
+		t.Errorf("Unexpected text record %q", got)
+	}
+
+	if _, err := newLogHandler(&out, "xml", false); err == nil {
+		t.Errorf("Expected an error for an invalid log format")
+	}
+}
+
+func TestProgressWriteClearsStatusLine(t *testing.T) {
+	var out bytes.Buffer
+	reporter := &progressReporter{out: &out, enabled: true, namespaces: map[string]bool{}}
+	reporter.draw(true)
+	if _, err := reporter.Write([]byte("level=ERROR msg=failed\n")); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(out.String(), "\r\033[Klevel=ERROR msg=failed\n") || reporter.drawn {
+		t.Errorf("Expected the status line to be cleared before the log record, got %q", out.String())
+	}
+}
@@ -0,0 +1,196 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+// secretGenerator describes a custom resource that renders a Secret.
+type secretGenerator struct {
+	kind     string
+	group    string
+	resource string
+	// targetPath holds the generated Secret name; the CR name is used when unset
+	targetPath []string
+}
+
+var secretGenerators = []secretGenerator{
+	{kind: "SealedSecret", group: "bitnami.com", resource: "sealedsecrets", targetPath: []string{"spec", "template", "metadata", "name"}},
+	{kind: "ExternalSecret", group: "external-secrets.io", resource: "externalsecrets", targetPath: []string{"spec", "target", "name"}},
+}
+
+func (g secretGenerator) targetSecretName(obj unstructured.Unstructured) string {
+	if name, found, _ := unstructured.NestedString(obj.Object, g.targetPath...); found && name != "" {
+		return name
+	}
+	return obj.GetName()
+}
+
+func processNamespaceManagedSecrets(ctx context.Context, clientset kubernetes.Interface, dynamicClient dynamic.Interface, namespace string, filterOpts *filters.Options) (map[string][]ResourceInfo, error) {
+	secrets, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.CoreV1().Secrets(namespace).List)
+	if err != nil {
+		return nil, err
+	}
+
+	existingSecrets := make(map[string]bool, len(secrets.Items))
+	for _, secret := range secrets.Items {
+		existingSecrets[secret.Name] = true
+	}
+
+	unusedSecrets, err := processNamespaceSecret(ctx, clientset, namespace, &filters.Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	unusedSecretNames := make(map[string]bool, len(unusedSecrets))
+	for _, secret := range unusedSecrets {
+		unusedSecretNames[secret.Name] = true
+	}
+
+	// The generator objects are listed regardless of IncludeLabels, an owner
+	// without the labels still exists. Only the resources reported must match.
+	included, err := labels.Parse(filterOpts.IncludeLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := make(map[string][]ResourceInfo)
+	// existing generator objects keyed by group and kind, then by name
+	generatorObjects := make(map[schema.GroupKind]map[string]bool)
+
+	for _, generator := range secretGenerators {
+		groupKind := schema.GroupKind{Group: generator.group, Kind: generator.kind}
+		generatorObjects[groupKind] = make(map[string]bool)
+
+		gvr, found, err := discoverResource(clientset, generator.group, generator.resource)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+
+		objects, err := utils.ListAll(ctx, metav1.ListOptions{}, dynamicClient.Resource(gvr).Namespace(namespace).List)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range objects.Items {
+			generatorObjects[groupKind][obj.GetName()] = true
+
+			if !included.Matches(labels.Set(obj.GetLabels())) {
+				continue
+			}
+			if pass, _ := filter.SetObject(&obj).Run(filterOpts, filters.LabelFilterName, filters.AgeFilterName); pass {
+				continue
+			}
+
+			if obj.GetLabels()["kor/used"] == "false" {
+				diffs[generator.kind] = append(diffs[generator.kind], ResourceInfo{Name: obj.GetName(), Reason: "Marked with unused label"})
+				continue
+			}
+
+			secretName := generator.targetSecretName(obj)
+			if existingSecrets[secretName] && unusedSecretNames[secretName] {
+				reason := fmt.Sprintf("Generated Secret %s is not used", secretName)
+				diffs[generator.kind] = append(diffs[generator.kind], ResourceInfo{Name: obj.GetName(), Reason: reason})
+			}
+		}
+	}
+
+	for _, secret := range secrets.Items {
+		if !included.Matches(labels.Set(secret.Labels)) {
+			continue
+		}
+		if pass, _ := filter.SetObject(&secret).Run(filterOpts); pass {
+			continue
+		}
+
+		for _, owner := range secret.OwnerReferences {
+			ownerGV, err := schema.ParseGroupVersion(owner.APIVersion)
+			if err != nil {
+				continue
+			}
+
+			objects, ok := generatorObjects[schema.GroupKind{Group: ownerGV.Group, Kind: owner.Kind}]
+			if !ok || objects[owner.Name] {
+				continue
+			}
+
+			reason := fmt.Sprintf("Secret was generated by %s %s which no longer exists", owner.Kind, owner.Name)
+			diffs["GeneratedSecret"] = append(diffs["GeneratedSecret"], ResourceInfo{Name: secret.Name, Reason: reason})
+			break
+		}
+	}
+
+	return diffs, nil
+}
+
+func GetUnusedManagedSecrets(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
+	clientset = scanClientset(clientset)
+	resources := make(map[string]map[string][]ResourceInfo)
+	for _, scan := range scanNamespaces(filterOpts.Namespaces(ctx, clientset), func(namespace string) (map[string][]ResourceInfo, error) {
+		return processNamespaceManagedSecrets(ctx, clientset, dynamicClient, namespace, filterOpts)
+	}) {
+		namespace, diffs, err := scan.namespace, scan.result, scan.err
+		if err != nil {
+			recordScanError("ManagedSecret", namespace, err)
+			continue
+		}
+		// Only the generated Secrets can be removed through the typed clientset
+		exportManifests(ctx, clientset, namespace, "GeneratedSecret", diffs["GeneratedSecret"], opts)
+		if deleteEnabled(opts, "GeneratedSecret") {
+			if diffs["GeneratedSecret"], err = DeleteResource(ctx, diffs["GeneratedSecret"], clientset, namespace, "GeneratedSecret", opts.NoInteractive); err != nil {
+				slog.Error("Failed to delete GeneratedSecret", "name", diffs["GeneratedSecret"], "namespace", namespace, "error", err)
+			}
+		}
+		switch opts.GroupBy {
+		case "namespace":
+			resources[namespace] = make(map[string][]ResourceInfo)
+			for _, kind := range []string{"SealedSecret", "ExternalSecret", "GeneratedSecret"} {
+				resources[namespace][kind] = diffs[kind]
+			}
+		case "resource":
+			for _, kind := range []string{"SealedSecret", "ExternalSecret", "GeneratedSecret"} {
+				appendResources(resources, kind, namespace, diffs[kind])
+			}
+		}
+	}
+
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+	default:
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	unusedManagedSecrets, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return unusedManagedSecrets, nil
+}
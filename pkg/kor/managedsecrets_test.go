@@ -0,0 +1,171 @@
+package kor
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+var (
+	sealedSecretGVR   = schema.GroupVersionResource{Group: "bitnami.com", Version: "v1alpha1", Resource: "sealedsecrets"}
+	externalSecretGVR = schema.GroupVersionResource{Group: "external-secrets.io", Version: "v1beta1", Resource: "externalsecrets"}
+)
+
+func createTestManagedSecrets(t *testing.T) (*fake.Clientset, *fakedynamic.FakeDynamicClient) {
+	clientset := fake.NewSimpleClientset()
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*v1.APIResourceList{
+		{
+			GroupVersion: sealedSecretGVR.GroupVersion().String(),
+			APIResources: []v1.APIResource{{Name: sealedSecretGVR.Resource, Kind: "SealedSecret", Namespaced: true}},
+		},
+		{
+			GroupVersion: externalSecretGVR.GroupVersion().String(),
+			APIResources: []v1.APIResource{{Name: externalSecretGVR.Resource, Kind: "ExternalSecret", Namespaced: true}},
+		},
+	}
+
+	secrets := []*corev1.Secret{
+		CreateTestSecret(testNamespace, "sealed-used", AppLabels),
+		CreateTestSecret(testNamespace, "sealed-unused", AppLabels),
+		CreateTestSecret(testNamespace, "external-target", AppLabels),
+		CreateTestSecret(testNamespace, "sealed-orphan", AppLabels),
+	}
+	secrets[3].OwnerReferences = []v1.OwnerReference{
+		{APIVersion: sealedSecretGVR.GroupVersion().String(), Kind: "SealedSecret", Name: "sealed-orphan"},
+	}
+	for _, secret := range secrets {
+		_, err := clientset.CoreV1().Secrets(testNamespace).Create(context.TODO(), secret, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake %s: %v", "Secret", err)
+		}
+	}
+
+	volume := corev1.Volume{
+		Name: "secret",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: "sealed-used"},
+		},
+	}
+	pod1 := CreateTestPod(testNamespace, "test-pod1", "", []corev1.Volume{volume}, AppLabels)
+	_, err := clientset.CoreV1().Pods(testNamespace).Create(context.TODO(), pod1, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "Pod", err)
+	}
+
+	externalSecret := CreateTestUnstructered("ExternalSecret", externalSecretGVR.GroupVersion().String(), testNamespace, "external")
+	externalSecret.Object["spec"] = map[string]interface{}{
+		"target": map[string]interface{}{"name": "external-target"},
+	}
+	markedSealedSecret := CreateTestUnstructered("SealedSecret", sealedSecretGVR.GroupVersion().String(), testNamespace, "sealed-marked")
+	markedSealedSecret.SetLabels(UnusedLabels)
+
+	dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			sealedSecretGVR:   "SealedSecretList",
+			externalSecretGVR: "ExternalSecretList",
+		},
+		CreateTestUnstructered("SealedSecret", sealedSecretGVR.GroupVersion().String(), testNamespace, "sealed-used"),
+		CreateTestUnstructered("SealedSecret", sealedSecretGVR.GroupVersion().String(), testNamespace, "sealed-unused"),
+		markedSealedSecret,
+		externalSecret,
+	)
+
+	return clientset, dynamicClient
+}
+
+func TestProcessNamespaceManagedSecrets(t *testing.T) {
+	clientset, dynamicClient := createTestManagedSecrets(t)
+
+	diffs, err := processNamespaceManagedSecrets(context.Background(), clientset, dynamicClient, testNamespace, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string][]ResourceInfo{
+		"SealedSecret": {
+			{Name: "sealed-marked", Reason: "Marked with unused label"},
+			{Name: "sealed-unused", Reason: "Generated Secret sealed-unused is not used"},
+		},
+		"ExternalSecret": {
+			{Name: "external", Reason: "Generated Secret external-target is not used"},
+		},
+		"GeneratedSecret": {
+			{Name: "sealed-orphan", Reason: "Secret was generated by SealedSecret sealed-orphan which no longer exists"},
+		},
+	}
+	if !reflect.DeepEqual(diffs, expected) {
+		t.Errorf("Expected %v, got %v", expected, diffs)
+	}
+}
+
+func TestProcessNamespaceManagedSecretsWithoutCRDs(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	dynamicClient := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())
+
+	diffs, err := processNamespaceManagedSecrets(context.Background(), clientset, dynamicClient, testNamespace, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(diffs) != 0 {
+		t.Errorf("Expected no managed secrets to be reported, got %v", diffs)
+	}
+}
+
+func TestProcessNamespaceManagedSecretsIncludeLabels(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*v1.APIResourceList{
+		{
+			GroupVersion: sealedSecretGVR.GroupVersion().String(),
+			APIResources: []v1.APIResource{{Name: sealedSecretGVR.Resource, Kind: "SealedSecret", Namespaced: true}},
+		},
+	}
+
+	teamLabels := map[string]string{"team": "payments"}
+	secrets := []*corev1.Secret{
+		CreateTestSecret(testNamespace, "sealed-owned", teamLabels),
+		CreateTestSecret(testNamespace, "sealed-orphan", teamLabels),
+		CreateTestSecret(testNamespace, "sealed-orphan-other-team", AppLabels),
+	}
+	for i, owner := range []string{"sealed-owned", "sealed-gone", "sealed-gone"} {
+		secrets[i].OwnerReferences = []v1.OwnerReference{
+			{APIVersion: sealedSecretGVR.GroupVersion().String(), Kind: "SealedSecret", Name: owner},
+		}
+	}
+	for _, secret := range secrets {
+		_, err := clientset.CoreV1().Secrets(testNamespace).Create(context.TODO(), secret, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake %s: %v", "Secret", err)
+		}
+	}
+
+	// The owner of sealed-owned exists, but without the labels of the scan
+	dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{sealedSecretGVR: "SealedSecretList"},
+		CreateTestUnstructered("SealedSecret", sealedSecretGVR.GroupVersion().String(), testNamespace, "sealed-owned"),
+	)
+
+	diffs, err := processNamespaceManagedSecrets(context.Background(), clientset, dynamicClient, testNamespace, &filters.Options{IncludeLabels: "team=payments"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string][]ResourceInfo{
+		"GeneratedSecret": {
+			{Name: "sealed-orphan", Reason: "Secret was generated by SealedSecret sealed-gone which no longer exists"},
+		},
+	}
+	if !reflect.DeepEqual(diffs, expected) {
+		t.Errorf("Expected %v, got %v", expected, diffs)
+	}
+}
@@ -0,0 +1,160 @@
+package kor
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// reportResourceTypes maps the kinds used in reports to the resource types
+// understood by getResource, where they differ.
+var reportResourceTypes = map[string]string{
+	"Hpa": "HPA",
+	"Pdb": "PDB",
+	"Pv":  "PV",
+	"Pvc": "PVC",
+}
+
+// instanceLabel names the Helm release of the objects lacking its annotation.
+const instanceLabel = "app.kubernetes.io/instance"
+
+// enrichResources looks up every unused resource to fill in its creation time,
+// size, ownership and, with --show-cost, its monthly cost. It only calls the
+// API when a column that needs them is requested, and leaves resources it
+// cannot look up untouched.
+func enrichResources(ctx context.Context, clientset kubernetes.Interface, resources map[string]map[string][]ResourceInfo, opts common.Opts) {
+	if !opts.ShowAge && !opts.ShowSize && !opts.ShowCost && !opts.Wide && !opts.ShowHelmSummary && opts.SortBy != "age" && opts.SortBy != "size" {
+		return
+	}
+	for outerKey, inner := range resources {
+		for innerKey, infos := range inner {
+			namespace, kind := outerKey, innerKey
+			if opts.GroupBy == "resource" {
+				namespace, kind = innerKey, outerKey
+			}
+			resourceType := kind
+			if mapped, ok := reportResourceTypes[kind]; ok {
+				resourceType = mapped
+			}
+			for i := range infos {
+				obj, err := getResource(ctx, clientset, namespace, resourceType, infos[i].Name)
+				if err != nil {
+					continue
+				}
+				setResourceMetadata(&infos[i], obj)
+				if opts.ShowCost {
+					infos[i].MonthlyCost = resourceCost(obj)
+				}
+			}
+		}
+	}
+}
+
+func setResourceMetadata(info *ResourceInfo, obj interface{}) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return
+	}
+	creationTimestamp := accessor.GetCreationTimestamp()
+	info.CreationTimestamp = &creationTimestamp
+	info.Size = resourceSize(obj)
+
+	info.Owners = ownerNames(accessor)
+	labels := accessor.GetLabels()
+	info.ManagedBy = labels[filters.ManagedByKey]
+	info.HelmRelease = accessor.GetAnnotations()[filters.HelmReleaseNameKey]
+	if info.HelmRelease == "" && info.ManagedBy == "Helm" {
+		info.HelmRelease = labels[instanceLabel]
+	}
+}
+
+// ownerNames lists the owners of the object as Kind/name, split by commas.
+func ownerNames(object metav1.Object) string {
+	var owners []string
+	for _, owner := range object.GetOwnerReferences() {
+		owners = append(owners, owner.Kind+"/"+owner.Name)
+	}
+	return strings.Join(owners, ",")
+}
+
+// resourceSize returns the data size of ConfigMaps and Secrets, and the
+// capacity of volumes. It is nil for kinds without a meaningful size.
+func resourceSize(obj interface{}) *resource.Quantity {
+	var size int64
+	switch o := obj.(type) {
+	case *corev1.ConfigMap:
+		for _, value := range o.Data {
+			size += int64(len(value))
+		}
+		for _, value := range o.BinaryData {
+			size += int64(len(value))
+		}
+	case *corev1.Secret:
+		for _, value := range o.Data {
+			size += int64(len(value))
+		}
+	case *corev1.PersistentVolumeClaim:
+		if capacity, ok := o.Status.Capacity[corev1.ResourceStorage]; ok {
+			return &capacity
+		}
+		if request, ok := o.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+			return &request
+		}
+		return nil
+	case *corev1.PersistentVolume:
+		if capacity, ok := o.Spec.Capacity[corev1.ResourceStorage]; ok {
+			return &capacity
+		}
+		return nil
+	default:
+		return nil
+	}
+	return resource.NewQuantity(size, resource.BinarySI)
+}
+
+// getResourceInfoColumns returns the optional age, size, cost and wide table columns.
+func getResourceInfoColumns(info ResourceInfo, opts common.Opts) []string {
+	var columns []string
+	if opts.ShowAge {
+		age := "<unknown>"
+		if info.CreationTimestamp != nil && !info.CreationTimestamp.IsZero() {
+			age = duration.HumanDuration(time.Since(info.CreationTimestamp.Time))
+		}
+		columns = append(columns, age)
+	}
+	if opts.ShowSize {
+		size := ""
+		if info.Size != nil {
+			size = info.Size.String()
+		}
+		columns = append(columns, valueOrNone(size))
+	}
+	if opts.ShowCost {
+		cost := ""
+		if info.MonthlyCost != nil {
+			cost = info.MonthlyCost.String()
+		}
+		columns = append(columns, valueOrNone(cost))
+	}
+	if opts.Wide {
+		columns = append(columns, valueOrNone(info.Owners), valueOrNone(info.ManagedBy), valueOrNone(info.HelmRelease))
+	}
+	return columns
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
@@ -0,0 +1,96 @@
+package kor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+)
+
+func TestEnrichResources(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	configmap := CreateTestConfigmap(testNamespace, "cm-1", AppLabels)
+	configmap.CreationTimestamp = metav1.NewTime(time.Now().Add(-50 * time.Hour))
+	configmap.Data = map[string]string{"key": "0123456789"}
+	if _, err := clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), configmap, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating fake configmap: %v", err)
+	}
+
+	pvc := CreateTestPvc(testNamespace, "pvc-1", AppLabels, "standard")
+	pvc.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("5Gi")}
+	if _, err := clientset.CoreV1().PersistentVolumeClaims(testNamespace).Create(context.TODO(), pvc, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating fake pvc: %v", err)
+	}
+
+	resources := map[string]map[string][]ResourceInfo{
+		testNamespace: {
+			"ConfigMap": {{Name: "cm-1"}},
+			"Pvc":       {{Name: "pvc-1"}},
+			"Service":   {{Name: "missing-svc"}},
+		},
+	}
+	opts := common.Opts{GroupBy: "namespace", ShowAge: true, ShowSize: true}
+
+	enrichResources(context.Background(), clientset, resources, opts)
+
+	cm := resources[testNamespace]["ConfigMap"][0]
+	if cm.CreationTimestamp == nil || cm.Size == nil || cm.Size.Value() != 10 {
+		t.Errorf("Expected configmap creation time and a size of 10 bytes, got %+v", cm)
+	}
+	if size := resources[testNamespace]["Pvc"][0].Size; size == nil || size.String() != "5Gi" {
+		t.Errorf("Expected pvc size 5Gi, got %v", size)
+	}
+	if svc := resources[testNamespace]["Service"][0]; svc.CreationTimestamp != nil {
+		t.Errorf("Expected missing service to be left untouched, got %+v", svc)
+	}
+
+	output := FormatOutput(resources, opts)
+	for _, expected := range []string{"AGE", "SIZE", "2d2h", "5Gi", "<unknown>"} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("Expected table output to contain %q, got:\n%s", expected, output.String())
+		}
+	}
+}
+
+func TestEnrichResourcesWide(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	secret := CreateTestSecret(testNamespace, "secret-1", map[string]string{"app.kubernetes.io/managed-by": "Helm"})
+	secret.Annotations = map[string]string{"meta.helm.sh/release-name": "my-release"}
+	secret.OwnerReferences = []metav1.OwnerReference{{Kind: "Deployment", Name: "my-app"}}
+	if _, err := clientset.CoreV1().Secrets(testNamespace).Create(context.TODO(), secret, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating fake secret: %v", err)
+	}
+
+	resources := map[string]map[string][]ResourceInfo{
+		"Secret": {
+			testNamespace: {{Name: "secret-1"}},
+		},
+	}
+	opts := common.Opts{GroupBy: "resource", Wide: true}
+
+	enrichResources(context.Background(), clientset, resources, opts)
+
+	info := resources["Secret"][testNamespace][0]
+	if info.Owners != "Deployment/my-app" {
+		t.Errorf("Expected owner Deployment/my-app, got %v", info.Owners)
+	}
+	if info.ManagedBy != "Helm" || info.HelmRelease != "my-release" {
+		t.Errorf("Expected Helm managed secret of release my-release, got %+v", info)
+	}
+
+	output := FormatOutput(resources, opts)
+	for _, expected := range []string{"OWNERS", "MANAGED BY", "HELM RELEASE", "Deployment/my-app", "my-release"} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("Expected table output to contain %q, got:\n%s", expected, output.String())
+		}
+	}
+}
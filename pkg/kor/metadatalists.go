@@ -0,0 +1,87 @@
+package kor
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/rest"
+
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+// metadataClients are the metadata clients of the clientsets created by
+// GetKubeClient and NewClients, by clientset.
+var metadataClients sync.Map
+
+// metadataObject is an object of a namespace, typed or only its metadata.
+type metadataObject interface {
+	metav1.Object
+	runtime.Object
+}
+
+// registerMetadataClient makes the scans with clientset list the candidate
+// ConfigMaps and Secrets with a metadata client of config, so only their
+// metadata is transferred and not their data.
+func registerMetadataClient(clientset kubernetes.Interface, config *rest.Config) error {
+	client, err := metadata.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	metadataClients.Store(clientset, client)
+	return nil
+}
+
+// metadataClientFor returns the metadata client registered for clientset.
+// Clientsets served from cluster-wide lists or informer caches, and the ones
+// created outside of kor, have none.
+func metadataClientFor(clientset kubernetes.Interface) (metadata.Interface, bool) {
+	client, ok := metadataClients.Load(clientset)
+	if !ok {
+		return nil, false
+	}
+	return client.(metadata.Interface), true
+}
+
+// listObjectMetadata lists the objects of gvk in namespace with the metadata
+// client of clientset, or with list when it has none. The metadata lists are
+// given the kind of the objects for the filters to match them.
+func listObjectMetadata[L runtime.Object](ctx context.Context, clientset kubernetes.Interface, gvk schema.GroupVersionKind, namespace string, options metav1.ListOptions, list func(context.Context, metav1.ListOptions) (L, error)) ([]metadataObject, error) {
+	client, ok := metadataClientFor(clientset)
+	if !ok {
+		typed, err := utils.ListAll(ctx, options, list)
+		if err != nil {
+			return nil, err
+		}
+		items, err := meta.ExtractList(typed)
+		if err != nil {
+			return nil, err
+		}
+		objects := make([]metadataObject, 0, len(items))
+		for _, item := range items {
+			if object, ok := item.(metadataObject); ok {
+				objects = append(objects, object)
+			}
+		}
+		return objects, nil
+	}
+
+	gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+	partial, err := utils.ListAll(ctx, options, client.Resource(gvr).Namespace(namespace).List)
+	if err != nil {
+		return nil, err
+	}
+	apiVersion, kind := gvk.ToAPIVersionAndKind()
+	objects := make([]metadataObject, 0, len(partial.Items))
+	for i := range partial.Items {
+		object := &partial.Items[i]
+		object.APIVersion, object.Kind = apiVersion, kind
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
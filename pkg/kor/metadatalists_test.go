@@ -0,0 +1,77 @@
+package kor
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	metadatafake "k8s.io/client-go/metadata/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func partialObject(kind, name string, labels map[string]string) *metav1.PartialObjectMetadata {
+	return &metav1.PartialObjectMetadata{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: kind},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace, Labels: labels},
+	}
+}
+
+func resourceInfoNames(infos []ResourceInfo) []string {
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name)
+	}
+	return names
+}
+
+func TestMetadataLists(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	scheme := metadatafake.NewTestScheme()
+	if err := metav1.AddMetaToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	metadataClient := metadatafake.NewSimpleMetadataClient(scheme,
+		partialObject("ConfigMap", "configmap-1", nil),
+		partialObject("ConfigMap", "configmap-marked", map[string]string{"kor/used": "false"}),
+		partialObject("Secret", "secret-1", nil),
+		partialObject("Secret", "secret-marked", map[string]string{"kor/used": "false"}),
+	)
+	metadataClients.Store(clientset, metadataClient)
+	defer metadataClients.Delete(clientset)
+
+	configmaps, err := processNamespaceCM(context.TODO(), clientset, testNamespace, &filters.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := resourceInfoNames(configmaps); !slices.Equal(names, []string{"configmap-1", "configmap-marked"}) {
+		t.Errorf("Expected the configmaps listed from their metadata, got %v", names)
+	}
+
+	secrets, err := processNamespaceSecret(context.TODO(), clientset, testNamespace, &filters.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := resourceInfoNames(secrets); !slices.Equal(names, []string{"secret-1", "secret-marked"}) {
+		t.Errorf("Expected the secrets listed from their metadata, got %v", names)
+	}
+
+	for _, resource := range []string{"configmaps", "secrets"} {
+		if lists := listActions(clientset, resource); len(lists) != 0 {
+			t.Errorf("Expected no typed list of %s, got %v", resource, lists)
+		}
+	}
+	var secretLists []k8stesting.ListRestrictions
+	for _, action := range metadataClient.Actions() {
+		if list, ok := action.(k8stesting.ListAction); ok && action.GetResource().Resource == "secrets" {
+			secretLists = append(secretLists, list.GetListRestrictions())
+		}
+	}
+	if len(secretLists) != 2 || !strings.Contains(secretLists[0].Fields.String(), "type!=kubernetes.io/dockerconfigjson") || secretLists[1].Labels.String() != "kor/used=false" {
+		t.Errorf("Expected the exception types to be left out and the marked secrets listed apart, got %+v", secretLists)
+	}
+}
@@ -2,9 +2,10 @@ package kor
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"os"
+	"log/slog"
 	"strings"
 
 	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
@@ -15,28 +16,15 @@ import (
 	"github.com/yonahd/kor/pkg/filters"
 )
 
-func retrieveNoNamespaceDiff(clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, resourceList []string, filterOpts *filters.Options) ([]ResourceDiff, []string) {
+func retrieveNoNamespaceDiff(ctx context.Context, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, resourceList []string, filterOpts *filters.Options) ([]ResourceDiff, []string) {
 	var noNamespaceDiff []ResourceDiff
 	markedForRemoval := make([]bool, len(resourceList))
 	updatedResourceList := resourceList
 
+	clients := Clients{Kubernetes: clientset, APIExtensions: apiExtClient, Dynamic: dynamicClient}
 	for counter, resource := range resourceList {
-		switch resource {
-		case "crd", "crds", "customresourcedefinition", "customresourcedefinitions":
-			crdDiff := getUnusedCrds(apiExtClient, dynamicClient, filterOpts)
-			noNamespaceDiff = append(noNamespaceDiff, crdDiff)
-			markedForRemoval[counter] = true
-		case "pv", "persistentvolume", "persistentvolumes":
-			pvDiff := getUnusedPvs(clientset, filterOpts)
-			noNamespaceDiff = append(noNamespaceDiff, pvDiff)
-			markedForRemoval[counter] = true
-		case "clusterrole", "clusterroles":
-			clusterRoleDiff := getUnusedClusterRoles(clientset, filterOpts)
-			noNamespaceDiff = append(noNamespaceDiff, clusterRoleDiff)
-			markedForRemoval[counter] = true
-		case "sc", "storageclass", "storageclasses":
-			storageClassDiff := getUnusedStorageClasses(clientset, filterOpts)
-			noNamespaceDiff = append(noNamespaceDiff, storageClassDiff)
+		if detector := lookupDetector(resource); detector != nil && isClusterScoped(detector) {
+			noNamespaceDiff = append(noNamespaceDiff, detectorDiffs(ctx, detector, clients, "", filterOpts)...)
 			markedForRemoval[counter] = true
 		}
 	}
@@ -52,56 +40,42 @@ func retrieveNoNamespaceDiff(clientset kubernetes.Interface, apiExtClient apiext
 	return noNamespaceDiff, clearedResourceList
 }
 
-func retrieveNamespaceDiffs(clientset kubernetes.Interface, namespace string, resourceList []string, filterOpts *filters.Options) []ResourceDiff {
+func retrieveNamespaceDiffs(ctx context.Context, clientset kubernetes.Interface, dynamicClient dynamic.Interface, namespace string, resourceList []string, filterOpts *filters.Options) []ResourceDiff {
 	var allDiffs []ResourceDiff
+	clients := Clients{Kubernetes: clientset, Dynamic: dynamicClient}
 	for _, resource := range resourceList {
-		var diffResult ResourceDiff
-		switch resource {
-		case "cm", "configmap", "configmaps":
-			diffResult = getUnusedCMs(clientset, namespace, filterOpts)
-		case "svc", "service", "services":
-			diffResult = getUnusedSVCs(clientset, namespace, filterOpts)
-		case "secret", "secrets":
-			diffResult = getUnusedSecrets(clientset, namespace, filterOpts)
-		case "sa", "serviceaccount", "serviceaccounts":
-			diffResult = getUnusedServiceAccounts(clientset, namespace, filterOpts)
-		case "deploy", "deployment", "deployments":
-			diffResult = getUnusedDeployments(clientset, namespace, filterOpts)
-		case "sts", "statefulset", "statefulsets":
-			diffResult = getUnusedStatefulSets(clientset, namespace, filterOpts)
-		case "role", "roles":
-			diffResult = getUnusedRoles(clientset, namespace, filterOpts)
-		case "hpa", "horizontalpodautoscaler", "horizontalpodautoscalers":
-			diffResult = getUnusedHpas(clientset, namespace, filterOpts)
-		case "pvc", "persistentvolumeclaim", "persistentvolumeclaims":
-			diffResult = getUnusedPvcs(clientset, namespace, filterOpts)
-		case "ing", "ingress", "ingresses":
-			diffResult = getUnusedIngresses(clientset, namespace, filterOpts)
-		case "pdb", "poddisruptionbudget", "poddisruptionbudgets":
-			diffResult = getUnusedPdbs(clientset, namespace, filterOpts)
-		case "po", "pod", "pods":
-			diffResult = getUnusedPods(clientset, namespace, filterOpts)
-		case "job", "jobs":
-			diffResult = getUnusedJobs(clientset, namespace, filterOpts)
-		case "rs", "replicaset", "replicasets":
-			diffResult = getUnusedReplicaSets(clientset, namespace, filterOpts)
-		case "ds", "daemonset", "daemonsets":
-			diffResult = getUnusedDaemonSets(clientset, namespace, filterOpts)
-		case "netpol", "networkpolicy", "networkpolicies":
-			diffResult = getUnusedNetworkPolicies(clientset, namespace, filterOpts)
-		case "rolebinding", "rolebindings":
-			diffResult = getUnusedNetworkPolicies(clientset, namespace, filterOpts)
-		default:
+		detector := lookupDetector(resource)
+		if detector == nil || isClusterScoped(detector) {
 			fmt.Printf("resource type %q is not supported\n", resource)
+			continue
 		}
-		allDiffs = append(allDiffs, diffResult)
+		allDiffs = append(allDiffs, detectorDiffs(ctx, detector, clients, namespace, filterOpts)...)
 	}
 	return allDiffs
 }
 
-func GetUnusedMulti(resourceNames string, filterOpts *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
+// collectResources runs the detectors of resourceList and groups the
+// findings, without exporting or deleting them.
+func collectResources(ctx context.Context, resourceList []string, filterOpts *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, groupBy string) map[string]map[string][]ResourceInfo {
+	clientset = scanClientset(clientset)
+	resources := make(map[string]map[string][]ResourceInfo)
+	noNamespaceDiff, resourceList := retrieveNoNamespaceDiff(ctx, clientset, apiExtClient, dynamicClient, resourceList, filterOpts)
+	groupResourceDiffs(resources, "", noNamespaceDiff, groupBy)
+	if len(resourceList) == 0 {
+		return resources
+	}
+	for _, scan := range scanNamespaces(filterOpts.Namespaces(ctx, clientset), func(namespace string) ([]ResourceDiff, error) {
+		return retrieveNamespaceDiffs(ctx, clientset, dynamicClient, namespace, resourceList, filterOpts), nil
+	}) {
+		groupResourceDiffs(resources, scan.namespace, scan.result, groupBy)
+	}
+	return resources
+}
+
+func GetUnusedMulti(ctx context.Context, resourceNames string, filterOpts *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
+	clientset = scanClientset(clientset)
 	resourceList := strings.Split(resourceNames, ",")
-	namespaces := filterOpts.Namespaces(clientset)
+	namespaces := filterOpts.Namespaces(ctx, clientset)
 	resources := make(map[string]map[string][]ResourceInfo)
 	var err error
 
@@ -109,13 +83,14 @@ func GetUnusedMulti(resourceNames string, filterOpts *filters.Options, clientset
 		resources[""] = make(map[string][]ResourceInfo)
 	}
 
-	noNamespaceDiff, resourceList := retrieveNoNamespaceDiff(clientset, apiExtClient, dynamicClient, resourceList, filterOpts)
+	noNamespaceDiff, resourceList := retrieveNoNamespaceDiff(ctx, clientset, apiExtClient, dynamicClient, resourceList, filterOpts)
 	if len(noNamespaceDiff) != 0 {
 		for _, diff := range noNamespaceDiff {
 			if len(diff.diff) != 0 {
-				if opts.DeleteFlag {
-					if diff.diff, err = DeleteResource(diff.diff, clientset, "", diff.resourceType, opts.NoInteractive); err != nil {
-						fmt.Fprintf(os.Stderr, "Failed to delete %s %s: %v\n", diff.resourceType, diff.diff, err)
+				exportManifests(ctx, clientset, "", diff.resourceType, diff.diff, opts)
+				if deleteEnabled(opts, diff.resourceType) {
+					if diff.diff, err = DeleteResource(ctx, diff.diff, clientset, "", diff.resourceType, opts.NoInteractive); err != nil {
+						slog.Error("Failed to delete resource", "resource", diff.resourceType, "name", diff.diff, "error", err)
 					}
 				}
 				switch opts.GroupBy {
@@ -128,16 +103,20 @@ func GetUnusedMulti(resourceNames string, filterOpts *filters.Options, clientset
 		}
 	}
 
-	for _, namespace := range namespaces {
-		allDiffs := retrieveNamespaceDiffs(clientset, namespace, resourceList, filterOpts)
+	for _, scan := range scanNamespaces(namespaces, func(namespace string) ([]ResourceDiff, error) {
+		return retrieveNamespaceDiffs(ctx, clientset, dynamicClient, namespace, resourceList, filterOpts), nil
+	}) {
+		namespace, allDiffs := scan.namespace, scan.result
 		if opts.GroupBy == "namespace" {
 			resources[namespace] = make(map[string][]ResourceInfo)
 		}
 
 		for _, diff := range allDiffs {
-			if opts.DeleteFlag {
-				if diff.diff, err = DeleteResource(diff.diff, clientset, namespace, diff.resourceType, opts.NoInteractive); err != nil {
-					fmt.Fprintf(os.Stderr, "Failed to delete %s %s in namespace %s: %v\n", diff.resourceType, diff.diff, namespace, err)
+			progress.addFindings(len(diff.diff))
+			exportManifests(ctx, clientset, namespace, diff.resourceType, diff.diff, opts)
+			if deleteEnabled(opts, diff.resourceType) {
+				if diff.diff, err = DeleteResource(ctx, diff.diff, clientset, namespace, diff.resourceType, opts.NoInteractive); err != nil {
+					slog.Error("Failed to delete resource", "resource", diff.resourceType, "name", diff.diff, "namespace", namespace, "error", err)
 				}
 			}
 			switch opts.GroupBy {
@@ -149,12 +128,15 @@ func GetUnusedMulti(resourceNames string, filterOpts *filters.Options, clientset
 		}
 	}
 
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
 	var outputBuffer bytes.Buffer
 	var jsonResponse []byte
 	switch outputFormat {
 	case "table":
 		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
+	default:
 		var err error
 		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
 			return "", err
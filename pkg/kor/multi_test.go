@@ -46,7 +46,7 @@ func TestRetrieveNamespaceDiff(t *testing.T) {
 	resourceList := []string{"cm", "pdb", "deployment"}
 	filterOpts := &filters.Options{}
 
-	namespaceDiff := retrieveNamespaceDiffs(clientset, testNamespace, resourceList, filterOpts)
+	namespaceDiff := retrieveNamespaceDiffs(context.Background(), clientset, nil, testNamespace, resourceList, filterOpts)
 
 	if len(namespaceDiff) != 3 {
 		t.Fatalf("Expected 3 diffs, got %d", len(namespaceDiff))
@@ -79,7 +79,7 @@ func TestGetUnusedMulti(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedMulti(resourceList, &filters.Options{}, clientset, nil, nil, "json", opts)
+	output, err := GetUnusedMulti(context.Background(), resourceList, &filters.Options{}, clientset, nil, nil, "json", opts)
 
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedMulti: %v", err)
@@ -106,3 +106,17 @@ func TestGetUnusedMulti(t *testing.T) {
 	}
 
 }
+
+func TestRetrieveNamespaceDiffRoleBindings(t *testing.T) {
+	clientset := createTestMultiResources(t)
+
+	namespaceDiff := retrieveNamespaceDiffs(context.Background(), clientset, nil, testNamespace, []string{"rolebinding", "unknown"}, &filters.Options{})
+
+	if len(namespaceDiff) != 1 {
+		t.Fatalf("Expected 1 diff, got %d", len(namespaceDiff))
+	}
+
+	if namespaceDiff[0].resourceType != "RoleBinding" {
+		t.Fatalf("Expected RoleBinding, got %s", namespaceDiff[0].resourceType)
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
+	"log/slog"
 	"slices"
 
 	v1 "k8s.io/api/core/v1"
@@ -15,6 +15,7 @@ import (
 
 	"github.com/yonahd/kor/pkg/common"
 	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
 )
 
 const (
@@ -23,14 +24,14 @@ const (
 	noPodAppliedByRulesReason = "NetworkPolicy Ingress and Egress rules apply to 0 pods"
 )
 
-func retrievePodsForSelector(clientset kubernetes.Interface, namespace string, selector *metav1.LabelSelector) ([]v1.Pod, error) {
+func retrievePodsForSelector(ctx context.Context, clientset kubernetes.Interface, namespace string, selector *metav1.LabelSelector) ([]v1.Pod, error) {
 	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
 	if err != nil {
 		return nil, err
 	}
-	podList, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
+	podList, err := utils.ListAll(ctx, metav1.ListOptions{
 		LabelSelector: labelSelector.String(),
-	})
+	}, clientset.CoreV1().Pods(namespace).List)
 	if err != nil {
 		return nil, err
 	}
@@ -38,7 +39,7 @@ func retrievePodsForSelector(clientset kubernetes.Interface, namespace string, s
 	return podList.Items, nil
 }
 
-func isAnyPodMatchedInSources(clientset kubernetes.Interface, sources []networkingv1.NetworkPolicyPeer) (bool, error) {
+func isAnyPodMatchedInSources(ctx context.Context, clientset kubernetes.Interface, sources []networkingv1.NetworkPolicyPeer) (bool, error) {
 	// If this field is empty or missing, this rule matches all pods
 	if len(sources) == 0 {
 		return true, nil
@@ -55,15 +56,15 @@ func isAnyPodMatchedInSources(clientset kubernetes.Interface, sources []networki
 			return false, err
 		}
 
-		nsList, err := clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{
+		nsList, err := utils.ListAll(ctx, metav1.ListOptions{
 			LabelSelector: labelSelector.String(),
-		})
+		}, clientset.CoreV1().Namespaces().List)
 		if err != nil {
 			return false, err
 		}
 
 		for _, ns := range nsList.Items {
-			podList, err := retrievePodsForSelector(clientset, ns.Name, netpolPeer.PodSelector)
+			podList, err := retrievePodsForSelector(ctx, clientset, ns.Name, netpolPeer.PodSelector)
 			if err != nil {
 				return false, err
 			}
@@ -77,13 +78,13 @@ func isAnyPodMatchedInSources(clientset kubernetes.Interface, sources []networki
 	return false, nil
 }
 
-func isAnyIngressRuleUsed(clientset kubernetes.Interface, netpol networkingv1.NetworkPolicy) (bool, error) {
+func isAnyIngressRuleUsed(ctx context.Context, clientset kubernetes.Interface, netpol networkingv1.NetworkPolicy) (bool, error) {
 	// Deny all ingress traffic
 	if len(netpol.Spec.Ingress) == 0 && slices.Contains(netpol.Spec.PolicyTypes, networkingv1.PolicyTypeIngress) {
 		return true, nil
 	}
 	for _, ingressRule := range netpol.Spec.Ingress {
-		podsMatched, err := isAnyPodMatchedInSources(clientset, ingressRule.From)
+		podsMatched, err := isAnyPodMatchedInSources(ctx, clientset, ingressRule.From)
 		if err != nil {
 			return false, err
 		}
@@ -96,14 +97,14 @@ func isAnyIngressRuleUsed(clientset kubernetes.Interface, netpol networkingv1.Ne
 	return false, nil
 }
 
-func isAnyEgressRuleUsed(clientset kubernetes.Interface, netpol networkingv1.NetworkPolicy) (bool, error) {
+func isAnyEgressRuleUsed(ctx context.Context, clientset kubernetes.Interface, netpol networkingv1.NetworkPolicy) (bool, error) {
 	// Deny all egress traffic
 	if len(netpol.Spec.Egress) == 0 && slices.Contains(netpol.Spec.PolicyTypes, networkingv1.PolicyTypeEgress) {
 		return true, nil
 	}
 
 	for _, egressRule := range netpol.Spec.Egress {
-		podsMatched, err := isAnyPodMatchedInSources(clientset, egressRule.To)
+		podsMatched, err := isAnyPodMatchedInSources(ctx, clientset, egressRule.To)
 		if err != nil {
 			return false, err
 		}
@@ -116,8 +117,8 @@ func isAnyEgressRuleUsed(clientset kubernetes.Interface, netpol networkingv1.Net
 	return false, nil
 }
 
-func processNamespaceNetworkPolicies(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
-	netpolList, err := clientset.NetworkingV1().NetworkPolicies(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func processNamespaceNetworkPolicies(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	netpolList, err := utils.ListAll(ctx, filterOpts.ListOptions(), clientset.NetworkingV1().NetworkPolicies(namespace).List)
 	if err != nil {
 		return nil, err
 	}
@@ -134,7 +135,7 @@ func processNamespaceNetworkPolicies(clientset kubernetes.Interface, namespace s
 			continue
 		}
 
-		pods, err := retrievePodsForSelector(clientset, namespace, &netpol.Spec.PodSelector)
+		pods, err := retrievePodsForSelector(ctx, clientset, namespace, &netpol.Spec.PodSelector)
 		if err != nil {
 			return nil, err
 		}
@@ -144,13 +145,13 @@ func processNamespaceNetworkPolicies(clientset kubernetes.Interface, namespace s
 			continue
 		}
 
-		if used, err := isAnyIngressRuleUsed(clientset, netpol); err != nil {
+		if used, err := isAnyIngressRuleUsed(ctx, clientset, netpol); err != nil {
 			return nil, err
 		} else if used {
 			continue
 		}
 
-		if used, err := isAnyEgressRuleUsed(clientset, netpol); err != nil {
+		if used, err := isAnyEgressRuleUsed(ctx, clientset, netpol); err != nil {
 			return nil, err
 		} else if used {
 			continue
@@ -162,18 +163,22 @@ func processNamespaceNetworkPolicies(clientset kubernetes.Interface, namespace s
 	return unusedNetpols, nil
 }
 
-func GetUnusedNetworkPolicies(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+func GetUnusedNetworkPolicies(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	clientset = scanClientset(clientset)
 	resources := make(map[string]map[string][]ResourceInfo)
 
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceNetworkPolicies(clientset, namespace, filterOpts)
+	for _, scan := range scanNamespaces(filterOpts.Namespaces(ctx, clientset), func(namespace string) ([]ResourceInfo, error) {
+		return processNamespaceNetworkPolicies(ctx, clientset, namespace, filterOpts)
+	}) {
+		namespace, diff, err := scan.namespace, scan.result, scan.err
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
+			recordScanError("NetworkPolicy", namespace, err)
 			continue
 		}
-		if opts.DeleteFlag {
-			if diff, err := DeleteResource(diff, clientset, namespace, "NetworkPolicy", opts.NoInteractive); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to delete NetworkPolicy %s in namespace %s: %v\n", diff, namespace, err)
+		exportManifests(ctx, clientset, namespace, "NetworkPolicy", diff, opts)
+		if deleteEnabled(opts, "NetworkPolicy") {
+			if diff, err := DeleteResource(ctx, diff, clientset, namespace, "NetworkPolicy", opts.NoInteractive); err != nil {
+				slog.Error("Failed to delete NetworkPolicy", "name", diff, "namespace", namespace, "error", err)
 			}
 		}
 		switch opts.GroupBy {
@@ -185,13 +190,16 @@ func GetUnusedNetworkPolicies(filterOpts *filters.Options, clientset kubernetes.
 		}
 	}
 
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
 	var outputBuffer bytes.Buffer
 	var jsonResponse []byte
 
 	switch outputFormat {
 	case "table":
 		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
+	default:
 		var err error
 		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
 			return "", err
@@ -130,7 +130,7 @@ func TestRetrievePodsForSelector(t *testing.T) {
 			"app.kubernetes.io/version": "v1",
 		},
 	}
-	pods, err := retrievePodsForSelector(clientset, testNamespace, selector)
+	pods, err := retrievePodsForSelector(context.Background(), clientset, testNamespace, selector)
 	if err != nil {
 		t.Errorf("Error retrieving pods for selector %v: %v", selector, err)
 	}
@@ -163,7 +163,7 @@ func TestIsAnyPodMatchedInSources(t *testing.T) {
 		},
 	}
 
-	matched, err := isAnyPodMatchedInSources(clientset, sources)
+	matched, err := isAnyPodMatchedInSources(context.Background(), clientset, sources)
 	if err != nil {
 		t.Errorf("Error checking if sources match any pods: %v", err)
 	}
@@ -178,7 +178,7 @@ func TestIsAnyIngressRuleUsed(t *testing.T) {
 
 	netpol := CreateTestNetworkPolicy("netpol-0", testNamespace, AppLabels, v1.LabelSelector{}, nil, nil)
 
-	used, err := isAnyIngressRuleUsed(clientset, *netpol)
+	used, err := isAnyIngressRuleUsed(context.Background(), clientset, *netpol)
 	if err != nil {
 		t.Errorf("Error checking if any ingress rule is used: %v", err)
 	}
@@ -193,7 +193,7 @@ func TestIsAnyEgressRuleUsed(t *testing.T) {
 
 	netpol := CreateTestNetworkPolicy("netpol-0", testNamespace, AppLabels, v1.LabelSelector{}, nil, nil)
 
-	used, err := isAnyEgressRuleUsed(clientset, *netpol)
+	used, err := isAnyEgressRuleUsed(context.Background(), clientset, *netpol)
 	if err != nil {
 		t.Errorf("Error checking if any egress rule is used: %v", err)
 	}
@@ -206,7 +206,7 @@ func TestIsAnyEgressRuleUsed(t *testing.T) {
 func TestProcessNamespaceNetworkPolicies(t *testing.T) {
 	clientset := createTestNetworkPolicies(t)
 
-	unusedNetpols, err := processNamespaceNetworkPolicies(clientset, testNamespace, &filters.Options{})
+	unusedNetpols, err := processNamespaceNetworkPolicies(context.Background(), clientset, testNamespace, &filters.Options{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -241,7 +241,7 @@ func TestGetUnusedNetworkPolicies(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedNetworkPolicies(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedNetworkPolicies(context.Background(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedNetworkPolicies: %v", err)
 	}
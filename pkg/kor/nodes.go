@@ -0,0 +1,183 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+const (
+	DefaultNodeCordonedFor          = 24 * time.Hour
+	DefaultNodeUtilisationThreshold = 5.0
+)
+
+// nodeUsage holds the requests of the non-DaemonSet pods scheduled on a node.
+type nodeUsage struct {
+	pods        int
+	cpuMilli    int64
+	memoryBytes int64
+}
+
+func isDaemonSetPod(pod corev1.Pod) bool {
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == "DaemonSet" {
+			return true
+		}
+	}
+	return false
+}
+
+func retrieveNodeUsage(ctx context.Context, clientset kubernetes.Interface) (map[string]*nodeUsage, error) {
+	pods, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.CoreV1().Pods("").List)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make(map[string]*nodeUsage)
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == "" || isDaemonSetPod(pod) {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+
+		u, ok := usage[pod.Spec.NodeName]
+		if !ok {
+			u = &nodeUsage{}
+			usage[pod.Spec.NodeName] = u
+		}
+		u.pods++
+		for _, container := range pod.Spec.Containers {
+			u.cpuMilli += container.Resources.Requests.Cpu().MilliValue()
+			u.memoryBytes += container.Resources.Requests.Memory().Value()
+		}
+	}
+	return usage, nil
+}
+
+// cordonedSince returns when the node was cordoned, if the unschedulable
+// taint recorded it.
+func cordonedSince(node corev1.Node) *time.Time {
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == corev1.TaintNodeUnschedulable && taint.TimeAdded != nil {
+			return &taint.TimeAdded.Time
+		}
+	}
+	return nil
+}
+
+func percentOf(used, allocatable int64) float64 {
+	if allocatable == 0 {
+		return 0
+	}
+	return float64(used) * 100 / float64(allocatable)
+}
+
+func processNodes(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options, cordonedFor time.Duration, utilisationThreshold float64) ([]ResourceInfo, error) {
+	nodes, err := utils.ListAll(ctx, metav1.ListOptions{LabelSelector: filterOpts.LabelSelector()}, clientset.CoreV1().Nodes().List)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := retrieveNodeUsage(ctx, clientset)
+	if err != nil {
+		return nil, err
+	}
+
+	var unusedNodes []ResourceInfo
+
+	for _, node := range nodes.Items {
+		if pass, _ := filter.SetObject(&node).Run(filterOpts, filters.LabelFilterName, filters.AgeFilterName); pass {
+			continue
+		}
+
+		if node.Labels["kor/used"] == "false" {
+			unusedNodes = append(unusedNodes, ResourceInfo{Name: node.Name, Reason: "Marked with unused label"})
+			continue
+		}
+
+		u, ok := usage[node.Name]
+		if !ok {
+			u = &nodeUsage{}
+		}
+
+		if node.Spec.Unschedulable {
+			if u.pods > 0 {
+				continue
+			}
+			since := cordonedSince(node)
+			if since == nil {
+				// Without the time of the taint the node may have been
+				// cordoned a moment ago, only --cordoned-for 0 reports it
+				if cordonedFor <= 0 {
+					unusedNodes = append(unusedNodes, ResourceInfo{Name: node.Name, Reason: "Node is cordoned and runs only DaemonSet pods"})
+				}
+			} else if time.Since(*since) >= cordonedFor {
+				reason := fmt.Sprintf("Node has been cordoned since %s and runs only DaemonSet pods", since.UTC().Format(time.RFC3339))
+				unusedNodes = append(unusedNodes, ResourceInfo{Name: node.Name, Reason: reason})
+			}
+			continue
+		}
+
+		if utilisationThreshold <= 0 {
+			continue
+		}
+		cpuPercent := percentOf(u.cpuMilli, node.Status.Allocatable.Cpu().MilliValue())
+		memoryPercent := percentOf(u.memoryBytes, node.Status.Allocatable.Memory().Value())
+		if cpuPercent < utilisationThreshold && memoryPercent < utilisationThreshold {
+			reason := fmt.Sprintf("Node requests are below %g%% of allocatable (cpu %.1f%%, memory %.1f%%)", utilisationThreshold, cpuPercent, memoryPercent)
+			unusedNodes = append(unusedNodes, ResourceInfo{Name: node.Name, Reason: reason})
+		}
+	}
+
+	return unusedNodes, nil
+}
+
+func GetUnusedNodes(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts, cordonedFor time.Duration, utilisationThreshold float64) (string, error) {
+	resources := make(map[string]map[string][]ResourceInfo)
+	diff, err := processNodes(ctx, clientset, filterOpts, cordonedFor, utilisationThreshold)
+	if err != nil {
+		recordScanError("Node", "", err)
+	}
+	switch opts.GroupBy {
+	case "namespace":
+		resources[""] = make(map[string][]ResourceInfo)
+		resources[""]["Node"] = diff
+	case "resource":
+		appendResources(resources, "Node", "", diff)
+	}
+
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+	default:
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	unusedNodes, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return unusedNodes, nil
+}
@@ -0,0 +1,118 @@
+package kor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestNode(name string, unschedulable bool, cordonedAt *time.Time) *corev1.Node {
+	node := &corev1.Node{
+		ObjectMeta: v1.ObjectMeta{Name: name},
+		Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+			},
+		},
+	}
+	if cordonedAt != nil {
+		node.Spec.Taints = []corev1.Taint{{
+			Key:       corev1.TaintNodeUnschedulable,
+			Effect:    corev1.TaintEffectNoSchedule,
+			TimeAdded: &v1.Time{Time: *cordonedAt},
+		}}
+	}
+	return node
+}
+
+func createTestNodePod(name, nodeName, cpu string, daemonSet bool) *corev1.Pod {
+	pod := CreateTestPod(testNamespace, name, "", nil, AppLabels)
+	pod.Spec.NodeName = nodeName
+	pod.Spec.Containers = []corev1.Container{{
+		Name: "app",
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+		},
+	}}
+	if daemonSet {
+		pod.OwnerReferences = []v1.OwnerReference{{Kind: "DaemonSet", Name: "test-ds"}}
+	}
+	return pod
+}
+
+func TestProcessNodes(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	longAgo := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	recently := time.Now().Add(-time.Hour)
+	nodes := []*corev1.Node{
+		createTestNode("busy-node", false, nil),
+		createTestNode("idle-node", false, nil),
+		createTestNode("cordoned-old", true, &longAgo),
+		createTestNode("cordoned-recent", true, &recently),
+		createTestNode("cordoned-busy", true, &longAgo),
+	}
+	for _, node := range nodes {
+		if _, err := clientset.CoreV1().Nodes().Create(context.TODO(), node, v1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating fake %s: %v", "Node", err)
+		}
+	}
+
+	pods := []*corev1.Pod{
+		createTestNodePod("busy-pod", "busy-node", "2", false),
+		createTestNodePod("idle-ds-pod", "idle-node", "2", true),
+		createTestNodePod("cordoned-ds-pod", "cordoned-old", "1", true),
+		createTestNodePod("cordoned-busy-pod", "cordoned-busy", "100m", false),
+	}
+	for _, pod := range pods {
+		if _, err := clientset.CoreV1().Pods(testNamespace).Create(context.TODO(), pod, v1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating fake %s: %v", "Pod", err)
+		}
+	}
+
+	unusedNodes, err := processNodes(context.Background(), clientset, &filters.Options{}, DefaultNodeCordonedFor, DefaultNodeUtilisationThreshold)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []ResourceInfo{
+		{Name: "cordoned-old", Reason: "Node has been cordoned since 2024-01-01T00:00:00Z and runs only DaemonSet pods"},
+		{Name: "idle-node", Reason: "Node requests are below 5% of allocatable (cpu 0.0%, memory 0.0%)"},
+	}
+	if !equalResourceInfoSlices(unusedNodes, expected) {
+		t.Errorf("Expected %v, got %v", expected, unusedNodes)
+	}
+}
+
+func TestProcessNodesCordonedWithoutTime(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	if _, err := clientset.CoreV1().Nodes().Create(context.TODO(), createTestNode("cordoned-untimed", true, nil), v1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating fake %s: %v", "Node", err)
+	}
+
+	unusedNodes, err := processNodes(context.Background(), clientset, &filters.Options{}, DefaultNodeCordonedFor, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(unusedNodes) != 0 {
+		t.Errorf("Expected a node cordoned at an unknown time to wait for --cordoned-for, got %v", unusedNodes)
+	}
+
+	unusedNodes, err = processNodes(context.Background(), clientset, &filters.Options{}, 0, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []ResourceInfo{{Name: "cordoned-untimed", Reason: "Node is cordoned and runs only DaemonSet pods"}}
+	if !equalResourceInfoSlices(unusedNodes, expected) {
+		t.Errorf("Expected %v, got %v", expected, unusedNodes)
+	}
+}
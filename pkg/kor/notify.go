@@ -0,0 +1,232 @@
+package kor
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+// notifyNamesPerKind caps the names listed for a kind in a namespace, the
+// full report is in the --output or the uploaded file.
+const notifyNamesPerKind = 10
+
+const (
+	// Discord rejects embeds with more fields or longer field values
+	discordMaxFields     = 25
+	discordMaxFieldValue = 1024
+	discordColor         = 0xf2c744
+)
+
+// notificationSummary is the report model shared by the chat notifications:
+// the findings of a run counted per namespace and kind.
+type notificationSummary struct {
+	Total      int
+	Namespaces []namespaceSummary
+}
+
+type namespaceSummary struct {
+	// Namespace is empty for cluster-scoped resources
+	Namespace string
+	Kinds     []kindSummary
+}
+
+type kindSummary struct {
+	Kind  string
+	Count int
+	// Names holds at most notifyNamesPerKind sorted names
+	Names []string
+}
+
+func summarizeFindings(findings []unusedResource) notificationSummary {
+	byNamespace := make(map[string]map[string][]string)
+	for _, finding := range findings {
+		if byNamespace[finding.Namespace] == nil {
+			byNamespace[finding.Namespace] = make(map[string][]string)
+		}
+		byNamespace[finding.Namespace][finding.Kind] = append(byNamespace[finding.Namespace][finding.Kind], finding.Name)
+	}
+
+	summary := notificationSummary{Total: len(findings)}
+	for namespace, kinds := range byNamespace {
+		ns := namespaceSummary{Namespace: namespace}
+		for kind, names := range kinds {
+			sort.Strings(names)
+			count := len(names)
+			if len(names) > notifyNamesPerKind {
+				names = names[:notifyNamesPerKind]
+			}
+			ns.Kinds = append(ns.Kinds, kindSummary{Kind: kind, Count: count, Names: names})
+		}
+		sort.Slice(ns.Kinds, func(i, j int) bool { return ns.Kinds[i].Kind < ns.Kinds[j].Kind })
+		summary.Namespaces = append(summary.Namespaces, ns)
+	}
+	sort.Slice(summary.Namespaces, func(i, j int) bool {
+		return summary.Namespaces[i].Namespace < summary.Namespaces[j].Namespace
+	})
+	return summary
+}
+
+func (s notificationSummary) title() string {
+	if s.Total == 0 {
+		return "kor found no unused resources"
+	}
+	return fmt.Sprintf("kor found %d unused resources in %d namespaces", s.Total, len(s.Namespaces))
+}
+
+func (n namespaceSummary) heading(quote func(string) string) string {
+	if n.Namespace == "" {
+		return "Cluster-scoped"
+	}
+	return "Namespace " + quote(n.Namespace)
+}
+
+func (k kindSummary) line(quote func(string) string) string {
+	names := make([]string, len(k.Names))
+	for i, name := range k.Names {
+		names[i] = quote(name)
+	}
+	line := fmt.Sprintf("%s (%d): %s", k.Kind, k.Count, strings.Join(names, ", "))
+	if k.Count > len(k.Names) {
+		line += fmt.Sprintf(" and %d more", k.Count-len(k.Names))
+	}
+	return line
+}
+
+func codeQuote(s string) string {
+	return "`" + s + "`"
+}
+
+func plainQuote(s string) string {
+	return s
+}
+
+// Notify posts a summary of the resources reported during this run to the
+// Slack, Microsoft Teams and Discord webhooks of opts. Slack uploads the
+// report as well when a channel and token are given, the generic webhook
+// receives every resource with its details and emails attach them.
+func Notify(opts common.Opts, report string) error {
+	summary := summarizeFindings(reportedResources)
+	var errs []error
+	if opts.WebhookURL != "" || opts.Channel != "" {
+		message := utils.SlackMessage{Summary: slackSummary(summary)}
+		if err := utils.SendToSlack(message, opts, report); err != nil {
+			errs = append(errs, fmt.Errorf("failed to send message to slack: %w", err))
+		}
+	}
+	if opts.TeamsWebhookURL != "" {
+		if err := utils.PostJSON(nil, opts.TeamsWebhookURL, teamsMessage(summary), nil); err != nil {
+			errs = append(errs, fmt.Errorf("failed to send message to teams: %w", err))
+		}
+	}
+	if opts.DiscordWebhookURL != "" {
+		if err := utils.PostJSON(nil, opts.DiscordWebhookURL, discordMessage(summary), nil); err != nil {
+			errs = append(errs, fmt.Errorf("failed to send message to discord: %w", err))
+		}
+	}
+	if len(opts.EmailTo) > 0 {
+		if err := sendEmailReport(opts, summary); err != nil {
+			errs = append(errs, fmt.Errorf("failed to send email: %w", err))
+		}
+	}
+	if opts.ResultWebhookURL != "" {
+		if err := postResults(opts); err != nil {
+			errs = append(errs, fmt.Errorf("failed to send results to webhook: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// resultDocument is the report posted to --webhook-url.
+type resultDocument struct {
+	GeneratedAt time.Time `json:"generatedAt"`
+	Total       int       `json:"total"`
+	// Resources holds the unused resources by namespace and kind, an empty
+	// namespace holds the cluster-scoped ones
+	Resources map[string]map[string][]ResourceInfo `json:"resources"`
+}
+
+func postResults(opts common.Opts) error {
+	headers, err := utils.ParseHeaders(opts.ResultWebhookHeaders)
+	if err != nil {
+		return err
+	}
+	document := resultDocument{GeneratedAt: time.Now().UTC(), Total: len(reportedResources), Resources: reportedInfo}
+	return utils.PostJSON(nil, opts.ResultWebhookURL, document, headers)
+}
+
+// slackSummary renders the summary in Slack markup.
+func slackSummary(summary notificationSummary) string {
+	var sb strings.Builder
+	sb.WriteString("*" + summary.title() + "*")
+	for _, ns := range summary.Namespaces {
+		sb.WriteString("\n\n*" + ns.heading(codeQuote) + "*")
+		for _, kind := range ns.Kinds {
+			sb.WriteString("\n• " + kind.line(codeQuote))
+		}
+	}
+	return sb.String()
+}
+
+// teamsMessage renders the summary as an Adaptive Card, accepted by Teams
+// incoming webhooks and Workflows webhooks alike.
+func teamsMessage(summary notificationSummary) map[string]interface{} {
+	body := []map[string]interface{}{
+		{"type": "TextBlock", "text": summary.title(), "size": "Large", "weight": "Bolder", "wrap": true},
+	}
+	for _, ns := range summary.Namespaces {
+		lines := make([]string, len(ns.Kinds))
+		for i, kind := range ns.Kinds {
+			lines[i] = "- " + kind.line(plainQuote)
+		}
+		body = append(body,
+			map[string]interface{}{"type": "TextBlock", "text": ns.heading(plainQuote), "weight": "Bolder", "separator": true, "wrap": true},
+			map[string]interface{}{"type": "TextBlock", "text": strings.Join(lines, "\n"), "wrap": true},
+		)
+	}
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]interface{}{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body":    body,
+			},
+		}},
+	}
+}
+
+// discordMessage renders the summary as an embed with a field per namespace.
+func discordMessage(summary notificationSummary) map[string]interface{} {
+	var fields []map[string]interface{}
+	for i, ns := range summary.Namespaces {
+		if i == discordMaxFields-1 && len(summary.Namespaces) > discordMaxFields {
+			fields = append(fields, map[string]interface{}{
+				"name":  "…",
+				"value": fmt.Sprintf("and %d more namespaces", len(summary.Namespaces)-i),
+			})
+			break
+		}
+		lines := make([]string, len(ns.Kinds))
+		for j, kind := range ns.Kinds {
+			lines[j] = kind.line(codeQuote)
+		}
+		value := strings.Join(lines, "\n")
+		if len(value) > discordMaxFieldValue {
+			value = value[:strings.LastIndex(value[:discordMaxFieldValue-1], "\n")+1] + "…"
+		}
+		fields = append(fields, map[string]interface{}{"name": ns.heading(plainQuote), "value": value})
+	}
+
+	embed := map[string]interface{}{"title": summary.title(), "color": discordColor}
+	if len(fields) > 0 {
+		embed["fields"] = fields
+	}
+	return map[string]interface{}{"embeds": []map[string]interface{}{embed}}
+}
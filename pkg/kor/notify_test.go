@@ -0,0 +1,188 @@
+package kor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yonahd/kor/pkg/common"
+)
+
+func notifyTestFindings() []unusedResource {
+	findings := []unusedResource{
+		{Namespace: "default", Kind: "Secret", Name: "token"},
+		{Namespace: "default", Kind: "ConfigMap", Name: "b"},
+		{Namespace: "default", Kind: "ConfigMap", Name: "a"},
+		{Namespace: "", Kind: "Pv", Name: "pv-1"},
+	}
+	for i := 0; i < notifyNamesPerKind+2; i++ {
+		findings = append(findings, unusedResource{Namespace: "apps", Kind: "Service", Name: fmt.Sprintf("svc-%02d", i)})
+	}
+	return findings
+}
+
+func TestSummarizeFindings(t *testing.T) {
+	summary := summarizeFindings(notifyTestFindings())
+	if summary.Total != 16 || len(summary.Namespaces) != 3 {
+		t.Fatalf("Expected 16 findings in 3 namespaces, got %+v", summary)
+	}
+	if got := []string{summary.Namespaces[0].Namespace, summary.Namespaces[1].Namespace, summary.Namespaces[2].Namespace}; strings.Join(got, ",") != ",apps,default" {
+		t.Errorf("Expected sorted namespaces, got %v", got)
+	}
+	services := summary.Namespaces[1].Kinds[0]
+	if services.Count != 12 || len(services.Names) != notifyNamesPerKind {
+		t.Errorf("Expected 12 services with %d names, got %+v", notifyNamesPerKind, services)
+	}
+	defaults := summary.Namespaces[2].Kinds
+	if defaults[0].Kind != "ConfigMap" || strings.Join(defaults[0].Names, ",") != "a,b" {
+		t.Errorf("Expected sorted kinds and names, got %+v", defaults)
+	}
+}
+
+func TestSlackSummary(t *testing.T) {
+	got := slackSummary(summarizeFindings(notifyTestFindings()))
+	for _, want := range []string{
+		"*kor found 16 unused resources in 3 namespaces*\n",
+		"\n\n*Cluster-scoped*\n• Pv (1): `pv-1`",
+		"\n\n*Namespace `default`*\n• ConfigMap (2): `a`, `b`\n• Secret (1): `token`",
+		"• Service (12): `svc-00`",
+		"`svc-09` and 2 more",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected summary to contain %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "svc-10") {
+		t.Errorf("Expected names past %d to be left out, got:\n%s", notifyNamesPerKind, got)
+	}
+}
+
+func TestSlackSummaryEmpty(t *testing.T) {
+	if got := slackSummary(summarizeFindings(nil)); got != "*kor found no unused resources*" {
+		t.Errorf("Unexpected summary %q", got)
+	}
+}
+
+func TestTeamsMessage(t *testing.T) {
+	payload, err := json.Marshal(teamsMessage(summarizeFindings(notifyTestFindings())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(payload)
+	for _, want := range []string{
+		`"contentType":"application/vnd.microsoft.card.adaptive"`,
+		`"text":"kor found 16 unused resources in 3 namespaces"`,
+		`"text":"Namespace default"`,
+		`"text":"- ConfigMap (2): a, b\n- Secret (1): token"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected card to contain %s, got %s", want, got)
+		}
+	}
+}
+
+func TestDiscordMessage(t *testing.T) {
+	var findings []unusedResource
+	for i := 0; i < discordMaxFields+5; i++ {
+		findings = append(findings, unusedResource{Namespace: fmt.Sprintf("ns-%02d", i), Kind: "ConfigMap", Name: "cm"})
+	}
+	for i := 0; i < 100; i++ {
+		findings = append(findings, unusedResource{Namespace: "ns-00", Kind: fmt.Sprintf("Kind%02d", i), Name: strings.Repeat("x", 20)})
+	}
+
+	embed := discordMessage(summarizeFindings(findings))["embeds"].([]map[string]interface{})[0]
+	fields := embed["fields"].([]map[string]interface{})
+	if len(fields) != discordMaxFields {
+		t.Fatalf("Expected %d fields, got %d", discordMaxFields, len(fields))
+	}
+	if got := fields[len(fields)-1]["value"]; got != "and 6 more namespaces" {
+		t.Errorf("Expected the remaining namespaces to be counted, got %v", got)
+	}
+	if value := fields[0]["value"].(string); len(value) > discordMaxFieldValue || !strings.HasSuffix(value, "\n…") {
+		t.Errorf("Expected the field value to be cut at a line under %d bytes, got %d bytes", discordMaxFieldValue, len(value))
+	}
+	if _, ok := discordMessage(summarizeFindings(nil))["embeds"].([]map[string]interface{})[0]["fields"]; ok {
+		t.Error("Expected no fields without findings")
+	}
+}
+
+func TestNotify(t *testing.T) {
+	reportedResources = []unusedResource{{Namespace: "default", Kind: "ConfigMap", Name: "cm"}}
+	defer func() { reportedResources = nil }()
+
+	received := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Expected a JSON payload, got %v", err)
+		}
+		encoded, _ := json.Marshal(payload)
+		received[r.URL.Path] = string(encoded)
+		if r.URL.Path == "/failing" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	opts := common.Opts{WebhookURL: server.URL + "/slack", TeamsWebhookURL: server.URL + "/teams", DiscordWebhookURL: server.URL + "/discord"}
+	if err := Notify(opts, "report"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for path, want := range map[string]string{
+		"/slack":   "Namespace `default`",
+		"/teams":   "AdaptiveCard",
+		"/discord": "embeds",
+	} {
+		if !strings.Contains(received[path], want) {
+			t.Errorf("Expected %s to receive %q, got %s", path, want, received[path])
+		}
+	}
+
+	err := Notify(common.Opts{DiscordWebhookURL: server.URL + "/failing"}, "report")
+	if err == nil || !strings.Contains(err.Error(), "discord") {
+		t.Errorf("Expected the discord error, got %v", err)
+	}
+	if err := Notify(common.Opts{}, "report"); err != nil {
+		t.Errorf("Expected nothing to be sent without webhooks, got %v", err)
+	}
+}
+
+func TestNotifyResultWebhook(t *testing.T) {
+	resetReportedResources()
+	defer resetReportedResources()
+	recordUnusedResources(map[string]map[string][]ResourceInfo{
+		"ConfigMap": {"default": {{Name: "cm", Reason: "ConfigMap is not used in any pod or container"}}},
+		"Pv":        {"": {{Name: "pv-1"}}},
+	}, "resource")
+
+	t.Setenv("KOR_TEST_TOKEN", "secret")
+	var document resultDocument
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&document); err != nil {
+			t.Errorf("Expected a JSON document, got %v", err)
+		}
+	}))
+	defer server.Close()
+
+	opts := common.Opts{ResultWebhookURL: server.URL, ResultWebhookHeaders: []string{"Authorization: Bearer $KOR_TEST_TOKEN"}}
+	if err := Notify(opts, "report"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if authorization != "Bearer secret" {
+		t.Errorf("Expected the expanded header, got %q", authorization)
+	}
+	if document.Total != 2 || document.GeneratedAt.IsZero() {
+		t.Errorf("Expected 2 resources and a timestamp, got %+v", document)
+	}
+	if got := document.Resources["default"]["ConfigMap"]; len(got) != 1 || got[0].Reason == "" {
+		t.Errorf("Expected the ConfigMap with its reason by namespace, got %v", document.Resources)
+	}
+	if got := document.Resources[""]["Pv"]; len(got) != 1 || got[0].Name != "pv-1" {
+		t.Errorf("Expected the cluster-scoped PV under the empty namespace, got %v", document.Resources)
+	}
+}
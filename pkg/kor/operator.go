@@ -0,0 +1,419 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	yamlutil "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// operatorCRDs defines the ScanPolicy and ScanReport resources
+//
+//go:embed manifests/crds.yaml
+var operatorCRDs []byte
+
+const (
+	// ScanActionNotify sends the notifications configured on the operator
+	ScanActionNotify = "notify"
+	// ScanActionQuarantine quarantines the unused resources
+	ScanActionQuarantine = "quarantine"
+	// ScanActionDelete deletes the unused resources, protected resources excepted
+	ScanActionDelete = "delete"
+
+	// scanReportMaxResources keeps ScanReports well under the size limit of etcd
+	scanReportMaxResources = 1000
+	// operatorMaxWait is the longest the operator sleeps between two looks at the policies
+	operatorMaxWait = time.Hour
+)
+
+var (
+	scanPolicyGVR = schema.GroupVersionResource{Group: "kor.yonahd.io", Version: "v1alpha1", Resource: "scanpolicies"}
+	scanReportGVR = schema.GroupVersionResource{Group: "kor.yonahd.io", Version: "v1alpha1", Resource: "scanreports"}
+)
+
+// ScanPolicySpec is what a ScanPolicy scans, when and what happens to the
+// unused resources besides reporting them.
+type ScanPolicySpec struct {
+	Schedule          string   `json:"schedule"`
+	Suspend           bool     `json:"suspend,omitempty"`
+	Resources         []string `json:"resources,omitempty"`
+	IncludeNamespaces []string `json:"includeNamespaces,omitempty"`
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
+	IncludeLabels     string   `json:"includeLabels,omitempty"`
+	ExcludeLabels     []string `json:"excludeLabels,omitempty"`
+	OlderThan         string   `json:"olderThan,omitempty"`
+	NewerThan         string   `json:"newerThan,omitempty"`
+	Actions           []string `json:"actions,omitempty"`
+}
+
+// ScanPolicyStatus is the outcome of the last scan of a ScanPolicy.
+type ScanPolicyStatus struct {
+	LastScanTime    *metav1.Time `json:"lastScanTime,omitempty"`
+	NextScanTime    *metav1.Time `json:"nextScanTime,omitempty"`
+	UnusedResources int          `json:"unusedResources"`
+	Report          string       `json:"report,omitempty"`
+	Error           string       `json:"error,omitempty"`
+}
+
+// ScanReportStatus holds the unused resources found by the last scan of a policy.
+type ScanReportStatus struct {
+	Policy   string      `json:"policy"`
+	ScanTime metav1.Time `json:"scanTime"`
+	Total    int         `json:"total"`
+	// Truncated is set when Resources only lists the first scanReportMaxResources
+	Truncated bool                 `json:"truncated,omitempty"`
+	Actions   []string             `json:"actions,omitempty"`
+	Kinds     []ScanReportKind     `json:"kinds,omitempty"`
+	Resources []ScanReportResource `json:"resources,omitempty"`
+}
+
+// ScanReportKind counts the unused resources of a kind.
+type ScanReportKind struct {
+	Kind  string `json:"kind"`
+	Count int    `json:"count"`
+}
+
+// ScanReportResource is an unused resource.
+type ScanReportResource struct {
+	Namespace string `json:"namespace,omitempty"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+type operator struct {
+	clientset     kubernetes.Interface
+	apiExtClient  apiextensionsclientset.Interface
+	dynamicClient dynamic.Interface
+	opts          common.Opts
+	// lastScans holds when each policy was scanned, by UID, in case the watch lags behind the status updates
+	lastScans map[types.UID]time.Time
+}
+
+// Operator watches the ScanPolicy resources and scans the cluster on their
+// schedule, one policy at a time. The unused resources found are written to
+// the status of a ScanReport named after the policy. With installCRDs, the
+// ScanPolicy and ScanReport definitions are created first when missing.
+func Operator(ctx context.Context, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, opts common.Opts, installCRDs bool) error {
+	clientset = cachedClientset(ctx, clientset)
+	if installCRDs {
+		if err := installOperatorCRDs(ctx, apiExtClient); err != nil {
+			return err
+		}
+	}
+	o := &operator{clientset: clientset, apiExtClient: apiExtClient, dynamicClient: dynamicClient, opts: opts, lastScans: make(map[types.UID]time.Time)}
+
+	// Changes to a policy wake the operator up, so a new schedule applies right away
+	wake := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, 0)
+	informer := factory.ForResource(scanPolicyGVR).Informer()
+	if _, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(interface{}) { notify() },
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			// Status updates written by the operator don't change the generation
+			if oldObj.(*unstructured.Unstructured).GetGeneration() != newObj.(*unstructured.Unstructured).GetGeneration() {
+				notify()
+			}
+		},
+	}); err != nil {
+		return err
+	}
+	factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return fmt.Errorf("failed to watch ScanPolicies: %w", context.Cause(ctx))
+	}
+	slog.Info("Operator watching ScanPolicies")
+
+	for {
+		var policies []*unstructured.Unstructured
+		for _, obj := range informer.GetStore().List() {
+			policies = append(policies, obj.(*unstructured.Unstructured))
+		}
+		timer := time.NewTimer(o.reconcile(ctx, policies, time.Now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+func installOperatorCRDs(ctx context.Context, apiExtClient apiextensionsclientset.Interface) error {
+	decoder := yamlutil.NewYAMLOrJSONDecoder(bytes.NewReader(operatorCRDs), 4096)
+	for {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := decoder.Decode(crd); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		_, err := apiExtClient.ApiextensionsV1().CustomResourceDefinitions().Create(ctx, crd, metav1.CreateOptions{})
+		switch {
+		case err == nil:
+			slog.Info("Installed CustomResourceDefinition", "name", crd.Name)
+		case !apierrors.IsAlreadyExists(err):
+			return fmt.Errorf("failed to install CustomResourceDefinition %s: %w", crd.Name, err)
+		}
+	}
+}
+
+// reconcile scans the policies that are due and returns how long to wait
+// until the next one is.
+func (o *operator) reconcile(ctx context.Context, policies []*unstructured.Unstructured, now time.Time) time.Duration {
+	sort.Slice(policies, func(i, j int) bool { return policies[i].GetName() < policies[j].GetName() })
+	wait := operatorMaxWait
+	for _, policy := range policies {
+		next, err := o.reconcilePolicy(ctx, policy, now)
+		if err != nil {
+			slog.Error("Failed to reconcile ScanPolicy", "name", policy.GetName(), "error", err)
+			continue
+		}
+		if !next.IsZero() && next.Sub(now) < wait {
+			wait = next.Sub(now)
+		}
+	}
+	return max(wait, time.Second)
+}
+
+// reconcilePolicy scans the policy when it is due, and returns when it is due next.
+func (o *operator) reconcilePolicy(ctx context.Context, policy *unstructured.Unstructured, now time.Time) (time.Time, error) {
+	var spec ScanPolicySpec
+	var status ScanPolicyStatus
+	if err := fromUnstructuredField(policy, "status", &status); err != nil {
+		return time.Time{}, err
+	}
+	var schedule scanSchedule
+	err := fromUnstructuredField(policy, "spec", &spec)
+	if err == nil {
+		schedule, err = parseSchedule(spec.Schedule)
+	}
+	if err == nil {
+		err = validateScanActions(spec.Actions)
+	}
+	if err != nil {
+		if status.Error == err.Error() {
+			return time.Time{}, nil
+		}
+		return time.Time{}, o.updatePolicyStatus(ctx, policy, status, err)
+	}
+	if spec.Suspend {
+		return time.Time{}, nil
+	}
+
+	last := policy.GetCreationTimestamp().Time
+	if status.LastScanTime != nil {
+		last = status.LastScanTime.Time
+	}
+	if scanned := o.lastScans[policy.GetUID()]; scanned.After(last) {
+		last = scanned
+	}
+	// Missed scans, e.g. while the operator was down, are caught up with a single one
+	if next := schedule.next(last); next.After(now) {
+		return next, nil
+	}
+
+	slog.Info("Scanning ScanPolicy", "name", policy.GetName())
+	o.lastScans[policy.GetUID()] = now
+	report, err := o.scan(ctx, policy, spec, now)
+	status.LastScanTime = &metav1.Time{Time: now}
+	status.NextScanTime = &metav1.Time{Time: schedule.next(now)}
+	if report != nil {
+		status.UnusedResources = report.Total
+		status.Report = policy.GetName()
+		if writeErr := o.writeReport(ctx, policy, *report); writeErr != nil {
+			err = errors.Join(err, writeErr)
+		}
+	}
+	if err != nil {
+		slog.Error("ScanPolicy scan failed", "name", policy.GetName(), "error", err)
+	}
+	return status.NextScanTime.Time, o.updatePolicyStatus(ctx, policy, status, err)
+}
+
+func validateScanActions(actions []string) error {
+	for _, action := range actions {
+		if action != ScanActionNotify && action != ScanActionQuarantine && action != ScanActionDelete {
+			return fmt.Errorf("invalid action %q, must be one of %v", action, []string{ScanActionNotify, ScanActionQuarantine, ScanActionDelete})
+		}
+	}
+	return nil
+}
+
+// scan runs the scan of the policy with its actions. The report is nil when
+// the scan failed, and holds the unused resources found when an action failed.
+func (o *operator) scan(ctx context.Context, policy *unstructured.Unstructured, spec ScanPolicySpec, now time.Time) (*ScanReportStatus, error) {
+	filterOpts := &filters.Options{
+		IncludeNamespaces: spec.IncludeNamespaces,
+		ExcludeNamespaces: spec.ExcludeNamespaces,
+		IncludeLabels:     spec.IncludeLabels,
+		ExcludeLabels:     spec.ExcludeLabels,
+		OlderThan:         spec.OlderThan,
+		NewerThan:         spec.NewerThan,
+	}
+	if err := filterOpts.Validate(); err != nil {
+		return nil, err
+	}
+	filterOpts.Modify()
+
+	opts := o.opts
+	opts.GroupBy = "namespace"
+	opts.DeleteFlag, opts.NoInteractive = false, true
+	var quarantine, notify bool
+	for _, action := range spec.Actions {
+		switch action {
+		case ScanActionDelete:
+			opts.DeleteFlag = true
+		case ScanActionQuarantine:
+			quarantine = true
+		case ScanActionNotify:
+			notify = true
+		}
+	}
+
+	// Only this scan counts, the findings of the previous policy are dropped
+	resetReportedResources()
+	output, err := getUnusedResources(ctx, filterOpts, o.clientset, o.apiExtClient, o.dynamicClient, "json", opts, spec.Resources)
+	if err != nil {
+		return nil, err
+	}
+	report := &ScanReportStatus{Policy: policy.GetName(), ScanTime: metav1.Time{Time: now}, Actions: spec.Actions}
+	fillScanReport(report, reportedResources)
+	if quarantine {
+		QuarantineResources(ctx, o.clientset)
+	}
+	if notify {
+		err = Notify(opts, output)
+	}
+	return report, err
+}
+
+func fillScanReport(report *ScanReportStatus, findings []unusedResource) {
+	findings = append([]unusedResource(nil), findings...)
+	sort.Slice(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+
+	report.Total = len(findings)
+	counts := make(map[string]int)
+	for i, finding := range findings {
+		counts[finding.Kind]++
+		if i == scanReportMaxResources {
+			report.Truncated = true
+		}
+		if !report.Truncated {
+			report.Resources = append(report.Resources, ScanReportResource{Namespace: finding.Namespace, Kind: finding.Kind, Name: finding.Name, Reason: finding.Reason})
+		}
+	}
+	for kind, count := range counts {
+		report.Kinds = append(report.Kinds, ScanReportKind{Kind: kind, Count: count})
+	}
+	sort.Slice(report.Kinds, func(i, j int) bool { return report.Kinds[i].Kind < report.Kinds[j].Kind })
+}
+
+// writeReport creates or replaces the status of the ScanReport of the policy,
+// which is garbage collected with the policy.
+func (o *operator) writeReport(ctx context.Context, policy *unstructured.Unstructured, report ScanReportStatus) error {
+	status, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&report)
+	if err != nil {
+		return err
+	}
+	client := o.dynamicClient.Resource(scanReportGVR)
+	obj, err := client.Get(ctx, policy.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		obj = &unstructured.Unstructured{}
+		obj.SetAPIVersion(scanReportGVR.GroupVersion().String())
+		obj.SetKind("ScanReport")
+		obj.SetName(policy.GetName())
+		obj.SetOwnerReferences([]metav1.OwnerReference{{
+			APIVersion: policy.GetAPIVersion(),
+			Kind:       policy.GetKind(),
+			Name:       policy.GetName(),
+			UID:        policy.GetUID(),
+		}})
+		obj, err = client.Create(ctx, obj, metav1.CreateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write ScanReport %s: %w", policy.GetName(), err)
+	}
+	obj.Object["status"] = status
+	if _, err := client.UpdateStatus(ctx, obj, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to write ScanReport %s: %w", policy.GetName(), err)
+	}
+	return nil
+}
+
+func (o *operator) updatePolicyStatus(ctx context.Context, policy *unstructured.Unstructured, status ScanPolicyStatus, scanErr error) error {
+	status.Error = ""
+	if scanErr != nil {
+		status.Error = scanErr.Error()
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&status)
+	if err != nil {
+		return err
+	}
+	client := o.dynamicClient.Resource(scanPolicyGVR)
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := client.Get(ctx, policy.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		latest.Object["status"] = obj
+		_, err = client.UpdateStatus(ctx, latest, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update the status: %w", err)
+	}
+	return nil
+}
+
+func fromUnstructuredField(obj *unstructured.Unstructured, field string, into interface{}) error {
+	value, ok := obj.Object[field].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(value, into); err != nil {
+		return fmt.Errorf("invalid %s: %w", field, err)
+	}
+	return nil
+}
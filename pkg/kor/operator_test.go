@@ -0,0 +1,103 @@
+package kor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+)
+
+func testScanPolicy(name string, spec map[string]interface{}, created time.Time) *unstructured.Unstructured {
+	policy := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	policy.SetAPIVersion("kor.yonahd.io/v1alpha1")
+	policy.SetKind("ScanPolicy")
+	policy.SetName(name)
+	policy.SetUID(types.UID("uid-" + name))
+	policy.SetCreationTimestamp(metav1.NewTime(created))
+	return policy
+}
+
+func TestInstallOperatorCRDs(t *testing.T) {
+	apiExtClient := apiextensionsfake.NewSimpleClientset()
+	for i := 0; i < 2; i++ {
+		if err := installOperatorCRDs(context.Background(), apiExtClient); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	crds, _ := apiExtClient.ApiextensionsV1().CustomResourceDefinitions().List(context.TODO(), metav1.ListOptions{})
+	if len(crds.Items) != 2 || crds.Items[0].Name != "scanpolicies.kor.yonahd.io" || crds.Items[1].Name != "scanreports.kor.yonahd.io" {
+		t.Errorf("Expected the ScanPolicy and ScanReport definitions, got %v", crds.Items)
+	}
+}
+
+func TestOperatorReconcile(t *testing.T) {
+	defer resetReportedResources()
+	now := time.Date(2026, 10, 14, 10, 30, 0, 0, time.UTC)
+	policy := testScanPolicy("configmaps", map[string]interface{}{
+		"schedule":          "@hourly",
+		"resources":         []interface{}{"cm"},
+		"includeNamespaces": []interface{}{testNamespace},
+	}, now.Add(-2*time.Hour))
+	invalid := testScanPolicy("invalid", map[string]interface{}{"schedule": "every day"}, now.Add(-2*time.Hour))
+	later := testScanPolicy("later", map[string]interface{}{"schedule": "0 12 * * *"}, now.Add(-time.Hour))
+
+	dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{scanPolicyGVR: "ScanPolicyList", scanReportGVR: "ScanReportList"},
+		policy, invalid, later,
+	)
+	o := &operator{clientset: createTestMultiResources(t), dynamicClient: dynamicClient, opts: common.Opts{}, lastScans: map[types.UID]time.Time{}}
+
+	// The next scan is the one of the configmaps policy, at 11:00
+	if wait := o.reconcile(context.Background(), []*unstructured.Unstructured{policy, invalid, later}, now); wait != 30*time.Minute {
+		t.Errorf("Expected to wait 30m for the next scan, got %s", wait)
+	}
+
+	report, err := dynamicClient.Resource(scanReportGVR).Get(context.TODO(), "configmaps", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected a ScanReport, got %v", err)
+	}
+	var status ScanReportStatus
+	if err := fromUnstructuredField(report, "status", &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Policy != "configmaps" || status.Total != 1 || len(status.Resources) != 1 || status.Resources[0].Name != "configmap-1" || status.Kinds[0] != (ScanReportKind{Kind: "ConfigMap", Count: 1}) {
+		t.Errorf("Unexpected report %+v", status)
+	}
+	if owners := report.GetOwnerReferences(); len(owners) != 1 || owners[0].UID != "uid-configmaps" {
+		t.Errorf("Expected the report to be owned by the policy, got %v", owners)
+	}
+
+	scanned, _ := dynamicClient.Resource(scanPolicyGVR).Get(context.TODO(), "configmaps", metav1.GetOptions{})
+	var policyStatus ScanPolicyStatus
+	if err := fromUnstructuredField(scanned, "status", &policyStatus); err != nil {
+		t.Fatal(err)
+	}
+	if policyStatus.UnusedResources != 1 || policyStatus.Report != "configmaps" || policyStatus.Error != "" || !policyStatus.NextScanTime.Equal(&metav1.Time{Time: now.Add(30 * time.Minute)}) {
+		t.Errorf("Unexpected policy status %+v", policyStatus)
+	}
+
+	rejected, _ := dynamicClient.Resource(scanPolicyGVR).Get(context.TODO(), "invalid", metav1.GetOptions{})
+	if message, _, _ := unstructured.NestedString(rejected.Object, "status", "error"); !strings.Contains(message, "invalid schedule") {
+		t.Errorf("Expected the invalid schedule in the status, got %q", message)
+	}
+	if _, err := dynamicClient.Resource(scanReportGVR).Get(context.TODO(), "later", metav1.GetOptions{}); err == nil {
+		t.Error("Expected the later policy not to be scanned yet")
+	}
+
+	// The watch may not have caught up with the status, the policy is not scanned twice
+	resetReportedResources()
+	o.reconcile(context.Background(), []*unstructured.Unstructured{policy}, now.Add(time.Minute))
+	if len(reportedResources) != 0 {
+		t.Error("Expected no scan before the next schedule")
+	}
+}
@@ -0,0 +1,241 @@
+package kor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/yonahd/kor/pkg/common"
+)
+
+// Formatter renders the unused resources of a scan in an output format.
+// opts.GroupBy, opts.ShowReason and the other output options apply.
+type Formatter interface {
+	Format(w io.Writer, resources []Resource, opts common.Opts) error
+}
+
+// FormatterFunc adapts a function to a Formatter.
+type FormatterFunc func(w io.Writer, resources []Resource, opts common.Opts) error
+
+// Format calls f.
+func (f FormatterFunc) Format(w io.Writer, resources []Resource, opts common.Opts) error {
+	return f(w, resources, opts)
+}
+
+var formatterRegistry = struct {
+	sync.RWMutex
+	formatters map[string]Formatter
+}{formatters: map[string]Formatter{
+	"table": FormatterFunc(formatTable),
+	"json":  FormatterFunc(formatJSON),
+	"yaml":  FormatterFunc(formatYAML),
+	"csv":   FormatterFunc(formatCSV),
+	"junit": FormatterFunc(func(w io.Writer, resources []Resource, opts common.Opts) error {
+		return writeFormatted(w, func() (string, error) {
+			return formatJUnit(flattenResources(groupResources(resources, opts.GroupBy), opts.GroupBy))
+		})
+	}),
+	"sarif": FormatterFunc(func(w io.Writer, resources []Resource, opts common.Opts) error {
+		return writeFormatted(w, func() (string, error) {
+			return formatSARIF(flattenResources(groupResources(resources, opts.GroupBy), opts.GroupBy))
+		})
+	}),
+}}
+
+// RegisterFormatter makes formatter available as the output format name,
+// e.g. kor all -o name. It fails when the name is taken.
+func RegisterFormatter(name string, formatter Formatter) error {
+	if name == "" || name == "wide" {
+		return fmt.Errorf("invalid output format %q", name)
+	}
+	if _, _, ok := parseCustomOutput(name); ok {
+		return fmt.Errorf("invalid output format %q", name)
+	}
+	formatterRegistry.Lock()
+	defer formatterRegistry.Unlock()
+	if _, ok := formatterRegistry.formatters[name]; ok {
+		return fmt.Errorf("output format %q is already registered", name)
+	}
+	formatterRegistry.formatters[name] = formatter
+	return nil
+}
+
+func lookupFormatter(name string) Formatter {
+	formatterRegistry.RLock()
+	defer formatterRegistry.RUnlock()
+	return formatterRegistry.formatters[name]
+}
+
+// FormatResources writes resources to w in outputFormat, a registered output
+// format, go-template=... or jsonpath=....
+func FormatResources(w io.Writer, outputFormat string, resources []Resource, opts common.Opts) error {
+	if kind, expression, ok := parseCustomOutput(outputFormat); ok {
+		var report interface{} = groupResources(resources, opts.GroupBy)
+		if clusters := resourcesByCluster(resources); clusters != nil {
+			grouped := make(map[string]map[string]map[string][]ResourceInfo, len(clusters))
+			for cluster, clusterResources := range clusters {
+				grouped[cluster] = groupResources(clusterResources, opts.GroupBy)
+			}
+			report = grouped
+		}
+		data, err := json.Marshal(report)
+		if err != nil {
+			return err
+		}
+		return writeFormatted(w, func() (string, error) { return formatCustomOutput(kind, expression, data) })
+	}
+	formatter := lookupFormatter(outputFormat)
+	if formatter == nil {
+		return fmt.Errorf("unsupported output format: %s", outputFormat)
+	}
+	return formatter.Format(w, resources, opts)
+}
+
+// writeFormatted writes the output of format to w.
+func writeFormatted(w io.Writer, format func() (string, error)) error {
+	output, err := format()
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, output)
+	return err
+}
+
+// groupResources groups resources by namespace and kind, or by kind and
+// namespace for opts.GroupBy resource, as the reports of kor.
+func groupResources(resources []Resource, groupBy string) map[string]map[string][]ResourceInfo {
+	grouped := make(map[string]map[string][]ResourceInfo)
+	for _, resource := range resources {
+		outer, inner := resource.Namespace, resource.Kind
+		if groupBy == "resource" {
+			outer, inner = resource.Kind, resource.Namespace
+		}
+		if grouped[outer] == nil {
+			grouped[outer] = make(map[string][]ResourceInfo)
+		}
+		grouped[outer][inner] = append(grouped[outer][inner], resource.ResourceInfo)
+	}
+	return grouped
+}
+
+// resourcesByCluster splits resources by cluster, or returns nil when they
+// were not scanned across several kubeconfig contexts.
+func resourcesByCluster(resources []Resource) map[string][]Resource {
+	var clusters map[string][]Resource
+	for _, resource := range resources {
+		if resource.Cluster == "" {
+			continue
+		}
+		if clusters == nil {
+			clusters = make(map[string][]Resource)
+		}
+		clusters[resource.Cluster] = append(clusters[resource.Cluster], resource)
+	}
+	return clusters
+}
+
+// reportResources lists the resources of a report grouped by groupBy, sorted
+// by namespace and kind in the order the detectors reported them.
+func reportResources(report map[string]map[string][]ResourceInfo, groupBy string) []Resource {
+	var resources []Resource
+	for outer, inner := range report {
+		for key, infos := range inner {
+			namespace, kind := outer, key
+			if groupBy == "resource" {
+				namespace, kind = key, outer
+			}
+			for _, info := range infos {
+				resources = append(resources, Resource{Namespace: namespace, Kind: kind, ResourceInfo: info})
+			}
+		}
+	}
+	sort.SliceStable(resources, func(i, j int) bool {
+		if resources[i].Namespace != resources[j].Namespace {
+			return resources[i].Namespace < resources[j].Namespace
+		}
+		return resources[i].Kind < resources[j].Kind
+	})
+	return resources
+}
+
+func formatTable(w io.Writer, resources []Resource, opts common.Opts) error {
+	var output bytes.Buffer
+	if clusters := resourcesByCluster(resources); clusters != nil {
+		output = formatClusterOutput(clusters, opts)
+	} else {
+		output = FormatOutput(groupResources(resources, opts.GroupBy), opts)
+	}
+	_, err := output.WriteTo(w)
+	return err
+}
+
+func formatJSON(w io.Writer, resources []Resource, opts common.Opts) error {
+	data, err := marshalReport(resources, opts)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func formatYAML(w io.Writer, resources []Resource, opts common.Opts) error {
+	data, err := marshalReport(resources, opts)
+	if err != nil {
+		return err
+	}
+	if data, err = yaml.JSONToYAML(data); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// marshalReport renders the JSON report, with the names of the resources only
+// unless opts.ShowReason is set. The reports of the clusters scanned across
+// several kubeconfig contexts are keyed by cluster.
+func marshalReport(resources []Resource, opts common.Opts) ([]byte, error) {
+	if clusters := resourcesByCluster(resources); clusters != nil {
+		reports := make(map[string]interface{}, len(clusters))
+		for cluster, clusterResources := range clusters {
+			reports[cluster] = reportDocument(clusterResources, opts)
+		}
+		return json.MarshalIndent(reports, "", "  ")
+	}
+	return json.MarshalIndent(reportDocument(resources, opts), "", "  ")
+}
+
+// reportDocument groups resources for the JSON report.
+func reportDocument(resources []Resource, opts common.Opts) interface{} {
+	grouped := groupResources(resources, opts.GroupBy)
+	if opts.ShowReason {
+		return grouped
+	}
+	names := make(map[string]map[string][]string)
+	for outer, inner := range grouped {
+		names[outer] = make(map[string][]string)
+		for key, infos := range inner {
+			for _, info := range infos {
+				names[outer][key] = append(names[outer][key], info.Name)
+			}
+		}
+	}
+	return names
+}
+
+func formatCSV(w io.Writer, resources []Resource, _ common.Opts) error {
+	rows := make([]reportRow, len(resources))
+	for i, resource := range resources {
+		rows[i] = reportRow{Cluster: resource.Cluster, Namespace: resource.Namespace, Kind: resource.Kind, ResourceInfo: resource.ResourceInfo}
+	}
+	data, err := renderCSVReport(rows)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,142 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func testOutputResources() []Resource {
+	return []Resource{
+		{Namespace: "default", Kind: "ConfigMap", ResourceInfo: ResourceInfo{Name: "cm-2", Reason: "ConfigMap is not used"}},
+		{Namespace: "default", Kind: "ConfigMap", ResourceInfo: ResourceInfo{Name: "cm-1", Reason: "ConfigMap is not used"}},
+		{Kind: "Pv", ResourceInfo: ResourceInfo{Name: "pv-1", Reason: "PV is not bound"}},
+	}
+}
+
+func TestFormatResources(t *testing.T) {
+	tests := []struct {
+		format   string
+		opts     common.Opts
+		expected string
+	}{
+		{"json", common.Opts{GroupBy: "namespace"}, `{
+  "": {
+    "Pv": [
+      "pv-1"
+    ]
+  },
+  "default": {
+    "ConfigMap": [
+      "cm-2",
+      "cm-1"
+    ]
+  }
+}`},
+		{"yaml", common.Opts{GroupBy: "resource", ShowReason: true}, `ConfigMap:
+  default:
+  - name: cm-2
+    reason: ConfigMap is not used
+  - name: cm-1
+    reason: ConfigMap is not used
+Pv:
+  "":
+  - name: pv-1
+    reason: PV is not bound
+`},
+		{"csv", common.Opts{}, `Namespace,Kind,Name,Reason,Created,Size,Owners,Managed By,Helm Release
+default,ConfigMap,cm-2,ConfigMap is not used,,,,,
+default,ConfigMap,cm-1,ConfigMap is not used,,,,,
+,Pv,pv-1,PV is not bound,,,,,
+`},
+		{"go-template={{range $kind, $names := .default}}{{$kind}}={{len $names}}{{end}}", common.Opts{GroupBy: "namespace"}, "ConfigMap=2"},
+	}
+	for _, test := range tests {
+		var output bytes.Buffer
+		if err := FormatResources(&output, test.format, testOutputResources(), test.opts); err != nil {
+			t.Errorf("%s: unexpected error %v", test.format, err)
+			continue
+		}
+		if output.String() != test.expected {
+			t.Errorf("%s: expected\n%s\ngot\n%s", test.format, test.expected, output.String())
+		}
+	}
+
+	if err := FormatResources(io.Discard, "xml", nil, common.Opts{}); err == nil {
+		t.Error("Expected an unknown output format to fail")
+	}
+}
+
+func TestFormatClusterResources(t *testing.T) {
+	resources := []Resource{
+		{Cluster: "staging", Namespace: "default", Kind: "ConfigMap", ResourceInfo: ResourceInfo{Name: "cm-1"}},
+		{Cluster: "production", Namespace: "default", Kind: "ConfigMap", ResourceInfo: ResourceInfo{Name: "cm-2"}},
+	}
+
+	var table bytes.Buffer
+	if err := FormatResources(&table, "table", resources, common.Opts{GroupBy: "namespace"}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(table.String(), "\n")
+	if len(lines) < 7 || lines[0] != `Unused resources in namespace: "default"` || !strings.Contains(lines[2], "CLUSTER") ||
+		!strings.Contains(lines[4], "production") || !strings.Contains(lines[5], "staging") {
+		t.Errorf("Expected a table of both clusters sorted by cluster, got\n%s", table.String())
+	}
+
+	var quiet bytes.Buffer
+	if err := FormatResources(&quiet, "table", resources, common.Opts{GroupBy: "namespace", Quiet: true}); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "production default/configmap/cm-2\nstaging default/configmap/cm-1\n"; quiet.String() != expected {
+		t.Errorf("Expected the quiet lines prefixed with the cluster, got\n%s", quiet.String())
+	}
+
+	var csv bytes.Buffer
+	if err := FormatResources(&csv, "csv", resources, common.Opts{}); err != nil {
+		t.Fatal(err)
+	}
+	if expected := `Cluster,Namespace,Kind,Name,Reason,Created,Size,Owners,Managed By,Helm Release
+staging,default,ConfigMap,cm-1,,,,,,
+production,default,ConfigMap,cm-2,,,,,,
+`; csv.String() != expected {
+		t.Errorf("Expected a cluster column, got\n%s", csv.String())
+	}
+}
+
+func TestRegisterFormatter(t *testing.T) {
+	defer resetReportedResources()
+	names := FormatterFunc(func(w io.Writer, resources []Resource, _ common.Opts) error {
+		for _, resource := range resources {
+			if _, err := io.WriteString(w, resource.Namespace+"/"+resource.Name+"\n"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err := RegisterFormatter("names", names); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		formatterRegistry.Lock()
+		delete(formatterRegistry.formatters, "names")
+		formatterRegistry.Unlock()
+	}()
+	for _, name := range []string{"names", "json", "wide", "jsonpath={.}"} {
+		if err := RegisterFormatter(name, names); err == nil {
+			t.Errorf("Expected output format %q not to be registered", name)
+		}
+	}
+
+	output, err := GetUnusedConfigmaps(context.Background(), &filters.Options{}, createTestConfigmaps(t), "names", common.Opts{GroupBy: "namespace"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Fields(output); len(lines) != 2 || lines[0] != testNamespace+"/configmap-3" {
+		t.Errorf("Expected the commands to render with the registered formatter, got %q", output)
+	}
+}
@@ -6,7 +6,7 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
-	"os"
+	"log/slog"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
@@ -15,14 +15,15 @@ import (
 
 	"github.com/yonahd/kor/pkg/common"
 	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
 )
 
 //go:embed exceptions/pdbs/pdbs.json
 var pdbsConfig []byte
 
-func processNamespacePdbs(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+func processNamespacePdbs(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
 	var unusedPdbs []ResourceInfo
-	pdbs, err := clientset.PolicyV1().PodDisruptionBudgets(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	pdbs, err := utils.ListAll(ctx, filterOpts.ListOptions(), clientset.PolicyV1().PodDisruptionBudgets(namespace).List)
 	if err != nil {
 		return nil, err
 	}
@@ -57,7 +58,7 @@ func processNamespacePdbs(clientset kubernetes.Interface, namespace string, filt
 
 		// Validate empty selector
 		if selector == nil || len(selector.MatchLabels) == 0 {
-			hasRunningPods, err := validateRunningPods(clientset, namespace)
+			hasRunningPods, err := validateRunningPods(ctx, clientset, namespace)
 			if err != nil {
 				return nil, err
 			}
@@ -69,12 +70,12 @@ func processNamespacePdbs(clientset kubernetes.Interface, namespace string, filt
 
 			continue
 		} else {
-			hasMatchingTemplates, err = validateMatchingTemplates(clientset, namespace, selector)
+			hasMatchingTemplates, err = validateMatchingTemplates(ctx, clientset, namespace, selector)
 			if err != nil {
 				return nil, err
 			}
 
-			hasMatchingWorkloads, err = validateMatchingWorkloads(clientset, namespace, selector)
+			hasMatchingWorkloads, err = validateMatchingWorkloads(ctx, clientset, namespace, selector)
 			if err != nil {
 				return nil, err
 			}
@@ -89,10 +90,10 @@ func processNamespacePdbs(clientset kubernetes.Interface, namespace string, filt
 	return unusedPdbs, nil
 }
 
-func validateRunningPods(clientset kubernetes.Interface, namespace string) (bool, error) {
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
+func validateRunningPods(ctx context.Context, clientset kubernetes.Interface, namespace string) (bool, error) {
+	pods, err := utils.ListAll(ctx, metav1.ListOptions{
 		FieldSelector: "status.phase=Running",
-	})
+	}, clientset.CoreV1().Pods(namespace).List)
 	if err != nil {
 		return false, err
 	}
@@ -108,13 +109,13 @@ func validateRunningPods(clientset kubernetes.Interface, namespace string) (bool
 	return false, nil
 }
 
-func validateMatchingTemplates(clientset kubernetes.Interface, namespace string, selector *metav1.LabelSelector) (bool, error) {
+func validateMatchingTemplates(ctx context.Context, clientset kubernetes.Interface, namespace string, selector *metav1.LabelSelector) (bool, error) {
 	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
 	if err != nil {
 		return false, err
 	}
 
-	deployments, err := clientset.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{})
+	deployments, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.AppsV1().Deployments(namespace).List)
 	if err != nil {
 		return false, err
 	}
@@ -126,7 +127,7 @@ func validateMatchingTemplates(clientset kubernetes.Interface, namespace string,
 		}
 	}
 
-	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(context.TODO(), metav1.ListOptions{})
+	statefulSets, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.AppsV1().StatefulSets(namespace).List)
 	if err != nil {
 		return false, err
 	}
@@ -141,10 +142,10 @@ func validateMatchingTemplates(clientset kubernetes.Interface, namespace string,
 	return false, nil
 }
 
-func validateMatchingWorkloads(clientset kubernetes.Interface, namespace string, selector *metav1.LabelSelector) (bool, error) {
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
+func validateMatchingWorkloads(ctx context.Context, clientset kubernetes.Interface, namespace string, selector *metav1.LabelSelector) (bool, error) {
+	pods, err := utils.ListAll(ctx, metav1.ListOptions{
 		LabelSelector: metav1.FormatLabelSelector(selector),
-	})
+	}, clientset.CoreV1().Pods(namespace).List)
 	if err != nil {
 		return false, err
 	}
@@ -155,17 +156,21 @@ func validateMatchingWorkloads(clientset kubernetes.Interface, namespace string,
 	return false, nil
 }
 
-func GetUnusedPdbs(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+func GetUnusedPdbs(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	clientset = scanClientset(clientset)
 	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespacePdbs(clientset, namespace, filterOpts)
+	for _, scan := range scanNamespaces(filterOpts.Namespaces(ctx, clientset), func(namespace string) ([]ResourceInfo, error) {
+		return processNamespacePdbs(ctx, clientset, namespace, filterOpts)
+	}) {
+		namespace, diff, err := scan.namespace, scan.result, scan.err
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
+			recordScanError("Pdb", namespace, err)
 			continue
 		}
-		if opts.DeleteFlag {
-			if diff, err = DeleteResource(diff, clientset, namespace, "PDB", opts.NoInteractive); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to delete PDB %s in namespace %s: %v\n", diff, namespace, err)
+		exportManifests(ctx, clientset, namespace, "PDB", diff, opts)
+		if deleteEnabled(opts, "PDB") {
+			if diff, err = DeleteResource(ctx, diff, clientset, namespace, "PDB", opts.NoInteractive); err != nil {
+				slog.Error("Failed to delete PDB", "name", diff, "namespace", namespace, "error", err)
 			}
 		}
 		switch opts.GroupBy {
@@ -177,12 +182,15 @@ func GetUnusedPdbs(filterOpts *filters.Options, clientset kubernetes.Interface,
 		}
 	}
 
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
 	var outputBuffer bytes.Buffer
 	var jsonResponse []byte
 	switch outputFormat {
 	case "table":
 		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
+	default:
 		var err error
 		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
 			return "", err
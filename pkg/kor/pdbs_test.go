@@ -115,7 +115,7 @@ func TestProcessNamespacePdbs(t *testing.T) {
 	totalUnusedPdbs := []ResourceInfo{}
 
 	for _, ns := range namespaces {
-		unusedPdbs, err := processNamespacePdbs(clientset, ns, &filters.Options{})
+		unusedPdbs, err := processNamespacePdbs(context.Background(), clientset, ns, &filters.Options{})
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
 		}
@@ -146,7 +146,7 @@ func TestGetUnusedPdbsStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedPdbs(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedPdbs(context.Background(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedPdbsStructured: %v", err)
 	}
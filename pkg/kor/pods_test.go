@@ -92,7 +92,7 @@ func createTestPods(t *testing.T) *fake.Clientset {
 
 func TestProcessNamespacePods(t *testing.T) {
 	clientset := createTestPods(t)
-	evictedPods, err := processNamespacePods(clientset, testNamespace, &filters.Options{})
+	evictedPods, err := processNamespacePods(context.Background(), clientset, testNamespace, &filters.Options{})
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -125,7 +125,7 @@ func TestGetUnusedPodsStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedPods(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedPods(context.Background(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedPodsStructured: %v", err)
 	}
@@ -0,0 +1,97 @@
+package kor
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	runtimepprof "runtime/pprof"
+	"sync"
+)
+
+// ProfileKinds are the profiles StartProfile writes.
+var ProfileKinds = []string{"cpu", "mem"}
+
+// profiling is the profile of the run, written by StopProfile.
+var profiling struct {
+	sync.Mutex
+	kind string
+	file *os.File
+}
+
+// pprofEnabled serves the pprof endpoints, see SetPprof.
+var pprofEnabled bool
+
+// SetPprof makes the exporter and the API server serve the pprof endpoints
+// under /debug/pprof/, to profile long-running kor processes live.
+func SetPprof(enabled bool) {
+	pprofEnabled = enabled
+}
+
+// handlePprof serves the pprof endpoints on mux when they are enabled.
+func handlePprof(mux *http.ServeMux) {
+	if !pprofEnabled {
+		return
+	}
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+}
+
+// StartProfile profiles the run of kor into the file at path, kor.<kind>.pprof
+// when empty. The CPU profile starts right away and the memory profile is a
+// snapshot of the heap taken by StopProfile, which must be called before kor
+// exits. An empty kind profiles nothing.
+func StartProfile(kind, path string) error {
+	if kind == "" {
+		return nil
+	}
+	if kind != "cpu" && kind != "mem" {
+		return fmt.Errorf("unsupported profile %q, expected one of %v", kind, ProfileKinds)
+	}
+	if path == "" {
+		path = fmt.Sprintf("kor.%s.pprof", kind)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if kind == "cpu" {
+		if err := runtimepprof.StartCPUProfile(file); err != nil {
+			file.Close()
+			return err
+		}
+	}
+
+	profiling.Lock()
+	defer profiling.Unlock()
+	profiling.kind, profiling.file = kind, file
+	return nil
+}
+
+// StopProfile writes the profile started by StartProfile, if any.
+func StopProfile() error {
+	profiling.Lock()
+	defer profiling.Unlock()
+	if profiling.file == nil {
+		return nil
+	}
+	file := profiling.file
+	profiling.file = nil
+
+	switch profiling.kind {
+	case "cpu":
+		runtimepprof.StopCPUProfile()
+	case "mem":
+		// The heap profile is as of the last garbage collection
+		runtime.GC()
+		if err := runtimepprof.WriteHeapProfile(file); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	return file.Close()
+}
@@ -0,0 +1,45 @@
+package kor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProfile(t *testing.T) {
+	for _, kind := range ProfileKinds {
+		path := filepath.Join(t.TempDir(), kind+".pprof")
+		if err := StartProfile(kind, path); err != nil {
+			t.Fatal(err)
+		}
+		if err := StopProfile(); err != nil {
+			t.Fatal(err)
+		}
+		if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+			t.Errorf("Expected a %s profile to be written, got %v", kind, err)
+		}
+	}
+
+	if err := StopProfile(); err != nil {
+		t.Errorf("Expected nothing to be written without a profile, got %v", err)
+	}
+	if err := StartProfile("block", filepath.Join(t.TempDir(), "block.pprof")); err == nil {
+		t.Error("Expected an unsupported profile to be rejected")
+	}
+}
+
+func TestPprofEndpoints(t *testing.T) {
+	defer SetPprof(false)
+	for _, enabled := range []bool{false, true} {
+		SetPprof(enabled)
+		mux := http.NewServeMux()
+		handlePprof(mux)
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil))
+		if served := recorder.Code == http.StatusOK; served != enabled {
+			t.Errorf("Expected the pprof endpoints to be served only when enabled, got %d with %v", recorder.Code, enabled)
+		}
+	}
+}
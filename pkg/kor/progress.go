@@ -0,0 +1,154 @@
+package kor
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-isatty"
+)
+
+// progressInterval limits how often the status line is redrawn.
+const progressInterval = 100 * time.Millisecond
+
+// progressReporter draws a live status line on stderr while a scan runs, so
+// scans of large clusters do not look hung. It is only enabled when stderr
+// is a terminal.
+type progressReporter struct {
+	mu         sync.Mutex
+	out        io.Writer
+	enabled    bool
+	namespaces map[string]bool
+	lists      int
+	findings   int
+	drawn      bool
+	lastDraw   time.Time
+}
+
+var progress = &progressReporter{
+	out:        os.Stderr,
+	enabled:    isatty.IsTerminal(os.Stderr.Fd()) || isatty.IsCygwinTerminal(os.Stderr.Fd()),
+	namespaces: make(map[string]bool),
+}
+
+// StopProgress clears the status line, it must be called before the report is printed.
+func StopProgress() {
+	progress.stop()
+}
+
+// listRequest reports whether an API path lists a collection, and the namespace it lists in.
+func listRequest(path string) (namespace string, ok bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(segments) >= 2 && segments[0] == "api":
+		segments = segments[2:]
+	case len(segments) >= 3 && segments[0] == "apis":
+		segments = segments[3:]
+	default:
+		return "", false
+	}
+
+	switch {
+	case len(segments) == 1:
+		return "", true
+	case len(segments) == 3 && segments[0] == "namespaces":
+		return segments[1], true
+	}
+	return "", false
+}
+
+func (p *progressReporter) observeRequest(req *http.Request) {
+	if req.Method != http.MethodGet {
+		return
+	}
+	namespace, ok := listRequest(req.URL.Path)
+	if !ok {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lists++
+	if namespace != "" {
+		p.namespaces[namespace] = true
+	}
+	p.draw(false)
+}
+
+// setFindings records the number of findings so far. Detectors report while
+// they scan and again once the report is built, so the count never goes down.
+func (p *progressReporter) setFindings(findings int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if findings > p.findings {
+		p.findings = findings
+		p.draw(false)
+	}
+}
+
+func (p *progressReporter) addFindings(findings int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.findings += findings
+	p.draw(false)
+}
+
+func (p *progressReporter) draw(force bool) {
+	if !p.enabled || (!force && time.Since(p.lastDraw) < progressInterval) {
+		return
+	}
+	fmt.Fprintf(p.out, "\r\033[KScanning: %d namespaces processed, %d resources listed, %d unused found", len(p.namespaces), p.lists, p.findings)
+	p.drawn = true
+	p.lastDraw = time.Now()
+}
+
+// Write clears the status line before writing log records, it is redrawn on the next update.
+func (p *progressReporter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.drawn {
+		fmt.Fprint(p.out, "\r\033[K")
+		p.drawn = false
+		p.lastDraw = time.Time{}
+	}
+	return p.out.Write(b)
+}
+
+func (p *progressReporter) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.drawn {
+		fmt.Fprint(p.out, "\r\033[K")
+		p.drawn = false
+	}
+	p.enabled = false
+}
+
+// progressRoundTripper feeds the API requests of the clients to the status line,
+// traces them and logs them at debug level.
+type progressRoundTripper struct {
+	next http.RoundTripper
+}
+
+func (rt *progressRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	progress.observeRequest(req)
+	span := traceRequest(req)
+	start := time.Now()
+	resp, err := rt.next.RoundTrip(req)
+	span.finishRequest(resp, err)
+	if err != nil {
+		slog.Debug("API request failed", "method", req.Method, "url", req.URL.String(), "duration", time.Since(start), "error", err)
+		return resp, err
+	}
+	slog.Debug("API request", "method", req.Method, "url", req.URL.String(), "status", resp.StatusCode, "duration", time.Since(start))
+	return resp, nil
+}
+
+func wrapProgress(next http.RoundTripper) http.RoundTripper {
+	return &progressRoundTripper{next: next}
+}
@@ -0,0 +1,57 @@
+package kor
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestListRequest(t *testing.T) {
+	tests := []struct {
+		path      string
+		namespace string
+		list      bool
+	}{
+		{path: "/api/v1/namespaces", list: true},
+		{path: "/api/v1/namespaces/test-namespace", list: false},
+		{path: "/api/v1/namespaces/test-namespace/configmaps", namespace: testNamespace, list: true},
+		{path: "/api/v1/namespaces/test-namespace/configmaps/cm-1", list: false},
+		{path: "/apis/apps/v1/namespaces/test-namespace/deployments", namespace: testNamespace, list: true},
+		{path: "/apis/storage.k8s.io/v1/storageclasses", list: true},
+		{path: "/version", list: false},
+	}
+	for _, test := range tests {
+		namespace, list := listRequest(test.path)
+		if namespace != test.namespace || list != test.list {
+			t.Errorf("%s: expected (%q, %v), got (%q, %v)", test.path, test.namespace, test.list, namespace, list)
+		}
+	}
+}
+
+func TestProgressReporter(t *testing.T) {
+	var out bytes.Buffer
+	p := &progressReporter{out: &out, enabled: true, namespaces: make(map[string]bool)}
+
+	for _, path := range []string{"/api/v1/namespaces/ns-a/configmaps", "/api/v1/namespaces/ns-b/secrets", "/api/v1/namespaces/ns-b/pods"} {
+		req, _ := http.NewRequest(http.MethodGet, "https://cluster"+path, nil)
+		p.observeRequest(req)
+	}
+	p.addFindings(2)
+	p.setFindings(1)
+	p.draw(true)
+
+	if !strings.HasSuffix(out.String(), "Scanning: 2 namespaces processed, 3 resources listed, 2 unused found") {
+		t.Errorf("Unexpected status line %q", out.String())
+	}
+
+	p.stop()
+	if !strings.HasSuffix(out.String(), "\r\033[K") {
+		t.Errorf("Expected the status line to be cleared, got %q", out.String())
+	}
+	stopped := out.String()
+	p.draw(true)
+	if out.String() != stopped {
+		t.Errorf("Expected no status line once stopped, got %q", out.String())
+	}
+}
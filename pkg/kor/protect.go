@@ -0,0 +1,106 @@
+package kor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// ProtectedResource matches resources that --delete, --quarantine and the
+// delete command of kor ui refuse to touch without --force. Kind, Namespace
+// and Name are regular expressions matching the whole value, an empty one
+// matches anything. Kind is matched case-insensitively against the kind or
+// the resource name.
+type ProtectedResource struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Reason    string
+}
+
+// DefaultProtectedResources are always protected, --protect adds to them.
+var DefaultProtectedResources = []ProtectedResource{
+	{Kind: "ConfigMap", Name: `kube-root-ca\.crt`, Reason: "published in every namespace by the root CA controller"},
+	{Kind: "ServiceAccount", Name: "default", Reason: "created in every namespace by Kubernetes"},
+	{Kind: "(Validating|Mutating)WebhookConfiguration", Reason: "admission webhooks guard the cluster"},
+	{Kind: "ClusterRole|ClusterRoleBinding|Role|RoleBinding", Name: "system:.*", Reason: "bootstrapped by Kubernetes"},
+	{Namespace: strings.Join(filters.SystemNamespaces, "|"), Reason: "system namespace"},
+}
+
+type protectionRule struct {
+	kind      *regexp.Regexp
+	namespace *regexp.Regexp
+	name      *regexp.Regexp
+	reason    string
+}
+
+var (
+	protectionRules []protectionRule
+	// forceDelete lets delete and quarantine modes touch protected resources
+	forceDelete bool
+)
+
+func init() {
+	if err := SetProtectedResources(nil, false); err != nil {
+		panic(err)
+	}
+}
+
+// SetProtectedResources protects the given kind/namespace/name patterns on
+// top of DefaultProtectedResources, force disables the protection.
+func SetProtectedResources(patterns []string, force bool) error {
+	resources := append([]ProtectedResource{}, DefaultProtectedResources...)
+	for _, pattern := range patterns {
+		parts := strings.Split(pattern, "/")
+		if len(parts) != 3 {
+			return fmt.Errorf("invalid protected resource %q, must be kind/namespace/name", pattern)
+		}
+		resources = append(resources, ProtectedResource{Kind: parts[0], Namespace: parts[1], Name: parts[2], Reason: "protected with --protect"})
+	}
+
+	rules := make([]protectionRule, 0, len(resources))
+	for _, resource := range resources {
+		var patterns [3]*regexp.Regexp
+		for i, pattern := range []string{"(?i)" + orAnyPattern(resource.Kind), orAnyPattern(resource.Namespace), orAnyPattern(resource.Name)} {
+			re, err := regexp.Compile("^(?:" + pattern + ")$")
+			if err != nil {
+				return fmt.Errorf("invalid protected resource pattern %q: %w", pattern, err)
+			}
+			patterns[i] = re
+		}
+		rules = append(rules, protectionRule{kind: patterns[0], namespace: patterns[1], name: patterns[2], reason: resource.Reason})
+	}
+	protectionRules = rules
+	forceDelete = force
+	return nil
+}
+
+// protectedReason returns why the resource must not be deleted or
+// quarantined, or false when it may be. resourceType is a report kind or the
+// plural resource name of a dynamic resource.
+func protectedReason(resourceType, namespace, name string) (string, bool) {
+	if forceDelete {
+		return "", false
+	}
+	candidates := []string{resourceType, lookupScriptKind(resourceType).kind, strings.TrimSuffix(resourceType, "s")}
+	for _, rule := range protectionRules {
+		if !rule.namespace.MatchString(namespace) || !rule.name.MatchString(name) {
+			continue
+		}
+		for _, kind := range candidates {
+			if rule.kind.MatchString(kind) {
+				return rule.reason, true
+			}
+		}
+	}
+	return "", false
+}
+
+func orAnyPattern(pattern string) string {
+	if pattern == "" {
+		return ".*"
+	}
+	return pattern
+}
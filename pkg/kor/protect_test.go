@@ -0,0 +1,104 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestProtectedReason(t *testing.T) {
+	defer func() { _ = SetProtectedResources(nil, false) }()
+
+	if err := SetProtectedResources([]string{"Secret/prod/.*"}, false); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		resourceType, namespace, name string
+		want                          bool
+	}{
+		{"ConfigMap", "default", "kube-root-ca.crt", true},
+		{"ConfigMap", "default", "kube-root-ca-crt", false},
+		{"ServiceAccount", "payments", "default", true},
+		{"ServiceAccount", "payments", "payments", false},
+		{"Deployment", "kube-system", "coredns", true},
+		{"ClusterRole", "", "system:controller:job-controller", true},
+		{"validatingwebhookconfigurations", "", "gatekeeper", true},
+		{"Secret", "prod", "api-token", true},
+		{"Secret", "staging", "api-token", false},
+	}
+	for _, tt := range tests {
+		if _, got := protectedReason(tt.resourceType, tt.namespace, tt.name); got != tt.want {
+			t.Errorf("protectedReason(%s, %s, %s) = %v, want %v", tt.resourceType, tt.namespace, tt.name, got, tt.want)
+		}
+	}
+
+	if err := SetProtectedResources(nil, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, protected := protectedReason("ConfigMap", "default", "kube-root-ca.crt"); protected {
+		t.Error("Expected --force to disable the protection")
+	}
+
+	if err := SetProtectedResources([]string{"Secret/prod"}, false); err == nil {
+		t.Error("Expected an error for a pattern without a name")
+	}
+}
+
+func TestDeleteResourceProtected(t *testing.T) {
+	clientset := fake.NewSimpleClientset(CreateTestConfigmap(testNamespace, "kube-root-ca.crt", AppLabels))
+
+	diff := []ResourceInfo{{Name: "kube-root-ca.crt", Reason: "ConfigMap is not used in any pod or container"}}
+	deletedDiff, err := DeleteResource(context.Background(), diff, clientset, testNamespace, "ConfigMap", true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(deletedDiff) != 1 || deletedDiff[0] != diff[0] {
+		t.Errorf("Expected the protected configmap to be reported but not deleted, got %v", deletedDiff)
+	}
+	for _, action := range clientset.Actions() {
+		if action.GetVerb() == "delete" {
+			t.Errorf("Expected no delete request, got %v", action)
+		}
+	}
+}
+
+func TestResultBrowserProtected(t *testing.T) {
+	defer func() { _ = SetProtectedResources(nil, false) }()
+	if err := SetProtectedResources([]string{"ConfigMap//keep-.*"}, false); err != nil {
+		t.Fatal(err)
+	}
+	findings := []unusedResource{
+		{Namespace: testNamespace, Kind: "ConfigMap", Name: "keep-me"},
+		{Namespace: testNamespace, Kind: "ServiceAccount", Name: "default"},
+	}
+	newClientset := func() *fake.Clientset {
+		return fake.NewSimpleClientset(CreateTestConfigmap(testNamespace, "keep-me", AppLabels), CreateTestServiceAccount(testNamespace, "default", AppLabels))
+	}
+
+	clientset := newClientset()
+	var out bytes.Buffer
+	if err := newResultBrowser(clientset, findings, strings.NewReader("mark 1 2\ndelete\ny\nquit\n"), &out).run(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, action := range clientset.Actions() {
+		if action.GetVerb() == "delete" {
+			t.Errorf("Expected the protected findings to survive delete, got %v", action)
+		}
+	}
+
+	if err := SetProtectedResources([]string{"ConfigMap//keep-.*"}, true); err != nil {
+		t.Fatal(err)
+	}
+	clientset = newClientset()
+	out.Reset()
+	if err := newResultBrowser(clientset, findings, strings.NewReader("mark 1 2\ndelete\ny\nquit\n"), &out).run(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := clientset.CoreV1().ServiceAccounts(testNamespace).Get(context.TODO(), "default", metav1.GetOptions{}); err == nil {
+		t.Errorf("Expected --force to let kor ui delete protected findings, got:\n%s", out.String())
+	}
+}
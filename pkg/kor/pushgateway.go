@@ -0,0 +1,43 @@
+package kor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// DefaultPushGatewayJob is the job label of the metrics pushed to a Pushgateway
+const DefaultPushGatewayJob = "kor"
+
+// runStarted is when kor started, one-off runs are timed from it.
+var runStarted = time.Now()
+
+// PushMetrics pushes the unused resource counts of this run and its duration to
+// the Pushgateway at url, replacing the metrics previously pushed with the same
+// job and grouping labels, given as key=value. Credentials of the url are used
+// for basic authentication.
+func PushMetrics(url, job string, grouping []string) error {
+	pusher := push.New(url, job)
+	for _, label := range grouping {
+		name, value, ok := strings.Cut(label, "=")
+		if !ok || name == "" {
+			return fmt.Errorf("invalid grouping label %q, must be name=value", label)
+		}
+		pusher = pusher.Grouping(name, value)
+	}
+
+	updateMetrics(reportedResources)
+	scanDurationGauge.Set(time.Since(runStarted).Seconds())
+	lastScanGauge.Set(float64(time.Now().Unix()))
+	err := pusher.
+		Collector(unusedResourcesGauge).
+		Collector(scanDurationGauge).
+		Collector(lastScanGauge).
+		Push()
+	if err != nil {
+		return fmt.Errorf("failed to push metrics to %s: %w", url, err)
+	}
+	return nil
+}
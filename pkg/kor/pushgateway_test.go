@@ -0,0 +1,52 @@
+package kor
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPushMetrics(t *testing.T) {
+	reportedResources = []unusedResource{{Namespace: "default", Kind: "ConfigMap", Name: "cm-1"}}
+	defer resetReportedResources()
+
+	var method, path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	}))
+	defer server.Close()
+
+	if err := PushMetrics(server.URL, DefaultPushGatewayJob, []string{"cluster=production"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// PUT replaces the metrics of the previous run, so resolved resources disappear
+	if method != http.MethodPut || path != "/metrics/job/kor/cluster/production" {
+		t.Errorf("Expected PUT /metrics/job/kor/cluster/production, got %s %s", method, path)
+	}
+	for _, metric := range []string{"kor_unused_resources", "kor_scan_duration_seconds", "kor_last_scan_timestamp_seconds"} {
+		if !strings.Contains(body, metric) {
+			t.Errorf("Expected %s to be pushed", metric)
+		}
+	}
+	if strings.Contains(body, "kubernetes_orphaned_resources") {
+		t.Error("Expected only the counts to be pushed, not a series per resource")
+	}
+}
+
+func TestPushMetricsErrors(t *testing.T) {
+	if err := PushMetrics("http://localhost", DefaultPushGatewayJob, []string{"cluster"}); err == nil || !strings.Contains(err.Error(), "invalid grouping label") {
+		t.Errorf("Expected an invalid grouping error, got %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+	if err := PushMetrics(server.URL, DefaultPushGatewayJob, nil); err == nil {
+		t.Error("Expected the Pushgateway error")
+	}
+}
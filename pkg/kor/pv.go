@@ -5,7 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
+	"log/slog"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -13,10 +13,11 @@ import (
 
 	"github.com/yonahd/kor/pkg/common"
 	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
 )
 
-func processPvs(clientset kubernetes.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
-	pvs, err := clientset.CoreV1().PersistentVolumes().List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func processPvs(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	pvs, err := utils.ListAll(ctx, metav1.ListOptions{LabelSelector: filterOpts.LabelSelector()}, clientset.CoreV1().PersistentVolumes().List)
 	if err != nil {
 		return nil, err
 	}
@@ -24,7 +25,7 @@ func processPvs(clientset kubernetes.Interface, filterOpts *filters.Options) ([]
 	var unusedPvs []ResourceInfo
 
 	for _, pv := range pvs.Items {
-		if pass := filters.KorLabelFilter(&pv, &filters.Options{}); pass {
+		if pass, _ := filter.SetObject(&pv).Run(filterOpts, filters.LabelFilterName, filters.AgeFilterName); pass {
 			continue
 		}
 
@@ -45,15 +46,16 @@ func processPvs(clientset kubernetes.Interface, filterOpts *filters.Options) ([]
 
 }
 
-func GetUnusedPvs(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+func GetUnusedPvs(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
 	resources := make(map[string]map[string][]ResourceInfo)
-	diff, err := processPvs(clientset, filterOpts)
+	diff, err := processPvs(ctx, clientset, filterOpts)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to process pvs: %v\n", err)
+		recordScanError("Pv", "", err)
 	}
-	if opts.DeleteFlag {
-		if diff, err = DeleteResource(diff, clientset, "", "PV", opts.NoInteractive); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete PV %s: %v\n", diff, err)
+	exportManifests(ctx, clientset, "", "PV", diff, opts)
+	if deleteEnabled(opts, "PV") {
+		if diff, err = DeleteResource(ctx, diff, clientset, "", "PV", opts.NoInteractive); err != nil {
+			slog.Error("Failed to delete PV", "name", diff, "error", err)
 		}
 	}
 	switch opts.GroupBy {
@@ -64,12 +66,15 @@ func GetUnusedPvs(filterOpts *filters.Options, clientset kubernetes.Interface, o
 		appendResources(resources, "Pv", "", diff)
 	}
 
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
 	var outputBuffer bytes.Buffer
 	var jsonResponse []byte
 	switch outputFormat {
 	case "table":
 		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
+	default:
 		var err error
 		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
 			return "", err
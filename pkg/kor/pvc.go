@@ -5,7 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
+	"log/slog"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -13,13 +13,13 @@ import (
 
 	"github.com/yonahd/kor/pkg/common"
 	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
 )
 
-func retrieveUsedPvcs(clientset kubernetes.Interface, namespace string) ([]string, error) {
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+func retrieveUsedPvcs(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]string, error) {
+	pods, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.CoreV1().Pods(namespace).List)
 	if err != nil {
-		fmt.Printf("Failed to list Pods: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to list pods: %w", err)
 	}
 	var usedPvcs []string
 	// Iterate through each Pod and check for PVC usage
@@ -33,8 +33,8 @@ func retrieveUsedPvcs(clientset kubernetes.Interface, namespace string) ([]strin
 	return usedPvcs, err
 }
 
-func processNamespacePvcs(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
-	pvcs, err := clientset.CoreV1().PersistentVolumeClaims(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func processNamespacePvcs(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	pvcs, err := utils.ListAll(ctx, metav1.ListOptions{LabelSelector: filterOpts.LabelSelector()}, clientset.CoreV1().PersistentVolumeClaims(namespace).List)
 	if err != nil {
 		return nil, err
 	}
@@ -42,7 +42,7 @@ func processNamespacePvcs(clientset kubernetes.Interface, namespace string, filt
 	var unusedPvcNames []string
 	pvcNames := make([]string, 0, len(pvcs.Items))
 	for _, pvc := range pvcs.Items {
-		if pass := filters.KorLabelFilter(&pvc, &filters.Options{}); pass {
+		if pass, _ := filter.SetObject(&pvc).Run(filterOpts, filters.LabelFilterName, filters.AgeFilterName); pass {
 			continue
 		}
 
@@ -54,7 +54,7 @@ func processNamespacePvcs(clientset kubernetes.Interface, namespace string, filt
 		pvcNames = append(pvcNames, pvc.Name)
 	}
 
-	usedPvcs, err := retrieveUsedPvcs(clientset, namespace)
+	usedPvcs, err := retrieveUsedPvcs(ctx, clientset, namespace)
 	if err != nil {
 		return nil, err
 	}
@@ -73,17 +73,21 @@ func processNamespacePvcs(clientset kubernetes.Interface, namespace string, filt
 	return diff, nil
 }
 
-func GetUnusedPvcs(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+func GetUnusedPvcs(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	clientset = scanClientset(clientset)
 	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespacePvcs(clientset, namespace, filterOpts)
+	for _, scan := range scanNamespaces(filterOpts.Namespaces(ctx, clientset), func(namespace string) ([]ResourceInfo, error) {
+		return processNamespacePvcs(ctx, clientset, namespace, filterOpts)
+	}) {
+		namespace, diff, err := scan.namespace, scan.result, scan.err
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
+			recordScanError("Pvc", namespace, err)
 			continue
 		}
-		if opts.DeleteFlag {
-			if diff, err = DeleteResource(diff, clientset, namespace, "PVC", opts.NoInteractive); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to delete PVC %s in namespace %s: %v\n", diff, namespace, err)
+		exportManifests(ctx, clientset, namespace, "PVC", diff, opts)
+		if deleteEnabled(opts, "PVC") {
+			if diff, err = DeleteResource(ctx, diff, clientset, namespace, "PVC", opts.NoInteractive); err != nil {
+				slog.Error("Failed to delete PVC", "name", diff, "namespace", namespace, "error", err)
 			}
 		}
 		switch opts.GroupBy {
@@ -95,12 +99,15 @@ func GetUnusedPvcs(filterOpts *filters.Options, clientset kubernetes.Interface,
 		}
 	}
 
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
 	var outputBuffer bytes.Buffer
 	var jsonResponse []byte
 	switch outputFormat {
 	case "table":
 		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
+	default:
 		var err error
 		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
 			return "", err
@@ -67,7 +67,7 @@ func createTestPvcs(t *testing.T) *fake.Clientset {
 
 func TestRetrieveUsedPvcs(t *testing.T) {
 	clientset := createTestPvcs(t)
-	usedPvcs, err := retrieveUsedPvcs(clientset, testNamespace)
+	usedPvcs, err := retrieveUsedPvcs(context.Background(), clientset, testNamespace)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -83,7 +83,7 @@ func TestRetrieveUsedPvcs(t *testing.T) {
 
 func TestProcessNamespacePvcs(t *testing.T) {
 	clientset := createTestPvcs(t)
-	usedPvcs, err := processNamespacePvcs(clientset, testNamespace, &filters.Options{})
+	usedPvcs, err := processNamespacePvcs(context.Background(), clientset, testNamespace, &filters.Options{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -109,7 +109,7 @@ func TestGetUnusedPvcsStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedPvcs(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedPvcs(context.Background(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedPvcsStructured: %v", err)
 	}
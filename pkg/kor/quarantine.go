@@ -0,0 +1,70 @@
+package kor
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// QuarantineResources marks every resource reported during this run as
+// quarantined instead of deleting it. A later run with --quarantined-for then
+// only reports the resources that stayed unused for the whole grace period.
+func QuarantineResources(ctx context.Context, clientset kubernetes.Interface) {
+	now := time.Now()
+	for _, finding := range scriptFindings(reportedResources) {
+		resourceType := finding.Kind
+		if mapped, ok := reportResourceTypes[resourceType]; ok {
+			resourceType = mapped
+		}
+		if reason, protected := protectedReason(finding.Kind, finding.Namespace, finding.Name); protected {
+			slog.Warn("Skipping protected resource, use --force to quarantine it", "resource", finding.Kind, "name", finding.Name, "namespace", finding.Namespace, "reason", reason)
+			continue
+		}
+		if err := QuarantineResource(ctx, clientset, finding.Namespace, resourceType, finding.Name, now); err != nil {
+			slog.Error("Failed to quarantine resource", "resource", finding.Kind, "name", finding.Name, "namespace", finding.Namespace, "error", err)
+			continue
+		}
+		slog.Info("Quarantined resource", "resource", finding.Kind, "name", finding.Name, "namespace", finding.Namespace)
+	}
+}
+
+// QuarantineResource labels the resource with filters.QuarantineLabel and
+// records the time in the filters.UnusedSinceKey annotation. The time of an
+// earlier quarantine is kept, so the grace period is not restarted.
+func QuarantineResource(ctx context.Context, clientset kubernetes.Interface, namespace, resourceType, resourceName string, now time.Time) error {
+	resource, err := getResource(ctx, clientset, namespace, resourceType, resourceName)
+	if err != nil {
+		return err
+	}
+	accessor, err := meta.Accessor(resource)
+	if err != nil {
+		return err
+	}
+	if _, ok := filters.QuarantinedSince(accessor); ok && accessor.GetLabels()[filters.QuarantineLabel] == "true" {
+		return nil
+	}
+
+	labels := accessor.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[filters.QuarantineLabel] = "true"
+	accessor.SetLabels(labels)
+
+	annotations := accessor.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	if _, ok := filters.QuarantinedSince(accessor); !ok {
+		annotations[filters.UnusedSinceKey] = now.UTC().Format(time.RFC3339)
+	}
+	accessor.SetAnnotations(annotations)
+
+	_, err = updateResource(ctx, clientset, namespace, resourceType, resource)
+	return err
+}
@@ -0,0 +1,45 @@
+package kor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func TestQuarantineResource(t *testing.T) {
+	clientset := fake.NewSimpleClientset(CreateTestConfigmap(testNamespace, "configmap-1", AppLabels))
+
+	first := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := QuarantineResource(context.Background(), clientset, testNamespace, "ConfigMap", "configmap-1", first); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// A second run must not restart the grace period
+	if err := QuarantineResource(context.Background(), clientset, testNamespace, "ConfigMap", "configmap-1", first.Add(24*time.Hour)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	configmap, err := clientset.CoreV1().ConfigMaps(testNamespace).Get(context.TODO(), "configmap-1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error getting configmap: %v", err)
+	}
+	if configmap.Labels[filters.QuarantineLabel] != "true" {
+		t.Errorf("Expected the %s label, got %v", filters.QuarantineLabel, configmap.Labels)
+	}
+	if got := configmap.Annotations[filters.UnusedSinceKey]; got != "2026-01-02T03:04:05Z" {
+		t.Errorf("Expected %s to be 2026-01-02T03:04:05Z, got %q", filters.UnusedSinceKey, got)
+	}
+	for key, value := range AppLabels {
+		if configmap.Labels[key] != value {
+			t.Errorf("Expected the existing label %s=%s to be kept", key, value)
+		}
+	}
+
+	if err := QuarantineResource(context.Background(), clientset, testNamespace, "ConfigMap", "missing", first); err == nil {
+		t.Error("Expected an error for a missing configmap")
+	}
+}
@@ -0,0 +1,206 @@
+package kor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// scanResource is a resource of an API group a scan lists.
+type scanResource struct {
+	group    string
+	resource string
+}
+
+func inGroup(group string, resources ...string) []scanResource {
+	scanResources := make([]scanResource, 0, len(resources))
+	for _, resource := range resources {
+		scanResources = append(scanResources, scanResource{group: group, resource: resource})
+	}
+	return scanResources
+}
+
+var (
+	// podSpecResources hold the pod specs the ConfigMaps, Secrets and
+	// ServiceAccount tokens are looked up in
+	podSpecResources = slices.Concat(
+		inGroup("", "pods"),
+		inGroup("apps", "deployments", "statefulsets", "daemonsets", "replicasets"),
+		inGroup("batch", "jobs", "cronjobs"),
+	)
+	// crossNamespaceResources are listed across the cluster for the Services
+	// and Secrets
+	crossNamespaceResources = slices.Concat(
+		inGroup("admissionregistration.k8s.io", "validatingwebhookconfigurations", "mutatingwebhookconfigurations"),
+		inGroup("apiregistration.k8s.io", "apiservices"),
+		inGroup("gateway.networking.k8s.io", "gateways", "httproutes", "grpcroutes", "tlsroutes", "tcproutes", "udproutes"),
+	)
+)
+
+// detectorResources are the resources the built-in detectors list, by
+// detector name.
+var detectorResources = map[string][]scanResource{
+	"configmap":               slices.Concat(inGroup("", "configmaps"), podSpecResources),
+	"service":                 slices.Concat(inGroup("", "services", "endpoints"), crossNamespaceResources),
+	"secret":                  slices.Concat(inGroup("", "secrets", "serviceaccounts"), podSpecResources, inGroup("networking.k8s.io", "ingresses"), crossNamespaceResources),
+	"serviceaccount":          slices.Concat(inGroup("", "serviceaccounts", "pods"), inGroup(rbacv1.GroupName, "rolebindings", "clusterrolebindings")),
+	"deployment":              inGroup("apps", "deployments"),
+	"statefulset":             inGroup("apps", "statefulsets"),
+	"role":                    inGroup(rbacv1.GroupName, "roles", "rolebindings"),
+	"horizontalpodautoscaler": slices.Concat(inGroup("autoscaling", "horizontalpodautoscalers"), inGroup("apps", "deployments", "statefulsets")),
+	"persistentvolumeclaim":   inGroup("", "persistentvolumeclaims", "pods"),
+	"pod":                     inGroup("", "pods"),
+	"ingress":                 slices.Concat(inGroup("networking.k8s.io", "ingresses"), inGroup("", "services")),
+	"poddisruptionbudget":     slices.Concat(inGroup("policy", "poddisruptionbudgets"), inGroup("apps", "deployments", "statefulsets"), inGroup("", "pods")),
+	"job":                     inGroup("batch", "jobs"),
+	"replicaset":              inGroup("apps", "replicasets"),
+	"daemonset":               inGroup("apps", "daemonsets"),
+	"networkpolicy":           slices.Concat(inGroup("networking.k8s.io", "networkpolicies"), inGroup("", "pods")),
+	"rolebinding":             slices.Concat(inGroup(rbacv1.GroupName, "rolebindings", "roles", "clusterroles"), inGroup("", "serviceaccounts")),
+	"endpoints":               inGroup("", "endpoints", "services"),
+	"endpointslice":           slices.Concat(inGroup("discovery.k8s.io", "endpointslices"), inGroup("", "services")),
+	"serviceaccounttoken":     slices.Concat(inGroup("", "secrets", "serviceaccounts"), podSpecResources),
+	"helmrelease":             inGroup("", "secrets"),
+	"managedsecret": slices.Concat(
+		inGroup("", "secrets", "serviceaccounts"), podSpecResources, inGroup("networking.k8s.io", "ingresses"), crossNamespaceResources,
+		inGroup("bitnami.com", "sealedsecrets"), inGroup("external-secrets.io", "externalsecrets"),
+	),
+	"gitops": slices.Concat(
+		inGroup("kustomize.toolkit.fluxcd.io", "kustomizations"), inGroup("helm.toolkit.fluxcd.io", "helmreleases"), inGroup("argoproj.io", "applications"),
+		inGroup(fluxSourceGroup, "gitrepositories", "ocirepositories", "buckets", "helmrepositories", "helmcharts"),
+	),
+	"customresourcedefinition": inGroup("apiextensions.k8s.io", "customresourcedefinitions"),
+	"persistentvolume":         inGroup("", "persistentvolumes"),
+	"clusterrole":              inGroup(rbacv1.GroupName, "clusterroles", "clusterrolebindings", "rolebindings"),
+	"storageclass":             slices.Concat(inGroup("storage.k8s.io", "storageclasses"), inGroup("", "persistentvolumes", "persistentvolumeclaims")),
+	"csidriver":                slices.Concat(inGroup("storage.k8s.io", "csidrivers", "storageclasses"), inGroup("", "persistentvolumes", "pods")),
+	"volumeattachment":         slices.Concat(inGroup("storage.k8s.io", "volumeattachments"), inGroup("", "nodes", "persistentvolumes")),
+	"apiservice":               slices.Concat(inGroup("apiregistration.k8s.io", "apiservices"), inGroup("", "services")),
+	"node":                     inGroup("", "nodes", "pods"),
+}
+
+// detectorNotes tell what the Role of a detector cannot grant up front.
+var detectorNotes = map[string]string{
+	"customresourcedefinition": "The customresourcedefinition detector also lists the custom resources of every CustomResourceDefinition.",
+	"helmrelease":              "The helmrelease detector also gets the objects of the Helm releases.",
+}
+
+// ScanRole returns the ClusterRole allowing kor to scan the resources of the
+// detectors in names, every built-in one when empty, along with notes on the
+// permissions it cannot grant up front.
+func ScanRole(names []string) (*rbacv1.ClusterRole, []string, error) {
+	var detectors []string
+	for _, name := range names {
+		detector := lookupDetector(name)
+		if detector == nil {
+			return nil, nil, fmt.Errorf("unknown resource %q", name)
+		}
+		detectors = append(detectors, detector.Name())
+	}
+	if len(names) == 0 {
+		for _, detector := range Detectors() {
+			detectors = append(detectors, detector.Name())
+		}
+	}
+
+	byGroup := map[string]map[string]bool{"": {"namespaces": true}}
+	add := func(resource scanResource) {
+		if byGroup[resource.group] == nil {
+			byGroup[resource.group] = make(map[string]bool)
+		}
+		byGroup[resource.group][resource.resource] = true
+	}
+	var notes []string
+	for _, detector := range detectors {
+		resources, ok := detectorResources[detector]
+		if !ok {
+			slog.Warn("Unknown permissions of the detector, not in the role", "detector", detector)
+			continue
+		}
+		for _, resource := range resources {
+			add(resource)
+		}
+		if note, ok := detectorNotes[detector]; ok && !slices.Contains(notes, note) {
+			notes = append(notes, note)
+		}
+	}
+	for _, rule := range referenceRules {
+		add(scanResource{group: rule.gvr.Group, resource: rule.gvr.Resource})
+	}
+
+	verbs := []string{"get", "list"}
+	if informerCache {
+		verbs = append(verbs, "watch")
+	}
+	role := &rbacv1.ClusterRole{
+		TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+		ObjectMeta: metav1.ObjectMeta{Name: "kor"},
+	}
+	for _, group := range sortedKeys(byGroup) {
+		resources := make([]string, 0, len(byGroup[group]))
+		for resource := range byGroup[group] {
+			resources = append(resources, resource)
+		}
+		sort.Strings(resources)
+		role.Rules = append(role.Rules, rbacv1.PolicyRule{APIGroups: []string{group}, Resources: resources, Verbs: verbs})
+	}
+	return role, notes, nil
+}
+
+// FormatScanRole renders role as a YAML manifest, preceded by the notes as
+// comments.
+func FormatScanRole(role *rbacv1.ClusterRole, notes []string) (string, error) {
+	data, err := yaml.Marshal(role)
+	if err != nil {
+		return "", err
+	}
+	var output strings.Builder
+	for _, note := range notes {
+		fmt.Fprintf(&output, "# %s\n", note)
+	}
+	// The role is not read from the cluster
+	output.WriteString(strings.Replace(string(data), "  creationTimestamp: null\n", "", 1))
+	return output.String(), nil
+}
+
+// MissingScanPermissions returns the verbs and resources of role the user of
+// clientset is not allowed across the cluster, like "list configmaps".
+func MissingScanPermissions(ctx context.Context, clientset kubernetes.Interface, role *rbacv1.ClusterRole) ([]string, error) {
+	var missing []string
+	for _, rule := range role.Rules {
+		for _, group := range rule.APIGroups {
+			for _, resource := range rule.Resources {
+				for _, verb := range rule.Verbs {
+					review, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+						Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+							ResourceAttributes: &authorizationv1.ResourceAttributes{Verb: verb, Group: group, Resource: resource},
+						},
+					}, metav1.CreateOptions{})
+					if err != nil {
+						return nil, err
+					}
+					if !review.Status.Allowed {
+						missing = append(missing, verb+" "+qualifiedResource(group, resource))
+					}
+				}
+			}
+		}
+	}
+	return missing, nil
+}
+
+func qualifiedResource(group, resource string) string {
+	if group == "" {
+		return resource
+	}
+	return resource + "." + group
+}
@@ -0,0 +1,79 @@
+package kor
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestScanRoleCoversBuiltinDetectors(t *testing.T) {
+	for _, detector := range Detectors() {
+		if _, ok := detectorResources[detector.Name()]; !ok {
+			t.Errorf("Expected the resources the %s detector lists", detector.Name())
+		}
+	}
+}
+
+func TestScanRole(t *testing.T) {
+	role, notes, err := ScanRole([]string{"cm", "crd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var core, apps []string
+	for _, rule := range role.Rules {
+		switch rule.APIGroups[0] {
+		case "":
+			core = rule.Resources
+		case "apps":
+			apps = rule.Resources
+		}
+		if !slices.Equal(rule.Verbs, []string{"get", "list"}) {
+			t.Errorf("Expected the rules to get and list, got %v", rule.Verbs)
+		}
+	}
+	if !slices.Equal(core, []string{"configmaps", "namespaces", "pods"}) || !slices.Contains(apps, "deployments") {
+		t.Errorf("Expected the configmaps and the pod specs, got %v and %v", core, apps)
+	}
+	if len(notes) != 1 || !strings.Contains(notes[0], "custom resources") {
+		t.Errorf("Expected the note of the crd detector, got %v", notes)
+	}
+
+	output, err := FormatScanRole(role, notes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(output, "# The customresourcedefinition detector") || !strings.Contains(output, "kind: ClusterRole\nmetadata:\n  name: kor\n") {
+		t.Errorf("Expected the notes and the role manifest, got %q", output)
+	}
+
+	if _, _, err := ScanRole([]string{"configmap", "widget"}); err == nil {
+		t.Error("Expected an error for an unknown resource")
+	}
+}
+
+func TestMissingScanPermissions(t *testing.T) {
+	role, _, err := ScanRole([]string{"pod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = review.Spec.ResourceAttributes.Resource == "namespaces" || review.Spec.ResourceAttributes.Verb == "get"
+		return true, review, nil
+	})
+
+	missing, err := MissingScanPermissions(context.TODO(), clientset, role)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(missing, []string{"list pods"}) {
+		t.Errorf("Expected the pods not to be listable, got %v", missing)
+	}
+}
@@ -0,0 +1,198 @@
+package kor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+// ReferenceRulesFile is the layout of the file passed with --reference-rules.
+type ReferenceRulesFile struct {
+	Rules []ReferenceRule `json:"rules"`
+}
+
+// ReferenceRule makes the custom resources of APIVersion and Kind use the
+// ConfigMaps and Secrets of their namespace named at the JSONPath of their
+// References. Resource is the plural resource name, guessed from Kind when
+// empty.
+type ReferenceRule struct {
+	APIVersion string          `json:"apiVersion"`
+	Kind       string          `json:"kind"`
+	Resource   string          `json:"resource,omitempty"`
+	References []ReferencePath `json:"references"`
+}
+
+// ReferencePath is a JSONPath, like .spec.secretRef.name, of the names of
+// the ConfigMaps or Secrets a custom resource uses.
+type ReferencePath struct {
+	Kind string `json:"kind"`
+	Path string `json:"path"`
+}
+
+type referenceRule struct {
+	gvr        schema.GroupVersionResource
+	references []ReferencePath
+}
+
+var (
+	referenceRules []referenceRule
+	// dynamicClients are the dynamic clients the reference rules list the
+	// custom resources with, by clientset
+	dynamicClients sync.Map
+)
+
+// LoadReferenceRules reads the rules of the reference rules file at path,
+// an empty path clears them.
+func LoadReferenceRules(path string) error {
+	referenceRules = nil
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read reference rules file: %w", err)
+	}
+	var file ReferenceRulesFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return fmt.Errorf("failed to parse reference rules file %s: %w", path, err)
+	}
+
+	rules := make([]referenceRule, 0, len(file.Rules))
+	for _, rule := range file.Rules {
+		gv, err := schema.ParseGroupVersion(rule.APIVersion)
+		if err != nil || rule.APIVersion == "" || rule.Kind == "" {
+			return fmt.Errorf("invalid reference rule %s %s, apiVersion and kind are required", rule.APIVersion, rule.Kind)
+		}
+		gvr := gv.WithResource(rule.Resource)
+		if rule.Resource == "" {
+			gvr, _ = meta.UnsafeGuessKindToResource(gv.WithKind(rule.Kind))
+		}
+		references := make([]ReferencePath, 0, len(rule.References))
+		for _, reference := range rule.References {
+			if reference.Kind != "ConfigMap" && reference.Kind != "Secret" {
+				return fmt.Errorf("invalid reference of %s, the kind must be ConfigMap or Secret", rule.Kind)
+			}
+			reference.Path = jsonPathTemplate(reference.Path)
+			if err := jsonpath.New(rule.Kind).Parse(reference.Path); err != nil {
+				return fmt.Errorf("invalid reference path %s of %s: %w", reference.Path, rule.Kind, err)
+			}
+			references = append(references, reference)
+		}
+		rules = append(rules, referenceRule{gvr: gvr, references: references})
+	}
+	referenceRules = rules
+	return nil
+}
+
+// jsonPathTemplate wraps a bare .spec.secretRef.name path in braces like
+// kubectl -o jsonpath does.
+func jsonPathTemplate(path string) string {
+	if strings.HasPrefix(path, "{") {
+		return path
+	}
+	return "{" + path + "}"
+}
+
+// registerDynamicClient makes the reference rules of the scans with
+// clientset list the custom resources with a dynamic client of config.
+func registerDynamicClient(clientset kubernetes.Interface, config *rest.Config) error {
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	dynamicClients.Store(clientset, client)
+	return nil
+}
+
+// dynamicClientFor returns the dynamic client registered for clientset or
+// for the clientset it serves lists from.
+func dynamicClientFor(clientset kubernetes.Interface) (dynamic.Interface, bool) {
+	if wrapped, ok := clientset.(*listSourceClientset); ok {
+		clientset = wrapped.Interface
+	}
+	client, ok := dynamicClients.Load(clientset)
+	if !ok {
+		return nil, false
+	}
+	return client.(dynamic.Interface), true
+}
+
+// retrieveRuleReferences returns the ConfigMaps or Secrets of kind the
+// custom resources of the namespace use according to the reference rules.
+// The resources the API server does not serve or lets kor list are skipped.
+func retrieveRuleReferences(ctx context.Context, clientset kubernetes.Interface, namespace, kind string) ([]string, error) {
+	if len(referenceRules) == 0 {
+		return nil, nil
+	}
+	client, ok := dynamicClientFor(clientset)
+	if !ok {
+		return nil, nil
+	}
+
+	var names []string
+	for _, rule := range referenceRules {
+		var paths []string
+		for _, reference := range rule.references {
+			if reference.Kind == kind {
+				paths = append(paths, reference.Path)
+			}
+		}
+		if len(paths) == 0 {
+			continue
+		}
+
+		list, err := utils.ListAll(ctx, metav1.ListOptions{}, client.Resource(rule.gvr).Namespace(namespace).List)
+		if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list.Items {
+			for _, path := range paths {
+				// The parsed paths keep the state of their evaluation
+				parser := jsonpath.New(rule.gvr.Resource).AllowMissingKeys(true)
+				if err := parser.Parse(path); err != nil {
+					return nil, err
+				}
+				results, err := parser.FindResults(item.Object)
+				if err != nil {
+					continue
+				}
+				for _, result := range results {
+					for _, value := range result {
+						if name, ok := referenceName(value); ok {
+							names = append(names, name)
+						}
+					}
+				}
+			}
+		}
+	}
+	return names, nil
+}
+
+func referenceName(value reflect.Value) (string, bool) {
+	if value.Kind() == reflect.Interface {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.String || value.String() == "" {
+		return "", false
+	}
+	return value.String(), true
+}
@@ -0,0 +1,119 @@
+package kor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+const testReferenceRules = `rules:
+  - apiVersion: cert-manager.io/v1
+    kind: Certificate
+    references:
+      - kind: Secret
+        path: .spec.secretName
+  - apiVersion: example.com/v1
+    kind: Pipeline
+    resource: pipelines
+    references:
+      - kind: ConfigMap
+        path: "{.spec.steps[*].configMapRef.name}"
+      - kind: Secret
+        path: .spec.credentials.name
+`
+
+func writeReferenceRules(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReferenceRules(t *testing.T) {
+	certificate := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Certificate",
+		"metadata":   map[string]interface{}{"name": "certificate-1", "namespace": testNamespace},
+		"spec":       map[string]interface{}{"secretName": "secret-tls"},
+	}}
+	pipeline := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Pipeline",
+		"metadata":   map[string]interface{}{"name": "pipeline-1", "namespace": testNamespace},
+		"spec": map[string]interface{}{
+			"steps": []interface{}{
+				map[string]interface{}{"configMapRef": map[string]interface{}{"name": "configmap-build"}},
+				map[string]interface{}{"configMapRef": map[string]interface{}{"name": "configmap-test"}},
+			},
+		},
+	}}
+	dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), crossNamespaceListKinds(map[schema.GroupVersionResource]string{
+		{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}: "CertificateList",
+		{Group: "example.com", Version: "v1", Resource: "pipelines"}:        "PipelineList",
+	}), certificate, pipeline)
+	clientset := fake.NewSimpleClientset(
+		CreateTestConfigmap(testNamespace, "configmap-build", AppLabels),
+		CreateTestConfigmap(testNamespace, "configmap-test", AppLabels),
+		CreateTestConfigmap(testNamespace, "configmap-1", AppLabels),
+		CreateTestSecret(testNamespace, "secret-tls", AppLabels),
+		CreateTestSecret(testNamespace, "secret-1", AppLabels),
+	)
+	dynamicClients.Store(clientset, dynamicClient)
+	defer dynamicClients.Delete(clientset)
+
+	if err := LoadReferenceRules(writeReferenceRules(t, testReferenceRules)); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := LoadReferenceRules(""); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	configmaps, err := processNamespaceCM(context.TODO(), clientset, testNamespace, &filters.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := resourceInfoNames(configmaps); !slices.Equal(names, []string{"configmap-1"}) {
+		t.Errorf("Expected the configmaps of the pipeline steps to be used, got %v", names)
+	}
+
+	// The cluster-wide lists serve the clientset of the dynamic client
+	secrets, err := processNamespaceSecret(context.TODO(), &listSourceClientset{Interface: clientset, source: &clusterLists{lists: make(map[clusterListKey]*clusterList)}}, testNamespace, &filters.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := resourceInfoNames(secrets); !slices.Equal(names, []string{"secret-1"}) {
+		t.Errorf("Expected the secret of the certificate to be used, got %v", names)
+	}
+}
+
+func TestLoadReferenceRulesInvalid(t *testing.T) {
+	defer func() {
+		if err := LoadReferenceRules(""); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	for _, content := range []string{
+		"rules:\n  - kind: Certificate\n",
+		"rules:\n  - apiVersion: v1\n    kind: Pod\n    references:\n      - kind: Service\n        path: .spec.serviceName\n",
+		"rules:\n  - apiVersion: v1\n    kind: Pod\n    references:\n      - kind: Secret\n        path: \"{.spec[\"\n",
+		"rule: []\n",
+	} {
+		if err := LoadReferenceRules(writeReferenceRules(t, content)); err == nil {
+			t.Errorf("Expected an error for the reference rules %q", content)
+		}
+	}
+}
@@ -67,7 +67,7 @@ func TestProcessNamespaceReplicaSets(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedReplicaSets(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedReplicaSets(context.Background(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedReplicaSetsStructured: %v", err)
 	}
@@ -0,0 +1,181 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	yamlutil "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/restmapper"
+
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+// NewRESTMapper returns a RESTMapper discovering the resources of the cluster.
+func NewRESTMapper(clientset kubernetes.Interface) meta.RESTMapper {
+	return restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(clientset.Discovery()))
+}
+
+// RestoreManifests creates the resources of a backup written with
+// --export-manifests again. The archive is the backup directory, one of its
+// files or an s3://bucket/prefix URL. Resources that exist already are skipped.
+func RestoreManifests(ctx context.Context, archive string, dynamicClient dynamic.Interface, mapper meta.RESTMapper) error {
+	files, err := readArchive(archive)
+	if err != nil {
+		return err
+	}
+
+	var objects []*unstructured.Unstructured
+	for _, file := range files {
+		decoded, err := decodeManifests(file.content)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", file.name, err)
+		}
+		objects = append(objects, decoded...)
+	}
+	// Undo the deletion order, so the resources are created before the ones referencing them
+	sort.SliceStable(objects, func(i, j int) bool {
+		return lookupScriptKind(objects[i].GetKind()).order > lookupScriptKind(objects[j].GetKind()).order
+	})
+
+	failed := 0
+	for _, obj := range objects {
+		if err := restoreObject(ctx, obj, dynamicClient, mapper); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to restore %s %s in namespace %s: %v\n", obj.GetKind(), obj.GetName(), obj.GetNamespace(), err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to restore %d of %d resources", failed, len(objects))
+	}
+	return nil
+}
+
+func restoreObject(ctx context.Context, obj *unstructured.Unstructured, dynamicClient dynamic.Interface, mapper meta.RESTMapper) error {
+	gvk := obj.GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return err
+	}
+	var client dynamic.ResourceInterface = dynamicClient.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		client = dynamicClient.Resource(mapping.Resource).Namespace(obj.GetNamespace())
+	}
+
+	if _, err := client.Create(ctx, obj, metav1.CreateOptions{}); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			fmt.Printf("Skipping %s %s in namespace %s, it already exists\n", obj.GetKind(), obj.GetName(), obj.GetNamespace())
+			return nil
+		}
+		return err
+	}
+	fmt.Printf("Restored %s %s in namespace %s\n", obj.GetKind(), obj.GetName(), obj.GetNamespace())
+	return nil
+}
+
+type archiveFile struct {
+	name    string
+	content []byte
+}
+
+// readArchive returns the manifest files of a backup sorted by name.
+func readArchive(archive string) ([]archiveFile, error) {
+	var files []archiveFile
+	if utils.IsS3URL(archive) {
+		location, err := utils.ParseS3URL(archive)
+		if err != nil {
+			return nil, err
+		}
+		client, err := utils.NewS3ClientFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		prefix := location.Prefix
+		if prefix != "" {
+			prefix += "/"
+		}
+		keys, err := client.ListObjects(location.Bucket, prefix)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			if !isManifestFile(key) {
+				continue
+			}
+			content, err := client.GetObject(location.Bucket, key)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, archiveFile{name: key, content: content})
+		}
+	} else {
+		info, err := os.Stat(archive)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup: %w", err)
+		}
+		paths := []string{archive}
+		if info.IsDir() {
+			entries, err := os.ReadDir(archive)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read backup: %w", err)
+			}
+			paths = nil
+			for _, entry := range entries {
+				if !entry.IsDir() && isManifestFile(entry.Name()) {
+					paths = append(paths, filepath.Join(archive, entry.Name()))
+				}
+			}
+		}
+		for _, path := range paths {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read backup: %w", err)
+			}
+			files = append(files, archiveFile{name: path, content: content})
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no manifests found in %s", archive)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+	return files, nil
+}
+
+func isManifestFile(name string) bool {
+	return strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml")
+}
+
+func decodeManifests(content []byte) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	decoder := yamlutil.NewYAMLOrJSONDecoder(bytes.NewReader(content), 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+			return nil, err
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		if obj.GetKind() == "" || obj.GetName() == "" {
+			return nil, fmt.Errorf("manifest without kind or name")
+		}
+		objects = append(objects, obj)
+	}
+}
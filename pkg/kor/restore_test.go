@@ -0,0 +1,80 @@
+package kor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+)
+
+func TestRestoreManifests(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	configmap := CreateTestConfigmap(testNamespace, "cm-1", AppLabels)
+	configmap.UID = "1234"
+	configmap.ResourceVersion = "42"
+	configmap.Data = map[string]string{"key": "value"}
+	if _, err := clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), configmap, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating fake configmap: %v", err)
+	}
+	if _, err := clientset.StorageV1().StorageClasses().Create(context.TODO(), CreateTestStorageClass("sc-1", "kubernetes.io/no-provisioner"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating fake storageclass: %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "backup")
+	opts := common.Opts{ExportManifests: dir}
+	exportManifests(context.Background(), clientset, testNamespace, "ConfigMap", []ResourceInfo{{Name: "cm-1"}}, opts)
+	exportManifests(context.Background(), clientset, "", "StorageClass", []ResourceInfo{{Name: "sc-1"}}, opts)
+
+	content, err := os.ReadFile(filepath.Join(dir, testNamespace+".yaml"))
+	if err != nil {
+		t.Fatalf("Error reading exported manifests: %v", err)
+	}
+	for _, field := range []string{"uid:", "resourceVersion:", "creationTimestamp:"} {
+		if strings.Contains(string(content), field) {
+			t.Errorf("Expected %s to be stripped from the backup, got:\n%s", field, content)
+		}
+	}
+
+	configmapGVR := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	storageClassGVR := schema.GroupVersionResource{Group: "storage.k8s.io", Version: "v1", Resource: "storageclasses"}
+	dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		configmapGVR:    "ConfigMapList",
+		storageClassGVR: "StorageClassList",
+	})
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "storage.k8s.io", Version: "v1", Kind: "StorageClass"}, meta.RESTScopeRoot)
+
+	if err := RestoreManifests(context.Background(), dir, dynamicClient, mapper); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	restored, err := dynamicClient.Resource(configmapGVR).Namespace(testNamespace).Get(context.TODO(), "cm-1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the configmap to be restored: %v", err)
+	}
+	if data, _, _ := unstructured.NestedString(restored.Object, "data", "key"); data != "value" {
+		t.Errorf("Expected the restored configmap data, got %v", restored.Object["data"])
+	}
+	if _, err := dynamicClient.Resource(storageClassGVR).Get(context.TODO(), "sc-1", metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected the storageclass to be restored: %v", err)
+	}
+
+	// Resources that exist already are skipped
+	if err := RestoreManifests(context.Background(), filepath.Join(dir, testNamespace+".yaml"), dynamicClient, mapper); err != nil {
+		t.Errorf("Expected existing resources to be skipped, got %v", err)
+	}
+	if err := RestoreManifests(context.Background(), filepath.Join(t.TempDir(), "missing"), dynamicClient, mapper); err == nil {
+		t.Error("Expected an error for a missing backup")
+	}
+}
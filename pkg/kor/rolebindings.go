@@ -6,14 +6,14 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
-	"os"
+	"log/slog"
 
 	v1 "k8s.io/api/rbac/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/yonahd/kor/pkg/common"
 	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
 )
 
 //go:embed exceptions/rolebindings/rolebindings.json
@@ -52,23 +52,23 @@ func validateRoleReference(rb v1.RoleBinding, roleNames, clusterRoleNames map[st
 	return nil
 }
 
-func processNamespaceRoleBindings(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
-	roleBindingsList, err := clientset.RbacV1().RoleBindings(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func processNamespaceRoleBindings(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	roleBindingsList, err := utils.ListAll(ctx, filterOpts.ListOptions(), clientset.RbacV1().RoleBindings(namespace).List)
 	if err != nil {
 		return nil, err
 	}
 
-	roleNames, err := convertNamesToPresenseMap(retrieveRoleNames(clientset, namespace, filterOpts))
+	roleNames, err := convertNamesToPresenseMap(retrieveRoleNames(ctx, clientset, namespace, filterOpts))
 	if err != nil {
 		return nil, err
 	}
 
-	clusterRoleNames, err := convertNamesToPresenseMap(retrieveClusterRoleNames(clientset, filterOpts))
+	clusterRoleNames, err := convertNamesToPresenseMap(retrieveClusterRoleNames(ctx, clientset, filterOpts))
 	if err != nil {
 		return nil, err
 	}
 
-	serviceAccountNames, err := convertNamesToPresenseMap(retrieveServiceAccountNames(clientset, namespace, filterOpts))
+	serviceAccountNames, err := convertNamesToPresenseMap(retrieveServiceAccountNames(ctx, clientset, namespace, filterOpts))
 	if err != nil {
 		return nil, err
 	}
@@ -113,18 +113,22 @@ func processNamespaceRoleBindings(clientset kubernetes.Interface, namespace stri
 	return unusedRoleBindingNames, nil
 }
 
-func GetUnusedRoleBindings(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+func GetUnusedRoleBindings(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	clientset = scanClientset(clientset)
 	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceRoleBindings(clientset, namespace, filterOpts)
+	for _, scan := range scanNamespaces(filterOpts.Namespaces(ctx, clientset), func(namespace string) ([]ResourceInfo, error) {
+		return processNamespaceRoleBindings(ctx, clientset, namespace, filterOpts)
+	}) {
+		namespace, diff, err := scan.namespace, scan.result, scan.err
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
+			recordScanError("RoleBinding", namespace, err)
 			continue
 		}
 
-		if opts.DeleteFlag {
-			if diff, err = DeleteResource(diff, clientset, namespace, "RoleBinding", opts.NoInteractive); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to delete RoleBinding %s in namespace %s: %v\n", diff, namespace, err)
+		exportManifests(ctx, clientset, namespace, "RoleBinding", diff, opts)
+		if deleteEnabled(opts, "RoleBinding") {
+			if diff, err = DeleteResource(ctx, diff, clientset, namespace, "RoleBinding", opts.NoInteractive); err != nil {
+				slog.Error("Failed to delete RoleBinding", "name", diff, "namespace", namespace, "error", err)
 			}
 		}
 
@@ -137,12 +141,15 @@ func GetUnusedRoleBindings(filterOpts *filters.Options, clientset kubernetes.Int
 		}
 	}
 
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
 	var outputBuffer bytes.Buffer
 	var jsonResponse []byte
 	switch outputFormat {
 	case "table":
 		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
+	default:
 		var err error
 		if jsonResponse, err = json.MarshalIndent(resources, "", " "); err != nil {
 			return "", err
@@ -6,7 +6,7 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
-	"os"
+	"log/slog"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -14,14 +14,15 @@ import (
 
 	"github.com/yonahd/kor/pkg/common"
 	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
 )
 
 //go:embed exceptions/roles/roles.json
 var rolesConfig []byte
 
-func retrieveUsedRoles(clientset kubernetes.Interface, namespace string) ([]string, error) {
+func retrieveUsedRoles(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]string, error) {
 	// Get a list of all role bindings in the specified namespace
-	roleBindings, err := clientset.RbacV1().RoleBindings(namespace).List(context.TODO(), metav1.ListOptions{})
+	roleBindings, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.RbacV1().RoleBindings(namespace).List)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list role bindings in namespace %s: %v", namespace, err)
 	}
@@ -39,8 +40,8 @@ func retrieveUsedRoles(clientset kubernetes.Interface, namespace string) ([]stri
 	return usedRoleNames, nil
 }
 
-func retrieveRoleNames(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]string, []string, error) {
-	roles, err := clientset.RbacV1().Roles(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func retrieveRoleNames(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]string, []string, error) {
+	roles, err := utils.ListAll(ctx, metav1.ListOptions{LabelSelector: filterOpts.LabelSelector()}, clientset.RbacV1().Roles(namespace).List)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -53,7 +54,7 @@ func retrieveRoleNames(clientset kubernetes.Interface, namespace string, filterO
 	var unusedRoleNames []string
 	names := make([]string, 0, len(roles.Items))
 	for _, role := range roles.Items {
-		if pass := filters.KorLabelFilter(&role, &filters.Options{}); pass {
+		if pass, _ := filter.SetObject(&role).Run(filterOpts, filters.LabelFilterName, filters.AgeFilterName); pass {
 			continue
 		}
 		if role.Labels["kor/used"] == "false" {
@@ -74,22 +75,20 @@ func retrieveRoleNames(clientset kubernetes.Interface, namespace string, filterO
 	return names, unusedRoleNames, nil
 }
 
-func processNamespaceRoles(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
-	usedRoles, err := retrieveUsedRoles(clientset, namespace)
+func processNamespaceRoles(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	usedRoles, err := retrieveUsedRoles(ctx, clientset, namespace)
 	if err != nil {
 		return nil, err
 	}
 
-	usedRoles = RemoveDuplicatesAndSort(usedRoles)
-
-	roleInfos, rolesUnusedFromLabel, err := retrieveRoleNames(clientset, namespace, filterOpts)
+	roleInfos, rolesUnusedFromLabel, err := retrieveRoleNames(ctx, clientset, namespace, filterOpts)
 	if err != nil {
 		return nil, err
 	}
 
 	var diff []ResourceInfo
 
-	for _, name := range CalculateResourceDifference(usedRoles, roleInfos) {
+	for _, name := range newNameSet(usedRoles).unused(roleInfos) {
 		reason := "ServiceAccount is not in use"
 		diff = append(diff, ResourceInfo{Name: name, Reason: reason})
 	}
@@ -102,17 +101,21 @@ func processNamespaceRoles(clientset kubernetes.Interface, namespace string, fil
 	return diff, nil
 }
 
-func GetUnusedRoles(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+func GetUnusedRoles(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	clientset = scanClientset(clientset)
 	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceRoles(clientset, namespace, filterOpts)
+	for _, scan := range scanNamespaces(filterOpts.Namespaces(ctx, clientset), func(namespace string) ([]ResourceInfo, error) {
+		return processNamespaceRoles(ctx, clientset, namespace, filterOpts)
+	}) {
+		namespace, diff, err := scan.namespace, scan.result, scan.err
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
+			recordScanError("Role", namespace, err)
 			continue
 		}
-		if opts.DeleteFlag {
-			if diff, err = DeleteResource(diff, clientset, namespace, "Role", opts.NoInteractive); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to delete Role %s in namespace %s: %v\n", diff, namespace, err)
+		exportManifests(ctx, clientset, namespace, "Role", diff, opts)
+		if deleteEnabled(opts, "Role") {
+			if diff, err = DeleteResource(ctx, diff, clientset, namespace, "Role", opts.NoInteractive); err != nil {
+				slog.Error("Failed to delete Role", "name", diff, "namespace", namespace, "error", err)
 			}
 		}
 		switch opts.GroupBy {
@@ -124,12 +127,15 @@ func GetUnusedRoles(filterOpts *filters.Options, clientset kubernetes.Interface,
 		}
 	}
 
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
 	var outputBuffer bytes.Buffer
 	var jsonResponse []byte
 	switch outputFormat {
 	case "table":
 		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
+	default:
 		var err error
 		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
 			return "", err
@@ -0,0 +1,132 @@
+package kor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Version        string      `json:"version"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+func sarifRuleID(kind string) string {
+	return "unused-" + strings.ToLower(kind)
+}
+
+// formatSARIF renders findings as a SARIF 2.1.0 log with one rule per
+// resource kind and one warning per unused resource.
+func formatSARIF(findings []unusedResource) (string, error) {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "kor",
+			InformationURI: "https://github.com/yonahd/kor",
+			Version:        utils.Version,
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+
+	rules := make(map[string]bool)
+	for _, finding := range findings {
+		ruleID := sarifRuleID(finding.Kind)
+		if !rules[ruleID] {
+			rules[ruleID] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:               ruleID,
+				Name:             "Unused" + finding.Kind,
+				ShortDescription: sarifMessage{Text: fmt.Sprintf("Unused %s", finding.Kind)},
+			})
+		}
+
+		qualifiedName := finding.Kind + "/" + finding.Name
+		if finding.Namespace != "" {
+			qualifiedName = finding.Namespace + "/" + qualifiedName
+		}
+		message := fmt.Sprintf("%s %s is unused", finding.Kind, finding.Name)
+		if finding.Namespace != "" {
+			message = fmt.Sprintf("%s %s in namespace %s is unused", finding.Kind, finding.Name, finding.Namespace)
+		}
+		if finding.Reason != "" {
+			message = fmt.Sprintf("%s: %s", message, finding.Reason)
+		}
+
+		run.Results = append(run.Results, sarifResult{
+			RuleID:  ruleID,
+			Level:   "warning",
+			Message: sarifMessage{Text: message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: qualifiedName}},
+				LogicalLocations: []sarifLogicalLocation{{
+					Name:               finding.Name,
+					FullyQualifiedName: qualifiedName,
+					Kind:               "resource",
+				}},
+			}},
+		})
+	}
+
+	output, err := json.MarshalIndent(sarifLog{Schema: sarifSchema, Version: "2.1.0", Runs: []sarifRun{run}}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
@@ -0,0 +1,50 @@
+package kor
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/yonahd/kor/pkg/common"
+)
+
+func TestUnusedResourceFormatterSARIF(t *testing.T) {
+	resources := map[string]map[string][]ResourceInfo{
+		"ConfigMap": {
+			testNamespace: {{Name: "cm-1", Reason: "ConfigMap is not used in any pod or container"}},
+		},
+		"StorageClass": {
+			"": {{Name: "sc-1"}},
+		},
+	}
+
+	output, err := formatUnusedResources(resources, "sarif", common.Opts{GroupBy: "resource"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var report sarifLog
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf("Error unmarshaling SARIF output: %v", err)
+	}
+
+	if report.Version != "2.1.0" || len(report.Runs) != 1 {
+		t.Fatalf("Expected a single SARIF 2.1.0 run, got version %s with %d runs", report.Version, len(report.Runs))
+	}
+
+	run := report.Runs[0]
+	if len(run.Tool.Driver.Rules) != 2 || len(run.Results) != 2 {
+		t.Fatalf("Expected 2 rules and 2 results, got %d rules and %d results", len(run.Tool.Driver.Rules), len(run.Results))
+	}
+
+	result := run.Results[0]
+	if result.RuleID != "unused-configmap" {
+		t.Errorf("Expected rule unused-configmap, got %s", result.RuleID)
+	}
+	expectedMessage := "ConfigMap cm-1 in namespace test-namespace is unused: ConfigMap is not used in any pod or container"
+	if result.Message.Text != expectedMessage {
+		t.Errorf("Expected message %q, got %q", expectedMessage, result.Message.Text)
+	}
+	if uri := run.Results[1].Locations[0].PhysicalLocation.ArtifactLocation.URI; uri != "StorageClass/sc-1" {
+		t.Errorf("Expected cluster-scoped location StorageClass/sc-1, got %s", uri)
+	}
+}
@@ -0,0 +1,159 @@
+package kor
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// ScanError is the failure of a detector against a namespace, empty for the
+// cluster-scoped ones. The other namespaces and detectors are scanned
+// regardless. Cluster is the kubeconfig context of the scans across several
+// contexts, the failure to connect to one has no Resource.
+type ScanError struct {
+	Cluster   string
+	Resource  string
+	Namespace string
+	Err       error
+}
+
+func (e *ScanError) Error() string {
+	return fmt.Sprintf("failed to scan %s: %v", e.scanned(), e.Err)
+}
+
+// scanned describes what failed to be scanned, like "the Pod resources of
+// namespace default".
+func (e *ScanError) scanned() string {
+	if e.Resource == "" {
+		return "cluster " + e.Cluster
+	}
+	scanned := "the " + e.Resource + " resources"
+	if e.Namespace != "" {
+		scanned += " of namespace " + e.Namespace
+	}
+	if e.Cluster != "" {
+		scanned += " of cluster " + e.Cluster
+	}
+	return scanned
+}
+
+func (e *ScanError) Unwrap() error {
+	return e.Err
+}
+
+// Skipped reports whether kor was not allowed to list the resources the scan
+// needs, which only its RBAC permissions can fix.
+func (e *ScanError) Skipped() bool {
+	return apierrors.IsForbidden(e.Err)
+}
+
+// SkippedScan is a scan skipped for lack of RBAC permissions, with the reason
+// given by the API server.
+type SkippedScan struct {
+	Cluster   string `json:"cluster,omitempty"`
+	Resource  string `json:"resource"`
+	Namespace string `json:"namespace,omitempty"`
+	Reason    string `json:"reason"`
+}
+
+// scanErrors holds the failures of the scans of this run, alongside the
+// reported resources.
+var scanErrors struct {
+	sync.Mutex
+	errs []error
+}
+
+// recordScanError logs the failure of resource against namespace and keeps
+// it for ScanErrors. A forbidden scan is logged as skipped, the run goes on.
+func recordScanError(resource, namespace string, err error) {
+	recordClusterScanError("", resource, namespace, err)
+}
+
+// recordClusterScanError is recordScanError for the scan of cluster, the
+// kubeconfig context of a scan across several contexts.
+func recordClusterScanError(cluster, resource, namespace string, err error) {
+	scanErr := &ScanError{Cluster: cluster, Resource: resource, Namespace: namespace, Err: err}
+	attrs := []any{"resource", resource, "namespace", namespace, "error", err}
+	if cluster != "" {
+		attrs = append([]any{"cluster", cluster}, attrs...)
+	}
+	switch {
+	case resource == "":
+		slog.Error("Failed to connect to the cluster", "cluster", cluster, "error", err)
+	case scanErr.Skipped():
+		slog.Warn("Skipped namespace, access forbidden", attrs...)
+	default:
+		slog.Error("Failed to process namespace", attrs...)
+	}
+	scanErrors.Lock()
+	defer scanErrors.Unlock()
+	scanErrors.errs = append(scanErrors.errs, scanErr)
+}
+
+// ScanErrors returns the failures of the scans so far joined together, each
+// a *ScanError, or nil when every namespace and detector was scanned. The
+// skipped scans are among them.
+func ScanErrors() error {
+	scanErrors.Lock()
+	defer scanErrors.Unlock()
+	return errors.Join(scanErrors.errs...)
+}
+
+// SkippedScans returns the scans skipped so far for lack of RBAC permissions.
+func SkippedScans() []SkippedScan {
+	scanErrors.Lock()
+	defer scanErrors.Unlock()
+	var skipped []SkippedScan
+	for _, err := range scanErrors.errs {
+		if scanErr, ok := err.(*ScanError); ok && scanErr.Skipped() {
+			skipped = append(skipped, SkippedScan{Cluster: scanErr.Cluster, Resource: scanErr.Resource, Namespace: scanErr.Namespace, Reason: scanErr.Err.Error()})
+		}
+	}
+	return skipped
+}
+
+func resetScanErrors() {
+	scanErrors.Lock()
+	defer scanErrors.Unlock()
+	scanErrors.errs = nil
+}
+
+// FormatScanWarnings renders the failures of the scans so far as the
+// warnings section following a report, the skipped scans apart with their
+// reason, or "" when there are none.
+func FormatScanWarnings() string {
+	scanErrors.Lock()
+	defer scanErrors.Unlock()
+	if len(scanErrors.errs) == 0 {
+		return ""
+	}
+	var failed, skipped []*ScanError
+	for _, err := range scanErrors.errs {
+		scanErr := err.(*ScanError)
+		if scanErr.Skipped() {
+			skipped = append(skipped, scanErr)
+		} else {
+			failed = append(failed, scanErr)
+		}
+	}
+
+	var warnings strings.Builder
+	warnings.WriteString("Warnings: the report is partial\n")
+	if len(failed) > 0 {
+		warnings.WriteString("These scans failed:\n")
+		for _, err := range failed {
+			fmt.Fprintf(&warnings, "- %v\n", err)
+		}
+	}
+	if len(skipped) > 0 {
+		warnings.WriteString("These scans were skipped, kor may not list their resources (see kor rbac-check):\n")
+		for _, err := range skipped {
+			fmt.Fprintf(&warnings, "- %s: %v\n", err.scanned(), err.Err)
+		}
+	}
+	return warnings.String()
+}
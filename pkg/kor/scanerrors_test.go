@@ -0,0 +1,76 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func TestScanErrors(t *testing.T) {
+	defer resetReportedResources()
+	resetReportedResources()
+	clientset := createTestConfigmaps(t)
+	if _, err := clientset.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "forbidden"},
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	clientset.PrependReactor("list", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() != "forbidden" {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "", errors.New("denied"))
+	})
+
+	output, err := GetUnusedConfigmaps(context.Background(), &filters.Options{}, clientset, "json", common.Opts{GroupBy: "namespace"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resources map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &resources); err != nil {
+		t.Fatal(err)
+	}
+	if len(resources[testNamespace]["ConfigMap"]) != 2 {
+		t.Errorf("Expected the configmaps of the other namespaces to be reported, got %v", resources)
+	}
+
+	var scanErr *ScanError
+	if err := ScanErrors(); !errors.As(err, &scanErr) || scanErr.Namespace != "forbidden" || !apierrors.IsForbidden(scanErr) {
+		t.Fatalf("Expected the failure of the forbidden namespace, got %v", err)
+	}
+	if warnings := FormatScanWarnings(); !strings.HasPrefix(warnings, "Warnings:") || !strings.Contains(warnings, "namespace forbidden") {
+		t.Errorf("Expected a warning for the forbidden namespace, got %q", warnings)
+	}
+
+	if skipped := SkippedScans(); len(skipped) != 1 || skipped[0].Resource != "ConfigMap" || skipped[0].Namespace != "forbidden" || !strings.Contains(skipped[0].Reason, "denied") {
+		t.Errorf("Expected the forbidden namespace to be skipped with the reason, got %+v", skipped)
+	}
+	if warnings := FormatScanWarnings(); !strings.Contains(warnings, "kor rbac-check") || strings.Contains(warnings, "These scans failed") {
+		t.Errorf("Expected the forbidden namespace among the skipped scans, got %q", warnings)
+	}
+
+	recordScanError("Pod", "default", errors.New("timeout"))
+	if skipped := SkippedScans(); len(skipped) != 1 {
+		t.Errorf("Expected the failed scan not to be skipped, got %+v", skipped)
+	}
+	if warnings := FormatScanWarnings(); !strings.Contains(warnings, "These scans failed:\n- failed to scan the Pod resources of namespace default: timeout") {
+		t.Errorf("Expected the failed scan among the warnings, got %q", warnings)
+	}
+
+	resetReportedResources()
+	if ScanErrors() != nil || FormatScanWarnings() != "" {
+		t.Error("Expected the failures to be reset along with the reports")
+	}
+}
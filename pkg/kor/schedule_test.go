@@ -0,0 +1,46 @@
+package kor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScanScheduleNext(t *testing.T) {
+	from := time.Date(2026, 10, 14, 10, 30, 15, 0, time.UTC) // a Wednesday
+	tests := []struct {
+		schedule string
+		expected time.Time
+	}{
+		{"*/15 * * * *", time.Date(2026, 10, 14, 10, 45, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2026, 10, 15, 10, 30, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 10, 14, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 1 * * 1", time.Date(2026, 10, 19, 1, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 9-17/4 * * 1-5", time.Date(2026, 10, 14, 13, 0, 0, 0, time.UTC)},
+		{"0 0 1 */3 *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// Either day field matches when both are restricted
+		{"0 0 20 * 5", time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@every 90m", from.Add(90 * time.Minute)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, test := range tests {
+		schedule, err := parseSchedule(test.schedule)
+		if err != nil {
+			t.Errorf("Expected %q to parse, got %v", test.schedule, err)
+			continue
+		}
+		if next := schedule.next(from); !next.Equal(test.expected) {
+			t.Errorf("Expected %q to run at %s, got %s", test.schedule, test.expected, next)
+		}
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, schedule := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "a * * * *", "@every 10s", "@every soon", "@reboot"} {
+		if _, err := parseSchedule(schedule); err == nil {
+			t.Errorf("Expected %q to be rejected", schedule)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package kor
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ScriptFormatShell writes a shell script of kubectl delete commands
+	ScriptFormatShell = "shell"
+	// ScriptFormatKustomize writes `$patch: delete` patches, which remove the resources from
+	// a kustomize build and so get them pruned by Argo CD or kubectl apply --prune
+	ScriptFormatKustomize = "kustomize"
+)
+
+// ScriptFormats lists the values accepted by --script-format.
+var ScriptFormats = []string{ScriptFormatShell, ScriptFormatKustomize}
+
+// scriptKind describes how a reported kind is deleted. Kinds are deleted by
+// ascending order, so the resources referencing others go before them.
+type scriptKind struct {
+	apiVersion string
+	kind       string
+	resource   string
+	order      int
+}
+
+// defaultScriptOrder is used for kinds missing from scriptKinds.
+const defaultScriptOrder = 50
+
+var scriptKinds = map[string]scriptKind{
+	"apiservice":          {"apiregistration.k8s.io/v1", "APIService", "apiservices", 10},
+	"hpa":                 {"autoscaling/v2", "HorizontalPodAutoscaler", "horizontalpodautoscalers", 10},
+	"ingress":             {"networking.k8s.io/v1", "Ingress", "ingresses", 10},
+	"networkpolicy":       {"networking.k8s.io/v1", "NetworkPolicy", "networkpolicies", 10},
+	"pdb":                 {"policy/v1", "PodDisruptionBudget", "poddisruptionbudgets", 10},
+	"service":             {"v1", "Service", "services", 10},
+	"daemonset":           {"apps/v1", "DaemonSet", "daemonsets", 20},
+	"deployment":          {"apps/v1", "Deployment", "deployments", 20},
+	"job":                 {"batch/v1", "Job", "jobs", 20},
+	"statefulset":         {"apps/v1", "StatefulSet", "statefulsets", 20},
+	"replicaset":          {"apps/v1", "ReplicaSet", "replicasets", 30},
+	"pod":                 {"v1", "Pod", "pods", 40},
+	"endpoints":           {"v1", "Endpoints", "endpoints", 45},
+	"endpointslice":       {"discovery.k8s.io/v1", "EndpointSlice", "endpointslices", 45},
+	"configmap":           {"v1", "ConfigMap", "configmaps", 50},
+	"helmreleasesecret":   {"v1", "Secret", "secrets", 50},
+	"secret":              {"v1", "Secret", "secrets", 50},
+	"serviceaccounttoken": {"v1", "Secret", "secrets", 50},
+	"rolebinding":         {"rbac.authorization.k8s.io/v1", "RoleBinding", "rolebindings", 60},
+	"clusterrole":         {"rbac.authorization.k8s.io/v1", "ClusterRole", "clusterroles", 70},
+	"role":                {"rbac.authorization.k8s.io/v1", "Role", "roles", 70},
+	"serviceaccount":      {"v1", "ServiceAccount", "serviceaccounts", 75},
+	"pvc":                 {"v1", "PersistentVolumeClaim", "persistentvolumeclaims", 80},
+	"volumeattachment":    {"storage.k8s.io/v1", "VolumeAttachment", "volumeattachments", 85},
+	"pv":                  {"v1", "PersistentVolume", "persistentvolumes", 90},
+	"csidriver":           {"storage.k8s.io/v1", "CSIDriver", "csidrivers", 95},
+	"storageclass":        {"storage.k8s.io/v1", "StorageClass", "storageclasses", 95},
+	"crd":                 {"apiextensions.k8s.io/v1", "CustomResourceDefinition", "customresourcedefinitions", 100},
+	"node":                {"v1", "Node", "nodes", 110},
+}
+
+// unscriptedKinds are reported for keys of a resource, not for a resource that can be deleted.
+var unscriptedKinds = map[string]bool{
+	"configmapkey": true,
+	"secretkey":    true,
+}
+
+func lookupScriptKind(kind string) scriptKind {
+	if k, ok := scriptKinds[strings.ToLower(kind)]; ok {
+		return k
+	}
+	return scriptKind{kind: kind, resource: strings.ToLower(kind), order: defaultScriptOrder}
+}
+
+// GenerateScript writes the resources reported during this run to path, as a
+// deletion script or manifest in the given format, instead of deleting them.
+func GenerateScript(path, format, kubeContext string) error {
+	script, err := renderScript(reportedResources, format, kubeContext, time.Now())
+	if err != nil {
+		return err
+	}
+	perm := os.FileMode(0o644)
+	if format == ScriptFormatShell {
+		perm = 0o755
+	}
+	if err := os.WriteFile(path, []byte(script), perm); err != nil {
+		return fmt.Errorf("failed to write script: %w", err)
+	}
+	return nil
+}
+
+// ValidateScriptFormat checks the --script-format value.
+func ValidateScriptFormat(format string) error {
+	for _, f := range ScriptFormats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid script format %q, must be one of %v", format, ScriptFormats)
+}
+
+func renderScript(findings []unusedResource, format, kubeContext string, now time.Time) (string, error) {
+	findings = scriptFindings(findings)
+	switch format {
+	case ScriptFormatShell:
+		return renderShellScript(findings, kubeContext, now), nil
+	case ScriptFormatKustomize:
+		return renderKustomizePatches(findings)
+	}
+	return "", ValidateScriptFormat(format)
+}
+
+// scriptFindings drops the findings that cannot be deleted and sorts the rest
+// in deletion order, then by namespace and name.
+func scriptFindings(findings []unusedResource) []unusedResource {
+	var sorted []unusedResource
+	for _, finding := range findings {
+		if unscriptedKinds[strings.ToLower(finding.Kind)] {
+			continue
+		}
+		sorted = append(sorted, finding)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := lookupScriptKind(sorted[i].Kind), lookupScriptKind(sorted[j].Kind)
+		if a.order != b.order {
+			return a.order < b.order
+		}
+		if a.resource != b.resource {
+			return a.resource < b.resource
+		}
+		if sorted[i].Namespace != sorted[j].Namespace {
+			return sorted[i].Namespace < sorted[j].Namespace
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+// shellQuote quotes s as a single word of the shell, the words made only of
+// characters the shell leaves alone are kept as they are.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_.,:/@%+=") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func renderShellScript(findings []unusedResource, kubeContext string, now time.Time) string {
+	var sb strings.Builder
+	sb.WriteString("#!/bin/sh\n")
+	fmt.Fprintf(&sb, "# Generated by kor on %s, %d unused resources.\n", now.UTC().Format(time.RFC3339), len(findings))
+	sb.WriteString("# Review before running, resources are deleted in dependency order.\n")
+	sb.WriteString("set -e\n")
+
+	kubectl := "kubectl"
+	if kubeContext != "" {
+		kubectl += " --context " + shellQuote(kubeContext)
+	}
+	// Resources of the same kind and namespace are deleted with a single command
+	for i := 0; i < len(findings); {
+		kind := lookupScriptKind(findings[i].Kind)
+		namespace := findings[i].Namespace
+		var names []string
+		for ; i < len(findings) && lookupScriptKind(findings[i].Kind).resource == kind.resource && findings[i].Namespace == namespace; i++ {
+			names = append(names, findings[i].Name)
+		}
+
+		sb.WriteString("\n")
+		if namespace != "" {
+			fmt.Fprintf(&sb, "%s delete %s --namespace %s --ignore-not-found \\\n", kubectl, kind.resource, shellQuote(namespace))
+		} else {
+			fmt.Fprintf(&sb, "%s delete %s --ignore-not-found \\\n", kubectl, kind.resource)
+		}
+		sb.WriteString("  " + strings.Join(names, " \\\n  ") + "\n")
+	}
+	return sb.String()
+}
+
+func renderKustomizePatches(findings []unusedResource) (string, error) {
+	var sb strings.Builder
+	for _, finding := range findings {
+		kind := lookupScriptKind(finding.Kind)
+		if kind.apiVersion == "" {
+			slog.Warn("Skipping resource of unknown API version", "kind", finding.Kind, "name", finding.Name)
+			continue
+		}
+		metadata := map[string]string{"name": finding.Name}
+		if finding.Namespace != "" {
+			metadata["namespace"] = finding.Namespace
+		}
+		patch, err := yaml.Marshal(map[string]interface{}{
+			"apiVersion": kind.apiVersion,
+			"kind":       kind.kind,
+			"metadata":   metadata,
+			"$patch":     "delete",
+		})
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString("---\n")
+		sb.Write(patch)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,104 @@
+package kor
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func getFakeScriptFindings() []unusedResource {
+	return []unusedResource{
+		{Namespace: testNamespace, Kind: "ConfigMap", Name: "configmap-2"},
+		{Namespace: testNamespace, Kind: "ConfigMapKey", Name: "configmap-3/key"},
+		{Namespace: "", Kind: "Pv", Name: "pv-1"},
+		{Namespace: testNamespace, Kind: "Deployment", Name: "deployment-1"},
+		{Namespace: testNamespace, Kind: "ConfigMap", Name: "configmap-1"},
+	}
+}
+
+func TestRenderShellScript(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	script, err := renderScript(getFakeScriptFindings(), ScriptFormatShell, "prod", now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `#!/bin/sh
+# Generated by kor on 2026-01-02T03:04:05Z, 4 unused resources.
+# Review before running, resources are deleted in dependency order.
+set -e
+
+kubectl --context prod delete deployments --namespace test-namespace --ignore-not-found \
+  deployment-1
+
+kubectl --context prod delete configmaps --namespace test-namespace --ignore-not-found \
+  configmap-1 \
+  configmap-2
+
+kubectl --context prod delete persistentvolumes --ignore-not-found \
+  pv-1
+`
+	if script != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, script)
+	}
+}
+
+func TestRenderShellScriptQuoting(t *testing.T) {
+	findings := []unusedResource{{Namespace: testNamespace, Kind: "ConfigMap", Name: "configmap-1"}}
+	script, err := renderScript(findings, ScriptFormatShell, "it's prod; rm -rf /", time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := `kubectl --context 'it'\''s prod; rm -rf /' delete configmaps --namespace test-namespace --ignore-not-found \
+  configmap-1
+`
+	if !strings.HasSuffix(script, expected) {
+		t.Errorf("Expected the context to be quoted:\n%s\nGot:\n%s", expected, script)
+	}
+
+	for word, expected := range map[string]string{"": "''", "arn:aws:eks:eu-west-1:1234:cluster/prod": "arn:aws:eks:eu-west-1:1234:cluster/prod", "$(id)": "'$(id)'"} {
+		if quoted := shellQuote(word); quoted != expected {
+			t.Errorf("Expected %q to be quoted as %s, got %s", word, expected, quoted)
+		}
+	}
+}
+
+func TestRenderKustomizePatches(t *testing.T) {
+	findings := []unusedResource{
+		{Namespace: testNamespace, Kind: "Hpa", Name: "hpa-1"},
+		{Namespace: "", Kind: "Crd", Name: "foos.example.com"},
+	}
+	patches, err := renderScript(findings, ScriptFormatKustomize, "", time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `---
+$patch: delete
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: hpa-1
+  namespace: test-namespace
+---
+$patch: delete
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: foos.example.com
+`
+	if patches != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, patches)
+	}
+}
+
+func TestValidateScriptFormat(t *testing.T) {
+	for _, format := range ScriptFormats {
+		if err := ValidateScriptFormat(format); err != nil {
+			t.Errorf("Expected %s to be valid, got %v", format, err)
+		}
+	}
+	if err := ValidateScriptFormat("terraform"); err == nil || !strings.Contains(err.Error(), "terraform") {
+		t.Errorf("Expected an error for an unknown script format, got %v", err)
+	}
+}
@@ -6,15 +6,19 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
-	"os"
+	"log/slog"
+	"strings"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
 	"k8s.io/utils/strings/slices"
 
 	"github.com/yonahd/kor/pkg/common"
 	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
 )
 
 var exceptionSecretTypes = []string{
@@ -27,9 +31,9 @@ var exceptionSecretTypes = []string{
 //go:embed exceptions/secrets/secrets.json
 var secretsConfig []byte
 
-func retrieveIngressTLS(clientset kubernetes.Interface, namespace string) ([]string, error) {
+func retrieveIngressTLS(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]string, error) {
 	secretNames := make([]string, 0)
-	ingressList, err := clientset.NetworkingV1().Ingresses(namespace).List(context.TODO(), metav1.ListOptions{})
+	ingressList, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.NetworkingV1().Ingresses(namespace).List)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve Ingress resources: %v", err)
 	}
@@ -45,22 +49,78 @@ func retrieveIngressTLS(clientset kubernetes.Interface, namespace string) ([]str
 
 }
 
-func retrieveUsedSecret(clientset kubernetes.Interface, namespace string) ([]string, []string, []string, []string, []string, []string, error) {
+// retrieveServiceAccountSecrets returns the image pull secrets and the
+// mountable secrets of the ServiceAccounts in the namespace.
+func retrieveServiceAccountSecrets(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]string, error) {
+	serviceAccounts, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.CoreV1().ServiceAccounts(namespace).List)
+	if err != nil {
+		return nil, err
+	}
+
+	var secretNames []string
+	for _, serviceAccount := range serviceAccounts.Items {
+		for _, secret := range serviceAccount.ImagePullSecrets {
+			secretNames = append(secretNames, secret.Name)
+		}
+		for _, secret := range serviceAccount.Secrets {
+			secretNames = append(secretNames, secret.Name)
+		}
+	}
+	return secretNames, nil
+}
+
+// volumePluginSecrets returns the Secrets holding the credentials of the
+// storage plugin of a volume, which are read whole by the kubelet or the
+// CSI driver.
+func volumePluginSecrets(volume corev1.Volume) []string {
+	var secretNames []string
+	localSecret := func(ref *corev1.LocalObjectReference) {
+		if ref != nil && ref.Name != "" {
+			secretNames = append(secretNames, ref.Name)
+		}
+	}
+	switch {
+	case volume.CSI != nil:
+		localSecret(volume.CSI.NodePublishSecretRef)
+	case volume.AzureFile != nil:
+		secretNames = append(secretNames, volume.AzureFile.SecretName)
+	case volume.CephFS != nil:
+		localSecret(volume.CephFS.SecretRef)
+	case volume.RBD != nil:
+		localSecret(volume.RBD.SecretRef)
+	case volume.ISCSI != nil:
+		localSecret(volume.ISCSI.SecretRef)
+	case volume.FlexVolume != nil:
+		localSecret(volume.FlexVolume.SecretRef)
+	case volume.Cinder != nil:
+		localSecret(volume.Cinder.SecretRef)
+	case volume.ScaleIO != nil:
+		localSecret(volume.ScaleIO.SecretRef)
+	case volume.StorageOS != nil:
+		localSecret(volume.StorageOS.SecretRef)
+	}
+	return secretNames
+}
+
+// retrieveUsedSecret returns the Secrets referenced by the pods and pod
+// templates of the namespace, the pull secrets including the Secrets of the
+// ServiceAccounts, and the Secrets of the Ingress TLS.
+func retrieveUsedSecret(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]string, []string, []string, []string, []string, []string, error) {
 	var envSecrets []string
 	var envSecrets2 []string
 	var volumeSecrets []string
 	var pullSecrets []string
 	var initContainerEnvSecrets []string
 
-	// Retrieve pods in the specified namespace
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	// Retrieve the pods and pod templates in the specified namespace
+	specs, err := podSpecs(ctx, clientset, namespace)
 	if err != nil {
 		return nil, nil, nil, nil, nil, nil, err
 	}
 
-	// Extract volume and environment information from pods
-	for _, pod := range pods.Items {
-		for _, container := range pod.Spec.Containers {
+	// Extract volume and environment information from their specs
+	for _, spec := range specs {
+		for _, container := range runContainers(spec) {
 			for _, env := range container.Env {
 				if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
 					envSecrets = append(envSecrets, env.ValueFrom.SecretKeyRef.Name)
@@ -73,15 +133,20 @@ func retrieveUsedSecret(clientset kubernetes.Interface, namespace string) ([]str
 			}
 		}
 
-		for _, initContainer := range pod.Spec.InitContainers {
+		for _, initContainer := range spec.InitContainers {
 			for _, env := range initContainer.Env {
 				if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
 					initContainerEnvSecrets = append(initContainerEnvSecrets, env.ValueFrom.SecretKeyRef.Name)
 				}
 			}
+			for _, envFrom := range initContainer.EnvFrom {
+				if envFrom.SecretRef != nil {
+					initContainerEnvSecrets = append(initContainerEnvSecrets, envFrom.SecretRef.Name)
+				}
+			}
 		}
 
-		for _, volume := range pod.Spec.Volumes {
+		for _, volume := range spec.Volumes {
 			if volume.Secret != nil {
 				volumeSecrets = append(volumeSecrets, volume.Secret.SecretName)
 			}
@@ -92,16 +157,23 @@ func retrieveUsedSecret(clientset kubernetes.Interface, namespace string) ([]str
 					}
 				}
 			}
+			volumeSecrets = append(volumeSecrets, volumePluginSecrets(volume)...)
 		}
 
-		if pod.Spec.ImagePullSecrets != nil {
-			for _, secret := range pod.Spec.ImagePullSecrets {
+		if spec.ImagePullSecrets != nil {
+			for _, secret := range spec.ImagePullSecrets {
 				pullSecrets = append(pullSecrets, secret.Name)
 			}
 		}
 	}
 
-	tlsSecrets, err := retrieveIngressTLS(clientset, namespace)
+	serviceAccountSecrets, err := retrieveServiceAccountSecrets(ctx, clientset, namespace)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, err
+	}
+	pullSecrets = append(pullSecrets, serviceAccountSecrets...)
+
+	tlsSecrets, err := retrieveIngressTLS(ctx, clientset, namespace)
 	if err != nil {
 		return nil, nil, nil, nil, nil, nil, err
 	}
@@ -109,8 +181,46 @@ func retrieveUsedSecret(clientset kubernetes.Interface, namespace string) ([]str
 	return envSecrets, envSecrets2, volumeSecrets, initContainerEnvSecrets, pullSecrets, tlsSecrets, nil
 }
 
-func retrieveSecretNames(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]string, []string, error) {
-	secrets, err := clientset.CoreV1().Secrets(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+// listSecretCandidates lists the Secrets of the namespace retrieveSecretNames
+// looks at. Listed with only their metadata, the Secrets of the exception
+// types are left out by a field selector, so the ones marked unused, reported
+// whatever their type, are listed apart.
+func listSecretCandidates(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]metadataObject, error) {
+	gvk := corev1.SchemeGroupVersion.WithKind("Secret")
+	options := filterOpts.ListOptions()
+	list := clientset.CoreV1().Secrets(namespace).List
+	if _, ok := metadataClientFor(clientset); !ok {
+		return listObjectMetadata(ctx, clientset, gvk, namespace, options, list)
+	}
+
+	typed := options
+	selectors := make([]fields.Selector, 0, len(exceptionSecretTypes))
+	for _, secretType := range exceptionSecretTypes {
+		selectors = append(selectors, fields.OneTermNotEqualSelector("type", secretType))
+	}
+	typed.FieldSelector = strings.Trim(options.FieldSelector+","+fields.AndSelectors(selectors...).String(), ",")
+	secrets, err := listObjectMetadata(ctx, clientset, gvk, namespace, typed, list)
+	if err != nil {
+		return nil, err
+	}
+
+	marked := options
+	marked.LabelSelector = strings.Trim(options.LabelSelector+",kor/used=false", ",")
+	markedSecrets, err := listObjectMetadata(ctx, clientset, gvk, namespace, marked, list)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]metadataObject, 0, len(secrets)+len(markedSecrets))
+	for _, secret := range secrets {
+		if secret.GetLabels()["kor/used"] != "false" {
+			candidates = append(candidates, secret)
+		}
+	}
+	return append(candidates, markedSecrets...), nil
+}
+
+func retrieveSecretNames(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]string, []string, error) {
+	secrets, err := listSecretCandidates(ctx, clientset, namespace, filterOpts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -121,68 +231,64 @@ func retrieveSecretNames(clientset kubernetes.Interface, namespace string, filte
 	}
 
 	var unusedSecretNames []string
-	names := make([]string, 0, len(secrets.Items))
-	for _, secret := range secrets.Items {
-		if pass, _ := filter.SetObject(&secret).Run(filterOpts); pass {
+	names := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		if pass, _ := filter.SetObject(secret).Run(filterOpts); pass {
 			continue
 		}
 
-		if secret.Labels["kor/used"] == "false" {
-			unusedSecretNames = append(unusedSecretNames, secret.Name)
+		if secret.GetLabels()["kor/used"] == "false" {
+			unusedSecretNames = append(unusedSecretNames, secret.GetName())
 			continue
 		}
 
-		exceptionFound, err := isResourceException(secret.Name, secret.Namespace, config.ExceptionSecrets)
+		exceptionFound, err := isResourceException(secret.GetName(), secret.GetNamespace(), config.ExceptionSecrets)
 		if err != nil {
 			return nil, nil, err
 		}
 
-		if exceptionFound {
+		if exceptionFound || hasUsageMarker("Secret", secret) {
 			continue
 		}
 
-		if !slices.Contains(exceptionSecretTypes, string(secret.Type)) && !exceptionFound {
-			names = append(names, secret.Name)
+		// The metadata lists leave the exception types out with a field selector
+		if typed, ok := secret.(*corev1.Secret); ok && slices.Contains(exceptionSecretTypes, string(typed.Type)) {
+			continue
 		}
+		names = append(names, secret.GetName())
 	}
 	return names, unusedSecretNames, nil
 }
 
-func processNamespaceSecret(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
-	envSecrets, envSecrets2, volumeSecrets, initContainerEnvSecrets, pullSecrets, tlsSecrets, err := retrieveUsedSecret(clientset, namespace)
+func processNamespaceSecret(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	envSecrets, envSecrets2, volumeSecrets, initContainerEnvSecrets, pullSecrets, tlsSecrets, err := retrieveUsedSecret(ctx, clientset, namespace)
 	if err != nil {
 		return nil, err
 	}
 
-	envSecrets = RemoveDuplicatesAndSort(envSecrets)
-	envSecrets2 = RemoveDuplicatesAndSort(envSecrets2)
-	volumeSecrets = RemoveDuplicatesAndSort(volumeSecrets)
-	initContainerEnvSecrets = RemoveDuplicatesAndSort(initContainerEnvSecrets)
-	pullSecrets = RemoveDuplicatesAndSort(pullSecrets)
-	tlsSecrets = RemoveDuplicatesAndSort(tlsSecrets)
-
-	secretNames, unusedSecretNames, err := retrieveSecretNames(clientset, namespace, filterOpts)
+	secretNames, unusedSecretNames, err := retrieveSecretNames(ctx, clientset, namespace, filterOpts)
 	if err != nil {
 		return nil, err
 	}
 
-	var usedSecrets []string
-	slicesToAppend := [][]string{
-		envSecrets,
-		envSecrets2,
-		volumeSecrets,
-		pullSecrets,
-		tlsSecrets,
-		initContainerEnvSecrets,
+	markedSecrets, err := retrieveMarkedReferences(ctx, clientset, namespace, "Secret")
+	if err != nil {
+		return nil, err
 	}
-
-	for _, slice := range slicesToAppend {
-		usedSecrets = append(usedSecrets, slice...)
+	ruleSecrets, err := retrieveRuleReferences(ctx, clientset, namespace, "Secret")
+	if err != nil {
+		return nil, err
+	}
+	crossNamespaceSecrets, err := retrieveCrossNamespaceReferences(ctx, clientset, namespace, "Secret")
+	if err != nil {
+		return nil, err
 	}
 
+	usedSecrets := newNameSet(envSecrets, envSecrets2, volumeSecrets, pullSecrets, tlsSecrets, initContainerEnvSecrets, markedSecrets, ruleSecrets, crossNamespaceSecrets)
+
 	var diff []ResourceInfo
 
-	for _, name := range CalculateResourceDifference(usedSecrets, secretNames) {
+	for _, name := range usedSecrets.unused(secretNames) {
 		reason := "Secret is not used in any pod, container, or ingress"
 		diff = append(diff, ResourceInfo{Name: name, Reason: reason})
 	}
@@ -196,17 +302,21 @@ func processNamespaceSecret(clientset kubernetes.Interface, namespace string, fi
 
 }
 
-func GetUnusedSecrets(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+func GetUnusedSecrets(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	clientset = scanClientset(clientset)
 	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceSecret(clientset, namespace, filterOpts)
+	for _, scan := range scanNamespaces(filterOpts.Namespaces(ctx, clientset), func(namespace string) ([]ResourceInfo, error) {
+		return processNamespaceSecret(ctx, clientset, namespace, filterOpts)
+	}) {
+		namespace, diff, err := scan.namespace, scan.result, scan.err
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
+			recordScanError("Secret", namespace, err)
 			continue
 		}
-		if opts.DeleteFlag {
-			if diff, err = DeleteResource(diff, clientset, namespace, "Secret", opts.NoInteractive); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to delete Secret %s in namespace %s: %v\n", diff, namespace, err)
+		exportManifests(ctx, clientset, namespace, "Secret", diff, opts)
+		if deleteEnabled(opts, "Secret") {
+			if diff, err = DeleteResource(ctx, diff, clientset, namespace, "Secret", opts.NoInteractive); err != nil {
+				slog.Error("Failed to delete Secret", "name", diff, "namespace", namespace, "error", err)
 			}
 		}
 		switch opts.GroupBy {
@@ -218,12 +328,15 @@ func GetUnusedSecrets(filterOpts *filters.Options, clientset kubernetes.Interfac
 		}
 	}
 
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
 	var outputBuffer bytes.Buffer
 	var jsonResponse []byte
 	switch outputFormat {
 	case "table":
 		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
+	default:
 		var err error
 		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
 			return "", err
@@ -237,3 +350,183 @@ func GetUnusedSecrets(filterOpts *filters.Options, clientset kubernetes.Interfac
 
 	return unusedSecrets, nil
 }
+
+// retrieveUsedSecretKeys records the keys of each Secret referenced by pods, pod templates and ingresses in the namespace
+func retrieveUsedSecretKeys(ctx context.Context, clientset kubernetes.Interface, namespace string) (map[string]*keyUsage, error) {
+	specs, err := podSpecs(ctx, clientset, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make(map[string]*keyUsage)
+	for _, spec := range specs {
+		for _, container := range podContainers(spec) {
+			for _, env := range container.Env {
+				if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+					recordUsedKeys(usage, env.ValueFrom.SecretKeyRef.Name, env.ValueFrom.SecretKeyRef.Key)
+				}
+			}
+			for _, envFrom := range container.EnvFrom {
+				if envFrom.SecretRef != nil {
+					recordAllKeysUsed(usage, envFrom.SecretRef.Name)
+				}
+			}
+		}
+
+		subPaths, wholeMounts := volumeSubPaths(spec)
+		for _, volume := range spec.Volumes {
+			if volume.Secret != nil {
+				switch {
+				case len(volume.Secret.Items) > 0:
+					for _, item := range volume.Secret.Items {
+						recordUsedKeys(usage, volume.Secret.SecretName, item.Key)
+					}
+				case wholeMounts[volume.Name] || len(subPaths[volume.Name]) == 0:
+					recordAllKeysUsed(usage, volume.Secret.SecretName)
+				default:
+					recordUsedKeys(usage, volume.Secret.SecretName, subPaths[volume.Name]...)
+				}
+			}
+			if volume.Projected != nil {
+				for _, source := range volume.Projected.Sources {
+					if source.Secret == nil {
+						continue
+					}
+					if len(source.Secret.Items) == 0 {
+						recordAllKeysUsed(usage, source.Secret.Name)
+						continue
+					}
+					for _, item := range source.Secret.Items {
+						recordUsedKeys(usage, source.Secret.Name, item.Key)
+					}
+				}
+			}
+			for _, secret := range volumePluginSecrets(volume) {
+				recordAllKeysUsed(usage, secret)
+			}
+		}
+
+		// The kubelet reads the whole docker config of image pull secrets
+		for _, secret := range spec.ImagePullSecrets {
+			recordAllKeysUsed(usage, secret.Name)
+		}
+	}
+
+	serviceAccountSecrets, err := retrieveServiceAccountSecrets(ctx, clientset, namespace)
+	if err != nil {
+		return nil, err
+	}
+	for _, secret := range serviceAccountSecrets {
+		recordAllKeysUsed(usage, secret)
+	}
+
+	tlsSecrets, err := retrieveIngressTLS(ctx, clientset, namespace)
+	if err != nil {
+		return nil, err
+	}
+	for _, secret := range tlsSecrets {
+		// ca.crt is read by ingress controllers for client certificate authentication
+		recordUsedKeys(usage, secret, corev1.TLSCertKey, corev1.TLSPrivateKeyKey, corev1.ServiceAccountRootCAKey)
+	}
+
+	return usage, nil
+}
+
+// processNamespaceSecretKeys reports the keys of used Secrets which are never referenced
+func processNamespaceSecretKeys(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	usage, err := retrieveUsedSecretKeys(ctx, clientset, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := unmarshalConfig(secretsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets, err := utils.ListAll(ctx, filterOpts.ListOptions(), clientset.CoreV1().Secrets(namespace).List)
+	if err != nil {
+		return nil, err
+	}
+
+	var diff []ResourceInfo
+	for _, secret := range secrets.Items {
+		if pass, _ := filter.SetObject(&secret).Run(filterOpts); pass {
+			continue
+		}
+
+		if slices.Contains(exceptionSecretTypes, string(secret.Type)) {
+			continue
+		}
+
+		// Secrets which are not used at all are reported by processNamespaceSecret
+		secretUsage, ok := usage[secret.Name]
+		if !ok {
+			continue
+		}
+
+		exceptionFound, err := isResourceException(secret.Name, secret.Namespace, config.ExceptionSecrets)
+		if err != nil {
+			return nil, err
+		}
+
+		if exceptionFound {
+			continue
+		}
+
+		keys := make([]string, 0, len(secret.Data))
+		for key := range secret.Data {
+			keys = append(keys, key)
+		}
+
+		for _, key := range secretUsage.unusedKeys(keys) {
+			reason := "Secret key is not referenced by any pod, container, or ingress"
+			diff = append(diff, ResourceInfo{Name: secret.Name + "/" + key, Reason: reason})
+		}
+	}
+
+	return diff, nil
+}
+
+func GetUnusedSecretKeys(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	clientset = scanClientset(clientset)
+	resources := make(map[string]map[string][]ResourceInfo)
+	for _, scan := range scanNamespaces(filterOpts.Namespaces(ctx, clientset), func(namespace string) ([]ResourceInfo, error) {
+		return processNamespaceSecretKeys(ctx, clientset, namespace, filterOpts)
+	}) {
+		namespace, diff, err := scan.namespace, scan.result, scan.err
+		if err != nil {
+			recordScanError("Secret", namespace, err)
+			continue
+		}
+		switch opts.GroupBy {
+		case "namespace":
+			resources[namespace] = make(map[string][]ResourceInfo)
+			resources[namespace]["SecretKey"] = diff
+		case "resource":
+			appendResources(resources, "SecretKey", namespace, diff)
+		}
+	}
+
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+	default:
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	unusedSecretKeys, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return unusedSecretKeys, nil
+}
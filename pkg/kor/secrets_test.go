@@ -168,7 +168,7 @@ func TestRetrieveIngressTLS(t *testing.T) {
 		t.Fatalf("Error creating fake %s: %v", "Secret", err)
 	}
 
-	tlsSecrets, err := retrieveIngressTLS(clientset, testNamespace)
+	tlsSecrets, err := retrieveIngressTLS(context.Background(), clientset, testNamespace)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -186,7 +186,7 @@ func TestRetrieveIngressTLS(t *testing.T) {
 func TestRetrieveUsedSecret(t *testing.T) {
 	clientset := createTestSecrets(t)
 
-	envSecrets, envSecrets2, volumeSecrets, initContainerEnvSecrets, pullSecrets, _, err := retrieveUsedSecret(clientset, testNamespace)
+	envSecrets, envSecrets2, volumeSecrets, initContainerEnvSecrets, pullSecrets, _, err := retrieveUsedSecret(context.Background(), clientset, testNamespace)
 	if err != nil {
 		t.Fatalf("Error retrieving used secrets: %v", err)
 	}
@@ -224,6 +224,64 @@ func TestRetrieveUsedSecret(t *testing.T) {
 
 }
 
+func TestRetrieveUsedSecretInitAndEphemeralContainers(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	pod := CreateTestPod(testNamespace, "pod", "", nil, AppLabels)
+	pod.Spec.InitContainers = []corev1.Container{{
+		Name:    "migrate",
+		EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "secret-init"}}}},
+	}}
+	pod.Spec.EphemeralContainers = []corev1.EphemeralContainer{{EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+		Name:    "debug",
+		EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "secret-debug"}}}},
+	}}}
+	if _, err := clientset.CoreV1().Pods(testNamespace).Create(context.TODO(), pod, v1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, envSecrets2, _, initContainerEnvSecrets, _, _, err := retrieveUsedSecret(context.TODO(), clientset, testNamespace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(envSecrets2, []string{"secret-debug"}) {
+		t.Errorf("Expected the secret of the ephemeral container, got %v", envSecrets2)
+	}
+	if !reflect.DeepEqual(initContainerEnvSecrets, []string{"secret-init"}) {
+		t.Errorf("Expected the secret of the init container, got %v", initContainerEnvSecrets)
+	}
+}
+
+func TestProcessNamespaceSecretReferencePaths(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	for _, name := range []string{"secret-csi", "secret-azure", "secret-cephfs", "secret-sa-pull", "secret-sa-mountable", "secret-unused"} {
+		if _, err := clientset.CoreV1().Secrets(testNamespace).Create(context.TODO(), CreateTestSecret(testNamespace, name, AppLabels), v1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pod := CreateTestPod(testNamespace, "pod", "", []corev1.Volume{
+		{Name: "csi", VolumeSource: corev1.VolumeSource{CSI: &corev1.CSIVolumeSource{Driver: "secrets-store.csi.k8s.io", NodePublishSecretRef: &corev1.LocalObjectReference{Name: "secret-csi"}}}},
+		{Name: "azure", VolumeSource: corev1.VolumeSource{AzureFile: &corev1.AzureFileVolumeSource{SecretName: "secret-azure", ShareName: "share"}}},
+		{Name: "cephfs", VolumeSource: corev1.VolumeSource{CephFS: &corev1.CephFSVolumeSource{Monitors: []string{"mon"}, SecretRef: &corev1.LocalObjectReference{Name: "secret-cephfs"}}}},
+	}, AppLabels)
+	if _, err := clientset.CoreV1().Pods(testNamespace).Create(context.TODO(), pod, v1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	serviceAccount := CreateTestServiceAccount(testNamespace, "builder", AppLabels)
+	serviceAccount.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "secret-sa-pull"}}
+	serviceAccount.Secrets = []corev1.ObjectReference{{Name: "secret-sa-mountable"}}
+	if _, err := clientset.CoreV1().ServiceAccounts(testNamespace).Create(context.TODO(), serviceAccount, v1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	unused, err := processNamespaceSecret(context.TODO(), clientset, testNamespace, &filters.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unused) != 1 || unused[0].Name != "secret-unused" {
+		t.Errorf("Expected the secrets of the volume plugins and the service accounts to be used, got %v", unused)
+	}
+}
+
 func TestRetrieveSecretNames(t *testing.T) {
 	clientset := fake.NewSimpleClientset()
 
@@ -241,7 +299,7 @@ func TestRetrieveSecretNames(t *testing.T) {
 		t.Fatalf("Error creating fake secret: %v", err)
 	}
 
-	secretNames, _, err := retrieveSecretNames(clientset, testNamespace, &filters.Options{})
+	secretNames, _, err := retrieveSecretNames(context.Background(), clientset, testNamespace, &filters.Options{})
 
 	if err != nil {
 		t.Fatalf("Error retrieving secret names: %v", err)
@@ -259,7 +317,7 @@ func TestRetrieveSecretNames(t *testing.T) {
 func TestProcessNamespaceSecret(t *testing.T) {
 	clientset := createTestSecrets(t)
 
-	unusedSecrets, err := processNamespaceSecret(clientset, testNamespace, &filters.Options{})
+	unusedSecrets, err := processNamespaceSecret(context.Background(), clientset, testNamespace, &filters.Options{})
 	if err != nil {
 		t.Fatalf("Error retrieving unused secrets: %v", err)
 	}
@@ -286,7 +344,7 @@ func TestGetUnusedSecretsStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedSecrets(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedSecrets(context.Background(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedSecretsStructured: %v", err)
 	}
@@ -310,6 +368,78 @@ func TestGetUnusedSecretsStructured(t *testing.T) {
 	}
 }
 
+func TestProcessNamespaceSecretKeys(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{
+		ObjectMeta: v1.ObjectMeta{Name: testNamespace},
+	}, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+
+	keys := map[string][]byte{"key-1": []byte("value"), "key-2": []byte("value")}
+	secrets := []*corev1.Secret{
+		CreateTestSecret(testNamespace, "env-secret", AppLabels),
+		CreateTestSecret(testNamespace, "volume-secret", AppLabels),
+		CreateTestSecret(testNamespace, "tls-secret", AppLabels),
+	}
+	for _, secret := range secrets {
+		secret.Data = keys
+	}
+	secrets[2].Data = map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key"), "extra": []byte("value")}
+	for _, secret := range secrets {
+		_, err = clientset.CoreV1().Secrets(testNamespace).Create(context.TODO(), secret, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake secret: %v", err)
+		}
+	}
+
+	pod := CreateTestPod(testNamespace, "pod-1", "", []corev1.Volume{
+		{
+			Name:         "vol-1",
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "volume-secret"}},
+		},
+	}, AppLabels)
+	pod.Spec.Containers = []corev1.Container{
+		{
+			Env: []corev1.EnvVar{
+				{
+					Name:      "ENV_VAR_1",
+					ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "env-secret"}, Key: "key-1"}},
+				},
+			},
+			VolumeMounts: []corev1.VolumeMount{
+				{Name: "vol-1", MountPath: "/etc/secret"},
+			},
+		},
+	}
+	_, err = clientset.CoreV1().Pods(testNamespace).Create(context.TODO(), pod, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake pod: %v", err)
+	}
+
+	ingress := CreateTestIngress(testNamespace, "ingress-1", "my-service", "tls-secret", AppLabels)
+	_, err = clientset.NetworkingV1().Ingresses(testNamespace).Create(context.TODO(), ingress, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake ingress: %v", err)
+	}
+
+	diff, err := processNamespaceSecretKeys(context.Background(), clientset, testNamespace, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Error processing namespace secret keys: %v", err)
+	}
+
+	reason := "Secret key is not referenced by any pod, container, or ingress"
+	expected := []ResourceInfo{
+		{Name: "env-secret/key-2", Reason: reason},
+		{Name: "tls-secret/extra", Reason: reason},
+	}
+	if !equalResourceInfoSlices(diff, expected) {
+		t.Errorf("Expected diff %v, got %v", expected, diff)
+	}
+}
+
 func equalSlices(a, b []string) bool {
 	if len(a) != len(b) {
 		return false
@@ -0,0 +1,324 @@
+package kor
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	korv1 "github.com/yonahd/kor/pkg/api/kor/v1"
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+const (
+	// DefaultServerAddress is the address kor serve listens on
+	DefaultServerAddress = ":8080"
+	// DefaultServerCacheTTL is how long kor serve answers from the last scan
+	DefaultServerCacheTTL = 5 * time.Minute
+	// serverHistorySize caps the scans kept for the history of the dashboard
+	serverHistorySize = 1000
+)
+
+//go:embed dashboard/index.html
+var dashboardPage []byte
+
+// serverScan holds the results of a scan of kor serve.
+type serverScan struct {
+	GeneratedAt time.Time
+	Duration    time.Duration
+	Resources   []Resource
+}
+
+// historyPoint counts the unused resources of a past scan.
+type historyPoint struct {
+	Time  time.Time      `json:"time"`
+	Total int            `json:"total"`
+	Kinds map[string]int `json:"kinds"`
+}
+
+// apiServer answers the REST API from the last scan while it is younger than
+// ttl, and scans the cluster again otherwise. Scans run one at a time, requests
+// arriving during a scan wait for its results.
+type apiServer struct {
+	scan func() error
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu   sync.Mutex
+	last *serverScan
+	// history holds the counts of the last serverHistorySize scans, oldest first
+	history []historyPoint
+}
+
+// Serve serves the read-only REST API of the unused resources on
+// listenAddress, and the gRPC API on grpcListenAddress when set. Scans cover
+// the resourceList, or every resource when empty, and the results of the REST
+// API are reused for cacheTTL. Zero scans on every request.
+func Serve(ctx context.Context, filterOptions *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, opts common.Opts, resourceList []string, listenAddress, grpcListenAddress string, cacheTTL time.Duration) error {
+	clientset = cachedClientset(ctx, clientset)
+	// The API only reads, and resources carry their age, size and owners
+	opts.DeleteFlag = false
+	opts.Wide = true
+	opts.GroupBy = "namespace"
+	scan := func(ctx context.Context, filterOpts *filters.Options, resources []string) error {
+		if len(resources) == 0 {
+			resources = resourceList
+		}
+		if tracer != nil {
+			tracer.startTrace()
+		}
+		resetReportedResources()
+		_, err := getUnusedResources(ctx, filterOpts, clientset, apiExtClient, dynamicClient, "json", opts, resources)
+		if err := FlushTelemetry(); err != nil {
+			slog.Error("Failed to export telemetry", "error", err)
+		}
+		return err
+	}
+	// The options cache the namespaces, a copy per scan also covers the ones created since
+	server := &apiServer{scan: func() error { return scan(ctx, filterOptions.Clone(), nil) }, ttl: cacheTTL, now: time.Now}
+
+	errs := make(chan error, 2)
+	if grpcListenAddress != "" {
+		listener, err := net.Listen("tcp", grpcListenAddress)
+		if err != nil {
+			return err
+		}
+		rpcServer := grpc.NewServer()
+		korv1.RegisterKorServer(rpcServer, &grpcServer{scan: scan})
+		slog.Info("gRPC server listening", "address", grpcListenAddress)
+		go func() { errs <- rpcServer.Serve(listener) }()
+	}
+	slog.Info("Server listening", "address", listenAddress, "cacheTTL", cacheTTL)
+	go func() { errs <- http.ListenAndServe(listenAddress, server.handler()) }()
+	return <-errs
+}
+
+func (s *apiServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/unused", s.handleUnused)
+	mux.HandleFunc("GET /api/v1/summary", s.handleSummary)
+	mux.HandleFunc("GET /api/v1/history", s.handleHistory)
+	mux.HandleFunc("GET /api/v1/export", s.handleExport)
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(dashboardPage)
+	})
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok\n"))
+	})
+	handlePprof(mux)
+	return mux
+}
+
+// results returns the last scan, or the results of a new one when it is
+// older than the ttl or refresh is set.
+func (s *apiServer) results(refresh bool) (*serverScan, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !refresh && s.last != nil && s.now().Sub(s.last.GeneratedAt) < s.ttl {
+		return s.last, nil
+	}
+
+	// gRPC scans share the reported resources
+	scanLock.Lock()
+	defer scanLock.Unlock()
+	start := s.now()
+	if err := s.scan(); err != nil {
+		return nil, err
+	}
+	scan := &serverScan{GeneratedAt: s.now().UTC(), Duration: s.now().Sub(start)}
+	for _, finding := range findingDocuments(scan.GeneratedAt, nil) {
+		scan.Resources = append(scan.Resources, Resource{Namespace: finding.Namespace, Kind: finding.Kind, ResourceInfo: finding.ResourceInfo})
+	}
+	s.last = scan
+
+	point := historyPoint{Time: scan.GeneratedAt, Total: len(scan.Resources), Kinds: map[string]int{}}
+	for _, resource := range scan.Resources {
+		point.Kinds[resource.Kind]++
+	}
+	s.history = append(s.history, point)
+	if len(s.history) > serverHistorySize {
+		s.history = s.history[len(s.history)-serverHistorySize:]
+	}
+	return scan, nil
+}
+
+// handleUnused lists the unused resources, filtered by the comma separated or
+// repeated kind and namespace parameters. refresh=true scans the cluster again.
+func (s *apiServer) handleUnused(w http.ResponseWriter, r *http.Request) {
+	scan, ok := s.requestResults(w, r)
+	if !ok {
+		return
+	}
+	resources := filterAPIResources(scan.Resources, r)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"generatedAt": scan.GeneratedAt,
+		"total":       len(resources),
+		"resources":   resources,
+	})
+}
+
+// filterAPIResources keeps the resources matching the kind and namespace
+// parameters of the request, comma separated or repeated.
+func filterAPIResources(resources []Resource, r *http.Request) []Resource {
+	kinds, namespaces := queryValues(r, "kind"), queryValues(r, "namespace")
+	filtered := []Resource{}
+	for _, resource := range resources {
+		if len(kinds) > 0 && !anyKindMatches(kinds, resource.Kind) {
+			continue
+		}
+		if len(namespaces) > 0 && !slices.Contains(namespaces, resource.Namespace) {
+			continue
+		}
+		filtered = append(filtered, resource)
+	}
+	return filtered
+}
+
+type namespaceCounts struct {
+	Namespace string         `json:"namespace"`
+	Total     int            `json:"total"`
+	Kinds     map[string]int `json:"kinds"`
+}
+
+// handleSummary counts the unused resources per namespace and kind.
+func (s *apiServer) handleSummary(w http.ResponseWriter, r *http.Request) {
+	scan, ok := s.requestResults(w, r)
+	if !ok {
+		return
+	}
+	namespaces := []namespaceCounts{}
+	for _, resource := range scan.Resources {
+		if n := len(namespaces); n == 0 || namespaces[n-1].Namespace != resource.Namespace {
+			namespaces = append(namespaces, namespaceCounts{Namespace: resource.Namespace, Kinds: map[string]int{}})
+		}
+		counts := &namespaces[len(namespaces)-1]
+		counts.Total++
+		counts.Kinds[resource.Kind]++
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"generatedAt":     scan.GeneratedAt,
+		"durationSeconds": scan.Duration.Seconds(),
+		"total":           len(scan.Resources),
+		"namespaces":      namespaces,
+	})
+}
+
+// handleHistory returns the counts of the scans since the server started.
+func (s *apiServer) handleHistory(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	history := append([]historyPoint{}, s.history...)
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{"history": history})
+}
+
+// handleExport downloads the unused resources, filtered like /api/v1/unused,
+// as the JSON document of --webhook-url or the CSV and HTML reports of emails.
+func (s *apiServer) handleExport(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "json" && format != "html" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid format " + strconv.Quote(format) + ", must be csv, json or html"})
+		return
+	}
+	scan, ok := s.requestResults(w, r)
+	if !ok {
+		return
+	}
+	resources := filterAPIResources(scan.Resources, r)
+	report := make(map[string]map[string][]ResourceInfo)
+	for _, resource := range resources {
+		if report[resource.Namespace] == nil {
+			report[resource.Namespace] = make(map[string][]ResourceInfo)
+		}
+		report[resource.Namespace][resource.Kind] = append(report[resource.Namespace][resource.Kind], resource.ResourceInfo)
+	}
+
+	var data []byte
+	var err error
+	contentType := "text/csv; charset=utf-8"
+	switch format {
+	case "csv":
+		data, err = renderCSVReport(reportRows(report))
+	case "json":
+		contentType = "application/json"
+		data, err = json.MarshalIndent(resultDocument{GeneratedAt: scan.GeneratedAt, Total: len(resources), Resources: report}, "", "  ")
+	case "html":
+		contentType = "text/html; charset=utf-8"
+		title := fmt.Sprintf("kor found %d unused resources", len(resources))
+		data, err = renderHTMLReport(title, reportRows(report), scan.GeneratedAt)
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"kor-report-%s.%s\"", scan.GeneratedAt.Format("20060102T150405Z"), format))
+	_, _ = w.Write(data)
+}
+
+func (s *apiServer) requestResults(w http.ResponseWriter, r *http.Request) (*serverScan, bool) {
+	refresh, _ := strconv.ParseBool(r.URL.Query().Get("refresh"))
+	scan, err := s.results(refresh)
+	if err != nil {
+		slog.Error("Failed to collect unused resources", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return nil, false
+	}
+	return scan, true
+}
+
+func queryValues(r *http.Request, name string) []string {
+	var values []string
+	for _, value := range r.URL.Query()[name] {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+// anyKindMatches matches report kinds against kinds as given on the command
+// line: the kind, its short name or resource name, singular or plural.
+func anyKindMatches(queries []string, kind string) bool {
+	lowerKind := strings.ToLower(kind)
+	scriptKind := lookupScriptKind(kind)
+	for _, query := range queries {
+		query = strings.ToLower(query)
+		if short, ok := shortKindNames[query]; ok {
+			query = short
+		}
+		if query == lowerKind || query == lowerKind+"s" || query == scriptKind.resource || query == strings.ToLower(scriptKind.kind) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(body); err != nil {
+		slog.Error("Failed to write response", "error", err)
+	}
+}
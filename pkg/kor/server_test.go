@@ -0,0 +1,205 @@
+package kor
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testAPIServer(ttl time.Duration) (*apiServer, *int) {
+	scans := 0
+	now := time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC)
+	server := &apiServer{ttl: ttl, now: func() time.Time { return now }}
+	server.scan = func() error {
+		scans++
+		resetReportedResources()
+		recordUnusedResources(emailTestReport(), "namespace")
+		now = now.Add(time.Minute)
+		return nil
+	}
+	return server, &scans
+}
+
+func getAPI(t *testing.T, handler http.Handler, target string, into interface{}) int {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+	if into != nil {
+		if err := json.Unmarshal(recorder.Body.Bytes(), into); err != nil {
+			t.Fatalf("Invalid response %s: %v", recorder.Body, err)
+		}
+	}
+	return recorder.Code
+}
+
+func TestAPIServerUnused(t *testing.T) {
+	defer resetReportedResources()
+	server, scans := testAPIServer(time.Hour)
+	handler := server.handler()
+
+	var response struct {
+		Total     int        `json:"total"`
+		Resources []Resource `json:"resources"`
+	}
+	if code := getAPI(t, handler, "/api/v1/unused", &response); code != http.StatusOK || response.Total != 3 {
+		t.Fatalf("Expected every unused resource, got %d %+v", code, response)
+	}
+	if response.Resources[0].Kind != "Pv" || response.Resources[2].Name != "token" {
+		t.Errorf("Expected the resources sorted by namespace, kind and name, got %+v", response.Resources)
+	}
+
+	tests := map[string]int{
+		"/api/v1/unused?kind=configmap":                 1,
+		"/api/v1/unused?kind=cm,secrets":                2,
+		"/api/v1/unused?kind=persistentvolumes":         1,
+		"/api/v1/unused?namespace=default":              2,
+		"/api/v1/unused?namespace=default&kind=pv":      0,
+		"/api/v1/unused?namespace=other&namespace=apps": 0,
+	}
+	for target, expected := range tests {
+		if getAPI(t, handler, target, &response); response.Total != expected || len(response.Resources) != expected {
+			t.Errorf("Expected %d resources for %s, got %+v", expected, target, response)
+		}
+	}
+	if *scans != 1 {
+		t.Errorf("Expected the cached scan to answer, got %d scans", *scans)
+	}
+	getAPI(t, handler, "/api/v1/unused?refresh=true", &response)
+	if *scans != 2 {
+		t.Errorf("Expected refresh to scan again, got %d scans", *scans)
+	}
+}
+
+func TestAPIServerSummary(t *testing.T) {
+	defer resetReportedResources()
+	server, scans := testAPIServer(0)
+	handler := server.handler()
+
+	var response struct {
+		Total      int               `json:"total"`
+		Duration   float64           `json:"durationSeconds"`
+		Namespaces []namespaceCounts `json:"namespaces"`
+	}
+	getAPI(t, handler, "/api/v1/summary", &response)
+	getAPI(t, handler, "/api/v1/summary", &response)
+	if *scans != 2 {
+		t.Errorf("Expected a scan per request without a cache, got %d", *scans)
+	}
+	if response.Total != 3 || response.Duration != 60 || len(response.Namespaces) != 2 {
+		t.Fatalf("Unexpected summary %+v", response)
+	}
+	if ns := response.Namespaces[1]; ns.Namespace != "default" || ns.Total != 2 || ns.Kinds["Secret"] != 1 || ns.Kinds["ConfigMap"] != 1 {
+		t.Errorf("Unexpected namespace counts %+v", ns)
+	}
+}
+
+func TestAPIServerErrors(t *testing.T) {
+	server := &apiServer{now: time.Now, scan: func() error { return errors.New("forbidden") }}
+	handler := server.handler()
+
+	var response map[string]string
+	if code := getAPI(t, handler, "/api/v1/unused", &response); code != http.StatusInternalServerError || response["error"] != "forbidden" {
+		t.Errorf("Expected the scan error, got %d %v", code, response)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/api/v1/unused", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected the API to be read-only, got %d", recorder.Code)
+	}
+}
+
+func TestAPIServerHistory(t *testing.T) {
+	defer resetReportedResources()
+	server, _ := testAPIServer(0)
+	handler := server.handler()
+
+	var response struct {
+		History []historyPoint `json:"history"`
+	}
+	getAPI(t, handler, "/api/v1/history", &response)
+	if len(response.History) != 0 {
+		t.Errorf("Expected no history before the first scan, got %+v", response.History)
+	}
+	getAPI(t, handler, "/api/v1/unused", nil)
+	getAPI(t, handler, "/api/v1/unused", nil)
+	getAPI(t, handler, "/api/v1/history", &response)
+	if len(response.History) != 2 {
+		t.Fatalf("Expected a point per scan, got %+v", response.History)
+	}
+	if point := response.History[1]; point.Total != 3 || point.Kinds["Pv"] != 1 || !point.Time.After(response.History[0].Time) {
+		t.Errorf("Unexpected history point %+v", point)
+	}
+
+	server.history = make([]historyPoint, serverHistorySize)
+	getAPI(t, handler, "/api/v1/unused", nil)
+	if len(server.history) != serverHistorySize || server.history[serverHistorySize-1].Total != 3 {
+		t.Errorf("Expected the history to keep the last %d scans, got %d", serverHistorySize, len(server.history))
+	}
+}
+
+func TestAPIServerExport(t *testing.T) {
+	defer resetReportedResources()
+	server, _ := testAPIServer(time.Hour)
+	handler := server.handler()
+
+	tests := []struct {
+		target      string
+		contentType string
+		contains    []string
+		excludes    []string
+	}{
+		{"/api/v1/export", "text/csv", []string{"token", "pv-1", "<script>"}, nil},
+		{"/api/v1/export?format=csv&namespace=default", "text/csv", []string{"token"}, []string{"pv-1"}},
+		{"/api/v1/export?format=json&kind=pv", "application/json", []string{`"total": 1`, "pv-1"}, []string{"token"}},
+		{"/api/v1/export?format=html&kind=secret", "text/html", []string{"kor found 1 unused resources", "token"}, []string{"pv-1", "<script>"}},
+	}
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.target, nil))
+		body := recorder.Body.String()
+		if recorder.Code != http.StatusOK || !strings.HasPrefix(recorder.Header().Get("Content-Type"), test.contentType) {
+			t.Errorf("Unexpected response to %s: %d %s", test.target, recorder.Code, recorder.Header().Get("Content-Type"))
+		}
+		if disposition := recorder.Header().Get("Content-Disposition"); !strings.HasPrefix(disposition, `attachment; filename="kor-report-20261014T100100Z.`) {
+			t.Errorf("Unexpected Content-Disposition %q for %s", disposition, test.target)
+		}
+		for _, s := range test.contains {
+			if !strings.Contains(body, s) {
+				t.Errorf("Expected %q in the export of %s, got %s", s, test.target, body)
+			}
+		}
+		for _, s := range test.excludes {
+			if strings.Contains(body, s) {
+				t.Errorf("Expected no %q in the export of %s, got %s", s, test.target, body)
+			}
+		}
+	}
+
+	var response map[string]string
+	if code := getAPI(t, handler, "/api/v1/export?format=xml", &response); code != http.StatusBadRequest || response["error"] == "" {
+		t.Errorf("Expected an invalid format to be rejected, got %d %v", code, response)
+	}
+}
+
+func TestAPIServerDashboard(t *testing.T) {
+	server, scans := testAPIServer(time.Hour)
+	handler := server.handler()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	if recorder.Code != http.StatusOK || !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/html") || !strings.Contains(recorder.Body.String(), "/api/v1/summary") {
+		t.Errorf("Expected the dashboard, got %d %s", recorder.Code, recorder.Header().Get("Content-Type"))
+	}
+	if *scans != 0 {
+		t.Errorf("Expected the page to load without scanning, got %d scans", *scans)
+	}
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected unknown paths to be not found, got %d", recorder.Code)
+	}
+}
@@ -6,21 +6,22 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
-	"os"
+	"log/slog"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/yonahd/kor/pkg/common"
 	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
 )
 
 //go:embed exceptions/serviceaccounts/serviceaccounts.json
 var serviceAccountsConfig []byte
 
-func getServiceAccountsFromClusterRoleBindings(clientset kubernetes.Interface, namespace string) ([]string, error) {
+func getServiceAccountsFromClusterRoleBindings(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]string, error) {
 	// Get a list of all role bindings in the specified namespace
-	roleBindings, err := clientset.RbacV1().ClusterRoleBindings().List(context.TODO(), metav1.ListOptions{})
+	roleBindings, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.RbacV1().ClusterRoleBindings().List)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list role bindings in namespace %s: %v", namespace, err)
 	}
@@ -45,9 +46,9 @@ func getServiceAccountsFromClusterRoleBindings(clientset kubernetes.Interface, n
 	return serviceAccounts, nil
 }
 
-func getServiceAccountsFromRoleBindings(clientset kubernetes.Interface, namespace string) ([]string, error) {
+func getServiceAccountsFromRoleBindings(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]string, error) {
 	// Get a list of all role bindings in the specified namespace
-	roleBindings, err := clientset.RbacV1().RoleBindings(namespace).List(context.TODO(), metav1.ListOptions{})
+	roleBindings, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.RbacV1().RoleBindings(namespace).List)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list role bindings in namespace %s: %v", namespace, err)
 	}
@@ -71,11 +72,11 @@ func getServiceAccountsFromRoleBindings(clientset kubernetes.Interface, namespac
 	return serviceAccounts, nil
 }
 
-func retrieveUsedSA(clientset kubernetes.Interface, namespace string) ([]string, []string, []string, error) {
+func retrieveUsedSA(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]string, []string, []string, error) {
 
 	var podServiceAccounts []string
 
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	pods, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.CoreV1().Pods(namespace).List)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -87,19 +88,19 @@ func retrieveUsedSA(clientset kubernetes.Interface, namespace string) ([]string,
 		}
 	}
 
-	roleServiceAccounts, err := getServiceAccountsFromRoleBindings(clientset, namespace)
+	roleServiceAccounts, err := getServiceAccountsFromRoleBindings(ctx, clientset, namespace)
 	if err != nil {
 		return nil, nil, nil, err
 	}
-	clusterRoleServiceAccounts, err := getServiceAccountsFromClusterRoleBindings(clientset, namespace)
+	clusterRoleServiceAccounts, err := getServiceAccountsFromClusterRoleBindings(ctx, clientset, namespace)
 	if err != nil {
 		return nil, nil, nil, err
 	}
 	return podServiceAccounts, roleServiceAccounts, clusterRoleServiceAccounts, nil
 }
 
-func retrieveServiceAccountNames(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]string, []string, error) {
-	serviceaccounts, err := clientset.CoreV1().ServiceAccounts(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func retrieveServiceAccountNames(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]string, []string, error) {
+	serviceaccounts, err := utils.ListAll(ctx, filterOpts.ListOptions(), clientset.CoreV1().ServiceAccounts(namespace).List)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -121,8 +122,8 @@ func retrieveServiceAccountNames(clientset kubernetes.Interface, namespace strin
 	return names, unusedServiceAccountNames, nil
 }
 
-func processNamespaceSA(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
-	usedServiceAccounts, roleServiceAccounts, clusterRoleServiceAccounts, err := retrieveUsedSA(clientset, namespace)
+func processNamespaceSA(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	usedServiceAccounts, roleServiceAccounts, clusterRoleServiceAccounts, err := retrieveUsedSA(ctx, clientset, namespace)
 	if err != nil {
 		return nil, err
 	}
@@ -131,20 +132,16 @@ func processNamespaceSA(clientset kubernetes.Interface, namespace string, filter
 		return nil, err
 	}
 
-	usedServiceAccounts = RemoveDuplicatesAndSort(usedServiceAccounts)
-	roleServiceAccounts = RemoveDuplicatesAndSort(roleServiceAccounts)
-	clusterRoleServiceAccounts = RemoveDuplicatesAndSort(clusterRoleServiceAccounts)
+	used := newNameSet(usedServiceAccounts, roleServiceAccounts, clusterRoleServiceAccounts)
 
-	usedServiceAccounts = append(append(usedServiceAccounts, roleServiceAccounts...), clusterRoleServiceAccounts...)
-
-	serviceAccountNames, unusedServiceAccountNames, err := retrieveServiceAccountNames(clientset, namespace, filterOpts)
+	serviceAccountNames, unusedServiceAccountNames, err := retrieveServiceAccountNames(ctx, clientset, namespace, filterOpts)
 	if err != nil {
 		return nil, err
 	}
 
 	var unusedServiceAccounts []ResourceInfo
 
-	for _, name := range CalculateResourceDifference(usedServiceAccounts, serviceAccountNames) {
+	for _, name := range used.unused(serviceAccountNames) {
 		exceptionFound, err := isResourceException(name, namespace, config.ExceptionServiceAccounts)
 		if err != nil {
 			return nil, err
@@ -164,17 +161,21 @@ func processNamespaceSA(clientset kubernetes.Interface, namespace string, filter
 	return unusedServiceAccounts, nil
 }
 
-func GetUnusedServiceAccounts(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+func GetUnusedServiceAccounts(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	clientset = scanClientset(clientset)
 	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceSA(clientset, namespace, filterOpts)
+	for _, scan := range scanNamespaces(filterOpts.Namespaces(ctx, clientset), func(namespace string) ([]ResourceInfo, error) {
+		return processNamespaceSA(ctx, clientset, namespace, filterOpts)
+	}) {
+		namespace, diff, err := scan.namespace, scan.result, scan.err
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
+			recordScanError("ServiceAccount", namespace, err)
 			continue
 		}
-		if opts.DeleteFlag {
-			if diff, err = DeleteResource(diff, clientset, namespace, "ServiceAccount", opts.NoInteractive); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to delete Serviceaccount %s in namespace %s: %v\n", diff, namespace, err)
+		exportManifests(ctx, clientset, namespace, "ServiceAccount", diff, opts)
+		if deleteEnabled(opts, "ServiceAccount") {
+			if diff, err = DeleteResource(ctx, diff, clientset, namespace, "ServiceAccount", opts.NoInteractive); err != nil {
+				slog.Error("Failed to delete Serviceaccount", "name", diff, "namespace", namespace, "error", err)
 			}
 		}
 		switch opts.GroupBy {
@@ -186,12 +187,15 @@ func GetUnusedServiceAccounts(filterOpts *filters.Options, clientset kubernetes.
 		}
 	}
 
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
 	var outputBuffer bytes.Buffer
 	var jsonResponse []byte
 	switch outputFormat {
 	case "table":
 		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
+	default:
 		var err error
 		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
 			return "", err
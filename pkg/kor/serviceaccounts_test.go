@@ -64,7 +64,7 @@ func TestGetServiceAccountsFromClusterRoleBindings(t *testing.T) {
 		t.Fatalf("Error creating fake %s: %v", "clusterRoleBinding", err)
 	}
 
-	serviceAccountWithCRB, err := getServiceAccountsFromClusterRoleBindings(clientset, testNamespace)
+	serviceAccountWithCRB, err := getServiceAccountsFromClusterRoleBindings(context.Background(), clientset, testNamespace)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -89,7 +89,7 @@ func TestGetServiceAccountsFromRoleBindings(t *testing.T) {
 		t.Fatalf("Error creating fake %s: %v", "roleBinding", err)
 	}
 
-	serviceAccountWithRB, err := getServiceAccountsFromRoleBindings(clientset, testNamespace)
+	serviceAccountWithRB, err := getServiceAccountsFromRoleBindings(context.Background(), clientset, testNamespace)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -115,7 +115,7 @@ func TestRetrieveUsedSA(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Error creating fake %s: %v", "Pod", err)
 	}
-	serviceAccountUsedByPod, _, _, err := retrieveUsedSA(clientset, testNamespace)
+	serviceAccountUsedByPod, _, _, err := retrieveUsedSA(context.Background(), clientset, testNamespace)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -132,7 +132,7 @@ func TestRetrieveUsedSA(t *testing.T) {
 
 func TestRetrieveServiceAccountNames(t *testing.T) {
 	clientset := createTestServiceAccounts(t)
-	serviceAccountNames, _, err := retrieveServiceAccountNames(clientset, testNamespace, &filters.Options{})
+	serviceAccountNames, _, err := retrieveServiceAccountNames(context.Background(), clientset, testNamespace, &filters.Options{})
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -162,7 +162,7 @@ func TestProcessNamespaceSA(t *testing.T) {
 		t.Fatalf("Error creating fake %s: %v", "Pod", err)
 	}
 
-	unusedServiceAccounts, err := processNamespaceSA(clientset, testNamespace, &filters.Options{})
+	unusedServiceAccounts, err := processNamespaceSA(context.Background(), clientset, testNamespace, &filters.Options{})
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -194,7 +194,7 @@ func TestGetUnusedServiceAccountsStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedServiceAccounts(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedServiceAccounts(context.Background(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedServiceAccountsStructured: %v", err)
 	}
@@ -0,0 +1,148 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+// legacyTokenInvalidSinceLabel is set by the legacy service account token
+// cleaner once a token Secret has been invalidated.
+const legacyTokenInvalidSinceLabel = "kubernetes.io/legacy-token-invalid-since"
+
+func processNamespaceSATokens(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	options := filterOpts.ListOptions()
+	options.FieldSelector = strings.Trim(options.FieldSelector+",type="+string(corev1.SecretTypeServiceAccountToken), ",")
+	secrets, err := utils.ListAll(ctx, options, clientset.CoreV1().Secrets(namespace).List)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceAccounts, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.CoreV1().ServiceAccounts(namespace).List)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceAccountNames := make(map[string]bool, len(serviceAccounts.Items))
+	for _, sa := range serviceAccounts.Items {
+		serviceAccountNames[sa.Name] = true
+	}
+
+	envSecrets, envSecrets2, volumeSecrets, initContainerEnvSecrets, _, _, err := retrieveUsedSecret(ctx, clientset, namespace)
+	if err != nil {
+		return nil, err
+	}
+	usedSecrets := newNameSet(envSecrets, envSecrets2, volumeSecrets, initContainerEnvSecrets)
+
+	config, err := unmarshalConfig(secretsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var unusedTokens []ResourceInfo
+
+	for _, secret := range secrets.Items {
+		// The fake clientset ignores field selectors
+		if secret.Type != corev1.SecretTypeServiceAccountToken {
+			continue
+		}
+
+		if pass, _ := filter.SetObject(&secret).Run(filterOpts); pass {
+			continue
+		}
+
+		if secret.Labels["kor/used"] == "false" {
+			unusedTokens = append(unusedTokens, ResourceInfo{Name: secret.Name, Reason: "Marked with unused label"})
+			continue
+		}
+
+		exceptionFound, err := isResourceException(secret.Name, secret.Namespace, config.ExceptionSecrets)
+		if err != nil {
+			return nil, err
+		}
+
+		if exceptionFound {
+			continue
+		}
+
+		serviceAccountName := secret.Annotations[corev1.ServiceAccountNameKey]
+		if !serviceAccountNames[serviceAccountName] {
+			reason := fmt.Sprintf("ServiceAccount %s referenced by token Secret does not exist", serviceAccountName)
+			unusedTokens = append(unusedTokens, ResourceInfo{Name: secret.Name, Reason: reason})
+			continue
+		}
+
+		if invalidSince, ok := secret.Labels[legacyTokenInvalidSinceLabel]; ok {
+			reason := fmt.Sprintf("Legacy token Secret was invalidated on %s", invalidSince)
+			unusedTokens = append(unusedTokens, ResourceInfo{Name: secret.Name, Reason: reason})
+			continue
+		}
+
+		if !usedSecrets.has(secret.Name) {
+			unusedTokens = append(unusedTokens, ResourceInfo{Name: secret.Name, Reason: "Legacy token Secret is not mounted by any Pod"})
+		}
+	}
+
+	return unusedTokens, nil
+}
+
+func GetUnusedServiceAccountTokens(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	clientset = scanClientset(clientset)
+	resources := make(map[string]map[string][]ResourceInfo)
+	for _, scan := range scanNamespaces(filterOpts.Namespaces(ctx, clientset), func(namespace string) ([]ResourceInfo, error) {
+		return processNamespaceSATokens(ctx, clientset, namespace, filterOpts)
+	}) {
+		namespace, diff, err := scan.namespace, scan.result, scan.err
+		if err != nil {
+			recordScanError("ServiceAccountToken", namespace, err)
+			continue
+		}
+		exportManifests(ctx, clientset, namespace, "ServiceAccountToken", diff, opts)
+		if deleteEnabled(opts, "ServiceAccountToken") {
+			if diff, err = DeleteResource(ctx, diff, clientset, namespace, "ServiceAccountToken", opts.NoInteractive); err != nil {
+				slog.Error("Failed to delete ServiceAccountToken", "name", diff, "namespace", namespace, "error", err)
+			}
+		}
+		switch opts.GroupBy {
+		case "namespace":
+			resources[namespace] = make(map[string][]ResourceInfo)
+			resources[namespace]["ServiceAccountToken"] = diff
+		case "resource":
+			appendResources(resources, "ServiceAccountToken", namespace, diff)
+		}
+	}
+
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+	default:
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	unusedTokens, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return unusedTokens, nil
+}
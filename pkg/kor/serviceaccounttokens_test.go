@@ -0,0 +1,118 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestServiceAccountTokens(t *testing.T) *fake.Clientset {
+	clientset := fake.NewSimpleClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{
+		ObjectMeta: v1.ObjectMeta{Name: testNamespace},
+	}, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+
+	sa1 := CreateTestServiceAccount(testNamespace, "test-sa1", AppLabels)
+	_, err = clientset.CoreV1().ServiceAccounts(testNamespace).Create(context.TODO(), sa1, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "ServiceAccount", err)
+	}
+
+	tokens := []*corev1.Secret{
+		CreateTestServiceAccountToken(testNamespace, "test-sa1-token-mounted", "test-sa1", AppLabels),
+		CreateTestServiceAccountToken(testNamespace, "test-sa1-token-unmounted", "test-sa1", AppLabels),
+		CreateTestServiceAccountToken(testNamespace, "test-sa1-token-invalid", "test-sa1", map[string]string{legacyTokenInvalidSinceLabel: "2024-01-01"}),
+		CreateTestServiceAccountToken(testNamespace, "test-sa2-token", "test-sa2", AppLabels),
+		CreateTestServiceAccountToken(testNamespace, "test-sa1-token-marked", "test-sa1", UnusedLabels),
+		CreateTestSecret(testNamespace, "test-opaque-secret", AppLabels),
+	}
+	for _, token := range tokens {
+		_, err = clientset.CoreV1().Secrets(testNamespace).Create(context.TODO(), token, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake %s: %v", "Secret", err)
+		}
+	}
+
+	volume := corev1.Volume{
+		Name: "token",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: "test-sa1-token-mounted"},
+		},
+	}
+	pod1 := CreateTestPod(testNamespace, "test-pod1", "test-sa1", []corev1.Volume{volume}, AppLabels)
+	_, err = clientset.CoreV1().Pods(testNamespace).Create(context.TODO(), pod1, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "Pod", err)
+	}
+
+	return clientset
+}
+
+func TestProcessNamespaceSATokens(t *testing.T) {
+	clientset := createTestServiceAccountTokens(t)
+
+	unusedTokens, err := processNamespaceSATokens(context.Background(), clientset, testNamespace, &filters.Options{})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	expected := []ResourceInfo{
+		{Name: "test-sa1-token-invalid", Reason: "Legacy token Secret was invalidated on 2024-01-01"},
+		{Name: "test-sa1-token-marked", Reason: "Marked with unused label"},
+		{Name: "test-sa1-token-unmounted", Reason: "Legacy token Secret is not mounted by any Pod"},
+		{Name: "test-sa2-token", Reason: "ServiceAccount test-sa2 referenced by token Secret does not exist"},
+	}
+	if !equalResourceInfoSlices(unusedTokens, expected) {
+		t.Errorf("Expected %v, got %v", expected, unusedTokens)
+	}
+}
+
+func TestGetUnusedServiceAccountTokensStructured(t *testing.T) {
+	clientset := createTestServiceAccountTokens(t)
+
+	opts := common.Opts{
+		WebhookURL:    "",
+		Channel:       "",
+		Token:         "",
+		DeleteFlag:    false,
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+
+	output, err := GetUnusedServiceAccountTokens(context.Background(), &filters.Options{}, clientset, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedServiceAccountTokensStructured: %v", err)
+	}
+
+	expectedOutput := map[string]map[string][]string{
+		testNamespace: {
+			"ServiceAccountToken": {
+				"test-sa1-token-invalid",
+				"test-sa1-token-marked",
+				"test-sa1-token-unmounted",
+				"test-sa2-token",
+			},
+		},
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+
+	if !reflect.DeepEqual(expectedOutput, actualOutput) {
+		t.Errorf("Expected output does not match actual output")
+	}
+}
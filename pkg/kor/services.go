@@ -6,20 +6,20 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
-	"os"
+	"log/slog"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/yonahd/kor/pkg/common"
 	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
 )
 
 //go:embed exceptions/services/services.json
 var servicesConfig []byte
 
-func processNamespaceServices(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
-	endpointsList, err := clientset.CoreV1().Endpoints(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func processNamespaceServices(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	endpointsList, err := utils.ListAll(ctx, filterOpts.ListOptions(), clientset.CoreV1().Endpoints(namespace).List)
 	if err != nil {
 		return nil, err
 	}
@@ -29,6 +29,12 @@ func processNamespaceServices(clientset kubernetes.Interface, namespace string,
 		return nil, err
 	}
 
+	referencedServices, err := retrieveCrossNamespaceReferences(ctx, clientset, namespace, "Service")
+	if err != nil {
+		return nil, err
+	}
+	referenced := newNameSet(referencedServices)
+
 	var endpointsWithoutSubsets []ResourceInfo
 
 	for _, endpoints := range endpointsList.Items {
@@ -41,7 +47,7 @@ func processNamespaceServices(clientset kubernetes.Interface, namespace string,
 			return nil, err
 		}
 
-		if exceptionFound {
+		if exceptionFound || referenced.has(endpoints.Name) {
 			continue
 		}
 
@@ -60,18 +66,22 @@ func processNamespaceServices(clientset kubernetes.Interface, namespace string,
 	return endpointsWithoutSubsets, nil
 }
 
-func GetUnusedServices(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+func GetUnusedServices(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	clientset = scanClientset(clientset)
 	resources := make(map[string]map[string][]ResourceInfo)
 
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceServices(clientset, namespace, filterOpts)
+	for _, scan := range scanNamespaces(filterOpts.Namespaces(ctx, clientset), func(namespace string) ([]ResourceInfo, error) {
+		return processNamespaceServices(ctx, clientset, namespace, filterOpts)
+	}) {
+		namespace, diff, err := scan.namespace, scan.result, scan.err
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
+			recordScanError("Service", namespace, err)
 			continue
 		}
-		if opts.DeleteFlag {
-			if diff, err = DeleteResource(diff, clientset, namespace, "Service", opts.NoInteractive); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to delete Service %s in namespace %s: %v\n", diff, namespace, err)
+		exportManifests(ctx, clientset, namespace, "Service", diff, opts)
+		if deleteEnabled(opts, "Service") {
+			if diff, err = DeleteResource(ctx, diff, clientset, namespace, "Service", opts.NoInteractive); err != nil {
+				slog.Error("Failed to delete Service", "name", diff, "namespace", namespace, "error", err)
 			}
 		}
 		switch opts.GroupBy {
@@ -87,12 +97,15 @@ func GetUnusedServices(filterOpts *filters.Options, clientset kubernetes.Interfa
 		}
 	}
 
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
 	var outputBuffer bytes.Buffer
 	var jsonResponse []byte
 	switch outputFormat {
 	case "table":
 		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
+	default:
 		var err error
 		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
 			return "", err
@@ -58,7 +58,7 @@ func createTestServices(t *testing.T) *fake.Clientset {
 func TestGetEndpointsWithoutSubsets(t *testing.T) {
 	clientset := createTestServices(t)
 
-	servicesWithoutEndpoints, err := processNamespaceServices(clientset, testNamespace, &filters.Options{})
+	servicesWithoutEndpoints, err := processNamespaceServices(context.Background(), clientset, testNamespace, &filters.Options{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -84,7 +84,7 @@ func TestGetUnusedServicesStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedServices(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedServices(context.Background(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedServicesStructured: %v", err)
 	}
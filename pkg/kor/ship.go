@@ -0,0 +1,134 @@
+package kor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+// DefaultElasticsearchIndex is the index, or data stream, findings are shipped to
+const DefaultElasticsearchIndex = "kor-findings"
+
+// findingDocument is a resource reported during this run as shipped to
+// Elasticsearch, or logged to Loki.
+type findingDocument struct {
+	Timestamp time.Time `json:"@timestamp"`
+	Namespace string    `json:"namespace,omitempty"`
+	Kind      string    `json:"kind"`
+	ResourceInfo
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// findingDocuments returns the resources reported during this run sorted by
+// namespace, kind and name.
+func findingDocuments(now time.Time, labels map[string]string) []findingDocument {
+	return sortedDocuments(reportedInfo, now, labels)
+}
+
+// sortedDocuments returns a document per resource sorted by namespace, kind and name.
+func sortedDocuments(resources map[string]map[string][]ResourceInfo, now time.Time, labels map[string]string) []findingDocument {
+	var documents []findingDocument
+	for namespace, kinds := range resources {
+		for kind, infos := range kinds {
+			for _, info := range infos {
+				documents = append(documents, findingDocument{Timestamp: now, Namespace: namespace, Kind: kind, ResourceInfo: info, Labels: labels})
+			}
+		}
+	}
+	sort.Slice(documents, func(i, j int) bool {
+		a, b := documents[i], documents[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	return documents
+}
+
+// parseFindingLabels parses the name=value labels added to the shipped findings.
+func parseFindingLabels(labels []string) (map[string]string, error) {
+	parsed := make(map[string]string, len(labels))
+	for _, label := range labels {
+		name, value, ok := strings.Cut(label, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid label %q, must be name=value", label)
+		}
+		parsed[name] = value
+	}
+	return parsed, nil
+}
+
+// IndexFindings ships every resource reported during this run as a document
+// of the Elasticsearch index, with the name=value labels. $ELASTICSEARCH_API_KEY
+// authenticates when set, otherwise the credentials of the url.
+func IndexFindings(url, index string, labels []string) error {
+	parsed, err := parseFindingLabels(labels)
+	if err != nil {
+		return err
+	}
+	if len(parsed) == 0 {
+		parsed = nil
+	}
+	findings := findingDocuments(time.Now().UTC(), parsed)
+	documents := make([]interface{}, len(findings))
+	for i := range findings {
+		documents[i] = findings[i]
+	}
+	client := &utils.ElasticsearchClient{URL: url, APIKey: os.Getenv("ELASTICSEARCH_API_KEY")}
+	if err := client.Index(index, documents); err != nil {
+		return fmt.Errorf("failed to ship findings to elasticsearch: %w", err)
+	}
+	return nil
+}
+
+// PushFindingsToLoki logs every resource reported during this run as a JSON
+// line to Loki, in a stream per namespace and kind labeled with job="kor" and
+// the name=value labels.
+func PushFindingsToLoki(url, tenant string, labels []string) error {
+	parsed, err := parseFindingLabels(labels)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	var streams []utils.LokiStream
+	var lastStream string
+	for _, finding := range findingDocuments(now.UTC(), nil) {
+		// Lines are searched as written, so < > and & are not escaped
+		var line bytes.Buffer
+		encoder := json.NewEncoder(&line)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(finding); err != nil {
+			return err
+		}
+		if stream := finding.Namespace + "/" + finding.Kind; stream != lastStream {
+			lastStream = stream
+			streamLabels := map[string]string{"job": "kor"}
+			for name, value := range parsed {
+				streamLabels[name] = value
+			}
+			streamLabels["kind"] = finding.Kind
+			if finding.Namespace != "" {
+				streamLabels["namespace"] = finding.Namespace
+			}
+			streams = append(streams, utils.LokiStream{Labels: streamLabels})
+		}
+		streams[len(streams)-1].Lines = append(streams[len(streams)-1].Lines, strings.TrimSuffix(line.String(), "\n"))
+	}
+	if len(streams) == 0 {
+		return nil
+	}
+	client := &utils.LokiClient{URL: url, Tenant: tenant}
+	if err := client.Push(streams, now); err != nil {
+		return fmt.Errorf("failed to push findings to loki: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package kor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestIndexFindings(t *testing.T) {
+	resetReportedResources()
+	defer resetReportedResources()
+	recordUnusedResources(emailTestReport(), "namespace")
+
+	var documents []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		decoder := json.NewDecoder(r.Body)
+		for i := 0; decoder.More(); i++ {
+			var line map[string]interface{}
+			if err := decoder.Decode(&line); err != nil {
+				t.Fatal(err)
+			}
+			if i%2 == 1 {
+				documents = append(documents, line)
+			}
+		}
+		_, _ = w.Write([]byte(`{"errors": false}`))
+	}))
+	defer server.Close()
+
+	if err := IndexFindings(server.URL, DefaultElasticsearchIndex, []string{"cluster=production"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(documents) != 3 {
+		t.Fatalf("Expected a document per finding, got %v", documents)
+	}
+	first, last := documents[0], documents[2]
+	if _, ok := first["namespace"]; ok || first["kind"] != "Pv" || first["name"] != "pv-1" {
+		t.Errorf("Expected the cluster-scoped volume first without a namespace, got %v", first)
+	}
+	if last["namespace"] != "default" || last["kind"] != "Secret" || last["reason"] != `Secret is not used, "anywhere"` || last["@timestamp"] == nil {
+		t.Errorf("Unexpected document %v", last)
+	}
+	if labels, _ := last["labels"].(map[string]interface{}); labels["cluster"] != "production" {
+		t.Errorf("Expected the cluster label, got %v", last["labels"])
+	}
+
+	if err := IndexFindings(server.URL, DefaultElasticsearchIndex, []string{"cluster"}); err == nil {
+		t.Error("Expected the invalid label to be rejected")
+	}
+}
+
+func TestPushFindingsToLoki(t *testing.T) {
+	resetReportedResources()
+	defer resetReportedResources()
+	recordUnusedResources(emailTestReport(), "namespace")
+
+	var payload struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	if err := PushFindingsToLoki(server.URL, "", []string{"cluster=production"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(payload.Streams) != 3 {
+		t.Fatalf("Expected a stream per namespace and kind, got %+v", payload.Streams)
+	}
+	stream := payload.Streams[1]
+	if stream.Stream["job"] != "kor" || stream.Stream["namespace"] != "default" || stream.Stream["kind"] != "ConfigMap" || stream.Stream["cluster"] != "production" {
+		t.Errorf("Unexpected stream labels %v", stream.Stream)
+	}
+	if len(stream.Values) != 1 || !strings.Contains(stream.Values[0][1], `"name":"<script>"`) {
+		t.Errorf("Expected the finding as a JSON line, got %v", stream.Values)
+	}
+	if _, ok := payload.Streams[0].Stream["namespace"]; ok {
+		t.Error("Expected no namespace label for cluster-scoped resources")
+	}
+}
@@ -0,0 +1,75 @@
+package kor
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SortByOptions lists the values accepted by --sort-by.
+var SortByOptions = []string{"age", "name", "size", "namespace"}
+
+// tableEntry is a single table row before it is rendered. column holds the
+// resource type or the namespace, depending on how the report is grouped.
+type tableEntry struct {
+	column string
+	info   ResourceInfo
+}
+
+// ValidateSortBy returns an error for an unknown --sort-by value.
+func ValidateSortBy(sortBy string) error {
+	if sortBy == "" {
+		return nil
+	}
+	for _, option := range SortByOptions {
+		if sortBy == option {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported sort order %q, must be one of %v", sortBy, SortByOptions)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// getTableEntries returns the rows of a table ordered by column and name, or by
+// the --sort-by order when one is set.
+func getTableEntries(resources map[string][]ResourceInfo, sortBy string) []tableEntry {
+	var entries []tableEntry
+	for _, column := range sortedKeys(resources) {
+		for _, info := range resources[column] {
+			entries = append(entries, tableEntry{column: column, info: info})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		switch sortBy {
+		case "name":
+			return a.info.Name < b.info.Name
+		case "age":
+			// Oldest first, resources without a creation time last
+			if a.info.CreationTimestamp == nil || b.info.CreationTimestamp == nil {
+				return a.info.CreationTimestamp != nil && b.info.CreationTimestamp == nil
+			}
+			return a.info.CreationTimestamp.Before(b.info.CreationTimestamp)
+		case "size":
+			// Largest first, resources without a size last
+			if a.info.Size == nil || b.info.Size == nil {
+				return a.info.Size != nil && b.info.Size == nil
+			}
+			return a.info.Size.Cmp(*b.info.Size) > 0
+		default:
+			if a.column != b.column {
+				return a.column < b.column
+			}
+			return a.info.Name < b.info.Name
+		}
+	})
+	return entries
+}
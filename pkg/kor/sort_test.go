@@ -0,0 +1,54 @@
+package kor
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetTableEntries(t *testing.T) {
+	now := time.Now()
+	old := metav1.NewTime(now.Add(-48 * time.Hour))
+	recent := metav1.NewTime(now.Add(-1 * time.Hour))
+	large := resource.MustParse("10Gi")
+	small := resource.MustParse("1Ki")
+
+	resources := map[string][]ResourceInfo{
+		"ns-b": {{Name: "a-recent", CreationTimestamp: &recent, Size: &small}},
+		"ns-a": {
+			{Name: "c-unknown"},
+			{Name: "b-old", CreationTimestamp: &old, Size: &large},
+		},
+	}
+
+	tests := []struct {
+		sortBy   string
+		expected []string
+	}{
+		{sortBy: "", expected: []string{"b-old", "c-unknown", "a-recent"}},
+		{sortBy: "namespace", expected: []string{"b-old", "c-unknown", "a-recent"}},
+		{sortBy: "name", expected: []string{"a-recent", "b-old", "c-unknown"}},
+		{sortBy: "age", expected: []string{"b-old", "a-recent", "c-unknown"}},
+		{sortBy: "size", expected: []string{"b-old", "a-recent", "c-unknown"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.sortBy, func(t *testing.T) {
+			entries := getTableEntries(resources, test.sortBy)
+			if len(entries) != len(test.expected) {
+				t.Fatalf("Expected %d entries, got %d", len(test.expected), len(entries))
+			}
+			for i, entry := range entries {
+				if entry.info.Name != test.expected[i] {
+					t.Errorf("Expected entry %d to be %s, got %s", i, test.expected[i], entry.info.Name)
+				}
+			}
+		})
+	}
+
+	if err := ValidateSortBy("owner"); err == nil {
+		t.Error("Expected an error for an unsupported sort order")
+	}
+}
@@ -6,7 +6,7 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
-	"os"
+	"log/slog"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -14,22 +14,21 @@ import (
 
 	"github.com/yonahd/kor/pkg/common"
 	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
 )
 
 //go:embed exceptions/storageclasses/storageclasses.json
 var storageClassesConfig []byte
 
-func retrieveUsedStorageClasses(clientset kubernetes.Interface) ([]string, error) {
-	pvs, err := clientset.CoreV1().PersistentVolumes().List(context.TODO(), metav1.ListOptions{})
+func retrieveUsedStorageClasses(ctx context.Context, clientset kubernetes.Interface) ([]string, error) {
+	pvs, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.CoreV1().PersistentVolumes().List)
 	if err != nil {
-		fmt.Printf("Failed to list PVs: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to list persistent volumes: %w", err)
 	}
 
-	pvcs, err := clientset.CoreV1().PersistentVolumeClaims("").List(context.TODO(), metav1.ListOptions{})
+	pvcs, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.CoreV1().PersistentVolumeClaims("").List)
 	if err != nil {
-		fmt.Printf("Failed to list PVCs: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to list persistent volume claims: %w", err)
 	}
 
 	var usedStorageClasses []string
@@ -51,8 +50,8 @@ func retrieveUsedStorageClasses(clientset kubernetes.Interface) ([]string, error
 	return usedStorageClasses, err
 }
 
-func processStorageClasses(clientset kubernetes.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
-	scs, err := clientset.StorageV1().StorageClasses().List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func processStorageClasses(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	scs, err := utils.ListAll(ctx, metav1.ListOptions{LabelSelector: filterOpts.LabelSelector()}, clientset.StorageV1().StorageClasses().List)
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +65,7 @@ func processStorageClasses(clientset kubernetes.Interface, filterOpts *filters.O
 	storageClassNames := make([]string, 0, len(scs.Items))
 
 	for _, sc := range scs.Items {
-		if pass := filters.KorLabelFilter(&sc, &filters.Options{}); pass {
+		if pass, _ := filter.SetObject(&sc).Run(filterOpts, filters.LabelFilterName, filters.AgeFilterName); pass {
 			continue
 		}
 
@@ -87,7 +86,7 @@ func processStorageClasses(clientset kubernetes.Interface, filterOpts *filters.O
 		storageClassNames = append(storageClassNames, sc.Name)
 	}
 
-	usedStorageClasses, err := retrieveUsedStorageClasses(clientset)
+	usedStorageClasses, err := retrieveUsedStorageClasses(ctx, clientset)
 	if err != nil {
 		return nil, err
 	}
@@ -99,15 +98,16 @@ func processStorageClasses(clientset kubernetes.Interface, filterOpts *filters.O
 	return unusedStorageClasses, nil
 }
 
-func GetUnusedStorageClasses(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+func GetUnusedStorageClasses(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
 	resources := make(map[string]map[string][]ResourceInfo)
-	diff, err := processStorageClasses(clientset, filterOpts)
+	diff, err := processStorageClasses(ctx, clientset, filterOpts)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to process storageClasses: %v\n", err)
+		recordScanError("StorageClass", "", err)
 	}
-	if opts.DeleteFlag {
-		if diff, err = DeleteResource(diff, clientset, "", "StorageClass", opts.NoInteractive); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete StorageClass %s: %v\n", diff, err)
+	exportManifests(ctx, clientset, "", "StorageClass", diff, opts)
+	if deleteEnabled(opts, "StorageClass") {
+		if diff, err = DeleteResource(ctx, diff, clientset, "", "StorageClass", opts.NoInteractive); err != nil {
+			slog.Error("Failed to delete StorageClass", "name", diff, "error", err)
 		}
 	}
 	switch opts.GroupBy {
@@ -118,12 +118,15 @@ func GetUnusedStorageClasses(filterOpts *filters.Options, clientset kubernetes.I
 		appendResources(resources, "StorageClass", "", diff)
 	}
 
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
 	var outputBuffer bytes.Buffer
 	var jsonResponse []byte
 	switch outputFormat {
 	case "table":
 		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
+	default:
 		var err error
 		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
 			return "", err
@@ -27,7 +27,7 @@ func createTestStorageClass(t *testing.T) *fake.Clientset {
 
 func TestRetrieveUsedStorageClassesFromPVCs(t *testing.T) {
 	clientset := createTestPvcs(t)
-	usedStorageClasses, err := retrieveUsedStorageClasses(clientset)
+	usedStorageClasses, err := retrieveUsedStorageClasses(context.Background(), clientset)
 
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
@@ -40,7 +40,7 @@ func TestRetrieveUsedStorageClassesFromPVCs(t *testing.T) {
 
 func TestRetrieveUsedStorageClassesFromPVs(t *testing.T) {
 	clientset := createTestPvs(t)
-	usedStorageClasses, err := retrieveUsedStorageClasses(clientset)
+	usedStorageClasses, err := retrieveUsedStorageClasses(context.Background(), clientset)
 
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
@@ -53,7 +53,7 @@ func TestRetrieveUsedStorageClassesFromPVs(t *testing.T) {
 
 func TestProcessStorageClasses(t *testing.T) {
 	clientset := createTestStorageClass(t)
-	unusedStorageClasses, err := processStorageClasses(clientset, &filters.Options{})
+	unusedStorageClasses, err := processStorageClasses(context.Background(), clientset, &filters.Options{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -79,7 +79,7 @@ func TestGetUnusedStorageClassesStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedStorageClasses(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedStorageClasses(context.Background(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedStorageClasses: %v", err)
 	}
@@ -0,0 +1,96 @@
+package kor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// ScanResult is the outcome of a detector against a namespace, as sent by
+// Stream.
+type ScanResult struct {
+	// Namespace is empty for cluster-scoped detectors
+	Namespace string
+	Detector  string
+	Resources []Resource
+	// Err is the failure of the detector, Resources holds what it found regardless
+	Err error
+}
+
+// Stream scans with the detectors selected by names like kor <names>, or
+// with every detector like kor all when there are none. The outcome of each
+// detector against each namespace is sent on the returned channel as soon as
+// it completes, with the details of the resources looked up, so that long
+// scans can be rendered or notified about as they go. The channel is closed
+// when the scan is done or ctx is cancelled. filterOpts apply as for the
+// Unused functions.
+func Stream(ctx context.Context, clients Clients, filterOpts *filters.Options, names ...string) (<-chan ScanResult, error) {
+	if filterOpts == nil {
+		filterOpts = filters.NewFilterOptions()
+	}
+	if err := filterOpts.Validate(); err != nil {
+		return nil, err
+	}
+	filterOpts.Modify()
+
+	var namespaced, cluster []Detector
+	detectors := Detectors()
+	if len(names) > 0 {
+		detectors = nil
+		for _, name := range names {
+			detector := lookupDetector(name)
+			if detector == nil {
+				return nil, fmt.Errorf("resource type %q is not supported", name)
+			}
+			detectors = append(detectors, detector)
+		}
+	}
+	for _, detector := range detectors {
+		switch {
+		case !isClusterScoped(detector):
+			namespaced = append(namespaced, detector)
+		// kor all leaves the cluster-scoped resources out of the scans of given namespaces
+		case len(names) > 0 || len(filterOpts.IncludeNamespaces) == 0:
+			cluster = append(cluster, detector)
+		}
+	}
+
+	results := make(chan ScanResult)
+	go func() {
+		defer close(results)
+		send := func(detector Detector, namespace string) bool {
+			diffs, err := runDetector(ctx, detector, clients, namespace, filterOpts)
+			result := ScanResult{Namespace: namespace, Detector: detector.Name(), Err: err}
+			for _, diff := range diffs {
+				reportKind := diff.resourceType
+				if mapped, ok := reportResourceTypes[reportKind]; ok {
+					reportKind = mapped
+				}
+				result.Resources = append(result.Resources, libraryResources(ctx, clients.Kubernetes, namespace, diff.resourceType, reportKind, diff.diff)...)
+			}
+			select {
+			case results <- result:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		for _, detector := range cluster {
+			if ctx.Err() != nil || !send(detector, "") {
+				return
+			}
+		}
+		if len(namespaced) == 0 {
+			return
+		}
+		for _, namespace := range filterOpts.Namespaces(ctx, clients.Kubernetes) {
+			for _, detector := range namespaced {
+				if ctx.Err() != nil || !send(detector, namespace) {
+					return
+				}
+			}
+		}
+	}()
+	return results, nil
+}
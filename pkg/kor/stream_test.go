@@ -0,0 +1,58 @@
+package kor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func TestStream(t *testing.T) {
+	registerTestDetector(t, &testDetector{name: "widget", err: errors.New("forbidden")})
+	clientset := createTestConfigmaps(t)
+
+	results, err := Stream(context.Background(), Clients{Kubernetes: clientset}, nil, "cm", "widget")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var received []ScanResult
+	for result := range results {
+		received = append(received, result)
+	}
+	if len(received) != 2 {
+		t.Fatalf("Expected a result per detector and namespace, got %+v", received)
+	}
+
+	configMaps := received[0]
+	if configMaps.Detector != "configmap" || configMaps.Namespace != testNamespace || configMaps.Err != nil || len(configMaps.Resources) != 2 {
+		t.Fatalf("Expected the unused configmaps, got %+v", configMaps)
+	}
+	if resource := configMaps.Resources[0]; resource.Kind != "ConfigMap" || resource.Name != "configmap-3" || resource.CreationTimestamp == nil {
+		t.Errorf("Expected the details of the configmaps to be looked up, got %+v", resource)
+	}
+	if widgets := received[1]; widgets.Err == nil || len(widgets.Resources) != 1 || widgets.Resources[0].Kind != "Widget" {
+		t.Errorf("Expected the findings of the detector along with its error, got %+v", widgets)
+	}
+}
+
+func TestStreamErrors(t *testing.T) {
+	clientset := createTestConfigmaps(t)
+	if _, err := Stream(context.Background(), Clients{Kubernetes: clientset}, nil, "cm", "gadget"); err == nil {
+		t.Error("Expected an unknown resource to be rejected")
+	}
+	if _, err := Stream(context.Background(), Clients{Kubernetes: clientset}, &filters.Options{OlderThan: "1h", NewerThan: "2h"}); err == nil {
+		t.Error("Expected invalid filters to be rejected")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results, err := Stream(ctx, Clients{Kubernetes: clientset}, nil, "cm", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-results
+	cancel()
+	// The channel is closed rather than blocking the scan
+	for range results {
+	}
+}
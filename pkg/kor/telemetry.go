@@ -0,0 +1,319 @@
+package kor
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+// OpenTelemetry span kinds and status codes, as numbered by OTLP
+const (
+	spanKindInternal = 1
+	spanKindClient   = 3
+	statusCodeError  = 2
+)
+
+const telemetryScope = "github.com/yonahd/kor"
+
+// traceSpan is a finished or running span of a scan.
+type traceSpan struct {
+	tracer     *scanTracer
+	spanID     string
+	parentID   string
+	name       string
+	kind       int
+	start      time.Time
+	end        time.Time
+	attributes map[string]string
+	err        error
+}
+
+// scanTracer records the spans of a scan, exported to an OTLP/HTTP endpoint
+// once the scan is done. Detectors run one after the other, so the innermost
+// running span is the parent of new spans.
+type scanTracer struct {
+	mu       sync.Mutex
+	endpoint string
+	headers  map[string]string
+	service  string
+	traceID  string
+	root     *traceSpan
+	stack    []*traceSpan
+	spans    []*traceSpan
+	// detectorTime sums the time spent in each detector over all namespaces
+	detectorTime map[string]time.Duration
+}
+
+// tracer is nil unless EnableTelemetry was called
+var tracer *scanTracer
+
+// EnableTelemetry traces the scans and exports their spans and metrics to the
+// OTLP/HTTP endpoint, e.g. http://otel-collector:4318. Headers and the service
+// name are read from OTEL_EXPORTER_OTLP_HEADERS and OTEL_SERVICE_NAME.
+func EnableTelemetry(endpoint string) error {
+	if _, err := url.Parse(endpoint); err != nil {
+		return fmt.Errorf("invalid OTLP endpoint %q: %w", endpoint, err)
+	}
+	headers, err := parseOTLPHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"))
+	if err != nil {
+		return err
+	}
+	service := os.Getenv("OTEL_SERVICE_NAME")
+	if service == "" {
+		service = "kor"
+	}
+	tracer = &scanTracer{endpoint: strings.TrimSuffix(endpoint, "/"), headers: headers, service: service}
+	tracer.startTrace()
+	return nil
+}
+
+// parseOTLPHeaders parses the key=value,key=value format of OTEL_EXPORTER_OTLP_HEADERS.
+func parseOTLPHeaders(value string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, header := range strings.Split(value, ",") {
+		if strings.TrimSpace(header) == "" {
+			continue
+		}
+		name, val, ok := strings.Cut(header, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid OTEL_EXPORTER_OTLP_HEADERS entry %q, must be key=value", header)
+		}
+		decoded, err := url.QueryUnescape(strings.TrimSpace(val))
+		if err != nil {
+			return nil, fmt.Errorf("invalid OTEL_EXPORTER_OTLP_HEADERS entry %q: %w", header, err)
+		}
+		headers[strings.TrimSpace(name)] = decoded
+	}
+	return headers, nil
+}
+
+func (t *scanTracer) startTrace() {
+	t.mu.Lock()
+	t.traceID = randomHex(16)
+	t.spans = nil
+	t.stack = nil
+	t.detectorTime = make(map[string]time.Duration)
+	t.mu.Unlock()
+	t.root = t.start("kor scan", spanKindInternal, true, nil)
+}
+
+// startSpan starts a span under the innermost running span. Spans of a nil
+// tracer do nothing, so callers need not check whether tracing is enabled.
+func startSpan(name string, attributes ...string) *traceSpan {
+	return tracer.start(name, spanKindInternal, true, attributes)
+}
+
+// traceDetector times a detector run, it returns the function ending the span.
+func traceDetector(detector, namespace string) func() {
+	span := startSpan("detect "+detector, "kor.detector", detector, "k8s.namespace.name", namespace)
+	return span.finish
+}
+
+func (t *scanTracer) start(name string, kind int, nested bool, attributes []string) *traceSpan {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	span := &traceSpan{tracer: t, spanID: randomHex(8), name: name, kind: kind, start: time.Now(), attributes: make(map[string]string)}
+	for i := 0; i+1 < len(attributes); i += 2 {
+		if attributes[i+1] != "" {
+			span.attributes[attributes[i]] = attributes[i+1]
+		}
+	}
+	if len(t.stack) > 0 {
+		span.parentID = t.stack[len(t.stack)-1].spanID
+	}
+	if nested {
+		t.stack = append(t.stack, span)
+	}
+	return span
+}
+
+// finish ends the span.
+func (s *traceSpan) finish() {
+	s.finishWith(nil)
+}
+
+// finishWith ends the span, recording err as its status when set and adding
+// the key, value attribute pairs.
+func (s *traceSpan) finishWith(err error, attributes ...string) {
+	if s == nil {
+		return
+	}
+	t := s.tracer
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s.end, s.err = time.Now(), err
+	for i := 0; i+1 < len(attributes); i += 2 {
+		s.attributes[attributes[i]] = attributes[i+1]
+	}
+	for i := len(t.stack) - 1; i >= 0; i-- {
+		if t.stack[i] == s {
+			t.stack = append(t.stack[:i], t.stack[i+1:]...)
+			break
+		}
+	}
+	if detector, ok := s.attributes["kor.detector"]; ok {
+		t.detectorTime[detector] += s.end.Sub(s.start)
+	}
+	t.spans = append(t.spans, s)
+}
+
+// traceRequest records an API request as a client span, ended by finishRequest.
+func traceRequest(req *http.Request) *traceSpan {
+	return tracer.start("HTTP "+req.Method, spanKindClient, false, []string{
+		"http.request.method", req.Method,
+		"url.path", req.URL.Path,
+		"server.address", req.URL.Host,
+	})
+}
+
+func (s *traceSpan) finishRequest(resp *http.Response, err error) {
+	if err != nil || resp == nil {
+		s.finishWith(err)
+		return
+	}
+	if resp.StatusCode >= 400 {
+		err = errors.New(resp.Status)
+	}
+	s.finishWith(err, "http.response.status_code", strconv.Itoa(resp.StatusCode))
+}
+
+// FlushTelemetry ends the trace of the scan and exports it with the metrics of
+// the resources reported, then starts a new trace for the next scan.
+func FlushTelemetry() error {
+	if tracer == nil {
+		return nil
+	}
+	tracer.root.finish()
+	traces, metrics := tracer.export(time.Now())
+	tracer.startTrace()
+
+	var errs []error
+	if err := utils.PostJSON(nil, tracer.endpoint+"/v1/traces", traces, tracer.headers); err != nil {
+		errs = append(errs, fmt.Errorf("failed to export traces: %w", err))
+	}
+	if err := utils.PostJSON(nil, tracer.endpoint+"/v1/metrics", metrics, tracer.headers); err != nil {
+		errs = append(errs, fmt.Errorf("failed to export metrics: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// export returns the OTLP/JSON requests of the recorded spans and the metrics.
+func (t *scanTracer) export(now time.Time) (map[string]interface{}, map[string]interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	spans := make([]map[string]interface{}, 0, len(t.spans))
+	for _, s := range t.spans {
+		span := map[string]interface{}{
+			"traceId":           t.traceID,
+			"spanId":            s.spanID,
+			"name":              s.name,
+			"kind":              s.kind,
+			"startTimeUnixNano": unixNano(s.start),
+			"endTimeUnixNano":   unixNano(s.end),
+			"attributes":        otlpAttributes(s.attributes),
+		}
+		if s.parentID != "" {
+			span["parentSpanId"] = s.parentID
+		}
+		if s.err != nil {
+			span["status"] = map[string]interface{}{"code": statusCodeError, "message": s.err.Error()}
+		}
+		spans = append(spans, span)
+	}
+
+	timestamp := unixNano(now)
+	var unused []map[string]interface{}
+	for _, ns := range summarizeFindings(reportedResources).Namespaces {
+		for _, kind := range ns.Kinds {
+			unused = append(unused, map[string]interface{}{
+				"attributes":   otlpAttributes(map[string]string{"kind": kind.Kind, "k8s.namespace.name": ns.Namespace}),
+				"timeUnixNano": timestamp,
+				"asInt":        strconv.Itoa(kind.Count),
+			})
+		}
+	}
+	detectors := make([]string, 0, len(t.detectorTime))
+	for detector := range t.detectorTime {
+		detectors = append(detectors, detector)
+	}
+	sort.Strings(detectors)
+	var detectorTime []map[string]interface{}
+	for _, detector := range detectors {
+		detectorTime = append(detectorTime, map[string]interface{}{
+			"attributes":   otlpAttributes(map[string]string{"kor.detector": detector}),
+			"timeUnixNano": timestamp,
+			"asDouble":     t.detectorTime[detector].Seconds(),
+		})
+	}
+	metrics := []map[string]interface{}{
+		otlpGauge("kor.unused_resources", "{resource}", "Number of unused resources found by the scan", unused),
+		otlpGauge("kor.scan.duration", "s", "Duration of the scan", []map[string]interface{}{{
+			"timeUnixNano": timestamp,
+			"asDouble":     t.root.end.Sub(t.root.start).Seconds(),
+		}}),
+		otlpGauge("kor.detector.duration", "s", "Time spent in each detector over all namespaces", detectorTime),
+	}
+
+	resource := map[string]interface{}{"attributes": otlpAttributes(map[string]string{"service.name": t.service})}
+	scope := map[string]interface{}{"name": telemetryScope}
+	traces := map[string]interface{}{"resourceSpans": []map[string]interface{}{{
+		"resource":   resource,
+		"scopeSpans": []map[string]interface{}{{"scope": scope, "spans": spans}},
+	}}}
+	metricsRequest := map[string]interface{}{"resourceMetrics": []map[string]interface{}{{
+		"resource":     resource,
+		"scopeMetrics": []map[string]interface{}{{"scope": scope, "metrics": metrics}},
+	}}}
+	return traces, metricsRequest
+}
+
+func otlpGauge(name, unit, description string, points []map[string]interface{}) map[string]interface{} {
+	if points == nil {
+		points = []map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"name":        name,
+		"unit":        unit,
+		"description": description,
+		"gauge":       map[string]interface{}{"dataPoints": points},
+	}
+}
+
+func otlpAttributes(attributes map[string]string) []map[string]interface{} {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	converted := make([]map[string]interface{}, 0, len(keys))
+	for _, key := range keys {
+		converted = append(converted, map[string]interface{}{"key": key, "value": map[string]string{"stringValue": attributes[key]}})
+	}
+	return converted
+}
+
+// unixNano formats a time as OTLP/JSON encodes 64-bit integers
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
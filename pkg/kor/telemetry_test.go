@@ -0,0 +1,154 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+type otlpSpan struct {
+	SpanID       string `json:"spanId"`
+	ParentSpanID string `json:"parentSpanId"`
+	Name         string `json:"name"`
+	Kind         int    `json:"kind"`
+	Attributes   []struct {
+		Key   string `json:"key"`
+		Value struct {
+			StringValue string `json:"stringValue"`
+		} `json:"value"`
+	} `json:"attributes"`
+	Status struct {
+		Code int `json:"code"`
+	} `json:"status"`
+}
+
+func (s otlpSpan) attribute(key string) string {
+	for _, attribute := range s.Attributes {
+		if attribute.Key == key {
+			return attribute.Value.StringValue
+		}
+	}
+	return ""
+}
+
+func TestTelemetry(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "authorization=Bearer%20secret")
+	defer func() { tracer = nil }()
+	defer resetReportedResources()
+
+	requests := make(map[string]*http.Request)
+	bodies := make(map[string]map[string]interface{})
+	var traces struct {
+		ResourceSpans []struct {
+			ScopeSpans []struct {
+				Spans []otlpSpan `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path] = r
+		if r.URL.Path == "/v1/traces" {
+			if err := json.NewDecoder(r.Body).Decode(&traces); err != nil {
+				t.Errorf("Expected OTLP/JSON traces, got %v", err)
+			}
+			return
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Expected OTLP/JSON metrics, got %v", err)
+		}
+		bodies[r.URL.Path] = body
+	}))
+	defer server.Close()
+
+	if err := EnableTelemetry(server.URL + "/"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	clientset := fake.NewClientset()
+	if _, err := clientset.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: testNamespace}}, v1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), CreateTestConfigmap(testNamespace, "configmap-1", AppLabels), v1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetUnusedMulti(context.Background(), "cm", &filters.Options{}, clientset, nil, nil, "json", common.Opts{GroupBy: "namespace"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := FlushTelemetry(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, path := range []string{"/v1/traces", "/v1/metrics"} {
+		if requests[path] == nil || requests[path].Header.Get("Authorization") != "Bearer secret" {
+			t.Fatalf("Expected %s to be exported with the OTLP headers", path)
+		}
+	}
+	spans := make(map[string]otlpSpan)
+	for _, span := range traces.ResourceSpans[0].ScopeSpans[0].Spans {
+		spans[span.Name] = span
+	}
+	root, namespace, detector := spans["kor scan"], spans["scan namespace"], spans["detect ConfigMap"]
+	if root.SpanID == "" || root.ParentSpanID != "" {
+		t.Fatalf("Expected a root span, got %v", spans)
+	}
+	if namespace.ParentSpanID != root.SpanID || namespace.attribute("k8s.namespace.name") != testNamespace {
+		t.Errorf("Expected a namespace span under the root, got %+v", namespace)
+	}
+	if detector.ParentSpanID != namespace.SpanID || detector.attribute("kor.detector") != "ConfigMap" {
+		t.Errorf("Expected a detector span under the namespace, got %+v", detector)
+	}
+
+	encoded, _ := json.Marshal(bodies["/v1/metrics"])
+	for _, want := range []string{`"name":"kor.unused_resources"`, `"asInt":"1"`, `"name":"kor.scan.duration"`, `"stringValue":"ConfigMap"`} {
+		if !strings.Contains(string(encoded), want) {
+			t.Errorf("Expected metrics to contain %s, got %s", want, encoded)
+		}
+	}
+	if len(tracer.spans) != 0 {
+		t.Errorf("Expected a new trace after the flush, got %d spans", len(tracer.spans))
+	}
+}
+
+func TestTraceRequest(t *testing.T) {
+	if span := traceRequest(httptest.NewRequest(http.MethodGet, "/api/v1/pods", nil)); span != nil {
+		t.Fatal("Expected no span without telemetry")
+	}
+
+	tracer = &scanTracer{}
+	defer func() { tracer = nil }()
+	tracer.startTrace()
+	span := traceRequest(httptest.NewRequest(http.MethodGet, "https://cluster/api/v1/pods", nil))
+	span.finishRequest(&http.Response{StatusCode: http.StatusForbidden, Status: "403 Forbidden"}, nil)
+	if span.parentID != tracer.root.spanID || span.kind != spanKindClient {
+		t.Errorf("Expected a client span under the root, got %+v", span)
+	}
+	if span.err == nil || span.attributes["http.response.status_code"] != "403" || span.attributes["url.path"] != "/api/v1/pods" {
+		t.Errorf("Expected a failed request span, got %+v", span)
+	}
+	if len(tracer.stack) != 1 {
+		t.Errorf("Expected request spans to not be parents, got %d running spans", len(tracer.stack))
+	}
+}
+
+func TestParseOTLPHeaders(t *testing.T) {
+	headers, err := parseOTLPHeaders("api-key=abc , x-tenant=team%2Fa")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if headers["api-key"] != "abc" || headers["x-tenant"] != "team/a" {
+		t.Errorf("Unexpected headers %v", headers)
+	}
+	if _, err := parseOTLPHeaders("api-key"); err == nil {
+		t.Error("Expected an error without value")
+	}
+}
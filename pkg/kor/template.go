@@ -0,0 +1,55 @@
+package kor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// parseCustomOutput splits an output format such as `jsonpath={.ConfigMap}` into
+// its kind and expression. ok is false for the built-in formats.
+func parseCustomOutput(outputFormat string) (kind, expression string, ok bool) {
+	kind, expression, found := strings.Cut(outputFormat, "=")
+	if !found || (kind != "go-template" && kind != "jsonpath") {
+		return "", "", false
+	}
+	return kind, expression, true
+}
+
+// formatCustomOutput renders the JSON report through a user supplied Go template
+// or JSONPath expression, following kubectl's -o go-template=... and -o jsonpath=...
+func formatCustomOutput(kind, expression string, jsonResponse []byte) (string, error) {
+	var data interface{}
+	if err := json.Unmarshal(jsonResponse, &data); err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	switch kind {
+	case "go-template":
+		tmpl, err := template.New("output").Parse(expression)
+		if err != nil {
+			return "", fmt.Errorf("error parsing go-template: %w", err)
+		}
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return "", fmt.Errorf("error executing go-template: %w", err)
+		}
+	case "jsonpath":
+		// kubectl accepts relaxed expressions such as .ConfigMap without braces
+		if !strings.Contains(expression, "{") {
+			expression = "{" + expression + "}"
+		}
+		parser := jsonpath.New("output").AllowMissingKeys(true)
+		if err := parser.Parse(expression); err != nil {
+			return "", fmt.Errorf("error parsing jsonpath %s: %w", expression, err)
+		}
+		if err := parser.Execute(&buf, data); err != nil {
+			return "", fmt.Errorf("error executing jsonpath %s: %w", expression, err)
+		}
+	}
+	return buf.String(), nil
+}
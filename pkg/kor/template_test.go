@@ -0,0 +1,49 @@
+package kor
+
+import (
+	"testing"
+
+	"github.com/yonahd/kor/pkg/common"
+)
+
+func TestUnusedResourceFormatterCustomOutput(t *testing.T) {
+	resources := map[string]map[string][]ResourceInfo{
+		testNamespace: {
+			"ConfigMap": {{Name: "cm-1"}, {Name: "cm-2"}},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		outputFormat string
+		expected     string
+	}{
+		{
+			name:         "go-template",
+			outputFormat: `go-template={{range $ns, $kinds := .}}{{range $kind, $items := $kinds}}{{range $items}}{{$ns}}/{{.name}} {{end}}{{end}}{{end}}`,
+			expected:     "test-namespace/cm-1 test-namespace/cm-2 ",
+		},
+		{
+			name:         "jsonpath",
+			outputFormat: `jsonpath={.test-namespace.ConfigMap[*].name}`,
+			expected:     "cm-1 cm-2",
+		},
+		{
+			name:         "relaxed jsonpath",
+			outputFormat: `jsonpath=.test-namespace.ConfigMap[0].name`,
+			expected:     "cm-1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output, err := formatUnusedResources(resources, test.outputFormat, common.Opts{GroupBy: "namespace"})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if output != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, output)
+			}
+		})
+	}
+}
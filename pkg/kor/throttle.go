@@ -0,0 +1,138 @@
+package kor
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// DefaultDeleteBatchPause is the pause between two batches of deletions.
+const DefaultDeleteBatchPause = 5 * time.Second
+
+// deleteThrottle paces the deletion requests, so deleting hundreds of
+// resources does not overload the API server or the controllers reacting to
+// the deletions.
+var deleteThrottle struct {
+	limiter    flowcontrol.RateLimiter
+	batchSize  int
+	batchPause time.Duration
+	deleted    int
+}
+
+// SetDeleteRateLimits limits the deletions to qps requests per second and
+// pauses for batchPause after every batchSize deletions. Zero disables the limit.
+func SetDeleteRateLimits(qps float32, batchSize int, batchPause time.Duration) error {
+	if qps < 0 || batchSize < 0 || batchPause < 0 {
+		return errors.New("delete rate limits must not be negative")
+	}
+	deleteThrottle.limiter = nil
+	if qps > 0 {
+		deleteThrottle.limiter = flowcontrol.NewTokenBucketRateLimiter(qps, 1)
+	}
+	deleteThrottle.batchSize = batchSize
+	deleteThrottle.batchPause = batchPause
+	deleteThrottle.deleted = 0
+	return nil
+}
+
+// waitForDelete blocks until the next deletion request may be sent, it
+// returns an error once the scan is cancelled.
+func waitForDelete(ctx context.Context) error {
+	if deleteThrottle.batchSize > 0 && deleteThrottle.deleted > 0 && deleteThrottle.deleted%deleteThrottle.batchSize == 0 {
+		timer := time.NewTimer(deleteThrottle.batchPause)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if deleteThrottle.limiter != nil {
+		if err := deleteThrottle.limiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	deleteThrottle.deleted++
+	return nil
+}
+
+// clientRateLimits are the QPS and burst of the clients, zero keeps the
+// defaults of client-go.
+var clientRateLimits struct {
+	qps   float32
+	burst int
+}
+
+// SetClientRateLimits limits the API requests of the clients created
+// afterwards to qps per second with bursts of burst requests. Zero keeps the
+// defaults of client-go.
+func SetClientRateLimits(qps float32, burst int) error {
+	if qps < 0 || burst < 0 {
+		return errors.New("client rate limits must not be negative")
+	}
+	clientRateLimits.qps, clientRateLimits.burst = qps, burst
+	return nil
+}
+
+// applyClientRateLimits sets the rate limits and the priority and fairness
+// backoff of config.
+func applyClientRateLimits(config *rest.Config) {
+	if clientRateLimits.qps > 0 {
+		config.QPS = clientRateLimits.qps
+	}
+	if clientRateLimits.burst > 0 {
+		config.Burst = clientRateLimits.burst
+	}
+	config.Wrap(func(next http.RoundTripper) http.RoundTripper {
+		return &backoffRoundTripper{next: next}
+	})
+}
+
+// backoffRoundTripper holds every request of a client back once the API
+// server rejects one with 429 Too Many Requests, until its Retry-After delay
+// is over. client-go retries the rejected request by itself, this keeps the
+// requests of the parallel scans from being rejected in turn.
+type backoffRoundTripper struct {
+	next http.RoundTripper
+
+	mu    sync.Mutex
+	until time.Time
+}
+
+func (b *backoffRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	b.mu.Lock()
+	wait := time.Until(b.until)
+	b.mu.Unlock()
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+
+	resp, err := b.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+		return resp, err
+	}
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return resp, nil
+	}
+	slog.Warn("API server is throttling kor, backing off", "retryAfter", seconds, "flowSchema", resp.Header.Get("X-Kubernetes-PF-FlowSchema-UID"))
+	b.mu.Lock()
+	if until := time.Now().Add(time.Duration(seconds) * time.Second); until.After(b.until) {
+		b.until = until
+	}
+	b.mu.Unlock()
+	return resp, nil
+}
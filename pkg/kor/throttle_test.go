@@ -0,0 +1,115 @@
+package kor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestWaitForDelete(t *testing.T) {
+	defer func() { _ = SetDeleteRateLimits(0, 0, 0) }()
+
+	if err := SetDeleteRateLimits(-1, 0, 0); err == nil {
+		t.Error("Expected an error for a negative rate limit")
+	}
+
+	if err := SetDeleteRateLimits(0, 2, 50*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := waitForDelete(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected a pause after the first batch, took %v", elapsed)
+	}
+
+	if err := SetDeleteRateLimits(20, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	start = time.Now()
+	for i := 0; i < 3; i++ {
+		if err := waitForDelete(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Expected 3 deletions at 20 per second to take 100ms, took %v", elapsed)
+	}
+
+	if err := SetDeleteRateLimits(0, 1, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := waitForDelete(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	cancel()
+	if err := waitForDelete(ctx); err == nil {
+		t.Error("Expected the pause to end when the scan is cancelled")
+	}
+}
+
+func TestClientRateLimits(t *testing.T) {
+	defer func() { _ = SetClientRateLimits(0, 0) }()
+
+	if err := SetClientRateLimits(-1, 0); err == nil {
+		t.Error("Expected an error for a negative rate limit")
+	}
+	if err := SetClientRateLimits(50, 100); err != nil {
+		t.Fatal(err)
+	}
+	config := &rest.Config{}
+	applyClientRateLimits(config)
+	if config.QPS != 50 || config.Burst != 100 || config.WrapTransport == nil {
+		t.Errorf("Expected the rate limits and the backoff to be applied, got %+v", config)
+	}
+}
+
+func TestBackoffRoundTripper(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &backoffRoundTripper{next: http.DefaultTransport}}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Expected the rejection to be returned to client-go, got %d", resp.StatusCode)
+	}
+
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if _, err := client.Do(req); err == nil {
+		t.Error("Expected the backoff to end when the request is cancelled")
+	}
+	if resp, err = client.Get(server.URL); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond || resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the next request to wait for Retry-After, took %v", elapsed)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("Expected the cancelled request not to be sent, got %d requests", requests.Load())
+	}
+}
@@ -0,0 +1,226 @@
+package kor
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+const uiHelp = `Commands:
+  list                  list the findings matching the current filters
+  ns <namespace|*>      only list findings in a namespace
+  kind <kind|*>         only list findings of a resource kind
+  show <n>              print the YAML of finding n
+  mark <n>...           mark findings for deletion
+  unmark <n>...         unmark findings
+  delete                delete the marked findings
+  help                  print this help
+  quit                  leave the browser
+`
+
+// resultBrowser is an interactive, line based browser for the findings of a scan.
+type resultBrowser struct {
+	clientset kubernetes.Interface
+	findings  []unusedResource
+	marked    map[int]bool
+	deleted   map[int]bool
+	namespace string
+	kind      string
+	in        *bufio.Scanner
+	out       io.Writer
+}
+
+// RunUI scans the cluster like kor all and lets the user browse the findings
+// by namespace and kind, inspect their YAML and delete a batch of them.
+func RunUI(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, in io.Reader, out io.Writer) error {
+	resources := collectAllResources(ctx, filterOpts, clientset, apiExtClient, dynamicClient, "namespace")
+	StopProgress()
+	return newResultBrowser(clientset, flattenResources(resources, "namespace"), in, out).run(ctx)
+}
+
+func newResultBrowser(clientset kubernetes.Interface, findings []unusedResource, in io.Reader, out io.Writer) *resultBrowser {
+	return &resultBrowser{
+		clientset: clientset,
+		findings:  findings,
+		marked:    make(map[int]bool),
+		deleted:   make(map[int]bool),
+		in:        bufio.NewScanner(in),
+		out:       out,
+	}
+}
+
+func (b *resultBrowser) run(ctx context.Context) error {
+	fmt.Fprintf(b.out, "Found %d unused resources.\n%s", len(b.findings), uiHelp)
+	b.list()
+	for {
+		fmt.Fprint(b.out, "kor> ")
+		if !b.in.Scan() {
+			fmt.Fprintln(b.out)
+			return b.in.Err()
+		}
+		fields := strings.Fields(b.in.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		command, args := fields[0], fields[1:]
+		switch command {
+		case "list", "ls":
+			b.list()
+		case "ns", "namespace":
+			b.namespace = filterArg(args)
+			b.list()
+		case "kind":
+			b.kind = filterArg(args)
+			b.list()
+		case "show":
+			b.show(ctx, args)
+		case "mark":
+			b.setMarked(args, true)
+		case "unmark":
+			b.setMarked(args, false)
+		case "delete":
+			b.deleteMarked(ctx)
+		case "help", "?":
+			fmt.Fprint(b.out, uiHelp)
+		case "quit", "exit", "q":
+			return nil
+		default:
+			fmt.Fprintf(b.out, "Unknown command %q, type help for the list of commands\n", command)
+		}
+	}
+}
+
+func filterArg(args []string) string {
+	if len(args) == 0 || args[0] == "*" {
+		return ""
+	}
+	return args[0]
+}
+
+func (b *resultBrowser) list() {
+	var buf strings.Builder
+	table := tablewriter.NewWriter(&buf)
+	table.SetColWidth(60)
+	table.SetHeader([]string{"#", "MARKED", "RESOURCE TYPE", "NAMESPACE", "RESOURCE NAME", "REASON"})
+	var rows int
+	for i, finding := range b.findings {
+		if b.deleted[i] || (b.namespace != "" && finding.Namespace != b.namespace) || (b.kind != "" && !strings.EqualFold(finding.Kind, b.kind)) {
+			continue
+		}
+		marked := ""
+		if b.marked[i] {
+			marked = "*"
+		}
+		table.Append(getTableRow(i, marked, finding.Kind, finding.Namespace, finding.Name, finding.Reason))
+		rows++
+	}
+	if rows == 0 {
+		fmt.Fprintln(b.out, "No unused resources match the current filters")
+		return
+	}
+	table.Render()
+	fmt.Fprint(b.out, buf.String())
+}
+
+// finding resolves the 1-based number shown in the list.
+func (b *resultBrowser) finding(arg string) (int, bool) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 || n > len(b.findings) || b.deleted[n-1] {
+		fmt.Fprintf(b.out, "Invalid finding %q\n", arg)
+		return 0, false
+	}
+	return n - 1, true
+}
+
+func (b *resultBrowser) show(ctx context.Context, args []string) {
+	if len(args) != 1 {
+		fmt.Fprintln(b.out, "Usage: show <n>")
+		return
+	}
+	i, ok := b.finding(args[0])
+	if !ok {
+		return
+	}
+	finding := b.findings[i]
+	resourceType := finding.Kind
+	if mapped, ok := reportResourceTypes[resourceType]; ok {
+		resourceType = mapped
+	}
+	obj, err := getResource(ctx, b.clientset, finding.Namespace, resourceType, finding.Name)
+	if err != nil {
+		fmt.Fprintf(b.out, "Failed to get %s %s: %v\n", finding.Kind, finding.Name, err)
+		return
+	}
+	manifest, err := marshalManifest(obj)
+	if err != nil {
+		fmt.Fprintf(b.out, "Failed to render %s %s: %v\n", finding.Kind, finding.Name, err)
+		return
+	}
+	fmt.Fprint(b.out, string(manifest))
+}
+
+func (b *resultBrowser) setMarked(args []string, marked bool) {
+	if len(args) == 0 {
+		fmt.Fprintln(b.out, "Usage: mark|unmark <n>...")
+		return
+	}
+	for _, arg := range args {
+		if i, ok := b.finding(arg); ok {
+			b.marked[i] = marked
+		}
+	}
+	fmt.Fprintf(b.out, "%d resources marked for deletion\n", len(b.markedFindings()))
+}
+
+func (b *resultBrowser) markedFindings() []int {
+	var marked []int
+	for i := range b.findings {
+		if b.marked[i] && !b.deleted[i] {
+			marked = append(marked, i)
+		}
+	}
+	return marked
+}
+
+func (b *resultBrowser) deleteMarked(ctx context.Context) {
+	marked := b.markedFindings()
+	if len(marked) == 0 {
+		fmt.Fprintln(b.out, "No resources are marked for deletion")
+		return
+	}
+	fmt.Fprintf(b.out, "Do you want to delete the %d marked resources? (Y/N): ", len(marked))
+	if !b.in.Scan() {
+		return
+	}
+	if confirmation := strings.ToLower(strings.TrimSpace(b.in.Text())); confirmation != "y" && confirmation != "yes" {
+		return
+	}
+
+	for _, i := range marked {
+		finding := b.findings[i]
+		resourceType := finding.Kind
+		if mapped, ok := reportResourceTypes[resourceType]; ok {
+			resourceType = mapped
+		}
+		_, outcome, err := deleteGuarded(ctx, b.out, b.clientset, finding.Namespace, resourceType, ResourceInfo{Name: finding.Name}, nil)
+		if err != nil {
+			fmt.Fprintf(b.out, "Failed to delete %s %s in namespace %s: %v\n", finding.Kind, finding.Name, finding.Namespace, err)
+			return
+		}
+		// The previews of --dry-run leave the resources in the list
+		if outcome == deleteDone && deleteDryRun == "" {
+			b.deleted[i] = true
+			delete(b.marked, i)
+		}
+	}
+}
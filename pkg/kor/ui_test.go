@@ -0,0 +1,86 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestResultBrowser(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	for _, name := range []string{"cm-1", "cm-2"} {
+		if _, err := clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), CreateTestConfigmap(testNamespace, name, AppLabels), metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating fake configmap: %v", err)
+		}
+	}
+
+	findings := []unusedResource{
+		{Namespace: testNamespace, Kind: "ConfigMap", Name: "cm-1"},
+		{Namespace: testNamespace, Kind: "ConfigMap", Name: "cm-2"},
+		{Namespace: testNamespace, Kind: "Secret", Name: "secret-1"},
+	}
+	input := strings.NewReader("kind secret\nkind *\nshow 1\nmark 1\ndelete\ny\nlist\nquit\n")
+	var out bytes.Buffer
+
+	if err := newResultBrowser(clientset, findings, input, &out).run(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	output := out.String()
+	for _, expected := range []string{"Found 3 unused resources", "kind: ConfigMap", "1 resources marked for deletion", "Deleting ConfigMap cm-1 in namespace test-namespace"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+
+	if _, err := clientset.CoreV1().ConfigMaps(testNamespace).Get(context.TODO(), "cm-1", metav1.GetOptions{}); err == nil {
+		t.Error("Expected cm-1 to be deleted")
+	}
+	if _, err := clientset.CoreV1().ConfigMaps(testNamespace).Get(context.TODO(), "cm-2", metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected cm-2 to be kept, got %v", err)
+	}
+	lastList := output[strings.LastIndex(output, "MARKED"):]
+	if strings.Contains(lastList, "cm-1") {
+		t.Errorf("Expected deleted cm-1 to be hidden from the list, got:\n%s", lastList)
+	}
+}
+
+func TestResultBrowserDeleteGuards(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		CreateTestConfigmap("kube-system", "cm-system", AppLabels),
+		CreateTestServiceAccount(testNamespace, "default", AppLabels),
+		CreateTestConfigmap(testNamespace, "cm-1", AppLabels),
+	)
+	findings := []unusedResource{
+		{Namespace: "kube-system", Kind: "ConfigMap", Name: "cm-system"},
+		{Namespace: testNamespace, Kind: "ServiceAccount", Name: "default"},
+		{Namespace: testNamespace, Kind: "ConfigMap", Name: "cm-1"},
+	}
+
+	if err := SetDeleteDryRun(DryRunClient); err != nil {
+		t.Fatal(err)
+	}
+	defer SetDeleteDryRun("")
+	var out bytes.Buffer
+	if err := newResultBrowser(clientset, findings, strings.NewReader("mark 1 2 3\ndelete\ny\nquit\n"), &out).run(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, expected := range []string{
+		"Skipping ConfigMap cm-system in namespace kube-system, it is protected",
+		"Skipping ServiceAccount default in namespace test-namespace, it is protected",
+		"Deleting ConfigMap cm-1 in namespace test-namespace (client dry run)",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, out.String())
+		}
+	}
+	for _, action := range clientset.Actions() {
+		if action.GetVerb() == "delete" {
+			t.Errorf("Expected no delete request, got %v", action)
+		}
+	}
+}
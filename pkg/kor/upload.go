@@ -0,0 +1,72 @@
+package kor
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+const (
+	// UploadFormatJSON uploads the report as the document posted to --webhook-url
+	UploadFormatJSON = "json"
+	// UploadFormatHTML uploads the report as an HTML table
+	UploadFormatHTML = "html"
+)
+
+// UploadFormats lists the values accepted by --upload-formats.
+var UploadFormats = []string{UploadFormatJSON, UploadFormatHTML}
+
+// ValidateUploadFormats checks the --upload-formats values.
+func ValidateUploadFormats(formats []string) error {
+	for _, format := range formats {
+		if format != UploadFormatJSON && format != UploadFormatHTML {
+			return fmt.Errorf("invalid upload format %q, must be one of %v", format, UploadFormats)
+		}
+	}
+	return nil
+}
+
+// UploadReport uploads the report of this run, in each of the formats, to the
+// s3://, gs:// or azblob:// destination. The keys are stamped with the date
+// and time, so scheduled scans keep their history:
+// <prefix>/2006/01/02/kor-20060102T150405Z.json
+func UploadReport(destination string, formats []string) error {
+	store, prefix, err := utils.OpenObjectStore(destination)
+	if err != nil {
+		return err
+	}
+	return uploadReport(store, prefix, formats, time.Now().UTC())
+}
+
+func uploadReport(store utils.ObjectStore, prefix string, formats []string, now time.Time) error {
+	summary := summarizeFindings(reportedResources)
+	for _, format := range formats {
+		var data []byte
+		var contentType string
+		var err error
+		switch format {
+		case UploadFormatJSON:
+			contentType = "application/json"
+			data, err = json.MarshalIndent(resultDocument{GeneratedAt: now, Total: len(reportedResources), Resources: reportedInfo}, "", "  ")
+		case UploadFormatHTML:
+			contentType = "text/html; charset=utf-8"
+			data, err = renderHTMLReport(summary.title(), reportRows(reportedInfo), now)
+		default:
+			err = ValidateUploadFormats([]string{format})
+		}
+		if err != nil {
+			return err
+		}
+		if err := store.Put(reportKey(prefix, format, now), data, contentType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func reportKey(prefix, extension string, now time.Time) string {
+	return path.Join(prefix, now.Format("2006/01/02"), "kor-"+now.Format("20060102T150405Z")+"."+extension)
+}
@@ -0,0 +1,66 @@
+package kor
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeObjectStore struct {
+	objects      map[string]string
+	contentTypes map[string]string
+	err          error
+}
+
+func (s *fakeObjectStore) Put(key string, data []byte, contentType string) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.objects[key] = string(data)
+	s.contentTypes[key] = contentType
+	return nil
+}
+
+func TestUploadReport(t *testing.T) {
+	resetReportedResources()
+	defer resetReportedResources()
+	recordUnusedResources(emailTestReport(), "namespace")
+
+	store := &fakeObjectStore{objects: map[string]string{}, contentTypes: map[string]string{}}
+	now := time.Date(2026, 10, 14, 1, 2, 3, 0, time.UTC)
+	if err := uploadReport(store, "reports/production", UploadFormats, now); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	jsonKey := "reports/production/2026/10/14/kor-20261014T010203Z.json"
+	var document resultDocument
+	if err := json.Unmarshal([]byte(store.objects[jsonKey]), &document); err != nil {
+		t.Fatalf("Expected the JSON report at %s, got %v: %v", jsonKey, store.objects, err)
+	}
+	if document.Total != 3 || len(document.Resources["default"]["Secret"]) != 1 || !document.GeneratedAt.Equal(now) {
+		t.Errorf("Unexpected JSON report %+v", document)
+	}
+	if store.contentTypes[jsonKey] != "application/json" {
+		t.Errorf("Unexpected content type %q", store.contentTypes[jsonKey])
+	}
+
+	htmlKey := "reports/production/2026/10/14/kor-20261014T010203Z.html"
+	if !strings.Contains(store.objects[htmlKey], "<h1>kor found 3 unused resources in 2 namespaces</h1>") {
+		t.Errorf("Expected the HTML report at %s, got %v", htmlKey, store.objects)
+	}
+}
+
+func TestUploadReportErrors(t *testing.T) {
+	if err := ValidateUploadFormats([]string{"json", "csv"}); err == nil {
+		t.Error("Expected csv to be rejected")
+	}
+	if reportKey("", "json", time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)) != "2026/01/02/kor-20260102T030405Z.json" {
+		t.Error("Expected no leading slash without a prefix")
+	}
+	store := &fakeObjectStore{err: errors.New("access denied")}
+	if err := uploadReport(store, "", []string{UploadFormatJSON}, time.Now()); err == nil || err.Error() != "access denied" {
+		t.Errorf("Expected the store error, got %v", err)
+	}
+}
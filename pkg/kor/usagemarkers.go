@@ -0,0 +1,154 @@
+package kor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+const (
+	// usageMarkerLabel and usageMarkerAnnotation mark the ConfigMap or Secret
+	// carrying them as used
+	usageMarkerLabel      = "label"
+	usageMarkerAnnotation = "annotation"
+	// usageMarkerReference annotates workloads with the names of the
+	// ConfigMaps or Secrets they use, split by commas
+	usageMarkerReference = "reference"
+)
+
+// UsageMarker recognizes the ConfigMaps and Secrets used by controllers
+// outside of the pod specs. Kind is ConfigMap or Secret, empty for both. A
+// label or annotation marker matches the ConfigMaps and Secrets with the Key,
+// set to Value unless it is empty. A reference marker reads the names of the
+// ConfigMaps or Secrets from the Key annotation of the Deployments,
+// StatefulSets and DaemonSets.
+type UsageMarker struct {
+	Kind  string
+	Type  string
+	Key   string
+	Value string
+}
+
+// DefaultUsageMarkers are always recognized, --usage-markers adds to them.
+var DefaultUsageMarkers = []UsageMarker{
+	{Type: usageMarkerLabel, Key: "grafana_dashboard"},
+	{Type: usageMarkerLabel, Key: "grafana_datasource"},
+	{Type: usageMarkerLabel, Key: "grafana_alert"},
+	{Type: usageMarkerLabel, Key: "prometheus_rule"},
+	{Kind: "ConfigMap", Type: usageMarkerReference, Key: "configmap.reloader.stakater.com/reload"},
+	{Kind: "Secret", Type: usageMarkerReference, Key: "secret.reloader.stakater.com/reload"},
+}
+
+var usageMarkers = DefaultUsageMarkers
+
+// SetUsageMarkers recognizes the given kind:type:key[=value] markers on top of
+// DefaultUsageMarkers, for example ConfigMap:label:grafana_dashboard=1 or
+// Secret:reference:example.com/secrets. An empty kind matches both.
+func SetUsageMarkers(patterns []string) error {
+	markers := append([]UsageMarker{}, DefaultUsageMarkers...)
+	for _, pattern := range patterns {
+		parts := strings.SplitN(pattern, ":", 3)
+		if len(parts) != 3 || parts[2] == "" {
+			return fmt.Errorf("invalid usage marker %q, must be kind:type:key[=value]", pattern)
+		}
+		marker := UsageMarker{Kind: parts[0], Type: parts[1]}
+		marker.Key, marker.Value, _ = strings.Cut(parts[2], "=")
+		if marker.Kind != "" && marker.Kind != "ConfigMap" && marker.Kind != "Secret" {
+			return fmt.Errorf("invalid usage marker %q, the kind must be ConfigMap, Secret or empty", pattern)
+		}
+		switch marker.Type {
+		case usageMarkerLabel, usageMarkerAnnotation:
+		case usageMarkerReference:
+			if marker.Value != "" {
+				return fmt.Errorf("invalid usage marker %q, a reference has no value", pattern)
+			}
+		default:
+			return fmt.Errorf("invalid usage marker %q, the type must be label, annotation or reference", pattern)
+		}
+		markers = append(markers, marker)
+	}
+	usageMarkers = markers
+	return nil
+}
+
+func (m UsageMarker) appliesTo(kind string) bool {
+	return m.Kind == "" || m.Kind == kind
+}
+
+// hasUsageMarker reports whether a label or annotation marker marks the
+// object of kind as used.
+func hasUsageMarker(kind string, object metav1.Object) bool {
+	for _, marker := range usageMarkers {
+		if !marker.appliesTo(kind) {
+			continue
+		}
+		var values map[string]string
+		switch marker.Type {
+		case usageMarkerLabel:
+			values = object.GetLabels()
+		case usageMarkerAnnotation:
+			values = object.GetAnnotations()
+		default:
+			continue
+		}
+		if value, ok := values[marker.Key]; ok && (marker.Value == "" || value == marker.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// retrieveMarkedReferences returns the ConfigMaps or Secrets of kind the
+// workloads of the namespace reference in the annotations of reference
+// markers.
+func retrieveMarkedReferences(ctx context.Context, clientset kubernetes.Interface, namespace, kind string) ([]string, error) {
+	var keys []string
+	for _, marker := range usageMarkers {
+		if marker.Type == usageMarkerReference && marker.appliesTo(kind) {
+			keys = append(keys, marker.Key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	var workloads []metav1.Object
+	deployments, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.AppsV1().Deployments(namespace).List)
+	if err != nil {
+		return nil, err
+	}
+	for i := range deployments.Items {
+		workloads = append(workloads, &deployments.Items[i])
+	}
+	statefulSets, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.AppsV1().StatefulSets(namespace).List)
+	if err != nil {
+		return nil, err
+	}
+	for i := range statefulSets.Items {
+		workloads = append(workloads, &statefulSets.Items[i])
+	}
+	daemonSets, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.AppsV1().DaemonSets(namespace).List)
+	if err != nil {
+		return nil, err
+	}
+	for i := range daemonSets.Items {
+		workloads = append(workloads, &daemonSets.Items[i])
+	}
+
+	var names []string
+	for _, workload := range workloads {
+		for _, key := range keys {
+			for _, name := range strings.Split(workload.GetAnnotations()[key], ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					names = append(names, name)
+				}
+			}
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,82 @@
+package kor
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func TestUsageMarkers(t *testing.T) {
+	reloaded := CreateTestDeployment(testNamespace, "deployment-1", 1, AppLabels)
+	reloaded.Annotations = map[string]string{
+		"configmap.reloader.stakater.com/reload": "configmap-reloaded, configmap-missing",
+		"secret.reloader.stakater.com/reload":    "secret-reloaded",
+	}
+	clientset := fake.NewSimpleClientset(
+		reloaded,
+		CreateTestConfigmap(testNamespace, "configmap-reloaded", AppLabels),
+		CreateTestConfigmap(testNamespace, "configmap-dashboard", map[string]string{"grafana_dashboard": "1"}),
+		CreateTestConfigmap(testNamespace, "configmap-custom", map[string]string{"example.com/rules": "true"}),
+		CreateTestConfigmap(testNamespace, "configmap-1", AppLabels),
+		CreateTestSecret(testNamespace, "secret-reloaded", AppLabels),
+		CreateTestSecret(testNamespace, "secret-1", AppLabels),
+	)
+
+	defer func() {
+		if err := SetUsageMarkers(nil); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	for _, test := range []struct {
+		markers    []string
+		configmaps []string
+	}{
+		{configmaps: []string{"configmap-1", "configmap-custom"}},
+		{markers: []string{"ConfigMap:label:example.com/rules=true"}, configmaps: []string{"configmap-1"}},
+		{markers: []string{"ConfigMap:label:example.com/rules=false"}, configmaps: []string{"configmap-1", "configmap-custom"}},
+	} {
+		if err := SetUsageMarkers(test.markers); err != nil {
+			t.Fatal(err)
+		}
+		configmaps, err := processNamespaceCM(context.TODO(), clientset, testNamespace, &filters.Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := resourceInfoNames(configmaps)
+		slices.Sort(names)
+		if !slices.Equal(names, test.configmaps) {
+			t.Errorf("Expected unused configmaps %v with the markers %v, got %v", test.configmaps, test.markers, names)
+		}
+	}
+
+	secrets, err := processNamespaceSecret(context.TODO(), clientset, testNamespace, &filters.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := resourceInfoNames(secrets); !slices.Equal(names, []string{"secret-1"}) {
+		t.Errorf("Expected the secret reloaded by the deployment to be used, got %v", names)
+	}
+}
+
+func TestSetUsageMarkers(t *testing.T) {
+	defer func() {
+		if err := SetUsageMarkers(nil); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	for _, pattern := range []string{"grafana_dashboard", "Pod:label:app", "ConfigMap:selector:app", "Secret:reference:example.com/secrets=x", "ConfigMap:label:"} {
+		if err := SetUsageMarkers([]string{pattern}); err == nil {
+			t.Errorf("Expected an error for the usage marker %q", pattern)
+		}
+	}
+	if err := SetUsageMarkers([]string{":annotation:example.com/used=yes"}); err != nil {
+		t.Fatal(err)
+	}
+	if marker := usageMarkers[len(usageMarkers)-1]; marker != (UsageMarker{Type: usageMarkerAnnotation, Key: "example.com/used", Value: "yes"}) {
+		t.Errorf("Expected the annotation marker to be added, got %+v", marker)
+	}
+}
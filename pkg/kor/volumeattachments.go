@@ -0,0 +1,129 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+func retrieveNodeNames(ctx context.Context, clientset kubernetes.Interface) (map[string]bool, error) {
+	nodes, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.CoreV1().Nodes().List)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeNames := make(map[string]bool, len(nodes.Items))
+	for _, node := range nodes.Items {
+		nodeNames[node.Name] = true
+	}
+	return nodeNames, nil
+}
+
+func retrievePvNames(ctx context.Context, clientset kubernetes.Interface) (map[string]bool, error) {
+	pvs, err := utils.ListAll(ctx, metav1.ListOptions{}, clientset.CoreV1().PersistentVolumes().List)
+	if err != nil {
+		return nil, err
+	}
+
+	pvNames := make(map[string]bool, len(pvs.Items))
+	for _, pv := range pvs.Items {
+		pvNames[pv.Name] = true
+	}
+	return pvNames, nil
+}
+
+func processVolumeAttachments(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	volumeAttachments, err := utils.ListAll(ctx, metav1.ListOptions{LabelSelector: filterOpts.LabelSelector()}, clientset.StorageV1().VolumeAttachments().List)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeNames, err := retrieveNodeNames(ctx, clientset)
+	if err != nil {
+		return nil, err
+	}
+
+	pvNames, err := retrievePvNames(ctx, clientset)
+	if err != nil {
+		return nil, err
+	}
+
+	var unusedVolumeAttachments []ResourceInfo
+
+	for _, va := range volumeAttachments.Items {
+		if pass, _ := filter.SetObject(&va).Run(filterOpts, filters.LabelFilterName, filters.AgeFilterName); pass {
+			continue
+		}
+
+		if va.Labels["kor/used"] == "false" {
+			unusedVolumeAttachments = append(unusedVolumeAttachments, ResourceInfo{Name: va.Name, Reason: "Marked with unused label"})
+			continue
+		}
+
+		if !nodeNames[va.Spec.NodeName] {
+			reason := fmt.Sprintf("VolumeAttachment references missing node %s", va.Spec.NodeName)
+			unusedVolumeAttachments = append(unusedVolumeAttachments, ResourceInfo{Name: va.Name, Reason: reason})
+			continue
+		}
+
+		if pvName := va.Spec.Source.PersistentVolumeName; pvName != nil && !pvNames[*pvName] {
+			reason := fmt.Sprintf("VolumeAttachment references missing PV %s", *pvName)
+			unusedVolumeAttachments = append(unusedVolumeAttachments, ResourceInfo{Name: va.Name, Reason: reason})
+		}
+	}
+
+	return unusedVolumeAttachments, nil
+}
+
+func GetUnusedVolumeAttachments(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	resources := make(map[string]map[string][]ResourceInfo)
+	diff, err := processVolumeAttachments(ctx, clientset, filterOpts)
+	if err != nil {
+		recordScanError("VolumeAttachment", "", err)
+	}
+	exportManifests(ctx, clientset, "", "VolumeAttachment", diff, opts)
+	if deleteEnabled(opts, "VolumeAttachment") {
+		if diff, err = DeleteResource(ctx, diff, clientset, "", "VolumeAttachment", opts.NoInteractive); err != nil {
+			slog.Error("Failed to delete VolumeAttachment", "name", diff, "error", err)
+		}
+	}
+	switch opts.GroupBy {
+	case "namespace":
+		resources[""] = make(map[string][]ResourceInfo)
+		resources[""]["VolumeAttachment"] = diff
+	case "resource":
+		appendResources(resources, "VolumeAttachment", "", diff)
+	}
+
+	enrichResources(ctx, clientset, resources, opts)
+	recordUnusedResources(resources, opts.GroupBy)
+
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+	default:
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	unusedVolumeAttachments, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	return unusedVolumeAttachments, nil
+}
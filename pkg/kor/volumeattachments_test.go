@@ -0,0 +1,106 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestVolumeAttachments(t *testing.T) *fake.Clientset {
+	clientset := fake.NewSimpleClientset()
+
+	_, err := clientset.CoreV1().Nodes().Create(context.TODO(), &corev1.Node{
+		ObjectMeta: v1.ObjectMeta{Name: "test-node1"},
+	}, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "Node", err)
+	}
+
+	pv1 := CreateTestPv("test-pv1", "Bound", AppLabels, "")
+	_, err = clientset.CoreV1().PersistentVolumes().Create(context.TODO(), pv1, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake %s: %v", "PV", err)
+	}
+
+	volumeAttachments := []struct {
+		name, node, pv string
+		labels         map[string]string
+	}{
+		{"test-va1", "test-node1", "test-pv1", AppLabels},
+		{"test-va2", "test-node2", "test-pv1", AppLabels},
+		{"test-va3", "test-node1", "test-pv2", AppLabels},
+		{"test-va4", "test-node1", "test-pv1", UnusedLabels},
+	}
+	for _, va := range volumeAttachments {
+		attachment := CreateTestVolumeAttachment(va.name, "csi.kor.com", va.node, va.pv, va.labels)
+		_, err = clientset.StorageV1().VolumeAttachments().Create(context.TODO(), attachment, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake %s: %v", "VolumeAttachment", err)
+		}
+	}
+
+	return clientset
+}
+
+func TestProcessVolumeAttachments(t *testing.T) {
+	clientset := createTestVolumeAttachments(t)
+
+	unusedVolumeAttachments, err := processVolumeAttachments(context.Background(), clientset, &filters.Options{})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	expected := []ResourceInfo{
+		{Name: "test-va2", Reason: "VolumeAttachment references missing node test-node2"},
+		{Name: "test-va3", Reason: "VolumeAttachment references missing PV test-pv2"},
+		{Name: "test-va4", Reason: "Marked with unused label"},
+	}
+	if !equalResourceInfoSlices(unusedVolumeAttachments, expected) {
+		t.Errorf("Expected %v, got %v", expected, unusedVolumeAttachments)
+	}
+}
+
+func TestGetUnusedVolumeAttachmentsStructured(t *testing.T) {
+	clientset := createTestVolumeAttachments(t)
+
+	opts := common.Opts{
+		WebhookURL:    "",
+		Channel:       "",
+		Token:         "",
+		DeleteFlag:    false,
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+
+	output, err := GetUnusedVolumeAttachments(context.Background(), &filters.Options{}, clientset, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedVolumeAttachmentsStructured: %v", err)
+	}
+
+	expectedOutput := map[string]map[string][]string{
+		"": {
+			"VolumeAttachment": {
+				"test-va2",
+				"test-va3",
+				"test-va4",
+			},
+		},
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+
+	if !reflect.DeepEqual(expectedOutput, actualOutput) {
+		t.Errorf("Expected output does not match actual output")
+	}
+}
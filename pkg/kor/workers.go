@@ -0,0 +1,58 @@
+package kor
+
+import "sync"
+
+// workers is the number of namespaces scanned in parallel, see SetWorkers.
+var workers = 1
+
+// SetWorkers sets the number of namespaces the kor commands scan in
+// parallel, one or less scans them one after the other.
+func SetWorkers(n int) {
+	workers = max(n, 1)
+}
+
+// namespaceScan is the outcome of a scan of a namespace.
+type namespaceScan[T any] struct {
+	namespace string
+	result    T
+	err       error
+}
+
+// scanNamespaces runs scan against the namespaces with up to workers of them
+// in parallel, and returns the outcomes in the order of namespaces so the
+// reports do not depend on which scan completes first.
+func scanNamespaces[T any](namespaces []string, scan func(namespace string) (T, error)) []namespaceScan[T] {
+	scans := make([]namespaceScan[T], len(namespaces))
+	parallel := workers > 1 && len(namespaces) > 1
+	run := func(i int) {
+		// Spans of parallel scans cannot nest, their detectors are traced under the root span
+		span := tracer.start("scan namespace", spanKindInternal, !parallel, []string{"k8s.namespace.name", namespaces[i]})
+		result, err := scan(namespaces[i])
+		span.finish()
+		scans[i] = namespaceScan[T]{namespace: namespaces[i], result: result, err: err}
+	}
+	if !parallel {
+		for i := range namespaces {
+			run(i)
+		}
+		return scans
+	}
+
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for range min(workers, len(namespaces)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				run(i)
+			}
+		}()
+	}
+	for i := range namespaces {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return scans
+}
@@ -0,0 +1,92 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func setTestWorkers(t *testing.T, n int) {
+	t.Helper()
+	previous := workers
+	SetWorkers(n)
+	t.Cleanup(func() { workers = previous })
+}
+
+func TestScanNamespaces(t *testing.T) {
+	setTestWorkers(t, 3)
+	var namespaces []string
+	for i := range 20 {
+		namespaces = append(namespaces, fmt.Sprintf("ns-%02d", i))
+	}
+
+	var running, peak atomic.Int32
+	scans := scanNamespaces(namespaces, func(namespace string) (string, error) {
+		current := running.Add(1)
+		defer running.Add(-1)
+		for {
+			highest := peak.Load()
+			if current <= highest || peak.CompareAndSwap(highest, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		return "scanned " + namespace, nil
+	})
+
+	if peak.Load() > 3 {
+		t.Errorf("Expected at most 3 namespaces to be scanned at once, got %d", peak.Load())
+	}
+	for i, scan := range scans {
+		if scan.namespace != namespaces[i] || scan.result != "scanned "+namespaces[i] {
+			t.Fatalf("Expected the scans in the order of the namespaces, got %+v at %d", scan, i)
+		}
+	}
+
+	SetWorkers(0)
+	if workers != 1 {
+		t.Errorf("Expected the namespaces to be scanned one at a time, got %d workers", workers)
+	}
+}
+
+func TestGetUnusedMultiWorkers(t *testing.T) {
+	defer resetReportedResources()
+	setTestWorkers(t, 4)
+	clientset := fake.NewSimpleClientset()
+	var namespaces []string
+	for i := range 10 {
+		namespace := fmt.Sprintf("ns-%d", i)
+		namespaces = append(namespaces, namespace)
+		if _, err := clientset.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := clientset.CoreV1().ConfigMaps(namespace).Create(context.TODO(), CreateTestConfigmap(namespace, "unused", AppLabels), metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	output, err := GetUnusedMulti(context.Background(), "cm,secret", &filters.Options{}, clientset, nil, nil, "json", common.Opts{GroupBy: "namespace"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resources map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &resources); err != nil {
+		t.Fatal(err)
+	}
+	for _, namespace := range namespaces {
+		if !slices.Equal(resources[namespace]["ConfigMap"], []string{"unused"}) {
+			t.Errorf("Expected the unused configmap of %s, got %v", namespace, resources[namespace])
+		}
+	}
+}
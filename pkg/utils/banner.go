@@ -23,7 +23,7 @@ func PrintLogo(outputFormat string) {
 `
 	// processing of the `outputFormat` happens inside of the rootCmd so this requires a pretty large change
 	// to keep the banner. Instead just loop through os args and find if the format was set and handle it there
-	if outputFormat != "yaml" && outputFormat != "json" {
+	if outputFormat == "table" {
 		PrintVersion()
 		boldBlue.Println(asciiLogo)
 	}
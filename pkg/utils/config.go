@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/util/homedir"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultConfigFile is the config file read when --config is not set
+const DefaultConfigFile = ".kor.yaml"
+
+// DefaultConfigPath returns the path of the config file in the home directory
+func DefaultConfigPath() string {
+	return filepath.Join(homedir.HomeDir(), DefaultConfigFile)
+}
+
+// ConfigValue is a config file entry, Name is the long flag name it sets
+type ConfigValue struct {
+	Name  string
+	Value string
+	// Items holds the entries of a list, for flags that take their values one by one
+	Items []string
+}
+
+// LoadConfigFile reads a YAML config file whose keys are long flag names.
+// Lists are joined with commas so they can be set like on the command line.
+// A missing file is not an error unless required is set.
+func LoadConfigFile(path string, required bool) ([]ConfigValue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !required {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var config map[string]interface{}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	values := make([]ConfigValue, 0, len(config))
+	for name, value := range config {
+		switch v := value.(type) {
+		case nil:
+			continue
+		case []interface{}:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			values = append(values, ConfigValue{Name: name, Value: strings.Join(items, ","), Items: items})
+		case map[string]interface{}:
+			return nil, fmt.Errorf("config file %s: %s must be a value or a list", path, name)
+		default:
+			values = append(values, ConfigValue{Name: name, Value: fmt.Sprint(v)})
+		}
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Name < values[j].Name })
+	return values, nil
+}
+
+// ApplyConfigValues sets the flags of cmd listed in the config file at path,
+// flags given on the command line take precedence over it. The keys of flags
+// only other commands define, such as cordoned-for of kor node, are skipped
+// so a single file can hold the defaults of every command, and keys no
+// command defines are rejected.
+func ApplyConfigValues(cmd *cobra.Command, path string, values []ConfigValue) error {
+	for _, value := range values {
+		flag := cmd.Flags().Lookup(value.Name)
+		if flag == nil {
+			if !definesFlag(cmd.Root(), value.Name) {
+				return fmt.Errorf("config file %s: unknown flag %q", path, value.Name)
+			}
+			continue
+		}
+		if flag.Changed {
+			continue
+		}
+		// Array flags keep commas, each list entry is one value
+		if flag.Value.Type() == "stringArray" && value.Items != nil {
+			for _, item := range value.Items {
+				if err := cmd.Flags().Set(value.Name, item); err != nil {
+					return fmt.Errorf("config file %s: %w", path, err)
+				}
+			}
+			continue
+		}
+		if err := cmd.Flags().Set(value.Name, value.Value); err != nil {
+			return fmt.Errorf("config file %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// definesFlag reports whether cmd or any of its subcommands has the flag.
+func definesFlag(cmd *cobra.Command, name string) bool {
+	if cmd.Flags().Lookup(name) != nil || cmd.PersistentFlags().Lookup(name) != nil {
+		return true
+	}
+	for _, sub := range cmd.Commands() {
+		if definesFlag(sub, name) {
+			return true
+		}
+	}
+	return false
+}
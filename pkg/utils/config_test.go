@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestLoadConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".kor.yaml")
+	content := `output: json
+exclude-namespaces:
+  - monitoring
+  - logging
+exit-code-threshold: 5
+show-reason: true
+slack-webhook-url:
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := LoadConfigFile(path, true)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []ConfigValue{
+		{Name: "exclude-namespaces", Value: "monitoring,logging", Items: []string{"monitoring", "logging"}},
+		{Name: "exit-code-threshold", Value: "5"},
+		{Name: "output", Value: "json"},
+		{Name: "show-reason", Value: "true"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Expected %v, got %v", expected, values)
+	}
+}
+
+func TestLoadConfigFileMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".kor.yaml")
+
+	values, err := LoadConfigFile(path, false)
+	if err != nil || values != nil {
+		t.Errorf("Expected a missing optional config file to be ignored, got %v, %v", values, err)
+	}
+	if _, err := LoadConfigFile(path, true); err == nil {
+		t.Errorf("Expected an error for a missing required config file")
+	}
+}
+
+func TestApplyConfigValues(t *testing.T) {
+	var output string
+	var cordonedFor time.Duration
+	var values []ConfigValue
+	var applyErr error
+	root := &cobra.Command{
+		Use: "kor",
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			applyErr = ApplyConfigValues(cmd, ".kor.yaml", values)
+		},
+		Run: func(cmd *cobra.Command, args []string) {},
+	}
+	root.PersistentFlags().StringVar(&output, "output", "table", "")
+	configmap := &cobra.Command{Use: "configmap", Run: func(cmd *cobra.Command, args []string) {}}
+	node := &cobra.Command{Use: "node", Run: func(cmd *cobra.Command, args []string) {}}
+	node.Flags().DurationVar(&cordonedFor, "cordoned-for", 24*time.Hour, "")
+	root.AddCommand(configmap, node)
+
+	run := func(args ...string) error {
+		applyErr = nil
+		root.SetArgs(args)
+		if err := root.Execute(); err != nil {
+			return err
+		}
+		return applyErr
+	}
+
+	values = []ConfigValue{{Name: "cordoned-for", Value: "48h"}, {Name: "output", Value: "json"}}
+	if err := run("configmap"); err != nil {
+		t.Fatalf("Expected the key of another command to be skipped, got %v", err)
+	}
+	if output != "json" || cordonedFor != 24*time.Hour {
+		t.Errorf("Expected only the output of configmap to be set, got %q and %v", output, cordonedFor)
+	}
+	if err := run("node"); err != nil {
+		t.Fatal(err)
+	}
+	if cordonedFor != 48*time.Hour {
+		t.Errorf("Expected the config file to set cordoned-for of node, got %v", cordonedFor)
+	}
+	if err := run("node", "--cordoned-for", "1h"); err != nil || cordonedFor != time.Hour {
+		t.Errorf("Expected the command line to take precedence, got %v, %v", cordonedFor, err)
+	}
+
+	values = []ConfigValue{{Name: "cordon-for", Value: "48h"}}
+	if err := run("configmap"); err == nil {
+		t.Error("Expected an error for a key no command defines")
+	}
+}
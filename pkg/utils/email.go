@@ -0,0 +1,182 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPConfig is the server used to send emails. Port 465 is dialed with TLS,
+// other ports upgrade the connection with STARTTLS when the server offers it.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+// EmailAttachment is a file attached to an email
+type EmailAttachment struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// EmailMessage is a plain text email with attachments
+type EmailMessage struct {
+	From        string
+	To          []string
+	Subject     string
+	Body        string
+	Attachments []EmailAttachment
+}
+
+// Bytes returns the message in MIME format
+func (m EmailMessage) Bytes(now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	headers := []string{
+		"From: " + m.From,
+		"To: " + strings.Join(m.To, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", m.Subject),
+		"Date: " + now.Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/mixed; boundary=" + writer.Boundary(),
+	}
+	buf.WriteString(strings.Join(headers, "\r\n") + "\r\n\r\n")
+
+	body, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := body.Write(wrapBase64([]byte(m.Body))); err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range m.Attachments {
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {attachment.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := part.Write(wrapBase64(attachment.Data)); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// wrapBase64 encodes data in lines of 76 characters, as required by MIME
+func wrapBase64(data []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	var buf bytes.Buffer
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+	return buf.Bytes()
+}
+
+// SendEmail sends the message through the SMTP server, authenticating with
+// PLAIN when a username is set.
+func SendEmail(cfg SMTPConfig, m EmailMessage) error {
+	if cfg.Host == "" {
+		return fmt.Errorf("the SMTP host is not set")
+	}
+	if len(m.To) == 0 {
+		return fmt.Errorf("the email has no recipients")
+	}
+	from, err := addressOf(m.From)
+	if err != nil {
+		return err
+	}
+	recipients := make([]string, 0, len(m.To))
+	for _, to := range m.To {
+		address, err := addressOf(to)
+		if err != nil {
+			return err
+		}
+		recipients = append(recipients, address)
+	}
+	message, err := m.Bytes(time.Now())
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	var conn net.Conn
+	if cfg.Port == 465 {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: time.Minute}, "tcp", addr, &tls.Config{ServerName: cfg.Host})
+	} else {
+		conn, err = net.DialTimeout("tcp", addr, time.Minute)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server %s: %w", addr, err)
+	}
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && cfg.Port != 465 {
+		if err := client.StartTLS(&tls.Config{ServerName: cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, address := range recipients {
+		if err := client.Rcpt(address); err != nil {
+			return err
+		}
+	}
+	data, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := data.Write(message); err != nil {
+		return err
+	}
+	if err := data.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// addressOf returns the address of "Name <address>"
+func addressOf(address string) (string, error) {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return "", fmt.Errorf("invalid email address %q: %w", address, err)
+	}
+	return parsed.Address, nil
+}
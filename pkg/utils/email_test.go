@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testEmail() EmailMessage {
+	return EmailMessage{
+		From:    "kor <kor@example.com>",
+		To:      []string{"ops@example.com", "Dev Team <dev@example.com>"},
+		Subject: "kor found 2 unused resources",
+		Body:    "summary",
+		Attachments: []EmailAttachment{
+			{Name: "kor-report.csv", ContentType: "text/csv; charset=utf-8", Data: []byte("Namespace,Kind\n" + strings.Repeat("default,ConfigMap\n", 10))},
+		},
+	}
+}
+
+func TestEmailMessageBytes(t *testing.T) {
+	data, err := testEmail().Bytes(time.Date(2026, 10, 14, 1, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("Expected a valid message, got %v", err)
+	}
+	if got := msg.Header.Get("To"); got != "ops@example.com, Dev Team <dev@example.com>" {
+		t.Errorf("Unexpected To %q", got)
+	}
+	if got := msg.Header.Get("Subject"); got != "kor found 2 unused resources" {
+		t.Errorf("Unexpected Subject %q", got)
+	}
+
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	var parts []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, part))
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts = append(parts, part.FileName()+":"+string(content))
+	}
+	if len(parts) != 2 || parts[0] != ":summary" || !strings.HasPrefix(parts[1], "kor-report.csv:Namespace,Kind\n") {
+		t.Errorf("Unexpected parts %q", parts)
+	}
+	for _, line := range strings.Split(string(data), "\r\n") {
+		if len(line) > 998 {
+			t.Errorf("Expected lines within the SMTP limit, got %d characters", len(line))
+		}
+	}
+}
+
+// serveSMTP accepts a single session and returns the envelope and data it received.
+func serveSMTP(listener net.Listener) <-chan []string {
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var session []string
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+		reply("220 localhost ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				received <- session
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			switch command := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); command {
+			case "EHLO", "HELO":
+				reply("250 localhost")
+			case "MAIL", "RCPT":
+				session = append(session, line)
+				reply("250 OK")
+			case "DATA":
+				reply("354 Go ahead")
+				var data strings.Builder
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil || dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(dataLine)
+				}
+				session = append(session, data.String())
+				reply("250 Queued")
+			case "QUIT":
+				reply("221 Bye")
+				received <- session
+				return
+			default:
+				reply("502 Unknown command")
+			}
+		}
+	}()
+	return received
+}
+
+func TestSendEmail(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	received := serveSMTP(listener)
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	if err := SendEmail(SMTPConfig{Host: "127.0.0.1", Port: port}, testEmail()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	session := <-received
+	if len(session) != 4 {
+		t.Fatalf("Expected MAIL, two RCPT and DATA, got %q", session)
+	}
+	if session[0] != "MAIL FROM:<kor@example.com>" || session[2] != "RCPT TO:<dev@example.com>" {
+		t.Errorf("Unexpected envelope %q", session[:3])
+	}
+	if !strings.Contains(session[3], "Subject: kor found 2 unused resources") {
+		t.Errorf("Expected the message to be sent, got %q", session[3])
+	}
+}
+
+func TestSendEmailInvalid(t *testing.T) {
+	message := testEmail()
+	message.To = []string{"not an address"}
+	if err := SendEmail(SMTPConfig{Host: "127.0.0.1", Port: 1}, message); err == nil || !strings.Contains(err.Error(), "invalid email address") {
+		t.Errorf("Expected an invalid address error, got %v", err)
+	}
+	if err := SendEmail(SMTPConfig{}, testEmail()); err == nil {
+		t.Error("Expected an error without SMTP host")
+	}
+}
@@ -0,0 +1,199 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Issue is an issue opened in GitHub or Jira.
+type Issue struct {
+	Title string
+	Body  string
+	// Label identifies the issues opened by kor
+	Label string
+	// Assignees are GitHub logins, or a single Jira account ID or user name
+	Assignees []string
+}
+
+// GitHubClient opens issues in GitHub repositories with a token allowed to
+// write issues. BaseURL defaults to $GITHUB_API_URL, set on GitHub Actions and
+// GitHub Enterprise, or https://api.github.com.
+type GitHubClient struct {
+	Token      string
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// FindIssue returns the number of the open issue with the label and title of
+// the repository, or 0 when there is none.
+func (c *GitHubClient) FindIssue(repo, label, title string) (int, error) {
+	for page := 1; ; page++ {
+		query := url.Values{"state": {"open"}, "labels": {label}, "per_page": {"100"}, "page": {strconv.Itoa(page)}}
+		var issues []struct {
+			Number      int             `json:"number"`
+			Title       string          `json:"title"`
+			PullRequest json.RawMessage `json:"pull_request"`
+		}
+		if err := c.do(http.MethodGet, "/repos/"+repo+"/issues?"+query.Encode(), nil, &issues); err != nil {
+			return 0, err
+		}
+		for _, issue := range issues {
+			if issue.Title == title && issue.PullRequest == nil {
+				return issue.Number, nil
+			}
+		}
+		if len(issues) < 100 {
+			return 0, nil
+		}
+	}
+}
+
+// CreateIssue opens the issue in the repository
+func (c *GitHubClient) CreateIssue(repo string, issue Issue) error {
+	payload := map[string]interface{}{"title": issue.Title, "body": issue.Body, "labels": []string{issue.Label}}
+	if len(issue.Assignees) > 0 {
+		payload["assignees"] = issue.Assignees
+	}
+	return c.do(http.MethodPost, "/repos/"+repo+"/issues", payload, nil)
+}
+
+// UpdateIssue replaces the body and assignees of the issue number of the repository
+func (c *GitHubClient) UpdateIssue(repo string, number int, issue Issue) error {
+	payload := map[string]interface{}{"body": issue.Body}
+	if len(issue.Assignees) > 0 {
+		payload["assignees"] = issue.Assignees
+	}
+	return c.do(http.MethodPatch, "/repos/"+repo+"/issues/"+strconv.Itoa(number), payload, nil)
+}
+
+func (c *GitHubClient) do(method, path string, payload, result interface{}) error {
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = os.Getenv("GITHUB_API_URL")
+	}
+	if baseURL == "" {
+		baseURL = "https://api.github.com"
+	}
+	headers := map[string]string{
+		"Accept":               "application/vnd.github+json",
+		"Authorization":        "Bearer " + c.Token,
+		"X-GitHub-Api-Version": "2022-11-28",
+	}
+	return doJSON(c.HTTPClient, method, strings.TrimSuffix(baseURL, "/")+path, headers, payload, result)
+}
+
+// JiraClient opens issues in a Jira project through the REST API v2. With a
+// User, the Token is an API token of Jira Cloud used for basic authentication
+// and assignees are account IDs. Without, it is a personal access token of
+// Jira Data Center and assignees are user names.
+type JiraClient struct {
+	BaseURL    string
+	User       string
+	Token      string
+	Project    string
+	IssueType  string
+	HTTPClient *http.Client
+}
+
+// FindIssue returns the key of the unresolved issue of the project with the
+// label, or an empty key when there is none.
+func (c *JiraClient) FindIssue(label string) (string, error) {
+	jql := fmt.Sprintf("project = %q AND labels = %q AND statusCategory != Done ORDER BY created DESC", c.Project, label)
+	query := url.Values{"jql": {jql}, "fields": {"key"}, "maxResults": {"1"}}
+	var result struct {
+		Issues []struct {
+			Key string `json:"key"`
+		} `json:"issues"`
+	}
+	if err := c.do(http.MethodGet, "/rest/api/2/search?"+query.Encode(), nil, &result); err != nil {
+		return "", err
+	}
+	if len(result.Issues) == 0 {
+		return "", nil
+	}
+	return result.Issues[0].Key, nil
+}
+
+// CreateIssue opens the issue in the project, labeled with "kor" and its label
+func (c *JiraClient) CreateIssue(issue Issue) error {
+	fields := c.fields(issue)
+	fields["project"] = map[string]string{"key": c.Project}
+	fields["summary"] = issue.Title
+	fields["issuetype"] = map[string]string{"name": c.IssueType}
+	fields["labels"] = []string{"kor", issue.Label}
+	return c.do(http.MethodPost, "/rest/api/2/issue", map[string]interface{}{"fields": fields}, nil)
+}
+
+// UpdateIssue replaces the description and assignee of the issue key
+func (c *JiraClient) UpdateIssue(key string, issue Issue) error {
+	return c.do(http.MethodPut, "/rest/api/2/issue/"+url.PathEscape(key), map[string]interface{}{"fields": c.fields(issue)}, nil)
+}
+
+func (c *JiraClient) fields(issue Issue) map[string]interface{} {
+	fields := map[string]interface{}{"description": issue.Body}
+	if len(issue.Assignees) > 0 {
+		if c.User != "" {
+			fields["assignee"] = map[string]string{"accountId": issue.Assignees[0]}
+		} else {
+			fields["assignee"] = map[string]string{"name": issue.Assignees[0]}
+		}
+	}
+	return fields
+}
+
+func (c *JiraClient) do(method, path string, payload, result interface{}) error {
+	headers := map[string]string{"Accept": "application/json", "Authorization": "Bearer " + c.Token}
+	if c.User != "" {
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(c.User+":"+c.Token))
+	}
+	return doJSON(c.HTTPClient, method, strings.TrimSuffix(c.BaseURL, "/")+path, headers, payload, result)
+}
+
+// doJSON sends payload as JSON, when not nil, and decodes the response into
+// result, when not nil.
+func doJSON(client *http.Client, method, url string, headers map[string]string, payload, result interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	if client == nil {
+		client = &http.Client{Timeout: time.Minute}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s returned %s %s", method, req.URL.Path, resp.Status, strings.TrimSpace(string(message)))
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
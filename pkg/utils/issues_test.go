@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGitHubClient(t *testing.T) {
+	var requests []string
+	var created map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			if r.URL.Query().Get("labels") != "kor" || r.URL.Query().Get("state") != "open" {
+				t.Errorf("Unexpected query %s", r.URL.RawQuery)
+			}
+			fmt.Fprint(w, `[{"number": 3, "title": "Unused", "pull_request": {}}, {"number": 7, "title": "Unused"}]`)
+		case http.MethodPost:
+			_ = json.NewDecoder(r.Body).Decode(&created)
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer server.Close()
+
+	client := &GitHubClient{Token: "token", BaseURL: server.URL}
+	number, err := client.FindIssue("acme/platform", "kor", "Unused")
+	if err != nil || number != 7 {
+		t.Fatalf("Expected issue 7, skipping the pull request, got %d %v", number, err)
+	}
+	if number, _ := client.FindIssue("acme/platform", "kor", "Other"); number != 0 {
+		t.Errorf("Expected no issue, got %d", number)
+	}
+	issue := Issue{Title: "Unused", Body: "body", Label: "kor", Assignees: []string{"alice"}}
+	if err := client.CreateIssue("acme/platform", issue); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if created["title"] != "Unused" || fmt.Sprint(created["assignees"]) != "[alice]" || fmt.Sprint(created["labels"]) != "[kor]" {
+		t.Errorf("Unexpected issue %v", created)
+	}
+	if err := client.UpdateIssue("acme/platform", 7, issue); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if last := requests[len(requests)-1]; last != "PATCH /repos/acme/platform/issues/7" {
+		t.Errorf("Expected the issue to be updated, got %s", last)
+	}
+
+	client.Token = "wrong"
+	if _, err := client.FindIssue("acme/platform", "kor", "Unused"); err == nil {
+		t.Error("Expected the authentication error")
+	}
+}
+
+func TestJiraClient(t *testing.T) {
+	var jql, auth string
+	var fields map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		switch r.Method {
+		case http.MethodGet:
+			jql = r.URL.Query().Get("jql")
+			fmt.Fprint(w, `{"issues": [{"key": "OPS-12"}]}`)
+		case http.MethodPost:
+			var payload struct {
+				Fields map[string]interface{} `json:"fields"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+			fields = payload.Fields
+			fmt.Fprint(w, `{"key": "OPS-13"}`)
+		}
+	}))
+	defer server.Close()
+
+	client := &JiraClient{BaseURL: server.URL, User: "kor@example.com", Token: "token", Project: "OPS", IssueType: "Task"}
+	key, err := client.FindIssue("kor-namespace-default")
+	if err != nil || key != "OPS-12" {
+		t.Fatalf("Expected OPS-12, got %q %v", key, err)
+	}
+	if jql != `project = "OPS" AND labels = "kor-namespace-default" AND statusCategory != Done ORDER BY created DESC` {
+		t.Errorf("Unexpected JQL %s", jql)
+	}
+	if auth != "Basic a29yQGV4YW1wbGUuY29tOnRva2Vu" {
+		t.Errorf("Expected basic authentication with the user, got %q", auth)
+	}
+
+	if err := client.CreateIssue(Issue{Title: "Unused", Body: "body", Label: "kor-namespace-default", Assignees: []string{"5b10"}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if fmt.Sprint(fields["labels"]) != "[kor kor-namespace-default]" || fmt.Sprint(fields["assignee"]) != "map[accountId:5b10]" || fmt.Sprint(fields["project"]) != "map[key:OPS]" {
+		t.Errorf("Unexpected fields %v", fields)
+	}
+
+	client.User = ""
+	if err := client.CreateIssue(Issue{Title: "Unused", Assignees: []string{"alice"}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if auth != "Bearer token" || fmt.Sprint(fields["assignee"]) != "map[name:alice]" {
+		t.Errorf("Expected a personal access token and a user name on Data Center, got %q %v", auth, fields["assignee"])
+	}
+}
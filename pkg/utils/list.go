@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ListChunkSize is the number of objects requested per page by ListAll, zero
+// lists everything in a single request.
+var ListChunkSize int64 = 500
+
+// ListAll lists every object with list, requesting them ListChunkSize at a
+// time with Limit and Continue so large collections are not returned in a
+// single response, and merges the pages into the first one. When the
+// continue token expires while listing, the objects are listed again in a
+// single request, like kubectl does.
+func ListAll[L runtime.Object](ctx context.Context, options metav1.ListOptions, list func(context.Context, metav1.ListOptions) (L, error)) (L, error) {
+	if ListChunkSize <= 0 {
+		return list(ctx, options)
+	}
+	full := options
+	options.Limit = ListChunkSize
+
+	first, err := list(ctx, options)
+	if err != nil {
+		return first, err
+	}
+	listMeta, err := meta.ListAccessor(first)
+	if err != nil || listMeta.GetContinue() == "" {
+		return first, err
+	}
+	items, err := meta.ExtractList(first)
+	if err != nil {
+		return first, err
+	}
+
+	for token := listMeta.GetContinue(); token != ""; {
+		options.Continue = token
+		page, err := list(ctx, options)
+		if apierrors.IsResourceExpired(err) {
+			return list(ctx, full)
+		}
+		if err != nil {
+			return page, err
+		}
+		pageItems, err := meta.ExtractList(page)
+		if err != nil {
+			return page, err
+		}
+		items = append(items, pageItems...)
+		pageMeta, err := meta.ListAccessor(page)
+		if err != nil {
+			return page, err
+		}
+		token = pageMeta.GetContinue()
+	}
+
+	if err := meta.SetList(first, items); err != nil {
+		return first, err
+	}
+	listMeta.SetContinue("")
+	listMeta.SetRemainingItemCount(nil)
+	return first, nil
+}
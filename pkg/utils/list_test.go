@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// pagedConfigMaps serves count ConfigMaps a page at a time, the way the API
+// server honors Limit and Continue.
+func pagedConfigMaps(count int, requests *[]metav1.ListOptions) func(context.Context, metav1.ListOptions) (*corev1.ConfigMapList, error) {
+	return func(_ context.Context, options metav1.ListOptions) (*corev1.ConfigMapList, error) {
+		*requests = append(*requests, options)
+		if options.Continue == "expired" {
+			return nil, apierrors.NewResourceExpired("continue token expired")
+		}
+		start, _ := strconv.Atoi(options.Continue)
+		end := count
+		if options.Limit > 0 {
+			end = min(start+int(options.Limit), count)
+		}
+		list := &corev1.ConfigMapList{}
+		for i := start; i < end; i++ {
+			list.Items = append(list.Items, corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("configmap-%d", i)}})
+		}
+		if end < count {
+			list.Continue = strconv.Itoa(end)
+		}
+		return list, nil
+	}
+}
+
+func TestListAll(t *testing.T) {
+	defer func(size int64) { ListChunkSize = size }(ListChunkSize)
+	ListChunkSize = 2
+
+	var requests []metav1.ListOptions
+	list, err := ListAll(context.Background(), metav1.ListOptions{LabelSelector: "app=kor"}, pagedConfigMaps(5, &requests))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 5 || list.Items[4].Name != "configmap-4" || list.Continue != "" {
+		t.Errorf("Expected the pages to be merged, got %+v", list)
+	}
+	if len(requests) != 3 {
+		t.Fatalf("Expected 3 pages to be requested, got %d", len(requests))
+	}
+	for _, request := range requests {
+		if request.Limit != 2 || request.LabelSelector != "app=kor" {
+			t.Errorf("Expected every page to be limited and keep the selector, got %+v", request)
+		}
+	}
+
+	ListChunkSize = 0
+	requests = nil
+	if list, err = ListAll(context.Background(), metav1.ListOptions{}, pagedConfigMaps(5, &requests)); err != nil || len(list.Items) != 5 || requests[0].Limit != 0 {
+		t.Errorf("Expected a single request without a limit, got %v, %+v", err, requests)
+	}
+}
+
+func TestListAllExpiredContinue(t *testing.T) {
+	defer func(size int64) { ListChunkSize = size }(ListChunkSize)
+	ListChunkSize = 2
+
+	var requests []metav1.ListOptions
+	pages := pagedConfigMaps(5, &requests)
+	list, err := ListAll(context.Background(), metav1.ListOptions{}, func(ctx context.Context, options metav1.ListOptions) (*corev1.ConfigMapList, error) {
+		list, err := pages(ctx, options)
+		if err == nil && list.Continue == "4" {
+			list.Continue = "expired"
+		}
+		return list, err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 5 || requests[len(requests)-1].Limit != 0 {
+		t.Errorf("Expected everything to be listed again in a single request, got %d items and %+v", len(list.Items), requests)
+	}
+}
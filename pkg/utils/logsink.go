@@ -0,0 +1,166 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// elasticsearchBulkSize caps the documents indexed per bulk request
+const elasticsearchBulkSize = 1000
+
+// ElasticsearchClient indexes documents with the bulk API. Credentials of the
+// URL are used for basic authentication, an APIKey replaces them.
+type ElasticsearchClient struct {
+	URL        string
+	APIKey     string
+	HTTPClient *http.Client
+}
+
+// Index adds the documents to the index, or data stream, with create actions.
+func (c *ElasticsearchClient) Index(index string, documents []interface{}) error {
+	for start := 0; start < len(documents); start += elasticsearchBulkSize {
+		end := min(start+elasticsearchBulkSize, len(documents))
+		if err := c.bulk(index, documents[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *ElasticsearchClient) bulk(index string, documents []interface{}) error {
+	var body bytes.Buffer
+	action, err := json.Marshal(map[string]interface{}{"create": map[string]string{"_index": index}})
+	if err != nil {
+		return err
+	}
+	for _, document := range documents {
+		source, err := json.Marshal(document)
+		if err != nil {
+			return err
+		}
+		body.Write(action)
+		body.WriteByte('\n')
+		body.Write(source)
+		body.WriteByte('\n')
+	}
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(c.URL, "/")+"/_bulk", &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+c.APIKey)
+	}
+	resp, err := sendLogRequest(c.HTTPClient, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// The bulk API answers 200 even when documents are rejected
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int `json:"status"`
+			Error  struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to parse the bulk response: %w", err)
+	}
+	if !result.Errors {
+		return nil
+	}
+	failed := 0
+	var first string
+	for _, item := range result.Items {
+		for _, outcome := range item {
+			if outcome.Status > 299 {
+				if failed == 0 {
+					first = outcome.Error.Type + ": " + outcome.Error.Reason
+				}
+				failed++
+			}
+		}
+	}
+	return fmt.Errorf("elasticsearch rejected %d of %d documents, %s", failed, len(documents), first)
+}
+
+// LokiStream is a set of log lines sharing the same labels
+type LokiStream struct {
+	Labels map[string]string
+	Lines  []string
+}
+
+// LokiClient pushes log lines to Loki. Credentials of the URL are used for
+// basic authentication, Tenant is sent as X-Scope-OrgID to multi-tenant Loki.
+type LokiClient struct {
+	URL        string
+	Tenant     string
+	HTTPClient *http.Client
+}
+
+// Push sends the streams timestamped with now. The lines of a stream get
+// increasing timestamps, so Loki keeps their order and none is deduplicated.
+func (c *LokiClient) Push(streams []LokiStream, now time.Time) error {
+	type stream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+	payload := struct {
+		Streams []stream `json:"streams"`
+	}{}
+	for _, s := range streams {
+		values := make([][2]string, len(s.Lines))
+		for i, line := range s.Lines {
+			values[i] = [2]string{fmt.Sprint(now.UnixNano() + int64(i)), line}
+		}
+		payload.Streams = append(payload.Streams, stream{Stream: s.Labels, Values: values})
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(c.URL, "/")+"/loki/api/v1/push", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.Tenant != "" {
+		req.Header.Set("X-Scope-OrgID", c.Tenant)
+	}
+	resp, err := sendLogRequest(c.HTTPClient, req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func sendLogRequest(client *http.Client, req *http.Request) (*http.Response, error) {
+	if client == nil {
+		client = &http.Client{Timeout: time.Minute}
+	}
+	target := (&url.URL{Scheme: req.URL.Scheme, Host: req.URL.Host, Path: req.URL.Path}).String()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s failed: %w", req.Method, target, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %s returned %s %s", req.Method, target, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return resp, nil
+}
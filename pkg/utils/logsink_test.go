@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestElasticsearchClientIndex(t *testing.T) {
+	var lines []string
+	var auth, contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, contentType = r.Header.Get("Authorization"), r.Header.Get("Content-Type")
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		fmt.Fprint(w, `{"errors": false, "items": []}`)
+	}))
+	defer server.Close()
+
+	client := &ElasticsearchClient{URL: server.URL + "/", APIKey: "key"}
+	if err := client.Index("kor-findings", []interface{}{map[string]string{"name": "cm-1"}, map[string]string{"name": "cm-2"}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []string{`{"create":{"_index":"kor-findings"}}`, `{"name":"cm-1"}`, `{"create":{"_index":"kor-findings"}}`, `{"name":"cm-2"}`}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected the bulk body:\n%s\nGot:\n%s", strings.Join(expected, "\n"), strings.Join(lines, "\n"))
+	}
+	if auth != "ApiKey key" || contentType != "application/x-ndjson" {
+		t.Errorf("Unexpected headers %q %q", auth, contentType)
+	}
+}
+
+func TestElasticsearchClientIndexErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"errors": true, "items": [
+			{"create": {"status": 201}},
+			{"create": {"status": 400, "error": {"type": "mapper_parsing_exception", "reason": "failed to parse field [size]"}}}
+		]}`)
+	}))
+	defer server.Close()
+
+	client := &ElasticsearchClient{URL: server.URL}
+	err := client.Index("kor-findings", []interface{}{1, 2})
+	if err == nil || !strings.Contains(err.Error(), "rejected 1 of 2 documents, mapper_parsing_exception: failed to parse field [size]") {
+		t.Errorf("Expected the rejected document, got %v", err)
+	}
+}
+
+func TestLokiClientPush(t *testing.T) {
+	var payload struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	var path, tenant, user string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, tenant = r.URL.Path, r.Header.Get("X-Scope-OrgID")
+		user, _, _ = r.BasicAuth()
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := &LokiClient{URL: strings.Replace(server.URL, "http://", "http://kor:secret@", 1), Tenant: "platform"}
+	now := time.Unix(1700000000, 0)
+	streams := []LokiStream{{Labels: map[string]string{"job": "kor"}, Lines: []string{"a", "b"}}}
+	if err := client.Push(streams, now); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if path != "/loki/api/v1/push" || tenant != "platform" || user != "kor" {
+		t.Errorf("Unexpected request %s %q %q", path, tenant, user)
+	}
+	if len(payload.Streams) != 1 || payload.Streams[0].Stream["job"] != "kor" {
+		t.Fatalf("Unexpected streams %+v", payload.Streams)
+	}
+	values := payload.Streams[0].Values
+	if len(values) != 2 || values[0] != [2]string{"1700000000000000000", "a"} || values[1] != [2]string{"1700000000000000001", "b"} {
+		t.Errorf("Expected increasing timestamps, got %v", values)
+	}
+
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "entry out of order", http.StatusBadRequest)
+	})
+	err := client.Push(streams, now)
+	if err == nil || !strings.Contains(err.Error(), "entry out of order") || strings.Contains(err.Error(), "secret") {
+		t.Errorf("Expected the Loki error without the password, got %v", err)
+	}
+}
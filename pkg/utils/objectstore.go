@@ -0,0 +1,215 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// ObjectStore stores files in a bucket or container of an object storage
+type ObjectStore interface {
+	Put(key string, data []byte, contentType string) error
+}
+
+// OpenObjectStore returns the store and the key prefix of an s3://bucket/prefix,
+// gs://bucket/prefix or azblob://container/prefix destination, configured from
+// the environment like the CLI of each cloud.
+func OpenObjectStore(destination string) (ObjectStore, string, error) {
+	scheme, path, ok := strings.Cut(destination, "://")
+	if !ok {
+		return nil, "", fmt.Errorf("invalid destination %q, must be s3://, gs:// or azblob://bucket/prefix", destination)
+	}
+	bucket, prefix, _ := strings.Cut(path, "/")
+	prefix = strings.Trim(prefix, "/")
+	if bucket == "" {
+		return nil, "", fmt.Errorf("invalid destination %q, the bucket is missing", destination)
+	}
+
+	switch scheme {
+	case "s3":
+		client, err := NewS3ClientFromEnv()
+		if err != nil {
+			return nil, "", err
+		}
+		return s3Store{client: client, bucket: bucket}, prefix, nil
+	case "gs":
+		client, err := NewGCSClientFromEnv()
+		if err != nil {
+			return nil, "", err
+		}
+		return gcsStore{client: client, bucket: bucket}, prefix, nil
+	case "azblob":
+		client, err := NewAzureBlobClientFromEnv()
+		if err != nil {
+			return nil, "", err
+		}
+		return azureStore{client: client, container: bucket}, prefix, nil
+	}
+	return nil, "", fmt.Errorf("unsupported destination %q, must be s3://, gs:// or azblob://bucket/prefix", destination)
+}
+
+type s3Store struct {
+	client *S3Client
+	bucket string
+}
+
+func (s s3Store) Put(key string, data []byte, _ string) error {
+	return s.client.PutObject(s.bucket, key, data)
+}
+
+// GCSClient uploads objects to Google Cloud Storage with an OAuth access token.
+// The token is read from GOOGLE_OAUTH_ACCESS_TOKEN, or requested from the
+// metadata server on GCP, e.g. with GKE Workload Identity. STORAGE_EMULATOR_HOST
+// replaces the storage endpoint.
+type GCSClient struct {
+	Token string
+	// Endpoint defaults to https://storage.googleapis.com
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+// gcpMetadataTokenURL returns the access token of the service account of the GCP instance
+var gcpMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// NewGCSClientFromEnv returns a GCSClient configured from the environment
+func NewGCSClientFromEnv() (*GCSClient, error) {
+	client := &GCSClient{Token: os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"), Endpoint: os.Getenv("STORAGE_EMULATOR_HOST")}
+	if client.Endpoint != "" && !strings.Contains(client.Endpoint, "://") {
+		client.Endpoint = "http://" + client.Endpoint
+	}
+	if client.Token == "" && client.Endpoint == "" {
+		token, err := gcpMetadataToken(&http.Client{Timeout: 10 * time.Second})
+		if err != nil {
+			return nil, fmt.Errorf("GOOGLE_OAUTH_ACCESS_TOKEN must be set to use GCS outside of GCP: %w", err)
+		}
+		client.Token = token
+	}
+	return client, nil
+}
+
+func gcpMetadataToken(httpClient *http.Client) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, gcpMetadataTokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("metadata server returned %s", resp.Status)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+// PutObject uploads data to the key of the bucket, replacing the object if it exists
+func (c *GCSClient) PutObject(bucket, key string, data []byte, contentType string) error {
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = "https://storage.googleapis.com"
+	}
+	query := url.Values{"uploadType": {"media"}, "name": {key}}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(endpoint, "/")+"/upload/storage/v1/b/"+url.PathEscape(bucket)+"/o?"+query.Encode(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	return doObjectRequest(c.HTTPClient, req, "gs://"+bucket+"/"+key)
+}
+
+type gcsStore struct {
+	client *GCSClient
+	bucket string
+}
+
+func (s gcsStore) Put(key string, data []byte, contentType string) error {
+	return s.client.PutObject(s.bucket, key, data, contentType)
+}
+
+// AzureBlobClient uploads block blobs to an Azure storage account with a SAS
+// token, read from AZURE_STORAGE_ACCOUNT and AZURE_STORAGE_SAS_TOKEN.
+type AzureBlobClient struct {
+	Account  string
+	SASToken string
+	// Endpoint defaults to https://<account>.blob.core.windows.net
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+// NewAzureBlobClientFromEnv returns an AzureBlobClient configured from the environment
+func NewAzureBlobClientFromEnv() (*AzureBlobClient, error) {
+	client := &AzureBlobClient{
+		Account:  os.Getenv("AZURE_STORAGE_ACCOUNT"),
+		SASToken: strings.TrimPrefix(os.Getenv("AZURE_STORAGE_SAS_TOKEN"), "?"),
+	}
+	if client.Account == "" || client.SASToken == "" {
+		return nil, fmt.Errorf("AZURE_STORAGE_ACCOUNT and AZURE_STORAGE_SAS_TOKEN must be set to use Azure Blob Storage")
+	}
+	return client, nil
+}
+
+// PutBlob uploads data as the block blob name of the container, replacing it if it exists
+func (c *AzureBlobClient) PutBlob(container, name string, data []byte, contentType string) error {
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = "https://" + c.Account + ".blob.core.windows.net"
+	}
+	req, err := http.NewRequest(http.MethodPut, strings.TrimSuffix(endpoint, "/")+"/"+container+"/"+escapePath(name)+"?"+c.SASToken, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	req.Header.Set("x-ms-version", "2021-08-06")
+	req.Header.Set("Content-Type", contentType)
+	return doObjectRequest(c.HTTPClient, req, "azblob://"+container+"/"+name)
+}
+
+type azureStore struct {
+	client    *AzureBlobClient
+	container string
+}
+
+func (s azureStore) Put(key string, data []byte, contentType string) error {
+	return s.client.PutBlob(s.container, key, data, contentType)
+}
+
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func doObjectRequest(httpClient *http.Client, req *http.Request, object string) error {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: time.Minute}
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", object, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to upload %s: %s %s", object, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGCSClientPutObject(t *testing.T) {
+	var path, name, auth, contentType, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, name = r.URL.Path, r.URL.Query().Get("name")
+		auth, contentType = r.Header.Get("Authorization"), r.Header.Get("Content-Type")
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	}))
+	defer server.Close()
+
+	t.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "token")
+	t.Setenv("STORAGE_EMULATOR_HOST", strings.TrimPrefix(server.URL, "http://"))
+	store, prefix, err := OpenObjectStore("gs://reports/kor/")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if prefix != "kor" {
+		t.Errorf("Expected prefix kor, got %q", prefix)
+	}
+	if err := store.Put("kor/2026/10/14/kor.json", []byte("{}"), "application/json"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if path != "/upload/storage/v1/b/reports/o" || name != "kor/2026/10/14/kor.json" {
+		t.Errorf("Unexpected upload %s name=%s", path, name)
+	}
+	if auth != "Bearer token" || contentType != "application/json" || body != "{}" {
+		t.Errorf("Unexpected request %q %q %q", auth, contentType, body)
+	}
+}
+
+func TestAzureBlobClientPutBlob(t *testing.T) {
+	var method, path, query, blobType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path, query, blobType = r.Method, r.URL.EscapedPath(), r.URL.RawQuery, r.Header.Get("x-ms-blob-type")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := &AzureBlobClient{Account: "account", SASToken: "sv=2021&sig=abc", Endpoint: server.URL}
+	if err := client.PutBlob("reports", "kor/report one.html", []byte("<html>"), "text/html"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if method != http.MethodPut || path != "/reports/kor/report%20one.html" || query != "sv=2021&sig=abc" || blobType != "BlockBlob" {
+		t.Errorf("Unexpected request %s %s?%s %s", method, path, query, blobType)
+	}
+
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "AuthenticationFailed", http.StatusForbidden)
+	})
+	if err := client.PutBlob("reports", "kor.html", nil, "text/html"); err == nil || !strings.Contains(err.Error(), "AuthenticationFailed") {
+		t.Errorf("Expected the storage error, got %v", err)
+	}
+}
+
+func TestOpenObjectStoreErrors(t *testing.T) {
+	t.Setenv("AZURE_STORAGE_ACCOUNT", "")
+	for _, destination := range []string{"reports", "ftp://reports", "gs:///kor", "azblob://reports"} {
+		if _, _, err := OpenObjectStore(destination); err == nil {
+			t.Errorf("Expected %q to be rejected", destination)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WriteOutput writes the report to path, creating any missing parent
+// directories. When appendOutput is set the report is added to the end of an
+// existing file instead of replacing it.
+func WriteOutput(path, content string, appendOutput bool) error {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create output directory %s: %w", dir, err)
+		}
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendOutput {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open output file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if _, err := file.WriteString(content); err != nil {
+		return fmt.Errorf("failed to write output file %s: %w", path, err)
+	}
+	return nil
+}
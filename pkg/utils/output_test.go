@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reports", "nested", "kor.txt")
+
+	if err := WriteOutput(path, "first", false); err != nil {
+		t.Fatalf("Error writing output: %v", err)
+	}
+	if err := WriteOutput(path, "second", true); err != nil {
+		t.Fatalf("Error appending output: %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading output: %v", err)
+	}
+	if string(content) != "first\nsecond\n" {
+		t.Errorf("Expected appended content, got %q", content)
+	}
+
+	if err := WriteOutput(path, "third\n", false); err != nil {
+		t.Fatalf("Error overwriting output: %v", err)
+	}
+	content, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading output: %v", err)
+	}
+	if string(content) != "third\n" {
+		t.Errorf("Expected overwritten content, got %q", content)
+	}
+}
@@ -0,0 +1,259 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Location is a bucket and key prefix given as s3://bucket/prefix
+type S3Location struct {
+	Bucket string
+	Prefix string
+}
+
+// IsS3URL checks if the path is an s3://bucket/prefix URL
+func IsS3URL(path string) bool {
+	return strings.HasPrefix(path, "s3://")
+}
+
+// ParseS3URL parses an s3://bucket/prefix URL
+func ParseS3URL(path string) (S3Location, error) {
+	if !IsS3URL(path) {
+		return S3Location{}, fmt.Errorf("invalid S3 URL %q, must start with s3://", path)
+	}
+	bucket, prefix, _ := strings.Cut(strings.TrimPrefix(path, "s3://"), "/")
+	if bucket == "" {
+		return S3Location{}, fmt.Errorf("invalid S3 URL %q, the bucket is missing", path)
+	}
+	return S3Location{Bucket: bucket, Prefix: strings.Trim(prefix, "/")}, nil
+}
+
+// Key returns the object key of name under the prefix
+func (l S3Location) Key(name string) string {
+	if l.Prefix == "" {
+		return name
+	}
+	return l.Prefix + "/" + name
+}
+
+// S3Client is a minimal S3 client signing its requests with AWS Signature Version 4.
+// It is configured like the AWS CLI through the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY,
+// AWS_SESSION_TOKEN, AWS_REGION and AWS_ENDPOINT_URL_S3 (or AWS_ENDPOINT_URL) environment variables.
+type S3Client struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Region          string
+	// Endpoint is used with path-style requests, the regional AWS endpoint is used when empty
+	Endpoint   string
+	HTTPClient *http.Client
+	now        func() time.Time
+}
+
+// NewS3ClientFromEnv returns an S3Client configured from the environment
+func NewS3ClientFromEnv() (*S3Client, error) {
+	client := &S3Client{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		Region:          os.Getenv("AWS_REGION"),
+		Endpoint:        os.Getenv("AWS_ENDPOINT_URL_S3"),
+	}
+	if client.Region == "" {
+		client.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if client.Region == "" {
+		client.Region = "us-east-1"
+	}
+	if client.Endpoint == "" {
+		client.Endpoint = os.Getenv("AWS_ENDPOINT_URL")
+	}
+	if client.AccessKeyID == "" || client.SecretAccessKey == "" {
+		return nil, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set to use S3")
+	}
+	return client, nil
+}
+
+// PutObject uploads data to the key of the bucket, replacing the object if it exists
+func (c *S3Client) PutObject(bucket, key string, data []byte) error {
+	resp, err := c.do(http.MethodPut, bucket, key, nil, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// GetObject downloads the object at the key of the bucket
+func (c *S3Client) GetObject(bucket, key string) ([]byte, error) {
+	resp, err := c.do(http.MethodGet, bucket, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// ListObjects returns the keys of the objects in the bucket starting with prefix
+func (c *S3Client) ListObjects(bucket, prefix string) ([]string, error) {
+	var keys []string
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+	for {
+		resp, err := c.do(http.MethodGet, bucket, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		var result struct {
+			Contents []struct {
+				Key string `xml:"Key"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the objects of bucket %s: %w", bucket, err)
+		}
+		for _, content := range result.Contents {
+			keys = append(keys, content.Key)
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return keys, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+func (c *S3Client) do(method, bucket, key string, query url.Values, body []byte) (*http.Response, error) {
+	host, path := c.Endpoint, "/"+bucket+"/"+key
+	if host == "" {
+		// Virtual-hosted style, AWS no longer offers path-style requests for new buckets
+		host, path = fmt.Sprintf("https://%s.s3.%s.amazonaws.com", bucket, c.Region), "/"+key
+	}
+	endpoint, err := url.Parse(strings.TrimSuffix(host, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint %q: %w", host, err)
+	}
+	endpoint.Path = strings.TrimSuffix(endpoint.Path, "/") + path
+	endpoint.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequest(method, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	c.sign(req, body)
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: time.Minute}
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("S3 %s %s failed: %w", method, endpoint.Redacted(), err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("S3 %s s3://%s/%s failed: %s %s", method, bucket, key, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return resp, nil
+}
+
+// sign adds the AWS Signature Version 4 headers to the request
+func (c *S3Client) sign(req *http.Request, body []byte) {
+	now := time.Now
+	if c.now != nil {
+		now = c.now
+	}
+	t := now().UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+	if c.SessionToken != "" {
+		req.Header.Set("x-amz-security-token", c.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(req.Header.Get(name))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + c.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+c.SecretAccessKey), date)
+	for _, part := range []string{c.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", c.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery encodes the query sorted by key with %20 for spaces, as required by the signature
+func canonicalQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, key := range keys {
+		for _, value := range query[key] {
+			parts = append(parts, awsEscape(key)+"="+awsEscape(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func awsEscape(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -2,22 +2,33 @@ package utils
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/yonahd/kor/pkg/common"
 )
 
+// slackAPIURL is the base URL of the Slack Web API
+var slackAPIURL = "https://slack.com/api"
+
 type SendMessageToSlack interface {
 	SendToSlack(opts common.Opts, outputBuffer string) error
 }
 
+// SlackMessage posts a report to Slack. The webhook receives the Summary, or
+// the report itself when there is no summary. With a channel and token the
+// report is uploaded as a file, commented with the Summary.
 type SlackMessage struct {
+	Summary    string
+	HTTPClient *http.Client
 }
 
 func SendToSlack(sm SendMessageToSlack, opts common.Opts, outputBuffer string) error {
@@ -25,63 +36,108 @@ func SendToSlack(sm SendMessageToSlack, opts common.Opts, outputBuffer string) e
 }
 
 func (sm SlackMessage) SendToSlack(opts common.Opts, outputBuffer string) error {
-	if opts.WebhookURL != "" {
-		payload := []byte(`{"text": "` + outputBuffer + `"}`)
-		_, err := http.Post(opts.WebhookURL, "application/json", bytes.NewBuffer(payload))
+	text := sm.Summary
+	if text == "" {
+		text = outputBuffer
+	}
 
-		if err != nil {
-			return err
-		}
-		return nil
+	if opts.WebhookURL != "" {
+		return PostJSON(sm.HTTPClient, opts.WebhookURL, map[string]string{"text": text}, nil)
 	} else if opts.Channel != "" && opts.Token != "" {
-		fmt.Printf("Sending message to Slack channel %s...", opts.Channel)
-		outputFilePath, _ := writeOutputToFile(outputBuffer)
-
-		var formData bytes.Buffer
-		writer := multipart.NewWriter(&formData)
-
-		fileWriter, err := writer.CreateFormFile("file", outputFilePath)
-		if err != nil {
-			return err
-		}
-		file, err := os.Open(outputFilePath)
+		fmt.Printf("Sending message to Slack channel %s...\n", opts.Channel)
+		outputFilePath, err := writeOutputToFile(outputBuffer)
 		if err != nil {
 			return err
 		}
-		defer file.Close()
-		_, err = io.Copy(fileWriter, file)
+		content, err := os.ReadFile(outputFilePath)
 		if err != nil {
 			return err
 		}
+		return sm.uploadFile(opts, filepath.Base(outputFilePath), content, sm.Summary)
+	} else {
+		return errors.New("SlackOpts must contain either WebhookURL or Channel and Token")
+	}
+}
 
-		if err := writer.WriteField("channels", opts.Channel); err != nil {
-			return err
-		}
+// uploadFile shares content in the channel through the external upload flow
+// of the Slack API, which replaced files.upload.
+func (sm SlackMessage) uploadFile(opts common.Opts, filename string, content []byte, comment string) error {
+	var upload struct {
+		UploadURL string `json:"upload_url"`
+		FileID    string `json:"file_id"`
+	}
+	err := sm.callAPI(opts.Token, "files.getUploadURLExternal", url.Values{
+		"filename": {filename},
+		"length":   {strconv.Itoa(len(content))},
+	}, &upload)
+	if err != nil {
+		return err
+	}
 
-		writer.Close()
+	resp, err := sm.client().Post(upload.UploadURL, "text/plain", bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack file upload returned non-OK status code: %d", resp.StatusCode)
+	}
 
-		req, err := http.NewRequest("POST", "https://slack.com/api/files.upload", &formData)
-		if err != nil {
-			return err
-		}
-		req.Header.Set("Authorization", "Bearer "+opts.Token)
-		req.Header.Set("Content-Type", writer.FormDataContentType())
+	files, err := json.Marshal([]map[string]string{{"id": upload.FileID, "title": "kor scan results"}})
+	if err != nil {
+		return err
+	}
+	form := url.Values{"files": {string(files)}, "channel_id": {opts.Channel}}
+	if comment != "" {
+		form.Set("initial_comment", comment)
+	}
+	return sm.callAPI(opts.Token, "files.completeUploadExternal", form, nil)
+}
 
-		client := &http.Client{}
-		resp, err := client.Do(req)
-		if err != nil {
-			return err
-		}
-		defer resp.Body.Close()
+// callAPI posts the form to a Slack Web API method and decodes the response
+// into result. Slack reports failures with "ok": false and an error code.
+func (sm SlackMessage) callAPI(token, method string, form url.Values, result interface{}) error {
+	req, err := http.NewRequest(http.MethodPost, slackAPIURL+"/"+method, bytes.NewBufferString(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-		if resp.StatusCode != http.StatusOK {
-			return fmt.Errorf("slack API returned non-OK status code: %d", resp.StatusCode)
-		}
+	resp, err := sm.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack API returned non-OK status code: %d", resp.StatusCode)
+	}
 
-		return nil
-	} else {
-		return errors.New("SlackOpts must contain either WebhookURL or Channel and Token")
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var status struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &status); err != nil {
+		return fmt.Errorf("failed to parse slack %s response: %w", method, err)
+	}
+	if !status.OK {
+		return fmt.Errorf("slack %s failed: %s", method, status.Error)
+	}
+	if result != nil {
+		return json.Unmarshal(body, result)
+	}
+	return nil
+}
+
+func (sm SlackMessage) client() *http.Client {
+	if sm.HTTPClient != nil {
+		return sm.HTTPClient
 	}
+	return &http.Client{Timeout: time.Minute}
 }
 
 func writeOutputToFile(outputBuffer string) (string, error) {
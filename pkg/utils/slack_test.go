@@ -2,9 +2,13 @@ package utils
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/yonahd/kor/pkg/common"
@@ -51,6 +55,95 @@ func TestSendToSlack(t *testing.T) {
 	}
 }
 
+func TestSendToSlackWebhook(t *testing.T) {
+	var payload map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Expected a JSON payload, got %v", err)
+		}
+	}))
+	defer server.Close()
+
+	message := SlackMessage{Summary: "*2 unused* \"quoted\"\nnext line"}
+	if err := SendToSlack(message, common.Opts{WebhookURL: server.URL}, "full report"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if payload["text"] != message.Summary {
+		t.Errorf("Expected text %q, got %q", message.Summary, payload["text"])
+	}
+}
+
+func TestSendToSlackWebhookError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	err := SendToSlack(SlackMessage{}, common.Opts{WebhookURL: server.URL}, "report")
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Expected a status code error, got %v", err)
+	}
+}
+
+func TestSendToSlackUpload(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	var uploaded, completed string
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/upload" && r.Header.Get("Authorization") != "Bearer xoxb-token" {
+			t.Errorf("Expected the bot token, got %q", r.Header.Get("Authorization"))
+		}
+		switch r.URL.Path {
+		case "/files.getUploadURLExternal":
+			if r.FormValue("length") != "6" {
+				t.Errorf("Expected length 6, got %q", r.FormValue("length"))
+			}
+			fmt.Fprintf(w, `{"ok": true, "upload_url": "%s/upload", "file_id": "F123"}`, server.URL)
+		case "/upload":
+			body, _ := io.ReadAll(r.Body)
+			uploaded = string(body)
+		case "/files.completeUploadExternal":
+			if r.FormValue("channel_id") != "C123" || !strings.Contains(r.FormValue("files"), "F123") {
+				t.Errorf("Unexpected completion %v", r.Form)
+			}
+			completed = r.FormValue("initial_comment")
+			fmt.Fprint(w, `{"ok": true}`)
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	slackAPIURL = server.URL
+	defer func() { slackAPIURL = "https://slack.com/api" }()
+
+	opts := common.Opts{Channel: "C123", Token: "xoxb-token"}
+	if err := SendToSlack(SlackMessage{Summary: "summary"}, opts, "report"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if uploaded != "report" {
+		t.Errorf("Expected the report to be uploaded, got %q", uploaded)
+	}
+	if completed != "summary" {
+		t.Errorf("Expected the summary as comment, got %q", completed)
+	}
+}
+
+func TestSendToSlackUploadError(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok": false, "error": "not_in_channel"}`)
+	}))
+	defer server.Close()
+	slackAPIURL = server.URL
+	defer func() { slackAPIURL = "https://slack.com/api" }()
+
+	err := SendToSlack(SlackMessage{}, common.Opts{Channel: "C123", Token: "xoxb-token"}, "report")
+	if err == nil || !strings.Contains(err.Error(), "not_in_channel") {
+		t.Errorf("Expected the slack error, got %v", err)
+	}
+}
+
 func TestWriteOutputToFile(t *testing.T) {
 	outputBuffer := bytes.Buffer{}
 	outputBuffer.WriteString("This is a test output.\n")
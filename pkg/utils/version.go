@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// Commit and BuildDate are set at build time with -ldflags, like Version
+	Commit    = ""
+	BuildDate = ""
+)
+
+// LatestReleaseURL is queried by kor version --check-update
+var LatestReleaseURL = "https://api.github.com/repos/yonahd/kor/releases/latest"
+
+// BuildInfo describes the kor binary
+type BuildInfo struct {
+	Version            string `json:"version"`
+	Commit             string `json:"commit,omitempty"`
+	BuildDate          string `json:"buildDate,omitempty"`
+	GoVersion          string `json:"goVersion"`
+	Platform           string `json:"platform"`
+	ClientGoVersion    string `json:"clientGoVersion,omitempty"`
+	KubernetesVersions string `json:"kubernetesVersions,omitempty"`
+}
+
+// GetBuildInfo returns the build information, falling back to the VCS
+// information recorded by the Go toolchain when no -ldflags were passed
+func GetBuildInfo() BuildInfo {
+	info := BuildInfo{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, setting := range buildInfo.Settings {
+		switch {
+		case setting.Key == "vcs.revision" && info.Commit == "":
+			info.Commit = setting.Value
+		case setting.Key == "vcs.time" && info.BuildDate == "":
+			info.BuildDate = setting.Value
+		}
+	}
+	for _, dep := range buildInfo.Deps {
+		if dep.Path == "k8s.io/client-go" {
+			info.ClientGoVersion = dep.Version
+			info.KubernetesVersions = kubernetesVersionRange(dep.Version)
+		}
+	}
+	return info
+}
+
+// kubernetesVersionRange returns the Kubernetes versions supported by a
+// client-go version, client-go v0.X talks to Kubernetes 1.X and its direct neighbours
+func kubernetesVersionRange(clientGoVersion string) string {
+	parts := strings.Split(strings.TrimPrefix(clientGoVersion, "v"), ".")
+	if len(parts) < 2 || parts[0] != "0" {
+		return ""
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil || minor < 1 {
+		return ""
+	}
+	return fmt.Sprintf("1.%d - 1.%d", minor-1, minor+1)
+}
+
+// String renders the build information one field per line
+func (b BuildInfo) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "kor version: v%s\n", b.Version)
+	for _, field := range []struct{ name, value string }{
+		{"git commit", b.Commit},
+		{"build date", b.BuildDate},
+		{"go version", b.GoVersion},
+		{"platform", b.Platform},
+		{"client-go", b.ClientGoVersion},
+		{"kubernetes", b.KubernetesVersions},
+	} {
+		if field.value != "" {
+			fmt.Fprintf(&sb, "%s: %s\n", field.name, field.value)
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// CheckLatestVersion returns the latest released version and whether it is
+// newer than the running one
+func CheckLatestVersion() (string, bool, error) {
+	client := http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(LatestReleaseURL)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to check for updates: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", false, fmt.Errorf("failed to check for updates: %s", resp.Status)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", false, fmt.Errorf("failed to check for updates: %w", err)
+	}
+	latest := strings.TrimPrefix(release.TagName, "v")
+	return latest, Version != "dev" && compareVersions(latest, strings.TrimPrefix(Version, "v")) > 0, nil
+}
+
+// compareVersions compares two dotted versions numerically, pre-release
+// suffixes are ignored
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(strings.SplitN(as[i], "-", 2)[0])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(strings.SplitN(bs[i], "-", 2)[0])
+		}
+		if x != y {
+			if x > y {
+				return 1
+			}
+			return -1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestKubernetesVersionRange(t *testing.T) {
+	if got := kubernetesVersionRange("v0.31.2"); got != "1.30 - 1.32" {
+		t.Errorf("Expected 1.30 - 1.32, got %q", got)
+	}
+	if got := kubernetesVersionRange("v1.0.0"); got != "" {
+		t.Errorf("Expected no range for an unknown scheme, got %q", got)
+	}
+}
+
+func TestCheckLatestVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"tag_name": "v0.10.0"}`))
+	}))
+	defer server.Close()
+
+	originalURL, originalVersion := LatestReleaseURL, Version
+	defer func() { LatestReleaseURL, Version = originalURL, originalVersion }()
+	LatestReleaseURL = server.URL
+
+	for version, expectNewer := range map[string]bool{"0.9.9": true, "0.10.0": false, "0.10.1": false, "dev": false} {
+		Version = version
+		latest, newer, err := CheckLatestVersion()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if latest != "0.10.0" || newer != expectNewer {
+			t.Errorf("Version %s: expected latest 0.10.0 newer %v, got %s %v", version, expectNewer, latest, newer)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// PostJSON posts payload as JSON to the webhook URL with the extra headers set.
+// A nil client uses a client with a one minute timeout.
+func PostJSON(client *http.Client, url string, payload interface{}, headers map[string]string) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	if client == nil {
+		client = &http.Client{Timeout: time.Minute}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook returned non-OK status code: %d %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// ParseHeaders parses "Name: value" headers. Environment variables in the
+// values are expanded, so secrets do not have to be given on the command line.
+func ParseHeaders(headers []string) (map[string]string, error) {
+	parsed := make(map[string]string, len(headers))
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("invalid header %q, must be Name: value", header)
+		}
+		parsed[name] = os.ExpandEnv(strings.TrimSpace(value))
+	}
+	return parsed, nil
+}
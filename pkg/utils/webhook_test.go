@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPostJSON(t *testing.T) {
+	var payload map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" || r.Header.Get("X-Token") != "secret" {
+			t.Errorf("Unexpected headers %v", r.Header)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Expected a JSON payload, got %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	if err := PostJSON(nil, server.URL, map[string]string{"text": "hello"}, map[string]string{"X-Token": "secret"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if payload["text"] != "hello" {
+		t.Errorf("Expected the payload to be posted, got %v", payload)
+	}
+}
+
+func TestPostJSONError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	err := PostJSON(nil, server.URL, map[string]string{}, nil)
+	if err == nil || !strings.Contains(err.Error(), "429 rate limited") {
+		t.Errorf("Expected the status code and body in the error, got %v", err)
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	t.Setenv("KOR_TEST_TOKEN", "secret")
+	headers, err := ParseHeaders([]string{"Authorization: Bearer $KOR_TEST_TOKEN", "X-Tags:a,b"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if headers["Authorization"] != "Bearer secret" || headers["X-Tags"] != "a,b" {
+		t.Errorf("Unexpected headers %v", headers)
+	}
+
+	for _, header := range []string{"Authorization", ": value", "Bad Name: value"} {
+		if _, err := ParseHeaders([]string{header}); err == nil {
+			t.Errorf("Expected an error for %q", header)
+		}
+	}
+}